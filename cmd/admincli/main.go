@@ -0,0 +1,393 @@
+// Package main is the admin command line tool, for operators fixing accounts directly in the
+// database without going through the HTTP API.
+//
+// Usage:
+//
+//	admincli [-tenant <id>] <command> [flags]
+//
+// Commands:
+//
+//	create-admin    -email <email> [-username <name>] [-first-name <name>] [-last-name <name>] [-password <password>]
+//	reset-password  (-id <id> | -email <email>) [-password <password>]
+//	verify-email    (-id <id> | -email <email>)
+//	delete-user     (-id <id> | -email <email>) -yes
+//	reindex
+//	rotate-signing-key [-algorithm EdDSA|RS256] [-publish-delay <duration>] [-grace-period <duration>]
+//	purge           [-dry-run]
+//
+// Passwords not given as flags are read from the first line of standard input.
+// The MongoDB connection is configured like the API, through MONGODB_URI, MONGODB_DB_NAME and
+// TENANT_DATABASES; rotate-signing-key also reads JWT_KEY_ENCRYPTION_KEY and the defaults of its
+// flags from the JWT_KEY_* variables of the API. purge applies to every tenant the RETENTION_*
+// windows of the API, and reindex creates the indexes in every database.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/database"
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/core/usecase"
+	"github.com/frtasoniero/user-management-api/internal/repository"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+	"github.com/joho/godotenv"
+)
+
+// minPasswordLength matches the registration rule of the API
+const minPasswordLength = 6
+
+type command struct {
+	usage string
+	run   func(ctx context.Context, databases *repository.TenantDatabases, args []string) error
+}
+
+var commands = map[string]command{
+	"create-admin":       {"-email <email> [-username <name>] [-first-name <name>] [-last-name <name>] [-password <password>]", createAdmin},
+	"reset-password":     {"(-id <id> | -email <email>) [-password <password>]", resetPassword},
+	"verify-email":       {"(-id <id> | -email <email>)", verifyEmail},
+	"delete-user":        {"(-id <id> | -email <email>) -yes", deleteUser},
+	"reindex":            {"", reindex},
+	"rotate-signing-key": {"[-algorithm EdDSA|RS256] [-publish-delay <duration>] [-grace-period <duration>]", rotateSigningKey},
+	"purge":              {"[-dry-run]", purge},
+}
+
+func main() {
+	log.SetFlags(0)
+	flag.Usage = usage
+	tenant := flag.String("tenant", "", "tenant of the user (default DEFAULT_TENANT or \"default\")")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		log.Printf("Unknown command %q", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	// Load environment variables from .env file (optional)
+	_ = godotenv.Load()
+	if *tenant == "" {
+		*tenant = os.Getenv("DEFAULT_TENANT")
+	}
+	if *tenant == "" {
+		*tenant = "default"
+	}
+	if !domain.ValidTenantID(*tenant) {
+		log.Fatalf("Invalid tenant %q", *tenant)
+	}
+	dbName := os.Getenv("MONGODB_DB_NAME")
+	if dbName == "" {
+		log.Fatal("MONGODB_DB_NAME environment variable is not set")
+	}
+
+	database.ConnectToMongoDB()
+	defer database.DisconnectFromMongoDB()
+
+	ctx, cancel := context.WithTimeout(domain.WithTenant(context.Background(), *tenant), time.Minute)
+	defer cancel()
+	databases := repository.NewTenantDatabases(database.MongoDBClient.Database(dbName), database.ConnectToTenantDatabases())
+	databases.SetListReadPreference(database.ListReadPreference())
+	if err := cmd.run(ctx, databases, flag.Args()[1:]); err != nil {
+		log.Printf("%s: %v", flag.Arg(0), err)
+		cancel()
+		database.DisconnectFromMongoDB()
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, "Usage: admincli [-tenant <id>] <command> [flags]")
+	fmt.Fprintln(out, "\nCommands:")
+	for _, name := range []string{"create-admin", "reset-password", "verify-email", "delete-user", "reindex", "rotate-signing-key", "purge"} {
+		fmt.Fprintln(out, strings.TrimSpace(fmt.Sprintf("  %-19s %s", name, commands[name].usage)))
+	}
+}
+
+func createAdmin(ctx context.Context, databases *repository.TenantDatabases, args []string) error {
+	fs := flag.NewFlagSet("create-admin", flag.ExitOnError)
+	email := fs.String("email", "", "email of the admin")
+	username := fs.String("username", "", "optional username")
+	firstName := fs.String("first-name", "Admin", "first name")
+	lastName := fs.String("last-name", "User", "last name")
+	password := fs.String("password", "", "password (read from stdin when omitted)")
+	fs.Parse(args)
+	if *email == "" {
+		return errors.New("-email is required")
+	}
+
+	users := historyUsers(databases)
+	user, err := domain.NewUser(*email, "", domain.Profile{FirstName: *firstName, LastName: *lastName})
+	if err != nil {
+		return err
+	}
+	existing, err := users.GetUserByNormalizedEmail(ctx, user.NormalizedEmail)
+	if err == nil && existing == nil {
+		existing, err = users.GetUserByEmail(ctx, user.Email)
+	}
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return fmt.Errorf("%s is already registered as %s (id %s)", user.Email, existing.Email, existing.ID)
+	}
+	if *username != "" {
+		if user.Username, err = domain.NormalizeUsername(*username); err != nil {
+			return err
+		}
+	}
+	if user.PasswordHash, err = hashPassword(*password); err != nil {
+		return err
+	}
+	user.Roles = []string{domain.RoleAdmin}
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+
+	if err := users.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	log.Printf("Created admin %s (id %s)", user.Email, user.ID)
+	return nil
+}
+
+func resetPassword(ctx context.Context, databases *repository.TenantDatabases, args []string) error {
+	fs := flag.NewFlagSet("reset-password", flag.ExitOnError)
+	id, email := userFlags(fs)
+	password := fs.String("password", "", "new password (read from stdin when omitted)")
+	fs.Parse(args)
+
+	users := historyUsers(databases)
+	user, err := findUser(ctx, users, *id, *email)
+	if err != nil {
+		return err
+	}
+	hash, err := hashPassword(*password)
+	if err != nil {
+		return err
+	}
+	if err := users.SetPasswordHash(ctx, user.ID, hash); err != nil {
+		return err
+	}
+	details := map[string]string{"source": "admincli"}
+	if err := usecase.NewAuditUseCase(repository.NewAuditRepository(databases, "audit_logs")).Record(ctx, domain.AuditActionPasswordChanged, "", user.ID, details); err != nil {
+		log.Printf("Error recording password change of %s: %v", user.ID, err)
+	}
+	log.Printf("Reset the password of %s (id %s)", user.Email, user.ID)
+	return nil
+}
+
+func verifyEmail(ctx context.Context, databases *repository.TenantDatabases, args []string) error {
+	fs := flag.NewFlagSet("verify-email", flag.ExitOnError)
+	id, email := userFlags(fs)
+	fs.Parse(args)
+
+	users := historyUsers(databases)
+	user, err := findUser(ctx, users, *id, *email)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt != nil {
+		log.Printf("%s was already verified on %s", user.Email, user.EmailVerifiedAt.Format(time.RFC3339))
+		return nil
+	}
+	if err := users.SetEmailVerified(ctx, user.ID, time.Now()); err != nil {
+		return err
+	}
+	log.Printf("Verified %s (id %s)", user.Email, user.ID)
+	return nil
+}
+
+func deleteUser(ctx context.Context, databases *repository.TenantDatabases, args []string) error {
+	fs := flag.NewFlagSet("delete-user", flag.ExitOnError)
+	id, email := userFlags(fs)
+	yes := fs.Bool("yes", false, "confirm the deletion")
+	fs.Parse(args)
+
+	users := repository.NewUserRepository(databases, "users")
+	user, err := findUser(ctx, users, *id, *email)
+	if err != nil {
+		return err
+	}
+	if !*yes {
+		return fmt.Errorf("pass -yes to delete %s (id %s)", user.Email, user.ID)
+	}
+	if err := users.DeleteUser(ctx, user.ID); err != nil {
+		return err
+	}
+	log.Printf("Deleted %s (id %s)", user.Email, user.ID)
+	return nil
+}
+
+// reindex creates the indexes of every collection on databases not set up by scripts/mongo-init.js
+func reindex(ctx context.Context, databases *repository.TenantDatabases, args []string) error {
+	fs := flag.NewFlagSet("reindex", flag.ExitOnError)
+	fs.Parse(args)
+
+	repos := []struct {
+		name string
+		repo interface{ EnsureIndexes(context.Context) error }
+	}{
+		{"users", repository.NewUserRepository(databases, "users")},
+		{"organizations", repository.NewOrganizationRepository(databases, "organizations", "memberships")},
+		{"roles", repository.NewRoleRepository(databases, "roles")},
+		{"audit_logs", repository.NewAuditRepository(databases, "audit_logs")},
+		{"login_events", repository.NewLoginEventRepository(databases, "login_events")},
+		{"sessions", repository.NewSessionRepository(databases, "sessions")},
+		{"signing_keys", repository.NewSigningKeyRepository(databases.Shared(), "signing_keys")},
+		{"oauth_clients", repository.NewOAuthClientRepository(databases, "oauth_clients")},
+		{"email_domain_rules", repository.NewEmailDomainRepository(databases, "email_domain_rules")},
+		{"user_list", repository.NewUserReadModelRepository(databases.Shared(), "user_list")},
+		{"user_history", repository.NewUserHistoryRepository(databases, "user_history")},
+		{"terms", repository.NewTermsRepository(databases, "terms_versions", "terms_acceptances")},
+		{"jobs", repository.NewJobRepository(databases.Shared(), "jobs")},
+		{"user_stats", repository.NewStatsRepository(databases, "user_stats", "users", "login_events")},
+		{"relationships", repository.NewRelationshipRepository(databases, "relationships", "blocks")},
+		{"documents", repository.NewDocumentRepository(databases, "documents")},
+	}
+	for _, r := range repos {
+		if err := r.repo.EnsureIndexes(ctx); err != nil {
+			return fmt.Errorf("indexing %s: %w", r.name, err)
+		}
+		log.Printf("Indexed %s", r.name)
+	}
+	return nil
+}
+
+// rotateSigningKey adds a token signing key, which the running API instances publish on their next
+// reload and sign with after the publish delay; the previous keys expire after the grace period
+func rotateSigningKey(ctx context.Context, databases *repository.TenantDatabases, args []string) error {
+	fs := flag.NewFlagSet("rotate-signing-key", flag.ExitOnError)
+	algorithm := fs.String("algorithm", envOr("JWT_KEY_ALGORITHM", "EdDSA"), "algorithm of the new key, EdDSA or RS256")
+	publishDelay := fs.Duration("publish-delay", envDuration("JWT_KEY_PUBLISH_DELAY", 10*time.Minute), "how long the new key is published before it signs")
+	gracePeriod := fs.Duration("grace-period", envDuration("JWT_KEY_GRACE_PERIOD", envDuration("JWT_TTL", time.Hour)), "how long the previous keys verify tokens once the new key signs")
+	fs.Parse(args)
+
+	cipher, err := security.NewKeyCipher(os.Getenv("JWT_KEY_ENCRYPTION_KEY"))
+	if err != nil {
+		return fmt.Errorf("JWT_KEY_ENCRYPTION_KEY: %w", err)
+	}
+	policy := usecase.SigningKeyPolicy{Algorithm: *algorithm, PublishDelay: *publishDelay, GracePeriod: *gracePeriod}
+	keys := usecase.NewSigningKeyUseCase(repository.NewSigningKeyRepository(databases.Shared(), "signing_keys"), cipher, nil,
+		usecase.NewAuditUseCase(repository.NewAuditRepository(databases, "audit_logs")), policy, domain.TenantFromContext(ctx))
+	key, err := keys.RotateSigningKey(ctx, "")
+	if err != nil {
+		return err
+	}
+	log.Printf("Added signing key %s, signing from %s", key.ID, key.ActivatesAt.Format(time.RFC3339))
+	return nil
+}
+
+// purge runs the retention purge of the API once, printing its report as JSON
+func purge(ctx context.Context, databases *repository.TenantDatabases, args []string) error {
+	fs := flag.NewFlagSet("purge", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "only report what would be removed")
+	fs.Parse(args)
+
+	policy := domain.RetentionPolicy{
+		DeletedUsers:       envDays("RETENTION_DELETED_USERS_DAYS"),
+		DeletedUsersAction: envOr("RETENTION_DELETED_USERS_ACTION", domain.RetentionDelete),
+		LoginEvents:        envDays("RETENTION_LOGIN_EVENTS_DAYS"),
+	}
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("RETENTION_DELETED_USERS_ACTION: %w", err)
+	}
+	report, err := usecase.NewRetentionUseCase(repository.NewRetentionRepository(databases, "users", "login_events"), policy).Purge(ctx, *dryRun)
+	if err != nil {
+		return err
+	}
+	out := json.NewEncoder(os.Stdout)
+	out.SetIndent("", "  ")
+	return out.Encode(report)
+}
+
+// envOr returns the value of an environment variable, or fallback when it is unset
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// envDuration returns the duration of an environment variable, or fallback when it is unset or invalid
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if duration, err := time.ParseDuration(os.Getenv(name)); err == nil && duration > 0 {
+		return duration
+	}
+	return fallback
+}
+
+// envDays returns the number of days of an environment variable as a duration, or zero when it is unset or invalid
+func envDays(name string) time.Duration {
+	if days, err := strconv.Atoi(os.Getenv(name)); err == nil && days > 0 {
+		return time.Duration(days) * 24 * time.Hour
+	}
+	return 0
+}
+
+func userFlags(fs *flag.FlagSet) (id, email *string) {
+	return fs.String("id", "", "user ID"), fs.String("email", "", "user email")
+}
+
+// historyUsers returns the users of db, recording the changes of the writes in their change history
+func historyUsers(databases *repository.TenantDatabases) ports.UserRepository {
+	return repository.NewHistoryUserRepository(repository.NewUserRepository(databases, "users"), repository.NewUserHistoryRepository(databases, "user_history"))
+}
+
+// findUser looks a user of the tenant up by ID or email
+func findUser(ctx context.Context, users ports.UserRepository, id, email string) (*domain.User, error) {
+	var (
+		user *domain.User
+		err  error
+	)
+	switch {
+	case id != "" && email != "":
+		return nil, errors.New("pass either -id or -email, not both")
+	case id != "":
+		user, err = users.GetUserByID(ctx, id)
+	case email != "":
+		canonical, cerr := domain.CanonicalEmail(email)
+		if cerr != nil {
+			return nil, cerr
+		}
+		user, err = users.GetUserByEmail(ctx, canonical)
+	default:
+		return nil, errors.New("-id or -email is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// hashPassword hashes the given password, reading it from stdin when empty
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		fmt.Fprint(os.Stderr, "Password: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("reading password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	return security.HashPassword(password)
+}
@@ -3,21 +3,52 @@ package main
 
 import (
 	"context"
+	"crypto"
+	"crypto/rand"
+	"errors"
+	"fmt"
 	"log"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Embed the IANA time zone database used to validate profile timezones
 
 	"github.com/frtasoniero/user-management-api/database"
+	"github.com/frtasoniero/user-management-api/internal/adapters/breach"
+	"github.com/frtasoniero/user-management-api/internal/adapters/directory"
+	geocodingadapter "github.com/frtasoniero/user-management-api/internal/adapters/geocoding"
+	"github.com/frtasoniero/user-management-api/internal/adapters/geoip"
+	handler "github.com/frtasoniero/user-management-api/internal/adapters/handler/http"
+	"github.com/frtasoniero/user-management-api/internal/adapters/identity"
+	"github.com/frtasoniero/user-management-api/internal/adapters/kyc"
+	"github.com/frtasoniero/user-management-api/internal/adapters/mail"
+	"github.com/frtasoniero/user-management-api/internal/adapters/ratelimit"
+	"github.com/frtasoniero/user-management-api/internal/adapters/search"
+	"github.com/frtasoniero/user-management-api/internal/adapters/siem"
+	"github.com/frtasoniero/user-management-api/internal/adapters/sms"
+	"github.com/frtasoniero/user-management-api/internal/adapters/storage"
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/core/usecase"
 	"github.com/frtasoniero/user-management-api/internal/repository"
+	"github.com/frtasoniero/user-management-api/pkg/cron"
+	"github.com/frtasoniero/user-management-api/pkg/i18n"
+	"github.com/frtasoniero/user-management-api/pkg/ldap"
+	"github.com/frtasoniero/user-management-api/pkg/openapi"
+	"github.com/frtasoniero/user-management-api/pkg/redis"
+	"github.com/frtasoniero/user-management-api/pkg/security"
 	"github.com/frtasoniero/user-management-api/routes"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 
-	// Import docs for swagger (will be generated)
-	_ "github.com/frtasoniero/user-management-api/docs"
+	// Swagger documentation, generated by make swagger
+	"github.com/frtasoniero/user-management-api/docs"
 )
 
 // @title User Management API
@@ -37,12 +68,52 @@ import (
 // @BasePath /api/v1
 // @schemes http https
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and the access token
+
+// @securityDefinitions.basic ClientBasicAuth
+
 // @tag.name health
 // @tag.description Health check endpoints
 
+// @tag.name auth
+// @tag.description Authentication endpoints
+
 // @tag.name users
 // @tag.description User management operations including registration, authentication, and profile management
 
+// @tag.name roles
+// @tag.description Role and permission management
+
+// @tag.name audit
+// @tag.description Audit trail of administrative actions
+
+// @tag.name security
+// @tag.description Login abuse protection
+
+// @tag.name organizations
+// @tag.description Organizations and their memberships
+
+// @tag.name terms
+// @tag.description Terms of service and privacy policy consent
+
+// @tag.name jobs
+// @tag.description Background jobs and their failures
+
+// @tag.name stats
+// @tag.description Daily user statistics
+
+// @tag.name relationships
+// @tag.description Users following other users
+
+// @tag.name documents
+// @tag.description Identity documents uploaded for verification
+
+// @tag.name verification
+// @tag.description Identity verification of users reviewed by staff
+
 func main() {
 	// Load environment variables from .env file (optional for development)
 	if err := godotenv.Load(); err != nil {
@@ -62,13 +133,1052 @@ func main() {
 
 	// Initialize repository layer with MongoDB database connection
 	dbClient := database.MongoDBClient.Database(dbName)
-	userRepo := repository.NewUserRepository(dbClient, "users")
+	// Route the tenants of TENANT_DATABASES to their own database, the others share dbClient with the
+	// data of the instance, such as the jobs and the signing keys
+	tenantDatabases := database.ConnectToTenantDatabases()
+	databases := repository.NewTenantDatabases(dbClient, tenantDatabases)
+	databases.SetListReadPreference(database.ListReadPreference())
+	// Bound the operations run without a deadline, e.g. by background work or a handler without one
+	deadlinePolicy := ports.DefaultDeadlinePolicy()
+	deadlinePolicy.FindOne = durationFromEnv("DB_FIND_ONE_TIMEOUT", deadlinePolicy.FindOne)
+	deadlinePolicy.Find = durationFromEnv("DB_FIND_TIMEOUT", deadlinePolicy.Find)
+	deadlinePolicy.Write = durationFromEnv("DB_WRITE_TIMEOUT", deadlinePolicy.Write)
+	databases.SetDeadlinePolicy(deadlinePolicy)
+	userRepo := repository.NewUserRepository(databases, "users")
+	orgRepo := repository.NewOrganizationRepository(databases, "organizations", "memberships")
+	roleRepo := repository.NewRoleRepository(databases, "roles")
+	auditRepo := repository.NewAuditRepository(databases, "audit_logs")
+	loginEventRepo := repository.NewLoginEventRepository(databases, "login_events")
+	sessionRepo := repository.NewSessionRepository(databases, "sessions")
+	oauthClientRepo := repository.NewOAuthClientRepository(databases, "oauth_clients")
+	emailDomainRepo := repository.NewEmailDomainRepository(databases, "email_domain_rules")
+	termsRepo := repository.NewTermsRepository(databases, "terms_versions", "terms_acceptances")
+	relationshipRepo := repository.NewRelationshipRepository(databases, "relationships", "blocks")
+	documentRepo := repository.NewDocumentRepository(databases, "documents")
+	userHistoryRepo := repository.NewUserHistoryRepository(databases, "user_history")
+
+	// Check the dependencies of the instance at /readyz, which is not ready while the database is down
+	healthChecks := []ports.HealthCheck{{Name: "mongodb", Critical: true, Checker: repository.NewDatabaseHealthChecker(dbClient)}}
+	for _, tenantID := range slices.Sorted(maps.Keys(tenantDatabases)) {
+		healthChecks = append(healthChecks, ports.HealthCheck{Name: "mongodb:" + tenantID, Checker: repository.NewDatabaseHealthChecker(tenantDatabases[tenantID])})
+	}
+
+	// Collections whose indexes are checked at startup
+	indexedRepos := []repository.IndexedRepository{
+		userRepo, orgRepo, roleRepo, auditRepo, loginEventRepo, sessionRepo, oauthClientRepo, emailDomainRepo, termsRepo,
+		relationshipRepo, documentRepo, userHistoryRepo,
+		repository.NewJobRepository(dbClient, "jobs"),
+		repository.NewStatsRepository(databases, "user_stats", "users", "login_events"),
+	}
+
+	// Retry user reads failing with transient database errors, such as a primary stepping down
+	retryPolicy := ports.DefaultRetryPolicy()
+	if attempts := os.Getenv("DB_RETRY_ATTEMPTS"); attempts != "" {
+		n, err := strconv.Atoi(attempts)
+		if err != nil || n < 1 {
+			log.Fatalf("Invalid DB_RETRY_ATTEMPTS value %q: must be a positive number", attempts)
+		}
+		retryPolicy.MaxAttempts = n
+	}
+	retryPolicy.BaseDelay = durationFromEnv("DB_RETRY_BASE_DELAY", retryPolicy.BaseDelay)
+	retryPolicy.MaxDelay = durationFromEnv("DB_RETRY_MAX_DELAY", retryPolicy.MaxDelay)
+	retryingUserRepo := repository.NewRetryingUserRepository(userRepo, retryPolicy)
+
+	// Fail user repository calls fast while the database is down, probing it every DB_BREAKER_OPEN_TIMEOUT
+	breakerPolicy := ports.DefaultBreakerPolicy()
+	if failures := os.Getenv("DB_BREAKER_FAILURES"); failures != "" {
+		n, err := strconv.Atoi(failures)
+		if err != nil || n < 1 {
+			log.Fatalf("Invalid DB_BREAKER_FAILURES value %q: must be a positive number", failures)
+		}
+		breakerPolicy.FailureThreshold = n
+	}
+	breakerPolicy.OpenTimeout = durationFromEnv("DB_BREAKER_OPEN_TIMEOUT", breakerPolicy.OpenTimeout)
+	breakerUserRepo := repository.NewCircuitBreakerUserRepository(retryingUserRepo, breakerPolicy)
+
+	// Record the changes of every write of a user in its change history
+	historyUserRepo := repository.NewHistoryUserRepository(breakerUserRepo, userHistoryRepo)
 
-	// Initialize Gin HTTP router with default middleware (logger and recovery)
-	router := gin.Default()
+	// Serve the user lists from a read model with flat fields when USER_READ_MODEL is true, and their
+	// full-text searches from an OpenSearch index when OPENSEARCH_URL is set. Both are kept in sync with
+	// the users collection by change streams, on the instances with USER_READ_MODEL_SYNC and
+	// SEARCH_INDEX_SYNC (default true).
+	var users ports.UserRepository = historyUserRepo
+	var projections []*usecase.UserProjectionUseCase
+	if (boolFromEnv("USER_READ_MODEL", false) || os.Getenv("OPENSEARCH_URL") != "") && len(tenantDatabases) > 0 {
+		log.Fatal("USER_READ_MODEL and OPENSEARCH_URL are not supported with TENANT_DATABASES: they are only synced from the shared database")
+	}
+	if boolFromEnv("USER_READ_MODEL", false) {
+		readModel := repository.NewUserReadModelRepository(dbClient, "user_list")
+		indexedRepos = append(indexedRepos, readModel)
+		changes := repository.NewUserChangeStream(dbClient, "users", "change_stream_tokens", "user_read_model")
+		users = repository.NewReadModelUserRepository(users, readModel, changes)
+		if boolFromEnv("USER_READ_MODEL_SYNC", true) {
+			projection := usecase.NewUserProjectionUseCase("user read model", changes, readModel)
+			projections = append(projections, projection)
+			healthChecks = append(healthChecks, ports.HealthCheck{Name: "user_read_model_sync", Checker: projection})
+		}
+	}
+	if searchURL := os.Getenv("OPENSEARCH_URL"); searchURL != "" {
+		indexName := os.Getenv("OPENSEARCH_INDEX")
+		if indexName == "" {
+			indexName = "users"
+		}
+		index := search.NewOpenSearchIndex(searchURL, indexName, os.Getenv("OPENSEARCH_USERNAME"), os.Getenv("OPENSEARCH_PASSWORD"))
+		users = repository.NewSearchIndexedUserRepository(users, index)
+		if boolFromEnv("SEARCH_INDEX_SYNC", true) {
+			changes := repository.NewUserChangeStream(dbClient, "users", "change_stream_tokens", "search_index:"+indexName)
+			projection := usecase.NewUserProjectionUseCase("search index", changes, index)
+			projections = append(projections, projection)
+			healthChecks = append(healthChecks, ports.HealthCheck{Name: "search_index_sync", Checker: projection})
+		}
+	}
+	for _, projection := range projections {
+		projection.Start()
+	}
+
+	// Export the audit events to the SIEM sinks configured: syslog when AUDIT_SYSLOG_ADDR is set, a
+	// Splunk HTTP Event Collector when AUDIT_SPLUNK_HEC_URL is set and S3 batches when AUDIT_S3_BUCKET is set
+	var auditEvents ports.AuditRepository = auditRepo
+	var auditExport *usecase.AuditExportUseCase
+	if sinks := auditSinksFromEnv(); len(sinks) > 0 {
+		exportPolicy := ports.DefaultAuditExportPolicy()
+		exportPolicy.BatchSize = positiveIntFromEnv("AUDIT_EXPORT_BATCH_SIZE", exportPolicy.BatchSize)
+		exportPolicy.BufferSize = positiveIntFromEnv("AUDIT_EXPORT_BUFFER_SIZE", exportPolicy.BufferSize)
+		exportPolicy.FlushInterval = durationFromEnv("AUDIT_EXPORT_FLUSH_INTERVAL", exportPolicy.FlushInterval)
+		exportPolicy.MaxAttempts = positiveIntFromEnv("AUDIT_EXPORT_RETRY_ATTEMPTS", exportPolicy.MaxAttempts)
+		auditExport = usecase.NewAuditExportUseCase(sinks, exportPolicy)
+		auditExport.Start()
+		auditEvents = repository.NewExportingAuditRepository(auditRepo, auditExport)
+	}
+
+	// Initialize JWT token manager from environment variables, token lifetime defaults to 1h. Tokens
+	// are signed with the keys stored in the database when JWT_KEY_ENCRYPTION_KEY is set, with the
+	// private key of JWT_PRIVATE_KEY_FILE when set, or else with JWT_SECRET.
+	jwtSecret := os.Getenv("JWT_SECRET")
+	privateKeyFile := os.Getenv("JWT_PRIVATE_KEY_FILE")
+	keyEncryptionKey := os.Getenv("JWT_KEY_ENCRYPTION_KEY")
+	if jwtSecret == "" && privateKeyFile == "" && keyEncryptionKey == "" {
+		log.Fatal("JWT_SECRET, JWT_PRIVATE_KEY_FILE or JWT_KEY_ENCRYPTION_KEY environment variable must be set")
+	}
+	if privateKeyFile != "" && keyEncryptionKey != "" {
+		log.Fatal("JWT_PRIVATE_KEY_FILE and JWT_KEY_ENCRYPTION_KEY cannot be set together")
+	}
+	tokenTTL := time.Hour
+	if ttl := os.Getenv("JWT_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			log.Fatalf("Invalid JWT_TTL value %q: %v", ttl, err)
+		}
+		tokenTTL = parsed
+	}
+	tokens := security.NewTokenManager(jwtSecret, tokenTTL)
+	if privateKeyFile != "" {
+		data, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			log.Fatalf("Error reading JWT_PRIVATE_KEY_FILE: %v", err)
+		}
+		signer, err := security.ParsePrivateKeyPEM(data)
+		if err != nil {
+			log.Fatalf("Invalid private key in %s: %v", privateKeyFile, err)
+		}
+		// Public keys rotated out keep verifying the tokens they signed
+		var previous []crypto.PublicKey
+		if files := os.Getenv("JWT_PREVIOUS_PUBLIC_KEY_FILES"); files != "" {
+			for _, file := range strings.Split(files, ",") {
+				file = strings.TrimSpace(file)
+				data, err := os.ReadFile(file)
+				if err != nil {
+					log.Fatalf("Error reading JWT_PREVIOUS_PUBLIC_KEY_FILES: %v", err)
+				}
+				public, err := security.ParsePublicKeyPEM(data)
+				if err != nil {
+					log.Fatalf("Invalid public key in %s: %v", file, err)
+				}
+				previous = append(previous, public)
+			}
+		}
+		if tokens, err = security.NewKeyPairTokenManager(signer, previous, jwtSecret, tokenTTL); err != nil {
+			log.Fatalf("Invalid JWT signing keys: %v", err)
+		}
+	}
+
+	// Keep the signing keys in the database, encrypted with JWT_KEY_ENCRYPTION_KEY, so every instance
+	// signs with the same keys and admins rotate them without restarting the API
+	var signingKeyUseCase *usecase.SigningKeyUseCase
+	if keyEncryptionKey != "" {
+		cipher, err := security.NewKeyCipher(keyEncryptionKey)
+		if err != nil {
+			log.Fatalf("Invalid JWT_KEY_ENCRYPTION_KEY value: %v", err)
+		}
+		policy := usecase.SigningKeyPolicy{
+			Algorithm:       "EdDSA",
+			RefreshInterval: durationFromEnv("JWT_KEY_REFRESH_INTERVAL", time.Minute),
+			PublishDelay:    durationFromEnv("JWT_KEY_PUBLISH_DELAY", 10*time.Minute),
+			GracePeriod:     durationFromEnv("JWT_KEY_GRACE_PERIOD", tokenTTL),
+		}
+		if algorithm := os.Getenv("JWT_KEY_ALGORITHM"); algorithm != "" {
+			if algorithm != "EdDSA" && algorithm != "RS256" {
+				log.Fatalf("Invalid JWT_KEY_ALGORITHM value %q: must be EdDSA or RS256", algorithm)
+			}
+			policy.Algorithm = algorithm
+		}
+		// Instances must load a new key before it signs, and replaced keys outlive their tokens
+		if policy.PublishDelay < policy.RefreshInterval {
+			log.Fatal("JWT_KEY_PUBLISH_DELAY must be at least JWT_KEY_REFRESH_INTERVAL")
+		}
+		if policy.GracePeriod < tokenTTL {
+			log.Fatal("JWT_KEY_GRACE_PERIOD must be at least JWT_TTL")
+		}
+		adminTenant := os.Getenv("JWT_KEY_ADMIN_TENANT")
+		if adminTenant == "" {
+			adminTenant = domain.DefaultTenantID
+		}
+
+		signingKeyRepo := repository.NewSigningKeyRepository(dbClient, "signing_keys")
+		indexedRepos = append(indexedRepos, signingKeyRepo)
+		signingKeyUseCase = usecase.NewSigningKeyUseCase(signingKeyRepo, cipher, tokens, usecase.NewAuditUseCase(auditEvents), policy, adminTenant)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := signingKeyUseCase.Load(ctx); err != nil {
+			log.Fatalf("Error loading the token signing keys: %v", err)
+		}
+		cancel()
+		signingKeyUseCase.Start()
+	}
+
+	// Check the configuration, the token keys and the database before going further, reporting every
+	// problem at once: STARTUP_CHECK=enforce (default) refuses to start when a critical check fails,
+	// warn only reports them and off skips the checks
+	switch mode := os.Getenv("STARTUP_CHECK"); mode {
+	case "", "enforce", "warn":
+		selfCheck := usecase.NewSelfCheck()
+		selfCheck.Add("environment", true, func(context.Context) (string, error) {
+			if missing := missingEnv(); len(missing) > 0 {
+				return "", fmt.Errorf("missing %s", strings.Join(missing, "; "))
+			}
+			return "required variables set", nil
+		})
+		selfCheck.Add("jwt_keys", true, func(context.Context) (string, error) {
+			token, _, err := tokens.Generate("startup-check", nil, domain.DefaultTenantID)
+			if err != nil {
+				return "", fmt.Errorf("signing a token: %w", err)
+			}
+			if _, err := tokens.Parse(token); err != nil {
+				return "", fmt.Errorf("verifying a token: %w", err)
+			}
+			if keys := len(tokens.JWKS().Keys); keys > 0 {
+				return fmt.Sprintf("signed with a key pair, %d public keys", keys), nil
+			}
+			return "signed with JWT_SECRET", nil
+		})
+		if jwtSecret != "" {
+			selfCheck.Add("jwt_secret_length", false, func(context.Context) (string, error) {
+				if len(jwtSecret) < 32 {
+					return "", fmt.Errorf("JWT_SECRET has %d bytes, use at least 32", len(jwtSecret))
+				}
+				return fmt.Sprintf("%d bytes", len(jwtSecret)), nil
+			})
+		}
+		selfCheck.Add("mongodb", true, func(ctx context.Context) (string, error) {
+			health := repository.NewDatabaseHealthChecker(dbClient).CheckHealth(ctx)
+			if health.Status != ports.HealthUp {
+				return "", errors.New(health.Error)
+			}
+			return fmt.Sprintf("version %s, ping %.1fms", health.Version, health.LatencyMS), nil
+		})
+		var missingIndexes []repository.MissingIndex
+		var indexesErr error
+		selfCheck.Add("unique_indexes", true, func(ctx context.Context) (string, error) {
+			if missingIndexes, indexesErr = repository.MissingIndexes(ctx, indexedRepos...); indexesErr != nil {
+				return "", indexesErr
+			}
+			return indexReport(missingIndexes, true)
+		})
+		selfCheck.Add("indexes", false, func(context.Context) (string, error) {
+			if indexesErr != nil {
+				return "", indexesErr
+			}
+			return indexReport(missingIndexes, false)
+		})
+		selfCheck.Add("migrations", false, func(ctx context.Context) (string, error) {
+			pending, err := userRepo.PendingMigrations(ctx)
+			if err != nil {
+				return "", err
+			}
+			if len(pending) > 0 {
+				return "", fmt.Errorf("pending %s", strings.Join(pending, "; "))
+			}
+			return "none pending", nil
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		report := selfCheck.Run(ctx)
+		cancel()
+		log.Printf("Startup self-check:\n%s", report)
+		if report.Failed() && mode != "warn" {
+			log.Fatal("Startup self-check failed, set STARTUP_CHECK=warn to start anyway")
+		}
+	case "off":
+	default:
+		log.Fatalf("Invalid STARTUP_CHECK value %q: must be enforce, warn or off", mode)
+	}
+
+	// Impersonation tokens are deliberately short-lived, default to 15m
+	impersonationTTL := 15 * time.Minute
+	if ttl := os.Getenv("IMPERSONATION_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil {
+			log.Fatalf("Invalid IMPERSONATION_TTL value %q: %v", ttl, err)
+		}
+		impersonationTTL = parsed
+	}
+
+	// Sensitive routes need a password or second factor proven within the last 15m by default
+	recentAuthMaxAge := 15 * time.Minute
+	if maxAge := os.Getenv("RECENT_AUTH_MAX_AGE"); maxAge != "" {
+		parsed, err := time.ParseDuration(maxAge)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("Invalid RECENT_AUTH_MAX_AGE value %q: must be a positive duration", maxAge)
+		}
+		recentAuthMaxAge = parsed
+	}
+
+	// Logins answer bearer tokens by default, or set session cookies for first-party web apps
+	authMode, err := domain.ParseAuthMode(os.Getenv("AUTH_MODE"))
+	if err != nil {
+		log.Fatalf("Invalid AUTH_MODE value %q: %v", os.Getenv("AUTH_MODE"), err)
+	}
+	sessionTTL := 12 * time.Hour
+	if ttl := os.Getenv("SESSION_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("Invalid SESSION_TTL value %q: must be a positive duration", ttl)
+		}
+		sessionTTL = parsed
+	}
+
+	// Configure custom metadata limits from environment variables
+	metadataPolicy := domain.DefaultMetadataPolicy()
+	if keys := os.Getenv("METADATA_ALLOWED_KEYS"); keys != "" {
+		for _, key := range strings.Split(keys, ",") {
+			metadataPolicy.AllowedKeys = append(metadataPolicy.AllowedKeys, strings.TrimSpace(key))
+		}
+	}
+	if maxLen := os.Getenv("METADATA_MAX_VALUE_LENGTH"); maxLen != "" {
+		parsed, err := strconv.Atoi(maxLen)
+		if err != nil || parsed < 1 {
+			log.Fatalf("Invalid METADATA_MAX_VALUE_LENGTH value %q", maxLen)
+		}
+		metadataPolicy.MaxValueLength = parsed
+	}
+
+	// Configure the password policy enforced on registration from environment variables
+	passwordPolicy := domain.DefaultPasswordPolicy()
+	if minLen := os.Getenv("PASSWORD_MIN_LENGTH"); minLen != "" {
+		parsed, err := strconv.Atoi(minLen)
+		if err != nil || parsed < 1 || parsed > domain.MaxPasswordBytes {
+			log.Fatalf("Invalid PASSWORD_MIN_LENGTH value %q: must be between 1 and %d", minLen, domain.MaxPasswordBytes)
+		}
+		passwordPolicy.MinLength = parsed
+	}
+	if minScore := os.Getenv("PASSWORD_MIN_SCORE"); minScore != "" {
+		parsed, err := strconv.Atoi(minScore)
+		if err != nil || parsed < 0 || parsed > 4 {
+			log.Fatalf("Invalid PASSWORD_MIN_SCORE value %q: must be between 0 and 4", minScore)
+		}
+		passwordPolicy.MinScore = parsed
+	}
+
+	// Look new passwords up in known data breaches with the Have I Been Pwned range API
+	// (PASSWORD_BREACH_CHECK=warn or reject), accepting them unchecked after PASSWORD_BREACH_TIMEOUT
+	passwordBreaches := ports.PasswordBreachCheck{Timeout: durationFromEnv("PASSWORD_BREACH_TIMEOUT", 2*time.Second)}
+	switch check := os.Getenv("PASSWORD_BREACH_CHECK"); check {
+	case "", "off":
+	case "warn", "reject":
+		baseURL := os.Getenv("HIBP_API_URL")
+		if baseURL == "" {
+			baseURL = "https://api.pwnedpasswords.com"
+		}
+		passwordBreaches.Checker = breach.NewHIBPChecker(baseURL, "user-management-api")
+		passwordBreaches.Reject = check == "reject"
+	default:
+		log.Fatalf("Invalid PASSWORD_BREACH_CHECK value %q: must be off, warn or reject", check)
+	}
+
+	// Upserts of the external systems of record overwrite the values of existing users by default
+	upsertPolicy, err := domain.ParseUpsertPolicy(os.Getenv("UPSERT_CONFLICT_POLICY"))
+	if err != nil {
+		log.Fatalf("Invalid UPSERT_CONFLICT_POLICY value %q: %v", os.Getenv("UPSERT_CONFLICT_POLICY"), err)
+	}
+
+	// Configure the custom registration fields, a JSON array of definitions such as
+	// [{"name":"department","required":true,"options":["sales","engineering"]}]
+	customFields, err := domain.ParseCustomFieldSchema(os.Getenv("REGISTRATION_FIELDS"))
+	if err != nil {
+		log.Fatalf("Invalid REGISTRATION_FIELDS value: %v", err)
+	}
+
+	// Configure the email domains allowed and denied to register in every tenant (comma-separated),
+	// next to the ones admins add at runtime
+	var allowedDomains, deniedDomains []string
+	if domains := os.Getenv("EMAIL_DOMAIN_ALLOWLIST"); domains != "" {
+		allowedDomains = strings.Split(domains, ",")
+	}
+	if domains := os.Getenv("EMAIL_DOMAIN_DENYLIST"); domains != "" {
+		deniedDomains = strings.Split(domains, ",")
+	}
+	emailDomainPolicy, err := domain.NewEmailDomainPolicy(allowedDomains, deniedDomains)
+	if err != nil {
+		log.Fatalf("Invalid EMAIL_DOMAIN_ALLOWLIST or EMAIL_DOMAIN_DENYLIST value: %v", err)
+	}
+
+	// Configure the minimum age checked against birthdates, disabled when MINIMUM_AGE is unset or 0
+	var agePolicy domain.AgePolicy
+	if minAge := os.Getenv("MINIMUM_AGE"); minAge != "" {
+		parsed, err := strconv.Atoi(minAge)
+		if err != nil || parsed < 0 || parsed > 150 {
+			log.Fatalf("Invalid MINIMUM_AGE value %q: must be between 0 and 150", minAge)
+		}
+		agePolicy.MinimumAge = parsed
+	}
+
+	// Configure the optional geocoding of addresses (GEOCODER=google or nominatim)
+	var geocoding ports.AddressGeocoding
+	switch geocoder := os.Getenv("GEOCODER"); geocoder {
+	case "":
+	case "google":
+		apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
+		if apiKey == "" {
+			log.Fatal("GOOGLE_MAPS_API_KEY is required when GEOCODER=google")
+		}
+		geocoding.Geocoder = geocodingadapter.NewGoogleGeocoder(apiKey)
+	case "nominatim":
+		baseURL := os.Getenv("NOMINATIM_URL")
+		if baseURL == "" {
+			baseURL = "https://nominatim.openstreetmap.org"
+		}
+		userAgent := os.Getenv("GEOCODER_USER_AGENT")
+		if userAgent == "" {
+			userAgent = "user-management-api"
+		}
+		geocoding.Geocoder = geocodingadapter.NewNominatimGeocoder(baseURL, userAgent)
+	default:
+		log.Fatalf("Invalid GEOCODER value %q: must be google or nominatim", geocoder)
+	}
+	geocoding.RejectUnknown = os.Getenv("GEOCODER_REJECT_UNKNOWN") == "true"
+
+	// Configure the optional external identity provider whose tokens are accepted next to the
+	// tokens of the API (AUTH_PROVIDER=firebase or keycloak)
+	var externalTokens ports.TokenVerifier
+	switch provider := os.Getenv("AUTH_PROVIDER"); provider {
+	case "":
+	case "firebase":
+		projectID := os.Getenv("FIREBASE_PROJECT_ID")
+		if projectID == "" {
+			log.Fatal("FIREBASE_PROJECT_ID is required when AUTH_PROVIDER=firebase")
+		}
+		externalTokens = identity.NewFirebaseVerifier(projectID)
+	case "keycloak":
+		realmURL, clientID := os.Getenv("KEYCLOAK_REALM_URL"), os.Getenv("KEYCLOAK_CLIENT_ID")
+		if realmURL == "" || clientID == "" {
+			log.Fatal("KEYCLOAK_REALM_URL and KEYCLOAK_CLIENT_ID are required when AUTH_PROVIDER=keycloak")
+		}
+		externalTokens = identity.NewKeycloakVerifier(realmURL, clientID)
+	default:
+		log.Fatalf("Invalid AUTH_PROVIDER value %q: must be firebase or keycloak", provider)
+	}
+
+	// Configure the clients allowed to introspect tokens ("<client id>:<secret>,..."), the introspection
+	// endpoint is disabled when INTROSPECTION_CLIENTS is not set
+	var introspectionClients handler.ClientCredentials
+	if clients := os.Getenv("INTROSPECTION_CLIENTS"); clients != "" {
+		introspectionClients = make(handler.ClientCredentials)
+		for _, client := range strings.Split(clients, ",") {
+			id, secret, found := strings.Cut(strings.TrimSpace(client), ":")
+			if !found || id == "" || secret == "" {
+				log.Fatal("Invalid INTROSPECTION_CLIENTS value: must be comma-separated <client id>:<secret> pairs")
+			}
+			introspectionClients[id] = secret
+		}
+	}
+
+	// Configure tenant resolution from environment variables
+	tenancy := handler.TenantResolver{
+		BaseDomain:    os.Getenv("TENANT_BASE_DOMAIN"),
+		Header:        os.Getenv("TENANT_HEADER"),
+		DefaultTenant: os.Getenv("DEFAULT_TENANT"),
+	}
+	if tenancy.Header == "" {
+		tenancy.Header = "X-Tenant-ID"
+	}
+	if _, set := os.LookupEnv("DEFAULT_TENANT"); !set {
+		tenancy.DefaultTenant = domain.DefaultTenantID
+	}
+
+	// Configure request body limits from environment variables
+	bodyLimits := handler.DefaultBodyLimits()
+	if maxBytes := os.Getenv("MAX_BODY_BYTES"); maxBytes != "" {
+		parsed, err := strconv.ParseInt(maxBytes, 10, 64)
+		if err != nil || parsed < 1 {
+			log.Fatalf("Invalid MAX_BODY_BYTES value %q", maxBytes)
+		}
+		bodyLimits.MaxBytes = parsed
+	}
+	if maxBytes := os.Getenv("MAX_UPLOAD_BYTES"); maxBytes != "" {
+		parsed, err := strconv.ParseInt(maxBytes, 10, 64)
+		if err != nil || parsed < 1 {
+			log.Fatalf("Invalid MAX_UPLOAD_BYTES value %q", maxBytes)
+		}
+		bodyLimits.MaxUploadBytes = parsed
+	}
+	if maxDepth := os.Getenv("MAX_JSON_DEPTH"); maxDepth != "" {
+		parsed, err := strconv.Atoi(maxDepth)
+		if err != nil || parsed < 1 {
+			log.Fatalf("Invalid MAX_JSON_DEPTH value %q", maxDepth)
+		}
+		bodyLimits.MaxJSONDepth = parsed
+	}
+
+	// Deadlines of read and write requests, so a stuck database call does not hold the handler
+	requestTimeouts := handler.DefaultRequestTimeouts()
+	requestTimeouts.Read = durationFromEnv("REQUEST_READ_TIMEOUT", requestTimeouts.Read)
+	requestTimeouts.Write = durationFromEnv("REQUEST_WRITE_TIMEOUT", requestTimeouts.Write)
+
+	// Initialize rate limiting: shared through Redis when REDIS_URL is set, in memory otherwise
+	var rateLimiter ports.RateLimiter = ratelimit.NewMemoryRateLimiter()
+	if redisURL := os.Getenv("REDIS_URL"); redisURL != "" {
+		redisOpts, err := redis.ParseURL(redisURL)
+		if err != nil {
+			log.Fatalf("Invalid REDIS_URL value: %v", err)
+		}
+		redisClient := redis.NewClient(redisOpts)
+		defer redisClient.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := redisClient.Ping(ctx); err != nil {
+			log.Fatalf("Error connecting to Redis: %v", err)
+		}
+		cancel()
+		log.Println("Connected to Redis, rate limits are shared across instances")
+		redisRateLimiter := ratelimit.NewRedisRateLimiter(redisClient)
+		rateLimiter = redisRateLimiter
+		healthChecks = append(healthChecks, ports.HealthCheck{Name: "redis", Checker: redisRateLimiter})
+	}
+
+	// Configure what is reloaded without restarting, on SIGHUP or from the admin routes: the log level
+	// of the request log, the rate limits of each endpoint class ("<requests>/<window>"), the feature
+	// flags and the CORS origins
+	runtimeConfigEnv, err := runtimeConfigFromEnv(os.LookupEnv)
+	if err != nil {
+		log.Fatal(err)
+	}
+	runtimeConfig := ports.RuntimeConfig{
+		LogLevel: ports.LogLevelInfo,
+		RateLimits: ports.RuntimeRateLimits{
+			API:        ports.RateLimit{Requests: 300, Window: time.Minute},
+			Auth:       ports.RateLimit{Requests: 10, Window: time.Minute},
+			EmailCheck: ports.RateLimit{Requests: 10, Window: time.Minute},
+		},
+	}.Updated(runtimeConfigEnv)
+	configTenant := os.Getenv("CONFIG_ADMIN_TENANT")
+	if configTenant == "" {
+		configTenant = domain.DefaultTenantID
+	}
+	configUseCase, err := usecase.NewConfigUseCase(runtimeConfig, usecase.NewAuditUseCase(auditEvents), configTenant)
+	if err != nil {
+		log.Fatalf("Invalid runtime configuration: %v", err)
+	}
+
+	// Configure the blocking of IPs with repeated failed logins
+	backoffPolicy := ports.DefaultBackoffPolicy()
+	if threshold := os.Getenv("AUTH_IP_FAILURE_THRESHOLD"); threshold != "" {
+		parsed, err := strconv.Atoi(threshold)
+		if err != nil || parsed < 1 {
+			log.Fatalf("Invalid AUTH_IP_FAILURE_THRESHOLD value %q", threshold)
+		}
+		backoffPolicy.Threshold = parsed
+	}
+	if delay := os.Getenv("AUTH_IP_BACKOFF_BASE"); delay != "" {
+		parsed, err := time.ParseDuration(delay)
+		if err != nil {
+			log.Fatalf("Invalid AUTH_IP_BACKOFF_BASE value %q: %v", delay, err)
+		}
+		backoffPolicy.BaseDelay = parsed
+	}
+	if delay := os.Getenv("AUTH_IP_BACKOFF_MAX"); delay != "" {
+		parsed, err := time.ParseDuration(delay)
+		if err != nil {
+			log.Fatalf("Invalid AUTH_IP_BACKOFF_MAX value %q: %v", delay, err)
+		}
+		backoffPolicy.MaxDelay = parsed
+	}
+	ipBackoff := ratelimit.NewMemoryIPBackoff(backoffPolicy)
+
+	// Email availability checks can be delayed randomly to hide response time differences
+	var emailCheckMaxDelay time.Duration
+	if delay := os.Getenv("EMAIL_CHECK_MAX_DELAY"); delay != "" {
+		parsed, err := time.ParseDuration(delay)
+		if err != nil {
+			log.Fatalf("Invalid EMAIL_CHECK_MAX_DELAY value %q: %v", delay, err)
+		}
+		emailCheckMaxDelay = parsed
+	}
+
+	// Configure how often the last-seen time of active users is written, default 5m
+	lastSeenInterval := 5 * time.Minute
+	if interval := os.Getenv("LAST_SEEN_INTERVAL"); interval != "" {
+		parsed, err := time.ParseDuration(interval)
+		if err != nil {
+			log.Fatalf("Invalid LAST_SEEN_INTERVAL value %q: %v", interval, err)
+		}
+		lastSeenInterval = parsed
+	}
+
+	// Mount the pprof endpoints when PPROF_ENABLED is true
+	profiling := false
+	if enabled := os.Getenv("PPROF_ENABLED"); enabled != "" {
+		parsed, err := strconv.ParseBool(enabled)
+		if err != nil {
+			log.Fatalf("Invalid PPROF_ENABLED value %q: must be true or false", enabled)
+		}
+		profiling = parsed
+	}
+
+	// Log the requests and responses not matching the Swagger documentation when OPENAPI_VALIDATION is true
+	var contract *openapi.Spec
+	if enabled := os.Getenv("OPENAPI_VALIDATION"); enabled != "" {
+		validate, err := strconv.ParseBool(enabled)
+		if err != nil {
+			log.Fatalf("Invalid OPENAPI_VALIDATION value %q: must be true or false", enabled)
+		}
+		if validate {
+			contract, err = handler.LoadContract([]byte(docs.SwaggerInfo.ReadDoc()))
+			if err != nil {
+				log.Fatalf("Failed to load the Swagger documentation: %v", err)
+			}
+			log.Println("Validating requests and responses against the Swagger documentation")
+		}
+	}
+
+	// Serve the Swagger document updated to the running binary at /api/v1/openapi.json when OPENAPI_ENDPOINT_ENABLED is true
+	var openAPIDocument []byte
+	if enabled := os.Getenv("OPENAPI_ENDPOINT_ENABLED"); enabled != "" {
+		serve, err := strconv.ParseBool(enabled)
+		if err != nil {
+			log.Fatalf("Invalid OPENAPI_ENDPOINT_ENABLED value %q: must be true or false", enabled)
+		}
+		if serve {
+			openAPIDocument = []byte(docs.SwaggerInfo.ReadDoc())
+		}
+	}
+
+	// Mount the admin dashboard under /admin/ when ADMIN_DASHBOARD_ENABLED is true
+	adminDashboard := boolFromEnv("ADMIN_DASHBOARD_ENABLED", false)
+
+	// Mount the Swagger UI when SWAGGER_ENABLED is true, by default outside of GIN_MODE=release, and
+	// protect the documentation with SWAGGER_USERNAME and SWAGGER_PASSWORD or admin tokens
+	swaggerEnabled := gin.Mode() != gin.ReleaseMode
+	if enabled := os.Getenv("SWAGGER_ENABLED"); enabled != "" {
+		parsed, err := strconv.ParseBool(enabled)
+		if err != nil {
+			log.Fatalf("Invalid SWAGGER_ENABLED value %q: must be true or false", enabled)
+		}
+		swaggerEnabled = parsed
+	}
+	docsAuth := routes.DocsAuth{
+		Username: os.Getenv("SWAGGER_USERNAME"),
+		Password: os.Getenv("SWAGGER_PASSWORD"),
+	}
+	if (docsAuth.Username == "") != (docsAuth.Password == "") {
+		log.Fatal("SWAGGER_USERNAME and SWAGGER_PASSWORD must be set together")
+	}
+	if requireAdmin := os.Getenv("SWAGGER_REQUIRE_ADMIN"); requireAdmin != "" {
+		parsed, err := strconv.ParseBool(requireAdmin)
+		if err != nil {
+			log.Fatalf("Invalid SWAGGER_REQUIRE_ADMIN value %q: must be true or false", requireAdmin)
+		}
+		if parsed && docsAuth.Username != "" {
+			log.Fatal("SWAGGER_REQUIRE_ADMIN cannot be combined with SWAGGER_USERNAME")
+		}
+		docsAuth.Admin = parsed
+	}
+
+	// Configure the mailer from environment variables, emails are only logged when SMTP_ADDR is not set
+	var mailer ports.Mailer = mail.NewLogMailer()
+	if smtpAddr := os.Getenv("SMTP_ADDR"); smtpAddr != "" {
+		from := os.Getenv("MAIL_FROM")
+		if from == "" {
+			log.Fatal("MAIL_FROM environment variable is required when SMTP_ADDR is set")
+		}
+		smtpMailer := mail.NewSMTPMailer(smtpAddr, from, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"))
+		mailer = smtpMailer
+		healthChecks = append(healthChecks, ports.HealthCheck{Name: "mail", Checker: smtpMailer})
+	}
+
+	// Configure the SMS sender of the phone verification and login codes, which are only logged when
+	// SMS_PROVIDER is not set; codes are valid for PHONE_CODE_TTL (default 10m)
+	var smsSender ports.SMSSender = sms.NewLogSender()
+	switch provider := os.Getenv("SMS_PROVIDER"); provider {
+	case "":
+	case "twilio":
+		accountSID, authToken, from := os.Getenv("TWILIO_ACCOUNT_SID"), os.Getenv("TWILIO_AUTH_TOKEN"), os.Getenv("TWILIO_FROM")
+		if accountSID == "" || authToken == "" || from == "" {
+			log.Fatal("TWILIO_ACCOUNT_SID, TWILIO_AUTH_TOKEN and TWILIO_FROM environment variables are required when SMS_PROVIDER=twilio")
+		}
+		apiURL := os.Getenv("TWILIO_API_URL")
+		if apiURL == "" {
+			apiURL = sms.TwilioAPIURL
+		}
+		smsSender = sms.NewTwilioSender(accountSID, authToken, from, apiURL)
+	default:
+		log.Fatalf("Invalid SMS_PROVIDER value %q: must be twilio", provider)
+	}
+	phoneCodeTTL := 10 * time.Minute
+	if ttl := os.Getenv("PHONE_CODE_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil || parsed < time.Minute {
+			log.Fatalf("Invalid PHONE_CODE_TTL value %q: must be at least 1m", ttl)
+		}
+		phoneCodeTTL = parsed
+	}
+	// Bound the text messages sent to each phone, which the provider bills (RATE_LIMIT_SMS)
+	smsSender = sms.NewRateLimitedSender(smsSender, rateLimiter, rateLimitFromEnv("RATE_LIMIT_SMS", ports.RateLimit{Requests: 5, Window: time.Hour}))
+
+	// Name the TOTP factors in authenticator apps, and skip the second factor on trusted devices for
+	// TRUSTED_DEVICE_TTL (default 30 days)
+	mfaIssuer := os.Getenv("MFA_ISSUER")
+	if mfaIssuer == "" {
+		mfaIssuer = "User Management API"
+	}
+	trustedDeviceTTL := 30 * 24 * time.Hour
+	if ttl := os.Getenv("TRUSTED_DEVICE_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil || parsed < time.Hour {
+			log.Fatalf("Invalid TRUSTED_DEVICE_TTL value %q: must be at least 1h", ttl)
+		}
+		trustedDeviceTTL = parsed
+	}
+
+	// Run background jobs stored in the database with a pool of workers on every instance, retrying
+	// failed runs; emails are queued as jobs so they survive restarts and mail server outages
+	jobPolicy := usecase.DefaultJobPolicy()
+	if workers := os.Getenv("JOB_WORKERS"); workers != "" {
+		n, err := strconv.Atoi(workers)
+		if err != nil || n < 1 {
+			log.Fatalf("Invalid JOB_WORKERS value %q: must be a positive number", workers)
+		}
+		jobPolicy.Workers = n
+	}
+	if attempts := os.Getenv("JOB_MAX_ATTEMPTS"); attempts != "" {
+		n, err := strconv.Atoi(attempts)
+		if err != nil || n < 1 {
+			log.Fatalf("Invalid JOB_MAX_ATTEMPTS value %q: must be a positive number", attempts)
+		}
+		jobPolicy.MaxAttempts = n
+	}
+	jobPolicy.PollInterval = durationFromEnv("JOB_POLL_INTERVAL", jobPolicy.PollInterval)
+	jobPolicy.RetryBaseDelay = durationFromEnv("JOB_RETRY_BASE_DELAY", jobPolicy.RetryBaseDelay)
+	jobPolicy.RetryMaxDelay = durationFromEnv("JOB_RETRY_MAX_DELAY", jobPolicy.RetryMaxDelay)
+	jobPolicy.Timeout = durationFromEnv("JOB_TIMEOUT", jobPolicy.Timeout)
+	jobUseCase := usecase.NewJobUseCase(repository.NewJobRepository(dbClient, "jobs"), usecase.NewAuditUseCase(auditEvents), jobPolicy)
+	jobUseCase.Register(domain.JobTypeEmail, mail.DeliveryHandler(mailer))
+	mailer = mail.NewQueueMailer(jobUseCase)
+	healthChecks = append(healthChecks, ports.HealthCheck{Name: "job_workers", Checker: jobUseCase})
+
+	// Enqueue the periodic jobs at the times of their cron schedules, on every instance: each job is
+	// enqueued once and run by a single instance. Starts are delayed by up to SCHEDULER_JITTER.
+	scheduler := usecase.NewScheduler(durationFromEnv("SCHEDULER_JITTER", 30*time.Second))
+
+	// Get the address of the "wasn't me" link of new-device login emails
+	loginReportURL := os.Getenv("LOGIN_REPORT_URL")
+	if loginReportURL == "" {
+		loginReportURL = "http://localhost:8080/api/v1/auth/login-report"
+	}
+
+	// Load the MaxMind database locating login IP addresses, geolocation is disabled when GEOIP_DB_PATH is not set
+	var geoIP ports.GeoIPResolver
+	if path := os.Getenv("GEOIP_DB_PATH"); path != "" {
+		resolver, err := geoip.NewMaxMindResolver(path)
+		if err != nil {
+			log.Fatalf("Error loading GeoIP database %q: %v", path, err)
+		}
+		geoIP = resolver
+	}
+
+	// Configure the rules comparing each login with the previous one, which need GeoIP locations:
+	// LOGIN_ANOMALY_RULES maps rules to actions, LOGIN_ANOMALY_MAX_SPEED is the fastest travel in km/h
+	loginAnomalyPolicy := domain.DefaultLoginAnomalyPolicy()
+	if rules := os.Getenv("LOGIN_ANOMALY_RULES"); rules != "" {
+		parsed, err := domain.ParseLoginAnomalyRules(rules)
+		if err != nil {
+			log.Fatalf("Invalid LOGIN_ANOMALY_RULES value %q: %v", rules, err)
+		}
+		loginAnomalyPolicy.Rules = parsed
+	}
+	if speed := os.Getenv("LOGIN_ANOMALY_MAX_SPEED"); speed != "" {
+		parsed, err := strconv.ParseFloat(speed, 64)
+		if err != nil || !(parsed > 0) {
+			log.Fatalf("Invalid LOGIN_ANOMALY_MAX_SPEED value %q: must be a positive number of km/h", speed)
+		}
+		loginAnomalyPolicy.MaxTravelSpeed = parsed
+	}
+
+	// Load the embedded message catalogs used to localize error responses
+	catalog, err := i18n.Load()
+	if err != nil {
+		log.Fatalf("Error loading message catalogs: %v", err)
+	}
+
+	// Get media directory for uploaded files from environment variable, default to ./uploads
+	mediaDir := os.Getenv("MEDIA_DIR")
+	if mediaDir == "" {
+		mediaDir = "./uploads"
+	}
+
+	// Initialize avatar processing with local file storage and start the image-processing workers
+	fileStorage := storage.NewLocalFileStorage(mediaDir, "/media")
+	avatarUseCase := usecase.NewAvatarUseCase(historyUserRepo, fileStorage, 100)
+	avatarUseCase.Start(2)
+
+	// Identity documents are kept out of the public media directory, in DOCUMENTS_DIR (default
+	// ./documents), and only served through download URLs signed with DOCUMENT_URL_SECRET, valid for
+	// DOCUMENT_URL_TTL (default 5m). Without a secret a random one is used, whose URLs only work on
+	// this instance until it restarts.
+	documentsDir := os.Getenv("DOCUMENTS_DIR")
+	if documentsDir == "" {
+		documentsDir = "./documents"
+	}
+	documentURLSecret := []byte(os.Getenv("DOCUMENT_URL_SECRET"))
+	if len(documentURLSecret) == 0 {
+		log.Println("Warning: DOCUMENT_URL_SECRET is not set, document download URLs only work on this instance until it restarts")
+		documentURLSecret = make([]byte, 32)
+		if _, err := rand.Read(documentURLSecret); err != nil {
+			log.Fatalf("Error generating the document URL secret: %v", err)
+		}
+	}
+	documentURLTTL := 5 * time.Minute
+	if ttl := os.Getenv("DOCUMENT_URL_TTL"); ttl != "" {
+		parsed, err := time.ParseDuration(ttl)
+		if err != nil || parsed <= 0 {
+			log.Fatalf("Invalid DOCUMENT_URL_TTL value %q", ttl)
+		}
+		documentURLTTL = parsed
+	}
+	documentStorage := storage.NewSignedLocalFileStorage(documentsDir, "/documents", documentURLSecret)
+
+	// Configure the optional automated checks of the documents submitted for verification
+	// (IDENTITY_VERIFIER=sandbox or onfido), whose results the provider posts to the webhook
+	var identityVerifier ports.IdentityVerifier
+	if provider := os.Getenv("IDENTITY_VERIFIER"); provider != "" {
+		webhookSecret := []byte(os.Getenv("IDENTITY_VERIFIER_WEBHOOK_SECRET"))
+		if len(webhookSecret) == 0 {
+			log.Fatal("IDENTITY_VERIFIER_WEBHOOK_SECRET is required when IDENTITY_VERIFIER is set")
+		}
+		switch provider {
+		case "sandbox":
+			identityVerifier = kyc.NewSandboxVerifier(webhookSecret)
+		case "onfido":
+			apiToken := os.Getenv("ONFIDO_API_TOKEN")
+			if apiToken == "" {
+				log.Fatal("ONFIDO_API_TOKEN is required when IDENTITY_VERIFIER=onfido")
+			}
+			apiURL := os.Getenv("ONFIDO_API_URL")
+			if apiURL == "" {
+				apiURL = "https://api.eu.onfido.com/v3.6"
+			}
+			identityVerifier = kyc.NewOnfidoVerifier(apiToken, webhookSecret, apiURL)
+		default:
+			log.Fatalf("Invalid IDENTITY_VERIFIER value %q: must be sandbox or onfido", provider)
+		}
+	}
+
+	// Sync the accounts of a tenant with an LDAP directory, the sync is disabled when LDAP_URL is not set
+	var directorySyncUseCase *usecase.DirectorySyncUseCase
+	if ldapURL := os.Getenv("LDAP_URL"); ldapURL != "" {
+		baseDN := os.Getenv("LDAP_BASE_DN")
+		if baseDN == "" {
+			log.Fatal("LDAP_BASE_DN environment variable is required when LDAP_URL is set")
+		}
+		attributes := directory.DefaultLDAPAttributes()
+		filter := "(&(objectClass=inetOrgPerson)(mail=*))"
+		if activeDirectory := os.Getenv("LDAP_ACTIVE_DIRECTORY"); activeDirectory != "" {
+			parsed, err := strconv.ParseBool(activeDirectory)
+			if err != nil {
+				log.Fatalf("Invalid LDAP_ACTIVE_DIRECTORY value %q: must be true or false", activeDirectory)
+			}
+			if parsed {
+				attributes = directory.ActiveDirectoryAttributes()
+				filter = "(&(objectCategory=person)(objectClass=user)(mail=*))"
+			}
+		}
+		if userFilter := os.Getenv("LDAP_USER_FILTER"); userFilter != "" {
+			filter = userFilter
+		}
+		source := directory.NewLDAPSource(ldap.Options{
+			URL:          ldapURL,
+			BindDN:       os.Getenv("LDAP_BIND_DN"),
+			BindPassword: os.Getenv("LDAP_BIND_PASSWORD"),
+			Timeout:      durationFromEnv("LDAP_TIMEOUT", 30*time.Second),
+		}, baseDN, filter, attributes)
+
+		syncTenant := os.Getenv("DIRECTORY_SYNC_TENANT")
+		if syncTenant == "" {
+			syncTenant = domain.DefaultTenantID
+		}
+		dryRun := false
+		if value := os.Getenv("DIRECTORY_SYNC_DRY_RUN"); value != "" {
+			if dryRun, err = strconv.ParseBool(value); err != nil {
+				log.Fatalf("Invalid DIRECTORY_SYNC_DRY_RUN value %q: must be true or false", value)
+			}
+		}
+		directorySyncUseCase = usecase.NewDirectorySyncUseCase(source, historyUserRepo, usecase.NewAuditUseCase(auditEvents), syncTenant)
+		jobUseCase.Register(domain.JobTypeDirectorySync, directorySyncUseCase.RunJob)
+		scheduler.Add(domain.JobTypeDirectorySync, scheduleFromEnv("SCHEDULE_DIRECTORY_SYNC", "DIRECTORY_SYNC_INTERVAL", "0 * * * *"),
+			jobUseCase.ScheduledTask(syncTenant, domain.JobTypeDirectorySync, map[string]string{"dry_run": strconv.FormatBool(dryRun)}))
+	}
+
+	// Purge the data of every tenant past its retention window in a scheduled job; unset windows keep
+	// the data forever
+	retentionPolicy := domain.RetentionPolicy{
+		DeletedUsers:       daysFromEnv("RETENTION_DELETED_USERS_DAYS"),
+		DeletedUsersAction: os.Getenv("RETENTION_DELETED_USERS_ACTION"),
+		LoginEvents:        daysFromEnv("RETENTION_LOGIN_EVENTS_DAYS"),
+	}
+	if retentionPolicy.DeletedUsersAction == "" {
+		retentionPolicy.DeletedUsersAction = domain.RetentionDelete
+	}
+	if err := retentionPolicy.Validate(); err != nil {
+		log.Fatalf("Invalid RETENTION_DELETED_USERS_ACTION value: %v", err)
+	}
+	retentionDryRun := false
+	if value := os.Getenv("RETENTION_DRY_RUN"); value != "" {
+		if retentionDryRun, err = strconv.ParseBool(value); err != nil {
+			log.Fatalf("Invalid RETENTION_DRY_RUN value %q: must be true or false", value)
+		}
+	}
+	retentionUseCase := usecase.NewRetentionUseCase(repository.NewRetentionRepository(databases, "users", "login_events"), retentionPolicy)
+	jobUseCase.Register(domain.JobTypeRetentionPurge, retentionUseCase.RunJob)
+	scheduler.Add(domain.JobTypeRetentionPurge, scheduleFromEnv("SCHEDULE_RETENTION_PURGE", "RETENTION_INTERVAL", "0 3 * * *"),
+		jobUseCase.ScheduledTask(domain.DefaultTenantID, domain.JobTypeRetentionPurge, map[string]string{"dry_run": strconv.FormatBool(retentionDryRun)}))
+
+	// Roll up the daily user statistics of every tenant in a scheduled job, after the day is over
+	statsUseCase := usecase.NewStatsUseCase(repository.NewStatsRepository(databases, "user_stats", "users", "login_events"))
+	jobUseCase.Register(domain.JobTypeStatsRollup, statsUseCase.RunJob)
+	scheduler.Add(domain.JobTypeStatsRollup, scheduleFromEnv("SCHEDULE_STATS_ROLLUP", "", "15 0 * * *"),
+		jobUseCase.ScheduledTask(domain.DefaultTenantID, domain.JobTypeStatsRollup, nil))
+
+	// Email the admins of every tenant a summary of the account activity of the last day when
+	// ADMIN_DIGEST_ENABLED is true
+	if enabled := os.Getenv("ADMIN_DIGEST_ENABLED"); enabled != "" {
+		parsed, err := strconv.ParseBool(enabled)
+		if err != nil {
+			log.Fatalf("Invalid ADMIN_DIGEST_ENABLED value %q: must be true or false", enabled)
+		}
+		if parsed {
+			digestUseCase := usecase.NewDigestUseCase(repository.NewDigestRepository(databases, "users", "audit_logs"), mailer)
+			jobUseCase.Register(domain.JobTypeAdminDigest, digestUseCase.RunJob)
+			scheduler.Add(domain.JobTypeAdminDigest, scheduleFromEnv("SCHEDULE_ADMIN_DIGEST", "", "0 7 * * *"),
+				jobUseCase.ScheduledTask(domain.DefaultTenantID, domain.JobTypeAdminDigest, nil))
+		}
+	}
+	jobUseCase.Start()
+	scheduler.Start()
+
+	// Initialize Gin HTTP router with the request log, down to the configured log level, and recovery
+	router := gin.New()
+	router.Use(handler.LogRequests(configUseCase), gin.Recovery())
+	// Take the client IP from X-Forwarded-For only behind the proxies of TRUSTED_PROXIES (IPs or CIDRs
+	// separated by commas), other clients could pick the IP their rate limits and failed logins count
+	// against
+	var trustedProxies []string
+	if proxies := os.Getenv("TRUSTED_PROXIES"); proxies != "" {
+		trustedProxies = strings.Split(proxies, ",")
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES value: %v", err)
+	}
+
+	// Serve stored media files (avatars) from the media directory
+	router.Static("/media", mediaDir)
+	// Serve identity documents to the holders of a signed download URL
+	router.GET("/documents/*key", gin.WrapH(http.StripPrefix("/documents", documentStorage)))
+
+	// A nil use case must stay a nil interface for the sync routes to be left out
+	var directorySync ports.DirectorySyncUseCase
+	if directorySyncUseCase != nil {
+		directorySync = directorySyncUseCase
+	}
+	var signingKeys ports.SigningKeyUseCase
+	if signingKeyUseCase != nil {
+		signingKeys = signingKeyUseCase
+	}
 
 	// Register all API routes and handlers
-	routes.RegisterRoutes(router, userRepo)
+	routes.RegisterRoutes(router, routes.Dependencies{
+		UserRepo:             users,
+		OrgRepo:              orgRepo,
+		RoleRepo:             roleRepo,
+		AuditRepo:            auditEvents,
+		LoginEvents:          loginEventRepo,
+		Sessions:             sessionRepo,
+		OAuthClients:         oauthClientRepo,
+		EmailDomains:         emailDomainRepo,
+		Terms:                termsRepo,
+		Relationships:        relationshipRepo,
+		Documents:            documentRepo,
+		UserHistory:          userHistoryRepo,
+		DocumentStorage:      documentStorage,
+		DocumentURLTTL:       documentURLTTL,
+		IdentityVerifier:     identityVerifier,
+		AvatarUseCase:        avatarUseCase,
+		DirectorySync:        directorySync,
+		SigningKeys:          signingKeys,
+		Jobs:                 jobUseCase,
+		Stats:                statsUseCase,
+		Scheduler:            scheduler,
+		Tokens:               tokens,
+		Mailer:               mailer,
+		SMS:                  smsSender,
+		PhoneCodeTTL:         phoneCodeTTL,
+		MFAIssuer:            mfaIssuer,
+		TrustedDeviceTTL:     trustedDeviceTTL,
+		LoginReportURL:       loginReportURL,
+		ExternalTokens:       externalTokens,
+		IntrospectionClients: introspectionClients,
+		GeoIP:                geoIP,
+		LoginAnomalyPolicy:   loginAnomalyPolicy,
+		ImpersonationTTL:     impersonationTTL,
+		RecentAuthMaxAge:     recentAuthMaxAge,
+		AuthMode:             authMode,
+		SessionTTL:           sessionTTL,
+		MetadataPolicy:       metadataPolicy,
+		PasswordPolicy:       passwordPolicy,
+		PasswordBreaches:     passwordBreaches,
+		AgePolicy:            agePolicy,
+		EmailDomainPolicy:    emailDomainPolicy,
+		CustomFields:         customFields,
+		UpsertPolicy:         upsertPolicy,
+		Geocoding:            geocoding,
+		Tenancy:              tenancy,
+		BodyLimits:           bodyLimits,
+		RequestTimeouts:      requestTimeouts,
+		RateLimiter:          rateLimiter,
+		IPBackoff:            ipBackoff,
+		Config:               configUseCase,
+		EmailCheckMaxDelay:   emailCheckMaxDelay,
+		LastSeenInterval:     lastSeenInterval,
+		DatabaseRetries:      retryingUserRepo,
+		DatabaseBreaker:      breakerUserRepo,
+		SlowQueries:          database.SlowQueries,
+		Profiling:            profiling,
+		Contract:             contract,
+		OpenAPI:              openAPIDocument,
+		Swagger:              swaggerEnabled,
+		DocsAuth:             docsAuth,
+		AdminDashboard:       adminDashboard,
+		HealthChecks:         healthChecks,
+		I18n:                 catalog,
+	})
 
 	// Get server port from environment variable, default to 8080
 	port := os.Getenv("PORT")
@@ -76,21 +1186,54 @@ func main() {
 		port = "8080"
 	}
 
-	// Configure HTTP server with timeout and handler
+	// Configure HTTP server with timeouts from environment variables, so slow clients cannot hold
+	// connections open; streaming exports lift the write timeout of their own response
 	srv := &http.Server{
-		Addr:    ":" + port,
-		Handler: router,
+		Addr:              ":" + port,
+		Handler:           router,
+		ReadHeaderTimeout: durationFromEnv("HTTP_READ_HEADER_TIMEOUT", 5*time.Second),
+		ReadTimeout:       durationFromEnv("HTTP_READ_TIMEOUT", 30*time.Second),
+		WriteTimeout:      durationFromEnv("HTTP_WRITE_TIMEOUT", 60*time.Second),
+		IdleTimeout:       durationFromEnv("HTTP_IDLE_TIMEOUT", 120*time.Second),
 	}
 
 	// Start HTTP server in a goroutine to allow for graceful shutdown
 	go func() {
 		log.Printf("🚀 Server starting on port %s", port)
-		log.Printf("📖 Swagger documentation available at http://localhost:%s/swagger/index.html", port)
+		if swaggerEnabled {
+			log.Printf("📖 Swagger documentation available at http://localhost:%s/swagger/index.html", port)
+		}
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("❌ Server failed to start: %v", err)
 		}
 	}()
 
+	// Reload the runtime configuration on SIGHUP from the .env file, the variables it does not set
+	// keeping their value
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			env, err := godotenv.Read()
+			if err != nil {
+				log.Printf("❌ Configuration not reloaded: %v", err)
+				continue
+			}
+			update, err := runtimeConfigFromEnv(func(name string) (string, bool) {
+				value, ok := env[name]
+				return value, ok
+			})
+			if err == nil {
+				_, err = configUseCase.ReloadConfig(context.Background(), update)
+			}
+			if err != nil {
+				log.Printf("❌ Configuration not reloaded: %v", err)
+				continue
+			}
+			log.Println("🔄 Configuration reloaded")
+		}
+	}()
+
 	// Setup graceful shutdown - wait for interrupt signal (Ctrl+C, SIGTERM)
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -106,5 +1249,253 @@ func main() {
 		log.Printf("❌ Server forced to shutdown: %v", err)
 	}
 
+	// Wait for queued avatar jobs to finish, stop scheduling jobs, cancel the running background jobs
+	// and directory sync, stop reloading the signing keys, stop syncing the read model and search index
+	// and write the audit events still buffered for export
+	avatarUseCase.Stop()
+	scheduler.Stop()
+	jobUseCase.Stop()
+	if directorySyncUseCase != nil {
+		directorySyncUseCase.Stop()
+	}
+	if signingKeyUseCase != nil {
+		signingKeyUseCase.Stop()
+	}
+	for _, projection := range projections {
+		projection.Stop()
+	}
+	if auditExport != nil {
+		auditExport.Stop()
+	}
+
 	log.Println("✅ Server shutdown complete")
 }
+
+// boolFromEnv parses the boolean of an environment variable, or returns fallback when it is unset
+// requiredEnv are the variables required by other variables: when is set, to value unless it is empty
+var requiredEnv = []struct {
+	when, value string
+	required    []string
+}{
+	{"GEOCODER", "google", []string{"GOOGLE_MAPS_API_KEY"}},
+	{"AUTH_PROVIDER", "firebase", []string{"FIREBASE_PROJECT_ID"}},
+	{"AUTH_PROVIDER", "keycloak", []string{"KEYCLOAK_REALM_URL", "KEYCLOAK_CLIENT_ID"}},
+	{"SWAGGER_USERNAME", "", []string{"SWAGGER_PASSWORD"}},
+	{"SWAGGER_PASSWORD", "", []string{"SWAGGER_USERNAME"}},
+	{"SMTP_ADDR", "", []string{"MAIL_FROM"}},
+	{"SMS_PROVIDER", "twilio", []string{"TWILIO_ACCOUNT_SID", "TWILIO_AUTH_TOKEN", "TWILIO_FROM"}},
+	{"IDENTITY_VERIFIER", "", []string{"IDENTITY_VERIFIER_WEBHOOK_SECRET"}},
+	{"IDENTITY_VERIFIER", "onfido", []string{"ONFIDO_API_TOKEN"}},
+	{"LDAP_URL", "", []string{"LDAP_BASE_DN"}},
+}
+
+// missingEnv returns the variables of requiredEnv that are not set, as "<variable> (required by <variable>)"
+func missingEnv() []string {
+	var missing []string
+	for _, rule := range requiredEnv {
+		value := os.Getenv(rule.when)
+		if value == "" || (rule.value != "" && value != rule.value) {
+			continue
+		}
+		by := rule.when
+		if rule.value != "" {
+			by += "=" + rule.value
+		}
+		for _, name := range rule.required {
+			if os.Getenv(name) == "" {
+				missing = append(missing, name+" (required by "+by+")")
+			}
+		}
+	}
+	return missing
+}
+
+// indexReport reports the missing indexes that are unique, or not
+func indexReport(missing []repository.MissingIndex, unique bool) (string, error) {
+	var names []string
+	for _, index := range missing {
+		if index.Unique == unique {
+			names = append(names, index.Database+"."+index.Collection+"."+index.Name)
+		}
+	}
+	if len(names) > 0 {
+		return "", fmt.Errorf("missing %s, run admincli reindex", strings.Join(names, ", "))
+	}
+	return "all present", nil
+}
+
+func boolFromEnv(name string, fallback bool) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid %s value %q: must be true or false", name, value)
+	}
+	return parsed
+}
+
+// durationFromEnv parses the positive duration of an environment variable, or returns fallback when it is unset
+func durationFromEnv(name string, fallback time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Fatalf("Invalid %s value %q: must be a positive duration", name, value)
+	}
+	return duration
+}
+
+// positiveIntFromEnv parses the positive number of an environment variable, or returns fallback when it is unset
+func positiveIntFromEnv(name string, fallback int) int {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		log.Fatalf("Invalid %s value %q: must be a positive number", name, value)
+	}
+	return n
+}
+
+// scheduleFromEnv parses the cron expression of an environment variable, see pkg/cron. When it is
+// unset, the schedule runs every interval of the legacyName variable if that is set, or else on fallback.
+func scheduleFromEnv(name, legacyName, fallback string) *cron.Schedule {
+	expr := os.Getenv(name)
+	if expr == "" && legacyName != "" && os.Getenv(legacyName) != "" {
+		expr = "@every " + durationFromEnv(legacyName, 0).String()
+	}
+	if expr == "" {
+		expr = fallback
+	}
+	schedule, err := cron.Parse(expr)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %v", name, err)
+	}
+	return schedule
+}
+
+// daysFromEnv parses the number of days of an environment variable, or returns zero when it is unset
+func daysFromEnv(name string) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		log.Fatalf("Invalid %s value %q: must be a number of days", name, value)
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// rateLimitFromEnv parses the rate limit of an environment variable, or returns fallback when it is unset
+func rateLimitFromEnv(name string, fallback ports.RateLimit) ports.RateLimit {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback
+	}
+	limit, err := ports.ParseRateLimit(value)
+	if err != nil {
+		log.Fatalf("Invalid %s value: %v", name, err)
+	}
+	return limit
+}
+
+// runtimeConfigFromEnv returns the update of the runtime configuration setting the fields of the
+// variables lookup finds. FEATURE_FLAGS lists the enabled flags, "<name>=false" disabling one, and
+// CORS_ALLOWED_ORIGINS the allowed origins, both separated by commas.
+func runtimeConfigFromEnv(lookup func(name string) (string, bool)) (*ports.RuntimeConfigUpdate, error) {
+	update := &ports.RuntimeConfigUpdate{}
+	if level, ok := lookup("LOG_LEVEL"); ok && level != "" {
+		update.LogLevel = &level
+	}
+	for _, rateLimit := range []struct {
+		name  string
+		field **ports.RateLimit
+	}{
+		{"RATE_LIMIT_API", &update.RateLimits.API},
+		{"RATE_LIMIT_AUTH", &update.RateLimits.Auth},
+		{"EMAIL_CHECK_RATE_LIMIT", &update.RateLimits.EmailCheck},
+	} {
+		value, ok := lookup(rateLimit.name)
+		if !ok || value == "" {
+			continue
+		}
+		limit, err := ports.ParseRateLimit(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value: %w", rateLimit.name, err)
+		}
+		*rateLimit.field = &limit
+	}
+	if flags, ok := lookup("FEATURE_FLAGS"); ok {
+		update.Features = map[string]bool{}
+		for _, flag := range strings.Split(flags, ",") {
+			name, value, hasValue := strings.Cut(strings.TrimSpace(flag), "=")
+			if name == "" {
+				continue
+			}
+			enabled := true
+			if hasValue {
+				parsed, err := strconv.ParseBool(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid FEATURE_FLAGS value %q: %w", flag, err)
+				}
+				enabled = parsed
+			}
+			update.Features[name] = enabled
+		}
+	}
+	if origins, ok := lookup("CORS_ALLOWED_ORIGINS"); ok {
+		update.CORSOrigins = []string{}
+		for _, origin := range strings.Split(origins, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				update.CORSOrigins = append(update.CORSOrigins, origin)
+			}
+		}
+	}
+	return update, nil
+}
+
+// auditSinksFromEnv returns the sinks the audit events are exported to, keyed by name, from the
+// environment variables of those configured
+func auditSinksFromEnv() map[string]ports.AuditSink {
+	sinks := make(map[string]ports.AuditSink)
+	if addr := os.Getenv("AUDIT_SYSLOG_ADDR"); addr != "" {
+		network := os.Getenv("AUDIT_SYSLOG_NETWORK")
+		if network == "" {
+			network = "udp"
+		}
+		sink, err := siem.NewSyslogSink(network, addr, "user-management-api")
+		if err != nil {
+			log.Fatalf("Invalid AUDIT_SYSLOG_NETWORK value: %v", err)
+		}
+		sinks["syslog"] = sink
+	}
+	if hecURL := os.Getenv("AUDIT_SPLUNK_HEC_URL"); hecURL != "" {
+		token := os.Getenv("AUDIT_SPLUNK_HEC_TOKEN")
+		if token == "" {
+			log.Fatal("AUDIT_SPLUNK_HEC_TOKEN environment variable is required with AUDIT_SPLUNK_HEC_URL")
+		}
+		sinks["splunk"] = siem.NewSplunkHECSink(hecURL, token, os.Getenv("AUDIT_SPLUNK_HEC_INDEX"), os.Getenv("AUDIT_SPLUNK_HEC_SOURCETYPE"), "user-management-api")
+	}
+	if bucket := os.Getenv("AUDIT_S3_BUCKET"); bucket != "" {
+		region := os.Getenv("AUDIT_S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		credentials := siem.S3Credentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}
+		if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+			log.Fatal("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables are required with AUDIT_S3_BUCKET")
+		}
+		sinks["s3"] = siem.NewS3Sink(os.Getenv("AUDIT_S3_ENDPOINT"), bucket, region, os.Getenv("AUDIT_S3_PREFIX"), credentials)
+	}
+	return sinks
+}
@@ -0,0 +1,199 @@
+// Package main seeds a development database with realistic fake users.
+//
+// Usage:
+//
+//	seed [-tenant <id>] [-n 100] [-seed 1] [-days 730]
+//
+// Users are generated deterministically from -seed: running the command again with the same
+// flags inserts nothing new, and a larger -n only adds the missing users. Every seeded user has
+// the password printed at the end. The command refuses to run when ENV is production.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/database"
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/repository"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+)
+
+// seedPassword is the password of every seeded user, hashed once as bcrypt is slow by design
+const seedPassword = "seedPassword123"
+
+// batchSize is the number of users inserted per request
+const batchSize = 1000
+
+var firstNames = []string{
+	"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda", "William", "Elizabeth",
+	"David", "Barbara", "Richard", "Susan", "Joseph", "Jessica", "Thomas", "Sarah", "Charles", "Karen",
+	"Lucas", "Ana", "Gabriel", "Beatriz", "Pedro", "Julia", "Mateo", "Sofia", "Liam", "Emma",
+	"Noah", "Olivia", "Ethan", "Chloe", "Hiroshi", "Yuki", "Arjun", "Priya", "Omar", "Fatima",
+}
+
+var lastNames = []string{
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Rodriguez", "Martinez",
+	"Hernandez", "Lopez", "Gonzalez", "Wilson", "Anderson", "Thomas", "Taylor", "Moore", "Jackson", "Martin",
+	"Silva", "Santos", "Oliveira", "Souza", "Pereira", "Costa", "Tremblay", "Roy", "Nguyen", "Kim",
+}
+
+var streets = []string{"Main St", "Oak Avenue", "Maple Drive", "Park Road", "Cedar Lane", "Elm Street", "Lake View", "Hill Crest"}
+
+// cities are consistent city, state and country triples, with a zip code prefix
+var cities = []struct {
+	city, state, country, zip string
+}{
+	{"New York", "NY", "US", "100"},
+	{"Los Angeles", "CA", "US", "900"},
+	{"Chicago", "IL", "US", "606"},
+	{"Austin", "TX", "US", "787"},
+	{"Seattle", "WA", "US", "981"},
+	{"Boston", "MA", "US", "021"},
+	{"São Paulo", "SP", "BR", "010"},
+	{"Rio de Janeiro", "RJ", "BR", "200"},
+	{"Toronto", "ON", "CA", "M5V"},
+	{"Vancouver", "BC", "CA", "V6B"},
+	{"Sydney", "NSW", "AU", "200"},
+	{"Mexico City", "CMX", "MX", "060"},
+	{"London", "", "GB", "EC1"},
+	{"Berlin", "", "DE", "101"},
+}
+
+var timezones = map[string]string{
+	"US": "America/New_York", "BR": "America/Sao_Paulo", "CA": "America/Toronto", "AU": "Australia/Sydney",
+	"MX": "America/Mexico_City", "GB": "Europe/London", "DE": "Europe/Berlin",
+}
+
+var locales = map[string]string{
+	"US": "en-US", "BR": "pt-BR", "CA": "en-CA", "AU": "en-AU", "MX": "es-MX", "GB": "en-GB", "DE": "de-DE",
+}
+
+var tags = []string{"beta", "vip", "newsletter", "trial", "churn-risk"}
+
+func main() {
+	tenant := flag.String("tenant", "", "tenant to seed (default DEFAULT_TENANT or \"default\")")
+	count := flag.Int("n", 100, "number of users")
+	seed := flag.Uint64("seed", 1, "random seed; the same seed generates the same users")
+	days := flag.Int("days", 730, "spread creation dates over this many past days")
+	flag.Parse()
+
+	// Load environment variables from .env file (optional)
+	_ = godotenv.Load()
+	if env := strings.ToLower(os.Getenv("ENV")); env == "production" || env == "prod" {
+		log.Fatal("Refusing to seed fake users: ENV is production")
+	}
+	if *count < 1 || *days < 1 {
+		log.Fatal("-n and -days must be positive")
+	}
+	if *tenant == "" {
+		*tenant = os.Getenv("DEFAULT_TENANT")
+	}
+	if *tenant == "" {
+		*tenant = "default"
+	}
+	if !domain.ValidTenantID(*tenant) {
+		log.Fatalf("Invalid tenant %q", *tenant)
+	}
+	dbName := os.Getenv("MONGODB_DB_NAME")
+	if dbName == "" {
+		log.Fatal("MONGODB_DB_NAME environment variable is not set")
+	}
+
+	hash, err := security.HashPassword(seedPassword)
+	if err != nil {
+		log.Fatalf("Error hashing the seed password: %v", err)
+	}
+
+	database.ConnectToMongoDB()
+	defer database.DisconnectFromMongoDB()
+	databases := repository.NewTenantDatabases(database.MongoDBClient.Database(dbName), database.ConnectToTenantDatabases())
+	users := repository.NewUserRepository(databases, "users")
+	ctx := domain.WithTenant(context.Background(), *tenant)
+
+	// The reference time is truncated to the day so reruns on the same day generate identical users
+	now := time.Now().UTC().Truncate(24 * time.Hour)
+	rng := rand.New(rand.NewPCG(*seed, 0))
+	inserted := 0
+	for start := 0; start < *count; start += batchSize {
+		batch := make([]*domain.User, 0, min(batchSize, *count-start))
+		for i := start; i < start+cap(batch); i++ {
+			user, err := fakeUser(rng, *tenant, *seed, i, hash, now, *days)
+			if err != nil {
+				log.Fatalf("Error generating user %d: %v", i, err)
+			}
+			batch = append(batch, user)
+		}
+		n, err := users.CreateUsers(ctx, batch)
+		if err != nil {
+			log.Fatalf("Error inserting users: %v", err)
+		}
+		inserted += n
+	}
+
+	fmt.Printf("Seeded %d new users (%d already present) in tenant %q, password %q\n",
+		inserted, *count-inserted, *tenant, seedPassword)
+}
+
+// fakeUser generates user i; its ID and email only depend on the tenant, seed and index
+func fakeUser(rng *rand.Rand, tenant string, seed uint64, i int, passwordHash string, now time.Time, days int) (*domain.User, error) {
+	first := firstNames[rng.IntN(len(firstNames))]
+	last := lastNames[rng.IntN(len(lastNames))]
+	place := cities[rng.IntN(len(cities))]
+
+	addresses := []domain.Address{{
+		Type:    domain.AddressTypeHome,
+		Street:  fmt.Sprintf("%d %s", 1+rng.IntN(9999), streets[rng.IntN(len(streets))]),
+		City:    place.city,
+		State:   place.state,
+		Country: place.country,
+		ZipCode: fmt.Sprintf("%s%02d", place.zip, rng.IntN(100)),
+	}}
+	if rng.IntN(4) == 0 {
+		work := addresses[0]
+		work.Type = domain.AddressTypeWork
+		work.Street = fmt.Sprintf("%d %s", 1+rng.IntN(500), streets[rng.IntN(len(streets))])
+		addresses = append(addresses, work)
+	}
+
+	profile := domain.Profile{
+		FirstName: first,
+		LastName:  last,
+		Addresses: addresses,
+		Phone:     fmt.Sprintf("+1-555-%03d-%04d", rng.IntN(1000), rng.IntN(10000)),
+		Birthdate: now.AddDate(-18-rng.IntN(60), 0, -rng.IntN(365)).Format(time.DateOnly),
+		Timezone:  timezones[place.country],
+		Locale:    locales[place.country],
+	}
+	local := fmt.Sprintf("%s.%s.%d", strings.ToLower(first), strings.ToLower(last), i)
+	user, err := domain.NewUser(local+"@example.com", passwordHash, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	user.ID = uuid.NewSHA1(uuid.NameSpaceURL, []byte(fmt.Sprintf("seed:%s:%d:%d", tenant, seed, i))).String()
+	user.Username = fmt.Sprintf("%s%s%d", strings.ToLower(first), strings.ToLower(last), i)
+	user.CreatedAt = now.Add(-time.Duration(rng.Int64N(int64(days) * int64(24*time.Hour))))
+	user.UpdatedAt = user.CreatedAt.Add(time.Duration(rng.Int64N(int64(now.Sub(user.CreatedAt)) + 1)))
+	if rng.IntN(3) > 0 {
+		verifiedAt := user.CreatedAt.Add(time.Duration(rng.IntN(48)) * time.Hour)
+		user.EmailVerifiedAt = &verifiedAt
+	}
+	for _, tag := range tags {
+		if rng.IntN(5) == 0 {
+			user.Tags = append(user.Tags, tag)
+		}
+	}
+	if i%50 == 0 {
+		user.Roles = []string{domain.RoleUser, domain.RoleAdmin}
+	}
+	return user, nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"log"
 	"os"
+	"strconv"
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
@@ -20,6 +21,17 @@ func ConnectToMongoDB() {
 	}
 
 	clientOpt := options.Client().ApplyURI(mongoURI)
+	applyPoolOptions(clientOpt)
+
+	// Log operations slower than MONGODB_SLOW_QUERY_THRESHOLD, 0 disables slow query logging
+	threshold := 100 * time.Millisecond
+	if d, ok := durationFromEnv("MONGODB_SLOW_QUERY_THRESHOLD"); ok {
+		threshold = d
+	}
+	SlowQueries = NewSlowQueryLog(threshold)
+	if monitor := SlowQueries.Monitor(); monitor != nil {
+		clientOpt.SetMonitor(monitor)
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -35,7 +47,78 @@ func ConnectToMongoDB() {
 		log.Fatal("Error pinging MongoDB:", err)
 	}
 
-	log.Println("Connected to MongoDB")
+	log.Printf("Connected to MongoDB (min pool size %d, max pool size %d, max connection idle time %s, server selection timeout %s, socket timeout %s, slow query threshold %s)",
+		valueOr(clientOpt.MinPoolSize, 0), valueOr(clientOpt.MaxPoolSize, 100),
+		durationSetting(clientOpt.MaxConnIdleTime, 0), durationSetting(clientOpt.ServerSelectionTimeout, 30*time.Second),
+		durationSetting(clientOpt.SocketTimeout, 0), durationSetting(&threshold, 0))
+}
+
+// applyPoolOptions overrides the connection pool and timeout options of the URI with the
+// MONGODB_MIN_POOL_SIZE, MONGODB_MAX_POOL_SIZE, MONGODB_MAX_CONN_IDLE_TIME,
+// MONGODB_SERVER_SELECTION_TIMEOUT and MONGODB_SOCKET_TIMEOUT environment variables, when set
+func applyPoolOptions(clientOpt *options.ClientOptions) {
+	if size, ok := uintFromEnv("MONGODB_MIN_POOL_SIZE"); ok {
+		clientOpt.SetMinPoolSize(size)
+	}
+	if size, ok := uintFromEnv("MONGODB_MAX_POOL_SIZE"); ok {
+		clientOpt.SetMaxPoolSize(size)
+	}
+	if minSize, maxSize := valueOr(clientOpt.MinPoolSize, 0), valueOr(clientOpt.MaxPoolSize, 100); maxSize != 0 && minSize > maxSize {
+		log.Fatalf("MongoDB min pool size %d is greater than the max pool size %d", minSize, maxSize)
+	}
+	if d, ok := durationFromEnv("MONGODB_MAX_CONN_IDLE_TIME"); ok {
+		clientOpt.SetMaxConnIdleTime(d)
+	}
+	if d, ok := durationFromEnv("MONGODB_SERVER_SELECTION_TIMEOUT"); ok {
+		clientOpt.SetServerSelectionTimeout(d)
+	}
+	if d, ok := durationFromEnv("MONGODB_SOCKET_TIMEOUT"); ok {
+		clientOpt.SetSocketTimeout(d)
+	}
+}
+
+func uintFromEnv(name string) (uint64, bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		log.Fatalf("Invalid %s value %q: must be a non-negative integer", name, value)
+	}
+	return n, true
+}
+
+func durationFromEnv(name string) (time.Duration, bool) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, false
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log.Fatalf("Invalid %s value %q: must be a duration such as 30s", name, value)
+	}
+	return d, true
+}
+
+// valueOr returns a pool size option, or the driver default when it is not set
+func valueOr(value *uint64, fallback uint64) uint64 {
+	if value == nil {
+		return fallback
+	}
+	return *value
+}
+
+// durationSetting formats a timeout option, where zero means no limit
+func durationSetting(value *time.Duration, fallback time.Duration) string {
+	d := fallback
+	if value != nil {
+		d = *value
+	}
+	if d == 0 {
+		return "none"
+	}
+	return d.String()
 }
 
 func DisconnectFromMongoDB() {
@@ -43,6 +126,11 @@ func DisconnectFromMongoDB() {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
+		for _, client := range tenantClients {
+			if err := client.Disconnect(ctx); err != nil {
+				log.Printf("Error disconnecting from the MongoDB deployment of a tenant: %v", err)
+			}
+		}
 		if err := MongoDBClient.Disconnect(ctx); err != nil {
 			log.Printf("Error disconnecting from MongoDB: %v", err)
 		} else {
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// ListReadPreference returns the read preference of the user lists and the reports, from
+// MONGODB_LIST_READ_PREFERENCE and MONGODB_LIST_MAX_STALENESS, or nil when they are read from the
+// primary like every other operation. Reading them from secondaries keeps the heavy scans off the
+// primary, at the cost of lists lagging behind the writes by the replication delay.
+func ListReadPreference() *readpref.ReadPref {
+	value := os.Getenv("MONGODB_LIST_READ_PREFERENCE")
+	if value == "" {
+		value = readpref.PrimaryMode.String()
+	}
+	mode, err := readpref.ModeFromString(value)
+	if err != nil {
+		log.Fatalf("Invalid MONGODB_LIST_READ_PREFERENCE value %q: must be primary, primaryPreferred, secondary, secondaryPreferred or nearest", value)
+	}
+
+	var opts []readpref.Option
+	if staleness, ok := durationFromEnv("MONGODB_LIST_MAX_STALENESS"); ok && staleness > 0 {
+		if mode == readpref.PrimaryMode || staleness < 90*time.Second {
+			log.Fatalf("Invalid MONGODB_LIST_MAX_STALENESS value %q: must be at least 90s, with a read preference other than primary", staleness)
+		}
+		opts = append(opts, readpref.WithMaxStaleness(staleness))
+	}
+	if mode == readpref.PrimaryMode {
+		return nil
+	}
+
+	rp, err := readpref.New(mode, opts...)
+	if err != nil {
+		log.Fatalf("Invalid MongoDB list read preference: %v", err)
+	}
+	log.Printf("Reading user lists and reports with read preference %s", mode)
+	return rp
+}
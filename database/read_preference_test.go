@@ -0,0 +1,43 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestListReadPreference(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		staleness     string
+		wantMode      readpref.Mode // Zero for the primary, read without a read preference of its own
+		wantStaleness time.Duration
+	}{
+		{name: "unset"},
+		{name: "primary", mode: "primary"},
+		{name: "secondary", mode: "secondary", wantMode: readpref.SecondaryMode},
+		{name: "nearest with a maximum staleness", mode: "nearest", staleness: "2m", wantMode: readpref.NearestMode, wantStaleness: 2 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MONGODB_LIST_READ_PREFERENCE", tt.mode)
+			t.Setenv("MONGODB_LIST_MAX_STALENESS", tt.staleness)
+
+			rp := ListReadPreference()
+			if tt.wantMode == 0 {
+				if rp != nil {
+					t.Errorf("ListReadPreference() = %v, want nil", rp.Mode())
+				}
+				return
+			}
+			if rp == nil || rp.Mode() != tt.wantMode {
+				t.Fatalf("ListReadPreference() = %v, want %v", rp, tt.wantMode)
+			}
+			if staleness, _ := rp.MaxStaleness(); staleness != tt.wantStaleness {
+				t.Errorf("MaxStaleness() = %v, want %v", staleness, tt.wantStaleness)
+			}
+		})
+	}
+}
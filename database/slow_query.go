@@ -0,0 +1,217 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// maxSlowQueryShapes bounds the distinct shapes counted; slower operations of further shapes are
+// still logged and counted in the total
+const maxSlowQueryShapes = 100
+
+// Compile-time interface check
+var _ ports.SlowQueryReporter = (*SlowQueryLog)(nil)
+
+// SlowQueries logs and counts the operations of MongoDBClient slower than MONGODB_SLOW_QUERY_THRESHOLD
+var SlowQueries = NewSlowQueryLog(0)
+
+// SlowQueryLog is a command monitor logging the database operations slower than a threshold with
+// the shape of their filter, so missing indexes can be spotted without logging user data
+type SlowQueryLog struct {
+	threshold time.Duration
+
+	pending sync.Map // Request ID to the *startedCommand of commands in flight
+	count   atomic.Int64
+
+	mu     sync.Mutex
+	shapes map[slowQueryKey]*ports.SlowQueryShape
+}
+
+type startedCommand struct {
+	collection string
+	filter     bson.RawValue
+	sort       bson.RawValue
+}
+
+type slowQueryKey struct {
+	command, collection, filter, sort string
+}
+
+// NewSlowQueryLog returns a log of the operations slower than threshold, zero disables it
+func NewSlowQueryLog(threshold time.Duration) *SlowQueryLog {
+	return &SlowQueryLog{
+		threshold: threshold,
+		shapes:    make(map[slowQueryKey]*ports.SlowQueryShape),
+	}
+}
+
+// Monitor returns the command monitor to install on the client, nil when the log is disabled
+func (l *SlowQueryLog) Monitor() *event.CommandMonitor {
+	if l.threshold <= 0 {
+		return nil
+	}
+	return &event.CommandMonitor{
+		Started: l.started,
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			l.finished(&evt.CommandFinishedEvent)
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			l.finished(&evt.CommandFinishedEvent)
+		},
+	}
+}
+
+func (l *SlowQueryLog) SlowQueryStats() ports.SlowQueryStats {
+	l.mu.Lock()
+	shapes := make([]ports.SlowQueryShape, 0, len(l.shapes))
+	for _, shape := range l.shapes {
+		shapes = append(shapes, *shape)
+	}
+	l.mu.Unlock()
+
+	slices.SortFunc(shapes, func(a, b ports.SlowQueryShape) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(b.MaxMS, a.MaxMS))
+	})
+	return ports.SlowQueryStats{
+		ThresholdMS: l.threshold.Milliseconds(),
+		Count:       l.count.Load(),
+		Shapes:      shapes,
+	}
+}
+
+// filterFields are the fields holding the filter and sort of the commands the repositories send
+var filterFields = map[string][2]string{
+	"find":          {"filter", "sort"},
+	"findAndModify": {"query", "sort"},
+	"count":         {"query", ""},
+	"distinct":      {"query", ""},
+	"aggregate":     {"pipeline", ""},
+	"update":        {"updates.0.q", ""},
+	"delete":        {"deletes.0.q", ""},
+}
+
+// started keeps the collection, filter and sort of a command until it finishes. They are copied, as
+// the command buffer is reused by the driver, but only turned into a shape for slow operations.
+func (l *SlowQueryLog) started(_ context.Context, evt *event.CommandStartedEvent) {
+	cmd := &startedCommand{}
+	if first, err := evt.Command.IndexErr(0); err == nil {
+		if collection, ok := first.Value().StringValueOK(); ok {
+			cmd.collection = collection
+		}
+	}
+	if collection, ok := evt.Command.Lookup("collection").StringValueOK(); ok && evt.CommandName == "getMore" {
+		cmd.collection = collection
+	}
+	if fields, ok := filterFields[evt.CommandName]; ok {
+		cmd.filter = lookupRaw(evt.Command, fields[0])
+		if fields[1] != "" {
+			cmd.sort = lookupRaw(evt.Command, fields[1])
+		}
+	}
+	l.pending.Store(evt.RequestID, cmd)
+}
+
+// lookupRaw returns a copy of the document or array at a dotted path of the command
+func lookupRaw(command bson.Raw, path string) bson.RawValue {
+	value, err := command.LookupErr(strings.Split(path, ".")...)
+	if err != nil || (value.Type != bsontype.EmbeddedDocument && value.Type != bsontype.Array) {
+		return bson.RawValue{}
+	}
+	return bson.RawValue{Type: value.Type, Value: slices.Clone(value.Value)}
+}
+
+func (l *SlowQueryLog) finished(evt *event.CommandFinishedEvent) {
+	started, ok := l.pending.LoadAndDelete(evt.RequestID)
+	if !ok || evt.Duration < l.threshold {
+		return
+	}
+	cmd := started.(*startedCommand)
+	l.count.Add(1)
+
+	key := slowQueryKey{
+		command:    evt.CommandName,
+		collection: cmd.collection,
+		filter:     shapeOf(cmd.filter),
+		sort:       shapeOf(cmd.sort),
+	}
+	message := "Slow MongoDB " + key.command + " on " + key.collection + " took " + evt.Duration.Round(time.Millisecond).String()
+	if key.filter != "" {
+		message += ", filter " + key.filter
+	}
+	if key.sort != "" {
+		message += ", sort " + key.sort
+	}
+	log.Println(message)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	shape, ok := l.shapes[key]
+	if !ok {
+		if len(l.shapes) >= maxSlowQueryShapes {
+			return
+		}
+		shape = &ports.SlowQueryShape{Command: key.command, Collection: key.collection, Filter: key.filter, Sort: key.sort}
+		l.shapes[key] = shape
+	}
+	shape.Count++
+	shape.MaxMS = max(shape.MaxMS, evt.Duration.Milliseconds())
+}
+
+// shapeOf renders a filter, sort or pipeline keeping its field names and operators and replacing
+// every value with "?"; arrays of documents, such as $or clauses and pipeline stages, are kept
+func shapeOf(value bson.RawValue) string {
+	if value.Value == nil {
+		return ""
+	}
+	var b strings.Builder
+	writeShape(&b, value)
+	return b.String()
+}
+
+func writeShape(b *strings.Builder, value bson.RawValue) {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		elements, err := value.Document().Elements()
+		if err != nil {
+			b.WriteString("?")
+			return
+		}
+		b.WriteString("{")
+		for i, element := range elements {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			b.WriteString(element.Key())
+			b.WriteString(": ")
+			writeShape(b, element.Value())
+		}
+		b.WriteString("}")
+	case bsontype.Array:
+		values, err := value.Array().Values()
+		if err != nil || len(values) == 0 || values[0].Type != bsontype.EmbeddedDocument {
+			b.WriteString("?")
+			return
+		}
+		b.WriteString("[")
+		for i, v := range values {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			writeShape(b, v)
+		}
+		b.WriteString("]")
+	default:
+		b.WriteString("?")
+	}
+}
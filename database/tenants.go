@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+// tenantClients are the clients of the deployments of the tenant databases other than that of
+// MongoDBClient, by connection string
+var tenantClients = map[string]*mongo.Client{}
+
+// ConnectToTenantDatabases returns the databases of the tenants of TENANT_DATABASES, a comma-separated
+// list of "<tenant>=<database>", on the deployment of MongoDBClient, or "<tenant>=<connection string>"
+// naming the database, on another deployment. Each other deployment is connected to once, with the
+// pool options of MongoDBClient, however many tenants it serves.
+func ConnectToTenantDatabases() map[string]*mongo.Database {
+	databases := make(map[string]*mongo.Database)
+	spec := os.Getenv("TENANT_DATABASES")
+	if spec == "" {
+		return databases
+	}
+	for _, entry := range strings.Split(spec, ",") {
+		tenantID, target, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || !domain.ValidTenantID(tenantID) || target == "" {
+			log.Fatalf("Invalid TENANT_DATABASES entry %q: must be <tenant>=<database> or <tenant>=<connection string>", entry)
+		}
+		if _, duplicate := databases[tenantID]; duplicate {
+			log.Fatalf("Invalid TENANT_DATABASES value: tenant %q is listed twice", tenantID)
+		}
+		if !strings.HasPrefix(target, "mongodb://") && !strings.HasPrefix(target, "mongodb+srv://") {
+			databases[tenantID] = MongoDBClient.Database(target)
+			continue
+		}
+
+		cs, err := connstring.ParseAndValidate(target)
+		if err != nil || cs.Database == "" {
+			log.Fatalf("Invalid TENANT_DATABASES entry of tenant %q: the connection string must name the database", tenantID)
+		}
+		client, ok := tenantClients[target]
+		if !ok {
+			client = connectToTenantDeployment(tenantID, target)
+			tenantClients[target] = client
+		}
+		databases[tenantID] = client.Database(cs.Database)
+	}
+	log.Printf("Routing %d tenants to their own database", len(databases))
+	return databases
+}
+
+func connectToTenantDeployment(tenantID, uri string) *mongo.Client {
+	clientOpt := options.Client().ApplyURI(uri)
+	applyPoolOptions(clientOpt)
+	if monitor := SlowQueries.Monitor(); monitor != nil {
+		clientOpt.SetMonitor(monitor)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, clientOpt)
+	if err != nil {
+		log.Fatalf("Error connecting to the MongoDB deployment of tenant %q: %v", tenantID, err)
+	}
+	if err := client.Ping(ctx, nil); err != nil {
+		log.Fatalf("Error pinging the MongoDB deployment of tenant %q: %v", tenantID, err)
+	}
+	return client
+}
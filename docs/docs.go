@@ -24,46 +24,118 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/health": {
+        "/admin/analytics/registrations": {
             "get": {
-                "description": "Check if the API server is running and healthy",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Users of the tenant registered per day, week (from Monday) or month, in UTC, for charting: every bucket of the period\nis returned, oldest first, counting zero when no user registered. The first bucket starts at the start of the\ninterval holding from. Soft-deleted users count as registered.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "stats"
+                ],
+                "summary": "Get registrations over time",
+                "parameters": [
+                    {
+                        "enum": [
+                            "day",
+                            "week",
+                            "month"
+                        ],
+                        "type": "string",
+                        "default": "day",
+                        "description": "Bucket interval",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2024-01-01\"",
+                        "description": "Start of the period, a date or an RFC 3339 time; defaults to 30 days, 12 weeks or 12 months before to",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2024-02-01\"",
+                        "description": "End of the period, excluded, a date or an RFC 3339 time; defaults to now",
+                        "name": "to",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Health check endpoint",
                 "responses": {
                     "200": {
-                        "description": "API is healthy",
+                        "description": "Registrations by bucket",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "$ref": "#/definitions/ports.RegistrationsReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid interval, time or range",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "system:read permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/users": {
+        "/admin/audit-logs": {
             "get": {
-                "description": "Retrieve a paginated list of users with optional search, sorting, and field selection\nSupports full-text search across email, first name, and last name",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Retrieve a paginated list of audit events, newest first, optionally filtered by action, actor or target",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "audit"
                 ],
-                "summary": "Get users with advanced filtering",
+                "summary": "List audit events",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"impersonation.started\"",
+                        "description": "Audit action",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Actor user UUID",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Target resource UUID",
+                        "name": "target_id",
+                        "in": "query"
+                    },
                     {
                         "minimum": 1,
                         "type": "integer",
@@ -76,67 +148,126 @@ const docTemplate = `{
                         "maximum": 100,
                         "minimum": 1,
                         "type": "integer",
-                        "default": 10,
-                        "description": "Number of users per page",
+                        "default": 20,
+                        "description": "Number of events per page",
                         "name": "page_size",
                         "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Audit events with pagination info",
+                        "schema": {
+                            "$ref": "#/definitions/ports.AuditQueryResult"
+                        }
                     },
-                    {
-                        "type": "string",
-                        "example": "\"john\"",
-                        "description": "Search term for email, first name, or last name",
-                        "name": "search",
-                        "in": "query"
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
                     },
-                    {
-                        "enum": [
-                            "email",
-                            "created_at",
-                            "updated_at",
-                            "first_name",
-                            "last_name"
-                        ],
-                        "type": "string",
-                        "example": "\"created_at\"",
-                        "description": "Sort field",
-                        "name": "sort",
-                        "in": "query"
+                    "403": {
+                        "description": "audit:read permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
                     },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/directory-sync": {
+            "get": {
+                "security": [
                     {
-                        "enum": [
-                            "asc",
-                            "desc"
-                        ],
-                        "type": "string",
-                        "default": "asc",
-                        "example": "\"desc\"",
-                        "description": "Sort order",
-                        "name": "order",
-                        "in": "query"
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Report whether a sync with the LDAP directory is running and the changes of the last sync",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get the directory sync status",
+                "responses": {
+                    "200": {
+                        "description": "Sync status and last report",
+                        "schema": {
+                            "$ref": "#/definitions/ports.DirectorySyncStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:sync permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
                     },
+                    "404": {
+                        "description": "Directory sync is not enabled for the tenant",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
                     {
-                        "type": "string",
-                        "example": "\"email,profile.first_name,created_at\"",
-                        "description": "Comma-separated list of fields to include in response",
-                        "name": "fields",
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Start a sync with the LDAP directory in the background, creating, updating and deactivating accounts to match its users\nA dry run only reports the changes; the report is returned by GET /admin/directory-sync once the sync finishes",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Sync accounts with the directory",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Report the changes without making them",
+                        "name": "dry_run",
                         "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "List of users with pagination info",
+                    "202": {
+                        "description": "Sync started"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
                         "schema": {
-                            "$ref": "#/definitions/http.GetUsersResponse"
+                            "$ref": "#/definitions/http.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad request - invalid parameters",
+                    "403": {
+                        "description": "users:sync permission required",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "404": {
+                        "description": "Directory sync is not enabled for the tenant",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "A sync is already running",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
@@ -144,9 +275,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/register": {
+        "/admin/email-domains": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the allowed and denied email domains of the registrations: the ones of EMAIL_DOMAIN_ALLOWLIST and EMAIL_DOMAIN_DENYLIST,\nflagged configured, followed by the ones added to the tenant. A domain covers its subdomains; with allowed domains, only their emails\nregister, and denied domains never do",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "List the email domain rules",
+                "responses": {
+                    "200": {
+                        "description": "Email domain rules",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.EmailDomainRule"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Register a new user account with email, password, and profile information\nThe password will be securely hashed before storage",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a domain to the allow or deny list of the tenant, applied to the next registrations of every instance\nInternationalized domains are stored in their ASCII form. Every change is recorded as email_domain.added in the audit log",
                 "consumes": [
                     "application/json"
                 ],
@@ -154,35 +328,47 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "security"
                 ],
-                "summary": "Register a new user",
+                "summary": "Add an email domain rule",
                 "parameters": [
                     {
-                        "description": "User registration data",
+                        "description": "List and domain",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/http.RegisterRequest"
+                            "$ref": "#/definitions/http.AddEmailDomainRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "User registered successfully",
+                        "description": "Domain added",
                         "schema": {
-                            "$ref": "#/definitions/http.RegisterResponse"
+                            "$ref": "#/definitions/domain.EmailDomainRule"
                         }
                     },
                     "400": {
-                        "description": "Bad request - invalid input data",
+                        "description": "Bad request - invalid list or domain",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
                     },
                     "409": {
-                        "description": "Conflict - email already exists",
+                        "description": "Domain already in the list",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
@@ -190,220 +376,10176 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
-            "get": {
-                "description": "Retrieve a specific user by their UUID",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
+        "/admin/email-domains/{list}/{domain}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "Remove a domain added to a list of the tenant; configured domains can only be removed from the configuration\nEvery change is recorded as email_domain.removed in the audit log",
                 "tags": [
-                    "users"
+                    "security"
                 ],
-                "summary": "Get user by ID",
+                "summary": "Remove an email domain rule",
                 "parameters": [
                     {
+                        "enum": [
+                            "allow",
+                            "deny"
+                        ],
                         "type": "string",
-                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
-                        "description": "User UUID",
-                        "name": "id",
+                        "description": "List",
+                        "name": "list",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"example.com\"",
+                        "description": "Domain",
+                        "name": "domain",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "User details",
+                    "204": {
+                        "description": "Domain removed"
+                    },
+                    "400": {
+                        "description": "Bad request - invalid list or domain",
                         "schema": {
-                            "$ref": "#/definitions/domain.User"
+                            "$ref": "#/definitions/http.ErrorResponse"
                         }
                     },
-                    "400": {
-                        "description": "Bad request - invalid UUID format",
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Domain not added to the list",
                         "schema": {
                             "$ref": "#/definitions/http.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.Address": {
-            "type": "object",
-            "properties": {
-                "city": {
-                    "type": "string",
+        },
+        "/admin/jobs": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a paginated list of the background jobs of the tenant, newest first, optionally filtered by status or type; failed jobs used all their attempts and are kept until retried",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "List background jobs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "queued",
+                            "running",
+                            "succeeded",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Job status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"email.send\"",
+                        "description": "Job type",
+                        "name": "type",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of jobs per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Jobs with pagination info",
+                        "schema": {
+                            "$ref": "#/definitions/ports.JobQueryResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid status",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "jobs:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get a background job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"0c4a7b52-1f3e-4d6a-9b8c-2e5f7a9d1c3b\"",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Job",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "jobs:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/jobs/{id}/retry": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Queue a failed job again to run now, with all its attempts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Retry a failed background job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"0c4a7b52-1f3e-4d6a-9b8c-2e5f7a9d1c3b\"",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Job queued again",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Job"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "jobs:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Job not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Job is not failed",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/oauth-clients": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the OAuth2 clients registered in the tenant, without their secrets",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "List OAuth2 clients",
+                "responses": {
+                    "200": {
+                        "description": "OAuth2 clients",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.OAuthClient"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Register a service allowed to get access tokens with the client_credentials grant; its scopes are token scopes, such as admin to reach the admin routes, and the permissions it is granted, except * and users:impersonate\nThe client secret is only returned by this request, it is stored hashed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "Register an OAuth2 client",
+                "parameters": [
+                    {
+                        "description": "Client name and scopes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RegisterClientRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Client registered",
+                        "schema": {
+                            "$ref": "#/definitions/http.RegisterClientResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid name or scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/oauth-clients/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "Get an OAuth2 client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"3f2b8c1a-5d6e-4f70-8a9b-1c2d3e4f5a6b\"",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OAuth2 client",
+                        "schema": {
+                            "$ref": "#/definitions/domain.OAuthClient"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a client, which can no longer get tokens; the tokens it holds are refused from then on",
+                "tags": [
+                    "security"
+                ],
+                "summary": "Delete an OAuth2 client",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"3f2b8c1a-5d6e-4f70-8a9b-1c2d3e4f5a6b\"",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Client deleted"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Client not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/roles": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the built-in system roles and the custom roles of the tenant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "List roles",
+                "responses": {
+                    "200": {
+                        "description": "Roles",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Role"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "roles:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a custom role with a set of permissions",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Create a role",
+                "parameters": [
+                    {
+                        "description": "Role data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CreateRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Role created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid name or permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "roles:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - role name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/roles/{name}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Get role by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"support\"",
+                        "description": "Role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Role details",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "roles:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete a custom role and unassign it from every user",
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Delete a role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"support\"",
+                        "description": "Role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Role deleted"
+                    },
+                    "400": {
+                        "description": "System roles cannot be deleted",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "roles:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the description and/or permissions of a custom role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Update a role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"support\"",
+                        "description": "Role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role changes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.UpdateRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated role",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid permission or system role",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "roles:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/security/ip-blocks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the IPs currently blocked after repeated failed logins, with failure counters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "List blocked IPs",
+                "responses": {
+                    "200": {
+                        "description": "Blocked IPs and counters",
+                        "schema": {
+                            "$ref": "#/definitions/http.IPBlocksResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/security/ip-blocks/{ip}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Unblock an IP and forget its failed logins",
+                "tags": [
+                    "security"
+                ],
+                "summary": "Clear an IP block",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"203.0.113.7\"",
+                        "description": "Blocked IP",
+                        "name": "ip",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Block cleared"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "IP has no recorded failures",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/security/signing-keys": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the keys signing and verifying the access tokens of every tenant: the active key signs, pending keys sign once activated and retired keys verify tokens until they expire",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "List the token signing keys",
+                "responses": {
+                    "200": {
+                        "description": "Signing keys",
+                        "schema": {
+                            "$ref": "#/definitions/http.SigningKeysResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Signing keys are not managed by the tenant",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/security/signing-keys/rotate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a signing key, published at once and signing tokens after the publish delay; the previous keys are retired and expire after the grace period",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "security"
+                ],
+                "summary": "Rotate the token signing key",
+                "responses": {
+                    "201": {
+                        "description": "Pending signing key",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SigningKey"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "security:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Signing keys are not managed by the tenant",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/stats/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Users of the tenant per day: the total at the end of the day, the new, deleted and active ones, active users\nhaving logged in during the day. The statistics of a day are rolled up by a scheduled job once it is over,\ndays without users are left out.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get daily user statistics",
+                "parameters": [
+                    {
+                        "maximum": 366,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 30,
+                        "description": "Number of past days",
+                        "name": "days",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Daily user statistics",
+                        "schema": {
+                            "$ref": "#/definitions/http.UserStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid number of days",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "system:read permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/system/config": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The configuration of this instance that is reloaded without restarting it: the log level of\nthe request log, the rate limits, the feature flags and the CORS origins",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Get the runtime configuration",
+                "responses": {
+                    "200": {
+                        "description": "Runtime configuration",
+                        "schema": {
+                            "$ref": "#/definitions/ports.RuntimeConfig"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "system:read permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Change the fields of the runtime configuration that are set, on this instance only; the\nfeature flags and the CORS origins are replaced as a whole. The changed fields are recorded\nin the audit log. Only the admins of the tenant managing the instances can change it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Update the runtime configuration",
+                "parameters": [
+                    {
+                        "description": "Fields to change",
+                        "name": "config",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/ports.RuntimeConfigUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated configuration",
+                        "schema": {
+                            "$ref": "#/definitions/ports.RuntimeConfig"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid log level, rate limit, feature flag or CORS origin",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "system:config permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Configuration not managed by this tenant",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/system/database": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Counters since startup of this instance: user reads retried after transient database errors,\nand how many of them recovered or still failed after the last attempt,\nthe state of the circuit breaker failing requests fast while the database is down,\nand the database operations slower than the slow query threshold grouped by filter shape",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Get database resilience counters",
+                "responses": {
+                    "200": {
+                        "description": "Database counters",
+                        "schema": {
+                            "$ref": "#/definitions/http.DatabaseStatsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "system:read permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/system/scheduler": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The periodic tasks of this instance with their cron schedule, next run time and the outcome\nof their runs since startup. Each run enqueues a background job, run by a single instance.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Get the scheduled tasks",
+                "responses": {
+                    "200": {
+                        "description": "Scheduled tasks",
+                        "schema": {
+                            "$ref": "#/definitions/http.SchedulerResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "system:read permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/terms": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the published versions of the documents, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "List the published terms",
+                "parameters": [
+                    {
+                        "enum": [
+                            "terms",
+                            "privacy"
+                        ],
+                        "type": "string",
+                        "description": "Only the versions of the document",
+                        "name": "document",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Published versions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.TermsVersion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid document",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "terms:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Publish a version of the terms of service or of the privacy policy, which becomes current: users are answered 451 until they accept it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "Publish a version of the terms",
+                "parameters": [
+                    {
+                        "description": "Document, version and URL of its text",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.PublishTermsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Version published",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TermsVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid document, version or URL",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "terms:manage permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Version already published",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/batch": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Apply the same change to every user matching a structured filter (as in /users/search) and, when set, listed in ids (up to 1000):\nadd or remove tags, or suspend (deactivated: true) or reactivate them, in a single database update\nSuspended users cannot log in and their tokens are revoked. Every batch is recorded as users.batch_updated in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update many users at once",
+                "parameters": [
+                    {
+                        "description": "Selected users and changes",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.BatchUpdateUsersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of users matched and modified",
+                        "schema": {
+                            "$ref": "#/definitions/ports.UserBatchResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - no users selected, invalid filter, tag or change",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:batch permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/duplicates": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve paginated groups of users that likely belong to the same person, for review before merging\nUsers match on the same birthdate and name (ignoring case, accents and punctuation) or on similar emails",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Report likely duplicate accounts",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of groups per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Duplicate candidates with pagination info",
+                        "schema": {
+                            "$ref": "#/definitions/ports.DuplicateReport"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:merge permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/export": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stream a snapshot of the tenant users, oldest first, for loading into a data warehouse\nSnapshots leave out credentials, national identification numbers, phones, addresses and metadata; login and last-seen times need the users:activity permission\nParquet files have one nullable column per snapshot field, with roles and tags joined by commas and UTC millisecond timestamps",
+                "produces": [
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet",
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Export users",
+                "parameters": [
+                    {
+                        "enum": [
+                            "ndjson",
+                            "parquet"
+                        ],
+                        "type": "string",
+                        "default": "ndjson",
+                        "description": "Export format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"john\"",
+                        "description": "Search term for email, username, first name, or last name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact-match filter on a metadata value, e.g. metadata.plan=pro",
+                        "name": "metadata.{key}",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only users having all of these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "example": 90,
+                        "description": "Only users not seen for this many days (users:activity permission)",
+                        "name": "inactive_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"40.7128,-74.006\"",
+                        "description": "Only users having an address located within radius_km of this latitude,longitude",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 20000,
+                        "minimum": 0,
+                        "type": "number",
+                        "default": 10,
+                        "example": 25,
+                        "description": "Radius of the near filter, in kilometers",
+                        "name": "radius_km",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User snapshots",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid format or filters",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:export permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/impersonate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Issue a short-lived access token acting as the target user, flagged with the admin in the \"act\" claim\nThe impersonation and every request made with the token are recorded in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Impersonate a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "Target user UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Impersonation reason",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ImpersonateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Impersonation token issued",
+                        "schema": {
+                            "$ref": "#/definitions/http.ImpersonateResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - missing reason or self-impersonation",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:impersonate permission required, nested impersonation or target holding permissions the admin lacks",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/merge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Combine the profile of a duplicate account into the user, move its organization memberships and soft-delete it\nkeep_primary (default) only fills blank fields of the user, prefer_duplicate overwrites them; roles, tags and addresses are unioned",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Merge a duplicate account into a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "Primary user UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Duplicate account and merge policy",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.MergeUsersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Merged user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid policy or self-merge",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:merge permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/{id}/roles/{name}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Assign a role to a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"support\"",
+                        "description": "Role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resulting user roles",
+                        "schema": {
+                            "$ref": "#/definitions/http.RolesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "roles:assign permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or role not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a role from a user; users must keep at least one role",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "roles"
+                ],
+                "summary": "Unassign a role from a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"support\"",
+                        "description": "Role name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resulting user roles",
+                        "schema": {
+                            "$ref": "#/definitions/http.RolesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Users must keep at least one role",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "roles:assign permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or role not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/csrf-token": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace the CSRF token of the cookie session of the request, for single-page apps that lost the one of the login, e.g. on reload\nThe token is answered and set in the csrf_token cookie, readable by the scripts of the site; state-changing requests of the\nsession send it in the X-CSRF-Token header and are otherwise answered 403 AUTH_CSRF_TOKEN_INVALID. The previous token stops passing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Get a new CSRF token of the cookie session",
+                "responses": {
+                    "200": {
+                        "description": "CSRF token issued",
+                        "schema": {
+                            "$ref": "#/definitions/http.CSRFTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bearer token, which needs no CSRF token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token, or expired session",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/introspect": {
+            "post": {
+                "security": [
+                    {
+                        "ClientBasicAuth": []
+                    }
+                ],
+                "description": "Report whether an access token of the API is active and its claims, for resource servers validating tokens with the API instead of locally (RFC 7662)\nTokens are active until they expire, unless their user or OAuth2 client was deleted, or their user deactivated, since they were issued\nClients authenticate with HTTP basic credentials configured in INTROSPECTION_CLIENTS",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Introspect a token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token to introspect",
+                        "name": "token",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "access_token",
+                        "description": "Type of the token, ignored",
+                        "name": "token_type_hint",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token state, only active for inactive tokens",
+                        "schema": {
+                            "$ref": "#/definitions/http.IntrospectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - missing token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid client credentials",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Authenticate with email and password and receive a bearer access token, optionally restricted to scopes (users:read, users:write, admin)\nUsers with a second factor receive an MFA token instead, exchanged for the access token at /auth/mfa/verify,\nunless the device sends the token of a trusted device in the X-Device-Token header or the device_token cookie\nIn cookie mode, set by AUTH_MODE, the login sets the HttpOnly session cookie and answers its CSRF token instead of an access token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log in",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token of a trusted device, skipping the second factor",
+                        "name": "X-Device-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access token issued, or session started",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "Password verified, the code of a second factor is required",
+                        "schema": {
+                            "$ref": "#/definitions/http.MFARequiredResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input data or scope",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid email or password",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Password reset required after a reported login, or account deactivated by the directory sync",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins from this IP",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login-report": {
+            "get": {
+                "description": "Target of the \"wasn't me\" link of new-device login emails: a page whose button reports the login with POST /auth/login-report\nOpening the link reports nothing, so that the scanners and prefetchers of emails cannot lock accounts",
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Confirm the report of a login",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token from the notification email",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmation page",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Sent by the confirmation page of the \"wasn't me\" link of new-device login emails: revokes the tokens and sessions of the account and blocks logins to it until its password is reset\nThe token carries the tenant, so no tenant header or subdomain is needed; the link expires after 7 days",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Report a login as suspicious",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Token from the notification email",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Login reported",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid or expired link",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "End the cookie session of the request and clear its cookie; in cookie mode, set by AUTH_MODE, logins set an\nHttpOnly, Secure, SameSite=Strict session cookie lasting SESSION_TTL instead of answering a bearer token\nBearer tokens cannot be revoked one by one and stay valid until they expire, see /users/me/logout-all",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Log out",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "CSRF token of the session, the value of the csrf_token cookie, required with the session cookie",
+                        "name": "X-CSRF-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Logged out"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token, or expired session",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Missing or invalid CSRF token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/challenge": {
+            "post": {
+                "description": "Text a 6-digit login code to an SMS factor of the user of an MFA token returned by login, valid for PHONE_CODE_TTL\nAnother code can be requested after a minute; the text messages sent to each phone are limited by RATE_LIMIT_SMS",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Send a login code to an SMS factor",
+                "parameters": [
+                    {
+                        "description": "MFA token and SMS factor",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.MFAChallengeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Code sent",
+                        "schema": {
+                            "$ref": "#/definitions/http.PhoneCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - not an SMS factor",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired MFA token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Factor not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "A code was sent less than a minute ago, or too many text messages sent to the phone",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The SMS could not be sent",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/mfa/verify": {
+            "post": {
+                "description": "Exchange the MFA token returned by login and a code of a second factor for an access token, restricted to the scopes of the login\nTOTP codes are accepted once each; SMS codes expire after PHONE_CODE_TTL or 5 wrong codes\nremember_device trusts the device for TRUSTED_DEVICE_TTL: its device token, returned as trusted_device and set as the device_token cookie,\nskips the second factor when sent to /auth/login in the X-Device-Token header or the cookie",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Finish a login with a second factor",
+                "parameters": [
+                    {
+                        "description": "MFA token, factor and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.MFAVerifyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access token issued",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid or expired MFA token, or wrong or expired code",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Factor not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins from this IP",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/password-strength": {
+            "post": {
+                "description": "Estimate how hard a password is to guess, zxcvbn-style, with the password policy enforced on registration,\nso sign-up forms show the same strength feedback the server applies\nThe optional account details are treated as common passwords, making the passwords based on them weaker\nWith PASSWORD_BREACH_CHECK, the password is also looked up in known data breaches (Have I Been Pwned); breached\npasswords are refused with USER_PASSWORD_BREACHED when set to reject, and only get a warning when set to warn\nThe password is neither stored nor logged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Evaluate the strength of a password",
+                "parameters": [
+                    {
+                        "description": "Password and account details",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.PasswordStrengthRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Strength and verdict of the password policy",
+                        "schema": {
+                            "$ref": "#/definitions/http.PasswordStrengthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reauthenticate": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Prove the password, or a code of a second factor, again and receive a fresh access token with the scopes of the current one\nCookie sessions are re-authenticated in place and keep their cookie and CSRF token, no access token is issued\nSensitive routes, such as removing a second factor or deleting the account, require a password or second factor proven within RECENT_AUTH_MAX_AGE\nand otherwise answer 401 AUTH_REAUTHENTICATION_REQUIRED with WWW-Authenticate: Bearer error=\"insufficient_user_authentication\"\nWrong passwords and codes count as failed logins of the IP; impersonation tokens cannot re-authenticate",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Re-authenticate the current user",
+                "parameters": [
+                    {
+                        "description": "Password, or factor and code",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ReauthenticateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access token issued",
+                        "schema": {
+                            "$ref": "#/definitions/http.LoginResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - neither a password nor a factor code",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token, or wrong password or code",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Impersonation token, password reset required or account deactivated",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or factor not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many failed logins from this IP",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reauthenticate/challenge": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Text a 6-digit code to an SMS factor of the authenticated user, sent to /auth/reauthenticate within PHONE_CODE_TTL\nAnother code can be requested after a minute; the text messages sent to each phone are limited by RATE_LIMIT_SMS",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Send a re-authentication code to an SMS factor",
+                "parameters": [
+                    {
+                        "description": "SMS factor",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ReauthenticationChallengeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Code sent",
+                        "schema": {
+                            "$ref": "#/definitions/http.PhoneCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - not an SMS factor",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Impersonation token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or factor not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "A code was sent less than a minute ago, or too many text messages sent to the phone",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The SMS could not be sent",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/token": {
+            "post": {
+                "security": [
+                    {
+                        "ClientBasicAuth": []
+                    }
+                ],
+                "description": "Issue an access token to a registered OAuth2 client with the client_credentials grant (RFC 6749), for services calling the API without a user\nThe token grants the requested scopes, space-delimited, or every scope of the client when none is requested; client tokens are accepted by the routes guarded by a permission they hold, within their token scopes",
+                "consumes": [
+                    "application/x-www-form-urlencoded"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Issue a client access token",
+                "parameters": [
+                    {
+                        "enum": [
+                            "client_credentials"
+                        ],
+                        "type": "string",
+                        "description": "Grant type",
+                        "name": "grant_type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "admin system:read",
+                        "description": "Requested scopes, space-delimited",
+                        "name": "scope",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client ID, when not sent with basic auth",
+                        "name": "client_id",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Client secret, when not sent with basic auth",
+                        "name": "client_secret",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Access token issued",
+                        "schema": {
+                            "$ref": "#/definitions/http.TokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - unsupported grant type or scope not held by the client",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid client credentials",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/features": {
+            "get": {
+                "description": "The feature flags clients enable features with, the same for every tenant",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meta"
+                ],
+                "summary": "List the feature flags",
+                "responses": {
+                    "200": {
+                        "description": "Feature flags",
+                        "schema": {
+                            "$ref": "#/definitions/http.FeaturesResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Check if the API server is running and healthy",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check endpoint",
+                "responses": {
+                    "200": {
+                        "description": "API is healthy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/meta/countries": {
+            "get": {
+                "description": "List the ISO 3166-1 countries accepted in addresses, with the ISO 3166-2 subdivisions\nvalidated as address states for the countries that have them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meta"
+                ],
+                "summary": "List countries",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"US\"",
+                        "description": "Only this country (code or name)",
+                        "name": "country",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Countries",
+                        "schema": {
+                            "$ref": "#/definitions/http.CountriesResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown country",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/openapi.json": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "The Swagger 2.0 document of the running instance: the documented operations of its\nregistered routes, with the definitions of its request and response bodies generated\nfrom their fields, for client generators",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "meta"
+                ],
+                "summary": "Get the OpenAPI document",
+                "responses": {
+                    "200": {
+                        "description": "Swagger 2.0 document",
+                        "schema": {
+                            "type": "object"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials, when the documentation is protected"
+                    },
+                    "403": {
+                        "description": "system:read permission required, when the documentation is admin only",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Document could not be built",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Create a new organization; the authenticated user becomes its owner",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Create an organization",
+                "parameters": [
+                    {
+                        "description": "Organization data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CreateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Organization created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Organization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - slug already exists",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve an organization the authenticated user is a member of",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Get organization by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "Organization UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Organization details",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Organization"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a member of the organization",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Delete an organization and all of its memberships (owners only)",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Delete an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "Organization UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Organization deleted"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Owner role required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Rename an organization (owner or admin members only)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Update an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "Organization UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Organization data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.UpdateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated organization",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Organization"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Owner or admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the memberships of an organization the authenticated user belongs to",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "List organization members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "Organization UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Organization memberships",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Membership"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not a member of the organization",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members/{userId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a user to the organization or change the role of an existing member (owner or admin members only)\nOnly owners can grant or revoke the owner role",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Add a member or change their role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "Organization UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Membership role",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SetMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Membership",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Membership"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid role",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Owner or admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a user from the organization (owner or admin members, or the member themselves)",
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "Remove a member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "Organization UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Member removed"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Owner or admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Organization or membership not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/terms": {
+            "get": {
+                "description": "List the current version of each published document, the terms of service and the privacy policy, which users accept before using the API",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "List the current terms",
+                "responses": {
+                    "200": {
+                        "description": "Current versions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.TermsVersion"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Retrieve a paginated list of users with optional search, sorting, and field selection\nSupports full-text search across email, username, first name, and last name\nThe response format follows the Accept header: JSON (default), application/xml or text/csv with the pagination in X-Total-Count, X-Page, X-Page-Size and X-Total-Pages headers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "text/csv"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get users with advanced filtering",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of users per page",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"john\"",
+                        "description": "Search term for email, username, first name, or last name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "email",
+                            "created_at",
+                            "updated_at",
+                            "first_name",
+                            "last_name"
+                        ],
+                        "type": "string",
+                        "example": "\"created_at\"",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "example": "\"desc\"",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"email,profile.first_name,created_at\"",
+                        "description": "Comma-separated list of fields to include in response",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact-match filter on a metadata value, e.g. metadata.plan=pro",
+                        "name": "metadata.{key}",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only users having all of these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "example": 90,
+                        "description": "Only users not seen for this many days (users:activity permission)",
+                        "name": "inactive_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"40.7128,-74.006\"",
+                        "description": "Only users having an address located within radius_km of this latitude,longitude",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 20000,
+                        "minimum": 0,
+                        "type": "number",
+                        "default": 10,
+                        "example": 25,
+                        "description": "Radius of the near filter, in kilometers",
+                        "name": "radius_km",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Attach the MongoDB query plan and execution statistics to JSON responses (system:read permission)",
+                        "name": "explain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"country,status\"",
+                        "description": "Comma-separated facets counted over every matching user and attached to JSON responses: country (of the primary address), status (active, locked or deactivated), role, tag",
+                        "name": "facets",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "X-Consistency-Token header of a previous response, for the users to include its writes",
+                        "name": "X-Consistency-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of users with pagination info",
+                        "schema": {
+                            "$ref": "#/definitions/ports.GetUsersResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:activity permission required to filter by inactivity, or system:read to explain",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/autocomplete": {
+            "get": {
+                "description": "Suggest the users whose email, username, first name or last name starts with q, or whose first and last names start with its two words\n(\"john d\"), sorted by name, for typeahead pickers; only their ID, name and email are returned, and users blocking the caller are omitted\nPrefixes use the indexes of the users collection: emails and usernames are matched exactly, names ignoring case",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Autocomplete users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"jo\"",
+                        "description": "Prefix typed so far, 1 to 100 characters",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of suggestions",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Suggested users",
+                        "schema": {
+                            "$ref": "#/definitions/http.AutocompleteUsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - missing or too long q",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/by-email/{email}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mirror a user of another system of record: create the user with the email, without a password, when there is none, or else apply the upsert to it\nOmitted fields are left untouched and blank fields of the user are always filled. on_conflict decides about the fields the user already has\nother values for: overwrite them, keep_existing to keep them, or reject to fail with 409 USER_UPSERT_CONFLICT; UPSERT_CONFLICT_POLICY sets the default\nThe minimum age rule applies to new users and changed birthdates. Every change is recorded as user.upserted in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create or update a user by email",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"john.doe@example.com\"",
+                        "description": "Email of the user",
+                        "name": "email",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Conflict policy: overwrite, keep_existing or reject, UPSERT_CONFLICT_POLICY when omitted",
+                        "name": "on_conflict",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Fields of the user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UserUpsert"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User updated, or already up to date",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "201": {
+                        "description": "User created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid email, field or policy, or under the minimum age",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:sync permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - the user has other values and on_conflict=reject, or the username or an alias of the email is in use",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/by-username/{username}": {
+            "get": {
+                "description": "Retrieve a specific user by their username (case-insensitive), with the numbers of users following it and followed by it\nUsers blocking the authenticated caller are reported as not found",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by username",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"johndoe\"",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User details",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/check-email": {
+            "get": {
+                "description": "Check whether an email is still available for registration, for signup forms\nRequests are rate limited per IP to make account enumeration harder",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Check email availability",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"john.doe@example.com\"",
+                        "description": "Email to check",
+                        "name": "email",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Email availability",
+                        "schema": {
+                            "$ref": "#/definitions/http.EmailAvailabilityResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - missing or invalid email",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many requests",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/count": {
+            "get": {
+                "description": "Count the users matching the same search, metadata, tag, inactivity and distance filters as GET /users, without fetching them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Count users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"john\"",
+                        "description": "Search term for email, username, first name, or last name",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exact-match filter on a metadata value, e.g. metadata.plan=pro",
+                        "name": "metadata.{key}",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only users having all of these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "example": 90,
+                        "description": "Only users not seen for this many days (users:activity permission)",
+                        "name": "inactive_days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"40.7128,-74.006\"",
+                        "description": "Only users having an address located within radius_km of this latitude,longitude",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 20000,
+                        "minimum": 0,
+                        "type": "number",
+                        "default": 10,
+                        "example": 25,
+                        "description": "Radius of the near filter, in kilometers",
+                        "name": "radius_km",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Number of matching users",
+                        "schema": {
+                            "$ref": "#/definitions/http.CountUsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:activity permission required to filter by inactivity",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/find-or-create": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Return the user with the email, unchanged, or else create a minimal one without a password in the same database operation,\nso that concurrent sign-ins or imports create a single user. Blank names are taken from the email\nCreated users are recorded as user.created in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Find or create a user by email",
+                "parameters": [
+                    {
+                        "description": "Email and names of the user",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.FindOrCreateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Existing user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "201": {
+                        "description": "User created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid email or names",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:sync permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - an alias of the email is in use",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/lookup": {
+            "post": {
+                "description": "Retrieve up to 100 users in a single query, in request order; unknown IDs and users blocking the caller are omitted from the response",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get users by IDs",
+                "parameters": [
+                    {
+                        "description": "User IDs",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.LookupUsersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Matching users",
+                        "schema": {
+                            "$ref": "#/definitions/http.LookupUsersResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - missing IDs or more than 100 IDs",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Permanently delete the account of the authenticated user\nThe password or a second factor must have been proven within RECENT_AUTH_MAX_AGE, see /auth/reauthenticate",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete current user account",
+                "responses": {
+                    "204": {
+                        "description": "Account deleted"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token, or re-authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/addresses": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the addresses of the authenticated user; exactly one of them is primary",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List current user addresses",
+                "responses": {
+                    "200": {
+                        "description": "User addresses",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Address"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a home, work or billing address. The first address, or one flagged primary, becomes the primary address.\nCountry and state accept ISO 3166 codes or names and are stored as codes.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Add an address to the current user",
+                "parameters": [
+                    {
+                        "description": "Address to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.AddressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Added address",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Address"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid address type, country or state, or too many addresses",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/addresses/{addressId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Replace an address, keeping its ID. Unflagging the primary address makes the first other address primary.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Replace an address of the current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Address ID",
+                        "name": "addressId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New address",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.AddressRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated address",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Address"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid address type, country or state",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or address not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove an address; removing the primary address makes the first remaining address primary",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove an address of the current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Address ID",
+                        "name": "addressId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Address removed"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or address not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/blocks": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a paginated list of the blocks of the authenticated user, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "List the users blocked by the authenticated user",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of blocks per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Blocks with pagination info",
+                        "schema": {
+                            "$ref": "#/definitions/ports.BlockQueryResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/blocks/{id}": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make the authenticated user block another user of the tenant, removing their follow relationships in both directions\nBlocked users can neither view the blocker, which GET /users/{id}, GET /users/by-username/{username}, POST /users/lookup and the follower lists report as not found, nor follow it\nBlocking a user already blocked returns the existing block",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Block a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "UUID of the user to block",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Block",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Block"
+                        }
+                    },
+                    "400": {
+                        "description": "Users cannot block themselves",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a block; the follow relationships removed by the block are not restored",
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Unblock a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "UUID of the blocked user",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "User unblocked"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The user is not blocked",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/following/{userId}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Make the authenticated user follow another user of the tenant; following a user already followed returns the existing relationship\nUsers cannot follow the users they block nor the users blocking them",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Follow a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "UUID of the user to follow",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Relationship",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Relationship"
+                        }
+                    },
+                    "400": {
+                        "description": "Users cannot follow themselves nor the users they block",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route, or the user blocks the caller",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "Unfollow a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"7c9e6679-7425-40de-944b-e07fc1f90ae7\"",
+                        "description": "UUID of the followed user",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "User unfollowed"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The user is not followed",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/login-history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the logins of the authenticated user, newest first, with their IP address, device and approximate location\nThe location is only set when the server has a GeoIP database and the IP address is public",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List current user login history",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of logins per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Logins with pagination info",
+                        "schema": {
+                            "$ref": "#/definitions/ports.LoginHistory"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/logout-all": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Revoke every access token issued to the authenticated user so far, the token of the request included, on every device\nFollowing requests with these tokens are answered 401 AUTH_TOKEN_REVOKED; trusted devices stay trusted until revoked at /users/me/trusted-devices",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Log out from all devices",
+                "responses": {
+                    "204": {
+                        "description": "Tokens revoked"
+                    },
+                    "401": {
+                        "description": "Missing, invalid or revoked token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/mfa/factors": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the TOTP and SMS second factors of the authenticated user, asked for at login once confirmed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List current user second factors",
+                "responses": {
+                    "200": {
+                        "description": "Second factors",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.MFAFactor"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add a TOTP factor, returned once with its secret and otpauth:// URI and used at login once confirmed with a code of the app,\nor an SMS factor texting codes to the verified phone of the profile, used at login right away\nA user has at most 5 factors",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Add a second factor to the current user",
+                "parameters": [
+                    {
+                        "description": "Factor type and label",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.AddMFAFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Factor added",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MFAEnrollment"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid type or label, too many factors, or unverified phone",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The phone is already a second factor",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/mfa/factors/{factorId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a factor; the login no longer asks for a second factor once the last confirmed one is removed\nThe password or a second factor must have been proven within RECENT_AUTH_MAX_AGE, see /auth/reauthenticate",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove a second factor of the current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Factor ID",
+                        "name": "factorId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Factor removed"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token, or re-authentication required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or factor not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/mfa/factors/{factorId}/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Activate a TOTP factor with a code of the authenticator app it was enrolled in",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Confirm a TOTP factor of the current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Factor ID",
+                        "name": "factorId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Code of the authenticator app",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ConfirmMFAFactorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Confirmed factor",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MFAFactor"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong code, or not a TOTP factor",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or factor not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The factor is already confirmed",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/organizations": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the organization memberships of the authenticated user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organizations"
+                ],
+                "summary": "List current user memberships",
+                "responses": {
+                    "200": {
+                        "description": "User memberships",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Membership"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/phone/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Mark the phone of the authenticated user verified with the code last sent to it\nCodes expire after PHONE_CODE_TTL, after 5 wrong codes, or when the phone changes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Confirm the current user phone",
+                "parameters": [
+                    {
+                        "description": "Code received by SMS",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ConfirmPhoneRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User with a verified phone",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong or expired code",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/phone/verify": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Text a 6-digit one-time code to the phone of the profile of the authenticated user, valid for PHONE_CODE_TTL\nAnother code can be requested after a minute, replacing the previous one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Send a code verifying the current user phone",
+                "responses": {
+                    "202": {
+                        "description": "Code sent",
+                        "schema": {
+                            "$ref": "#/definitions/http.PhoneCodeResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "The profile has no phone, or not an international number",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The phone is already verified",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "A code was sent less than a minute ago",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "The SMS could not be sent",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/profile": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Partially update the profile of the authenticated user; omitted fields are left unchanged and addresses have their own endpoints\nA new birthdate must meet the minimum age (MINIMUM_AGE), reported as USER_UNDER_MINIMUM_AGE",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update current user profile",
+                "parameters": [
+                    {
+                        "description": "Profile fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.ProfileUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid field or under the minimum age",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/security-events": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the security events of the authenticated user, newest first: password and email changes, second factors\nadded or removed, devices trusted or revoked, reported logins and logins from new devices, with their metadata\nPass next_before of a response as before to get the next page; actor_id is set when someone else made the change",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List current user security events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"2024-01-01T00:00:00Z\"",
+                        "description": "Only events that occurred before this RFC 3339 time",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of events",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Security events",
+                        "schema": {
+                            "$ref": "#/definitions/ports.SecurityEventFeed"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid before time",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/settings": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the preferences (theme, language, marketing opt-in) of the authenticated user\nUsers who never changed their settings receive the defaults",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get current user settings",
+                "responses": {
+                    "200": {
+                        "description": "User settings",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Settings"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Partially update the preferences of the authenticated user; omitted fields are left unchanged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update current user settings",
+                "parameters": [
+                    {
+                        "description": "Settings to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.SettingsUpdate"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated settings",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Settings"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid settings",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/terms": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Get the current versions the authenticated user has yet to accept, and the versions they accepted with when, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "Get my terms status",
+                "responses": {
+                    "200": {
+                        "description": "Terms status",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TermsStatus"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/terms/accept": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Accept the current versions of documents for the authenticated user, who can use the API again once every current version is accepted\nVersions must be current: one published since it was shown to the user fails with 409 and nothing is accepted",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "terms"
+                ],
+                "summary": "Accept the current terms",
+                "parameters": [
+                    {
+                        "description": "Accepted version of each document",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.AcceptTermsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Terms status",
+                        "schema": {
+                            "$ref": "#/definitions/domain.TermsStatus"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid document",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Impersonation token, or token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Version is not current",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/trusted-devices": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "List the devices skipping the second factor of the authenticated user until they expire",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List current user trusted devices",
+                "responses": {
+                    "200": {
+                        "description": "Trusted devices",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.TrustedDevice"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop trusting every device of the authenticated user, e.g. after losing one",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke every trusted device of the current user",
+                "responses": {
+                    "200": {
+                        "description": "Number of devices revoked",
+                        "schema": {
+                            "$ref": "#/definitions/http.RevokeTrustedDevicesResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/trusted-devices/{deviceId}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Stop trusting a device, which is asked for the second factor at its next login",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revoke a trusted device of the current user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Device ID",
+                        "name": "deviceId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Device revoked"
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or device not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/username": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Claim a new unique username (handle) for the authenticated user\nUsernames are 3-30 lowercase letters, digits, dots, underscores or dashes; reserved names are rejected",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Change current user username",
+                "parameters": [
+                    {
+                        "description": "New username",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.UpdateUsernameRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid or reserved username",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Token scopes do not allow the route",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Username already in use",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register": {
+            "post": {
+                "description": "Register a new user account with email, password, and profile information\nThe password must satisfy the password policy, see POST /auth/password-strength, and will be securely hashed before storage\nWith PASSWORD_BREACH_CHECK=reject, passwords found in known data breaches are refused with USER_PASSWORD_BREACHED\nThe optional username must be unique and is validated against a list of reserved names\nEmails of a denied domain, or of none of the allowed domains when some are, see /admin/email-domains, are refused with USER_EMAIL_DOMAIN_NOT_ALLOWED\ncustom_fields holds the values of the custom fields configured by REGISTRATION_FIELDS, listed by /users/register/fields; invalid values get USER_CUSTOM_FIELD_INVALID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "User registration data",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.RegisterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "User registered successfully",
+                        "schema": {
+                            "$ref": "#/definitions/http.RegisterResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid input data",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Email domain not allowed to register",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict - email or username already exists",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Body too large or nesting JSON too deeply",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register/fields": {
+            "get": {
+                "description": "List the extra fields configured by REGISTRATION_FIELDS, such as a department or an employee ID, with their type, options and pattern,\nso sign-up forms render them; registrations send their values in custom_fields and missing required fields are refused",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List the custom registration fields",
+                "responses": {
+                    "200": {
+                        "description": "Custom registration fields",
+                        "schema": {
+                            "$ref": "#/definitions/http.RegistrationFieldsResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/search": {
+            "post": {
+                "description": "Search users with nested and/or groups of conditions, for queries too complex for URL parameters\nConditions have a field (email, username, roles, tags, created_at, updated_at, profile.*, metadata.\u003ckey\u003e),\nan operator (eq, ne, in, contains, prefix, gt, gte, lt, lte, exists) and a value\nTime fields accept RFC 3339 timestamps or YYYY-MM-DD dates",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Search users with a structured query",
+                "parameters": [
+                    {
+                        "description": "Search query",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SearchUsersRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of users with pagination info",
+                        "schema": {
+                            "$ref": "#/definitions/ports.GetUsersResult"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid search query",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Retrieve a specific user by their UUID, with the numbers of users following it and followed by it\nThe response format follows the Accept header: JSON (default), application/xml or text/csv\nUsers blocking the authenticated caller are reported as not found",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/xml",
+                    "text/csv"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "X-Consistency-Token header of a previous response, for the users to include its writes",
+                        "name": "X-Consistency-Token",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User details",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid UUID format",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "description": "Lightweight existence probe: responds 200 or 404 without a body",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Check if a user exists",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User exists"
+                    },
+                    "404": {
+                        "description": "User not found"
+                    },
+                    "500": {
+                        "description": "Internal server error"
+                    }
+                }
+            }
+        },
+        "/users/{id}/avatar": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a JPEG, PNG or GIF avatar for a user (max 5 MB), by the user or by staff holding users:profile\nThumbnail and medium sizes are generated in the background; their URLs appear on the user once processing finishes",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Upload user avatar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Avatar image",
+                        "name": "avatar",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Avatar accepted for processing",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Avatar"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - missing or invalid image",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Avatar of another user without the users:profile permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Avatar exceeds the maximum size",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/documents": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the documents of a user, newest first; users list their own documents, staff with the users:documents permission and an admin token those of any user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "documents"
+                ],
+                "summary": "List the identity documents of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Documents of the user",
+                        "schema": {
+                            "$ref": "#/definitions/http.DocumentsResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Documents of another user without the users:documents permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Upload a PDF, JPEG or PNG identity document for a user (max 5 MB), kept in private storage pending review\nUsers upload their own documents; staff with the users:documents permission and an admin token upload the documents of any user",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "documents"
+                ],
+                "summary": "Upload an identity document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Document file",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "passport",
+                            "national_id",
+                            "drivers_license",
+                            "proof_of_address"
+                        ],
+                        "type": "string",
+                        "description": "Document type",
+                        "name": "type",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"2030-01-31\"",
+                        "description": "Expiry date of the document, YYYY-MM-DD",
+                        "name": "expires_on",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Uploaded document",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Document"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - missing file, invalid type, file format or expiry date",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Documents of another user without the users:documents permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Document exceeds the maximum size",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/documents/{documentId}/download": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Pre-sign a short-lived URL to the file of a document, for staff reviewing it; every URL issued is recorded as document.downloaded in the audit log",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "documents"
+                ],
+                "summary": "Get a download URL of an identity document",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"4d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a\"",
+                        "description": "Document UUID",
+                        "name": "documentId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Pre-signed download URL",
+                        "schema": {
+                            "$ref": "#/definitions/domain.DocumentDownload"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:documents permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Document not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/followers": {
+            "get": {
+                "description": "Retrieve a paginated list of the relationships of the users following a user, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "List the followers of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of relationships per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Relationships with pagination info",
+                        "schema": {
+                            "$ref": "#/definitions/ports.RelationshipQueryResult"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found or blocking the caller",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/following": {
+            "get": {
+                "description": "Retrieve a paginated list of the relationships of a user with the users it follows, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "relationships"
+                ],
+                "summary": "List the users a user follows",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of relationships per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Relationships with pagination info",
+                        "schema": {
+                            "$ref": "#/definitions/ports.RelationshipQueryResult"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found or blocking the caller",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/history": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve a paginated list of the versions of a user, newest first: the fields each write changed, with their old and new\nvalues, the caller who made it and when. The first version is the creation of the user; password changes are listed without values",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "List the change history of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number (1-based)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Number of versions per page",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Versions with pagination info",
+                        "schema": {
+                            "$ref": "#/definitions/ports.UserHistoryResult"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:history permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/metadata": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Set or remove custom key/value metadata on a user (admin only)\nKeys with a null value are removed; keys not present in the body are left unchanged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user metadata",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Metadata changes (null removes a key)",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resulting user metadata",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid key or value",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/profile": {
+            "patch": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Partially update the profile of any user; omitted fields are left unchanged and addresses have their own endpoints\noverride_minimum_age accepts a birthdate younger than the minimum age, recorded as user.minimum_age_overridden in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update the profile of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Profile fields to change",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.UpdateUserProfileRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid field or under the minimum age",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:profile permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/revert": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Restore the profile of a user, addresses included, as of a version of its change history. The revert is recorded as a new version\nand as user.reverted in the audit log; the password, email, roles and every field outside the profile are kept. A verified phone\ndoes not carry over to another phone",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Revert a user to a previous version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "example": 2,
+                        "description": "Version to restore",
+                        "name": "version",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reverted user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.User"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid version",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:profile permission required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User or version not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/tags": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Add one or more tags to a user (admin only); tags are lowercased and duplicates ignored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Add tags to a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Tags to add",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.AddTagsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resulting user tags",
+                        "schema": {
+                            "$ref": "#/definitions/http.TagsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid tag",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/tags/{tag}": {
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Remove a single tag from a user (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Remove a tag from a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "\"beta\"",
+                        "description": "Tag to remove",
+                        "name": "tag",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Resulting user tags",
+                        "schema": {
+                            "$ref": "#/definitions/http.TagsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid tag",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Admin role required",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/verification": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieve the verification status of a user: unverified, pending_review, verified or rejected\nUsers read their own verification; staff with the users:documents permission and an admin token that of any user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "verification"
+                ],
+                "summary": "Get the identity verification of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verification of the user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Verification"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Verification of another user without the users:documents permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Submit pending documents of an unverified or rejected user for review, moving its verification to pending_review\nUsers submit their own documents; staff with the users:documents permission and an admin token those of any user. Recorded as verification.submitted in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "verification"
+                ],
+                "summary": "Submit documents for identity verification",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Documents to review",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.SubmitVerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Verification pending review",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Verification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - no documents, or documents not pending or of another user",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Verification of another user without the users:documents permission",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The verification is pending review or verified",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/verification/review": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Approve or reject the verification pending review of a user, or reject a verified user to revoke its verification; rejections require a reason\nThe submitted documents take the outcome, the user is emailed it, and the review is recorded as verification.verified or verification.rejected in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "verification"
+                ],
+                "summary": "Review the identity verification of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "\"550e8400-e29b-41d4-a716-446655440000\"",
+                        "description": "User UUID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review decision",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.ReviewVerificationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Reviewed verification",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Verification"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request - invalid decision or missing reason",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid token",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "users:verify permission required, or reviewing the own verification",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The verification is not pending review nor verified",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/webhooks/identity-verification": {
+            "post": {
+                "description": "Webhook of the identity verification provider (IDENTITY_VERIFIER), authenticated by the signature of the delivery: the X-SHA2-Signature header for Onfido, X-Sandbox-Signature for the sandbox\nA clear check verifies the user, a check to consider leaves the verification pending review by staff; the result is recorded as verification.verified or verification.checked in the audit log",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "verification"
+                ],
+                "summary": "Receive identity check results",
+                "parameters": [
+                    {
+                        "description": "Delivery of the provider",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Delivery processed"
+                    },
+                    "400": {
+                        "description": "Malformed delivery",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "No identity verification provider configured, or unknown check",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.Address": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "New York"
+                },
+                "country": {
+                    "type": "string",
+                    "example": "USA"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "location": {
+                    "description": "Location is set when the address is geocoded on write",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.GeoPoint"
+                        }
+                    ]
+                },
+                "primary": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "state": {
+                    "type": "string",
+                    "example": "NY"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "123 Main St"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "home"
+                },
+                "zip_code": {
+                    "type": "string",
+                    "example": "10001"
+                }
+            }
+        },
+        "domain.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "impersonation.started"
+                },
+                "actor_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "target_id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "token_id": {
+                    "description": "TokenID and IP identify the token, or cookie session, and the IP address of the request\nperforming the action, see Actor; they are empty for background work",
+                    "type": "string",
+                    "example": "0f8fad5b-d9cb-469f-a165-70867728950e"
+                }
+            }
+        },
+        "domain.Avatar": {
+            "type": "object",
+            "properties": {
+                "medium_url": {
+                    "type": "string",
+                    "example": "/media/avatars/550e8400-e29b-41d4-a716-446655440000/1704067200000000000/medium.png"
+                },
+                "original_url": {
+                    "type": "string",
+                    "example": "/media/avatars/550e8400-e29b-41d4-a716-446655440000/1704067200000000000/original.png"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AvatarStatus"
+                        }
+                    ],
+                    "example": "ready"
+                },
+                "thumbnail_url": {
+                    "type": "string",
+                    "example": "/media/avatars/550e8400-e29b-41d4-a716-446655440000/1704067200000000000/thumbnail.png"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "1704067200000000000"
+                }
+            }
+        },
+        "domain.AvatarStatus": {
+            "type": "string",
+            "enum": [
+                "processing",
+                "ready",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "AvatarStatusProcessing",
+                "AvatarStatusReady",
+                "AvatarStatusFailed"
+            ]
+        },
+        "domain.Block": {
+            "type": "object",
+            "properties": {
+                "blocked_id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "blocker_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d"
+                }
+            }
+        },
+        "domain.CustomField": {
+            "type": "object",
+            "properties": {
+                "max_length": {
+                    "type": "integer",
+                    "example": 64
+                },
+                "name": {
+                    "type": "string",
+                    "example": "department"
+                },
+                "options": {
+                    "description": "Options lists the accepted values of a text field, any value when empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "sales",
+                        "engineering",
+                        "support"
+                    ]
+                },
+                "pattern": {
+                    "description": "Pattern is a regular expression the whole value of a text field matches",
+                    "type": "string",
+                    "example": "E[0-9]{6}"
+                },
+                "required": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "type": {
+                    "type": "string",
+                    "example": "text"
+                }
+            }
+        },
+        "domain.Document": {
+            "type": "object",
+            "properties": {
+                "content_type": {
+                    "type": "string",
+                    "example": "application/pdf"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2030-01-31T00:00:00Z"
+                },
+                "file_name": {
+                    "type": "string",
+                    "example": "passport.pdf"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "4d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a"
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 482133
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "verified",
+                        "rejected"
+                    ],
+                    "example": "pending"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "passport",
+                        "national_id",
+                        "drivers_license",
+                        "proof_of_address"
+                    ],
+                    "example": "passport"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "uploaded_by": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "domain.DocumentDownload": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:05:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "/documents/default/550e8400-e29b-41d4-a716-446655440000/4d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a.pdf?expires=1704067500\u0026signature=9f86d081884c7d65"
+                }
+            }
+        },
+        "domain.DuplicateCandidate": {
+            "type": "object",
+            "properties": {
+                "reasons": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "name_birthdate",
+                        "similar_email"
+                    ]
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.User"
+                    }
+                }
+            }
+        },
+        "domain.EmailDomainRule": {
+            "type": "object",
+            "properties": {
+                "configured": {
+                    "description": "Configured rules come from EMAIL_DOMAIN_ALLOWLIST and EMAIL_DOMAIN_DENYLIST, without ID nor\ncreation, and cannot be removed",
+                    "type": "boolean",
+                    "example": false
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "domain": {
+                    "type": "string",
+                    "example": "example.com"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+                },
+                "list": {
+                    "type": "string",
+                    "example": "allow"
+                }
+            }
+        },
+        "domain.ExternalIdentity": {
+            "type": "object",
+            "properties": {
+                "issuer": {
+                    "type": "string",
+                    "example": "https://securetoken.google.com/my-project"
+                },
+                "subject": {
+                    "type": "string",
+                    "example": "kX3mJ9pQ2rT5vW8yZ1aB4cD6eF7g"
+                }
+            }
+        },
+        "domain.FieldChange": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "profile.last_name"
+                },
+                "new": {},
+                "old": {}
+            }
+        },
+        "domain.GeoPoint": {
+            "type": "object",
+            "properties": {
+                "lat": {
+                    "type": "number",
+                    "example": 40.7128
+                },
+                "lng": {
+                    "type": "number",
+                    "example": -74.006
+                }
+            }
+        },
+        "domain.IPLocation": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
+                    "example": "São Paulo"
+                },
+                "country": {
+                    "description": "ISO 3166-1 alpha-2 code",
+                    "type": "string",
+                    "example": "BR"
+                },
+                "point": {
+                    "$ref": "#/definitions/domain.GeoPoint"
+                }
+            }
+        },
+        "domain.IdentityCheck": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:03:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "8546921-123123-123123"
+                },
+                "provider": {
+                    "type": "string",
+                    "example": "onfido"
+                },
+                "result": {
+                    "type": "string",
+                    "enum": [
+                        "clear",
+                        "consider"
+                    ],
+                    "example": "clear"
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:05Z"
+                }
+            }
+        },
+        "domain.Job": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:05:01Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0c4a7b52-1f3e-4d6a-9b8c-2e5f7a9d1c3b"
+                },
+                "last_error": {
+                    "type": "string",
+                    "example": "dial tcp 10.0.0.5:587: connect: connection refused"
+                },
+                "max_attempts": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "run_at": {
+                    "description": "RunAt is when the job is run next, or was last run",
+                    "type": "string",
+                    "example": "2024-01-01T00:05:00Z"
+                },
+                "status": {
+                    "enum": [
+                        "queued",
+                        "running",
+                        "succeeded",
+                        "failed"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.JobStatus"
+                        }
+                    ],
+                    "example": "failed"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "email.send"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:05:01Z"
+                }
+            }
+        },
+        "domain.JobStatus": {
+            "type": "string",
+            "enum": [
+                "queued",
+                "running",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-comments": {
+                "JobStatusFailed": "Dead letter: every attempt failed, kept until retried",
+                "JobStatusQueued": "Waiting for a worker, first run or retry",
+                "JobStatusRunning": "Claimed by a worker",
+                "JobStatusSucceeded": "Done, removed after JobSucceededTTL"
+            },
+            "x-enum-descriptions": [
+                "Waiting for a worker, first run or retry",
+                "Claimed by a worker",
+                "Done, removed after JobSucceededTTL",
+                "Dead letter: every attempt failed, kept until retried"
+            ],
+            "x-enum-varnames": [
+                "JobStatusQueued",
+                "JobStatusRunning",
+                "JobStatusSucceeded",
+                "JobStatusFailed"
+            ]
+        },
+        "domain.LoginEvent": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "location": {
+                    "description": "Location is set when the IP address could be located, see ports.GeoIPResolver",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.IPLocation"
+                        }
+                    ]
+                },
+                "new_device": {
+                    "description": "NewDevice is set when the user had logged in before, but never from this device",
+                    "type": "boolean"
+                },
+                "reported_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "domain.MFAEnrollment": {
+            "type": "object",
+            "properties": {
+                "factor": {
+                    "$ref": "#/definitions/domain.MFAFactor"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                },
+                "uri": {
+                    "description": "URI is the otpauth:// URI of the secret, usually shown as a QR code",
+                    "type": "string",
+                    "example": "otpauth://totp/User%20Management%20API:john.doe@example.com?algorithm=SHA1\u0026digits=6\u0026issuer=User+Management+API\u0026period=30\u0026secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+                }
+            }
+        },
+        "domain.MFAFactor": {
+            "type": "object",
+            "properties": {
+                "confirmed_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:01:00Z"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6d4a-4e9b-9c7f-1a2b3c4d5e6f"
+                },
+                "label": {
+                    "type": "string",
+                    "example": "Work phone"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2024-01-02T00:00:00Z"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+15551234567"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "totp",
+                        "sms"
+                    ],
+                    "example": "totp"
+                }
+            }
+        },
+        "domain.Membership": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9b2f6a1e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"
+                },
+                "organization_id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "role": {
+                    "type": "string",
+                    "example": "member"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "domain.OAuthClient": {
+            "type": "object",
+            "properties": {
+                "client_id": {
+                    "type": "string",
+                    "example": "3f2b8c1a-5d6e-4f70-8a9b-1c2d3e4f5a6b"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "created_by": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "billing-service"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "admin",
+                        "system:read"
+                    ]
+                }
+            }
+        },
+        "domain.Organization": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Acme Corp"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "acme-corp"
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "domain.Profile": {
+            "type": "object",
+            "properties": {
+                "addresses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Address"
+                    }
+                },
+                "birthdate": {
+                    "type": "string",
+                    "example": "1990-05-15"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en-US"
+                },
+                "nin": {
+                    "type": "string",
+                    "example": "123-45-6789"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+1-555-123-4567"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/New_York"
+                }
+            }
+        },
+        "domain.ProfileUpdate": {
+            "type": "object",
+            "properties": {
+                "birthdate": {
+                    "type": "string",
+                    "example": "1990-05-15"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en-US"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+1-555-123-4567"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/New_York"
+                }
+            }
+        },
+        "domain.Relationship": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "followee_id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "follower_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f2b8c1d-6e4a-4b7f-9c2d-8a1e5f7b9c3d"
+                }
+            }
+        },
+        "domain.RelationshipCounts": {
+            "type": "object",
+            "properties": {
+                "followers": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "following": {
+                    "type": "integer",
+                    "example": 80
+                }
+            }
+        },
+        "domain.Role": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Support agents"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "3f2b8c1a-5d6e-4f70-8a9b-1c2d3e4f5a6b"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "support"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:tags"
+                    ]
+                },
+                "system": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "domain.SearchFilter": {
+            "type": "object",
+            "properties": {
+                "and": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SearchFilter"
+                    }
+                },
+                "field": {
+                    "type": "string",
+                    "example": "created_at"
+                },
+                "op": {
+                    "type": "string",
+                    "example": "gte"
+                },
+                "or": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SearchFilter"
+                    }
+                },
+                "value": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                }
+            }
+        },
+        "domain.SecurityEvent": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "description": "ActorID is set when someone else, such as an admin, made the change",
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "occurred_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "user.password_changed",
+                        "user.email_changed",
+                        "mfa.factor_added",
+                        "mfa.factor_removed",
+                        "mfa.device_trusted",
+                        "mfa.device_revoked",
+                        "login.reported",
+                        "login.anomaly",
+                        "user.logged_out_all",
+                        "login.new_device"
+                    ],
+                    "example": "mfa.factor_added"
+                }
+            }
+        },
+        "domain.Settings": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "type": "string",
+                    "example": "en-US"
+                },
+                "marketing_opt_in": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "theme": {
+                    "type": "string",
+                    "example": "dark"
+                }
+            }
+        },
+        "domain.SettingsUpdate": {
+            "type": "object",
+            "properties": {
+                "language": {
+                    "type": "string",
+                    "example": "pt-BR"
+                },
+                "marketing_opt_in": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "theme": {
+                    "type": "string",
+                    "example": "dark"
+                }
+            }
+        },
+        "domain.SigningKey": {
+            "type": "object",
+            "properties": {
+                "activates_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:10:00Z"
+                },
+                "alg": {
+                    "type": "string",
+                    "example": "EdDSA"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-02-01T01:10:00Z"
+                },
+                "kid": {
+                    "description": "ID is the key ID named by the tokens and the key set, the RFC 7638 thumbprint of the public key",
+                    "type": "string",
+                    "example": "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"
+                },
+                "status": {
+                    "description": "Status is computed when the keys are listed",
+                    "type": "string",
+                    "example": "active"
+                }
+            }
+        },
+        "domain.TermsAcceptance": {
+            "type": "object",
+            "properties": {
+                "accepted_at": {
+                    "type": "string",
+                    "example": "2024-06-02T09:30:00Z"
+                },
+                "document": {
+                    "type": "string",
+                    "example": "terms"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "2024-06-01"
+                }
+            }
+        },
+        "domain.TermsStatus": {
+            "type": "object",
+            "properties": {
+                "accepted": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TermsAcceptance"
+                    }
+                },
+                "pending": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.TermsVersion"
+                    }
+                }
+            }
+        },
+        "domain.TermsVersion": {
+            "type": "object",
+            "properties": {
+                "document": {
+                    "type": "string",
+                    "enum": [
+                        "terms",
+                        "privacy"
+                    ],
+                    "example": "terms"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "8c1d2e3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f"
+                },
+                "published_at": {
+                    "type": "string",
+                    "example": "2024-06-01T00:00:00Z"
+                },
+                "published_by": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://example.com/legal/terms/2024-06-01"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "2024-06-01"
+                }
+            }
+        },
+        "domain.TrustedDevice": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-31T00:00:00Z"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9b2d6e1f-3c4a-4f5b-8e7d-6c5b4a392817"
+                },
+                "ip": {
+                    "type": "string",
+                    "example": "203.0.113.7"
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2024-01-15T00:00:00Z"
+                },
+                "user_agent": {
+                    "type": "string",
+                    "example": "Mozilla/5.0"
+                }
+            }
+        },
+        "domain.User": {
+            "type": "object",
+            "properties": {
+                "avatar": {
+                    "$ref": "#/definitions/domain.Avatar"
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "custom_fields": {
+                    "description": "CustomFields are the values of the custom registration fields, see CustomFieldSchema",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "deactivated_at": {
+                    "description": "DeactivatedAt is set on accounts disabled in or removed from their directory, which cannot log in",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "directory_id": {
+                    "description": "DirectoryID identifies the entry of the external directory the account is synced from",
+                    "type": "string",
+                    "example": "7d3a9b0e-52c1-4a8e-9f3c-0b6e1d2f4a5c"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "email_verified_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "external_identity": {
+                    "description": "ExternalIdentity is the account of an external identity provider whose tokens authenticate the user",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.ExternalIdentity"
+                        }
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "last_login_at": {
+                    "description": "LastLoginAt and LastSeenAt are only shown to callers with the users:activity permission",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "last_seen_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "phone_verified_at": {
+                    "description": "PhoneVerifiedAt is set once the user confirms a code sent to the phone of its profile, and\ncleared when the phone changes",
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "profile": {
+                    "$ref": "#/definitions/domain.Profile"
+                },
+                "relationships": {
+                    "description": "Relationships are counted from the relationships collection when a single user is fetched",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.RelationshipCounts"
+                        }
+                    ]
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user"
+                    ]
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "beta",
+                        "vip"
+                    ]
+                },
+                "updated_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "updated_by": {
+                    "description": "UpdatedBy is the caller who last updated the user, see Actor; it is empty when the last update\nwas made by background work, such as a directory sync",
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "domain.UserBatchUpdate": {
+            "type": "object",
+            "properties": {
+                "add_tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "vip"
+                    ]
+                },
+                "deactivated": {
+                    "description": "Deactivated suspends the users, which cannot log in and whose tokens are revoked, when true\nand reactivates them when false",
+                    "type": "boolean",
+                    "example": true
+                },
+                "remove_tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "trial"
+                    ]
+                }
+            }
+        },
+        "domain.UserStats": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "Active is the number of users who logged in during the day",
+                    "type": "integer",
+                    "example": 320
+                },
+                "computed_at": {
+                    "type": "string",
+                    "example": "2024-01-02T00:15:00Z"
+                },
+                "day": {
+                    "type": "string",
+                    "example": "2024-01-01"
+                },
+                "deleted": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "new": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "total": {
+                    "description": "Total is the number of users at the end of the day, soft-deleted users excluded",
+                    "type": "integer",
+                    "example": 1250
+                }
+            }
+        },
+        "domain.UserUpsert": {
+            "type": "object",
+            "properties": {
+                "birthdate": {
+                    "type": "string",
+                    "example": "1990-05-15"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en-US"
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+1-555-123-4567"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/New_York"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "domain.UserVersion": {
+            "type": "object",
+            "properties": {
+                "actor_id": {
+                    "description": "ActorID is the caller who made the change, see ActorFromContext",
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldChange"
+                    }
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "version": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "domain.Verification": {
+            "type": "object",
+            "properties": {
+                "check": {
+                    "description": "Check is the automated check of the submitted documents by the identity verification provider",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.IdentityCheck"
+                        }
+                    ]
+                },
+                "document_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "4d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a"
+                    ]
+                },
+                "reason": {
+                    "description": "Reason is given by the staff rejecting the verification, and optionally when verifying it",
+                    "type": "string",
+                    "example": "The passport photo is not legible"
+                },
+                "reviewed_at": {
+                    "type": "string",
+                    "example": "2024-01-02T00:00:00Z"
+                },
+                "reviewed_by": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "unverified",
+                        "pending_review",
+                        "verified",
+                        "rejected"
+                    ],
+                    "example": "pending_review"
+                },
+                "submitted_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "submitted_by": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "errcode.Code": {
+            "type": "string",
+            "enum": [
+                "INVALID_REQUEST",
+                "VALIDATION_FAILED",
+                "UNAUTHORIZED",
+                "PERMISSION_DENIED",
+                "NOT_FOUND",
+                "CONFLICT",
+                "PAYLOAD_TOO_LARGE",
+                "RATE_LIMITED",
+                "INTERNAL_ERROR",
+                "DATABASE_UNAVAILABLE",
+                "REQUEST_TIMEOUT",
+                "TENANT_REQUIRED",
+                "TENANT_INVALID",
+                "AUTH_INVALID_CREDENTIALS",
+                "AUTH_PASSWORD_RESET_REQUIRED",
+                "AUTH_ACCOUNT_DEACTIVATED",
+                "AUTH_TOO_MANY_ATTEMPTS",
+                "AUTH_LOGIN_REPORT_INVALID",
+                "AUTH_IMPERSONATION_NOT_ALLOWED",
+                "AUTH_TOKEN_NO_EMAIL",
+                "AUTH_IDENTITY_CONFLICT",
+                "AUTH_PROVIDER_UNAVAILABLE",
+                "AUTH_INVALID_CLIENT",
+                "AUTH_UNSUPPORTED_GRANT_TYPE",
+                "AUTH_INVALID_SCOPE",
+                "AUTH_USER_TOKEN_REQUIRED",
+                "AUTH_INSUFFICIENT_SCOPE",
+                "AUTH_MFA_TOKEN_INVALID",
+                "AUTH_MFA_CODE_INVALID",
+                "AUTH_TOKEN_REVOKED",
+                "AUTH_REAUTHENTICATION_REQUIRED",
+                "AUTH_SESSION_EXPIRED",
+                "AUTH_CSRF_TOKEN_INVALID",
+                "USER_NOT_FOUND",
+                "USER_EMAIL_INVALID",
+                "USER_EMAIL_TAKEN",
+                "USER_USERNAME_INVALID",
+                "USER_USERNAME_TAKEN",
+                "USER_PASSWORD_TOO_WEAK",
+                "USER_PASSWORD_BREACHED",
+                "USER_SEARCH_INVALID",
+                "USER_METADATA_INVALID",
+                "USER_CUSTOM_FIELD_INVALID",
+                "USER_TAG_INVALID",
+                "USER_MERGE_SAME_USER",
+                "USER_VERSION_NOT_FOUND",
+                "USER_VERSION_INVALID",
+                "USER_BIRTHDATE_INVALID",
+                "USER_UNDER_MINIMUM_AGE",
+                "ADDRESS_NOT_FOUND",
+                "ADDRESS_LIMIT_REACHED",
+                "ADDRESS_NOT_LOCATED",
+                "EXPORT_FORMAT_INVALID",
+                "USER_BATCH_UPDATE_INVALID",
+                "USER_UPSERT_CONFLICT",
+                "USER_EMAIL_DOMAIN_NOT_ALLOWED",
+                "RELATIONSHIP_NOT_FOUND",
+                "RELATIONSHIP_SELF",
+                "RELATIONSHIP_USER_BLOCKED",
+                "RELATIONSHIP_BLOCKED_BY_USER",
+                "BLOCK_NOT_FOUND",
+                "BLOCK_SELF",
+                "DOCUMENT_NOT_FOUND",
+                "DOCUMENT_TYPE_INVALID",
+                "DOCUMENT_FILE_INVALID",
+                "DOCUMENT_EXPIRY_INVALID",
+                "VERIFICATION_DOCUMENTS_INVALID",
+                "VERIFICATION_DECISION_INVALID",
+                "VERIFICATION_REASON_INVALID",
+                "VERIFICATION_CONFLICT",
+                "VERIFICATION_SELF_REVIEW",
+                "IDENTITY_CHECK_NOT_FOUND",
+                "IDENTITY_VERIFIER_DISABLED",
+                "WEBHOOK_SIGNATURE_INVALID",
+                "PHONE_INVALID",
+                "PHONE_MISSING",
+                "PHONE_ALREADY_VERIFIED",
+                "PHONE_CODE_INVALID",
+                "PHONE_CODE_EXPIRED",
+                "PHONE_CODE_ATTEMPTS",
+                "PHONE_CODE_TOO_FREQUENT",
+                "PHONE_SMS_RATE_LIMITED",
+                "MFA_FACTOR_TYPE_INVALID",
+                "MFA_FACTOR_LABEL_INVALID",
+                "MFA_FACTOR_LIMIT",
+                "MFA_PHONE_UNVERIFIED",
+                "MFA_FACTOR_EXISTS",
+                "MFA_FACTOR_ALREADY_CONFIRMED",
+                "MFA_FACTOR_NOT_FOUND",
+                "MFA_FACTOR_NOT_SMS",
+                "MFA_TRUSTED_DEVICE_NOT_FOUND",
+                "ORGANIZATION_NOT_FOUND",
+                "ORGANIZATION_SLUG_TAKEN",
+                "ORGANIZATION_NOT_MEMBER",
+                "ORGANIZATION_PERMISSION_DENIED",
+                "ORGANIZATION_LAST_OWNER",
+                "ORGANIZATION_MEMBERSHIP_NOT_FOUND",
+                "ROLE_NOT_FOUND",
+                "ROLE_NAME_TAKEN",
+                "ROLE_SYSTEM_IMMUTABLE",
+                "ROLE_NOT_HELD",
+                "ROLE_LAST_OF_USER",
+                "COUNTRY_NOT_FOUND",
+                "SECURITY_IP_NOT_BLOCKED",
+                "SECURITY_SIGNING_KEYS_DISABLED",
+                "SECURITY_CLIENT_NOT_FOUND",
+                "SECURITY_EMAIL_DOMAIN_EXISTS",
+                "SECURITY_EMAIL_DOMAIN_NOT_FOUND",
+                "DIRECTORY_SYNC_DISABLED",
+                "DIRECTORY_SYNC_RUNNING",
+                "TERMS_ACCEPTANCE_REQUIRED",
+                "TERMS_VERSION_NOT_CURRENT",
+                "TERMS_VERSION_EXISTS",
+                "JOB_NOT_FOUND",
+                "JOB_NOT_FAILED"
+            ],
+            "x-enum-comments": {
+                "BirthdateInvalid": "400, malformed, future, or missing while a minimum age applies",
+                "BlockSelf": "400, users cannot block themselves",
+                "CSRFTokenInvalid": "403, an unsafe request of a cookie session without its CSRF token, or cross-site",
+                "Conflict": "409",
+                "DatabaseUnavailable": "503, retry after the Retry-After delay",
+                "DocumentExpiryInvalid": "400, not a future YYYY-MM-DD date",
+                "DocumentFileInvalid": "400, not a PDF, JPEG or PNG file",
+                "EmailDomainNotAllowed": "403, the email domain is denied, or not allowed while some are",
+                "IdentityProviderDown": "503",
+                "IdentityVerifierDisabled": "404, IDENTITY_VERIFIER is not set",
+                "Internal": "500",
+                "InvalidRequest": "400",
+                "JobNotFailed": "409, only failed jobs can be retried",
+                "MFAPhoneUnverified": "400, SMS factors text the verified phone of the profile",
+                "MFATokenInvalid": "401, the MFA token of the login is invalid or expired",
+                "NotFound": "404",
+                "PasswordBreached": "400, the password is in known data breaches and PASSWORD_BREACH_CHECK=reject",
+                "PayloadTooLarge": "413",
+                "PermissionDenied": "403",
+                "PhoneCodeAttempts": "400, too many wrong codes, a new one must be requested",
+                "PhoneCodeExpired": "400, no code pending, expired, or sent to a phone since replaced",
+                "PhoneCodeTooFrequent": "429, a code was sent less than a minute ago",
+                "PhoneInvalid": "400, the phone of the profile is not an international number",
+                "RateLimited": "429",
+                "ReauthenticationNeeded": "401, the route needs a password or second factor proven within RECENT_AUTH_MAX_AGE",
+                "RelationshipBlockedByUser": "403, users cannot follow the users blocking them",
+                "RelationshipSelf": "400, users cannot follow themselves",
+                "RelationshipUserBlocked": "400, users cannot follow the users they block",
+                "RequestTimeout": "503, the request exceeded its deadline",
+                "SMSRateLimited": "429, too many text messages sent to the phone (RATE_LIMIT_SMS)",
+                "ScopeInsufficient": "403, the token scopes do not allow the route",
+                "SessionExpired": "401, the session cookie is unknown, expired or logged out",
+                "TermsAcceptanceRequired": "451, current versions must be accepted first",
+                "TokenRevoked": "401, the user logged out of all devices since the token was issued",
+                "Unauthorized": "401, missing, invalid or expired token",
+                "UserBatchInvalid": "400, no users selected, no change, or tags both added and removed",
+                "UserCustomFieldInvalid": "400, a custom registration field is missing, unknown or invalid",
+                "UserTokenRequired": "401, client tokens on routes acting for a user",
+                "UserUnderMinimumAge": "400, the birthdate is younger than MINIMUM_AGE",
+                "UserUpsertConflict": "409, on_conflict=reject and the user already has other values",
+                "UserVersionInvalid": "400, the version is not a positive number",
+                "ValidationFailed": "400, the body or a field of the request is invalid",
+                "VerificationConflict": "409, the status does not allow the action",
+                "VerificationDocumentsInvalid": "400, documents missing, not pending or of another user",
+                "VerificationReasonInvalid": "400, rejections require a reason",
+                "VerificationSelfReview": "403, staff cannot review their own verification"
+            },
+            "x-enum-descriptions": [
+                "400",
+                "400, the body or a field of the request is invalid",
+                "401, missing, invalid or expired token",
+                "403",
+                "404",
+                "409",
+                "413",
+                "429",
+                "500",
+                "503, retry after the Retry-After delay",
+                "503, the request exceeded its deadline",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "503",
+                "",
+                "",
+                "",
+                "401, client tokens on routes acting for a user",
+                "403, the token scopes do not allow the route",
+                "401, the MFA token of the login is invalid or expired",
+                "",
+                "401, the user logged out of all devices since the token was issued",
+                "401, the route needs a password or second factor proven within RECENT_AUTH_MAX_AGE",
+                "401, the session cookie is unknown, expired or logged out",
+                "403, an unsafe request of a cookie session without its CSRF token, or cross-site",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "400, the password is in known data breaches and PASSWORD_BREACH_CHECK=reject",
+                "",
+                "",
+                "400, a custom registration field is missing, unknown or invalid",
+                "",
+                "",
+                "",
+                "400, the version is not a positive number",
+                "400, malformed, future, or missing while a minimum age applies",
+                "400, the birthdate is younger than MINIMUM_AGE",
+                "",
+                "",
+                "",
+                "",
+                "400, no users selected, no change, or tags both added and removed",
+                "409, on_conflict=reject and the user already has other values",
+                "403, the email domain is denied, or not allowed while some are",
+                "",
+                "400, users cannot follow themselves",
+                "400, users cannot follow the users they block",
+                "403, users cannot follow the users blocking them",
+                "",
+                "400, users cannot block themselves",
+                "",
+                "",
+                "400, not a PDF, JPEG or PNG file",
+                "400, not a future YYYY-MM-DD date",
+                "400, documents missing, not pending or of another user",
+                "",
+                "400, rejections require a reason",
+                "409, the status does not allow the action",
+                "403, staff cannot review their own verification",
+                "",
+                "404, IDENTITY_VERIFIER is not set",
+                "",
+                "400, the phone of the profile is not an international number",
+                "",
+                "",
+                "",
+                "400, no code pending, expired, or sent to a phone since replaced",
+                "400, too many wrong codes, a new one must be requested",
+                "429, a code was sent less than a minute ago",
+                "429, too many text messages sent to the phone (RATE_LIMIT_SMS)",
+                "",
+                "",
+                "",
+                "400, SMS factors text the verified phone of the profile",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "",
+                "451, current versions must be accepted first",
+                "",
+                "",
+                "",
+                "409, only failed jobs can be retried"
+            ],
+            "x-enum-varnames": [
+                "InvalidRequest",
+                "ValidationFailed",
+                "Unauthorized",
+                "PermissionDenied",
+                "NotFound",
+                "Conflict",
+                "PayloadTooLarge",
+                "RateLimited",
+                "Internal",
+                "DatabaseUnavailable",
+                "RequestTimeout",
+                "TenantRequired",
+                "TenantInvalid",
+                "InvalidCredentials",
+                "PasswordResetRequired",
+                "AccountDeactivated",
+                "TooManyLoginAttempts",
+                "LoginReportInvalid",
+                "ImpersonationNotAllowed",
+                "ExternalTokenNoEmail",
+                "IdentityConflict",
+                "IdentityProviderDown",
+                "ClientInvalid",
+                "GrantTypeUnsupported",
+                "ScopeInvalid",
+                "UserTokenRequired",
+                "ScopeInsufficient",
+                "MFATokenInvalid",
+                "MFACodeInvalid",
+                "TokenRevoked",
+                "ReauthenticationNeeded",
+                "SessionExpired",
+                "CSRFTokenInvalid",
+                "UserNotFound",
+                "UserEmailInvalid",
+                "UserEmailTaken",
+                "UsernameInvalid",
+                "UsernameTaken",
+                "PasswordTooWeak",
+                "PasswordBreached",
+                "UserSearchInvalid",
+                "UserMetadataInvalid",
+                "UserCustomFieldInvalid",
+                "UserTagInvalid",
+                "UserMergeSameUser",
+                "UserVersionNotFound",
+                "UserVersionInvalid",
+                "BirthdateInvalid",
+                "UserUnderMinimumAge",
+                "AddressNotFound",
+                "AddressLimitReached",
+                "AddressNotLocated",
+                "ExportFormatInvalid",
+                "UserBatchInvalid",
+                "UserUpsertConflict",
+                "EmailDomainNotAllowed",
+                "RelationshipNotFound",
+                "RelationshipSelf",
+                "RelationshipUserBlocked",
+                "RelationshipBlockedByUser",
+                "BlockNotFound",
+                "BlockSelf",
+                "DocumentNotFound",
+                "DocumentTypeInvalid",
+                "DocumentFileInvalid",
+                "DocumentExpiryInvalid",
+                "VerificationDocumentsInvalid",
+                "VerificationDecisionInvalid",
+                "VerificationReasonInvalid",
+                "VerificationConflict",
+                "VerificationSelfReview",
+                "IdentityCheckNotFound",
+                "IdentityVerifierDisabled",
+                "WebhookSignatureInvalid",
+                "PhoneInvalid",
+                "PhoneMissing",
+                "PhoneAlreadyVerified",
+                "PhoneCodeInvalid",
+                "PhoneCodeExpired",
+                "PhoneCodeAttempts",
+                "PhoneCodeTooFrequent",
+                "SMSRateLimited",
+                "MFAFactorTypeInvalid",
+                "MFAFactorLabelInvalid",
+                "MFAFactorLimit",
+                "MFAPhoneUnverified",
+                "MFAFactorExists",
+                "MFAFactorConfirmed",
+                "MFAFactorNotFound",
+                "MFAFactorNotSMS",
+                "TrustedDeviceNotFound",
+                "OrganizationNotFound",
+                "OrganizationSlugTaken",
+                "OrganizationNotMember",
+                "OrganizationPermissionDenied",
+                "OrganizationLastOwner",
+                "MembershipNotFound",
+                "RoleNotFound",
+                "RoleNameTaken",
+                "RoleSystemImmutable",
+                "RoleNotHeld",
+                "RoleLastOfUser",
+                "CountryNotFound",
+                "IPNotBlocked",
+                "SigningKeysDisabled",
+                "ClientNotFound",
+                "EmailDomainExists",
+                "EmailDomainNotFound",
+                "DirectorySyncDisabled",
+                "DirectorySyncRunning",
+                "TermsAcceptanceRequired",
+                "TermsVersionNotCurrent",
+                "TermsVersionExists",
+                "JobNotFound",
+                "JobNotFailed"
+            ]
+        },
+        "http.AcceptTermsRequest": {
+            "type": "object",
+            "required": [
+                "versions"
+            ],
+            "properties": {
+                "versions": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        "privacy": "2024-01-15",
+                        "terms": "2024-06-01"
+                    }
+                }
+            }
+        },
+        "http.AddEmailDomainRequest": {
+            "type": "object",
+            "required": [
+                "domain",
+                "list"
+            ],
+            "properties": {
+                "domain": {
+                    "type": "string",
+                    "example": "example.com"
+                },
+                "list": {
+                    "type": "string",
+                    "enum": [
+                        "allow",
+                        "deny"
+                    ],
+                    "example": "allow"
+                }
+            }
+        },
+        "http.AddMFAFactorRequest": {
+            "type": "object",
+            "required": [
+                "type"
+            ],
+            "properties": {
+                "label": {
+                    "type": "string",
+                    "example": "Work phone"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "totp",
+                        "sms"
+                    ],
+                    "example": "totp"
+                }
+            }
+        },
+        "http.AddTagsRequest": {
+            "type": "object",
+            "required": [
+                "tags"
+            ],
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "beta",
+                        "vip"
+                    ]
+                }
+            }
+        },
+        "http.AddressRequest": {
+            "type": "object",
+            "properties": {
+                "city": {
+                    "type": "string",
                     "example": "New York"
                 },
-                "country": {
+                "country": {
+                    "type": "string",
+                    "example": "USA"
+                },
+                "primary": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "state": {
+                    "type": "string",
+                    "example": "NY"
+                },
+                "street": {
+                    "type": "string",
+                    "example": "123 Main St"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "home"
+                },
+                "zip_code": {
+                    "type": "string",
+                    "example": "10001"
+                }
+            }
+        },
+        "http.AutocompleteUsersResponse": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.UserSuggestion"
+                    }
+                }
+            }
+        },
+        "http.BatchUpdateUsersRequest": {
+            "type": "object",
+            "properties": {
+                "filter": {
+                    "description": "Filter has the syntax of /users/search",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.SearchFilter"
+                        }
+                    ]
+                },
+                "ids": {
+                    "type": "array",
+                    "maxItems": 1000,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "550e8400-e29b-41d4-a716-446655440000",
+                        "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                    ]
+                },
+                "update": {
+                    "$ref": "#/definitions/domain.UserBatchUpdate"
+                }
+            }
+        },
+        "http.CSRFTokenResponse": {
+            "type": "object",
+            "properties": {
+                "csrf_token": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                }
+            }
+        },
+        "http.ConfirmMFAFactorRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "http.ConfirmPhoneRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                }
+            }
+        },
+        "http.CountUsersResponse": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "http.CountriesResponse": {
+            "type": "object",
+            "properties": {
+                "countries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iso3166.Country"
+                    }
+                }
+            }
+        },
+        "http.CreateOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Acme Corp"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "acme-corp"
+                }
+            }
+        },
+        "http.CreateRoleRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Support agents"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "support"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:tags"
+                    ]
+                }
+            }
+        },
+        "http.DatabaseStatsResponse": {
+            "type": "object",
+            "properties": {
+                "breaker": {
+                    "$ref": "#/definitions/ports.BreakerStats"
+                },
+                "retries": {
+                    "$ref": "#/definitions/ports.RetryStats"
+                },
+                "slow_queries": {
+                    "$ref": "#/definitions/ports.SlowQueryStats"
+                }
+            }
+        },
+        "http.DocumentsResponse": {
+            "type": "object",
+            "properties": {
+                "documents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Document"
+                    }
+                }
+            }
+        },
+        "http.EmailAvailabilityResponse": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                }
+            }
+        },
+        "http.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code identifies the error for clients, see package errcode; unlike Error it is never translated",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/errcode.Code"
+                        }
+                    ],
+                    "example": "VALIDATION_FAILED"
+                },
+                "details": {
+                    "description": "Details lists the fields of a request body breaking validation rules",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/http.FieldViolation"
+                    }
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Invalid input"
+                },
+                "fields": {
+                    "description": "Fields maps the JSON path of invalid input fields to their error",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "http.FeaturesResponse": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                }
+            }
+        },
+        "http.FieldViolation": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "Field is the JSON path of the field",
+                    "type": "string",
+                    "example": "profile.first_name"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "password is too short"
+                },
+                "param": {
+                    "description": "Param is the parameter of the rule, such as the length of min, or the expected JSON type",
+                    "type": "string",
+                    "example": "8"
+                },
+                "rule": {
+                    "description": "Rule is the binding tag the field breaks, such as required, email, min, max or oneof, or type\nfor values of the wrong JSON type",
+                    "type": "string",
+                    "example": "min"
+                }
+            }
+        },
+        "http.FindOrCreateUserRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                }
+            }
+        },
+        "http.IPBlocksResponse": {
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.IPBlock"
+                    }
+                },
+                "stats": {
+                    "$ref": "#/definitions/ports.IPBackoffStats"
+                }
+            }
+        },
+        "http.ImpersonateRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "Investigating ticket #4521"
+                }
+            }
+        },
+        "http.ImpersonateResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:15:00Z"
+                },
+                "impersonator_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                }
+            }
+        },
+        "http.IntrospectionResponse": {
+            "type": "object",
+            "properties": {
+                "act": {
+                    "description": "Actor is the admin impersonating the subject, for impersonation tokens",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/security.ActorClaims"
+                        }
+                    ]
+                },
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "client_id": {
+                    "description": "ClientID is set for the tokens of OAuth2 clients, which have no user",
+                    "type": "string",
+                    "example": "3f2b8c1a-5d6e-4f70-8a9b-1c2d3e4f5a6b"
+                },
+                "exp": {
+                    "type": "integer",
+                    "example": 1704070800
+                },
+                "iat": {
+                    "type": "integer",
+                    "example": 1704067200
+                },
+                "iss": {
+                    "type": "string",
+                    "example": "user-management-api"
+                },
+                "jti": {
+                    "type": "string",
+                    "example": "1b4e28ba-2fa1-11d2-883f-0016d3cca427"
+                },
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "admin"
+                    ]
+                },
+                "scope": {
+                    "description": "Scope lists the scopes of restricted tokens, space-delimited",
+                    "type": "string",
+                    "example": "admin system:read"
+                },
+                "sub": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "tenant": {
+                    "type": "string",
+                    "example": "default"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "http.LoginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "securePassword123"
+                },
+                "scopes": {
+                    "description": "Scopes restrict the token to some routes, such as a read-only token; none issues an unrestricted token",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:read"
+                    ]
+                }
+            }
+        },
+        "http.LoginResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "csrf_token": {
+                    "description": "CSRFToken is sent in the X-CSRF-Token header of the unsafe requests of the session, also set in the csrf_token cookie",
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-01T01:00:00Z"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "users:read"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                },
+                "trusted_device": {
+                    "description": "TrustedDevice is set when a login with a second factor asked to remember the device",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/http.TrustedDeviceToken"
+                        }
+                    ]
+                }
+            }
+        },
+        "http.LookupUsersRequest": {
+            "type": "object",
+            "required": [
+                "ids"
+            ],
+            "properties": {
+                "ids": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "550e8400-e29b-41d4-a716-446655440000",
+                        "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                    ]
+                }
+            }
+        },
+        "http.LookupUsersResponse": {
+            "type": "object",
+            "properties": {
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.User"
+                    }
+                }
+            }
+        },
+        "http.MFAChallengeRequest": {
+            "type": "object",
+            "required": [
+                "factor_id",
+                "mfa_token"
+            ],
+            "properties": {
+                "factor_id": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6d4a-4e9b-9c7f-1a2b3c4d5e6f"
+                },
+                "mfa_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "http.MFARequiredResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:05:00Z"
+                },
+                "factors": {
+                    "description": "Factors are the confirmed factors of the user, with the phone of SMS factors masked",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.MFAFactor"
+                    }
+                },
+                "mfa_required": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "mfa_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                }
+            }
+        },
+        "http.MFAVerifyRequest": {
+            "type": "object",
+            "required": [
+                "code",
+                "factor_id",
+                "mfa_token"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "factor_id": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6d4a-4e9b-9c7f-1a2b3c4d5e6f"
+                },
+                "mfa_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "remember_device": {
+                    "description": "RememberDevice trusts the device, which skips the second factor until the device token expires",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "http.MergeUsersRequest": {
+            "type": "object",
+            "required": [
+                "duplicate_id"
+            ],
+            "properties": {
+                "duplicate_id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "policy": {
+                    "type": "string",
+                    "enum": [
+                        "keep_primary",
+                        "prefer_duplicate"
+                    ],
+                    "example": "keep_primary"
+                }
+            }
+        },
+        "http.PasswordStrengthRequest": {
+            "type": "object",
+            "required": [
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "securePassword123"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "http.PasswordStrengthResponse": {
+            "type": "object",
+            "properties": {
+                "acceptable": {
+                    "description": "Acceptable reports whether the password policy accepts the password; Code and Reason tell why not",
+                    "type": "boolean",
+                    "example": true
+                },
+                "breaches": {
+                    "description": "Breaches is how many times the password was seen in known data breaches, when PASSWORD_BREACH_CHECK is enabled",
+                    "type": "integer",
+                    "example": 0
+                },
+                "code": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/errcode.Code"
+                        }
+                    ],
+                    "example": "USER_PASSWORD_TOO_WEAK"
+                },
+                "guesses_log10": {
+                    "description": "GuessesLog10 is the log10 of the estimated number of guesses needed to find the password",
+                    "type": "number",
+                    "example": 7.25
+                },
+                "min_length": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "min_score": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "invalid password: too easy to guess"
+                },
+                "score": {
+                    "description": "Score rates the password from 0, too guessable, to 4, very unguessable",
+                    "type": "integer",
+                    "example": 2
+                },
+                "suggestions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "Add another word or two. Uncommon words are better."
+                    ]
+                },
+                "warning": {
+                    "description": "Warning explains the weakest pattern of passwords scored below 3",
+                    "type": "string",
+                    "example": "This is similar to a commonly used password"
+                }
+            }
+        },
+        "http.PhoneCodeResponse": {
+            "type": "object",
+            "properties": {
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:10:00Z"
+                }
+            }
+        },
+        "http.PublishTermsRequest": {
+            "type": "object",
+            "required": [
+                "document",
+                "version"
+            ],
+            "properties": {
+                "document": {
+                    "type": "string",
+                    "enum": [
+                        "terms",
+                        "privacy"
+                    ],
+                    "example": "terms"
+                },
+                "url": {
+                    "description": "URL is where the text of the version is shown to users",
+                    "type": "string",
+                    "example": "https://example.com/legal/terms/2024-06-01"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "2024-06-01"
+                }
+            }
+        },
+        "http.ReauthenticateRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "123456"
+                },
+                "factor_id": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6d4a-4e9b-9c7f-1a2b3c4d5e6f"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "securePassword123"
+                }
+            }
+        },
+        "http.ReauthenticationChallengeRequest": {
+            "type": "object",
+            "required": [
+                "factor_id"
+            ],
+            "properties": {
+                "factor_id": {
+                    "type": "string",
+                    "example": "3f2b8c1e-6d4a-4e9b-9c7f-1a2b3c4d5e6f"
+                }
+            }
+        },
+        "http.RegisterClientRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "billing-service"
+                },
+                "scopes": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "admin",
+                        "system:read"
+                    ]
+                }
+            }
+        },
+        "http.RegisterClientResponse": {
+            "type": "object",
+            "properties": {
+                "client": {
+                    "$ref": "#/definitions/domain.OAuthClient"
+                },
+                "client_secret": {
+                    "type": "string",
+                    "example": "Zm9vYmFyYmF6cXV4LXNlY3JldC1vZi0zMi1ieXRlcw"
+                }
+            }
+        },
+        "http.RegisterRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password",
+                "profile"
+            ],
+            "properties": {
+                "custom_fields": {
+                    "description": "CustomFields are the values of the custom registration fields, see /users/register/fields",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "password": {
+                    "type": "string",
+                    "example": "securePassword123"
+                },
+                "profile": {
+                    "$ref": "#/definitions/domain.Profile"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "http.RegisterResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "User registered successfully"
+                }
+            }
+        },
+        "http.RegistrationFieldsResponse": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.CustomField"
+                    }
+                }
+            }
+        },
+        "http.ReviewVerificationRequest": {
+            "type": "object",
+            "required": [
+                "decision"
+            ],
+            "properties": {
+                "decision": {
+                    "type": "string",
+                    "enum": [
+                        "approve",
+                        "reject"
+                    ],
+                    "example": "reject"
+                },
+                "reason": {
+                    "description": "Reason is required to reject a verification",
+                    "type": "string",
+                    "maxLength": 500,
+                    "example": "The passport photo is not legible"
+                }
+            }
+        },
+        "http.RevokeTrustedDevicesResponse": {
+            "type": "object",
+            "properties": {
+                "revoked": {
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "http.RolesResponse": {
+            "type": "object",
+            "properties": {
+                "roles": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "user",
+                        "support"
+                    ]
+                }
+            }
+        },
+        "http.SchedulerResponse": {
+            "type": "object",
+            "properties": {
+                "tasks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.ScheduledTaskStats"
+                    }
+                }
+            }
+        },
+        "http.SearchUsersRequest": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "email",
+                        "profile.first_name"
+                    ]
+                },
+                "filter": {
+                    "$ref": "#/definitions/domain.SearchFilter"
+                },
+                "order": {
+                    "type": "string",
+                    "example": "desc"
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "sort": {
+                    "type": "string",
+                    "example": "created_at"
+                }
+            }
+        },
+        "http.SetMemberRequest": {
+            "type": "object",
+            "required": [
+                "role"
+            ],
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "example": "member"
+                }
+            }
+        },
+        "http.SigningKeysResponse": {
+            "type": "object",
+            "properties": {
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SigningKey"
+                    }
+                }
+            }
+        },
+        "http.SubmitVerificationRequest": {
+            "type": "object",
+            "required": [
+                "document_ids"
+            ],
+            "properties": {
+                "document_ids": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "4d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a"
+                    ]
+                }
+            }
+        },
+        "http.TagsResponse": {
+            "type": "object",
+            "properties": {
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "beta",
+                        "vip"
+                    ]
+                }
+            }
+        },
+        "http.TokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+                },
+                "expires_in": {
+                    "type": "integer",
+                    "example": 3600
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "admin system:read"
+                },
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "http.TrustedDeviceToken": {
+            "type": "object",
+            "properties": {
+                "device_id": {
+                    "type": "string",
+                    "example": "9b2d6e1f-3c4a-4f5b-8e7d-6c5b4a392817"
+                },
+                "expires_at": {
+                    "type": "string",
+                    "example": "2024-01-31T00:00:00Z"
+                },
+                "token": {
+                    "type": "string",
+                    "example": "5f0c9a3e8b7d6c5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e0d9c8b7a6f5e4d3c2b1a"
+                }
+            }
+        },
+        "http.UpdateOrganizationRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "Acme Corporation"
+                }
+            }
+        },
+        "http.UpdateRoleRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "Tier 1 support agents"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "users:tags",
+                        "users:metadata"
+                    ]
+                }
+            }
+        },
+        "http.UpdateUserProfileRequest": {
+            "type": "object",
+            "properties": {
+                "birthdate": {
+                    "type": "string",
+                    "example": "1990-05-15"
+                },
+                "first_name": {
+                    "type": "string",
+                    "example": "John"
+                },
+                "last_name": {
+                    "type": "string",
+                    "example": "Doe"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en-US"
+                },
+                "override_minimum_age": {
+                    "description": "OverrideMinimumAge accepts a birthdate younger than the minimum age, recorded in the audit log",
+                    "type": "boolean",
+                    "example": false
+                },
+                "phone": {
+                    "type": "string",
+                    "example": "+1-555-123-4567"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "America/New_York"
+                }
+            }
+        },
+        "http.UpdateUsernameRequest": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "username": {
+                    "type": "string",
+                    "example": "johndoe"
+                }
+            }
+        },
+        "http.UserStatsResponse": {
+            "type": "object",
+            "properties": {
+                "days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "stats": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.UserStats"
+                    }
+                }
+            }
+        },
+        "http.UserSuggestion": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "John Doe"
+                }
+            }
+        },
+        "iso3166.Country": {
+            "type": "object",
+            "properties": {
+                "aliases": {
+                    "description": "Other common names, matched on lookup",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "alpha3": {
+                    "description": "Alpha-3 code",
                     "type": "string",
                     "example": "USA"
                 },
-                "state": {
+                "code": {
+                    "description": "Alpha-2 code",
+                    "type": "string",
+                    "example": "US"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "United States"
+                },
+                "subdivisions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/iso3166.Subdivision"
+                    }
+                }
+            }
+        },
+        "iso3166.Subdivision": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string",
+                    "example": "US-NY"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "New York"
+                }
+            }
+        },
+        "ports.AuditQueryResult": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AuditEvent"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total_count": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.BlockQueryResult": {
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Block"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total_count": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.BreakerStats": {
+            "type": "object",
+            "properties": {
+                "consecutive_failures": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "open_until": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:10Z"
+                },
+                "opened": {
+                    "description": "Times the breaker opened",
+                    "type": "integer",
+                    "example": 1
+                },
+                "rejected": {
+                    "description": "Calls failed without querying the database",
+                    "type": "integer",
+                    "example": 42
+                },
+                "state": {
+                    "type": "string",
+                    "example": "closed"
+                }
+            }
+        },
+        "ports.DirectorySyncChange": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "example": "update"
+                },
+                "directory_id": {
+                    "type": "string",
+                    "example": "7d3a9b0e-52c1-4a8e-9f3c-0b6e1d2f4a5c"
+                },
+                "email": {
+                    "type": "string",
+                    "example": "john.doe@example.com"
+                },
+                "error": {
+                    "description": "Error is why the entry could not be synced, for failed changes",
+                    "type": "string",
+                    "example": "invalid email address"
+                },
+                "fields": {
+                    "description": "Fields are the updated fields of the account",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "profile.last_name"
+                    ]
+                },
+                "user_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "ports.DirectorySyncReport": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.DirectorySyncChange"
+                    }
+                },
+                "created": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "deactivated": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "dry_run": {
+                    "description": "DryRun reports the changes without making them",
+                    "type": "boolean",
+                    "example": false
+                },
+                "entries": {
+                    "description": "Entries is the number of accounts read from the directory",
+                    "type": "integer",
+                    "example": 120
+                },
+                "error": {
+                    "description": "Error is why the sync stopped before changing any account, such as an unreachable directory",
+                    "type": "string",
+                    "example": "dial tcp: connection refused"
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "finished_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:05Z"
+                },
+                "reactivated": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "started_at": {
+                    "type": "string",
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "unchanged": {
+                    "type": "integer",
+                    "example": 112
+                },
+                "updated": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
+        "ports.DirectorySyncStatus": {
+            "type": "object",
+            "properties": {
+                "last_report": {
+                    "description": "LastReport is the report of the last finished sync, nil until a sync finished",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/ports.DirectorySyncReport"
+                        }
+                    ]
+                },
+                "running": {
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "ports.DuplicateReport": {
+            "type": "object",
+            "properties": {
+                "candidates": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DuplicateCandidate"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total_count": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.FacetCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "value": {
+                    "type": "string",
+                    "example": "US"
+                }
+            }
+        },
+        "ports.GetUsersResult": {
+            "type": "object",
+            "properties": {
+                "explain": {
+                    "description": "Explain is the MongoDB explain output of the page query, when requested with GetUsersOptions.Explain",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "facets": {
+                    "description": "Facets are the counts of the facets requested with GetUsersOptions.Facets, keyed by facet, the\nmost frequent values first",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "$ref": "#/definitions/ports.FacetCount"
+                        }
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total_count": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.User"
+                    }
+                }
+            }
+        },
+        "ports.IPBackoffStats": {
+            "type": "object",
+            "properties": {
+                "active_blocks": {
+                    "description": "IPs currently blocked in the tenant",
+                    "type": "integer",
+                    "example": 2
+                },
+                "blocks_issued": {
+                    "description": "Times an IP was (re)blocked",
+                    "type": "integer",
+                    "example": 12
+                },
+                "failures": {
+                    "description": "Failed logins recorded",
+                    "type": "integer",
+                    "example": 124
+                },
+                "rejected_requests": {
+                    "description": "Logins refused because the IP was blocked",
+                    "type": "integer",
+                    "example": 37
+                },
+                "tracked_ips": {
+                    "description": "IPs with recent failures in the tenant",
+                    "type": "integer",
+                    "example": 15
+                }
+            }
+        },
+        "ports.IPBlock": {
+            "type": "object",
+            "properties": {
+                "blocked_until": {
                     "type": "string",
-                    "example": "NY"
+                    "example": "2024-01-01T00:00:08Z"
                 },
-                "street": {
+                "failures": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "ip": {
                     "type": "string",
-                    "example": "123 Main St"
+                    "example": "203.0.113.7"
                 },
-                "zip_code": {
+                "last_failure_at": {
                     "type": "string",
-                    "example": "10001"
+                    "example": "2024-01-01T00:00:00Z"
                 }
             }
         },
-        "domain.Profile": {
+        "ports.JobQueryResult": {
             "type": "object",
             "properties": {
-                "address": {
-                    "$ref": "#/definitions/domain.Address"
-                },
-                "birthdate": {
-                    "type": "string",
-                    "example": "1990-05-15"
+                "jobs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Job"
+                    }
                 },
-                "first_name": {
-                    "type": "string",
-                    "example": "John"
+                "page": {
+                    "type": "integer"
                 },
-                "last_name": {
-                    "type": "string",
-                    "example": "Doe"
+                "page_size": {
+                    "type": "integer"
                 },
-                "nin": {
-                    "type": "string",
-                    "example": "123-45-6789"
+                "total_count": {
+                    "type": "integer"
                 },
-                "phone": {
-                    "type": "string",
-                    "example": "+1-555-123-4567"
+                "total_pages": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.User": {
+        "ports.LoginHistory": {
             "type": "object",
             "properties": {
-                "created_at": {
-                    "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LoginEvent"
+                    }
                 },
-                "email": {
-                    "type": "string",
-                    "example": "john.doe@example.com"
+                "page": {
+                    "type": "integer"
                 },
-                "id": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                "page_size": {
+                    "type": "integer"
                 },
-                "profile": {
-                    "$ref": "#/definitions/domain.Profile"
+                "total_count": {
+                    "type": "integer"
                 },
-                "updated_at": {
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.RegistrationBucket": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "start": {
                     "type": "string",
                     "example": "2024-01-01T00:00:00Z"
                 }
             }
         },
-        "http.ErrorResponse": {
+        "ports.RegistrationsReport": {
             "type": "object",
             "properties": {
-                "error": {
+                "buckets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.RegistrationBucket"
+                    }
+                },
+                "from": {
                     "type": "string",
-                    "example": "Invalid input"
+                    "example": "2024-01-01T00:00:00Z"
+                },
+                "interval": {
+                    "type": "string",
+                    "enum": [
+                        "day",
+                        "week",
+                        "month"
+                    ],
+                    "example": "day"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2024-01-31T00:00:00Z"
+                },
+                "total": {
+                    "type": "integer",
+                    "example": 420
                 }
             }
         },
-        "http.GetUsersResponse": {
+        "ports.RelationshipQueryResult": {
             "type": "object",
             "properties": {
                 "page": {
-                    "type": "integer",
-                    "example": 1
+                    "type": "integer"
                 },
                 "page_size": {
-                    "type": "integer",
-                    "example": 10
+                    "type": "integer"
+                },
+                "relationships": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Relationship"
+                    }
                 },
                 "total_count": {
-                    "type": "integer",
-                    "example": 100
+                    "type": "integer"
                 },
                 "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "ports.RetryStats": {
+            "type": "object",
+            "properties": {
+                "exhausted": {
+                    "description": "Reads that still failed after the last attempt",
                     "type": "integer",
-                    "example": 10
+                    "example": 2
                 },
-                "users": {
+                "recovered": {
+                    "description": "Reads that succeeded after retrying",
+                    "type": "integer",
+                    "example": 9
+                },
+                "retries": {
+                    "description": "Attempts made after a transient error",
+                    "type": "integer",
+                    "example": 14
+                }
+            }
+        },
+        "ports.RuntimeConfig": {
+            "type": "object",
+            "properties": {
+                "cors_origins": {
+                    "description": "CORSOrigins are the origins allowed to call the API from browsers, \"*\" allowing any",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/http.UserResponse"
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://app.example.com"
+                    ]
+                },
+                "features": {
+                    "description": "Features are the feature flags served to clients at /api/v1/features",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
                     }
+                },
+                "log_level": {
+                    "type": "string",
+                    "enum": [
+                        "debug",
+                        "info",
+                        "warn",
+                        "error"
+                    ],
+                    "example": "info"
+                },
+                "rate_limits": {
+                    "$ref": "#/definitions/ports.RuntimeRateLimits"
                 }
             }
         },
-        "http.RegisterRequest": {
+        "ports.RuntimeConfigUpdate": {
             "type": "object",
-            "required": [
-                "email",
-                "password",
-                "profile"
-            ],
             "properties": {
-                "email": {
+                "cors_origins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "https://app.example.com"
+                    ]
+                },
+                "features": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "boolean"
+                    }
+                },
+                "log_level": {
                     "type": "string",
-                    "example": "john.doe@example.com"
+                    "enum": [
+                        "debug",
+                        "info",
+                        "warn",
+                        "error"
+                    ],
+                    "example": "warn"
                 },
-                "password": {
+                "rate_limits": {
+                    "$ref": "#/definitions/ports.RuntimeRateLimitsUpdate"
+                }
+            }
+        },
+        "ports.RuntimeRateLimits": {
+            "type": "object",
+            "properties": {
+                "api": {
                     "type": "string",
-                    "minLength": 6,
-                    "example": "securePassword123"
+                    "example": "300/1m0s"
                 },
-                "profile": {
-                    "$ref": "#/definitions/domain.Profile"
+                "auth": {
+                    "type": "string",
+                    "example": "10/1m0s"
+                },
+                "check_email": {
+                    "type": "string",
+                    "example": "10/1m0s"
                 }
             }
         },
-        "http.RegisterResponse": {
+        "ports.RuntimeRateLimitsUpdate": {
             "type": "object",
             "properties": {
-                "message": {
+                "api": {
                     "type": "string",
-                    "example": "User registered successfully"
+                    "example": "600/1m"
+                },
+                "auth": {
+                    "type": "string",
+                    "example": "5/1m"
+                },
+                "check_email": {
+                    "type": "string",
+                    "example": "20/1m"
                 }
             }
         },
-        "http.UserResponse": {
+        "ports.ScheduledTaskStats": {
             "type": "object",
             "properties": {
-                "created_at": {
+                "failures": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_duration_ms": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "last_error": {
                     "type": "string",
-                    "example": "2024-01-01T00:00:00Z"
+                    "example": "dial tcp: connection refused"
                 },
-                "email": {
+                "last_outcome": {
                     "type": "string",
-                    "example": "john.doe@example.com"
+                    "enum": [
+                        "succeeded",
+                        "failed"
+                    ],
+                    "example": "succeeded"
                 },
-                "id": {
+                "last_run_at": {
                     "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                    "example": "2024-01-01T03:00:07Z"
                 },
-                "profile": {
-                    "$ref": "#/definitions/domain.Profile"
+                "name": {
+                    "type": "string",
+                    "example": "retention.purge"
                 },
-                "updated_at": {
+                "next_run_at": {
+                    "description": "NextRunAt is when the task runs next, jitter excluded",
+                    "type": "string",
+                    "example": "2024-01-02T03:00:00Z"
+                },
+                "running": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "runs": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "schedule": {
+                    "type": "string",
+                    "example": "0 3 * * *"
+                }
+            }
+        },
+        "ports.SecurityEventFeed": {
+            "type": "object",
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SecurityEvent"
+                    }
+                },
+                "next_before": {
+                    "description": "NextBefore is the before parameter of the next page, unset on the last page",
                     "type": "string",
                     "example": "2024-01-01T00:00:00Z"
                 }
             }
+        },
+        "ports.SlowQueryShape": {
+            "type": "object",
+            "properties": {
+                "collection": {
+                    "type": "string",
+                    "example": "users"
+                },
+                "command": {
+                    "type": "string",
+                    "example": "find"
+                },
+                "count": {
+                    "type": "integer",
+                    "example": 9
+                },
+                "filter": {
+                    "type": "string",
+                    "example": "{tenant_id: ?, deleted_at: ?, profile.city: ?}"
+                },
+                "max_ms": {
+                    "type": "integer",
+                    "example": 850
+                },
+                "sort": {
+                    "type": "string",
+                    "example": "{created_at: ?}"
+                }
+            }
+        },
+        "ports.SlowQueryStats": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "shapes": {
+                    "description": "Most frequent first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/ports.SlowQueryShape"
+                    }
+                },
+                "threshold_ms": {
+                    "description": "Zero when slow query logging is disabled",
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "ports.UserBatchResult": {
+            "type": "object",
+            "properties": {
+                "matched": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "modified": {
+                    "type": "integer",
+                    "example": 40
+                }
+            }
+        },
+        "ports.UserHistoryResult": {
+            "type": "object",
+            "properties": {
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total_count": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                },
+                "versions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.UserVersion"
+                    }
+                }
+            }
+        },
+        "security.ActorClaims": {
+            "type": "object",
+            "properties": {
+                "sub": {
+                    "type": "string"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "BearerAuth": {
+            "description": "Type \"Bearer\" followed by a space and the access token",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        },
+        "ClientBasicAuth": {
+            "type": "basic"
         }
     },
     "tags": [
@@ -411,9 +10553,53 @@ const docTemplate = `{
             "description": "Health check endpoints",
             "name": "health"
         },
+        {
+            "description": "Authentication endpoints",
+            "name": "auth"
+        },
         {
             "description": "User management operations including registration, authentication, and profile management",
             "name": "users"
+        },
+        {
+            "description": "Role and permission management",
+            "name": "roles"
+        },
+        {
+            "description": "Audit trail of administrative actions",
+            "name": "audit"
+        },
+        {
+            "description": "Login abuse protection",
+            "name": "security"
+        },
+        {
+            "description": "Organizations and their memberships",
+            "name": "organizations"
+        },
+        {
+            "description": "Terms of service and privacy policy consent",
+            "name": "terms"
+        },
+        {
+            "description": "Background jobs and their failures",
+            "name": "jobs"
+        },
+        {
+            "description": "Daily user statistics",
+            "name": "stats"
+        },
+        {
+            "description": "Users following other users",
+            "name": "relationships"
+        },
+        {
+            "description": "Identity documents uploaded for verification",
+            "name": "documents"
+        },
+        {
+            "description": "Identity verification of users reviewed by staff",
+            "name": "verification"
         }
     ]
 }`
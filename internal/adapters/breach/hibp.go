@@ -0,0 +1,72 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.BreachChecker = (*HIBPChecker)(nil)
+
+// HIBPChecker looks passwords up with the range API of Have I Been Pwned Pwned Passwords. Only the
+// first 5 characters of the SHA-1 of a password are sent (k-anonymity), and the responses are
+// padded so their size does not tell which range was asked for.
+type HIBPChecker struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+}
+
+func NewHIBPChecker(baseURL, userAgent string) *HIBPChecker {
+	return &HIBPChecker{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		userAgent: userAgent,
+		client:    &http.Client{},
+	}
+}
+
+func (c *HIBPChecker) Breaches(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/range/"+prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	req.Header.Set("Add-Padding", "true")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("hibp breach checker: HTTP %d", resp.StatusCode)
+	}
+
+	// Each line is the suffix of a hash and its count, padding lines counting 0
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lineSuffix, count, found := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !found || !strings.EqualFold(lineSuffix, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, fmt.Errorf("hibp breach checker: invalid count %q", count)
+		}
+		return n, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("hibp breach checker: invalid response: %w", err)
+	}
+	return 0, nil
+}
@@ -0,0 +1,101 @@
+// Package directory reads the accounts of external directories for the directory sync.
+package directory
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/ldap"
+)
+
+// Compile-time interface check
+var _ ports.DirectorySource = (*LDAPSource)(nil)
+
+// ldapPageSize stays below the 1000 entries Active Directory returns per search by default
+const ldapPageSize = 500
+
+// userAccountDisabled is the ACCOUNTDISABLE flag of the userAccountControl attribute of Active Directory
+const userAccountDisabled = 0x2
+
+// LDAPAttributes are the attributes the fields of the directory users are read from
+type LDAPAttributes struct {
+	ID        string // Stable identifier, kept when the entry is renamed or moved
+	Email     string
+	Username  string
+	FirstName string
+	LastName  string
+}
+
+// DefaultLDAPAttributes returns the attributes of OpenLDAP and other directories with the
+// standard schema of RFC 4519
+func DefaultLDAPAttributes() LDAPAttributes {
+	return LDAPAttributes{ID: "entryUUID", Email: "mail", Username: "uid", FirstName: "givenName", LastName: "sn"}
+}
+
+// ActiveDirectoryAttributes returns the attributes of Active Directory
+func ActiveDirectoryAttributes() LDAPAttributes {
+	return LDAPAttributes{ID: "objectGUID", Email: "mail", Username: "sAMAccountName", FirstName: "givenName", LastName: "sn"}
+}
+
+// LDAPSource reads the users matching a filter under a base DN of an LDAP directory. Entries are
+// disabled when the ACCOUNTDISABLE flag of their userAccountControl is set, as in Active
+// Directory, or when they carry a pwdAccountLockedTime, as with the password policy overlay of
+// OpenLDAP.
+type LDAPSource struct {
+	opts       ldap.Options
+	baseDN     string
+	filter     string
+	attributes LDAPAttributes
+}
+
+func NewLDAPSource(opts ldap.Options, baseDN, filter string, attributes LDAPAttributes) *LDAPSource {
+	return &LDAPSource{opts: opts, baseDN: baseDN, filter: filter, attributes: attributes}
+}
+
+func (s *LDAPSource) ListUsers(ctx context.Context) ([]ports.DirectoryUser, error) {
+	conn, err := ldap.Dial(ctx, s.opts)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	a := s.attributes
+	entries, err := conn.Search(ctx, ldap.SearchRequest{
+		BaseDN:     s.baseDN,
+		Filter:     s.filter,
+		Attributes: []string{a.ID, a.Email, a.Username, a.FirstName, a.LastName, "userAccountControl", "pwdAccountLockedTime"},
+		PageSize:   ldapPageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]ports.DirectoryUser, 0, len(entries))
+	for _, entry := range entries {
+		control, _ := strconv.ParseInt(entry.Get("userAccountControl"), 10, 64)
+		users = append(users, ports.DirectoryUser{
+			ID:        s.id(entry),
+			Email:     entry.Get(a.Email),
+			Username:  entry.Get(a.Username),
+			FirstName: entry.Get(a.FirstName),
+			LastName:  entry.Get(a.LastName),
+			Disabled:  control&userAccountDisabled != 0 || entry.Get("pwdAccountLockedTime") != "",
+		})
+	}
+	return users, nil
+}
+
+// id returns the identifier of entry; the binary objectGUID of Active Directory is formatted as
+// the GUID string Windows shows
+func (s *LDAPSource) id(entry *ldap.Entry) string {
+	raw := entry.GetRaw(s.attributes.ID)
+	if !strings.EqualFold(s.attributes.ID, "objectGUID") || len(raw) != 16 {
+		return string(raw)
+	}
+	// The first three groups are little-endian
+	return fmt.Sprintf("%02x%02x%02x%02x-%02x%02x-%02x%02x-%x-%x",
+		raw[3], raw[2], raw[1], raw[0], raw[5], raw[4], raw[7], raw[6], raw[8:10], raw[10:])
+}
@@ -0,0 +1,73 @@
+// Package geocoding provides geocoder adapters resolving postal addresses to coordinates.
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.Geocoder = (*GoogleGeocoder)(nil)
+
+// requestTimeout bounds each call to a geocoding provider, as geocoding runs while users wait
+const requestTimeout = 5 * time.Second
+
+// GoogleGeocoder geocodes addresses with the Google Maps Geocoding API
+type GoogleGeocoder struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+func NewGoogleGeocoder(apiKey string) *GoogleGeocoder {
+	return &GoogleGeocoder{
+		apiKey:  apiKey,
+		baseURL: "https://maps.googleapis.com/maps/api/geocode/json",
+		client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (g *GoogleGeocoder) Geocode(ctx context.Context, address domain.Address) (*domain.GeoPoint, error) {
+	query := url.Values{"address": {address.Query()}, "key": {g.apiKey}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Status       string `json:"status"`
+		ErrorMessage string `json:"error_message"`
+		Results      []struct {
+			Geometry struct {
+				Location domain.GeoPoint `json:"location"`
+			} `json:"geometry"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("google geocoder: invalid response (HTTP %d): %w", resp.StatusCode, err)
+	}
+
+	switch body.Status {
+	case "OK":
+		if len(body.Results) == 0 {
+			return nil, nil
+		}
+		location := body.Results[0].Geometry.Location
+		return &location, nil
+	case "ZERO_RESULTS":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("google geocoder: %s %s", body.Status, body.ErrorMessage)
+	}
+}
@@ -0,0 +1,82 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.Geocoder = (*NominatimGeocoder)(nil)
+
+// NominatimGeocoder geocodes addresses with an OpenStreetMap Nominatim server.
+// The public server allows one request per second and requires an identifying User-Agent.
+type NominatimGeocoder struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+}
+
+func NewNominatimGeocoder(baseURL, userAgent string) *NominatimGeocoder {
+	return &NominatimGeocoder{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		userAgent: userAgent,
+		client:    &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (g *NominatimGeocoder) Geocode(ctx context.Context, address domain.Address) (*domain.GeoPoint, error) {
+	// Structured queries match more reliably than free text
+	query := url.Values{"format": {"jsonv2"}, "limit": {"1"}}
+	for param, value := range map[string]string{
+		"street":     address.Street,
+		"city":       address.City,
+		"state":      address.StateName(),
+		"postalcode": address.ZipCode,
+		"country":    address.CountryName(),
+	} {
+		if value = strings.TrimSpace(value); value != "" {
+			query.Set(param, value)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"/search?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", g.userAgent)
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("nominatim geocoder: HTTP %d", resp.StatusCode)
+	}
+
+	var results []struct {
+		Lat string `json:"lat"`
+		Lon string `json:"lon"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&results); err != nil {
+		return nil, fmt.Errorf("nominatim geocoder: invalid response: %w", err)
+	}
+	if len(results) == 0 {
+		return nil, nil
+	}
+	lat, err := strconv.ParseFloat(results[0].Lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("nominatim geocoder: invalid latitude %q", results[0].Lat)
+	}
+	lng, err := strconv.ParseFloat(results[0].Lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("nominatim geocoder: invalid longitude %q", results[0].Lon)
+	}
+	return &domain.GeoPoint{Lat: lat, Lng: lng}, nil
+}
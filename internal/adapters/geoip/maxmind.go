@@ -0,0 +1,76 @@
+// Package geoip provides GeoIPResolver adapters.
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/maxminddb"
+)
+
+var _ ports.GeoIPResolver = (*MaxMindResolver)(nil)
+
+// MaxMindResolver locates IP addresses with a MaxMind GeoLite2/GeoIP2 Country or City database.
+// The database is loaded in memory; Country databases only resolve the country.
+type MaxMindResolver struct {
+	reader *maxminddb.Reader
+}
+
+func NewMaxMindResolver(path string) (*MaxMindResolver, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &MaxMindResolver{reader: reader}, nil
+}
+
+func (r *MaxMindResolver) Resolve(_ context.Context, ip string) (*domain.IPLocation, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid IP address %q", ip)
+	}
+	if parsed.IsPrivate() || parsed.IsLoopback() || parsed.IsUnspecified() {
+		return nil, nil
+	}
+
+	value, err := r.reader.Lookup(parsed)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	record, _ := value.(map[string]any)
+
+	location := &domain.IPLocation{
+		Country: field(record, "country", "iso_code"),
+		City:    field(record, "city", "names", "en"),
+	}
+	lat, latOK := lookup(record, "location", "latitude").(float64)
+	lng, lngOK := lookup(record, "location", "longitude").(float64)
+	if latOK && lngOK {
+		location.Point = &domain.GeoPoint{Lat: lat, Lng: lng}
+	}
+	if location.Country == "" && location.City == "" && location.Point == nil {
+		return nil, nil
+	}
+	return location, nil
+}
+
+// lookup returns the value at a path of nested maps, or nil if it is missing
+func lookup(record map[string]any, path ...string) any {
+	var value any = record
+	for _, key := range path {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+func field(record map[string]any, path ...string) string {
+	s, _ := lookup(record, path...).(string)
+	return s
+}
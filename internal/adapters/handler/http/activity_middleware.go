@@ -0,0 +1,66 @@
+package http
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// maxTrackedUsers bounds the users whose last write is remembered before stale entries are dropped
+const maxTrackedUsers = 10000
+
+// TrackLastSeen records when authenticated users make requests, writing at most once per interval
+// for each user and instance. Requests made with an impersonation token do not count.
+// It must run after RequireAuth or OptionalAuth.
+func TrackLastSeen(users ports.UserUseCase, interval time.Duration) gin.HandlerFunc {
+	var mu sync.Mutex
+	written := make(map[string]time.Time)
+
+	return func(c *gin.Context) {
+		userID := currentUserID(c)
+		if userID == "" || currentImpersonatorID(c) != "" {
+			c.Next()
+			return
+		}
+
+		key := domain.TenantFromContext(c.Request.Context()) + ":" + userID
+		now := time.Now()
+		mu.Lock()
+		due := now.Sub(written[key]) >= interval
+		if due {
+			if len(written) >= maxTrackedUsers {
+				for k, at := range written {
+					if now.Sub(at) >= interval {
+						delete(written, k)
+					}
+				}
+			}
+			written[key] = now
+		}
+		mu.Unlock()
+
+		if due {
+			if err := users.RecordLastSeen(c.Request.Context(), userID); err != nil {
+				log.Printf("Error recording last seen time of user %s: %v", userID, err)
+			}
+		}
+		c.Next()
+	}
+}
+
+// hideActivity clears the login and last-seen times of users unless the caller holds the
+// users:activity permission, see CheckPermission
+func hideActivity(c *gin.Context, users ...*domain.User) {
+	if hasPermission(c, domain.PermissionUsersActivity) {
+		return
+	}
+	for _, user := range users {
+		if user != nil {
+			user.LastLoginAt, user.LastSeenAt = nil, nil
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// AddressRequest represents the request body for adding or replacing an address
+type AddressRequest struct {
+	Type    string `json:"type" example:"home"`
+	Primary bool   `json:"primary" example:"true"`
+	Street  string `json:"street" example:"123 Main St"`
+	City    string `json:"city" example:"New York"`
+	State   string `json:"state" example:"NY"`
+	Country string `json:"country" example:"USA"`
+	ZipCode string `json:"zip_code" example:"10001"`
+}
+
+func (r AddressRequest) toAddress() domain.Address {
+	return domain.Address{
+		Type:    r.Type,
+		Primary: r.Primary,
+		Street:  r.Street,
+		City:    r.City,
+		State:   r.State,
+		Country: r.Country,
+		ZipCode: r.ZipCode,
+	}
+}
+
+// ListMyAddresses godoc
+// @Summary List current user addresses
+// @Description Retrieve the addresses of the authenticated user; exactly one of them is primary
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Address "User addresses"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/addresses [get]
+func (h *UserHandler) ListMyAddresses(c *gin.Context) {
+	addresses, err := h.userUC.ListAddresses(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		addressError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, addresses)
+}
+
+// AddMyAddress godoc
+// @Summary Add an address to the current user
+// @Description Add a home, work or billing address. The first address, or one flagged primary, becomes the primary address.
+// @Description Country and state accept ISO 3166 codes or names and are stored as codes.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AddressRequest true "Address to add"
+// @Success 201 {object} domain.Address "Added address"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid address type, country or state, or too many addresses"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/addresses [post]
+func (h *UserHandler) AddMyAddress(c *gin.Context) {
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	address, err := h.userUC.AddAddress(c.Request.Context(), currentUserID(c), req.toAddress())
+	if err != nil {
+		addressError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, address)
+}
+
+// UpdateMyAddress godoc
+// @Summary Replace an address of the current user
+// @Description Replace an address, keeping its ID. Unflagging the primary address makes the first other address primary.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param addressId path string true "Address ID"
+// @Param request body AddressRequest true "New address"
+// @Success 200 {object} domain.Address "Updated address"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid address type, country or state"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User or address not found"
+// @Router /users/me/addresses/{addressId} [put]
+func (h *UserHandler) UpdateMyAddress(c *gin.Context) {
+	var req AddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	address, err := h.userUC.UpdateAddress(c.Request.Context(), currentUserID(c), c.Param("addressId"), req.toAddress())
+	if err != nil {
+		addressError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, address)
+}
+
+// RemoveMyAddress godoc
+// @Summary Remove an address of the current user
+// @Description Remove an address; removing the primary address makes the first remaining address primary
+// @Tags users
+// @Security BearerAuth
+// @Param addressId path string true "Address ID"
+// @Success 204 "Address removed"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User or address not found"
+// @Router /users/me/addresses/{addressId} [delete]
+func (h *UserHandler) RemoveMyAddress(c *gin.Context) {
+	if err := h.userUC.RemoveAddress(c.Request.Context(), currentUserID(c), c.Param("addressId")); err != nil {
+		addressError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func addressError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditUC ports.AuditUseCase
+}
+
+func NewAuditHandler(auditUC ports.AuditUseCase) *AuditHandler {
+	return &AuditHandler{
+		auditUC: auditUC,
+	}
+}
+
+// ListAuditEvents godoc
+// @Summary List audit events
+// @Description Retrieve a paginated list of audit events, newest first, optionally filtered by action, actor or target
+// @Tags audit
+// @Produce json
+// @Security BearerAuth
+// @Param action query string false "Audit action" example("impersonation.started")
+// @Param actor_id query string false "Actor user UUID"
+// @Param target_id query string false "Target resource UUID"
+// @Param page query int false "Page number (1-based)" default(1) minimum(1)
+// @Param page_size query int false "Number of events per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} ports.AuditQueryResult "Audit events with pagination info"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "audit:read permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/audit-logs [get]
+func (h *AuditHandler) ListAuditEvents(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+
+	result, err := h.auditUC.List(c.Request.Context(), &ports.AuditQuery{
+		Action:   c.Query("action"),
+		ActorID:  c.Query("actor_id"),
+		TargetID: c.Query("target_id"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
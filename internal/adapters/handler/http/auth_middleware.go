@@ -0,0 +1,369 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+	"github.com/gin-gonic/gin"
+)
+
+// Gin context keys holding the authenticated user ID and roles, or the authenticated OAuth2 client,
+// the scopes of restricted tokens, when the user last authenticated, the token generation and the
+// cookie session of the request
+const (
+	userIDKey       = "userID"
+	userRolesKey    = "userRoles"
+	impersonatorKey = "impersonatorID"
+	clientIDKey     = "clientID"
+	tokenScopesKey  = "tokenScopes"
+	authTimeKey     = "authTime"
+	generationKey   = "tokenGeneration"
+	sessionKey      = "session"
+	tokenIDKey      = "tokenID"
+)
+
+// ErrUserTokenRequired rejects the tokens of OAuth2 clients on the routes acting for a user
+var ErrUserTokenRequired = errors.New("user token required: client tokens cannot access this route")
+
+// ErrTokenRevoked rejects the tokens issued before the last logout of their user from all devices
+var ErrTokenRevoked = errors.New("token revoked: the user logged out of all devices")
+
+// ErrClientRevoked rejects the tokens of deleted OAuth2 clients
+var ErrClientRevoked = errors.New("token revoked: the client was deleted")
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer <token>" header
+// or issued for another tenant, and stores the authenticated user ID in the request context.
+// Tokens not issued by the API are authenticated with external, unless it is nil. Requests
+// without a bearer token are authenticated by their session cookie when sessions is not nil.
+// Tokens of OAuth2 clients are refused, see RequireAuthOrClient.
+func RequireAuth(tokens *security.TokenManager, external ports.ExternalAuthUseCase, sessions ports.SessionUseCase) gin.HandlerFunc {
+	return requireAuth(tokens, external, sessions, false)
+}
+
+// RequireAuthOrClient authenticates requests like RequireAuth but also accepts the tokens of
+// OAuth2 clients, for routes guarded by RequirePermission, which checks the scopes of clients
+func RequireAuthOrClient(tokens *security.TokenManager, external ports.ExternalAuthUseCase, sessions ports.SessionUseCase) gin.HandlerFunc {
+	return requireAuth(tokens, external, sessions, true)
+}
+
+func requireAuth(tokens *security.TokenManager, external ports.ExternalAuthUseCase, sessions ports.SessionUseCase, allowClients bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := authenticate(c, tokens, external, sessions); err != nil {
+			status := http.StatusUnauthorized
+			// Outages must not log clients out
+			if errors.Is(err, ports.ErrDatabaseUnavailable) || errors.Is(err, ports.ErrIdentityProviderUnavailable) {
+				status = http.StatusServiceUnavailable
+			}
+			c.AbortWithStatusJSON(status, errorResponse(status, err))
+			return
+		}
+		// Client tokens do not authenticate a user, whose routes answer as for a missing token
+		if !allowClients && currentClientID(c) != "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(http.StatusUnauthorized, ErrUserTokenRequired))
+			return
+		}
+		withActor(c)
+		c.Next()
+	}
+}
+
+// OptionalAuth authenticates the request like RequireAuth when it carries a valid bearer token
+// or session cookie and lets it through anonymously otherwise, for public routes that show more to
+// some callers
+func OptionalAuth(tokens *security.TokenManager, external ports.ExternalAuthUseCase, sessions ports.SessionUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := authenticate(c, tokens, external, sessions); err == nil {
+			withActor(c)
+		}
+		c.Next()
+	}
+}
+
+// authenticate checks the bearer token of the request, or else its session cookie, and stores the
+// user, or the client, it was issued to
+func authenticate(c *gin.Context, tokens *security.TokenManager, external ports.ExternalAuthUseCase, sessions ports.SessionUseCase) error {
+	header := c.GetHeader("Authorization")
+	tokenString, found := strings.CutPrefix(header, "Bearer ")
+	if !found || tokenString == "" {
+		if cookie, _ := c.Cookie(sessionCookie); sessions != nil && cookie != "" {
+			return authenticateSession(c, sessions, cookie)
+		}
+		return errors.New("missing bearer token")
+	}
+
+	claims, err := tokens.Parse(tokenString)
+	if err != nil {
+		if external == nil {
+			return err
+		}
+		// Users of the identity provider are looked up in the tenant of the request
+		user, err := external.AuthenticateToken(c.Request.Context(), tokenString)
+		if err != nil {
+			return err
+		}
+		c.Set(userIDKey, user.ID)
+		c.Set(userRolesKey, user.EffectiveRoles())
+		return nil
+	}
+
+	if claims.Tenant != domain.TenantFromContext(c.Request.Context()) {
+		return errors.New("token was issued for a different tenant")
+	}
+	c.Set(tokenIDKey, claims.ID)
+	if claims.Restricted() {
+		c.Set(tokenScopesKey, claims.Scopes())
+	}
+	if claims.IsClient() {
+		c.Set(clientIDKey, claims.ClientID)
+		return nil
+	}
+
+	c.Set(userIDKey, claims.Subject)
+	c.Set(userRolesKey, claims.Roles)
+	c.Set(generationKey, claims.Generation)
+	if claims.IsImpersonation() {
+		c.Set(impersonatorKey, claims.Actor.Subject)
+	}
+	if claims.AuthTime != nil {
+		c.Set(authTimeKey, claims.AuthTime.Time)
+	}
+	return nil
+}
+
+// authenticateSession checks the session of a cookie token and stores its user like the claims of a
+// token; RequireCSRFToken checks the unsafe requests of the session
+func authenticateSession(c *gin.Context, sessions ports.SessionUseCase, token string) error {
+	session, err := sessions.Authenticate(c.Request.Context(), token)
+	if err != nil {
+		return err
+	}
+
+	c.Set(userIDKey, session.UserID)
+	c.Set(userRolesKey, session.Roles)
+	c.Set(tokenIDKey, session.ID)
+	c.Set(generationKey, session.Generation)
+	c.Set(authTimeKey, session.AuthTime)
+	if len(session.Scopes) > 0 {
+		c.Set(tokenScopesKey, session.Scopes)
+	}
+	c.Set(sessionKey, session)
+	return nil
+}
+
+// RejectRevokedTokens rejects the tokens of the API, and the cookie sessions, issued to a user before
+// their last logout from all devices, and the tokens of OAuth2 clients deleted since; the tokens of the
+// identity provider pass. It must run after RequireAuth, RequireAuthOrClient or OptionalAuth.
+func RejectRevokedTokens(users ports.UserUseCase, clients ports.OAuthClientUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if clientID := currentClientID(c); clientID != "" {
+			if _, err := clients.GetClient(c.Request.Context(), clientID); err != nil {
+				status := http.StatusInternalServerError
+				switch {
+				case strings.Contains(err.Error(), "not found"):
+					status, err = http.StatusUnauthorized, ErrClientRevoked
+				case errors.Is(err, ports.ErrDatabaseUnavailable):
+					status = http.StatusServiceUnavailable
+				}
+				c.AbortWithStatusJSON(status, errorResponse(status, err))
+				return
+			}
+			c.Next()
+			return
+		}
+		if _, ok := c.Get(generationKey); !ok {
+			c.Next()
+			return
+		}
+		current, err := users.TokenGeneration(c.Request.Context(), currentUserID(c))
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, ports.ErrDatabaseUnavailable) {
+				status = http.StatusServiceUnavailable
+			}
+			c.AbortWithStatusJSON(status, errorResponse(status, err))
+			return
+		}
+		if c.GetInt(generationKey) < current {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(http.StatusUnauthorized, ErrTokenRevoked))
+			return
+		}
+		c.Next()
+	}
+}
+
+// AuditImpersonation records every request made with an impersonation token; it must run after RequireAuth
+func AuditImpersonation(audit ports.AuditUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		impersonatorID := c.GetString(impersonatorKey)
+		if impersonatorID == "" {
+			c.Next()
+			return
+		}
+
+		c.Next()
+
+		details := map[string]string{
+			"method": c.Request.Method,
+			"path":   c.Request.URL.Path,
+			"status": strconv.Itoa(c.Writer.Status()),
+			"ip":     c.ClientIP(),
+		}
+		if err := audit.Record(c.Request.Context(), domain.AuditActionImpersonatedRequest, impersonatorID, currentUserID(c), details); err != nil {
+			log.Printf("Error recording impersonated request audit event: %v", err)
+		}
+	}
+}
+
+// RequirePermission rejects authenticated requests whose roles, or client scopes, do not grant
+// the permission; it must run after RequireAuth or RequireAuthOrClient
+func RequirePermission(roles ports.RoleUseCase, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		allowed, err := callerHasPermission(c, roles, permission)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+			return
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Code: errcode.PermissionDenied, Error: "insufficient permissions"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireScope rejects the requests made with tokens restricted to scopes other than scope, see
+// domain.TokenScopes; unrestricted tokens pass. It must run after RequireAuth or RequireAuthOrClient.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if scopes, restricted := currentScopes(c); restricted && !slices.Contains(scopes, scope) {
+			// RFC 6750 names the scope the token is missing
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+			c.AbortWithStatusJSON(http.StatusForbidden, ErrorResponse{Code: errcode.ScopeInsufficient, Error: "insufficient scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRecentAuth rejects the requests of users who did not prove their password or a second
+// factor within maxAge, asking them to go through /auth/reauthenticate. Tokens without an auth time,
+// such as impersonation tokens and those of the identity provider, never pass. It must run after
+// RequireAuth.
+func RequireRecentAuth(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authTime, ok := currentAuthTime(c)
+		if !ok || time.Since(authTime) > maxAge {
+			// RFC 9470 asks the client to step up the authentication of the user
+			c.Header("WWW-Authenticate", `Bearer error="insufficient_user_authentication", max_age=`+strconv.Itoa(int(maxAge/time.Second)))
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Code: errcode.ReauthenticationNeeded, Error: "recent authentication required: confirm your password or a second factor"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CheckPermission records whether the roles of the caller grant the permission, for handlers
+// that only show some fields to privileged callers; see hasPermission. Anonymous callers have none.
+func CheckPermission(roles ports.RoleUseCase, permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if currentActorID(c) != "" {
+			allowed, err := callerHasPermission(c, roles, permission)
+			if err != nil {
+				log.Printf("Error checking permission %s: %v", permission, err)
+			}
+			c.Set(permissionKey(permission), allowed)
+		}
+		c.Next()
+	}
+}
+
+// callerHasPermission checks the roles of the authenticated user or the scopes of the authenticated client
+func callerHasPermission(c *gin.Context, roles ports.RoleUseCase, permission string) (bool, error) {
+	if currentClientID(c) != "" {
+		return slices.Contains(c.GetStringSlice(tokenScopesKey), permission), nil
+	}
+	return roles.HasPermission(c.Request.Context(), c.GetStringSlice(userRolesKey), permission)
+}
+
+// hasPermission reports whether CheckPermission found that the caller holds the permission
+func hasPermission(c *gin.Context, permission string) bool {
+	return c.GetBool(permissionKey(permission))
+}
+
+func permissionKey(permission string) string {
+	return "permission:" + permission
+}
+
+// currentUserID returns the ID of the authenticated user set by RequireAuth
+func currentUserID(c *gin.Context) string {
+	return c.GetString(userIDKey)
+}
+
+// currentClientID returns the ID of the OAuth2 client authenticated by RequireAuthOrClient, or an empty string
+func currentClientID(c *gin.Context) string {
+	return c.GetString(clientIDKey)
+}
+
+// currentActorID identifies the caller in audit events and rate limits: the authenticated user,
+// or "client:<id>" for an OAuth2 client
+func currentActorID(c *gin.Context) string {
+	if clientID := currentClientID(c); clientID != "" {
+		return "client:" + clientID
+	}
+	return currentUserID(c)
+}
+
+// withActor carries the caller authenticated by the request in its context, see domain.ActorFromContext,
+// so the writes it makes are attributed to it in the audit log, the change history and the UpdatedBy
+// field of the users. The writes made with an impersonation token are attributed to the admin.
+func withActor(c *gin.Context) {
+	actorID := currentActorID(c)
+	if impersonatorID := currentImpersonatorID(c); impersonatorID != "" {
+		actorID = impersonatorID
+	}
+	c.Request = c.Request.WithContext(domain.WithActor(c.Request.Context(), domain.Actor{
+		ID:      actorID,
+		TokenID: c.GetString(tokenIDKey),
+		IP:      c.ClientIP(),
+	}))
+}
+
+// currentScopes returns the scopes of the token of the request and whether it is restricted to them
+func currentScopes(c *gin.Context) ([]string, bool) {
+	if _, restricted := c.Get(tokenScopesKey); !restricted {
+		return nil, false
+	}
+	return c.GetStringSlice(tokenScopesKey), true
+}
+
+// currentAuthTime returns when the authenticated user last proved their password or a second
+// factor, and false when the token does not tell
+func currentAuthTime(c *gin.Context) (time.Time, bool) {
+	value, ok := c.Get(authTimeKey)
+	if !ok {
+		return time.Time{}, false
+	}
+	authTime, ok := value.(time.Time)
+	return authTime, ok
+}
+
+// currentSession returns the cookie session authenticating the request, or nil for bearer tokens
+func currentSession(c *gin.Context) *domain.Session {
+	value, _ := c.Get(sessionKey)
+	session, _ := value.(*domain.Session)
+	return session
+}
+
+// currentImpersonatorID returns the ID of the admin impersonating the current user, or an empty string
+func currentImpersonatorID(c *gin.Context) string {
+	return c.GetString(impersonatorKey)
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UserSuggestion is a user suggested by the autocomplete, with only what pickers show
+type UserSuggestion struct {
+	ID    string `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Name  string `json:"name" example:"John Doe"`
+	Email string `json:"email" example:"john.doe@example.com"`
+}
+
+// AutocompleteUsersResponse lists the users suggested for a prefix
+type AutocompleteUsersResponse struct {
+	Users []UserSuggestion `json:"users"`
+}
+
+// AutocompleteUsers godoc
+// @Summary Autocomplete users
+// @Description Suggest the users whose email, username, first name or last name starts with q, or whose first and last names start with its two words
+// @Description ("john d"), sorted by name, for typeahead pickers; only their ID, name and email are returned, and users blocking the caller are omitted
+// @Description Prefixes use the indexes of the users collection: emails and usernames are matched exactly, names ignoring case
+// @Tags users
+// @Produce json
+// @Param q query string true "Prefix typed so far, 1 to 100 characters" example("jo")
+// @Param limit query int false "Number of suggestions" default(10) minimum(1) maximum(50)
+// @Success 200 {object} AutocompleteUsersResponse "Suggested users"
+// @Failure 400 {object} ErrorResponse "Bad request - missing or too long q"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/autocomplete [get]
+func (h *UserHandler) AutocompleteUsers(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	users, err := h.userUC.AutocompleteUsers(c.Request.Context(), c.Query("q"), limit)
+	if err == nil {
+		users, err = h.visibleUsers(c, users)
+	}
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+
+	suggestions := make([]UserSuggestion, 0, len(users))
+	for _, user := range users {
+		suggestions = append(suggestions, UserSuggestion{
+			ID:    user.ID,
+			Name:  strings.TrimSpace(user.Profile.FirstName + " " + user.Profile.LastName),
+			Email: user.Email,
+		})
+	}
+	c.JSON(http.StatusOK, AutocompleteUsersResponse{Users: suggestions})
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+type AvailabilityHandler struct {
+	userUC   ports.UserUseCase
+	maxDelay time.Duration
+}
+
+// EmailAvailabilityResponse reports whether an email can be used to register
+type EmailAvailabilityResponse struct {
+	Email     string `json:"email" example:"john.doe@example.com"`
+	Available bool   `json:"available" example:"true"`
+}
+
+// NewAvailabilityHandler creates the availability handler; every response is delayed by a
+// random duration up to maxDelay (0 disables it) so response times don't reveal existing accounts
+func NewAvailabilityHandler(userUC ports.UserUseCase, maxDelay time.Duration) *AvailabilityHandler {
+	return &AvailabilityHandler{
+		userUC:   userUC,
+		maxDelay: maxDelay,
+	}
+}
+
+// CheckEmail godoc
+// @Summary Check email availability
+// @Description Check whether an email is still available for registration, for signup forms
+// @Description Requests are rate limited per IP to make account enumeration harder
+// @Tags users
+// @Produce json
+// @Param email query string true "Email to check" example("john.doe@example.com")
+// @Success 200 {object} EmailAvailabilityResponse "Email availability"
+// @Failure 400 {object} ErrorResponse "Bad request - missing or invalid email"
+// @Failure 429 {object} ErrorResponse "Too many requests"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/check-email [get]
+func (h *AvailabilityHandler) CheckEmail(c *gin.Context) {
+	email := strings.TrimSpace(strings.ToLower(c.Query("email")))
+	if email == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: errcode.ValidationFailed, Error: "email query parameter is required"})
+		return
+	}
+
+	h.delay()
+	available, err := h.userUC.IsEmailAvailable(c.Request.Context(), email)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, EmailAvailabilityResponse{Email: email, Available: available})
+}
+
+// delay sleeps for a random duration up to maxDelay
+func (h *AvailabilityHandler) delay() {
+	if h.maxDelay > 0 {
+		time.Sleep(rand.N(h.maxDelay))
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxAvatarSize is the maximum accepted avatar upload size in bytes (5 MB)
+const MaxAvatarSize = 5 << 20
+
+type AvatarHandler struct {
+	avatarUC ports.AvatarUseCase
+}
+
+func NewAvatarHandler(avatarUC ports.AvatarUseCase) *AvatarHandler {
+	return &AvatarHandler{
+		avatarUC: avatarUC,
+	}
+}
+
+// UploadAvatar godoc
+// @Summary Upload user avatar
+// @Description Upload a JPEG, PNG or GIF avatar for a user (max 5 MB), by the user or by staff holding users:profile
+// @Description Thumbnail and medium sizes are generated in the background; their URLs appear on the user once processing finishes
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Security BearerAuth
+// @Param avatar formData file true "Avatar image"
+// @Success 202 {object} domain.Avatar "Avatar accepted for processing"
+// @Failure 400 {object} ErrorResponse "Bad request - missing or invalid image"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Avatar of another user without the users:profile permission"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 413 {object} ErrorResponse "Avatar exceeds the maximum size"
+// @Router /users/{id}/avatar [post]
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	idParam := c.Param("id")
+	if currentUserID(c) != idParam && !hasPermission(c, domain.PermissionUsersProfile) {
+		c.JSON(http.StatusForbidden, ErrorResponse{Code: errcode.PermissionDenied, Error: "insufficient permissions"})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("avatar")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: errcode.ValidationFailed, Error: "avatar file is required"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > MaxAvatarSize {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Code: errcode.PayloadTooLarge, Error: "avatar exceeds the maximum size of 5 MB"})
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxAvatarSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		return
+	}
+
+	avatar, err := h.avatarUC.UploadAvatar(c.Request.Context(), idParam, data)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+		case strings.Contains(err.Error(), "image"):
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, avatar)
+}
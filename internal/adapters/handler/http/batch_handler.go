@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// BatchUpdateUsersRequest selects users with a structured filter, a list of IDs or both, and lists
+// the changes applied to all of them
+type BatchUpdateUsersRequest struct {
+	// Filter has the syntax of /users/search
+	Filter *domain.SearchFilter   `json:"filter,omitempty"`
+	IDs    []string               `json:"ids,omitempty" binding:"max=1000" example:"550e8400-e29b-41d4-a716-446655440000,7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Update domain.UserBatchUpdate `json:"update"`
+}
+
+// BatchUpdateUsers godoc
+// @Summary Update many users at once
+// @Description Apply the same change to every user matching a structured filter (as in /users/search) and, when set, listed in ids (up to 1000):
+// @Description add or remove tags, or suspend (deactivated: true) or reactivate them, in a single database update
+// @Description Suspended users cannot log in and their tokens are revoked. Every batch is recorded as users.batch_updated in the audit log
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BatchUpdateUsersRequest true "Selected users and changes"
+// @Success 200 {object} ports.UserBatchResult "Number of users matched and modified"
+// @Failure 400 {object} ErrorResponse "Bad request - no users selected, invalid filter, tag or change"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:batch permission required"
+// @Router /admin/users/batch [patch]
+func (h *UserHandler) BatchUpdateUsers(c *gin.Context) {
+	var req BatchUpdateUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	opts := &ports.GetUsersOptions{Filter: req.Filter, IDs: req.IDs}
+	result, err := h.userUC.BatchUpdateUsers(c.Request.Context(), currentActorID(c), opts, req.Update)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
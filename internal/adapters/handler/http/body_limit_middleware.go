@@ -0,0 +1,97 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimits bounds the request bodies accepted on POST, PUT and PATCH routes
+type BodyLimits struct {
+	MaxBytes       int64 // Maximum body size of regular requests
+	MaxUploadBytes int64 // Maximum body size of multipart/form-data uploads
+	MaxJSONDepth   int   // Maximum nesting of JSON objects and arrays
+}
+
+// DefaultBodyLimits allows 1 MB bodies, 6 MB uploads (5 MB avatar plus form overhead) and 32 JSON levels
+func DefaultBodyLimits() BodyLimits {
+	return BodyLimits{
+		MaxBytes:       1 << 20,
+		MaxUploadBytes: 6 << 20,
+		MaxJSONDepth:   32,
+	}
+}
+
+// LimitRequestBody rejects oversized or too deeply nested bodies with 413 before they are bound
+func LimitRequestBody(limits BodyLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+
+		maxBytes := limits.MaxBytes
+		if strings.HasPrefix(c.ContentType(), "multipart/") {
+			maxBytes = limits.MaxUploadBytes
+		}
+		if c.Request.ContentLength > maxBytes {
+			abortTooLarge(c, fmt.Sprintf("request body exceeds the maximum size of %d bytes", maxBytes))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+
+		// Handlers bind JSON whatever the Content-Type, so every body but the uploads is scanned
+		if !strings.HasPrefix(c.ContentType(), "multipart/") {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					abortTooLarge(c, fmt.Sprintf("request body exceeds the maximum size of %d bytes", maxBytes))
+				} else {
+					c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+				}
+				return
+			}
+			if jsonDepth(body) > limits.MaxJSONDepth {
+				abortTooLarge(c, fmt.Sprintf("request body nests JSON deeper than %d levels", limits.MaxJSONDepth))
+				return
+			}
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		c.Next()
+	}
+}
+
+func abortTooLarge(c *gin.Context, message string) {
+	c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, ErrorResponse{Code: errcode.PayloadTooLarge, Error: message})
+}
+
+// jsonDepth returns the maximum nesting of objects and arrays in data.
+// Invalid JSON stops the scan; the handler reports the syntax error when binding.
+func jsonDepth(data []byte) int {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	depth, maxDepth := 0, 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			return maxDepth
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+			maxDepth = max(maxDepth, depth)
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package http
+
+import (
+	"bytes"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// FailFastWhenDatabaseUnavailable answers 503 with a Retry-After header while the database circuit
+// breaker is open, instead of letting requests wait for a database that is down. Requests rejected
+// by the breaker deeper in the handler, e.g. while it is half-open, get a 503 as well.
+func FailFastWhenDatabaseUnavailable(breaker ports.BreakerReporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if breaker == nil {
+			c.Next()
+			return
+		}
+
+		if stats := breaker.BreakerStats(); stats.State == ports.BreakerOpen {
+			c.Header("Retry-After", retryAfter(stats.OpenUntil))
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, ErrorResponse{Code: errcode.DatabaseUnavailable, Error: ports.ErrDatabaseUnavailable.Error()})
+			return
+		}
+
+		c.Writer = &unavailableWriter{ResponseWriter: c.Writer}
+		c.Next()
+	}
+}
+
+// retryAfter returns the seconds until the breaker lets a probe through, at least one
+func retryAfter(openUntil *time.Time) string {
+	seconds := 1.0
+	if openUntil != nil {
+		seconds = max(seconds, math.Ceil(time.Until(*openUntil).Seconds()))
+	}
+	return strconv.Itoa(int(seconds))
+}
+
+// unavailableWriter turns the 500 responses of handlers that got ports.ErrDatabaseUnavailable into 503s
+type unavailableWriter struct {
+	gin.ResponseWriter
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to change its deadlines
+func (w *unavailableWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *unavailableWriter) Write(data []byte) (int, error) {
+	if !w.Written() && w.Status() == http.StatusInternalServerError &&
+		bytes.Contains(data, []byte(ports.ErrDatabaseUnavailable.Error())) {
+		w.Header().Set("Retry-After", retryAfter(nil))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *unavailableWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
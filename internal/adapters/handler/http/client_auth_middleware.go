@@ -0,0 +1,31 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientCredentials are the secrets of the API clients by client ID, such as the resource servers
+// introspecting tokens
+type ClientCredentials map[string]string
+
+// RequireClient rejects requests without the HTTP basic credentials of one of the clients, the
+// client_secret_basic authentication of RFC 6749
+func RequireClient(clients ClientCredentials, realm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, secret, found := c.Request.BasicAuth()
+		expected, known := clients[id]
+		// Digests of equal length keep the comparison constant-time
+		given, want := sha256.Sum256([]byte(secret)), sha256.Sum256([]byte(expected))
+		if !found || !known || subtle.ConstantTimeCompare(given[:], want[:]) != 1 {
+			c.Header("WWW-Authenticate", `Basic realm="`+realm+`"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(http.StatusUnauthorized, errors.New("invalid client credentials")))
+			return
+		}
+		c.Next()
+	}
+}
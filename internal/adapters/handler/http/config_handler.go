@@ -0,0 +1,93 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type ConfigHandler struct {
+	configUC ports.ConfigUseCase
+}
+
+// FeaturesResponse lists the feature flags of the instance
+type FeaturesResponse struct {
+	Features map[string]bool `json:"features"`
+}
+
+func NewConfigHandler(configUC ports.ConfigUseCase) *ConfigHandler {
+	return &ConfigHandler{
+		configUC: configUC,
+	}
+}
+
+// GetConfig godoc
+// @Summary Get the runtime configuration
+// @Description The configuration of this instance that is reloaded without restarting it: the log level of
+// @Description the request log, the rate limits, the feature flags and the CORS origins
+// @Tags system
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ports.RuntimeConfig "Runtime configuration"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "system:read permission required"
+// @Router /admin/system/config [get]
+func (h *ConfigHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, h.configUC.Config())
+}
+
+// UpdateConfig godoc
+// @Summary Update the runtime configuration
+// @Description Change the fields of the runtime configuration that are set, on this instance only; the
+// @Description feature flags and the CORS origins are replaced as a whole. The changed fields are recorded
+// @Description in the audit log. Only the admins of the tenant managing the instances can change it.
+// @Tags system
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param config body ports.RuntimeConfigUpdate true "Fields to change"
+// @Success 200 {object} ports.RuntimeConfig "Updated configuration"
+// @Failure 400 {object} ErrorResponse "Invalid log level, rate limit, feature flag or CORS origin"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "system:config permission required"
+// @Failure 404 {object} ErrorResponse "Configuration not managed by this tenant"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/system/config [patch]
+func (h *ConfigHandler) UpdateConfig(c *gin.Context) {
+	var req ports.RuntimeConfigUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+	config, err := h.configUC.UpdateConfig(c.Request.Context(), currentActorID(c), &req)
+	if err != nil {
+		configError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, config)
+}
+
+// GetFeatures godoc
+// @Summary List the feature flags
+// @Description The feature flags clients enable features with, the same for every tenant
+// @Tags meta
+// @Produce json
+// @Success 200 {object} FeaturesResponse "Feature flags"
+// @Router /features [get]
+func (h *ConfigHandler) GetFeatures(c *gin.Context) {
+	c.Header("Cache-Control", "no-cache")
+	c.JSON(http.StatusOK, FeaturesResponse{Features: h.configUC.Config().Features})
+}
+
+func configError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not enabled"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
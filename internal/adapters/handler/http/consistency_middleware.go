@@ -0,0 +1,69 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// consistencyTokenHeader carries the position in the history of the database reached by a request,
+// which the next requests of the client send back to read its writes
+const consistencyTokenHeader = "X-Consistency-Token"
+
+// ReadYourWrites makes the user reads and writes of the requests causally consistent, so their reads
+// see the writes made before them, even when served by the secondaries. A request starts after the
+// position of its X-Consistency-Token header, when set, and its response carries the position it
+// reached in the same header, e.g. for a GET right after a registration to find the user.
+func ReadYourWrites() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, consistency := domain.WithCausalConsistency(c.Request.Context(), c.GetHeader(consistencyTokenHeader))
+		c.Request = c.Request.WithContext(ctx)
+
+		w := &consistencyWriter{ResponseWriter: c.Writer, consistency: consistency}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		// Responses without a body are written after the handlers
+		if !w.Written() {
+			w.setToken()
+		}
+	}
+}
+
+// consistencyWriter sets the X-Consistency-Token header of the position reached when the response
+// is written
+type consistencyWriter struct {
+	gin.ResponseWriter
+	consistency *domain.CausalConsistency
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to change its deadlines
+func (w *consistencyWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *consistencyWriter) setToken() {
+	if token := w.consistency.Token(); token != "" {
+		w.Header().Set(consistencyTokenHeader, token)
+	}
+}
+
+func (w *consistencyWriter) WriteHeaderNow() {
+	if !w.Written() {
+		w.setToken()
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *consistencyWriter) Write(data []byte) (int, error) {
+	if !w.Written() {
+		w.setToken()
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *consistencyWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,108 @@
+package http
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/pkg/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// maxValidatedBody bounds the request and response bodies kept to be checked by ValidateContract;
+// larger bodies, such as exports, only have their status checked
+const maxValidatedBody = 1 << 20
+
+// LoadContract parses the Swagger document of the API for ValidateContract. Every route may also
+// answer the errors of the middleware in front of the handlers: 400 for an invalid tenant, 413
+// for an oversized body, 429 past a rate limit, 451 until the current terms are accepted and 503
+// while the database is unavailable.
+func LoadContract(doc []byte) (*openapi.Spec, error) {
+	spec, err := openapi.Load(doc)
+	if err != nil {
+		return nil, err
+	}
+	errorSchema := &openapi.Schema{Ref: "#/definitions/http.ErrorResponse"}
+	spec.Shared = map[int]*openapi.Schema{
+		http.StatusBadRequest:                 errorSchema,
+		http.StatusRequestEntityTooLarge:      errorSchema,
+		http.StatusTooManyRequests:            errorSchema,
+		http.StatusUnavailableForLegalReasons: errorSchema,
+		http.StatusServiceUnavailable:         errorSchema,
+	}
+	return spec, nil
+}
+
+// ValidateContract logs the requests and responses of the routes that do not match their Swagger
+// documentation, so drift between the docs and the handlers shows up in development and staging.
+// Requests are served unchanged; it is meant to be installed in front of every other middleware.
+func ValidateContract(spec *openapi.Spec) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if spec == nil || route == "" {
+			c.Next()
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			read, err := io.ReadAll(io.LimitReader(c.Request.Body, maxValidatedBody+1))
+			if err == nil {
+				c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(read), c.Request.Body), c.Request.Body}
+				if len(read) <= maxValidatedBody {
+					body = read
+				}
+			}
+		}
+		if err := spec.ValidateRequest(route, c.Request, body); err != nil {
+			log.Printf("OpenAPI contract violation in request %s %s: %v", c.Request.Method, route, err)
+		}
+
+		w := &contractWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.truncated {
+			return
+		}
+		if err := spec.ValidateResponse(c.Request.Method, route, w.Status(), w.Header(), w.body.Bytes()); err != nil {
+			log.Printf("OpenAPI contract violation in response %d to %s %s: %v", w.Status(), c.Request.Method, route, err)
+		}
+	}
+}
+
+// readCloser reads a request body that was partly read already and closes the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// contractWriter keeps a copy of the response body for ValidateContract
+type contractWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to change its deadlines
+func (w *contractWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *contractWriter) Write(data []byte) (int, error) {
+	if !w.truncated {
+		if w.body.Len()+len(data) > maxValidatedBody {
+			w.truncated = true
+			w.body.Reset()
+		} else {
+			w.body.Write(data)
+		}
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *contractWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// corsMaxAge is how long browsers cache the answer to a preflight request, in seconds
+const corsMaxAge = 600
+
+// corsExposedHeaders are the response headers browsers let the scripts of other origins read
+const corsExposedHeaders = "Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-Page, X-Page-Size, X-Total-Count, X-Total-Pages, X-Consistency-Token, Content-Disposition"
+
+// CORS lets the browsers of the origins of config call the API, answering their preflight requests.
+// Allowed origins may send credentials, such as the session cookie, unless they are only allowed by
+// "*". The origins are read on every request, so reloaded origins apply at once. It must be used
+// before the routes, preflight requests not matching any.
+func CORS(config ports.ConfigUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		origins := config.Config().CORSOrigins
+		c.Writer.Header().Add("Vary", "Origin")
+		switch {
+		case slices.Contains(origins, origin):
+			c.Header("Access-Control-Allow-Origin", origin)
+			c.Header("Access-Control-Allow-Credentials", "true")
+		case slices.Contains(origins, "*"):
+			c.Header("Access-Control-Allow-Origin", "*")
+		default:
+			c.Next()
+			return
+		}
+		c.Header("Access-Control-Expose-Headers", corsExposedHeaders)
+
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+			if headers := c.GetHeader("Access-Control-Request-Headers"); headers != "" {
+				c.Header("Access-Control-Allow-Headers", headers)
+			}
+			c.Header("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// The CSRF token of a cookie session is answered by the login and /auth/csrf-token, and set in a
+// cookie the scripts of the site read and echo in the header (double submit)
+const (
+	csrfTokenHeader = "X-CSRF-Token"
+	csrfCookie      = "csrf_token"
+	csrfCookiePath  = "/"
+)
+
+var (
+	// ErrCSRFTokenInvalid rejects the unsafe requests of cookie sessions without the CSRF token of the session
+	ErrCSRFTokenInvalid = errors.New("invalid csrf token: send the csrf_token cookie of the session in the X-CSRF-Token header")
+	// ErrCrossSiteRequest rejects the unsafe requests browsers report as sent by the pages of another site
+	ErrCrossSiteRequest = errors.New("invalid csrf token: cross-site requests cannot use the session cookie")
+)
+
+// RequireCSRFToken rejects the state-changing requests of cookie sessions, anything but GET, HEAD
+// and OPTIONS, unless their X-CSRF-Token header matches both the csrf_token cookie and the token of
+// the session. Requests browsers flag as cross-site with Sec-Fetch-Site are rejected outright, for
+// the browsers that send SameSite=Strict cookies along anyway. Bearer tokens pass, as browsers never
+// attach them on their own. It must run after RequireAuth, RequireAuthOrClient or OptionalAuth.
+func RequireCSRFToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := currentSession(c)
+		if session == nil || safeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		if c.GetHeader("Sec-Fetch-Site") == "cross-site" {
+			c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(http.StatusForbidden, ErrCrossSiteRequest))
+			return
+		}
+		token := c.GetHeader(csrfTokenHeader)
+		cookie, _ := c.Cookie(csrfCookie)
+		if token == "" ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(cookie)) != 1 ||
+			subtle.ConstantTimeCompare([]byte(domain.HashSessionToken(token)), []byte(session.CSRFTokenHash)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, errorResponse(http.StatusForbidden, ErrCSRFTokenInvalid))
+			return
+		}
+		c.Next()
+	}
+}
+
+func safeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// setCSRFCookie sets the CSRF token of a session in a cookie readable by the scripts of the site;
+// an empty token clears it
+func setCSRFCookie(c *gin.Context, token string, expiresAt time.Time) {
+	cookie := &http.Cookie{
+		Name:     csrfCookie,
+		Value:    token,
+		Path:     csrfCookiePath,
+		Expires:  expiresAt,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	}
+	if token == "" {
+		cookie.Expires, cookie.MaxAge = time.Time{}, -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
@@ -0,0 +1,347 @@
+// Admin dashboard of the User Management API. It logs in with the auth routes and calls the admin
+// routes, keeping the access token in the session storage of the tab, or in cookie mode relying on the
+// session cookie and sending its CSRF token. Data is only ever inserted as text.
+"use strict";
+
+const API = "/api/v1";
+const PAGE_SIZE = 20;
+
+const state = {
+  config: {},
+  token: sessionStorage.getItem("token") || "",
+  csrf: sessionStorage.getItem("csrf") || "",
+  tenant: sessionStorage.getItem("tenant") || "",
+  mfa: null,
+  roles: [],
+  users: { page: 1, search: "" },
+  audit: { page: 1, filters: {} },
+};
+
+const $ = (id) => document.getElementById(id);
+
+// el creates an element with text content and children
+function el(tag, attrs = {}, ...children) {
+  const node = document.createElement(tag);
+  for (const [name, value] of Object.entries(attrs)) {
+    if (name === "onclick") {
+      node.addEventListener("click", value);
+    } else {
+      node.setAttribute(name, value);
+    }
+  }
+  for (const child of children) {
+    node.append(child instanceof Node ? child : document.createTextNode(child ?? ""));
+  }
+  return node;
+}
+
+function showMessage(text, info = false) {
+  const message = $("message");
+  message.textContent = text;
+  message.className = info ? "info" : "";
+  message.hidden = !text;
+}
+
+class APIError extends Error {
+  constructor(status, body) {
+    super(body?.error || `HTTP ${status}`);
+    this.status = status;
+    this.code = body?.code;
+  }
+}
+
+// api calls a route of the API with the credentials of the login
+async function api(method, path, body) {
+  const headers = { Accept: "application/json" };
+  if (body !== undefined) {
+    headers["Content-Type"] = "application/json";
+  }
+  if (state.token) {
+    headers.Authorization = `Bearer ${state.token}`;
+  } else if (state.csrf && method !== "GET") {
+    headers["X-CSRF-Token"] = state.csrf;
+  }
+  if (state.tenant && state.config.tenant_header) {
+    headers[state.config.tenant_header] = state.tenant;
+  }
+
+  const resp = await fetch(API + path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+    credentials: "same-origin",
+  });
+  const text = await resp.text();
+  const data = text ? JSON.parse(text) : null;
+  if (!resp.ok) {
+    if (resp.status === 401 && !path.startsWith("/auth/")) {
+      endLogin();
+    }
+    throw new APIError(resp.status, data);
+  }
+  return { status: resp.status, data };
+}
+
+function query(params) {
+  const search = new URLSearchParams();
+  for (const [name, value] of Object.entries(params)) {
+    if (value !== "" && value !== undefined) {
+      search.set(name, value);
+    }
+  }
+  return search.toString();
+}
+
+function formatTime(value) {
+  return value ? new Date(value).toLocaleString() : "";
+}
+
+// Login
+
+function startLogin(data) {
+  state.token = data.access_token || "";
+  state.csrf = data.csrf_token || "";
+  sessionStorage.setItem("token", state.token);
+  sessionStorage.setItem("csrf", state.csrf);
+  sessionStorage.setItem("tenant", state.tenant);
+  state.mfa = null;
+  $("mfa-form").hidden = true;
+  $("login-form").hidden = false;
+  $("login-form").reset();
+  showMessage("");
+  route();
+}
+
+function endLogin() {
+  state.token = state.csrf = "";
+  sessionStorage.removeItem("token");
+  sessionStorage.removeItem("csrf");
+  route();
+}
+
+function loggedIn() {
+  return Boolean(state.token || state.csrf);
+}
+
+$("login-form").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const form = event.target;
+  state.tenant = form.elements.tenant.value.trim();
+  try {
+    const { status, data } = await api("POST", "/auth/login", { email: form.elements.email.value, password: form.elements.password.value });
+    if (status === 202) {
+      state.mfa = data;
+      const select = $("mfa-form").elements.factor;
+      select.replaceChildren(...data.factors.map((factor) =>
+        el("option", { value: factor.id }, factor.label || (factor.type === "sms" ? `SMS ${factor.phone}` : "Authenticator app"))));
+      form.hidden = true;
+      $("mfa-form").hidden = false;
+      return;
+    }
+    startLogin(data);
+  } catch (err) {
+    showMessage(err.message);
+  }
+});
+
+$("mfa-send").addEventListener("click", async () => {
+  try {
+    await api("POST", "/auth/mfa/challenge", { mfa_token: state.mfa.mfa_token, factor_id: $("mfa-form").elements.factor.value });
+    showMessage("Code sent.", true);
+  } catch (err) {
+    showMessage(err.message);
+  }
+});
+
+$("mfa-form").addEventListener("submit", async (event) => {
+  event.preventDefault();
+  const form = event.target;
+  try {
+    const { data } = await api("POST", "/auth/mfa/verify", {
+      mfa_token: state.mfa.mfa_token,
+      factor_id: form.elements.factor.value,
+      code: form.elements.code.value,
+    });
+    form.reset();
+    startLogin(data);
+  } catch (err) {
+    showMessage(err.message);
+  }
+});
+
+$("logout").addEventListener("click", async () => {
+  // Bearer tokens expire on their own, cookie sessions are ended
+  if (!state.token) {
+    await api("POST", "/auth/logout").catch(() => {});
+  }
+  endLogin();
+});
+
+// Users
+
+async function loadRoles() {
+  try {
+    const { data } = await api("GET", "/admin/roles");
+    state.roles = data.map((role) => role.name);
+  } catch {
+    state.roles = [];
+  }
+  for (const builtin of ["admin", "user"]) {
+    if (!state.roles.includes(builtin)) {
+      state.roles.push(builtin);
+    }
+  }
+  state.roles.sort();
+}
+
+async function loadUsers() {
+  const { page, search } = state.users;
+  try {
+    const { data } = await api("GET", "/users?" + query({ search, page, page_size: PAGE_SIZE, sort: "created_at", order: "desc" }));
+    $("users-rows").replaceChildren(...data.users.map(userRow));
+    pager($("users-pager"), data, (next) => {
+      state.users.page = next;
+      loadUsers();
+    });
+  } catch (err) {
+    showMessage(err.message);
+  }
+}
+
+function userRow(user) {
+  const deactivated = Boolean(user.deactivated_at);
+  const roles = el("td");
+  for (const role of user.roles || []) {
+    roles.append(el("span", { class: "role" }, role,
+      el("button", { type: "button", title: `Remove ${role}`, onclick: () => changeRole(user, role, "DELETE") }, "×")));
+  }
+  const add = el("select", {}, el("option", { value: "" }, "Add role…"),
+    ...state.roles.filter((role) => !(user.roles || []).includes(role)).map((role) => el("option", { value: role }, role)));
+  add.addEventListener("change", () => add.value && changeRole(user, add.value, "PUT"));
+  roles.append(add);
+
+  const name = [user.profile?.first_name, user.profile?.last_name].filter(Boolean).join(" ");
+  return el("tr", {},
+    el("td", {}, user.email),
+    el("td", {}, name),
+    roles,
+    el("td", { class: deactivated ? "status-deactivated" : "" }, deactivated ? "Suspended" : "Active"),
+    el("td", {}, formatTime(user.created_at)),
+    el("td", { class: "actions" },
+      el("button", { type: "button", class: deactivated ? "" : "danger", onclick: () => setSuspended(user, !deactivated) },
+        deactivated ? "Reactivate" : "Suspend"),
+      " ",
+      el("a", { href: `#audit?target_id=${encodeURIComponent(user.id)}` }, "Audit")));
+}
+
+async function changeRole(user, role, method) {
+  try {
+    await api(method, `/admin/users/${encodeURIComponent(user.id)}/roles/${encodeURIComponent(role)}`);
+    showMessage(`${method === "PUT" ? "Assigned" : "Removed"} ${role} ${method === "PUT" ? "to" : "from"} ${user.email}.`, true);
+  } catch (err) {
+    showMessage(err.message);
+  }
+  loadUsers();
+}
+
+async function setSuspended(user, suspended) {
+  if (suspended && !confirm(`Suspend ${user.email}? They are logged out and cannot log in until reactivated.`)) {
+    return;
+  }
+  try {
+    await api("PATCH", "/admin/users/batch", { ids: [user.id], update: { deactivated: suspended } });
+    showMessage(`${user.email} ${suspended ? "suspended" : "reactivated"}.`, true);
+  } catch (err) {
+    showMessage(err.message);
+  }
+  loadUsers();
+}
+
+$("users-search").addEventListener("submit", (event) => {
+  event.preventDefault();
+  state.users = { page: 1, search: event.target.elements.search.value.trim() };
+  loadUsers();
+});
+
+// Audit log
+
+async function loadAudit() {
+  const { page, filters } = state.audit;
+  try {
+    const { data } = await api("GET", "/admin/audit-logs?" + query({ ...filters, page, page_size: PAGE_SIZE }));
+    $("audit-rows").replaceChildren(...data.events.map((event) => el("tr", {},
+      el("td", {}, formatTime(event.created_at)),
+      el("td", {}, event.action),
+      el("td", {}, event.actor_id || ""),
+      el("td", {}, event.target_id || ""),
+      el("td", {}, event.ip || ""),
+      el("td", { class: "details" }, event.details ? JSON.stringify(event.details) : ""))));
+    pager($("audit-pager"), data, (next) => {
+      state.audit.page = next;
+      loadAudit();
+    });
+  } catch (err) {
+    showMessage(err.message);
+  }
+}
+
+$("audit-search").addEventListener("submit", (event) => {
+  event.preventDefault();
+  const fields = event.target.elements;
+  state.audit = {
+    page: 1,
+    filters: { action: fields.action.value.trim(), actor_id: fields.actor_id.value.trim(), target_id: fields.target_id.value.trim() },
+  };
+  loadAudit();
+});
+
+function pager(container, data, go) {
+  const previous = el("button", { type: "button", onclick: () => go(data.page - 1) }, "Previous");
+  const next = el("button", { type: "button", onclick: () => go(data.page + 1) }, "Next");
+  previous.disabled = data.page <= 1;
+  next.disabled = data.page >= data.total_pages;
+  container.replaceChildren(previous, `Page ${data.page} of ${Math.max(data.total_pages, 1)} (${data.total_count} total)`, next);
+}
+
+// Views, selected by the location hash: #users, or #audit with optional filters
+
+async function route() {
+  const [view, params] = (location.hash.slice(1) || "users").split("?");
+  $("nav").hidden = !loggedIn();
+  $("login-view").hidden = loggedIn();
+  $("users-view").hidden = !loggedIn() || view !== "users";
+  $("audit-view").hidden = !loggedIn() || view !== "audit";
+  for (const link of document.querySelectorAll("nav a")) {
+    link.classList.toggle("active", link.getAttribute("href") === `#${view}`);
+  }
+  if (!loggedIn()) {
+    return;
+  }
+
+  if (view === "audit") {
+    const filters = Object.fromEntries(new URLSearchParams(params));
+    const fields = $("audit-search").elements;
+    for (const name of ["action", "actor_id", "target_id"]) {
+      fields[name].value = filters[name] || "";
+    }
+    state.audit = { page: 1, filters };
+    loadAudit();
+  } else {
+    await loadRoles();
+    loadUsers();
+  }
+}
+
+window.addEventListener("hashchange", route);
+
+(async () => {
+  try {
+    const resp = await fetch("config.json");
+    state.config = await resp.json();
+  } catch {
+    state.config = {};
+  }
+  $("tenant-field").hidden = !state.config.tenant_header;
+  $("login-form").elements.tenant.value = state.tenant;
+  route();
+})();
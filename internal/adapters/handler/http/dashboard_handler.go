@@ -0,0 +1,59 @@
+package http
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed dashboard
+var dashboardFiles embed.FS
+
+// dashboardPolicy only lets the dashboard load its own scripts and styles and call the API of its origin
+const dashboardPolicy = "default-src 'none'; script-src 'self'; style-src 'self'; img-src 'self' data:; connect-src 'self'; " +
+	"base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// DashboardConfig is what the dashboard needs to know of the deployment to call the API
+type DashboardConfig struct {
+	// TenantHeader is the header carrying the tenant of the requests, empty when only subdomains select it
+	TenantHeader string `json:"tenant_header,omitempty" example:"X-Tenant-ID"`
+}
+
+// DashboardHandler serves the admin dashboard, a single-page app embedded in the binary. Its files
+// hold no data: the dashboard logs its user in and calls the admin routes of the API, which
+// check the admin scope and permissions of every request.
+type DashboardHandler struct {
+	files  http.Handler
+	config DashboardConfig
+}
+
+func NewDashboardHandler(config DashboardConfig) *DashboardHandler {
+	root, err := fs.Sub(dashboardFiles, "dashboard")
+	if err != nil {
+		panic(err)
+	}
+	return &DashboardHandler{
+		files:  http.FileServer(http.FS(root)),
+		config: config,
+	}
+}
+
+// ServeDashboard serves the files of the dashboard under /admin/, and its config at /admin/config.json
+func (h *DashboardHandler) ServeDashboard(c *gin.Context) {
+	c.Header("Content-Security-Policy", dashboardPolicy)
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Cache-Control", "no-cache")
+
+	path := c.Param("filepath")
+	if path == "/config.json" {
+		c.JSON(http.StatusOK, h.config)
+		return
+	}
+	req := c.Request.Clone(c.Request.Context())
+	req.URL.Path = path
+	h.files.ServeHTTP(c.Writer, req)
+}
@@ -0,0 +1,73 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type DirectorySyncHandler struct {
+	syncUC ports.DirectorySyncUseCase
+}
+
+func NewDirectorySyncHandler(syncUC ports.DirectorySyncUseCase) *DirectorySyncHandler {
+	return &DirectorySyncHandler{
+		syncUC: syncUC,
+	}
+}
+
+// GetStatus godoc
+// @Summary Get the directory sync status
+// @Description Report whether a sync with the LDAP directory is running and the changes of the last sync
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} ports.DirectorySyncStatus "Sync status and last report"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:sync permission required"
+// @Failure 404 {object} ErrorResponse "Directory sync is not enabled for the tenant"
+// @Router /admin/directory-sync [get]
+func (h *DirectorySyncHandler) GetStatus(c *gin.Context) {
+	status, err := h.syncUC.Status(c.Request.Context())
+	if err != nil {
+		directorySyncError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// TriggerSync godoc
+// @Summary Sync accounts with the directory
+// @Description Start a sync with the LDAP directory in the background, creating, updating and deactivating accounts to match its users
+// @Description A dry run only reports the changes; the report is returned by GET /admin/directory-sync once the sync finishes
+// @Tags users
+// @Security BearerAuth
+// @Param dry_run query bool false "Report the changes without making them" default(false)
+// @Success 202 "Sync started"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:sync permission required"
+// @Failure 404 {object} ErrorResponse "Directory sync is not enabled for the tenant"
+// @Failure 409 {object} ErrorResponse "A sync is already running"
+// @Router /admin/directory-sync [post]
+func (h *DirectorySyncHandler) TriggerSync(c *gin.Context) {
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+	if err := h.syncUC.TriggerSync(c.Request.Context(), currentActorID(c), dryRun); err != nil {
+		directorySyncError(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func directorySyncError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not enabled"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "already running"):
+		c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,162 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxDocumentSize is the maximum accepted document upload size in bytes (5 MB)
+const MaxDocumentSize = 5 << 20
+
+type DocumentHandler struct {
+	documentUC ports.DocumentUseCase
+}
+
+func NewDocumentHandler(documentUC ports.DocumentUseCase) *DocumentHandler {
+	return &DocumentHandler{
+		documentUC: documentUC,
+	}
+}
+
+// DocumentsResponse lists the identity documents of a user, newest first
+type DocumentsResponse struct {
+	Documents []*domain.Document `json:"documents"`
+}
+
+// UploadDocument godoc
+// @Summary Upload an identity document
+// @Description Upload a PDF, JPEG or PNG identity document for a user (max 5 MB), kept in private storage pending review
+// @Description Users upload their own documents; staff with the users:documents permission and an admin token upload the documents of any user
+// @Tags documents
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param file formData file true "Document file"
+// @Param type formData string true "Document type" Enums(passport, national_id, drivers_license, proof_of_address)
+// @Param expires_on formData string false "Expiry date of the document, YYYY-MM-DD" example("2030-01-31")
+// @Success 201 {object} domain.Document "Uploaded document"
+// @Failure 400 {object} ErrorResponse "Bad request - missing file, invalid type, file format or expiry date"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Documents of another user without the users:documents permission"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 413 {object} ErrorResponse "Document exceeds the maximum size"
+// @Router /users/{id}/documents [post]
+func (h *DocumentHandler) UploadDocument(c *gin.Context) {
+	if !authorizeDocuments(c, domain.ScopeUsersWrite) {
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: errcode.ValidationFailed, Error: "document file is required"})
+		return
+	}
+	defer file.Close()
+
+	if header.Size > MaxDocumentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Code: errcode.PayloadTooLarge, Error: "document exceeds the maximum size of 5 MB"})
+		return
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, MaxDocumentSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		return
+	}
+
+	document, err := h.documentUC.UploadDocument(c.Request.Context(), &ports.DocumentUpload{
+		UserID:     c.Param("id"),
+		UploadedBy: currentActorID(c),
+		Type:       c.PostForm("type"),
+		FileName:   header.Filename,
+		ExpiresOn:  c.PostForm("expires_on"),
+		Content:    content,
+	})
+	if err != nil {
+		documentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, document)
+}
+
+// ListDocuments godoc
+// @Summary List the identity documents of a user
+// @Description Retrieve the documents of a user, newest first; users list their own documents, staff with the users:documents permission and an admin token those of any user
+// @Tags documents
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Success 200 {object} DocumentsResponse "Documents of the user"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Documents of another user without the users:documents permission"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/{id}/documents [get]
+func (h *DocumentHandler) ListDocuments(c *gin.Context) {
+	if !authorizeDocuments(c, domain.ScopeUsersRead) {
+		return
+	}
+
+	documents, err := h.documentUC.ListDocuments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		documentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, DocumentsResponse{Documents: documents})
+}
+
+// GetDocumentDownload godoc
+// @Summary Get a download URL of an identity document
+// @Description Pre-sign a short-lived URL to the file of a document, for staff reviewing it; every URL issued is recorded as document.downloaded in the audit log
+// @Tags documents
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param documentId path string true "Document UUID" example("4d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a")
+// @Success 200 {object} domain.DocumentDownload "Pre-signed download URL"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:documents permission required"
+// @Failure 404 {object} ErrorResponse "Document not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/{id}/documents/{documentId}/download [get]
+func (h *DocumentHandler) GetDocumentDownload(c *gin.Context) {
+	download, err := h.documentUC.PresignDownload(c.Request.Context(), currentActorID(c), c.Param("id"), c.Param("documentId"))
+	if err != nil {
+		documentError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, download)
+}
+
+// authorizeDocuments lets users reach their own documents with tokens of userScope, and staff
+// holding users:documents, see CheckPermission, the documents of any user with admin tokens
+func authorizeDocuments(c *gin.Context, userScope string) bool {
+	scope := domain.ScopeAdmin
+	switch {
+	case currentUserID(c) != "" && currentUserID(c) == c.Param("id"):
+		scope = userScope
+	case !hasPermission(c, domain.PermissionUsersDocuments):
+		c.JSON(http.StatusForbidden, ErrorResponse{Code: errcode.PermissionDenied, Error: "insufficient permissions"})
+		return false
+	}
+	RequireScope(scope)(c)
+	return !c.IsAborted()
+}
+
+func documentError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type EmailDomainHandler struct {
+	emailDomainsUC ports.EmailDomainUseCase
+}
+
+// AddEmailDomainRequest represents the request body for adding a domain to an email domain list
+type AddEmailDomainRequest struct {
+	List   string `json:"list" binding:"required,oneof=allow deny" example:"allow"`
+	Domain string `json:"domain" binding:"required" example:"example.com"`
+}
+
+func NewEmailDomainHandler(emailDomainsUC ports.EmailDomainUseCase) *EmailDomainHandler {
+	return &EmailDomainHandler{
+		emailDomainsUC: emailDomainsUC,
+	}
+}
+
+// ListEmailDomains godoc
+// @Summary List the email domain rules
+// @Description List the allowed and denied email domains of the registrations: the ones of EMAIL_DOMAIN_ALLOWLIST and EMAIL_DOMAIN_DENYLIST,
+// @Description flagged configured, followed by the ones added to the tenant. A domain covers its subdomains; with allowed domains, only their emails
+// @Description register, and denied domains never do
+// @Tags security
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.EmailDomainRule "Email domain rules"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Router /admin/email-domains [get]
+func (h *EmailDomainHandler) ListEmailDomains(c *gin.Context) {
+	rules, err := h.emailDomainsUC.ListRules(c.Request.Context())
+	if err != nil {
+		emailDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// AddEmailDomain godoc
+// @Summary Add an email domain rule
+// @Description Add a domain to the allow or deny list of the tenant, applied to the next registrations of every instance
+// @Description Internationalized domains are stored in their ASCII form. Every change is recorded as email_domain.added in the audit log
+// @Tags security
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AddEmailDomainRequest true "List and domain"
+// @Success 201 {object} domain.EmailDomainRule "Domain added"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid list or domain"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Failure 409 {object} ErrorResponse "Domain already in the list"
+// @Router /admin/email-domains [post]
+func (h *EmailDomainHandler) AddEmailDomain(c *gin.Context) {
+	var req AddEmailDomainRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	rule, err := h.emailDomainsUC.AddRule(c.Request.Context(), currentActorID(c), req.List, req.Domain)
+	if err != nil {
+		emailDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// RemoveEmailDomain godoc
+// @Summary Remove an email domain rule
+// @Description Remove a domain added to a list of the tenant; configured domains can only be removed from the configuration
+// @Description Every change is recorded as email_domain.removed in the audit log
+// @Tags security
+// @Security BearerAuth
+// @Param list path string true "List" Enums(allow, deny)
+// @Param domain path string true "Domain" example("example.com")
+// @Success 204 "Domain removed"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid list or domain"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Failure 404 {object} ErrorResponse "Domain not added to the list"
+// @Router /admin/email-domains/{list}/{domain} [delete]
+func (h *EmailDomainHandler) RemoveEmailDomain(c *gin.Context) {
+	if err := h.emailDomainsUC.RemoveRule(c.Request.Context(), currentActorID(c), c.Param("list"), c.Param("domain")); err != nil {
+		emailDomainError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func emailDomainError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "already in the list"):
+		c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
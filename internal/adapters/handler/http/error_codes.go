@@ -0,0 +1,211 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/core/usecase"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/go-playground/validator/v10"
+)
+
+// errorCodes are the codes of the errors clients can tell apart, matched with errors.Is
+var errorCodes = []struct {
+	err  error
+	code errcode.Code
+}{
+	{domain.ErrInvalidTenant, errcode.TenantInvalid},
+	{domain.ErrMissingTenant, errcode.TenantRequired},
+	{usecase.ErrInvalidCredentials, errcode.InvalidCredentials},
+	{usecase.ErrIncorrectPassword, errcode.InvalidCredentials},
+	{usecase.ErrPasswordResetRequired, errcode.PasswordResetRequired},
+	{usecase.ErrAccountDeactivated, errcode.AccountDeactivated},
+	{usecase.ErrExternalTokenNoEmail, errcode.ExternalTokenNoEmail},
+	{usecase.ErrExternalIdentityConflict, errcode.IdentityConflict},
+	{ports.ErrIdentityProviderUnavailable, errcode.IdentityProviderDown},
+	{domain.ErrInvalidLoginReport, errcode.LoginReportInvalid},
+	{usecase.ErrUserNotFound, errcode.UserNotFound},
+	{domain.ErrInvalidEmail, errcode.UserEmailInvalid},
+	{domain.ErrEmailTaken, errcode.UserEmailTaken},
+	{domain.ErrInvalidUsername, errcode.UsernameInvalid},
+	{domain.ErrReservedUsername, errcode.UsernameInvalid},
+	{domain.ErrUsernameTaken, errcode.UsernameTaken},
+	{domain.ErrPasswordTooWeak, errcode.PasswordTooWeak},
+	{domain.ErrPasswordBreached, errcode.PasswordBreached},
+	{domain.ErrInvalidSearch, errcode.UserSearchInvalid},
+	{domain.ErrInvalidMetadataKey, errcode.UserMetadataInvalid},
+	{domain.ErrMetadataKeyNotAllowed, errcode.UserMetadataInvalid},
+	{domain.ErrMetadataValueTooLong, errcode.UserMetadataInvalid},
+	{domain.ErrTooManyMetadataKeys, errcode.UserMetadataInvalid},
+	{domain.ErrCustomFieldRequired, errcode.UserCustomFieldInvalid},
+	{domain.ErrUnknownCustomField, errcode.UserCustomFieldInvalid},
+	{domain.ErrCustomFieldTooLong, errcode.UserCustomFieldInvalid},
+	{domain.ErrCustomFieldFormat, errcode.UserCustomFieldInvalid},
+	{domain.ErrCustomFieldOption, errcode.UserCustomFieldInvalid},
+	{domain.ErrInvalidTag, errcode.UserTagInvalid},
+	{domain.ErrMergeSameUser, errcode.UserMergeSameUser},
+	{usecase.ErrUserVersionNotFound, errcode.UserVersionNotFound},
+	{usecase.ErrInvalidUserVersion, errcode.UserVersionInvalid},
+	{domain.ErrInvalidBirthdate, errcode.BirthdateInvalid},
+	{domain.ErrBirthdateRequired, errcode.BirthdateInvalid},
+	{domain.ErrUnderMinimumAge, errcode.UserUnderMinimumAge},
+	{domain.ErrAddressNotFound, errcode.AddressNotFound},
+	{domain.ErrTooManyAddresses, errcode.AddressLimitReached},
+	{domain.ErrUnknownAddress, errcode.AddressNotLocated},
+	{domain.ErrInvalidExportFormat, errcode.ExportFormatInvalid},
+	{domain.ErrBatchSelectorMissing, errcode.UserBatchInvalid},
+	{domain.ErrBatchUpdateEmpty, errcode.UserBatchInvalid},
+	{domain.ErrBatchTagConflict, errcode.UserBatchInvalid},
+	{domain.ErrUpsertConflict, errcode.UserUpsertConflict},
+	{domain.ErrEmailDomainNotAllowed, errcode.EmailDomainNotAllowed},
+	{usecase.ErrOrganizationNotFound, errcode.OrganizationNotFound},
+	{usecase.ErrSlugTaken, errcode.OrganizationSlugTaken},
+	{usecase.ErrNotMember, errcode.OrganizationNotMember},
+	{usecase.ErrOrgPermissionDenied, errcode.OrganizationPermissionDenied},
+	{usecase.ErrLastOwner, errcode.OrganizationLastOwner},
+	{usecase.ErrMembershipNotFound, errcode.MembershipNotFound},
+	{usecase.ErrRelationshipNotFound, errcode.RelationshipNotFound},
+	{domain.ErrFollowSelf, errcode.RelationshipSelf},
+	{usecase.ErrFollowBlocked, errcode.RelationshipUserBlocked},
+	{usecase.ErrBlockedByUser, errcode.RelationshipBlockedByUser},
+	{usecase.ErrBlockNotFound, errcode.BlockNotFound},
+	{domain.ErrBlockSelf, errcode.BlockSelf},
+	{usecase.ErrDocumentNotFound, errcode.DocumentNotFound},
+	{domain.ErrInvalidDocumentType, errcode.DocumentTypeInvalid},
+	{domain.ErrInvalidDocumentFile, errcode.DocumentFileInvalid},
+	{domain.ErrInvalidDocumentExpiry, errcode.DocumentExpiryInvalid},
+	{domain.ErrInvalidVerificationDocuments, errcode.VerificationDocumentsInvalid},
+	{domain.ErrInvalidVerificationDecision, errcode.VerificationDecisionInvalid},
+	{domain.ErrInvalidVerificationReason, errcode.VerificationReasonInvalid},
+	{domain.ErrVerificationConflict, errcode.VerificationConflict},
+	{usecase.ErrVerificationSelfReview, errcode.VerificationSelfReview},
+	{domain.ErrInvalidPhone, errcode.PhoneInvalid},
+	{domain.ErrPhoneMissing, errcode.PhoneMissing},
+	{domain.ErrPhoneAlreadyVerified, errcode.PhoneAlreadyVerified},
+	{domain.ErrInvalidPhoneCode, errcode.PhoneCodeInvalid},
+	{domain.ErrPhoneCodeExpired, errcode.PhoneCodeExpired},
+	{domain.ErrPhoneCodeAttempts, errcode.PhoneCodeAttempts},
+	{domain.ErrPhoneCodeTooFrequent, errcode.PhoneCodeTooFrequent},
+	{domain.ErrSMSRateLimited, errcode.SMSRateLimited},
+	{domain.ErrInvalidMFAFactorType, errcode.MFAFactorTypeInvalid},
+	{domain.ErrInvalidMFAFactorLabel, errcode.MFAFactorLabelInvalid},
+	{domain.ErrTooManyMFAFactors, errcode.MFAFactorLimit},
+	{domain.ErrMFAPhoneUnverified, errcode.MFAPhoneUnverified},
+	{domain.ErrMFAFactorExists, errcode.MFAFactorExists},
+	{domain.ErrMFAFactorConfirmed, errcode.MFAFactorConfirmed},
+	{domain.ErrMFAFactorNotFound, errcode.MFAFactorNotFound},
+	{domain.ErrMFAFactorNotSMS, errcode.MFAFactorNotSMS},
+	{domain.ErrTrustedDeviceNotFound, errcode.TrustedDeviceNotFound},
+	{domain.ErrInvalidMFACode, errcode.MFACodeInvalid},
+	{usecase.ErrIdentityCheckNotFound, errcode.IdentityCheckNotFound},
+	{usecase.ErrIdentityVerifierDisabled, errcode.IdentityVerifierDisabled},
+	{ports.ErrInvalidWebhookSignature, errcode.WebhookSignatureInvalid},
+	{usecase.ErrRoleNotFound, errcode.RoleNotFound},
+	{usecase.ErrRoleExists, errcode.RoleNameTaken},
+	{usecase.ErrSystemRole, errcode.RoleSystemImmutable},
+	{usecase.ErrRoleNotHeld, errcode.RoleNotHeld},
+	{usecase.ErrLastRoleOfUser, errcode.RoleLastOfUser},
+	{usecase.ErrDirectorySyncDisabled, errcode.DirectorySyncDisabled},
+	{usecase.ErrDirectorySyncRunning, errcode.DirectorySyncRunning},
+	{usecase.ErrSigningKeysDisabled, errcode.SigningKeysDisabled},
+	{usecase.ErrClientNotFound, errcode.ClientNotFound},
+	{usecase.ErrInvalidClientCredentials, errcode.ClientInvalid},
+	{domain.ErrEmailDomainRuleExists, errcode.EmailDomainExists},
+	{usecase.ErrEmailDomainRuleNotFound, errcode.EmailDomainNotFound},
+	{ErrUnsupportedGrantType, errcode.GrantTypeUnsupported},
+	{domain.ErrInvalidScope, errcode.ScopeInvalid},
+	{domain.ErrScopeNotGranted, errcode.ScopeInvalid},
+	{domain.ErrInvalidTokenScope, errcode.ScopeInvalid},
+	{ErrUserTokenRequired, errcode.UserTokenRequired},
+	{ErrTokenRevoked, errcode.TokenRevoked},
+	{domain.ErrSessionNotFound, errcode.SessionExpired},
+	{ErrCSRFTokenInvalid, errcode.CSRFTokenInvalid},
+	{ErrCrossSiteRequest, errcode.CSRFTokenInvalid},
+	{ErrTermsAcceptanceRequired, errcode.TermsAcceptanceRequired},
+	{domain.ErrTermsVersionNotCurrent, errcode.TermsVersionNotCurrent},
+	{domain.ErrTermsVersionExists, errcode.TermsVersionExists},
+	{usecase.ErrJobNotFound, errcode.JobNotFound},
+	{usecase.ErrJobNotFailed, errcode.JobNotFailed},
+	{ports.ErrDatabaseUnavailable, errcode.DatabaseUnavailable},
+	{ErrRequestTimeout, errcode.RequestTimeout},
+	{domain.ErrInvalidAddressType, errcode.ValidationFailed},
+	{domain.ErrEmptyAddress, errcode.ValidationFailed},
+	{domain.ErrInvalidCountry, errcode.ValidationFailed},
+	{domain.ErrInvalidState, errcode.ValidationFailed},
+	{domain.ErrInvalidNear, errcode.ValidationFailed},
+	{domain.ErrInvalidRadius, errcode.ValidationFailed},
+	{domain.ErrInvalidOrganizationName, errcode.ValidationFailed},
+	{domain.ErrInvalidOrganizationSlug, errcode.ValidationFailed},
+	{domain.ErrInvalidMembershipRole, errcode.ValidationFailed},
+	{domain.ErrInvalidMergePolicy, errcode.ValidationFailed},
+	{domain.ErrInvalidUpsertPolicy, errcode.ValidationFailed},
+	{domain.ErrInvalidTheme, errcode.ValidationFailed},
+	{domain.ErrInvalidLanguage, errcode.ValidationFailed},
+	{domain.ErrInvalidTimezone, errcode.ValidationFailed},
+	{domain.ErrInvalidLocale, errcode.ValidationFailed},
+	{domain.ErrInvalidRoleName, errcode.ValidationFailed},
+	{domain.ErrInvalidPermission, errcode.ValidationFailed},
+	{domain.ErrInvalidClientName, errcode.ValidationFailed},
+	{domain.ErrInvalidEmailDomain, errcode.ValidationFailed},
+	{domain.ErrInvalidEmailDomainList, errcode.ValidationFailed},
+	{domain.ErrInvalidTermsDocument, errcode.ValidationFailed},
+	{domain.ErrInvalidTermsVersion, errcode.ValidationFailed},
+	{domain.ErrInvalidTermsURL, errcode.ValidationFailed},
+	{domain.ErrInvalidJobStatus, errcode.ValidationFailed},
+	{domain.ErrInvalidStatsDays, errcode.ValidationFailed},
+	{domain.ErrInvalidAnalyticsInterval, errcode.ValidationFailed},
+	{domain.ErrInvalidAnalyticsTime, errcode.ValidationFailed},
+	{domain.ErrInvalidAnalyticsRange, errcode.ValidationFailed},
+	{domain.ErrInvalidSecurityEventsBefore, errcode.ValidationFailed},
+	{domain.ErrInvalidFacet, errcode.ValidationFailed},
+	{domain.ErrPasswordTooShort, errcode.ValidationFailed},
+	{domain.ErrPasswordTooLong, errcode.ValidationFailed},
+}
+
+// statusCodes are the codes of the errors without a specific code
+var statusCodes = map[int]errcode.Code{
+	http.StatusBadRequest:            errcode.InvalidRequest,
+	http.StatusUnauthorized:          errcode.Unauthorized,
+	http.StatusForbidden:             errcode.PermissionDenied,
+	http.StatusNotFound:              errcode.NotFound,
+	http.StatusConflict:              errcode.Conflict,
+	http.StatusRequestEntityTooLarge: errcode.PayloadTooLarge,
+	http.StatusTooManyRequests:       errcode.RateLimited,
+	http.StatusServiceUnavailable:    errcode.DatabaseUnavailable,
+}
+
+// errorCode returns the code of err answered with status: its own code, VALIDATION_FAILED for
+// invalid input and otherwise the code of the status
+func errorCode(status int, err error) errcode.Code {
+	for _, known := range errorCodes {
+		if errors.Is(err, known.err) {
+			return known.code
+		}
+	}
+
+	var (
+		fieldErr      *domain.FieldError
+		validationErr validator.ValidationErrors
+		syntaxErr     *json.SyntaxError
+		typeErr       *json.UnmarshalTypeError
+	)
+	if status == http.StatusBadRequest &&
+		(errors.As(err, &fieldErr) || errors.As(err, &validationErr) || errors.As(err, &syntaxErr) || errors.As(err, &typeErr)) {
+		return errcode.ValidationFailed
+	}
+	return statusCode(status)
+}
+
+// statusCode returns the code of the errors of a status without a specific code
+func statusCode(status int) errcode.Code {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= http.StatusInternalServerError {
+		return errcode.Internal
+	}
+	return errcode.InvalidRequest
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/pkg/i18n"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// UseJSONFieldNames makes binding validation errors name fields by their JSON key
+// ("email") instead of the Go struct field ("Email"), as clients know them
+func UseJSONFieldNames() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+}
+
+// LocalizeErrors translates the message of error responses (status >= 400) into the language
+// negotiated from the Accept-Language header, falling back to English
+func LocalizeErrors(catalog *i18n.Catalog) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if catalog == nil {
+			c.Next()
+			return
+		}
+
+		w := &errorBufferWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if !w.buffered {
+			return
+		}
+		body := w.body.Bytes()
+		var resp ErrorResponse
+		if err := json.Unmarshal(body, &resp); err == nil && resp.Error != "" {
+			tag := catalog.Match(c.GetHeader("Accept-Language"))
+			resp.Error = catalog.Translate(tag, resp.Error)
+			for field, message := range resp.Fields {
+				resp.Fields[field] = catalog.Translate(tag, message)
+			}
+			for i, violation := range resp.Details {
+				if template, ok := catalog.Validation(tag, violation.Rule); ok {
+					resp.Details[i].Message = formatViolation(template, violation.Field, violation.Param)
+				}
+			}
+			if translated, err := json.Marshal(resp); err == nil {
+				body = translated
+				c.Header("Content-Language", tag.String())
+			}
+		}
+		w.ResponseWriter.Write(body)
+	}
+}
+
+// errorBufferWriter holds back the body of error responses so it can be translated
+type errorBufferWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	buffered bool
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to change its deadlines
+func (w *errorBufferWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *errorBufferWriter) Write(data []byte) (int, error) {
+	if w.Status() < 400 {
+		return w.ResponseWriter.Write(data)
+	}
+	w.buffered = true
+	return w.body.Write(data)
+}
+
+func (w *errorBufferWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
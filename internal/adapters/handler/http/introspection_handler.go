@@ -0,0 +1,133 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+	"github.com/gin-gonic/gin"
+)
+
+type IntrospectionHandler struct {
+	tokens    *security.TokenManager
+	userUC    ports.UserUseCase
+	clientsUC ports.OAuthClientUseCase
+}
+
+// IntrospectionRequest is the token to introspect, sent as a form as RFC 7662 requires
+type IntrospectionRequest struct {
+	Token string `form:"token" json:"token" binding:"required"`
+	// TokenTypeHint is accepted for compatibility, every token of the API is an access token
+	TokenTypeHint string `form:"token_type_hint" json:"token_type_hint"`
+}
+
+// IntrospectionResponse is the state of a token, see RFC 7662; inactive tokens only carry active
+type IntrospectionResponse struct {
+	Active    bool     `json:"active" example:"true"`
+	TokenType string   `json:"token_type,omitempty" example:"Bearer"`
+	Subject   string   `json:"sub,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Username  string   `json:"username,omitempty" example:"johndoe"`
+	Tenant    string   `json:"tenant,omitempty" example:"default"`
+	Roles     []string `json:"roles,omitempty" example:"admin"`
+	// ClientID is set for the tokens of OAuth2 clients, which have no user
+	ClientID string `json:"client_id,omitempty" example:"3f2b8c1a-5d6e-4f70-8a9b-1c2d3e4f5a6b"`
+	// Scope lists the scopes of restricted tokens, space-delimited
+	Scope     string `json:"scope,omitempty" example:"admin system:read"`
+	Issuer    string `json:"iss,omitempty" example:"user-management-api"`
+	IssuedAt  int64  `json:"iat,omitempty" example:"1704067200"`
+	ExpiresAt int64  `json:"exp,omitempty" example:"1704070800"`
+	TokenID   string `json:"jti,omitempty" example:"1b4e28ba-2fa1-11d2-883f-0016d3cca427"`
+	// Actor is the admin impersonating the subject, for impersonation tokens
+	Actor *security.ActorClaims `json:"act,omitempty"`
+}
+
+func NewIntrospectionHandler(tokens *security.TokenManager, userUC ports.UserUseCase, clientsUC ports.OAuthClientUseCase) *IntrospectionHandler {
+	return &IntrospectionHandler{
+		tokens:    tokens,
+		userUC:    userUC,
+		clientsUC: clientsUC,
+	}
+}
+
+// Introspect godoc
+// @Summary Introspect a token
+// @Description Report whether an access token of the API is active and its claims, for resource servers validating tokens with the API instead of locally (RFC 7662)
+// @Description Tokens are active until they expire, unless their user or OAuth2 client was deleted, or their user deactivated, since they were issued
+// @Description Clients authenticate with HTTP basic credentials configured in INTROSPECTION_CLIENTS
+// @Tags auth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security ClientBasicAuth
+// @Param token formData string true "Token to introspect"
+// @Param token_type_hint formData string false "Type of the token, ignored" example(access_token)
+// @Success 200 {object} IntrospectionResponse "Token state, only active for inactive tokens"
+// @Failure 400 {object} ErrorResponse "Bad request - missing token"
+// @Failure 401 {object} ErrorResponse "Invalid client credentials"
+// @Router /auth/introspect [post]
+func (h *IntrospectionHandler) Introspect(c *gin.Context) {
+	var req IntrospectionRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+	// Responses must not be reused once the token is revoked or expires
+	c.Header("Cache-Control", "no-store")
+
+	claims, err := h.tokens.Parse(req.Token)
+	if err != nil {
+		c.JSON(http.StatusOK, IntrospectionResponse{Active: false})
+		return
+	}
+	if claims.IsClient() {
+		h.introspectClient(c, claims)
+		return
+	}
+	user, err := h.userUC.GetUserByID(domain.WithTenant(c.Request.Context(), claims.Tenant), claims.Subject)
+	if err != nil || user == nil || user.DeactivatedAt != nil || claims.Generation < user.TokenGeneration {
+		c.JSON(http.StatusOK, IntrospectionResponse{Active: false})
+		return
+	}
+
+	resp := IntrospectionResponse{
+		Active:    true,
+		TokenType: "Bearer",
+		Subject:   claims.Subject,
+		Username:  user.Username,
+		Tenant:    claims.Tenant,
+		Roles:     claims.Roles,
+		Scope:     claims.Scope,
+		Issuer:    claims.Issuer,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		TokenID:   claims.ID,
+		Actor:     claims.Actor,
+	}
+	if claims.IssuedAt != nil {
+		resp.IssuedAt = claims.IssuedAt.Unix()
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *IntrospectionHandler) introspectClient(c *gin.Context, claims *security.Claims) {
+	client, err := h.clientsUC.GetClient(domain.WithTenant(c.Request.Context(), claims.Tenant), claims.ClientID)
+	if err != nil || client == nil {
+		c.JSON(http.StatusOK, IntrospectionResponse{Active: false})
+		return
+	}
+
+	resp := IntrospectionResponse{
+		Active:    true,
+		TokenType: "Bearer",
+		Subject:   claims.Subject,
+		Tenant:    claims.Tenant,
+		ClientID:  claims.ClientID,
+		Scope:     claims.Scope,
+		Issuer:    claims.Issuer,
+		ExpiresAt: claims.ExpiresAt.Unix(),
+		TokenID:   claims.ID,
+	}
+	if claims.IssuedAt != nil {
+		resp.IssuedAt = claims.IssuedAt.Unix()
+	}
+	c.JSON(http.StatusOK, resp)
+}
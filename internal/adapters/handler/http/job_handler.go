@@ -0,0 +1,113 @@
+package http
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	jobsUC ports.JobUseCase
+}
+
+func NewJobHandler(jobsUC ports.JobUseCase) *JobHandler {
+	return &JobHandler{
+		jobsUC: jobsUC,
+	}
+}
+
+// ListJobs godoc
+// @Summary List background jobs
+// @Description Retrieve a paginated list of the background jobs of the tenant, newest first, optionally filtered by status or type; failed jobs used all their attempts and are kept until retried
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Job status" Enums(queued, running, succeeded, failed)
+// @Param type query string false "Job type" example("email.send")
+// @Param page query int false "Page number (1-based)" default(1) minimum(1)
+// @Param page_size query int false "Number of jobs per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} ports.JobQueryResult "Jobs with pagination info"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid status"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "jobs:manage permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/jobs [get]
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	status := domain.JobStatus(c.Query("status"))
+	if status != "" && !slices.Contains(domain.JobStatuses, status) {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, domain.ErrInvalidJobStatus))
+		return
+	}
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+
+	result, err := h.jobsUC.ListJobs(c.Request.Context(), &ports.JobQuery{
+		Status:   status,
+		Type:     c.Query("type"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// GetJob godoc
+// @Summary Get a background job
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID" example("0c4a7b52-1f3e-4d6a-9b8c-2e5f7a9d1c3b")
+// @Success 200 {object} domain.Job "Job"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "jobs:manage permission required"
+// @Failure 404 {object} ErrorResponse "Job not found"
+// @Router /admin/jobs/{id} [get]
+func (h *JobHandler) GetJob(c *gin.Context) {
+	job, err := h.jobsUC.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// RetryJob godoc
+// @Summary Retry a failed background job
+// @Description Queue a failed job again to run now, with all its attempts
+// @Tags jobs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Job ID" example("0c4a7b52-1f3e-4d6a-9b8c-2e5f7a9d1c3b")
+// @Success 200 {object} domain.Job "Job queued again"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "jobs:manage permission required"
+// @Failure 404 {object} ErrorResponse "Job not found"
+// @Failure 409 {object} ErrorResponse "Job is not failed"
+// @Router /admin/jobs/{id}/retry [post]
+func (h *JobHandler) RetryJob(c *gin.Context) {
+	job, err := h.jobsUC.RetryJob(c.Request.Context(), currentActorID(c), c.Param("id"))
+	if err != nil {
+		jobError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+func jobError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "not failed"):
+		c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
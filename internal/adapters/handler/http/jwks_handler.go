@@ -0,0 +1,28 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/pkg/security"
+	"github.com/gin-gonic/gin"
+)
+
+type JWKSHandler struct {
+	tokens *security.TokenManager
+}
+
+func NewJWKSHandler(tokens *security.TokenManager) *JWKSHandler {
+	return &JWKSHandler{
+		tokens: tokens,
+	}
+}
+
+// GetJWKS serves the public keys verifying the access tokens of the API as a JSON Web Key Set, for
+// services and gateways validating them without a shared secret. It is served at the root of the
+// server, outside of the API base path, where OpenID Connect clients look for it.
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	// Clients refetch the set when a token names an unknown key; rotated keys are published
+	// longer than this before they sign
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.tokens.JWKS())
+}
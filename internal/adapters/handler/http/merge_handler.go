@@ -0,0 +1,97 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+type MergeHandler struct {
+	mergeUC ports.AccountMergeUseCase
+}
+
+// MergeUsersRequest represents the request body for merging a duplicate account into a user
+type MergeUsersRequest struct {
+	DuplicateID string `json:"duplicate_id" binding:"required" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Policy      string `json:"policy" binding:"omitempty,oneof=keep_primary prefer_duplicate" example:"keep_primary"`
+}
+
+func NewMergeHandler(mergeUC ports.AccountMergeUseCase) *MergeHandler {
+	return &MergeHandler{
+		mergeUC: mergeUC,
+	}
+}
+
+// ListDuplicateUsers godoc
+// @Summary Report likely duplicate accounts
+// @Description Retrieve paginated groups of users that likely belong to the same person, for review before merging
+// @Description Users match on the same birthdate and name (ignoring case, accents and punctuation) or on similar emails
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (1-based)" default(1) minimum(1)
+// @Param page_size query int false "Number of groups per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} ports.DuplicateReport "Duplicate candidates with pagination info"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:merge permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/users/duplicates [get]
+func (h *MergeHandler) ListDuplicateUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+
+	report, err := h.mergeUC.FindDuplicates(c.Request.Context(), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// MergeUsers godoc
+// @Summary Merge a duplicate account into a user
+// @Description Combine the profile of a duplicate account into the user, move its organization memberships and soft-delete it
+// @Description keep_primary (default) only fills blank fields of the user, prefer_duplicate overwrites them; roles, tags and addresses are unioned
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Primary user UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param request body MergeUsersRequest true "Duplicate account and merge policy"
+// @Success 200 {object} domain.User "Merged user"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid policy or self-merge"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:merge permission required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /admin/users/{id}/merge [post]
+func (h *MergeHandler) MergeUsers(c *gin.Context) {
+	var req MergeUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	user, err := h.mergeUC.Merge(c.Request.Context(), currentActorID(c), c.Param("id"), req.DuplicateID, req.Policy)
+	if err != nil {
+		mergeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func mergeError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+	case strings.Contains(err.Error(), "already in use"):
+		c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/frtasoniero/user-management-api/pkg/iso3166"
+	"github.com/gin-gonic/gin"
+)
+
+// CountriesResponse lists reference countries
+type CountriesResponse struct {
+	Countries []iso3166.Country `json:"countries"`
+}
+
+// ListCountries godoc
+// @Summary List countries
+// @Description List the ISO 3166-1 countries accepted in addresses, with the ISO 3166-2 subdivisions
+// @Description validated as address states for the countries that have them
+// @Tags meta
+// @Produce json
+// @Param country query string false "Only this country (code or name)" example("US")
+// @Success 200 {object} CountriesResponse "Countries"
+// @Failure 404 {object} ErrorResponse "Unknown country"
+// @Router /meta/countries [get]
+func ListCountries(c *gin.Context) {
+	// Reference data only changes with new releases
+	c.Header("Cache-Control", "public, max-age=86400")
+
+	if query := strings.TrimSpace(c.Query("country")); query != "" {
+		country, ok := iso3166.LookupCountry(query)
+		if !ok {
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.CountryNotFound, Error: "country not found"})
+			return
+		}
+		c.JSON(http.StatusOK, CountriesResponse{Countries: []iso3166.Country{country}})
+		return
+	}
+	c.JSON(http.StatusOK, CountriesResponse{Countries: iso3166.Countries()})
+}
@@ -0,0 +1,47 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateUserMetadata godoc
+// @Summary Update user metadata
+// @Description Set or remove custom key/value metadata on a user (admin only)
+// @Description Keys with a null value are removed; keys not present in the body are left unchanged
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param request body map[string]string true "Metadata changes (null removes a key)"
+// @Success 200 {object} map[string]string "Resulting user metadata"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid key or value"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/{id}/metadata [patch]
+func (h *UserHandler) UpdateUserMetadata(c *gin.Context) {
+	var changes map[string]*string
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	metadata, err := h.userUC.UpdateMetadata(c.Request.Context(), c.Param("id"), changes)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+		case strings.Contains(err.Error(), "metadata"):
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, metadata)
+}
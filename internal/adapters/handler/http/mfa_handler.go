@@ -0,0 +1,417 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// mfaTokenTTL is the time a user has to enter the code of its second factor after its password
+const mfaTokenTTL = 5 * time.Minute
+
+// Trusted devices send their device token at login in the header, or in the cookie set by
+// /auth/mfa/verify for browsers
+const (
+	trustedDeviceHeader = "X-Device-Token"
+	trustedDeviceCookie = "device_token"
+	trustedDevicePath   = "/api/v1/auth"
+)
+
+type MFAHandler struct {
+	mfaUC ports.MFAUseCase
+}
+
+func NewMFAHandler(mfaUC ports.MFAUseCase) *MFAHandler {
+	return &MFAHandler{
+		mfaUC: mfaUC,
+	}
+}
+
+type AddMFAFactorRequest struct {
+	Type  string `json:"type" binding:"required,oneof=totp sms" example:"totp" enums:"totp,sms"`
+	Label string `json:"label" example:"Work phone"`
+}
+
+type ConfirmMFAFactorRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" example:"123456"`
+}
+
+// MFARequiredResponse is returned by login instead of an access token when the user has a second
+// factor; the login finishes at /auth/mfa/verify with the code of one of the factors
+type MFARequiredResponse struct {
+	MFARequired bool      `json:"mfa_required" example:"true"`
+	MFAToken    string    `json:"mfa_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ExpiresAt   time.Time `json:"expires_at" example:"2024-01-01T00:05:00Z"`
+	// Factors are the confirmed factors of the user, with the phone of SMS factors masked
+	Factors []domain.MFAFactor `json:"factors"`
+}
+
+type MFAChallengeRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	FactorID string `json:"factor_id" binding:"required" example:"3f2b8c1e-6d4a-4e9b-9c7f-1a2b3c4d5e6f"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	FactorID string `json:"factor_id" binding:"required" example:"3f2b8c1e-6d4a-4e9b-9c7f-1a2b3c4d5e6f"`
+	Code     string `json:"code" binding:"required,len=6,numeric" example:"123456"`
+	// RememberDevice trusts the device, which skips the second factor until the device token expires
+	RememberDevice bool `json:"remember_device,omitempty" example:"true"`
+}
+
+// TrustedDeviceToken is the token a trusted device sends at login to skip the second factor, also
+// set as the device_token cookie
+type TrustedDeviceToken struct {
+	DeviceID  string    `json:"device_id" example:"9b2d6e1f-3c4a-4f5b-8e7d-6c5b4a392817"`
+	Token     string    `json:"token" example:"5f0c9a3e8b7d6c5a4f3e2d1c0b9a8f7e6d5c4b3a2f1e0d9c8b7a6f5e4d3c2b1a"`
+	ExpiresAt time.Time `json:"expires_at" example:"2024-01-31T00:00:00Z"`
+}
+
+type RevokeTrustedDevicesResponse struct {
+	Revoked int `json:"revoked" example:"2"`
+}
+
+// ListMyMFAFactors godoc
+// @Summary List current user second factors
+// @Description List the TOTP and SMS second factors of the authenticated user, asked for at login once confirmed
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.MFAFactor "Second factors"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/mfa/factors [get]
+func (h *MFAHandler) ListMyMFAFactors(c *gin.Context) {
+	factors, err := h.mfaUC.ListFactors(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, factors)
+}
+
+// AddMyMFAFactor godoc
+// @Summary Add a second factor to the current user
+// @Description Add a TOTP factor, returned once with its secret and otpauth:// URI and used at login once confirmed with a code of the app,
+// @Description or an SMS factor texting codes to the verified phone of the profile, used at login right away
+// @Description A user has at most 5 factors
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AddMFAFactorRequest true "Factor type and label"
+// @Success 201 {object} domain.MFAEnrollment "Factor added"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid type or label, too many factors, or unverified phone"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "The phone is already a second factor"
+// @Router /users/me/mfa/factors [post]
+func (h *MFAHandler) AddMyMFAFactor(c *gin.Context) {
+	var req AddMFAFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	enrollment, err := h.mfaUC.AddFactor(c.Request.Context(), currentUserID(c), req.Type, req.Label)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, enrollment)
+}
+
+// ConfirmMyMFAFactor godoc
+// @Summary Confirm a TOTP factor of the current user
+// @Description Activate a TOTP factor with a code of the authenticator app it was enrolled in
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param factorId path string true "Factor ID"
+// @Param request body ConfirmMFAFactorRequest true "Code of the authenticator app"
+// @Success 200 {object} domain.MFAFactor "Confirmed factor"
+// @Failure 400 {object} ErrorResponse "Wrong code, or not a TOTP factor"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User or factor not found"
+// @Failure 409 {object} ErrorResponse "The factor is already confirmed"
+// @Router /users/me/mfa/factors/{factorId}/confirm [post]
+func (h *MFAHandler) ConfirmMyMFAFactor(c *gin.Context) {
+	var req ConfirmMFAFactorRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	factor, err := h.mfaUC.ConfirmFactor(c.Request.Context(), currentUserID(c), c.Param("factorId"), req.Code)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, factor)
+}
+
+// RemoveMyMFAFactor godoc
+// @Summary Remove a second factor of the current user
+// @Description Remove a factor; the login no longer asks for a second factor once the last confirmed one is removed
+// @Description The password or a second factor must have been proven within RECENT_AUTH_MAX_AGE, see /auth/reauthenticate
+// @Tags users
+// @Security BearerAuth
+// @Param factorId path string true "Factor ID"
+// @Success 204 "Factor removed"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token, or re-authentication required"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User or factor not found"
+// @Router /users/me/mfa/factors/{factorId} [delete]
+func (h *MFAHandler) RemoveMyMFAFactor(c *gin.Context) {
+	if err := h.mfaUC.RemoveFactor(c.Request.Context(), currentUserID(c), c.Param("factorId")); err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListMyTrustedDevices godoc
+// @Summary List current user trusted devices
+// @Description List the devices skipping the second factor of the authenticated user until they expire
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.TrustedDevice "Trusted devices"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/trusted-devices [get]
+func (h *MFAHandler) ListMyTrustedDevices(c *gin.Context) {
+	devices, err := h.mfaUC.ListTrustedDevices(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, devices)
+}
+
+// RevokeMyTrustedDevice godoc
+// @Summary Revoke a trusted device of the current user
+// @Description Stop trusting a device, which is asked for the second factor at its next login
+// @Tags users
+// @Security BearerAuth
+// @Param deviceId path string true "Device ID"
+// @Success 204 "Device revoked"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User or device not found"
+// @Router /users/me/trusted-devices/{deviceId} [delete]
+func (h *MFAHandler) RevokeMyTrustedDevice(c *gin.Context) {
+	if err := h.mfaUC.RevokeTrustedDevice(c.Request.Context(), currentUserID(c), c.Param("deviceId")); err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeMyTrustedDevices godoc
+// @Summary Revoke every trusted device of the current user
+// @Description Stop trusting every device of the authenticated user, e.g. after losing one
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} RevokeTrustedDevicesResponse "Number of devices revoked"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/trusted-devices [delete]
+func (h *MFAHandler) RevokeMyTrustedDevices(c *gin.Context) {
+	revoked, err := h.mfaUC.RevokeTrustedDevices(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, RevokeTrustedDevicesResponse{Revoked: revoked})
+}
+
+// SendMFACode godoc
+// @Summary Send a login code to an SMS factor
+// @Description Text a 6-digit login code to an SMS factor of the user of an MFA token returned by login, valid for PHONE_CODE_TTL
+// @Description Another code can be requested after a minute; the text messages sent to each phone are limited by RATE_LIMIT_SMS
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MFAChallengeRequest true "MFA token and SMS factor"
+// @Success 202 {object} PhoneCodeResponse "Code sent"
+// @Failure 400 {object} ErrorResponse "Bad request - not an SMS factor"
+// @Failure 401 {object} ErrorResponse "Invalid or expired MFA token"
+// @Failure 404 {object} ErrorResponse "Factor not found"
+// @Failure 429 {object} ErrorResponse "A code was sent less than a minute ago, or too many text messages sent to the phone"
+// @Failure 500 {object} ErrorResponse "The SMS could not be sent"
+// @Router /auth/mfa/challenge [post]
+func (h *AuthHandler) SendMFACode(c *gin.Context) {
+	var req MFAChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+	claims, err := h.tokens.ParseMFA(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Code: errcode.MFATokenInvalid, Error: "invalid or expired mfa token"})
+		return
+	}
+
+	expiresAt, err := h.mfaUC.SendCode(c.Request.Context(), claims.Subject, req.FactorID)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, PhoneCodeResponse{ExpiresAt: expiresAt})
+}
+
+// VerifyMFA godoc
+// @Summary Finish a login with a second factor
+// @Description Exchange the MFA token returned by login and a code of a second factor for an access token, restricted to the scopes of the login
+// @Description TOTP codes are accepted once each; SMS codes expire after PHONE_CODE_TTL or 5 wrong codes
+// @Description remember_device trusts the device for TRUSTED_DEVICE_TTL: its device token, returned as trusted_device and set as the device_token cookie,
+// @Description skips the second factor when sent to /auth/login in the X-Device-Token header or the cookie
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body MFAVerifyRequest true "MFA token, factor and code"
+// @Success 200 {object} LoginResponse "Access token issued"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid input data"
+// @Failure 401 {object} ErrorResponse "Invalid or expired MFA token, or wrong or expired code"
+// @Failure 404 {object} ErrorResponse "Factor not found"
+// @Failure 429 {object} ErrorResponse "Too many failed logins from this IP"
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+	ip := c.ClientIP()
+	if h.blockedIP(c, ip) {
+		return
+	}
+	claims, err := h.tokens.ParseMFA(req.MFAToken)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Code: errcode.MFATokenInvalid, Error: "invalid or expired mfa token"})
+		return
+	}
+
+	if err := h.mfaUC.Verify(c.Request.Context(), claims.Subject, req.FactorID, req.Code); err != nil {
+		if strings.Contains(err.Error(), "invalid mfa code") || strings.Contains(err.Error(), "invalid phone code") {
+			// Wrong codes count as failed logins, so codes cannot be guessed from an IP
+			if err := h.backoff.RecordFailure(c.Request.Context(), ip); err != nil {
+				log.Printf("Error recording mfa failure for %s: %v", ip, err)
+			}
+			c.JSON(http.StatusUnauthorized, errorResponse(http.StatusUnauthorized, err))
+			return
+		}
+		mfaError(c, err)
+		return
+	}
+
+	user, err := h.userUC.GetUserByID(c.Request.Context(), claims.Subject)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	if user.PasswordResetRequired || user.DeactivatedAt != nil {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Code: errcode.MFATokenInvalid, Error: "invalid or expired mfa token"})
+		return
+	}
+
+	var trusted *TrustedDeviceToken
+	if req.RememberDevice {
+		device, token, err := h.mfaUC.TrustDevice(c.Request.Context(), user.ID, c.Request.UserAgent(), ip)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+			return
+		}
+		trusted = &TrustedDeviceToken{DeviceID: device.ID, Token: token, ExpiresAt: device.ExpiresAt}
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     trustedDeviceCookie,
+			Value:    token,
+			Path:     trustedDevicePath,
+			Expires:  device.ExpiresAt,
+			Secure:   true,
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+	}
+	h.issueLoginToken(c, user, strings.Fields(claims.Scope), trusted)
+}
+
+// requireMFA answers a login whose password is verified with an MFA token when the user has a
+// second factor and the device is not trusted, or untrusted is set, and reports whether it did
+func (h *AuthHandler) requireMFA(c *gin.Context, user *domain.User, scopes []string, untrusted bool) bool {
+	if !user.MFAEnabled() {
+		return false
+	}
+	if token := trustedDeviceToken(c); token != "" && !untrusted {
+		trusted, err := h.mfaUC.UseTrustedDevice(c.Request.Context(), user, token)
+		if err != nil {
+			log.Printf("Error checking the trusted devices of user %s: %v", user.ID, err)
+		}
+		if trusted {
+			return false
+		}
+	}
+	token, expiresAt, err := h.tokens.GenerateMFA(user.ID, user.TenantID, scopes, mfaTokenTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return true
+	}
+
+	factors := []domain.MFAFactor{}
+	for _, factor := range user.MFAFactors {
+		if factor.Confirmed() {
+			factor.Phone = maskPhone(factor.Phone)
+			factors = append(factors, factor)
+		}
+	}
+	c.JSON(http.StatusAccepted, MFARequiredResponse{MFARequired: true, MFAToken: token, ExpiresAt: expiresAt, Factors: factors})
+	return true
+}
+
+// trustedDeviceToken returns the device token sent with a login, from the header or else the cookie
+func trustedDeviceToken(c *gin.Context) string {
+	if token := c.GetHeader(trustedDeviceHeader); token != "" {
+		return token
+	}
+	token, _ := c.Cookie(trustedDeviceCookie)
+	return token
+}
+
+// maskPhone hides all but the last 4 digits of a phone, shown before the login is complete
+func maskPhone(phone string) string {
+	if len(phone) <= 4 {
+		return phone
+	}
+	return strings.Repeat("*", len(phone)-4) + phone[len(phone)-4:]
+}
+
+func mfaError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "mfa factor not found"), strings.Contains(err.Error(), "trusted device not found"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+	case strings.Contains(err.Error(), "too many requests"):
+		c.JSON(http.StatusTooManyRequests, errorResponse(http.StatusTooManyRequests, err))
+	case strings.Contains(err.Error(), "conflict"):
+		c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,220 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrUnsupportedGrantType rejects the token requests of other grants than client_credentials
+var ErrUnsupportedGrantType = errors.New("unsupported grant type: only client_credentials is supported")
+
+type OAuthClientHandler struct {
+	clientsUC ports.OAuthClientUseCase
+	tokens    *security.TokenManager
+}
+
+// TokenRequest is an OAuth2 token request, sent as a form as RFC 6749 requires. Clients
+// authenticate with HTTP basic credentials or with ClientID and ClientSecret.
+type TokenRequest struct {
+	GrantType    string `form:"grant_type" binding:"required"`
+	Scope        string `form:"scope"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// TokenResponse is the access token issued to a client, see RFC 6749
+type TokenResponse struct {
+	AccessToken string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	TokenType   string `json:"token_type" example:"Bearer"`
+	ExpiresIn   int64  `json:"expires_in" example:"3600"`
+	Scope       string `json:"scope" example:"admin system:read"`
+}
+
+// RegisterClientRequest represents the request body for registering an OAuth2 client
+type RegisterClientRequest struct {
+	Name   string   `json:"name" binding:"required" example:"billing-service"`
+	Scopes []string `json:"scopes" binding:"required,min=1" example:"admin,system:read"`
+}
+
+// RegisterClientResponse is the registered client and its secret, which is not shown again
+type RegisterClientResponse struct {
+	Client       *domain.OAuthClient `json:"client"`
+	ClientSecret string              `json:"client_secret" example:"Zm9vYmFyYmF6cXV4LXNlY3JldC1vZi0zMi1ieXRlcw"`
+}
+
+func NewOAuthClientHandler(clientsUC ports.OAuthClientUseCase, tokens *security.TokenManager) *OAuthClientHandler {
+	return &OAuthClientHandler{
+		clientsUC: clientsUC,
+		tokens:    tokens,
+	}
+}
+
+// IssueToken godoc
+// @Summary Issue a client access token
+// @Description Issue an access token to a registered OAuth2 client with the client_credentials grant (RFC 6749), for services calling the API without a user
+// @Description The token grants the requested scopes, space-delimited, or every scope of the client when none is requested; client tokens are accepted by the routes guarded by a permission they hold, within their token scopes
+// @Tags auth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security ClientBasicAuth
+// @Param grant_type formData string true "Grant type" Enums(client_credentials)
+// @Param scope formData string false "Requested scopes, space-delimited" example(admin system:read)
+// @Param client_id formData string false "Client ID, when not sent with basic auth"
+// @Param client_secret formData string false "Client secret, when not sent with basic auth"
+// @Success 200 {object} TokenResponse "Access token issued"
+// @Failure 400 {object} ErrorResponse "Bad request - unsupported grant type or scope not held by the client"
+// @Failure 401 {object} ErrorResponse "Invalid client credentials"
+// @Failure 429 {object} ErrorResponse "Too many requests"
+// @Router /auth/token [post]
+func (h *OAuthClientHandler) IssueToken(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+	// Tokens must not be cached, see RFC 6749 section 5.1
+	c.Header("Cache-Control", "no-store")
+	c.Header("Pragma", "no-cache")
+
+	if req.GrantType != "client_credentials" {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, ErrUnsupportedGrantType))
+		return
+	}
+	clientID, secret, found := c.Request.BasicAuth()
+	if !found {
+		clientID, secret = req.ClientID, req.ClientSecret
+	}
+
+	client, err := h.clientsUC.AuthenticateClient(c.Request.Context(), clientID, secret)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid client credentials") {
+			c.Header("WWW-Authenticate", `Basic realm="oauth2 token"`)
+			c.JSON(http.StatusUnauthorized, errorResponse(http.StatusUnauthorized, err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	scopes, err := client.GrantScopes(strings.Fields(req.Scope))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		return
+	}
+
+	token, expiresAt, err := h.tokens.GenerateClient(client.ID, scopes, domain.TenantFromContext(c.Request.Context()))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	c.JSON(http.StatusOK, TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(time.Until(expiresAt).Round(time.Second).Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	})
+}
+
+// ListClients godoc
+// @Summary List OAuth2 clients
+// @Description List the OAuth2 clients registered in the tenant, without their secrets
+// @Tags security
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.OAuthClient "OAuth2 clients"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Router /admin/oauth-clients [get]
+func (h *OAuthClientHandler) ListClients(c *gin.Context) {
+	clients, err := h.clientsUC.ListClients(c.Request.Context())
+	if err != nil {
+		oauthClientError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, clients)
+}
+
+// GetClient godoc
+// @Summary Get an OAuth2 client
+// @Tags security
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Client ID" example("3f2b8c1a-5d6e-4f70-8a9b-1c2d3e4f5a6b")
+// @Success 200 {object} domain.OAuthClient "OAuth2 client"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Failure 404 {object} ErrorResponse "Client not found"
+// @Router /admin/oauth-clients/{id} [get]
+func (h *OAuthClientHandler) GetClient(c *gin.Context) {
+	client, err := h.clientsUC.GetClient(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		oauthClientError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, client)
+}
+
+// RegisterClient godoc
+// @Summary Register an OAuth2 client
+// @Description Register a service allowed to get access tokens with the client_credentials grant; its scopes are token scopes, such as admin to reach the admin routes, and the permissions it is granted, except * and users:impersonate
+// @Description The client secret is only returned by this request, it is stored hashed
+// @Tags security
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body RegisterClientRequest true "Client name and scopes"
+// @Success 201 {object} RegisterClientResponse "Client registered"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid name or scope"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Router /admin/oauth-clients [post]
+func (h *OAuthClientHandler) RegisterClient(c *gin.Context) {
+	var req RegisterClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	client, secret, err := h.clientsUC.RegisterClient(c.Request.Context(), currentActorID(c), req.Name, req.Scopes)
+	if err != nil {
+		oauthClientError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, RegisterClientResponse{Client: client, ClientSecret: secret})
+}
+
+// DeleteClient godoc
+// @Summary Delete an OAuth2 client
+// @Description Delete a client, which can no longer get tokens; the tokens it holds are refused from then on
+// @Tags security
+// @Security BearerAuth
+// @Param id path string true "Client ID" example("3f2b8c1a-5d6e-4f70-8a9b-1c2d3e4f5a6b")
+// @Success 204 "Client deleted"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Failure 404 {object} ErrorResponse "Client not found"
+// @Router /admin/oauth-clients/{id} [delete]
+func (h *OAuthClientHandler) DeleteClient(c *gin.Context) {
+	if err := h.clientsUC.DeleteClient(c.Request.Context(), currentActorID(c), c.Param("id")); err != nil {
+		oauthClientError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func oauthClientError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
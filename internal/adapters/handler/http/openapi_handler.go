@@ -0,0 +1,164 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/frtasoniero/user-management-api/pkg/iso3166"
+	"github.com/frtasoniero/user-management-api/pkg/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// documentedTypes are the request and response bodies whose definitions the served document
+// generates from their fields; the types their fields refer to are found from them
+var documentedTypes = []any{
+	AcceptTermsRequest{},
+	AddEmailDomainRequest{},
+	AddMFAFactorRequest{},
+	AddTagsRequest{},
+	AddressRequest{},
+	AutocompleteUsersResponse{},
+	BatchUpdateUsersRequest{},
+	CSRFTokenResponse{},
+	ConfirmMFAFactorRequest{},
+	ConfirmPhoneRequest{},
+	CountUsersResponse{},
+	CountriesResponse{},
+	CreateOrganizationRequest{},
+	CreateRoleRequest{},
+	DatabaseStatsResponse{},
+	DocumentsResponse{},
+	EmailAvailabilityResponse{},
+	ErrorResponse{},
+	FindOrCreateUserRequest{},
+	IPBlocksResponse{},
+	ImpersonateRequest{},
+	ImpersonateResponse{},
+	IntrospectionResponse{},
+	LoginRequest{},
+	LoginResponse{},
+	LookupUsersRequest{},
+	LookupUsersResponse{},
+	MFAChallengeRequest{},
+	MFARequiredResponse{},
+	MFAVerifyRequest{},
+	MergeUsersRequest{},
+	PasswordStrengthRequest{},
+	PasswordStrengthResponse{},
+	PhoneCodeResponse{},
+	PublishTermsRequest{},
+	ReauthenticateRequest{},
+	ReauthenticationChallengeRequest{},
+	RegisterClientRequest{},
+	RegisterClientResponse{},
+	RegisterRequest{},
+	RegisterResponse{},
+	RegistrationFieldsResponse{},
+	ReviewVerificationRequest{},
+	RevokeTrustedDevicesResponse{},
+	RolesResponse{},
+	SchedulerResponse{},
+	SearchUsersRequest{},
+	SetMemberRequest{},
+	SigningKeysResponse{},
+	SubmitVerificationRequest{},
+	TagsResponse{},
+	TokenResponse{},
+	TrustedDeviceToken{},
+	UpdateOrganizationRequest{},
+	UpdateRoleRequest{},
+	UpdateUserProfileRequest{},
+	UpdateUsernameRequest{},
+	UserStatsResponse{},
+	domain.AuditEvent{},
+	domain.Avatar{},
+	domain.Block{},
+	domain.Document{},
+	domain.DocumentDownload{},
+	domain.EmailDomainRule{},
+	domain.IdentityCheck{},
+	domain.Job{},
+	domain.LoginEvent{},
+	domain.MFAEnrollment{},
+	domain.MFAFactor{},
+	domain.Membership{},
+	domain.OAuthClient{},
+	domain.Organization{},
+	domain.ProfileUpdate{},
+	domain.Relationship{},
+	domain.Role{},
+	domain.SecurityEvent{},
+	domain.Settings{},
+	domain.SigningKey{},
+	domain.SettingsUpdate{},
+	domain.TermsStatus{},
+	domain.TermsVersion{},
+	domain.TrustedDevice{},
+	domain.User{},
+	domain.UserUpsert{},
+	domain.UserVersion{},
+	domain.Verification{},
+	iso3166.Country{},
+	ports.AuditQueryResult{},
+	ports.BlockQueryResult{},
+	ports.DirectorySyncStatus{},
+	ports.DuplicateReport{},
+	ports.GetUsersResult{},
+	ports.JobQueryResult{},
+	ports.LoginHistory{},
+	ports.RegistrationsReport{},
+	ports.RelationshipQueryResult{},
+	ports.SecurityEventFeed{},
+	ports.UserHistoryResult{},
+}
+
+type OpenAPIHandler struct {
+	doc    []byte
+	routes func() gin.RoutesInfo
+
+	once     sync.Once
+	document []byte
+	err      error
+}
+
+// NewOpenAPIHandler serves doc, the Swagger document generated by swag, updated to the routes
+// returned by routes and to the fields of the request and response bodies of the running binary.
+// The document is built on the first request, once every route is registered.
+func NewOpenAPIHandler(doc []byte, routes func() gin.RoutesInfo) *OpenAPIHandler {
+	return &OpenAPIHandler{doc: doc, routes: routes}
+}
+
+// GetDocument godoc
+// @Summary Get the OpenAPI document
+// @Description The Swagger 2.0 document of the running instance: the documented operations of its
+// @Description registered routes, with the definitions of its request and response bodies generated
+// @Description from their fields, for client generators
+// @Tags meta
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} object "Swagger 2.0 document"
+// @Failure 401 "Missing or invalid credentials, when the documentation is protected"
+// @Failure 403 {object} ErrorResponse "system:read permission required, when the documentation is admin only"
+// @Failure 500 {object} ErrorResponse "Document could not be built"
+// @Router /openapi.json [get]
+func (h *OpenAPIHandler) GetDocument(c *gin.Context) {
+	h.once.Do(func() {
+		var routes []openapi.Route
+		for _, route := range h.routes() {
+			routes = append(routes, openapi.Route{Method: route.Method, Path: route.Path})
+		}
+		h.document, h.err = openapi.Build(h.doc, routes, documentedTypes...)
+		if h.err != nil {
+			log.Printf("Failed to build the OpenAPI document: %v", h.err)
+		}
+	})
+	if h.err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: errcode.Internal, Error: "OpenAPI document unavailable"})
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", h.document)
+}
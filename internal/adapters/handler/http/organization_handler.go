@@ -0,0 +1,241 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	orgUC ports.OrganizationUseCase
+}
+
+// CreateOrganizationRequest represents the request body for creating an organization
+type CreateOrganizationRequest struct {
+	Name string `json:"name" binding:"required" example:"Acme Corp"`
+	Slug string `json:"slug" binding:"required" example:"acme-corp"`
+}
+
+// UpdateOrganizationRequest represents the request body for updating an organization
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" binding:"required" example:"Acme Corporation"`
+}
+
+// SetMemberRequest represents the request body for adding a member or changing their role
+type SetMemberRequest struct {
+	Role string `json:"role" binding:"required" example:"member"`
+}
+
+func NewOrganizationHandler(orgUC ports.OrganizationUseCase) *OrganizationHandler {
+	return &OrganizationHandler{
+		orgUC: orgUC,
+	}
+}
+
+// CreateOrganization godoc
+// @Summary Create an organization
+// @Description Create a new organization; the authenticated user becomes its owner
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateOrganizationRequest true "Organization data"
+// @Success 201 {object} domain.Organization "Organization created"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid input data"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 409 {object} ErrorResponse "Conflict - slug already exists"
+// @Router /organizations [post]
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	var req CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	org, err := h.orgUC.CreateOrganization(c.Request.Context(), currentUserID(c), req.Name, req.Slug)
+	if err != nil {
+		organizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, org)
+}
+
+// GetOrganization godoc
+// @Summary Get organization by ID
+// @Description Retrieve an organization the authenticated user is a member of
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization UUID" example("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+// @Success 200 {object} domain.Organization "Organization details"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Not a member of the organization"
+// @Failure 404 {object} ErrorResponse "Organization not found"
+// @Router /organizations/{id} [get]
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	org, err := h.orgUC.GetOrganization(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		organizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// UpdateOrganization godoc
+// @Summary Update an organization
+// @Description Rename an organization (owner or admin members only)
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization UUID" example("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+// @Param request body UpdateOrganizationRequest true "Organization data"
+// @Success 200 {object} domain.Organization "Updated organization"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid input data"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Owner or admin role required"
+// @Failure 404 {object} ErrorResponse "Organization not found"
+// @Router /organizations/{id} [patch]
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	var req UpdateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	org, err := h.orgUC.UpdateOrganization(c.Request.Context(), currentUserID(c), c.Param("id"), req.Name)
+	if err != nil {
+		organizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, org)
+}
+
+// DeleteOrganization godoc
+// @Summary Delete an organization
+// @Description Delete an organization and all of its memberships (owners only)
+// @Tags organizations
+// @Security BearerAuth
+// @Param id path string true "Organization UUID" example("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+// @Success 204 "Organization deleted"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Owner role required"
+// @Failure 404 {object} ErrorResponse "Organization not found"
+// @Router /organizations/{id} [delete]
+func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
+	if err := h.orgUC.DeleteOrganization(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		organizationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListMembers godoc
+// @Summary List organization members
+// @Description List the memberships of an organization the authenticated user belongs to
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization UUID" example("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+// @Success 200 {array} domain.Membership "Organization memberships"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Not a member of the organization"
+// @Failure 404 {object} ErrorResponse "Organization not found"
+// @Router /organizations/{id}/members [get]
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	members, err := h.orgUC.ListMembers(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		organizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, members)
+}
+
+// SetMember godoc
+// @Summary Add a member or change their role
+// @Description Add a user to the organization or change the role of an existing member (owner or admin members only)
+// @Description Only owners can grant or revoke the owner role
+// @Tags organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Organization UUID" example("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+// @Param userId path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param request body SetMemberRequest true "Membership role"
+// @Success 200 {object} domain.Membership "Membership"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid role"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Owner or admin role required"
+// @Failure 404 {object} ErrorResponse "Organization or user not found"
+// @Router /organizations/{id}/members/{userId} [put]
+func (h *OrganizationHandler) SetMember(c *gin.Context) {
+	var req SetMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	membership, err := h.orgUC.SetMember(c.Request.Context(), currentUserID(c), c.Param("id"), c.Param("userId"), req.Role)
+	if err != nil {
+		organizationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, membership)
+}
+
+// RemoveMember godoc
+// @Summary Remove a member
+// @Description Remove a user from the organization (owner or admin members, or the member themselves)
+// @Tags organizations
+// @Security BearerAuth
+// @Param id path string true "Organization UUID" example("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+// @Param userId path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Success 204 "Member removed"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Owner or admin role required"
+// @Failure 404 {object} ErrorResponse "Organization or membership not found"
+// @Router /organizations/{id}/members/{userId} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	if err := h.orgUC.RemoveMember(c.Request.Context(), currentUserID(c), c.Param("id"), c.Param("userId")); err != nil {
+		organizationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListMyOrganizations godoc
+// @Summary List current user memberships
+// @Description List the organization memberships of the authenticated user
+// @Tags organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Membership "User memberships"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Router /users/me/organizations [get]
+func (h *OrganizationHandler) ListMyOrganizations(c *gin.Context) {
+	memberships, err := h.orgUC.ListUserMemberships(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	c.JSON(http.StatusOK, memberships)
+}
+
+func organizationError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "permission denied"):
+		c.JSON(http.StatusForbidden, errorResponse(http.StatusForbidden, err))
+	case strings.Contains(err.Error(), "already in use"):
+		c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
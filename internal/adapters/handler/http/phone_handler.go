@@ -0,0 +1,99 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+type PhoneHandler struct {
+	phoneUC ports.PhoneVerificationUseCase
+}
+
+func NewPhoneHandler(phoneUC ports.PhoneVerificationUseCase) *PhoneHandler {
+	return &PhoneHandler{
+		phoneUC: phoneUC,
+	}
+}
+
+type ConfirmPhoneRequest struct {
+	Code string `json:"code" binding:"required,len=6,numeric" example:"123456"`
+}
+
+type PhoneCodeResponse struct {
+	ExpiresAt time.Time `json:"expires_at" example:"2024-01-01T00:10:00Z"`
+}
+
+// SendPhoneCode godoc
+// @Summary Send a code verifying the current user phone
+// @Description Text a 6-digit one-time code to the phone of the profile of the authenticated user, valid for PHONE_CODE_TTL
+// @Description Another code can be requested after a minute, replacing the previous one
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} PhoneCodeResponse "Code sent"
+// @Failure 400 {object} ErrorResponse "The profile has no phone, or not an international number"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "The phone is already verified"
+// @Failure 429 {object} ErrorResponse "A code was sent less than a minute ago"
+// @Failure 500 {object} ErrorResponse "The SMS could not be sent"
+// @Router /users/me/phone/verify [post]
+func (h *PhoneHandler) SendPhoneCode(c *gin.Context) {
+	expiresAt, err := h.phoneUC.SendCode(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		phoneError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, PhoneCodeResponse{ExpiresAt: expiresAt})
+}
+
+// ConfirmPhone godoc
+// @Summary Confirm the current user phone
+// @Description Mark the phone of the authenticated user verified with the code last sent to it
+// @Description Codes expire after PHONE_CODE_TTL, after 5 wrong codes, or when the phone changes
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ConfirmPhoneRequest true "Code received by SMS"
+// @Success 200 {object} domain.User "User with a verified phone"
+// @Failure 400 {object} ErrorResponse "Wrong or expired code"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/phone/confirm [post]
+func (h *PhoneHandler) ConfirmPhone(c *gin.Context) {
+	var req ConfirmPhoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	user, err := h.phoneUC.ConfirmCode(c.Request.Context(), currentUserID(c), req.Code)
+	if err != nil {
+		phoneError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func phoneError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+	case strings.Contains(err.Error(), "too many requests"):
+		c.JSON(http.StatusTooManyRequests, errorResponse(http.StatusTooManyRequests, err))
+	case strings.Contains(err.Error(), "conflict"):
+		c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+type UpdateUserProfileRequest struct {
+	domain.ProfileUpdate
+	// OverrideMinimumAge accepts a birthdate younger than the minimum age, recorded in the audit log
+	OverrideMinimumAge bool `json:"override_minimum_age,omitempty" example:"false"`
+}
+
+// UpdateMyProfile godoc
+// @Summary Update current user profile
+// @Description Partially update the profile of the authenticated user; omitted fields are left unchanged and addresses have their own endpoints
+// @Description A new birthdate must meet the minimum age (MINIMUM_AGE), reported as USER_UNDER_MINIMUM_AGE
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.ProfileUpdate true "Profile fields to change"
+// @Success 200 {object} domain.User "Updated user"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid field or under the minimum age"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/profile [patch]
+func (h *UserHandler) UpdateMyProfile(c *gin.Context) {
+	var req domain.ProfileUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	user, err := h.userUC.UpdateProfile(c.Request.Context(), currentUserID(c), currentUserID(c), req, false)
+	if err != nil {
+		profileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// UpdateUserProfile godoc
+// @Summary Update the profile of a user
+// @Description Partially update the profile of any user; omitted fields are left unchanged and addresses have their own endpoints
+// @Description override_minimum_age accepts a birthdate younger than the minimum age, recorded as user.minimum_age_overridden in the audit log
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param request body UpdateUserProfileRequest true "Profile fields to change"
+// @Success 200 {object} domain.User "Updated user"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid field or under the minimum age"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:profile permission required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/{id}/profile [patch]
+func (h *UserHandler) UpdateUserProfile(c *gin.Context) {
+	var req UpdateUserProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	user, err := h.userUC.UpdateProfile(c.Request.Context(), currentActorID(c), c.Param("id"), req.ProfileUpdate, req.OverrideMinimumAge)
+	if err != nil {
+		profileError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func profileError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type ReadinessHandler struct {
+	readiness ports.ReadinessUseCase
+}
+
+func NewReadinessHandler(readiness ports.ReadinessUseCase) *ReadinessHandler {
+	return &ReadinessHandler{
+		readiness: readiness,
+	}
+}
+
+// GetReadiness answers the health of every dependency of the instance, for the readiness probes of
+// orchestrators and load balancers: 200 while it is up or degraded, 503 once a critical dependency
+// is down. It is served at the root of the server, outside of the API base path, without a tenant.
+func (h *ReadinessHandler) GetReadiness(c *gin.Context) {
+	report := h.readiness.CheckReadiness(c.Request.Context())
+	status := http.StatusOK
+	if report.Status == ports.HealthDown {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
@@ -0,0 +1,147 @@
+package http
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// errReauthenticationMethod rejects re-authentications sending neither a password nor a complete factor code
+var errReauthenticationMethod = errors.New("invalid re-authentication: send the password, or the factor_id and code of a second factor")
+
+// ReauthenticateRequest proves the user again with their password, or with a code of one of their
+// second factors
+type ReauthenticateRequest struct {
+	Password string `json:"password,omitempty" example:"securePassword123"`
+	FactorID string `json:"factor_id,omitempty" example:"3f2b8c1e-6d4a-4e9b-9c7f-1a2b3c4d5e6f"`
+	Code     string `json:"code,omitempty" binding:"omitempty,len=6,numeric" example:"123456"`
+}
+
+type ReauthenticationChallengeRequest struct {
+	FactorID string `json:"factor_id" binding:"required" example:"3f2b8c1e-6d4a-4e9b-9c7f-1a2b3c4d5e6f"`
+}
+
+// Reauthenticate godoc
+// @Summary Re-authenticate the current user
+// @Description Prove the password, or a code of a second factor, again and receive a fresh access token with the scopes of the current one
+// @Description Cookie sessions are re-authenticated in place and keep their cookie and CSRF token, no access token is issued
+// @Description Sensitive routes, such as removing a second factor or deleting the account, require a password or second factor proven within RECENT_AUTH_MAX_AGE
+// @Description and otherwise answer 401 AUTH_REAUTHENTICATION_REQUIRED with WWW-Authenticate: Bearer error="insufficient_user_authentication"
+// @Description Wrong passwords and codes count as failed logins of the IP; impersonation tokens cannot re-authenticate
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ReauthenticateRequest true "Password, or factor and code"
+// @Success 200 {object} LoginResponse "Access token issued"
+// @Failure 400 {object} ErrorResponse "Bad request - neither a password nor a factor code"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token, or wrong password or code"
+// @Failure 403 {object} ErrorResponse "Impersonation token, password reset required or account deactivated"
+// @Failure 404 {object} ErrorResponse "User or factor not found"
+// @Failure 429 {object} ErrorResponse "Too many failed logins from this IP"
+// @Router /auth/reauthenticate [post]
+func (h *AuthHandler) Reauthenticate(c *gin.Context) {
+	var req ReauthenticateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+	if req.Password == "" && (req.FactorID == "" || req.Code == "") {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, errReauthenticationMethod))
+		return
+	}
+	if currentImpersonatorID(c) != "" {
+		c.JSON(http.StatusForbidden, ErrorResponse{Code: errcode.ImpersonationNotAllowed, Error: "impersonation tokens cannot re-authenticate"})
+		return
+	}
+	ip := c.ClientIP()
+	if h.blockedIP(c, ip) {
+		return
+	}
+
+	user, err := h.reauthenticate(c, req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "incorrect password"), strings.Contains(err.Error(), "invalid mfa code"), strings.Contains(err.Error(), "invalid phone code"):
+			// Like at login, so that passwords and codes cannot be guessed from an IP
+			if err := h.backoff.RecordFailure(c.Request.Context(), ip); err != nil {
+				log.Printf("Error recording re-authentication failure for %s: %v", ip, err)
+			}
+			c.JSON(http.StatusUnauthorized, errorResponse(http.StatusUnauthorized, err))
+		case strings.Contains(err.Error(), "password reset required"), strings.Contains(err.Error(), "account deactivated"):
+			c.JSON(http.StatusForbidden, errorResponse(http.StatusForbidden, err))
+		default:
+			mfaError(c, err)
+		}
+		return
+	}
+
+	// The fresh token reaches no more routes than the current one
+	scopes, _ := currentScopes(c)
+	if session := currentSession(c); session != nil {
+		if err := h.sessions.Reauthenticated(c.Request.Context(), session); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+			return
+		}
+		c.JSON(http.StatusOK, LoginResponse{ExpiresAt: session.ExpiresAt, Scope: strings.Join(scopes, " ")})
+		return
+	}
+	token, expiresAt, err := h.tokens.GenerateScoped(user.ID, user.EffectiveRoles(), user.TenantID, user.TokenGeneration, scopes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	c.JSON(http.StatusOK, LoginResponse{AccessToken: token, TokenType: "Bearer", ExpiresAt: expiresAt, Scope: strings.Join(scopes, " ")})
+}
+
+// reauthenticate verifies the password of the request, or else its factor code, and returns the current user
+func (h *AuthHandler) reauthenticate(c *gin.Context, req ReauthenticateRequest) (*domain.User, error) {
+	if req.Password != "" {
+		return h.userUC.VerifyPassword(c.Request.Context(), currentUserID(c), req.Password)
+	}
+	if err := h.mfaUC.Verify(c.Request.Context(), currentUserID(c), req.FactorID, req.Code); err != nil {
+		return nil, err
+	}
+	return h.userUC.GetUserByID(c.Request.Context(), currentUserID(c))
+}
+
+// SendReauthenticationCode godoc
+// @Summary Send a re-authentication code to an SMS factor
+// @Description Text a 6-digit code to an SMS factor of the authenticated user, sent to /auth/reauthenticate within PHONE_CODE_TTL
+// @Description Another code can be requested after a minute; the text messages sent to each phone are limited by RATE_LIMIT_SMS
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ReauthenticationChallengeRequest true "SMS factor"
+// @Success 202 {object} PhoneCodeResponse "Code sent"
+// @Failure 400 {object} ErrorResponse "Bad request - not an SMS factor"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Impersonation token"
+// @Failure 404 {object} ErrorResponse "User or factor not found"
+// @Failure 429 {object} ErrorResponse "A code was sent less than a minute ago, or too many text messages sent to the phone"
+// @Failure 500 {object} ErrorResponse "The SMS could not be sent"
+// @Router /auth/reauthenticate/challenge [post]
+func (h *AuthHandler) SendReauthenticationCode(c *gin.Context) {
+	var req ReauthenticationChallengeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+	if currentImpersonatorID(c) != "" {
+		c.JSON(http.StatusForbidden, ErrorResponse{Code: errcode.ImpersonationNotAllowed, Error: "impersonation tokens cannot re-authenticate"})
+		return
+	}
+
+	expiresAt, err := h.mfaUC.SendCode(c.Request.Context(), currentUserID(c), req.FactorID)
+	if err != nil {
+		mfaError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, PhoneCodeResponse{ExpiresAt: expiresAt})
+}
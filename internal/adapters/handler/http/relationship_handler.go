@@ -0,0 +1,189 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type RelationshipHandler struct {
+	relationshipUC ports.RelationshipUseCase
+}
+
+func NewRelationshipHandler(relationshipUC ports.RelationshipUseCase) *RelationshipHandler {
+	return &RelationshipHandler{
+		relationshipUC: relationshipUC,
+	}
+}
+
+// Follow godoc
+// @Summary Follow a user
+// @Description Make the authenticated user follow another user of the tenant; following a user already followed returns the existing relationship
+// @Description Users cannot follow the users they block nor the users blocking them
+// @Tags relationships
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "UUID of the user to follow" example("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+// @Success 200 {object} domain.Relationship "Relationship"
+// @Failure 400 {object} ErrorResponse "Users cannot follow themselves nor the users they block"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route, or the user blocks the caller"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/following/{userId} [put]
+func (h *RelationshipHandler) Follow(c *gin.Context) {
+	relationship, err := h.relationshipUC.Follow(c.Request.Context(), currentUserID(c), c.Param("userId"))
+	if err != nil {
+		relationshipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, relationship)
+}
+
+// Unfollow godoc
+// @Summary Unfollow a user
+// @Tags relationships
+// @Security BearerAuth
+// @Param userId path string true "UUID of the followed user" example("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+// @Success 204 "User unfollowed"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "The user is not followed"
+// @Router /users/me/following/{userId} [delete]
+func (h *RelationshipHandler) Unfollow(c *gin.Context) {
+	if err := h.relationshipUC.Unfollow(c.Request.Context(), currentUserID(c), c.Param("userId")); err != nil {
+		relationshipError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListFollowers godoc
+// @Summary List the followers of a user
+// @Description Retrieve a paginated list of the relationships of the users following a user, newest first
+// @Tags relationships
+// @Produce json
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param page query int false "Page number (1-based)" default(1) minimum(1)
+// @Param page_size query int false "Number of relationships per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} ports.RelationshipQueryResult "Relationships with pagination info"
+// @Failure 404 {object} ErrorResponse "User not found or blocking the caller"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/{id}/followers [get]
+func (h *RelationshipHandler) ListFollowers(c *gin.Context) {
+	result, err := h.relationshipUC.ListFollowers(c.Request.Context(), relationshipQuery(c))
+	if err != nil {
+		relationshipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// ListFollowing godoc
+// @Summary List the users a user follows
+// @Description Retrieve a paginated list of the relationships of a user with the users it follows, newest first
+// @Tags relationships
+// @Produce json
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param page query int false "Page number (1-based)" default(1) minimum(1)
+// @Param page_size query int false "Number of relationships per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} ports.RelationshipQueryResult "Relationships with pagination info"
+// @Failure 404 {object} ErrorResponse "User not found or blocking the caller"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/{id}/following [get]
+func (h *RelationshipHandler) ListFollowing(c *gin.Context) {
+	result, err := h.relationshipUC.ListFollowing(c.Request.Context(), relationshipQuery(c))
+	if err != nil {
+		relationshipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// Block godoc
+// @Summary Block a user
+// @Description Make the authenticated user block another user of the tenant, removing their follow relationships in both directions
+// @Description Blocked users can neither view the blocker, which GET /users/{id}, GET /users/by-username/{username}, POST /users/lookup and the follower lists report as not found, nor follow it
+// @Description Blocking a user already blocked returns the existing block
+// @Tags relationships
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "UUID of the user to block" example("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+// @Success 200 {object} domain.Block "Block"
+// @Failure 400 {object} ErrorResponse "Users cannot block themselves"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/blocks/{id} [post]
+func (h *RelationshipHandler) Block(c *gin.Context) {
+	block, err := h.relationshipUC.Block(c.Request.Context(), currentUserID(c), c.Param("id"))
+	if err != nil {
+		relationshipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, block)
+}
+
+// Unblock godoc
+// @Summary Unblock a user
+// @Description Remove a block; the follow relationships removed by the block are not restored
+// @Tags relationships
+// @Security BearerAuth
+// @Param id path string true "UUID of the blocked user" example("7c9e6679-7425-40de-944b-e07fc1f90ae7")
+// @Success 204 "User unblocked"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "The user is not blocked"
+// @Router /users/me/blocks/{id} [delete]
+func (h *RelationshipHandler) Unblock(c *gin.Context) {
+	if err := h.relationshipUC.Unblock(c.Request.Context(), currentUserID(c), c.Param("id")); err != nil {
+		relationshipError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListBlocks godoc
+// @Summary List the users blocked by the authenticated user
+// @Description Retrieve a paginated list of the blocks of the authenticated user, newest first
+// @Tags relationships
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (1-based)" default(1) minimum(1)
+// @Param page_size query int false "Number of blocks per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} ports.BlockQueryResult "Blocks with pagination info"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/me/blocks [get]
+func (h *RelationshipHandler) ListBlocks(c *gin.Context) {
+	query := relationshipQuery(c)
+	query.UserID = currentUserID(c)
+	result, err := h.relationshipUC.ListBlocks(c.Request.Context(), query)
+	if err != nil {
+		relationshipError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func relationshipQuery(c *gin.Context) *ports.RelationshipQuery {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+	return &ports.RelationshipQuery{UserID: c.Param("id"), ViewerID: currentUserID(c), Page: page, PageSize: pageSize}
+}
+
+func relationshipError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "permission denied"):
+		c.JSON(http.StatusForbidden, errorResponse(http.StatusForbidden, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// LogRequests logs the requests, in the format of the default logger of Gin, down to the log level
+// of config: debug and info log every request, warn the ones failing with a 4xx or 5xx status and
+// error the ones failing with a 5xx status. The level is read on every request, so reloads apply at
+// once.
+func LogRequests(config ports.ConfigUseCase) gin.HandlerFunc {
+	return gin.LoggerWithConfig(gin.LoggerConfig{
+		Skip: func(c *gin.Context) bool {
+			status := c.Writer.Status()
+			switch config.Config().LogLevel {
+			case ports.LogLevelWarn:
+				return status < http.StatusBadRequest
+			case ports.LogLevelError:
+				return status < http.StatusInternalServerError
+			default:
+				return false
+			}
+		},
+	})
+}
@@ -0,0 +1,212 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleHandler struct {
+	roleUC ports.RoleUseCase
+}
+
+// CreateRoleRequest represents the request body for creating a role
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required" example:"support"`
+	Description string   `json:"description" example:"Support agents"`
+	Permissions []string `json:"permissions" example:"users:tags"`
+}
+
+// UpdateRoleRequest represents the request body for updating a role; omitted fields are left unchanged
+type UpdateRoleRequest struct {
+	Description *string  `json:"description,omitempty" example:"Tier 1 support agents"`
+	Permissions []string `json:"permissions,omitempty" example:"users:tags,users:metadata"`
+}
+
+// RolesResponse contains the roles of a user after a change
+type RolesResponse struct {
+	Roles []string `json:"roles" example:"user,support"`
+}
+
+func NewRoleHandler(roleUC ports.RoleUseCase) *RoleHandler {
+	return &RoleHandler{
+		roleUC: roleUC,
+	}
+}
+
+// ListRoles godoc
+// @Summary List roles
+// @Description List the built-in system roles and the custom roles of the tenant
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} domain.Role "Roles"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "roles:manage permission required"
+// @Router /admin/roles [get]
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.roleUC.ListRoles(c.Request.Context())
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, roles)
+}
+
+// GetRole godoc
+// @Summary Get role by name
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Role name" example("support")
+// @Success 200 {object} domain.Role "Role details"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "roles:manage permission required"
+// @Failure 404 {object} ErrorResponse "Role not found"
+// @Router /admin/roles/{name} [get]
+func (h *RoleHandler) GetRole(c *gin.Context) {
+	role, err := h.roleUC.GetRole(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+// CreateRole godoc
+// @Summary Create a role
+// @Description Create a custom role with a set of permissions
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateRoleRequest true "Role data"
+// @Success 201 {object} domain.Role "Role created"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid name or permission"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "roles:manage permission required"
+// @Failure 409 {object} ErrorResponse "Conflict - role name already exists"
+// @Router /admin/roles [post]
+func (h *RoleHandler) CreateRole(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	role, err := h.roleUC.CreateRole(c.Request.Context(), req.Name, req.Description, req.Permissions)
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole godoc
+// @Summary Update a role
+// @Description Change the description and/or permissions of a custom role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "Role name" example("support")
+// @Param request body UpdateRoleRequest true "Role changes"
+// @Success 200 {object} domain.Role "Updated role"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid permission or system role"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "roles:manage permission required"
+// @Failure 404 {object} ErrorResponse "Role not found"
+// @Router /admin/roles/{name} [patch]
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	role, err := h.roleUC.UpdateRole(c.Request.Context(), c.Param("name"), req.Description, req.Permissions)
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole godoc
+// @Summary Delete a role
+// @Description Delete a custom role and unassign it from every user
+// @Tags roles
+// @Security BearerAuth
+// @Param name path string true "Role name" example("support")
+// @Success 204 "Role deleted"
+// @Failure 400 {object} ErrorResponse "System roles cannot be deleted"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "roles:manage permission required"
+// @Failure 404 {object} ErrorResponse "Role not found"
+// @Router /admin/roles/{name} [delete]
+func (h *RoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.roleUC.DeleteRole(c.Request.Context(), c.Param("name")); err != nil {
+		roleError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AssignRole godoc
+// @Summary Assign a role to a user
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param name path string true "Role name" example("support")
+// @Success 200 {object} RolesResponse "Resulting user roles"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "roles:assign permission required"
+// @Failure 404 {object} ErrorResponse "User or role not found"
+// @Router /admin/users/{id}/roles/{name} [put]
+func (h *RoleHandler) AssignRole(c *gin.Context) {
+	roles, err := h.roleUC.AssignRole(c.Request.Context(), c.Param("id"), c.Param("name"))
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, RolesResponse{Roles: roles})
+}
+
+// UnassignRole godoc
+// @Summary Unassign a role from a user
+// @Description Remove a role from a user; users must keep at least one role
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param name path string true "Role name" example("support")
+// @Success 200 {object} RolesResponse "Resulting user roles"
+// @Failure 400 {object} ErrorResponse "Users must keep at least one role"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "roles:assign permission required"
+// @Failure 404 {object} ErrorResponse "User or role not found"
+// @Router /admin/users/{id}/roles/{name} [delete]
+func (h *RoleHandler) UnassignRole(c *gin.Context) {
+	roles, err := h.roleUC.UnassignRole(c.Request.Context(), c.Param("id"), c.Param("name"))
+	if err != nil {
+		roleError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, RolesResponse{Roles: roles})
+}
+
+func roleError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "already in use"):
+		c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+	case strings.Contains(err.Error(), "invalid"), strings.Contains(err.Error(), "system roles"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,57 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type SecurityEventHandler struct {
+	securityEventUC ports.SecurityEventUseCase
+}
+
+func NewSecurityEventHandler(securityEventUC ports.SecurityEventUseCase) *SecurityEventHandler {
+	return &SecurityEventHandler{
+		securityEventUC: securityEventUC,
+	}
+}
+
+// ListMySecurityEvents godoc
+// @Summary List current user security events
+// @Description Retrieve the security events of the authenticated user, newest first: password and email changes, second factors
+// @Description added or removed, devices trusted or revoked, reported logins and logins from new devices, with their metadata
+// @Description Pass next_before of a response as before to get the next page; actor_id is set when someone else made the change
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param before query string false "Only events that occurred before this RFC 3339 time" example("2024-01-01T00:00:00Z")
+// @Param limit query int false "Number of events" default(20) minimum(1) maximum(100)
+// @Success 200 {object} ports.SecurityEventFeed "Security events"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid before time"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/me/security-events [get]
+func (h *SecurityEventHandler) ListMySecurityEvents(c *gin.Context) {
+	var before time.Time
+	if value := c.Query("before"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, domain.ErrInvalidSecurityEventsBefore))
+			return
+		}
+		before = t
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	feed, err := h.securityEventUC.ListSecurityEvents(c.Request.Context(), currentUserID(c), before, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	c.JSON(http.StatusOK, feed)
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+type SecurityHandler struct {
+	backoff ports.IPBackoff
+}
+
+// IPBlocksResponse lists the blocked IPs with the login failure counters
+type IPBlocksResponse struct {
+	Blocks []ports.IPBlock       `json:"blocks"`
+	Stats  *ports.IPBackoffStats `json:"stats"`
+}
+
+func NewSecurityHandler(backoff ports.IPBackoff) *SecurityHandler {
+	return &SecurityHandler{
+		backoff: backoff,
+	}
+}
+
+// ListIPBlocks godoc
+// @Summary List blocked IPs
+// @Description List the IPs currently blocked after repeated failed logins, with failure counters
+// @Tags security
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} IPBlocksResponse "Blocked IPs and counters"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/security/ip-blocks [get]
+func (h *SecurityHandler) ListIPBlocks(c *gin.Context) {
+	blocks, err := h.backoff.ListBlocks(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	stats, err := h.backoff.Stats(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	c.JSON(http.StatusOK, IPBlocksResponse{Blocks: blocks, Stats: stats})
+}
+
+// ClearIPBlock godoc
+// @Summary Clear an IP block
+// @Description Unblock an IP and forget its failed logins
+// @Tags security
+// @Security BearerAuth
+// @Param ip path string true "Blocked IP" example("203.0.113.7")
+// @Success 204 "Block cleared"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Failure 404 {object} ErrorResponse "IP has no recorded failures"
+// @Router /admin/security/ip-blocks/{ip} [delete]
+func (h *SecurityHandler) ClearIPBlock(c *gin.Context) {
+	found, err := h.backoff.ClearBlock(c.Request.Context(), c.Param("ip"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.IPNotBlocked, Error: "IP has no recorded failures"})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
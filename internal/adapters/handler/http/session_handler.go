@@ -0,0 +1,106 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// In cookie mode, logins set the session cookie, sent by browsers to every API route, and answer
+// the CSRF token the unsafe requests of the session send in the header, see RequireCSRFToken
+const (
+	sessionCookie     = "session"
+	sessionCookiePath = "/api/v1"
+)
+
+// errNoCookieSession rejects the CSRF token requests of bearer tokens, which need none
+var errNoCookieSession = errors.New("invalid request: csrf tokens are only issued to cookie sessions")
+
+// CSRFTokenResponse is the new CSRF token of a cookie session
+type CSRFTokenResponse struct {
+	CSRFToken string    `json:"csrf_token" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	ExpiresAt time.Time `json:"expires_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// startSession creates a session of the user and answers with its cookie and CSRF token
+func (h *AuthHandler) startSession(c *gin.Context, user *domain.User, scopes []string, trusted *TrustedDeviceToken) {
+	session, token, csrfToken, err := h.sessions.CreateSession(c.Request.Context(), user, scopes, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     sessionCookiePath,
+		Expires:  session.ExpiresAt,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	setCSRFCookie(c, csrfToken, session.ExpiresAt)
+	c.JSON(http.StatusOK, LoginResponse{ExpiresAt: session.ExpiresAt, Scope: strings.Join(scopes, " "), CSRFToken: csrfToken, TrustedDevice: trusted})
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description End the cookie session of the request and clear its cookie; in cookie mode, set by AUTH_MODE, logins set an
+// @Description HttpOnly, Secure, SameSite=Strict session cookie lasting SESSION_TTL instead of answering a bearer token
+// @Description Bearer tokens cannot be revoked one by one and stay valid until they expire, see /users/me/logout-all
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Param X-CSRF-Token header string false "CSRF token of the session, the value of the csrf_token cookie, required with the session cookie"
+// @Success 204 "Logged out"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token, or expired session"
+// @Failure 403 {object} ErrorResponse "Missing or invalid CSRF token"
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if session := currentSession(c); session != nil {
+		if err := h.sessions.EndSession(c.Request.Context(), session); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+			return
+		}
+		http.SetCookie(c.Writer, &http.Cookie{
+			Name:     sessionCookie,
+			Path:     sessionCookiePath,
+			MaxAge:   -1,
+			Secure:   true,
+			HttpOnly: true,
+			SameSite: http.SameSiteStrictMode,
+		})
+		setCSRFCookie(c, "", time.Time{})
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetCSRFToken godoc
+// @Summary Get a new CSRF token of the cookie session
+// @Description Replace the CSRF token of the cookie session of the request, for single-page apps that lost the one of the login, e.g. on reload
+// @Description The token is answered and set in the csrf_token cookie, readable by the scripts of the site; state-changing requests of the
+// @Description session send it in the X-CSRF-Token header and are otherwise answered 403 AUTH_CSRF_TOKEN_INVALID. The previous token stops passing
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} CSRFTokenResponse "CSRF token issued"
+// @Failure 400 {object} ErrorResponse "Bearer token, which needs no CSRF token"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token, or expired session"
+// @Router /auth/csrf-token [get]
+func (h *AuthHandler) GetCSRFToken(c *gin.Context) {
+	session := currentSession(c)
+	if session == nil {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, errNoCookieSession))
+		return
+	}
+	csrfToken, err := h.sessions.RotateCSRFToken(c.Request.Context(), session)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	setCSRFCookie(c, csrfToken, session.ExpiresAt)
+	c.JSON(http.StatusOK, CSRFTokenResponse{CSRFToken: csrfToken, ExpiresAt: session.ExpiresAt})
+}
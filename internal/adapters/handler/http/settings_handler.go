@@ -0,0 +1,71 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// GetMySettings godoc
+// @Summary Get current user settings
+// @Description Retrieve the preferences (theme, language, marketing opt-in) of the authenticated user
+// @Description Users who never changed their settings receive the defaults
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.Settings "User settings"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/settings [get]
+func (h *UserHandler) GetMySettings(c *gin.Context) {
+	settings, err := h.userUC.GetSettings(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateMySettings godoc
+// @Summary Update current user settings
+// @Description Partially update the preferences of the authenticated user; omitted fields are left unchanged
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body domain.SettingsUpdate true "Settings to change"
+// @Success 200 {object} domain.Settings "Updated settings"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid settings"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/me/settings [patch]
+func (h *UserHandler) UpdateMySettings(c *gin.Context) {
+	var req domain.SettingsUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	settings, err := h.userUC.UpdateSettings(c.Request.Context(), currentUserID(c), req)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+		case strings.Contains(err.Error(), "invalid"):
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, settings)
+}
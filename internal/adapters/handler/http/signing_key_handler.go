@@ -0,0 +1,75 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type SigningKeyHandler struct {
+	keysUC ports.SigningKeyUseCase
+}
+
+// SigningKeysResponse lists the token signing keys by activation time
+type SigningKeysResponse struct {
+	Keys []*domain.SigningKey `json:"keys"`
+}
+
+func NewSigningKeyHandler(keysUC ports.SigningKeyUseCase) *SigningKeyHandler {
+	return &SigningKeyHandler{
+		keysUC: keysUC,
+	}
+}
+
+// ListSigningKeys godoc
+// @Summary List the token signing keys
+// @Description List the keys signing and verifying the access tokens of every tenant: the active key signs, pending keys sign once activated and retired keys verify tokens until they expire
+// @Tags security
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SigningKeysResponse "Signing keys"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Failure 404 {object} ErrorResponse "Signing keys are not managed by the tenant"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/security/signing-keys [get]
+func (h *SigningKeyHandler) ListSigningKeys(c *gin.Context) {
+	keys, err := h.keysUC.ListSigningKeys(c.Request.Context())
+	if err != nil {
+		signingKeyError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, SigningKeysResponse{Keys: keys})
+}
+
+// RotateSigningKey godoc
+// @Summary Rotate the token signing key
+// @Description Add a signing key, published at once and signing tokens after the publish delay; the previous keys are retired and expire after the grace period
+// @Tags security
+// @Produce json
+// @Security BearerAuth
+// @Success 201 {object} domain.SigningKey "Pending signing key"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "security:manage permission required"
+// @Failure 404 {object} ErrorResponse "Signing keys are not managed by the tenant"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/security/signing-keys/rotate [post]
+func (h *SigningKeyHandler) RotateSigningKey(c *gin.Context) {
+	key, err := h.keysUC.RotateSigningKey(c.Request.Context(), currentActorID(c))
+	if err != nil {
+		signingKeyError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, key)
+}
+
+func signingKeyError(c *gin.Context, err error) {
+	if strings.Contains(err.Error(), "not enabled") {
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+}
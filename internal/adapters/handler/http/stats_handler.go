@@ -0,0 +1,127 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type StatsHandler struct {
+	statsUC ports.StatsUseCase
+}
+
+// UserStatsResponse contains the daily user statistics of the tenant, oldest first
+type UserStatsResponse struct {
+	Days  int                 `json:"days" example:"30"`
+	Stats []*domain.UserStats `json:"stats"`
+}
+
+func NewStatsHandler(statsUC ports.StatsUseCase) *StatsHandler {
+	return &StatsHandler{
+		statsUC: statsUC,
+	}
+}
+
+// GetUserStats godoc
+// @Summary Get daily user statistics
+// @Description Users of the tenant per day: the total at the end of the day, the new, deleted and active ones, active users
+// @Description having logged in during the day. The statistics of a day are rolled up by a scheduled job once it is over,
+// @Description days without users are left out.
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Number of past days" default(30) minimum(1) maximum(366)
+// @Success 200 {object} UserStatsResponse "Daily user statistics"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid number of days"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "system:read permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/stats/users [get]
+func (h *StatsHandler) GetUserStats(c *gin.Context) {
+	days := 30
+	if value := c.Query("days"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, domain.ErrInvalidStatsDays))
+			return
+		}
+		days = n
+	}
+
+	stats, err := h.statsUC.ListUserStats(c.Request.Context(), days)
+	if err != nil {
+		if strings.Contains(err.Error(), "days must be") {
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	c.JSON(http.StatusOK, UserStatsResponse{Days: days, Stats: stats})
+}
+
+// GetRegistrations godoc
+// @Summary Get registrations over time
+// @Description Users of the tenant registered per day, week (from Monday) or month, in UTC, for charting: every bucket of the period
+// @Description is returned, oldest first, counting zero when no user registered. The first bucket starts at the start of the
+// @Description interval holding from. Soft-deleted users count as registered.
+// @Tags stats
+// @Produce json
+// @Security BearerAuth
+// @Param interval query string false "Bucket interval" Enums(day, week, month) default(day)
+// @Param from query string false "Start of the period, a date or an RFC 3339 time; defaults to 30 days, 12 weeks or 12 months before to" example("2024-01-01")
+// @Param to query string false "End of the period, excluded, a date or an RFC 3339 time; defaults to now" example("2024-02-01")
+// @Success 200 {object} ports.RegistrationsReport "Registrations by bucket"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid interval, time or range"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "system:read permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/analytics/registrations [get]
+func (h *StatsHandler) GetRegistrations(c *gin.Context) {
+	from, err := parseAnalyticsTime(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		return
+	}
+	to, err := parseAnalyticsTime(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		return
+	}
+
+	report, err := h.statsUC.CountRegistrations(c.Request.Context(), &ports.RegistrationsQuery{
+		Interval: c.Query("interval"),
+		From:     from,
+		To:       to,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid interval") || strings.Contains(err.Error(), "invalid range") {
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// parseAnalyticsTime parses a date, as the start of the day in UTC, or an RFC 3339 time; empty
+// values are the zero time
+func parseAnalyticsTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, domain.ErrInvalidAnalyticsTime
+	}
+	return t, nil
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type SystemHandler struct {
+	retries     ports.RetryReporter
+	breaker     ports.BreakerReporter
+	slowQueries ports.SlowQueryReporter
+	scheduler   ports.SchedulerReporter
+}
+
+// DatabaseStatsResponse contains the counters of the database layer since startup
+type DatabaseStatsResponse struct {
+	Retries     ports.RetryStats     `json:"retries"`
+	Breaker     ports.BreakerStats   `json:"breaker"`
+	SlowQueries ports.SlowQueryStats `json:"slow_queries"`
+}
+
+// SchedulerResponse lists the periodic tasks of the scheduler of this instance
+type SchedulerResponse struct {
+	Tasks []ports.ScheduledTaskStats `json:"tasks"`
+}
+
+func NewSystemHandler(retries ports.RetryReporter, breaker ports.BreakerReporter, slowQueries ports.SlowQueryReporter, scheduler ports.SchedulerReporter) *SystemHandler {
+	return &SystemHandler{
+		retries:     retries,
+		breaker:     breaker,
+		slowQueries: slowQueries,
+		scheduler:   scheduler,
+	}
+}
+
+// GetDatabaseStats godoc
+// @Summary Get database resilience counters
+// @Description Counters since startup of this instance: user reads retried after transient database errors,
+// @Description and how many of them recovered or still failed after the last attempt,
+// @Description the state of the circuit breaker failing requests fast while the database is down,
+// @Description and the database operations slower than the slow query threshold grouped by filter shape
+// @Tags system
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} DatabaseStatsResponse "Database counters"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "system:read permission required"
+// @Router /admin/system/database [get]
+func (h *SystemHandler) GetDatabaseStats(c *gin.Context) {
+	c.JSON(http.StatusOK, DatabaseStatsResponse{
+		Retries:     h.retries.RetryStats(),
+		Breaker:     h.breaker.BreakerStats(),
+		SlowQueries: h.slowQueries.SlowQueryStats(),
+	})
+}
+
+// GetScheduler godoc
+// @Summary Get the scheduled tasks
+// @Description The periodic tasks of this instance with their cron schedule, next run time and the outcome
+// @Description of their runs since startup. Each run enqueues a background job, run by a single instance.
+// @Tags system
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} SchedulerResponse "Scheduled tasks"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "system:read permission required"
+// @Router /admin/system/scheduler [get]
+func (h *SystemHandler) GetScheduler(c *gin.Context) {
+	c.JSON(http.StatusOK, SchedulerResponse{Tasks: h.scheduler.ScheduledTasks()})
+}
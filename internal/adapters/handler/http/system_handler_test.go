@@ -0,0 +1,92 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+)
+
+func TestGetDatabaseStats(t *testing.T) {
+	retries := &mocks.RetryReporter{
+		RetryStatsFunc: func() ports.RetryStats { return ports.RetryStats{Retries: 3, Recovered: 2, Exhausted: 1} },
+	}
+	breaker := &mocks.BreakerReporter{
+		BreakerStatsFunc: func() ports.BreakerStats { return ports.BreakerStats{State: ports.BreakerOpen, Opened: 1} },
+	}
+	slowQueries := &mocks.SlowQueryReporter{
+		SlowQueryStatsFunc: func() ports.SlowQueryStats { return ports.SlowQueryStats{ThresholdMS: 100, Count: 4} },
+	}
+	h := NewSystemHandler(retries, breaker, slowQueries, &mocks.SchedulerReporter{})
+
+	w := serve(http.MethodGet, "/admin/system/database", h.GetDatabaseStats, httptest.NewRequest(http.MethodGet, "/admin/system/database", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var resp DatabaseStatsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Retries.Recovered != 2 || resp.Breaker.State != ports.BreakerOpen || resp.SlowQueries.Count != 4 {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestGetScheduler(t *testing.T) {
+	lastRun := time.Date(2024, 1, 1, 3, 0, 7, 0, time.UTC)
+	scheduler := &mocks.SchedulerReporter{
+		ScheduledTasksFunc: func() []ports.ScheduledTaskStats {
+			return []ports.ScheduledTaskStats{
+				{Name: "retention.purge", Schedule: "0 3 * * *", LastRunAt: &lastRun, LastOutcome: ports.TaskFailed, LastError: "boom", Runs: 2, Failures: 1},
+			}
+		},
+	}
+	h := NewSystemHandler(&mocks.RetryReporter{}, &mocks.BreakerReporter{}, &mocks.SlowQueryReporter{}, scheduler)
+
+	w := serve(http.MethodGet, "/admin/system/scheduler", h.GetScheduler, httptest.NewRequest(http.MethodGet, "/admin/system/scheduler", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	var resp SchedulerResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Tasks) != 1 || resp.Tasks[0].LastOutcome != ports.TaskFailed || resp.Tasks[0].Failures != 1 || !resp.Tasks[0].LastRunAt.Equal(lastRun) {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestFailFastWhenDatabaseUnavailable(t *testing.T) {
+	tests := []struct {
+		name       string
+		state      string
+		wantStatus int
+	}{
+		{name: "closed", state: ports.BreakerClosed, wantStatus: http.StatusOK},
+		{name: "open", state: ports.BreakerOpen, wantStatus: http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := &mocks.BreakerReporter{
+				BreakerStatsFunc: func() ports.BreakerStats { return ports.BreakerStats{State: tt.state} },
+			}
+			router := serveWith(FailFastWhenDatabaseUnavailable(breaker))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusServiceUnavailable && w.Header().Get("Retry-After") == "" {
+				t.Error("missing Retry-After header")
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// AddTagsRequest represents the request body for tagging a user
+type AddTagsRequest struct {
+	Tags []string `json:"tags" binding:"required,min=1" example:"beta,vip"`
+}
+
+// TagsResponse contains the tags of a user after a change
+type TagsResponse struct {
+	Tags []string `json:"tags" example:"beta,vip"`
+}
+
+// AddUserTags godoc
+// @Summary Add tags to a user
+// @Description Add one or more tags to a user (admin only); tags are lowercased and duplicates ignored
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param request body AddTagsRequest true "Tags to add"
+// @Success 200 {object} TagsResponse "Resulting user tags"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid tag"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/{id}/tags [post]
+func (h *UserHandler) AddUserTags(c *gin.Context) {
+	var req AddTagsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	tags, err := h.userUC.AddTags(c.Request.Context(), c.Param("id"), req.Tags)
+	if err != nil {
+		tagsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TagsResponse{Tags: tags})
+}
+
+// RemoveUserTag godoc
+// @Summary Remove a tag from a user
+// @Description Remove a single tag from a user (admin only)
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param tag path string true "Tag to remove" example("beta")
+// @Success 200 {object} TagsResponse "Resulting user tags"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid tag"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Admin role required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Router /users/{id}/tags/{tag} [delete]
+func (h *UserHandler) RemoveUserTag(c *gin.Context) {
+	tags, err := h.userUC.RemoveTag(c.Request.Context(), c.Param("id"), c.Param("tag"))
+	if err != nil {
+		tagsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TagsResponse{Tags: tags})
+}
+
+func tagsError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+	case strings.Contains(err.Error(), "invalid tag"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// TenantResolver configures how the tenant of a request is determined
+type TenantResolver struct {
+	BaseDomain    string // Requests to <tenant>.<BaseDomain> resolve to <tenant>; empty disables subdomain resolution
+	Header        string // Header carrying the tenant ID when no subdomain matches (e.g. X-Tenant-ID)
+	DefaultTenant string // Tenant used when none is provided; empty rejects such requests
+}
+
+// ResolveTenant determines the request tenant from the subdomain, the tenant header or the default tenant
+// and stores it in the request context so every repository query is scoped to it
+func ResolveTenant(resolver TenantResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenantID := resolver.fromSubdomain(c.Request.Host)
+		if tenantID == "" && resolver.Header != "" {
+			tenantID = strings.ToLower(strings.TrimSpace(c.GetHeader(resolver.Header)))
+		}
+		if tenantID == "" {
+			tenantID = resolver.DefaultTenant
+		}
+
+		if tenantID == "" {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Code: errcode.TenantRequired, Error: "tenant is required"})
+			return
+		}
+		if !domain.ValidTenantID(tenantID) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{Code: errcode.TenantInvalid, Error: domain.ErrInvalidTenant.Error()})
+			return
+		}
+
+		c.Request = c.Request.WithContext(domain.WithTenant(c.Request.Context(), tenantID))
+		c.Next()
+	}
+}
+
+func (r TenantResolver) fromSubdomain(host string) string {
+	if r.BaseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	subdomain, found := strings.CutSuffix(strings.ToLower(host), "."+strings.ToLower(r.BaseDomain))
+	if !found || strings.Contains(subdomain, ".") {
+		return ""
+	}
+	return subdomain
+}
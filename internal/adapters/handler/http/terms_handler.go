@@ -0,0 +1,169 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+type TermsHandler struct {
+	termsUC ports.TermsUseCase
+}
+
+// PublishTermsRequest represents the request body for publishing a version of the terms
+type PublishTermsRequest struct {
+	Document string `json:"document" binding:"required" example:"terms" enums:"terms,privacy"`
+	Version  string `json:"version" binding:"required" example:"2024-06-01"`
+	// URL is where the text of the version is shown to users
+	URL string `json:"url" example:"https://example.com/legal/terms/2024-06-01"`
+}
+
+// AcceptTermsRequest names the version accepted of each document, as shown to the user
+type AcceptTermsRequest struct {
+	Versions map[string]string `json:"versions" binding:"required,min=1" example:"terms:2024-06-01,privacy:2024-01-15"`
+}
+
+func NewTermsHandler(termsUC ports.TermsUseCase) *TermsHandler {
+	return &TermsHandler{
+		termsUC: termsUC,
+	}
+}
+
+// ListCurrentTerms godoc
+// @Summary List the current terms
+// @Description List the current version of each published document, the terms of service and the privacy policy, which users accept before using the API
+// @Tags terms
+// @Produce json
+// @Success 200 {array} domain.TermsVersion "Current versions"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /terms [get]
+func (h *TermsHandler) ListCurrentTerms(c *gin.Context) {
+	versions, err := h.termsUC.CurrentVersions(c.Request.Context())
+	if err != nil {
+		termsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetMyTerms godoc
+// @Summary Get my terms status
+// @Description Get the current versions the authenticated user has yet to accept, and the versions they accepted with when, newest first
+// @Tags terms
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} domain.TermsStatus "Terms status"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/me/terms [get]
+func (h *TermsHandler) GetMyTerms(c *gin.Context) {
+	status, err := h.termsUC.GetStatus(c.Request.Context(), currentUserID(c))
+	if err != nil {
+		termsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// AcceptMyTerms godoc
+// @Summary Accept the current terms
+// @Description Accept the current versions of documents for the authenticated user, who can use the API again once every current version is accepted
+// @Description Versions must be current: one published since it was shown to the user fails with 409 and nothing is accepted
+// @Tags terms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body AcceptTermsRequest true "Accepted version of each document"
+// @Success 200 {object} domain.TermsStatus "Terms status"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid document"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Impersonation token, or token scopes do not allow the route"
+// @Failure 409 {object} ErrorResponse "Version is not current"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/me/terms/accept [post]
+func (h *TermsHandler) AcceptMyTerms(c *gin.Context) {
+	var req AcceptTermsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+	// Consent is given by the user, not by an admin acting as them
+	if currentImpersonatorID(c) != "" {
+		c.JSON(http.StatusForbidden, ErrorResponse{Code: errcode.ImpersonationNotAllowed, Error: "impersonation tokens cannot accept the terms for the user"})
+		return
+	}
+
+	status, err := h.termsUC.AcceptVersions(c.Request.Context(), currentUserID(c), req.Versions)
+	if err != nil {
+		termsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// ListTermsVersions godoc
+// @Summary List the published terms
+// @Description List the published versions of the documents, newest first
+// @Tags terms
+// @Produce json
+// @Security BearerAuth
+// @Param document query string false "Only the versions of the document" Enums(terms, privacy)
+// @Success 200 {array} domain.TermsVersion "Published versions"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid document"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "terms:manage permission required"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/terms [get]
+func (h *TermsHandler) ListTermsVersions(c *gin.Context) {
+	versions, err := h.termsUC.ListVersions(c.Request.Context(), c.Query("document"))
+	if err != nil {
+		termsError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, versions)
+}
+
+// PublishTermsVersion godoc
+// @Summary Publish a version of the terms
+// @Description Publish a version of the terms of service or of the privacy policy, which becomes current: users are answered 451 until they accept it
+// @Tags terms
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body PublishTermsRequest true "Document, version and URL of its text"
+// @Success 201 {object} domain.TermsVersion "Version published"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid document, version or URL"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "terms:manage permission required"
+// @Failure 409 {object} ErrorResponse "Version already published"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /admin/terms [post]
+func (h *TermsHandler) PublishTermsVersion(c *gin.Context) {
+	var req PublishTermsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	version, err := h.termsUC.PublishVersion(c.Request.Context(), currentActorID(c), req.Document, req.Version, req.URL)
+	if err != nil {
+		termsError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, version)
+}
+
+func termsError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	case strings.Contains(err.Error(), "already published"), strings.Contains(err.Error(), "not the current version"):
+		c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
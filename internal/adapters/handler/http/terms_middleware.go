@@ -0,0 +1,37 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrTermsAcceptanceRequired rejects the requests of users who did not accept the current terms
+var ErrTermsAcceptanceRequired = errors.New("terms acceptance required: accept the current terms at /api/v1/users/me/terms")
+
+// RequireTermsAccepted answers 451 to the users who did not accept the current version of every
+// published document, until they accept them at /users/me/terms. OAuth2 clients and impersonation
+// tokens, which do not act as the user consenting, are let through. It must run after RequireAuth
+// or RequireAuthOrClient.
+func RequireTermsAccepted(terms ports.TermsUseCase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := currentUserID(c)
+		if userID == "" || currentImpersonatorID(c) != "" {
+			c.Next()
+			return
+		}
+
+		pending, err := terms.PendingVersions(c.Request.Context(), userID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+			return
+		}
+		if len(pending) > 0 {
+			c.AbortWithStatusJSON(http.StatusUnavailableForLegalReasons, errorResponse(http.StatusUnavailableForLegalReasons, ErrTermsAcceptanceRequired))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrRequestTimeout is the error of requests that did not complete within their deadline
+var ErrRequestTimeout = errors.New("request timed out")
+
+// RequestTimeouts bound the time a request may spend in its handler, including database calls
+type RequestTimeouts struct {
+	Read  time.Duration // Deadline of GET, HEAD and OPTIONS requests
+	Write time.Duration // Deadline of every other request
+}
+
+// DefaultRequestTimeouts gives reads 5s and writes 10s
+func DefaultRequestTimeouts() RequestTimeouts {
+	return RequestTimeouts{
+		Read:  5 * time.Second,
+		Write: 10 * time.Second,
+	}
+}
+
+// RequestTimeout attaches the read or write deadline to the request context, so a stuck database
+// call returns instead of holding the handler. Requests whose handler fails once the deadline
+// passed are answered 503 with ErrRequestTimeout. Routes streaming their response, given by their
+// full path, get no deadline.
+func RequestTimeout(timeouts RequestTimeouts, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if slices.Contains(exempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		timeout := timeouts.Write
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			timeout = timeouts.Read
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Writer = &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Next()
+	}
+}
+
+// timeoutWriter replaces the 500 responses of handlers that ran out of time with a 503 ErrRequestTimeout
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx context.Context
+}
+
+// Unwrap lets http.ResponseController reach the connection, e.g. to change its deadlines
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if !w.Written() && w.Status() == http.StatusInternalServerError && errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		body, err := json.Marshal(ErrorResponse{Code: errcode.RequestTimeout, Error: ErrRequestTimeout.Error()})
+		if err != nil {
+			return 0, err
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		if _, err := w.ResponseWriter.Write(body); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// FindOrCreateUserRequest names the user created when there is none with the email
+type FindOrCreateUserRequest struct {
+	Email     string `json:"email" binding:"required,email" example:"john.doe@example.com"`
+	FirstName string `json:"first_name,omitempty" example:"John"`
+	LastName  string `json:"last_name,omitempty" example:"Doe"`
+}
+
+// UpsertUserByEmail godoc
+// @Summary Create or update a user by email
+// @Description Mirror a user of another system of record: create the user with the email, without a password, when there is none, or else apply the upsert to it
+// @Description Omitted fields are left untouched and blank fields of the user are always filled. on_conflict decides about the fields the user already has
+// @Description other values for: overwrite them, keep_existing to keep them, or reject to fail with 409 USER_UPSERT_CONFLICT; UPSERT_CONFLICT_POLICY sets the default
+// @Description The minimum age rule applies to new users and changed birthdates. Every change is recorded as user.upserted in the audit log
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param email path string true "Email of the user" example("john.doe@example.com")
+// @Param on_conflict query string false "Conflict policy: overwrite, keep_existing or reject, UPSERT_CONFLICT_POLICY when omitted"
+// @Param request body domain.UserUpsert true "Fields of the user"
+// @Success 200 {object} domain.User "User updated, or already up to date"
+// @Success 201 {object} domain.User "User created"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid email, field or policy, or under the minimum age"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:sync permission required"
+// @Failure 409 {object} ErrorResponse "Conflict - the user has other values and on_conflict=reject, or the username or an alias of the email is in use"
+// @Router /users/by-email/{email} [put]
+func (h *UserHandler) UpsertUserByEmail(c *gin.Context) {
+	var req domain.UserUpsert
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	user, created, err := h.userUC.UpsertUserByEmail(c.Request.Context(), currentActorID(c), c.Param("email"), req, c.Query("on_conflict"))
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "upsert conflict"), strings.Contains(err.Error(), "already in use"):
+			c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+		case strings.Contains(err.Error(), "invalid"):
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+	if created {
+		c.JSON(http.StatusCreated, user)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+// FindOrCreateUser godoc
+// @Summary Find or create a user by email
+// @Description Return the user with the email, unchanged, or else create a minimal one without a password in the same database operation,
+// @Description so that concurrent sign-ins or imports create a single user. Blank names are taken from the email
+// @Description Created users are recorded as user.created in the audit log
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body FindOrCreateUserRequest true "Email and names of the user"
+// @Success 200 {object} domain.User "Existing user"
+// @Success 201 {object} domain.User "User created"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid email or names"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:sync permission required"
+// @Failure 409 {object} ErrorResponse "Conflict - an alias of the email is in use"
+// @Router /users/find-or-create [post]
+func (h *UserHandler) FindOrCreateUser(c *gin.Context) {
+	var req FindOrCreateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	user, created, err := h.userUC.FindOrCreate(c.Request.Context(), currentActorID(c), req.Email, req.FirstName, req.LastName)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "already in use"):
+			c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+		case strings.Contains(err.Error(), "invalid"):
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+	if created {
+		c.JSON(http.StatusCreated, user)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
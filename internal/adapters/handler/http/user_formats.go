@@ -0,0 +1,334 @@
+package http
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// MIMECSV is the media type of CSV user responses
+const MIMECSV = "text/csv"
+
+// userFormats are the media types user responses can be negotiated to from the Accept header
+var userFormats = []string{binding.MIMEJSON, binding.MIMEXML, MIMECSV, binding.MIMEXML2}
+
+// negotiateUserFormat returns the supported media type the Accept header prefers, honoring quality
+// values and wildcards. JSON wins ties and is returned when no supported type is acceptable.
+func negotiateUserFormat(c *gin.Context) string {
+	best, bestQ := binding.MIMEJSON, 0.0
+	for _, format := range userFormats {
+		if q := acceptQuality(c.GetHeader("Accept"), format); q > bestQ {
+			best, bestQ = format, q
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the quality the Accept header gives to a media type, taken from its most
+// specific matching range; an empty header accepts everything
+func acceptQuality(accept, mediaType string) float64 {
+	if strings.TrimSpace(accept) == "" {
+		return 1
+	}
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	quality, specificity := 0.0, -1
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mediaRange := strings.ToLower(strings.TrimSpace(params[0]))
+		var match int
+		switch mediaRange {
+		case mediaType:
+			match = 2
+		case mainType + "/*":
+			match = 1
+		case "*/*":
+			match = 0
+		default:
+			continue
+		}
+		if match < specificity {
+			continue
+		}
+		q := 1.0
+		for _, param := range params[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil && parsed >= 0 && parsed <= 1 {
+					q = parsed
+				}
+			}
+		}
+		quality, specificity = q, match
+	}
+	return quality
+}
+
+// renderUser writes a user as JSON, XML or a single-row CSV, see negotiateUserFormat
+func renderUser(c *gin.Context, user *domain.User) {
+	switch negotiateUserFormat(c) {
+	case binding.MIMEXML, binding.MIMEXML2:
+		c.XML(http.StatusOK, newUserXML(user))
+	case MIMECSV:
+		renderUsersCSV(c, []*domain.User{user})
+	default:
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+// renderUserList writes a page of users as JSON, XML or CSV; CSV responses carry the
+// pagination in the X-Total-Count, X-Page, X-Page-Size and X-Total-Pages headers
+func renderUserList(c *gin.Context, result *ports.GetUsersResult) {
+	switch negotiateUserFormat(c) {
+	case binding.MIMEXML, binding.MIMEXML2:
+		c.XML(http.StatusOK, usersXML{
+			TotalCount: result.TotalCount,
+			Page:       result.Page,
+			PageSize:   result.PageSize,
+			TotalPages: result.TotalPages,
+			Users:      mapUsersXML(result.Users),
+		})
+	case MIMECSV:
+		c.Header("X-Total-Count", strconv.FormatInt(result.TotalCount, 10))
+		c.Header("X-Page", strconv.Itoa(result.Page))
+		c.Header("X-Page-Size", strconv.Itoa(result.PageSize))
+		c.Header("X-Total-Pages", strconv.Itoa(result.TotalPages))
+		renderUsersCSV(c, result.Users)
+	default:
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// usersCSVHeader lists the CSV columns; multivalued fields are joined with commas and the
+// address columns come from the primary address
+var usersCSVHeader = []string{
+	"id", "email", "email_verified_at", "username", "roles", "first_name", "last_name", "phone", "birthdate",
+	"nin", "timezone", "locale", "city", "state", "country", "tags", "created_at", "updated_at",
+	"last_login_at", "last_seen_at",
+}
+
+func renderUsersCSV(c *gin.Context, users []*domain.User) {
+	c.Header("Content-Type", MIMECSV+"; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	rows := make([][]string, 0, len(users)+1)
+	rows = append(rows, usersCSVHeader)
+	for _, user := range users {
+		var city, state, country string
+		if address := user.Profile.PrimaryAddress(); address != nil {
+			city, state, country = address.City, address.State, address.Country
+		}
+		row := []string{
+			user.ID,
+			user.Email,
+			formatOptionalTime(user.EmailVerifiedAt),
+			user.Username,
+			strings.Join(user.Roles, ","),
+			user.Profile.FirstName,
+			user.Profile.LastName,
+			user.Profile.Phone,
+			user.Profile.Birthdate,
+			user.Profile.NIN,
+			user.Profile.Timezone,
+			user.Profile.Locale,
+			city,
+			state,
+			country,
+			strings.Join(user.Tags, ","),
+			formatTime(user.CreatedAt),
+			formatTime(user.UpdatedAt),
+			formatOptionalTime(user.LastLoginAt),
+			formatOptionalTime(user.LastSeenAt),
+		}
+		for i := range row {
+			row[i] = escapeCSVFormula(row[i])
+		}
+		rows = append(rows, row)
+	}
+	if err := w.WriteAll(rows); err != nil {
+		log.Printf("Error writing CSV response: %v", err)
+	}
+}
+
+// escapeCSVFormula prefixes values that spreadsheets would evaluate as formulas with a quote
+func escapeCSVFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+// usersXML is the XML document of a page of users
+type usersXML struct {
+	XMLName    xml.Name  `xml:"users"`
+	TotalCount int64     `xml:"total_count,attr"`
+	Page       int       `xml:"page,attr"`
+	PageSize   int       `xml:"page_size,attr"`
+	TotalPages int       `xml:"total_pages,attr"`
+	Users      []userXML `xml:"user"`
+}
+
+// userXML mirrors the JSON representation of domain.User, with metadata as key attributes
+// since encoding/xml cannot marshal maps
+type userXML struct {
+	XMLName         xml.Name               `xml:"user"`
+	ID              string                 `xml:"id"`
+	Email           string                 `xml:"email"`
+	EmailVerifiedAt *time.Time             `xml:"email_verified_at,omitempty"`
+	Username        string                 `xml:"username,omitempty"`
+	Roles           []string               `xml:"roles>role"`
+	Profile         profileXML             `xml:"profile"`
+	Avatar          *avatarXML             `xml:"avatar,omitempty"`
+	Metadata        []metadataEntry        `xml:"metadata>entry,omitempty"`
+	CustomFields    []metadataEntry        `xml:"custom_fields>field,omitempty"`
+	Tags            []string               `xml:"tags>tag,omitempty"`
+	CreatedAt       time.Time              `xml:"created_at"`
+	UpdatedAt       time.Time              `xml:"updated_at"`
+	LastLoginAt     *time.Time             `xml:"last_login_at,omitempty"`
+	LastSeenAt      *time.Time             `xml:"last_seen_at,omitempty"`
+	Relationships   *relationshipCountsXML `xml:"relationships,omitempty"`
+}
+
+type relationshipCountsXML struct {
+	Followers int64 `xml:"followers,attr"`
+	Following int64 `xml:"following,attr"`
+}
+
+type profileXML struct {
+	FirstName string       `xml:"first_name"`
+	LastName  string       `xml:"last_name"`
+	Addresses []addressXML `xml:"addresses>address,omitempty"`
+	Phone     string       `xml:"phone"`
+	Birthdate string       `xml:"birthdate"`
+	NIN       string       `xml:"nin"`
+	Timezone  string       `xml:"timezone,omitempty"`
+	Locale    string       `xml:"locale,omitempty"`
+}
+
+type addressXML struct {
+	ID       string       `xml:"id,attr"`
+	Type     string       `xml:"type,attr"`
+	Primary  bool         `xml:"primary,attr"`
+	Street   string       `xml:"street"`
+	City     string       `xml:"city"`
+	State    string       `xml:"state"`
+	Country  string       `xml:"country"`
+	ZipCode  string       `xml:"zip_code"`
+	Location *geoPointXML `xml:"location,omitempty"`
+}
+
+type geoPointXML struct {
+	Lat float64 `xml:"lat,attr"`
+	Lng float64 `xml:"lng,attr"`
+}
+
+type avatarXML struct {
+	Version      string    `xml:"version"`
+	Status       string    `xml:"status"`
+	OriginalURL  string    `xml:"original_url"`
+	MediumURL    string    `xml:"medium_url,omitempty"`
+	ThumbnailURL string    `xml:"thumbnail_url,omitempty"`
+	UpdatedAt    time.Time `xml:"updated_at"`
+}
+
+type metadataEntry struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// newMetadataEntries returns the entries of a map, sorted by key
+func newMetadataEntries(values map[string]string) []metadataEntry {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	entries := make([]metadataEntry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, metadataEntry{Key: key, Value: values[key]})
+	}
+	return entries
+}
+
+func newUserXML(user *domain.User) userXML {
+	addresses := make([]addressXML, 0, len(user.Profile.Addresses))
+	for _, a := range user.Profile.Addresses {
+		address := addressXML{
+			ID: a.ID, Type: a.Type, Primary: a.Primary, Street: a.Street, City: a.City,
+			State: a.State, Country: a.Country, ZipCode: a.ZipCode,
+		}
+		if a.Location != nil {
+			address.Location = &geoPointXML{Lat: a.Location.Lat, Lng: a.Location.Lng}
+		}
+		addresses = append(addresses, address)
+	}
+	var relationships *relationshipCountsXML
+	if r := user.Relationships; r != nil {
+		relationships = &relationshipCountsXML{Followers: r.Followers, Following: r.Following}
+	}
+	var avatar *avatarXML
+	if a := user.Avatar; a != nil {
+		avatar = &avatarXML{
+			Version: a.Version, Status: string(a.Status), OriginalURL: a.OriginalURL,
+			MediumURL: a.MediumURL, ThumbnailURL: a.ThumbnailURL, UpdatedAt: a.UpdatedAt,
+		}
+	}
+
+	return userXML{
+		ID:              user.ID,
+		Email:           user.Email,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		Username:        user.Username,
+		Roles:           user.Roles,
+		Profile: profileXML{
+			FirstName: user.Profile.FirstName,
+			LastName:  user.Profile.LastName,
+			Addresses: addresses,
+			Phone:     user.Profile.Phone,
+			Birthdate: user.Profile.Birthdate,
+			NIN:       user.Profile.NIN,
+			Timezone:  user.Profile.Timezone,
+			Locale:    user.Profile.Locale,
+		},
+		Avatar:        avatar,
+		Metadata:      newMetadataEntries(user.Metadata),
+		CustomFields:  newMetadataEntries(user.CustomFields),
+		Tags:          user.Tags,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+		LastLoginAt:   user.LastLoginAt,
+		LastSeenAt:    user.LastSeenAt,
+		Relationships: relationships,
+	}
+}
+
+func mapUsersXML(users []*domain.User) []userXML {
+	mapped := make([]userXML, 0, len(users))
+	for _, user := range users {
+		mapped = append(mapped, newUserXML(user))
+	}
+	return mapped
+}
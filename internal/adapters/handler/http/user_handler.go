@@ -2,75 +2,173 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/frtasoniero/user-management-api/internal/core/domain"
 	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
 	userUC ports.UserUseCase
+	// relationshipUC counts the followers of the users fetched alone; nil leaves out the counts
+	relationshipUC ports.RelationshipUseCase
 }
 
 // RegisterRequest represents the request body for user registration
 type RegisterRequest struct {
 	Email    string         `json:"email" binding:"required,email" example:"john.doe@example.com"`
-	Password string         `json:"password" binding:"required,min=6" example:"securePassword123"`
+	Username string         `json:"username" example:"johndoe"`
+	Password string         `json:"password" binding:"required" example:"securePassword123"`
 	Profile  domain.Profile `json:"profile" binding:"required"`
+	// CustomFields are the values of the custom registration fields, see /users/register/fields
+	CustomFields map[string]string `json:"custom_fields,omitempty"`
+}
+
+// RegisterResponse confirms a registration
+type RegisterResponse struct {
+	Message string `json:"message" example:"User registered successfully"`
+}
+
+// RegistrationFieldsResponse lists the custom fields users register with
+type RegistrationFieldsResponse struct {
+	Fields []domain.CustomField `json:"fields"`
+}
+
+// SearchUsersRequest represents the request body for a structured user search
+type SearchUsersRequest struct {
+	Filter   *domain.SearchFilter `json:"filter"`
+	Page     int                  `json:"page" example:"1"`
+	PageSize int                  `json:"page_size" example:"10"`
+	Sort     string               `json:"sort" example:"created_at"`
+	Order    string               `json:"order" example:"desc"`
+	Fields   []string             `json:"fields" example:"email,profile.first_name"`
+}
+
+// LookupUsersRequest represents the request body for a batch user lookup (max 100 IDs)
+type LookupUsersRequest struct {
+	IDs []string `json:"ids" binding:"required,min=1,max=100" example:"550e8400-e29b-41d4-a716-446655440000,7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+}
+
+// LookupUsersResponse contains the users found by a batch lookup
+type LookupUsersResponse struct {
+	Users []*domain.User `json:"users"`
+}
+
+// CountUsersResponse contains the number of users matching a filter
+type CountUsersResponse struct {
+	Count int64 `json:"count" example:"42"`
+}
+
+// validSortFields lists the fields users can be sorted by
+var validSortFields = map[string]bool{
+	"email":      true,
+	"created_at": true,
+	"updated_at": true,
+	"first_name": true,
+	"last_name":  true,
 }
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Error string `json:"error" example:"Invalid input"`
+	// Code identifies the error for clients, see package errcode; unlike Error it is never translated
+	Code  errcode.Code `json:"code" example:"VALIDATION_FAILED"`
+	Error string       `json:"error" example:"Invalid input"`
+	// Fields maps the JSON path of invalid input fields to their error
+	Fields map[string]string `json:"fields,omitempty"`
+	// Details lists the fields of a request body breaking validation rules
+	Details []FieldViolation `json:"details,omitempty"`
+}
+
+// errorResponse builds the response of err answered with status, listing the invalid field of
+// validation errors
+func errorResponse(status int, err error) ErrorResponse {
+	resp := ErrorResponse{Code: errorCode(status, err), Error: err.Error()}
+	var fieldErr *domain.FieldError
+	if errors.As(err, &fieldErr) {
+		resp.Fields = map[string]string{fieldErr.Field: fieldErr.Err.Error()}
+	}
+	return resp
 }
 
-func NewUserHandler(userUC ports.UserUseCase) *UserHandler {
+func NewUserHandler(userUC ports.UserUseCase, relationshipUC ports.RelationshipUseCase) *UserHandler {
 	return &UserHandler{
-		userUC: userUC,
+		userUC:         userUC,
+		relationshipUC: relationshipUC,
 	}
 }
 
 // Register godoc
 // @Summary Register a new user
 // @Description Register a new user account with email, password, and profile information
-// @Description The password will be securely hashed before storage
+// @Description The password must satisfy the password policy, see POST /auth/password-strength, and will be securely hashed before storage
+// @Description With PASSWORD_BREACH_CHECK=reject, passwords found in known data breaches are refused with USER_PASSWORD_BREACHED
+// @Description The optional username must be unique and is validated against a list of reserved names
+// @Description Emails of a denied domain, or of none of the allowed domains when some are, see /admin/email-domains, are refused with USER_EMAIL_DOMAIN_NOT_ALLOWED
+// @Description custom_fields holds the values of the custom fields configured by REGISTRATION_FIELDS, listed by /users/register/fields; invalid values get USER_CUSTOM_FIELD_INVALID
 // @Tags users
 // @Accept json
 // @Produce json
 // @Param request body RegisterRequest true "User registration data"
 // @Success 201 {object} RegisterResponse "User registered successfully"
 // @Failure 400 {object} ErrorResponse "Bad request - invalid input data"
-// @Failure 409 {object} ErrorResponse "Conflict - email already exists"
+// @Failure 403 {object} ErrorResponse "Email domain not allowed to register"
+// @Failure 409 {object} ErrorResponse "Conflict - email or username already exists"
+// @Failure 413 {object} ErrorResponse "Body too large or nesting JSON too deeply"
 // @Router /users/register [post]
 func (h *UserHandler) Register(c *gin.Context) {
 	var req RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
 		return
 	}
 
-	if err := h.userUC.Register(c.Request.Context(), req.Email, req.Password, req.Profile); err != nil {
+	if err := h.userUC.Register(c.Request.Context(), req.Email, req.Username, req.Password, req.Profile, req.CustomFields); err != nil {
 		status := http.StatusBadRequest
-		if strings.Contains(err.Error(), "already in use") {
+		switch {
+		case strings.Contains(err.Error(), "already in use"):
 			status = http.StatusConflict
+		case strings.Contains(err.Error(), "not allowed"):
+			status = http.StatusForbidden
 		}
-		c.JSON(status, ErrorResponse{Error: err.Error()})
+		c.JSON(status, errorResponse(status, err))
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "User registered successfully"})
+	c.JSON(http.StatusCreated, RegisterResponse{Message: "User registered successfully"})
+}
+
+// GetRegistrationFields godoc
+// @Summary List the custom registration fields
+// @Description List the extra fields configured by REGISTRATION_FIELDS, such as a department or an employee ID, with their type, options and pattern,
+// @Description so sign-up forms render them; registrations send their values in custom_fields and missing required fields are refused
+// @Tags users
+// @Produce json
+// @Success 200 {object} RegistrationFieldsResponse "Custom registration fields"
+// @Router /users/register/fields [get]
+func (h *UserHandler) GetRegistrationFields(c *gin.Context) {
+	fields := h.userUC.RegistrationFields()
+	if fields == nil {
+		fields = []domain.CustomField{}
+	}
+	c.JSON(http.StatusOK, RegistrationFieldsResponse{Fields: fields})
 }
 
 // GetUserByID godoc
 // @Summary Get user by ID
-// @Description Retrieve a specific user by their UUID
+// @Description Retrieve a specific user by their UUID, with the numbers of users following it and followed by it
+// @Description The response format follows the Accept header: JSON (default), application/xml or text/csv
+// @Description Users blocking the authenticated caller are reported as not found
 // @Tags users
 // @Accept json
-// @Produce json
+// @Produce json,application/xml,text/csv
 // @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param X-Consistency-Token header string false "X-Consistency-Token header of a previous response, for the users to include its writes"
 // @Success 200 {object} domain.User "User details"
 // @Failure 400 {object} ErrorResponse "Bad request - invalid UUID format"
 // @Failure 404 {object} ErrorResponse "User not found"
@@ -80,30 +178,155 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	user, err := h.userUC.GetUserByID(c.Request.Context(), idParam)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
 		} else {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
 		}
 		return
 	}
+	visible, err := h.visibleUsers(c, []*domain.User{user})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	if len(visible) == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+		return
+	}
+	if err := h.countRelationships(c, user); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	hideActivity(c, user)
+	renderUser(c, user)
+}
+
+// GetUserByUsername godoc
+// @Summary Get user by username
+// @Description Retrieve a specific user by their username (case-insensitive), with the numbers of users following it and followed by it
+// @Description Users blocking the authenticated caller are reported as not found
+// @Tags users
+// @Produce json
+// @Param username path string true "Username" example("johndoe")
+// @Success 200 {object} domain.User "User details"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/by-username/{username} [get]
+func (h *UserHandler) GetUserByUsername(c *gin.Context) {
+	user, err := h.userUC.GetUserByUsername(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+	visible, err := h.visibleUsers(c, []*domain.User{user})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	if len(visible) == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+		return
+	}
+	if err := h.countRelationships(c, user); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+	hideActivity(c, user)
 	c.JSON(http.StatusOK, user)
 }
 
+// visibleUsers leaves out the users blocking the authenticated caller, as if they did not exist
+func (h *UserHandler) visibleUsers(c *gin.Context, users []*domain.User) ([]*domain.User, error) {
+	if h.relationshipUC == nil || len(users) == 0 {
+		return users, nil
+	}
+	ids := make([]string, 0, len(users))
+	for _, user := range users {
+		if user != nil {
+			ids = append(ids, user.ID)
+		}
+	}
+	blockers, err := h.relationshipUC.ListBlockers(c.Request.Context(), currentUserID(c), ids)
+	if err != nil || len(blockers) == 0 {
+		return users, err
+	}
+
+	blocking := make(map[string]bool, len(blockers))
+	for _, id := range blockers {
+		blocking[id] = true
+	}
+	visible := make([]*domain.User, 0, len(users))
+	for _, user := range users {
+		if user != nil && !blocking[user.ID] {
+			visible = append(visible, user)
+		}
+	}
+	return visible, nil
+}
+
+// countRelationships sets the numbers of followers and followed users of a user
+func (h *UserHandler) countRelationships(c *gin.Context, user *domain.User) error {
+	if h.relationshipUC == nil || user == nil {
+		return nil
+	}
+	counts, err := h.relationshipUC.CountRelationships(c.Request.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+	user.Relationships = counts
+	return nil
+}
+
+// UserExists godoc
+// @Summary Check if a user exists
+// @Description Lightweight existence probe: responds 200 or 404 without a body
+// @Tags users
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Success 200 "User exists"
+// @Failure 404 "User not found"
+// @Failure 500 "Internal server error"
+// @Router /users/{id} [head]
+func (h *UserHandler) UserExists(c *gin.Context) {
+	exists, err := h.userUC.UserExists(c.Request.Context(), c.Param("id"))
+	switch {
+	case err != nil:
+		c.Status(http.StatusInternalServerError)
+	case !exists:
+		c.Status(http.StatusNotFound)
+	default:
+		c.Status(http.StatusOK)
+	}
+}
+
 // GetUsers godoc
 // @Summary Get users with advanced filtering
 // @Description Retrieve a paginated list of users with optional search, sorting, and field selection
-// @Description Supports full-text search across email, first name, and last name
+// @Description Supports full-text search across email, username, first name, and last name
+// @Description The response format follows the Accept header: JSON (default), application/xml or text/csv with the pagination in X-Total-Count, X-Page, X-Page-Size and X-Total-Pages headers
 // @Tags users
 // @Accept json
-// @Produce json
+// @Produce json,application/xml,text/csv
 // @Param page query int false "Page number (1-based)" default(1) minimum(1)
 // @Param page_size query int false "Number of users per page" default(10) minimum(1) maximum(100)
-// @Param search query string false "Search term for email, first name, or last name" example("john")
+// @Param search query string false "Search term for email, username, first name, or last name" example("john")
 // @Param sort query string false "Sort field" Enums(email, created_at, updated_at, first_name, last_name) example("created_at")
 // @Param order query string false "Sort order" Enums(asc, desc) default(asc) example("desc")
 // @Param fields query string false "Comma-separated list of fields to include in response" example("email,profile.first_name,created_at")
-// @Success 200 {object} GetUsersResponse "List of users with pagination info"
+// @Param metadata.{key} query string false "Exact-match filter on a metadata value, e.g. metadata.plan=pro"
+// @Param tag query []string false "Only users having all of these tags" collectionFormat(multi)
+// @Param inactive_days query int false "Only users not seen for this many days (users:activity permission)" minimum(1) example(90)
+// @Param near query string false "Only users having an address located within radius_km of this latitude,longitude" example("40.7128,-74.006")
+// @Param radius_km query number false "Radius of the near filter, in kilometers" default(10) minimum(0) maximum(20000) example(25)
+// @Param explain query bool false "Attach the MongoDB query plan and execution statistics to JSON responses (system:read permission)" default(false)
+// @Param facets query string false "Comma-separated facets counted over every matching user and attached to JSON responses: country (of the primary address), status (active, locked or deactivated), role, tag" example("country,status")
+// @Param X-Consistency-Token header string false "X-Consistency-Token header of a previous response, for the users to include its writes"
+// @Success 200 {object} ports.GetUsersResult "List of users with pagination info"
 // @Failure 400 {object} ErrorResponse "Bad request - invalid parameters"
+// @Failure 403 {object} ErrorResponse "users:activity permission required to filter by inactivity, or system:read to explain"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /users [get]
 func (h *UserHandler) GetUsers(c *gin.Context) {
@@ -132,9 +355,6 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 		}
 	}
 
-	// Parse search parameter
-	search := strings.TrimSpace(c.Query("search"))
-
 	// Parse sorting parameters
 	sortBy := strings.TrimSpace(c.Query("sort"))
 	if sortBy == "" {
@@ -142,64 +362,298 @@ func (h *UserHandler) GetUsers(c *gin.Context) {
 	}
 
 	// Validate sort field to prevent injection
-	validSortFields := map[string]bool{
-		"email":      true,
-		"created_at": true,
-		"updated_at": true,
-		"first_name": true,
-		"last_name":  true,
-	}
 	if sortBy != "" && !validSortFields[sortBy] {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:  errcode.ValidationFailed,
 			Error: "Invalid sort field. Valid options: email, created_at, updated_at, first_name, last_name",
 		})
 		return
 	}
 
+	filter, err := parseUserFilters(c)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errActivityPermission) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, errorResponse(status, err))
+		return
+	}
+
 	// Parse order parameter (asc or desc)
 	order := strings.ToLower(strings.TrimSpace(c.Query("order")))
 	if order != "asc" && order != "desc" {
 		order = "asc" // Default order
 	}
 
-	// Build filter options
-	filter := &ports.GetUsersOptions{
-		Page:     page,
-		PageSize: pageSize,
-		Fields:   fields,
-		Search:   search,
-		SortBy:   sortBy,
-		Order:    order,
+	// Query plans reveal the indexes and collection sizes, so only admins may ask for them
+	if explain, _ := strconv.ParseBool(c.Query("explain")); explain {
+		if !hasPermission(c, domain.PermissionSystemRead) {
+			c.JSON(http.StatusForbidden, ErrorResponse{Code: errcode.PermissionDenied, Error: errExplainPermission.Error()})
+			return
+		}
+		filter.Explain = true
 	}
 
+	facets, err := domain.ParseUserFacets(c.Query("facets"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		return
+	}
+
+	// Complete filter options
+	filter.Facets = facets
+	filter.Page = page
+	filter.PageSize = pageSize
+	filter.Fields = fields
+	filter.SortBy = sortBy
+	filter.Order = order
+
 	result, err := h.userUC.GetUsers(c.Request.Context(), filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
 		return
 	}
 
+	hideActivity(c, result.Users...)
+	renderUserList(c, result)
+}
+
+// SearchUsers godoc
+// @Summary Search users with a structured query
+// @Description Search users with nested and/or groups of conditions, for queries too complex for URL parameters
+// @Description Conditions have a field (email, username, roles, tags, created_at, updated_at, profile.*, metadata.<key>),
+// @Description an operator (eq, ne, in, contains, prefix, gt, gte, lt, lte, exists) and a value
+// @Description Time fields accept RFC 3339 timestamps or YYYY-MM-DD dates
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body SearchUsersRequest true "Search query"
+// @Success 200 {object} ports.GetUsersResult "List of users with pagination info"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid search query"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/search [post]
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	var req SearchUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	if req.Sort == "" {
+		req.Sort = "created_at"
+	}
+	if !validSortFields[req.Sort] {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:  errcode.ValidationFailed,
+			Error: "Invalid sort field. Valid options: email, created_at, updated_at, first_name, last_name",
+		})
+		return
+	}
+	order := strings.ToLower(strings.TrimSpace(req.Order))
+	if order != "asc" && order != "desc" {
+		order = "asc"
+	}
+
+	result, err := h.userUC.GetUsers(c.Request.Context(), &ports.GetUsersOptions{
+		Page:     req.Page,
+		PageSize: req.PageSize,
+		Fields:   req.Fields,
+		SortBy:   req.Sort,
+		Order:    order,
+		Filter:   req.Filter,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+
+	hideActivity(c, result.Users...)
 	c.JSON(http.StatusOK, result)
 }
 
-// DeleteUser godoc
-// @Summary Delete user by ID
-// @Description Remove a specific user by their UUID
+// LookupUsers godoc
+// @Summary Get users by IDs
+// @Description Retrieve up to 100 users in a single query, in request order; unknown IDs and users blocking the caller are omitted from the response
 // @Tags users
 // @Accept json
 // @Produce json
-// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
-// @Success 200 {object} domain.User "User details"
-// @Failure 400 {object} ErrorResponse "Bad request - invalid UUID format"
+// @Param request body LookupUsersRequest true "User IDs"
+// @Success 200 {object} LookupUsersResponse "Matching users"
+// @Failure 400 {object} ErrorResponse "Bad request - missing IDs or more than 100 IDs"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/lookup [post]
+func (h *UserHandler) LookupUsers(c *gin.Context) {
+	var req LookupUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	users, err := h.userUC.LookupUsers(c.Request.Context(), req.IDs)
+	if err == nil {
+		users, err = h.visibleUsers(c, users)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+
+	hideActivity(c, users...)
+	c.JSON(http.StatusOK, LookupUsersResponse{Users: users})
+}
+
+// CountUsers godoc
+// @Summary Count users
+// @Description Count the users matching the same search, metadata, tag, inactivity and distance filters as GET /users, without fetching them
+// @Tags users
+// @Produce json
+// @Param search query string false "Search term for email, username, first name, or last name" example("john")
+// @Param metadata.{key} query string false "Exact-match filter on a metadata value, e.g. metadata.plan=pro"
+// @Param tag query []string false "Only users having all of these tags" collectionFormat(multi)
+// @Param inactive_days query int false "Only users not seen for this many days (users:activity permission)" minimum(1) example(90)
+// @Param near query string false "Only users having an address located within radius_km of this latitude,longitude" example("40.7128,-74.006")
+// @Param radius_km query number false "Radius of the near filter, in kilometers" default(10) minimum(0) maximum(20000) example(25)
+// @Success 200 {object} CountUsersResponse "Number of matching users"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid parameters"
+// @Failure 403 {object} ErrorResponse "users:activity permission required to filter by inactivity"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/count [get]
+func (h *UserHandler) CountUsers(c *gin.Context) {
+	filter, err := parseUserFilters(c)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, errActivityPermission) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, errorResponse(status, err))
+		return
+	}
+
+	count, err := h.userUC.CountUsers(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		return
+	}
+
+	c.JSON(http.StatusOK, CountUsersResponse{Count: count})
+}
+
+// parseUserFilters parses the search, metadata, tag, inactivity and distance filters shared by GET /users and GET /users/count
+func parseUserFilters(c *gin.Context) (*ports.GetUsersOptions, error) {
+	// Parse search parameter
+	search := strings.TrimSpace(c.Query("search"))
+
+	// Parse metadata filters (metadata.<key>=<value>)
+	var metadata map[string]string
+	for param, values := range c.Request.URL.Query() {
+		key, found := strings.CutPrefix(param, "metadata.")
+		if !found {
+			continue
+		}
+		if !domain.ValidMetadataKey(key) {
+			return nil, domain.ErrInvalidMetadataKey
+		}
+		if metadata == nil {
+			metadata = make(map[string]string)
+		}
+		metadata[key] = values[0]
+	}
+
+	// Parse tag filters (?tag=beta&tag=vip matches users having both tags)
+	var tags []string
+	if tagParams := c.QueryArray("tag"); len(tagParams) > 0 {
+		normalized, err := domain.NormalizeTags(tagParams)
+		if err != nil {
+			return nil, err
+		}
+		tags = normalized
+	}
+
+	// Parse the inactivity filter (?inactive_days=90 matches users not seen for 90 days),
+	// which would reveal activity to callers not allowed to see it
+	var inactiveSince *time.Time
+	if days := c.Query("inactive_days"); days != "" {
+		if !hasPermission(c, domain.PermissionUsersActivity) {
+			return nil, errActivityPermission
+		}
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 1 {
+			return nil, errors.New("invalid inactive_days: must be a positive number of days")
+		}
+		since := time.Now().AddDate(0, 0, -n)
+		inactiveSince = &since
+	}
+
+	// Parse the distance filter (?near=40.7128,-74.006&radius_km=25 matches users having an
+	// address within 25 km of New York)
+	var near *domain.GeoPoint
+	var radiusKM float64
+	if value := c.Query("near"); value != "" {
+		point, err := domain.ParseGeoPoint(value)
+		if err != nil {
+			return nil, err
+		}
+		near, radiusKM = point, domain.DefaultRadiusKM
+		if radius := c.Query("radius_km"); radius != "" {
+			parsed, err := strconv.ParseFloat(radius, 64)
+			if err != nil || !(parsed > 0 && parsed <= domain.MaxRadiusKM) {
+				return nil, domain.ErrInvalidRadius
+			}
+			radiusKM = parsed
+		}
+	}
+
+	return &ports.GetUsersOptions{
+		Search:        search,
+		Metadata:      metadata,
+		Tags:          tags,
+		InactiveSince: inactiveSince,
+		Near:          near,
+		RadiusKM:      radiusKM,
+	}, nil
+}
+
+var errActivityPermission = errors.New("filtering by activity requires the users:activity permission")
+
+var errExplainPermission = errors.New("explaining queries requires the system:read permission")
+
+// DeleteMyAccount godoc
+// @Summary Delete current user account
+// @Description Permanently delete the account of the authenticated user
+// @Description The password or a second factor must have been proven within RECENT_AUTH_MAX_AGE, see /auth/reauthenticate
+// @Tags users
+// @Security BearerAuth
+// @Success 204 "Account deleted"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token, or re-authentication required"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
 // @Failure 404 {object} ErrorResponse "User not found"
-// @Router /users/{id} [get]
+// @Router /users/me [delete]
+func (h *UserHandler) DeleteMyAccount(c *gin.Context) {
+	if err := h.userUC.DeleteUser(c.Request.Context(), currentUserID(c)); err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteUser removes a user by ID; it is not routed yet, so it has no Swagger annotations
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	idParam := c.Param("id")
 	err := h.userUC.DeleteUser(c.Request.Context(), idParam)
 	if err != nil {
 		if strings.Contains(err.Error(), "not found") {
-			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
 		} else {
-			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
 		}
 		return
 	}
@@ -218,7 +672,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 // 		if strings.Contains(err.Error(), "not found") {
 // 			c.JSON(http.StatusNotFound, ErrorResponse{Error: "User not found"})
 // 		} else {
-// 			c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+// 			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
 // 		}
 // 		return
 // 	}
@@ -0,0 +1,341 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	UseJSONFieldNames()
+}
+
+// serve runs a single request against a router holding one route
+func serve(method, route string, h gin.HandlerFunc, req *http.Request) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Handle(method, route, h)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// serveWith returns a router answering 200 on GET / behind the middleware
+func serveWith(middleware gin.HandlerFunc) *gin.Engine {
+	router := gin.New()
+	router.GET("/", middleware, func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) ErrorResponse {
+	t.Helper()
+	var resp ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decoding error response %q: %v", w.Body.String(), err)
+	}
+	return resp
+}
+
+func TestGetUserByID(t *testing.T) {
+	seen := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name       string
+		user       *domain.User
+		err        error
+		blockers   []string
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "found",
+			user:       &domain.User{ID: "u1", Email: "john@example.com", LastSeenAt: &seen},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "blocking the caller",
+			user:       &domain.User{ID: "u1", Email: "john@example.com"},
+			blockers:   []string{"u1"},
+			wantStatus: http.StatusNotFound,
+			wantError:  "User not found",
+		},
+		{
+			name:       "not found",
+			err:        errors.New("user not found"),
+			wantStatus: http.StatusNotFound,
+			wantError:  "User not found",
+		},
+		{
+			name:       "repository failure",
+			err:        errors.New("connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			wantError:  "connection refused",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotID string
+			users := &mocks.UserUseCase{
+				GetUserByIDFunc: func(_ context.Context, id string) (*domain.User, error) {
+					gotID = id
+					return tt.user, tt.err
+				},
+			}
+			relationships := &mocks.RelationshipUseCase{
+				CountRelationshipsFunc: func(context.Context, string) (*domain.RelationshipCounts, error) {
+					return &domain.RelationshipCounts{Followers: 3, Following: 2}, nil
+				},
+				ListBlockersFunc: func(context.Context, string, []string) ([]string, error) {
+					return tt.blockers, nil
+				},
+			}
+			h := NewUserHandler(users, relationships)
+
+			w := serve(http.MethodGet, "/users/:id", h.GetUserByID, httptest.NewRequest(http.MethodGet, "/users/u1", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if gotID != "u1" {
+				t.Errorf("looked up ID %q, want u1", gotID)
+			}
+			if tt.wantError != "" {
+				if got := decodeError(t, w).Error; got != tt.wantError {
+					t.Errorf("error = %q, want %q", got, tt.wantError)
+				}
+				return
+			}
+			var user domain.User
+			if err := json.Unmarshal(w.Body.Bytes(), &user); err != nil {
+				t.Fatal(err)
+			}
+			if user.Email != tt.user.Email {
+				t.Errorf("email = %q, want %q", user.Email, tt.user.Email)
+			}
+			// Callers without users:activity never see activity times
+			if user.LastSeenAt != nil {
+				t.Errorf("last_seen_at = %v, want hidden", user.LastSeenAt)
+			}
+			if user.Relationships == nil || *user.Relationships != (domain.RelationshipCounts{Followers: 3, Following: 2}) {
+				t.Errorf("relationships = %+v, want 3 followers and 2 followed", user.Relationships)
+			}
+		})
+	}
+}
+
+func TestGetUserByIDNegotiatesCSV(t *testing.T) {
+	users := &mocks.UserUseCase{
+		GetUserByIDFunc: func(context.Context, string) (*domain.User, error) {
+			return &domain.User{ID: "u1", Email: "john@example.com", Roles: []string{"user"}}, nil
+		},
+	}
+	req := httptest.NewRequest(http.MethodGet, "/users/u1", nil)
+	req.Header.Set("Accept", "text/csv, application/json;q=0.5")
+
+	w := serve(http.MethodGet, "/users/:id", NewUserHandler(users, nil).GetUserByID, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, MIMECSV) {
+		t.Errorf("content type = %q, want %s", got, MIMECSV)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], "u1,john@example.com,") {
+		t.Errorf("body = %q, want a header and the user row", w.Body)
+	}
+}
+
+func TestGetUsers(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantOpts   *ports.GetUsersOptions
+	}{
+		{
+			name:       "defaults",
+			query:      "",
+			wantStatus: http.StatusOK,
+			wantOpts:   &ports.GetUsersOptions{Page: 1, PageSize: 10, SortBy: "created_at", Order: "asc"},
+		},
+		{
+			name:       "pagination and sort",
+			query:      "?page=3&page_size=25&sort=email&order=DESC",
+			wantStatus: http.StatusOK,
+			wantOpts:   &ports.GetUsersOptions{Page: 3, PageSize: 25, SortBy: "email", Order: "desc"},
+		},
+		{
+			name:       "invalid sort field",
+			query:      "?sort=password_hash",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "inactivity filter without users:activity",
+			query:      "?inactive_days=90",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "explain without system:read",
+			query:      "?explain=true",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "facets",
+			query:      "?facets=country,%20status,country",
+			wantStatus: http.StatusOK,
+			wantOpts:   &ports.GetUsersOptions{Page: 1, PageSize: 10, SortBy: "created_at", Order: "asc", Facets: []string{"country", "status"}},
+		},
+		{
+			name:       "invalid facet",
+			query:      "?facets=country,password_hash",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "near",
+			query:      "?near=40.7128,-74.006&radius_km=25",
+			wantStatus: http.StatusOK,
+			wantOpts:   &ports.GetUsersOptions{Page: 1, PageSize: 10, SortBy: "created_at", Order: "asc", Near: &domain.GeoPoint{Lat: 40.7128, Lng: -74.006}, RadiusKM: 25},
+		},
+		{
+			name:       "near default radius",
+			query:      "?near=40.7128,-74.006",
+			wantStatus: http.StatusOK,
+			wantOpts:   &ports.GetUsersOptions{Page: 1, PageSize: 10, SortBy: "created_at", Order: "asc", Near: &domain.GeoPoint{Lat: 40.7128, Lng: -74.006}, RadiusKM: domain.DefaultRadiusKM},
+		},
+		{
+			name:       "invalid near",
+			query:      "?near=95,10",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid radius",
+			query:      "?near=40.7128,-74.006&radius_km=0",
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotOpts *ports.GetUsersOptions
+			users := &mocks.UserUseCase{
+				GetUsersFunc: func(_ context.Context, opts *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+					gotOpts = opts
+					return &ports.GetUsersResult{Users: []*domain.User{}, Page: opts.Page, PageSize: opts.PageSize}, nil
+				},
+			}
+
+			w := serve(http.MethodGet, "/users", NewUserHandler(users, nil).GetUsers, httptest.NewRequest(http.MethodGet, "/users"+tt.query, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			if tt.wantOpts == nil {
+				if gotOpts != nil {
+					t.Errorf("use case called with %+v, want no call", gotOpts)
+				}
+				return
+			}
+			if gotOpts.Page != tt.wantOpts.Page || gotOpts.PageSize != tt.wantOpts.PageSize ||
+				gotOpts.SortBy != tt.wantOpts.SortBy || gotOpts.Order != tt.wantOpts.Order || !slices.Equal(gotOpts.Facets, tt.wantOpts.Facets) ||
+				!reflect.DeepEqual(gotOpts.Near, tt.wantOpts.Near) || gotOpts.RadiusKM != tt.wantOpts.RadiusKM {
+				t.Errorf("options = %+v, want %+v", gotOpts, tt.wantOpts)
+			}
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	valid := `{"email":"john@example.com","password":"secret123","profile":{"first_name":"John","last_name":"Doe"}}`
+	tests := []struct {
+		name        string
+		body        string
+		err         error
+		wantStatus  int
+		wantFields  []string
+		wantDetails []FieldViolation
+	}{
+		{name: "registered", body: valid, wantStatus: http.StatusCreated},
+		{
+			name:        "missing email",
+			body:        `{"password":"secret123","profile":{}}`,
+			wantStatus:  http.StatusBadRequest,
+			wantDetails: []FieldViolation{{Field: "email", Rule: "required", Message: "email is required"}},
+		},
+		{
+			name:       "missing password and invalid email",
+			body:       `{"email":"john","profile":{}}`,
+			wantStatus: http.StatusBadRequest,
+			wantDetails: []FieldViolation{
+				{Field: "email", Rule: "email", Message: "email must be a valid email address"},
+				{Field: "password", Rule: "required", Message: "password is required"},
+			},
+		},
+		{
+			name:        "wrong type",
+			body:        `{"email":"john@example.com","password":"secret123","profile":{"first_name":7}}`,
+			wantStatus:  http.StatusBadRequest,
+			wantDetails: []FieldViolation{{Field: "profile.first_name", Rule: "type", Param: "string", Message: "profile.first_name must be of type string"}},
+		},
+		{
+			name:       "email in use",
+			body:       valid,
+			err:        errors.New("email already in use"),
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "invalid profile field",
+			body:       valid,
+			err:        &domain.FieldError{Field: "profile.phone", Err: errors.New("invalid phone number")},
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"profile.phone"},
+		},
+		{
+			name:       "weak password",
+			body:       valid,
+			err:        &domain.FieldError{Field: "password", Err: domain.ErrPasswordTooWeak},
+			wantStatus: http.StatusBadRequest,
+			wantFields: []string{"password"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &mocks.UserUseCase{
+				RegisterFunc: func(_ context.Context, email, _, _ string, _ domain.Profile, _ map[string]string) error {
+					if email != "john@example.com" {
+						t.Errorf("registered email %q", email)
+					}
+					return tt.err
+				},
+			}
+			req := httptest.NewRequest(http.MethodPost, "/users/register", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := serve(http.MethodPost, "/users/register", NewUserHandler(users, nil).Register, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+			for _, field := range tt.wantFields {
+				if _, ok := decodeError(t, w).Fields[field]; !ok {
+					t.Errorf("fields = %v, want %s", decodeError(t, w).Fields, field)
+				}
+			}
+			if tt.wantDetails != nil {
+				if got := decodeError(t, w).Details; !slices.Equal(got, tt.wantDetails) {
+					t.Errorf("details = %+v, want %+v", got, tt.wantDetails)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+type UserHistoryHandler struct {
+	historyUC ports.UserHistoryUseCase
+}
+
+func NewUserHistoryHandler(historyUC ports.UserHistoryUseCase) *UserHistoryHandler {
+	return &UserHistoryHandler{
+		historyUC: historyUC,
+	}
+}
+
+// GetUserHistory godoc
+// @Summary List the change history of a user
+// @Description Retrieve a paginated list of the versions of a user, newest first: the fields each write changed, with their old and new
+// @Description values, the caller who made it and when. The first version is the creation of the user; password changes are listed without values
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param page query int false "Page number (1-based)" default(1) minimum(1)
+// @Param page_size query int false "Number of versions per page" default(20) minimum(1) maximum(100)
+// @Success 200 {object} ports.UserHistoryResult "Versions with pagination info"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:history permission required"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/{id}/history [get]
+func (h *UserHistoryHandler) GetUserHistory(c *gin.Context) {
+	page, _ := strconv.Atoi(c.Query("page"))
+	pageSize, _ := strconv.Atoi(c.Query("page_size"))
+
+	result, err := h.historyUC.ListHistory(c.Request.Context(), &ports.UserHistoryQuery{
+		UserID:   c.Param("id"),
+		Page:     page,
+		PageSize: pageSize,
+	})
+	if err != nil {
+		userHistoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// RevertUser godoc
+// @Summary Revert a user to a previous version
+// @Description Restore the profile of a user, addresses included, as of a version of its change history. The revert is recorded as a new version
+// @Description and as user.reverted in the audit log; the password, email, roles and every field outside the profile are kept. A verified phone
+// @Description does not carry over to another phone
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param version query int true "Version to restore" minimum(1) example(2)
+// @Success 200 {object} domain.User "Reverted user"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid version"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:profile permission required"
+// @Failure 404 {object} ErrorResponse "User or version not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/{id}/revert [post]
+func (h *UserHistoryHandler) RevertUser(c *gin.Context) {
+	version, _ := strconv.Atoi(c.Query("version"))
+
+	user, err := h.historyUC.RevertUser(c.Request.Context(), currentActorID(c), c.Param("id"), version)
+	if err != nil {
+		userHistoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
+
+func userHistoryError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/gin-gonic/gin"
+)
+
+// UpdateUsernameRequest represents the request body for changing a username
+type UpdateUsernameRequest struct {
+	Username string `json:"username" binding:"required" example:"johndoe"`
+}
+
+// UpdateMyUsername godoc
+// @Summary Change current user username
+// @Description Claim a new unique username (handle) for the authenticated user
+// @Description Usernames are 3-30 lowercase letters, digits, dots, underscores or dashes; reserved names are rejected
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body UpdateUsernameRequest true "New username"
+// @Success 200 {object} domain.User "Updated user"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid or reserved username"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Token scopes do not allow the route"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "Username already in use"
+// @Router /users/me/username [put]
+func (h *UserHandler) UpdateMyUsername(c *gin.Context) {
+	var req UpdateUsernameRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	user, err := h.userUC.SetUsername(c.Request.Context(), currentUserID(c), req.Username)
+	if err != nil {
+		switch {
+		case strings.Contains(err.Error(), "not found"):
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: errcode.UserNotFound, Error: "User not found"})
+		case strings.Contains(err.Error(), "already in use"):
+			c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+		case strings.Contains(err.Error(), "invalid"):
+			c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		default:
+			c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+		}
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/pkg/errcode"
+	"github.com/go-playground/validator/v10"
+)
+
+// errValidationFailed is the message of request bodies breaking validation rules, detailed per field
+var errValidationFailed = errors.New("request validation failed")
+
+// FieldViolation is an input field breaking a validation rule
+type FieldViolation struct {
+	// Field is the JSON path of the field
+	Field string `json:"field" example:"profile.first_name"`
+	// Rule is the binding tag the field breaks, such as required, email, min, max or oneof, or type
+	// for values of the wrong JSON type
+	Rule string `json:"rule" example:"min"`
+	// Param is the parameter of the rule, such as the length of min, or the expected JSON type
+	Param   string `json:"param,omitempty" example:"8"`
+	Message string `json:"message" example:"password is too short"`
+}
+
+// violationMessages are the English messages of the validation rules, LocalizeErrors translates
+// them with the validation.<rule> messages of the catalog
+var violationMessages = map[string]string{
+	"required": "{field} is required",
+	"email":    "{field} must be a valid email address",
+	"min":      "{field} is too short",
+	"max":      "{field} is too long",
+	"oneof":    "{field} has an unsupported value",
+	"type":     "{field} must be of type {param}",
+}
+
+const defaultViolationMessage = "{field} is invalid"
+
+// bindingErrorResponse builds the response of a request body that could not be bound: fields
+// breaking validation rules or holding values of the wrong type are listed in Details, other
+// errors, such as malformed JSON, keep their message
+func bindingErrorResponse(err error) ErrorResponse {
+	var (
+		validationErrs validator.ValidationErrors
+		typeErr        *json.UnmarshalTypeError
+	)
+	var violations []FieldViolation
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fieldErr := range validationErrs {
+			// Namespaces start with the name of the request type, e.g. RegisterRequest.profile.first_name
+			_, field, _ := strings.Cut(fieldErr.Namespace(), ".")
+			violations = append(violations, newViolation(field, fieldErr.Tag(), fieldErr.Param()))
+		}
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		violations = append(violations, newViolation(typeErr.Field, "type", jsonType(typeErr.Type)))
+	default:
+		return errorResponse(http.StatusBadRequest, err)
+	}
+	return ErrorResponse{Code: errcode.ValidationFailed, Error: errValidationFailed.Error(), Details: violations}
+}
+
+func newViolation(field, rule, param string) FieldViolation {
+	template, ok := violationMessages[rule]
+	if !ok {
+		template = defaultViolationMessage
+	}
+	return FieldViolation{Field: field, Rule: rule, Param: param, Message: formatViolation(template, field, param)}
+}
+
+// formatViolation fills the {field} and {param} placeholders of a violation message
+func formatViolation(template, field, param string) string {
+	return strings.NewReplacer("{field}", field, "{param}", param).Replace(template)
+}
+
+// jsonType names the JSON type values of t are decoded from
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,173 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxWebhookSize is the maximum accepted size of webhook deliveries in bytes (1 MB)
+const MaxWebhookSize = 1 << 20
+
+type VerificationHandler struct {
+	verificationUC ports.VerificationUseCase
+}
+
+func NewVerificationHandler(verificationUC ports.VerificationUseCase) *VerificationHandler {
+	return &VerificationHandler{
+		verificationUC: verificationUC,
+	}
+}
+
+type SubmitVerificationRequest struct {
+	DocumentIDs []string `json:"document_ids" binding:"required,min=1,max=10" example:"4d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a"`
+}
+
+type ReviewVerificationRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approve reject" example:"reject"`
+	// Reason is required to reject a verification
+	Reason string `json:"reason" binding:"max=500" example:"The passport photo is not legible"`
+}
+
+// GetVerification godoc
+// @Summary Get the identity verification of a user
+// @Description Retrieve the verification status of a user: unverified, pending_review, verified or rejected
+// @Description Users read their own verification; staff with the users:documents permission and an admin token that of any user
+// @Tags verification
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Success 200 {object} domain.Verification "Verification of the user"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Verification of another user without the users:documents permission"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /users/{id}/verification [get]
+func (h *VerificationHandler) GetVerification(c *gin.Context) {
+	if !authorizeDocuments(c, domain.ScopeUsersRead) {
+		return
+	}
+
+	verification, err := h.verificationUC.GetVerification(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		verificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, verification)
+}
+
+// SubmitVerification godoc
+// @Summary Submit documents for identity verification
+// @Description Submit pending documents of an unverified or rejected user for review, moving its verification to pending_review
+// @Description Users submit their own documents; staff with the users:documents permission and an admin token those of any user. Recorded as verification.submitted in the audit log
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param request body SubmitVerificationRequest true "Documents to review"
+// @Success 200 {object} domain.Verification "Verification pending review"
+// @Failure 400 {object} ErrorResponse "Bad request - no documents, or documents not pending or of another user"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "Verification of another user without the users:documents permission"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "The verification is pending review or verified"
+// @Router /users/{id}/verification [post]
+func (h *VerificationHandler) SubmitVerification(c *gin.Context) {
+	if !authorizeDocuments(c, domain.ScopeUsersWrite) {
+		return
+	}
+
+	var req SubmitVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	verification, err := h.verificationUC.SubmitVerification(c.Request.Context(), currentActorID(c), c.Param("id"), req.DocumentIDs)
+	if err != nil {
+		verificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, verification)
+}
+
+// ReviewVerification godoc
+// @Summary Review the identity verification of a user
+// @Description Approve or reject the verification pending review of a user, or reject a verified user to revoke its verification; rejections require a reason
+// @Description The submitted documents take the outcome, the user is emailed it, and the review is recorded as verification.verified or verification.rejected in the audit log
+// @Tags verification
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User UUID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param request body ReviewVerificationRequest true "Review decision"
+// @Success 200 {object} domain.Verification "Reviewed verification"
+// @Failure 400 {object} ErrorResponse "Bad request - invalid decision or missing reason"
+// @Failure 401 {object} ErrorResponse "Missing or invalid token"
+// @Failure 403 {object} ErrorResponse "users:verify permission required, or reviewing the own verification"
+// @Failure 404 {object} ErrorResponse "User not found"
+// @Failure 409 {object} ErrorResponse "The verification is not pending review nor verified"
+// @Router /users/{id}/verification/review [post]
+func (h *VerificationHandler) ReviewVerification(c *gin.Context) {
+	var req ReviewVerificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, bindingErrorResponse(err))
+		return
+	}
+
+	verification, err := h.verificationUC.ReviewVerification(c.Request.Context(), currentActorID(c), c.Param("id"), req.Decision, req.Reason)
+	if err != nil {
+		verificationError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, verification)
+}
+
+// HandleCheckWebhook godoc
+// @Summary Receive identity check results
+// @Description Webhook of the identity verification provider (IDENTITY_VERIFIER), authenticated by the signature of the delivery: the X-SHA2-Signature header for Onfido, X-Sandbox-Signature for the sandbox
+// @Description A clear check verifies the user, a check to consider leaves the verification pending review by staff; the result is recorded as verification.verified or verification.checked in the audit log
+// @Tags verification
+// @Accept json
+// @Param request body object true "Delivery of the provider"
+// @Success 204 "Delivery processed"
+// @Failure 400 {object} ErrorResponse "Malformed delivery"
+// @Failure 401 {object} ErrorResponse "Invalid signature"
+// @Failure 404 {object} ErrorResponse "No identity verification provider configured, or unknown check"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /webhooks/identity-verification [post]
+func (h *VerificationHandler) HandleCheckWebhook(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, MaxWebhookSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+		return
+	}
+
+	if err := h.verificationUC.HandleCheckWebhook(c.Request.Context(), c.Request.Header, body); err != nil {
+		verificationError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func verificationError(c *gin.Context, err error) {
+	switch {
+	case strings.Contains(err.Error(), "not found"), strings.Contains(err.Error(), "not configured"):
+		c.JSON(http.StatusNotFound, errorResponse(http.StatusNotFound, err))
+	case strings.Contains(err.Error(), "signature"):
+		c.JSON(http.StatusUnauthorized, errorResponse(http.StatusUnauthorized, err))
+	case strings.Contains(err.Error(), "permission denied"):
+		c.JSON(http.StatusForbidden, errorResponse(http.StatusForbidden, err))
+	case strings.Contains(err.Error(), "conflict"):
+		c.JSON(http.StatusConflict, errorResponse(http.StatusConflict, err))
+	case strings.Contains(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, errorResponse(http.StatusBadRequest, err))
+	default:
+		c.JSON(http.StatusInternalServerError, errorResponse(http.StatusInternalServerError, err))
+	}
+}
@@ -0,0 +1,180 @@
+package identity
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	fetchTimeout = 10 * time.Second
+	// defaultKeysTTL caches key sets served without a max-age
+	defaultKeysTTL = time.Hour
+	// refetchInterval bounds how often tokens signed with unknown keys refetch the key set
+	refetchInterval = time.Minute
+	maxKeySetSize   = 1 << 20
+)
+
+var errUnknownKey = errors.New("token signed with an unknown key")
+
+// keySet caches the public keys of a JSON Web Key Set (RFC 7517) by key ID, refetching it when it
+// expires or when a token names a key it does not hold, as after a key rotation
+type keySet struct {
+	url    string
+	client *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	expiresAt time.Time
+	fetchedAt time.Time
+}
+
+func newKeySet(url string) *keySet {
+	return &keySet{url: url, client: &http.Client{Timeout: fetchTimeout}}
+}
+
+// key returns the key kid; fetch errors are returned when no cached key can be used
+func (s *keySet) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key, found := s.keys[kid]
+	now := time.Now()
+	if found && now.Before(s.expiresAt) {
+		return key, nil
+	}
+	if !found && now.Sub(s.fetchedAt) < refetchInterval {
+		return nil, errUnknownKey
+	}
+
+	keys, ttl, err := s.fetch(ctx)
+	if err != nil {
+		// Expired keys keep verifying tokens while the provider is unreachable
+		if found {
+			return key, nil
+		}
+		return nil, err
+	}
+	s.keys, s.expiresAt, s.fetchedAt = keys, now.Add(ttl), now
+	if key, found = keys[kid]; !found {
+		return nil, errUnknownKey
+	}
+	return key, nil
+}
+
+func (s *keySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("fetching %s: HTTP %d", s.url, resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(nil, resp.Body, maxKeySetSize)).Decode(&set); err != nil {
+		return nil, 0, fmt.Errorf("fetching %s: invalid key set: %w", s.url, err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		// Encryption keys and unsupported key types are skipped
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.KeyID] = key
+		}
+	}
+	return keys, maxAge(resp.Header.Get("Cache-Control")), nil
+}
+
+// maxAge returns the max-age directive of a Cache-Control header, or defaultKeysTTL
+func maxAge(cacheControl string) time.Duration {
+	for _, directive := range strings.Split(cacheControl, ",") {
+		if value, found := strings.CutPrefix(strings.TrimSpace(directive), "max-age="); found {
+			if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+				return time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	return defaultKeysTTL
+}
+
+// jsonWebKey is an RSA or elliptic curve public key of a key set
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil || !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.KeyType)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, errors.New("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,109 @@
+// Package identity verifies the tokens of external identity providers, such as Firebase Auth and Keycloak.
+package identity
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Compile-time interface check
+var _ ports.TokenVerifier = (*OIDCVerifier)(nil)
+
+// firebaseKeysURL serves the keys signing the ID tokens of every Firebase project
+const firebaseKeysURL = "https://www.googleapis.com/service_accounts/v1/jwk/securetoken@system.gserviceaccount.com"
+
+// clockSkew tolerates the clock differences between the provider and the API
+const clockSkew = 30 * time.Second
+
+// OIDCVerifier verifies the OpenID Connect tokens of one issuer, signed with the keys of its key set
+type OIDCVerifier struct {
+	issuer   string
+	audience string
+	keys     *keySet
+}
+
+// NewOIDCVerifier verifies the tokens of issuer issued to audience, which must be in their aud
+// claim or be their authorized party (azp), with the keys served at keysURL
+func NewOIDCVerifier(issuer, audience, keysURL string) *OIDCVerifier {
+	return &OIDCVerifier{issuer: issuer, audience: audience, keys: newKeySet(keysURL)}
+}
+
+// NewFirebaseVerifier verifies the ID tokens of the Firebase Auth users of a project
+func NewFirebaseVerifier(projectID string) *OIDCVerifier {
+	return NewOIDCVerifier("https://securetoken.google.com/"+projectID, projectID, firebaseKeysURL)
+}
+
+// NewKeycloakVerifier verifies the tokens a Keycloak realm, such as https://sso.example.com/realms/acme,
+// issued to a client
+func NewKeycloakVerifier(realmURL, clientID string) *OIDCVerifier {
+	realmURL = strings.TrimRight(realmURL, "/")
+	return NewOIDCVerifier(realmURL, clientID, realmURL+"/protocol/openid-connect/certs")
+}
+
+// tokenClaims are the standard claims of OpenID Connect tokens read from the tokens
+type tokenClaims struct {
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	GivenName         string `json:"given_name"`
+	FamilyName        string `json:"family_name"`
+	PreferredUsername string `json:"preferred_username"`
+	AuthorizedParty   string `json:"azp"`
+	jwt.RegisteredClaims
+}
+
+func (v *OIDCVerifier) Verify(ctx context.Context, token string) (*ports.ExternalToken, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (any, error) {
+		// Tokens of other issuers, such as the API itself, never fetch the keys
+		if claims.Issuer != v.issuer {
+			return nil, errors.New("token of another issuer")
+		}
+		kid, _ := t.Header["kid"].(string)
+		key, err := v.keys.key(ctx, kid)
+		if err != nil && !errors.Is(err, errUnknownKey) {
+			log.Printf("Error fetching the signing keys of %s: %v", v.issuer, err)
+			return nil, ports.ErrIdentityProviderUnavailable
+		}
+		return key, err
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	switch {
+	case errors.Is(err, ports.ErrIdentityProviderUnavailable):
+		return nil, ports.ErrIdentityProviderUnavailable
+	case err != nil:
+		return nil, security.ErrInvalidToken
+	case claims.Subject == "":
+		return nil, security.ErrInvalidToken
+	case !slices.Contains(claims.Audience, v.audience) && claims.AuthorizedParty != v.audience:
+		return nil, security.ErrInvalidToken
+	}
+
+	// Firebase tokens only carry the full name
+	firstName, lastName := claims.GivenName, claims.FamilyName
+	if firstName == "" && lastName == "" {
+		firstName, lastName, _ = strings.Cut(strings.TrimSpace(claims.Name), " ")
+	}
+	return &ports.ExternalToken{
+		Issuer:        v.issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Username:      claims.PreferredUsername,
+		FirstName:     firstName,
+		LastName:      strings.TrimSpace(lastName),
+	}, nil
+}
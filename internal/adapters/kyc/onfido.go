@@ -0,0 +1,204 @@
+package kyc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.IdentityVerifier = (*OnfidoVerifier)(nil)
+
+// requestTimeout bounds each call to a provider, which receives document files of up to 5 MB
+const requestTimeout = 30 * time.Second
+
+// OnfidoSignatureHeader carries the hex HMAC-SHA256 of the body of Onfido webhook deliveries,
+// keyed with the token of the webhook
+const OnfidoSignatureHeader = "X-SHA2-Signature"
+
+// onfidoDocumentTypes maps the document types to those of Onfido; proofs of address are not
+// identity documents the document report checks
+var onfidoDocumentTypes = map[string]string{
+	domain.DocumentTypePassport:       "passport",
+	domain.DocumentTypeNationalID:     "national_identity_card",
+	domain.DocumentTypeDriversLicense: "driving_licence",
+}
+
+// OnfidoVerifier runs document checks with the Onfido API (v3.6): every submission creates an
+// applicant, uploads its identity documents and runs a document report on them
+type OnfidoVerifier struct {
+	apiToken      string
+	webhookSecret []byte
+	baseURL       string
+	client        *http.Client
+}
+
+// NewOnfidoVerifier returns a verifier for the API at baseURL, such as https://api.eu.onfido.com/v3.6
+// for the EU region; webhookSecret is the token of the webhook registered in Onfido
+func NewOnfidoVerifier(apiToken string, webhookSecret []byte, baseURL string) *OnfidoVerifier {
+	return &OnfidoVerifier{
+		apiToken:      apiToken,
+		webhookSecret: webhookSecret,
+		baseURL:       strings.TrimRight(baseURL, "/"),
+		client:        &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (v *OnfidoVerifier) Provider() string {
+	return "onfido"
+}
+
+func (v *OnfidoVerifier) StartCheck(ctx context.Context, request *ports.IdentityCheckRequest) (string, error) {
+	var documents []ports.IdentityCheckDocument
+	for _, document := range request.Documents {
+		if _, ok := onfidoDocumentTypes[document.Document.Type]; ok {
+			documents = append(documents, document)
+		}
+	}
+	if len(documents) == 0 {
+		return "", nil
+	}
+
+	var applicant struct {
+		ID string `json:"id"`
+	}
+	profile := request.User.Profile
+	if err := v.postJSON(ctx, "/applicants", map[string]string{"first_name": profile.FirstName, "last_name": profile.LastName, "email": request.User.Email}, &applicant); err != nil {
+		return "", err
+	}
+
+	documentIDs := make([]string, 0, len(documents))
+	for _, document := range documents {
+		id, err := v.uploadDocument(ctx, applicant.ID, document)
+		if err != nil {
+			return "", err
+		}
+		documentIDs = append(documentIDs, id)
+	}
+
+	var check struct {
+		ID string `json:"id"`
+	}
+	body := map[string]any{"applicant_id": applicant.ID, "report_names": []string{"document"}, "document_ids": documentIDs}
+	if err := v.postJSON(ctx, "/checks", body, &check); err != nil {
+		return "", err
+	}
+	return check.ID, nil
+}
+
+// ParseWebhook reads check.completed events and fetches the result of the completed check
+func (v *OnfidoVerifier) ParseWebhook(ctx context.Context, header http.Header, body []byte) (*ports.IdentityCheckResult, error) {
+	if !validSignature(v.webhookSecret, body, header.Get(OnfidoSignatureHeader)) {
+		return nil, ports.ErrInvalidWebhookSignature
+	}
+
+	var event struct {
+		Payload struct {
+			ResourceType string `json:"resource_type"`
+			Action       string `json:"action"`
+			Object       struct {
+				ID string `json:"id"`
+			} `json:"object"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("invalid onfido webhook: %w", err)
+	}
+	if event.Payload.ResourceType != "check" || event.Payload.Action != "check.completed" {
+		return nil, nil
+	}
+
+	var check struct {
+		ID     string `json:"id"`
+		Result string `json:"result"`
+	}
+	if err := v.do(ctx, http.MethodGet, "/checks/"+event.Payload.Object.ID, nil, "", &check); err != nil {
+		return nil, err
+	}
+	// Checks are clear or consider, reported as consider when any report needs a closer look
+	result := domain.IdentityCheckResultConsider
+	if check.Result == domain.IdentityCheckResultClear {
+		result = domain.IdentityCheckResultClear
+	}
+	return &ports.IdentityCheckResult{CheckID: check.ID, Result: result}, nil
+}
+
+func (v *OnfidoVerifier) uploadDocument(ctx context.Context, applicantID string, document ports.IdentityCheckDocument) (string, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	form.WriteField("applicant_id", applicantID)
+	form.WriteField("type", onfidoDocumentTypes[document.Document.Type])
+	part, err := form.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {fmt.Sprintf(`form-data; name="file"; filename=%q`, path.Base(document.Document.StorageKey))},
+		"Content-Type":        {document.Document.ContentType},
+	})
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(document.Content); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	var uploaded struct {
+		ID string `json:"id"`
+	}
+	if err := v.do(ctx, http.MethodPost, "/documents", &body, form.FormDataContentType(), &uploaded); err != nil {
+		return "", err
+	}
+	return uploaded.ID, nil
+}
+
+func (v *OnfidoVerifier) postJSON(ctx context.Context, endpoint string, body, result any) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	return v.do(ctx, http.MethodPost, endpoint, bytes.NewReader(encoded), "application/json", result)
+}
+
+// do calls the API and decodes its JSON response into result
+func (v *OnfidoVerifier) do(ctx context.Context, method, endpoint string, body io.Reader, contentType string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, method, v.baseURL+endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token token="+v.apiToken)
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiError struct {
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiError)
+		return fmt.Errorf("onfido: %s %s: HTTP %d %s %s", method, endpoint, resp.StatusCode, apiError.Error.Type, apiError.Error.Message)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("onfido: invalid response to %s %s: %w", method, endpoint, err)
+	}
+	return nil
+}
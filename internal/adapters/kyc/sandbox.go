@@ -0,0 +1,68 @@
+// Package kyc provides identity verifier adapters running automated identity checks on the
+// documents users submit for verification.
+package kyc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/google/uuid"
+)
+
+var _ ports.IdentityVerifier = (*SandboxVerifier)(nil)
+
+// SandboxSignatureHeader carries the hex HMAC-SHA256 of the body of sandbox webhook deliveries
+const SandboxSignatureHeader = "X-Sandbox-Signature"
+
+// SandboxVerifier is a stub provider for development and tests: it starts checks without sending the
+// documents anywhere, and completes them when their result is posted to the webhook, signed with the
+// webhook secret as a provider would
+type SandboxVerifier struct {
+	secret []byte
+}
+
+func NewSandboxVerifier(secret []byte) *SandboxVerifier {
+	return &SandboxVerifier{secret: secret}
+}
+
+func (v *SandboxVerifier) Provider() string {
+	return "sandbox"
+}
+
+func (v *SandboxVerifier) StartCheck(ctx context.Context, request *ports.IdentityCheckRequest) (string, error) {
+	return "sandbox-" + uuid.New().String(), nil
+}
+
+// ParseWebhook reads a {"check_id": "...", "result": "clear|consider"} body
+func (v *SandboxVerifier) ParseWebhook(ctx context.Context, header http.Header, body []byte) (*ports.IdentityCheckResult, error) {
+	if !validSignature(v.secret, body, header.Get(SandboxSignatureHeader)) {
+		return nil, ports.ErrInvalidWebhookSignature
+	}
+
+	var delivery struct {
+		CheckID string `json:"check_id"`
+		Result  string `json:"result"`
+	}
+	if err := json.Unmarshal(body, &delivery); err != nil {
+		return nil, fmt.Errorf("invalid sandbox webhook: %w", err)
+	}
+	if delivery.CheckID == "" || (delivery.Result != domain.IdentityCheckResultClear && delivery.Result != domain.IdentityCheckResultConsider) {
+		return nil, fmt.Errorf("invalid sandbox webhook: check_id and a result of clear or consider are required")
+	}
+	return &ports.IdentityCheckResult{CheckID: delivery.CheckID, Result: delivery.Result}, nil
+}
+
+// validSignature reports whether signature is the hex HMAC-SHA256 of body with secret
+func validSignature(secret, body []byte, signature string) bool {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(signature), []byte(expected))
+}
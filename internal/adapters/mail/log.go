@@ -0,0 +1,22 @@
+package mail
+
+import (
+	"context"
+	"log"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.Mailer = (*LogMailer)(nil)
+
+// LogMailer writes emails to the log instead of sending them, for development without an SMTP server
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(_ context.Context, message ports.EmailMessage) error {
+	log.Printf("📧 Email to %s: %s\n%s", message.To, message.Subject, message.Body)
+	return nil
+}
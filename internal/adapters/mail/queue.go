@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.Mailer = (*QueueMailer)(nil)
+
+// QueueMailer queues emails as background jobs, delivered by the handler of DeliveryHandler and
+// retried while the mail server fails
+type QueueMailer struct {
+	jobs ports.JobQueue
+}
+
+func NewQueueMailer(jobs ports.JobQueue) *QueueMailer {
+	return &QueueMailer{
+		jobs: jobs,
+	}
+}
+
+func (m *QueueMailer) Send(ctx context.Context, message ports.EmailMessage) error {
+	_, err := m.jobs.Enqueue(ctx, domain.JobTypeEmail, map[string]string{
+		"to":      message.To,
+		"subject": message.Subject,
+		"body":    message.Body,
+	})
+	return err
+}
+
+// DeliveryHandler returns the handler of the email jobs, sending them with mailer
+func DeliveryHandler(mailer ports.Mailer) ports.JobHandler {
+	return func(ctx context.Context, job *domain.Job) error {
+		return mailer.Send(ctx, ports.EmailMessage{
+			To:      job.Payload["to"],
+			Subject: job.Payload["subject"],
+			Body:    job.Payload["body"],
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Package mail provides Mailer adapters delivering emails over SMTP or to the log.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var (
+	_ ports.Mailer        = (*SMTPMailer)(nil)
+	_ ports.HealthChecker = (*SMTPMailer)(nil)
+)
+
+// SMTPMailer sends emails through an SMTP server, authenticating with PLAIN when a username is set.
+// net/smtp upgrades the connection with STARTTLS when the server supports it.
+type SMTPMailer struct {
+	addr     string
+	from     string
+	username string
+	password string
+}
+
+func NewSMTPMailer(addr, from, username, password string) *SMTPMailer {
+	return &SMTPMailer{
+		addr:     addr,
+		from:     from,
+		username: username,
+		password: password,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, message ports.EmailMessage) error {
+	if strings.ContainsAny(message.To, "\r\n") {
+		return fmt.Errorf("invalid recipient %q", message.To)
+	}
+
+	var auth smtp.Auth
+	if m.username != "" {
+		host, _, err := net.SplitHostPort(m.addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", m.username, m.password, host)
+	}
+
+	headers := []string{
+		"From: " + m.from,
+		"To: " + message.To,
+		"Subject: " + mime.QEncoding.Encode("utf-8", message.Subject),
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=utf-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + strings.ReplaceAll(message.Body, "\n", "\r\n")
+
+	// smtp.SendMail has no context support, so honor cancellation by not starting late sends
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(m.addr, auth, m.from, []string{message.To}, []byte(body))
+}
+
+// CheckHealth connects to the SMTP server and reads its greeting, without sending anything
+func (m *SMTPMailer) CheckHealth(ctx context.Context) ports.DependencyHealth {
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", m.addr)
+	if err != nil {
+		return ports.DependencyHealth{Status: ports.HealthDown, Error: err.Error()}
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	host, _, _ := net.SplitHostPort(m.addr)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		return ports.DependencyHealth{Status: ports.HealthDown, Error: err.Error()}
+	}
+	health := ports.DependencyHealth{Status: ports.HealthUp, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+	client.Quit()
+	return health
+}
@@ -0,0 +1,186 @@
+package ratelimit
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.IPBackoff = (*MemoryIPBackoff)(nil)
+
+// ipFailures is the failure history of an IP in a tenant
+type ipFailures struct {
+	count        int
+	lastFailure  time.Time
+	blockedUntil time.Time
+}
+
+// MemoryIPBackoff keeps login failures per tenant and IP in process memory
+type MemoryIPBackoff struct {
+	policy ports.BackoffPolicy
+
+	mu        sync.Mutex
+	entries   map[string]*ipFailures
+	lastSweep time.Time
+
+	failures, rejected, blocks int64
+}
+
+func NewMemoryIPBackoff(policy ports.BackoffPolicy) *MemoryIPBackoff {
+	return &MemoryIPBackoff{
+		policy:    policy,
+		entries:   make(map[string]*ipFailures),
+		lastSweep: time.Now(),
+	}
+}
+
+func (b *MemoryIPBackoff) Check(ctx context.Context, ip string) (time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry := b.entry(ctx, ip, time.Now())
+	if entry == nil {
+		return 0, nil
+	}
+	if remaining := time.Until(entry.blockedUntil); remaining > 0 {
+		b.rejected++
+		return remaining, nil
+	}
+	return 0, nil
+}
+
+func (b *MemoryIPBackoff) RecordFailure(ctx context.Context, ip string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(b.lastSweep) > sweepInterval {
+		b.sweep(now)
+	}
+	entry := b.entry(ctx, ip, now)
+	if entry == nil {
+		entry = &ipFailures{}
+		b.entries[backoffKey(ctx, ip)] = entry
+	}
+	entry.count++
+	entry.lastFailure = now
+	b.failures++
+
+	if entry.count >= b.policy.Threshold {
+		entry.blockedUntil = now.Add(b.delay(entry.count))
+		b.blocks++
+	}
+	return nil
+}
+
+// delay is BaseDelay doubled for every failure past the threshold, capped at MaxDelay
+func (b *MemoryIPBackoff) delay(failures int) time.Duration {
+	delay := b.policy.BaseDelay
+	for range failures - b.policy.Threshold {
+		delay *= 2
+		if delay >= b.policy.MaxDelay {
+			return b.policy.MaxDelay
+		}
+	}
+	return min(delay, b.policy.MaxDelay)
+}
+
+func (b *MemoryIPBackoff) ListBlocks(ctx context.Context) ([]ports.IPBlock, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	blocks := []ports.IPBlock{}
+	b.forTenant(ctx, now, func(ip string, entry *ipFailures) {
+		if entry.blockedUntil.After(now) {
+			blocks = append(blocks, ports.IPBlock{
+				IP:            ip,
+				Failures:      entry.count,
+				LastFailureAt: entry.lastFailure,
+				BlockedUntil:  entry.blockedUntil,
+			})
+		}
+	})
+	slices.SortFunc(blocks, func(a, b ports.IPBlock) int { return b.BlockedUntil.Compare(a.BlockedUntil) })
+	return blocks, nil
+}
+
+func (b *MemoryIPBackoff) ClearBlock(ctx context.Context, ip string) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := backoffKey(ctx, ip)
+	_, found := b.entries[key]
+	delete(b.entries, key)
+	return found, nil
+}
+
+func (b *MemoryIPBackoff) Stats(ctx context.Context) (*ports.IPBackoffStats, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	stats := &ports.IPBackoffStats{
+		Failures:         b.failures,
+		RejectedRequests: b.rejected,
+		BlocksIssued:     b.blocks,
+	}
+	b.forTenant(ctx, now, func(_ string, entry *ipFailures) {
+		stats.TrackedIPs++
+		if entry.blockedUntil.After(now) {
+			stats.ActiveBlocks++
+		}
+	})
+	return stats, nil
+}
+
+// entry returns the live entry of the IP, dropping it once its failures have expired
+func (b *MemoryIPBackoff) entry(ctx context.Context, ip string, now time.Time) *ipFailures {
+	key := backoffKey(ctx, ip)
+	entry, ok := b.entries[key]
+	if !ok {
+		return nil
+	}
+	if b.expired(entry, now) {
+		delete(b.entries, key)
+		return nil
+	}
+	return entry
+}
+
+// forTenant calls fn for every live entry of the request tenant and drops expired entries
+func (b *MemoryIPBackoff) forTenant(ctx context.Context, now time.Time, fn func(ip string, entry *ipFailures)) {
+	prefix := domain.TenantFromContext(ctx) + "|"
+	for key, entry := range b.entries {
+		if b.expired(entry, now) {
+			delete(b.entries, key)
+			continue
+		}
+		if ip, found := strings.CutPrefix(key, prefix); found {
+			fn(ip, entry)
+		}
+	}
+}
+
+// sweep drops the expired entries of every tenant
+func (b *MemoryIPBackoff) sweep(now time.Time) {
+	for key, entry := range b.entries {
+		if b.expired(entry, now) {
+			delete(b.entries, key)
+		}
+	}
+	b.lastSweep = now
+}
+
+func (b *MemoryIPBackoff) expired(entry *ipFailures, now time.Time) bool {
+	return now.After(entry.blockedUntil) && now.Sub(entry.lastFailure) > b.policy.ResetAfter
+}
+
+func backoffKey(ctx context.Context, ip string) string {
+	return domain.TenantFromContext(ctx) + "|" + ip
+}
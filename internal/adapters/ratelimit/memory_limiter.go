@@ -0,0 +1,76 @@
+// Package ratelimit provides rate limiter adapters used to throttle HTTP requests.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.RateLimiter = (*MemoryRateLimiter)(nil)
+
+// sweepInterval is how often keys without recent requests are dropped
+const sweepInterval = time.Minute
+
+// MemoryRateLimiter is a sliding-window rate limiter keeping request times in process memory.
+// Limits are enforced per instance, so it only suits single-instance deployments.
+type MemoryRateLimiter struct {
+	mu        sync.Mutex
+	requests  map[string][]time.Time
+	maxWindow time.Duration
+	lastSweep time.Time
+}
+
+func NewMemoryRateLimiter() *MemoryRateLimiter {
+	return &MemoryRateLimiter{
+		requests:  make(map[string][]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (l *MemoryRateLimiter) Allow(ctx context.Context, key string, limit ports.RateLimit) (*ports.RateLimitResult, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.maxWindow = max(l.maxWindow, limit.Window)
+	if now.Sub(l.lastSweep) > sweepInterval {
+		l.sweep(now)
+	}
+
+	// Keep only the requests inside the window
+	windowStart := now.Add(-limit.Window)
+	times := l.requests[key]
+	kept := times[:0]
+	for _, t := range times {
+		if t.After(windowStart) {
+			kept = append(kept, t)
+		}
+	}
+
+	if len(kept) >= limit.Requests {
+		l.requests[key] = kept
+		return &ports.RateLimitResult{
+			Allowed:    false,
+			RetryAfter: kept[len(kept)-limit.Requests].Add(limit.Window).Sub(now),
+		}, nil
+	}
+
+	l.requests[key] = append(kept, now)
+	return &ports.RateLimitResult{
+		Allowed:   true,
+		Remaining: limit.Requests - len(kept) - 1,
+	}, nil
+}
+
+// sweep drops the keys whose latest request is older than the largest window in use
+func (l *MemoryRateLimiter) sweep(now time.Time) {
+	for key, times := range l.requests {
+		if len(times) == 0 || now.Sub(times[len(times)-1]) > l.maxWindow {
+			delete(l.requests, key)
+		}
+	}
+	l.lastSweep = now
+}
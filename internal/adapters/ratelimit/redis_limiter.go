@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/redis"
+)
+
+var (
+	_ ports.RateLimiter   = (*RedisRateLimiter)(nil)
+	_ ports.HealthChecker = (*RedisRateLimiter)(nil)
+)
+
+// slidingWindowScript keeps one sorted-set member per request scored by its time in milliseconds.
+// It uses the Redis clock so every API instance shares the same window.
+// Returns {allowed, remaining, retry_after_ms}.
+const slidingWindowScript = `
+local key = KEYS[1]
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local member = ARGV[3]
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+local count = redis.call('ZCARD', key)
+if count < limit then
+  redis.call('ZADD', key, now, member)
+  redis.call('PEXPIRE', key, window)
+  return {1, limit - count - 1, 0}
+end
+
+local oldest = redis.call('ZRANGE', key, count - limit, count - limit, 'WITHSCORES')
+return {0, 0, tonumber(oldest[2]) + window - now}
+`
+
+// RedisRateLimiter is a sliding-window rate limiter storing request times in Redis,
+// so limits are shared by every instance of the API
+type RedisRateLimiter struct {
+	client    *redis.Client
+	scriptSHA string
+}
+
+func NewRedisRateLimiter(client *redis.Client) *RedisRateLimiter {
+	sum := sha1.Sum([]byte(slidingWindowScript))
+	return &RedisRateLimiter{
+		client:    client,
+		scriptSHA: hex.EncodeToString(sum[:]),
+	}
+}
+
+func (l *RedisRateLimiter) Allow(ctx context.Context, key string, limit ports.RateLimit) (*ports.RateLimitResult, error) {
+	member := make([]byte, 8)
+	if _, err := rand.Read(member); err != nil {
+		return nil, err
+	}
+	args := []any{1, key, limit.Window.Milliseconds(), limit.Requests, hex.EncodeToString(member)}
+
+	// Run the cached script, loading it on the first call on each Redis server
+	reply, err := l.client.Do(ctx, append([]any{"EVALSHA", l.scriptSHA}, args...)...)
+	if err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT") {
+		reply, err = l.client.Do(ctx, append([]any{"EVAL", slidingWindowScript}, args...)...)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := reply.([]any)
+	if !ok || len(values) != 3 {
+		return nil, fmt.Errorf("unexpected rate limit script reply %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	remaining, _ := values[1].(int64)
+	retryAfter, _ := values[2].(int64)
+
+	return &ports.RateLimitResult{
+		Allowed:    allowed == 1,
+		Remaining:  int(remaining),
+		RetryAfter: time.Duration(retryAfter) * time.Millisecond,
+	}, nil
+}
+
+// CheckHealth pings Redis and reports the version of the server
+func (l *RedisRateLimiter) CheckHealth(ctx context.Context) ports.DependencyHealth {
+	start := time.Now()
+	if err := l.client.Ping(ctx); err != nil {
+		return ports.DependencyHealth{Status: ports.HealthDown, Error: err.Error()}
+	}
+	health := ports.DependencyHealth{Status: ports.HealthUp, LatencyMS: float64(time.Since(start).Microseconds()) / 1000}
+
+	if reply, err := l.client.Do(ctx, "INFO", "server"); err == nil {
+		info, _ := reply.(string)
+		for _, line := range strings.Split(info, "\n") {
+			if version, ok := strings.CutPrefix(strings.TrimSpace(line), "redis_version:"); ok {
+				health.Version = version
+			}
+		}
+	}
+	return health
+}
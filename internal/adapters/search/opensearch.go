@@ -0,0 +1,296 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.SearchIndex = (*OpenSearchIndex)(nil)
+
+// requestTimeout bounds each call to the cluster, searches fall back to the database past it
+const requestTimeout = 5 * time.Second
+
+// keywordText is a text field also indexed whole, to sort by it
+var keywordText = map[string]any{"type": "text", "fields": map[string]any{"keyword": map[string]any{"type": "keyword"}}}
+
+// userMapping is the mapping of the user documents: the searched names are text, the filters and
+// facets keywords
+var userMapping = map[string]any{
+	"dynamic": "strict",
+	"properties": map[string]any{
+		"id":         map[string]any{"type": "keyword"},
+		"tenant_id":  map[string]any{"type": "keyword"},
+		"email":      keywordText,
+		"username":   keywordText,
+		"first_name": keywordText,
+		"last_name":  keywordText,
+		"country":    map[string]any{"type": "keyword"},
+		"status":     map[string]any{"type": "keyword"},
+		"roles":      map[string]any{"type": "keyword"},
+		"tags":       map[string]any{"type": "keyword"},
+		"created_at": map[string]any{"type": "date"},
+		"updated_at": map[string]any{"type": "date"},
+	},
+}
+
+// sortFields maps the sort fields of the user lists to those of the documents
+var sortFields = map[string]string{
+	"email":      "email.keyword",
+	"first_name": "first_name.keyword",
+	"last_name":  "last_name.keyword",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// facetFields maps the facets to the fields of the documents, mirroring the facets counted by the
+// user repository
+var facetFields = map[string]string{
+	domain.UserFacetCountry: "country",
+	domain.UserFacetStatus:  "status",
+	domain.UserFacetRole:    "roles",
+	domain.UserFacetTag:     "tags",
+}
+
+// userDocument is the document of a user, holding what the indexed lists search, filter, sort and
+// count by
+type userDocument struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username,omitempty"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Country   string    `json:"country"`
+	Status    string    `json:"status"`
+	Roles     []string  `json:"roles"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OpenSearchIndex indexes the users of every tenant in a single index of an OpenSearch cluster, or of
+// an Elasticsearch 7+ cluster, which has the same document and search APIs. The index is created with
+// its mapping before the first user is indexed.
+type OpenSearchIndex struct {
+	baseURL  string
+	index    string
+	username string
+	password string
+	client   *http.Client
+
+	created atomic.Bool
+}
+
+// NewOpenSearchIndex returns an index named index of the cluster at baseURL, authenticating with
+// basic auth when username is set
+func NewOpenSearchIndex(baseURL, index, username, password string) *OpenSearchIndex {
+	return &OpenSearchIndex{
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		index:    url.PathEscape(index),
+		username: username,
+		password: password,
+		client:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (s *OpenSearchIndex) SaveUser(ctx context.Context, user *domain.User) error {
+	if err := s.createIndex(ctx); err != nil {
+		return err
+	}
+	document := userDocument{
+		ID:        user.ID,
+		TenantID:  user.TenantID,
+		Email:     user.Email,
+		Username:  user.Username,
+		FirstName: user.Profile.FirstName,
+		LastName:  user.Profile.LastName,
+		Status:    user.Status(),
+		Roles:     user.EffectiveRoles(),
+		Tags:      user.Tags,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+	if address := user.Profile.PrimaryAddress(); address != nil {
+		document.Country = address.Country
+	}
+	return s.do(ctx, http.MethodPut, "/"+s.index+"/_doc/"+url.PathEscape(user.ID), document, nil)
+}
+
+func (s *OpenSearchIndex) RemoveUser(ctx context.Context, id string) error {
+	err := s.do(ctx, http.MethodDelete, "/"+s.index+"/_doc/"+url.PathEscape(id), nil, nil)
+	if httpErr, ok := err.(*statusError); ok && httpErr.status == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+func (s *OpenSearchIndex) SearchUsers(ctx context.Context, query ports.UserSearchQuery) (*ports.UserSearchResult, error) {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return nil, domain.ErrMissingTenant
+	}
+
+	filters := []any{map[string]any{"term": map[string]any{"tenant_id": tenantID}}}
+	for _, tag := range query.Tags {
+		filters = append(filters, map[string]any{"term": map[string]any{"tags": tag}})
+	}
+	order := "asc"
+	if query.Order == "desc" {
+		order = "desc"
+	}
+	sortField, ok := sortFields[query.SortBy]
+	if !ok {
+		sortField = "created_at"
+	}
+	body := map[string]any{
+		"from":             (query.Page - 1) * query.PageSize,
+		"size":             query.PageSize,
+		"track_total_hits": true,
+		"_source":          false,
+		"query": map[string]any{"bool": map[string]any{
+			"filter": filters,
+			// Every word of the text starts a word of one of the fields
+			"must": map[string]any{"multi_match": map[string]any{
+				"query":    query.Text,
+				"type":     "bool_prefix",
+				"operator": "and",
+				"fields":   []string{"email", "username", "first_name", "last_name"},
+			}},
+		}},
+		"sort": []any{map[string]any{sortField: order}, map[string]any{"id": "asc"}},
+	}
+	if len(query.Facets) > 0 {
+		aggregations := make(map[string]any, len(query.Facets))
+		for _, facet := range query.Facets {
+			aggregations[facet] = map[string]any{"terms": map[string]any{
+				"field": facetFields[facet],
+				"size":  domain.MaxFacetValues,
+				"order": []any{map[string]any{"_count": "desc"}, map[string]any{"_key": "asc"}},
+			}}
+		}
+		body["aggs"] = aggregations
+	}
+
+	var response struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+		Aggregations map[string]struct {
+			Buckets []struct {
+				Key      string `json:"key"`
+				DocCount int64  `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"aggregations"`
+	}
+	if err := s.do(ctx, http.MethodPost, "/"+s.index+"/_search", body, &response); err != nil {
+		return nil, err
+	}
+
+	result := &ports.UserSearchResult{
+		IDs:        make([]string, 0, len(response.Hits.Hits)),
+		TotalCount: response.Hits.Total.Value,
+	}
+	for _, hit := range response.Hits.Hits {
+		result.IDs = append(result.IDs, hit.ID)
+	}
+	if len(query.Facets) > 0 {
+		result.Facets = make(map[string][]ports.FacetCount, len(query.Facets))
+		for _, facet := range query.Facets {
+			counts := []ports.FacetCount{}
+			for _, bucket := range response.Aggregations[facet].Buckets {
+				counts = append(counts, ports.FacetCount{Value: bucket.Key, Count: bucket.DocCount})
+			}
+			result.Facets[facet] = counts
+		}
+	}
+	return result, nil
+}
+
+// createIndex creates the index with its mapping once, unless it already exists
+func (s *OpenSearchIndex) createIndex(ctx context.Context) error {
+	if s.created.Load() {
+		return nil
+	}
+	err := s.do(ctx, http.MethodPut, "/"+s.index, map[string]any{"mappings": userMapping}, nil)
+	if httpErr, ok := err.(*statusError); ok && httpErr.errorType == "resource_already_exists_exception" {
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+	s.created.Store(true)
+	return nil
+}
+
+// statusError is an error response of the cluster
+type statusError struct {
+	method, endpoint string
+	status           int
+	errorType        string
+	reason           string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("opensearch: %s %s: HTTP %d %s %s", e.method, e.endpoint, e.status, e.errorType, e.reason)
+}
+
+// do calls the cluster with the JSON of body, if any, and decodes its JSON response into result
+func (s *OpenSearchIndex) do(ctx context.Context, method, endpoint string, body, result any) error {
+	var encoded []byte
+	if body != nil {
+		var err error
+		if encoded, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.baseURL+endpoint, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiError struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiError)
+		return &statusError{method: method, endpoint: endpoint, status: resp.StatusCode, errorType: apiError.Error.Type, reason: apiError.Error.Reason}
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("opensearch: invalid response to %s %s: %w", method, endpoint, err)
+	}
+	return nil
+}
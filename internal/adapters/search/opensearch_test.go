@@ -0,0 +1,230 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// cluster records the requests of an OpenSearchIndex and answers them with respond
+type cluster struct {
+	mu       sync.Mutex
+	requests []clusterRequest
+	respond  func(r clusterRequest) (int, string)
+}
+
+type clusterRequest struct {
+	Method, Path string
+	Body         map[string]any
+	User, Pass   string
+}
+
+func newCluster(t *testing.T, respond func(r clusterRequest) (int, string)) (*cluster, *httptest.Server) {
+	t.Helper()
+	c := &cluster{respond: respond}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := clusterRequest{Method: r.Method, Path: r.URL.EscapedPath()}
+		req.User, req.Pass, _ = r.BasicAuth()
+		if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+			if err := json.Unmarshal(data, &req.Body); err != nil {
+				t.Errorf("%s %s: %v", r.Method, r.URL, err)
+			}
+		}
+		c.mu.Lock()
+		c.requests = append(c.requests, req)
+		c.mu.Unlock()
+		status, body := http.StatusOK, `{}`
+		if c.respond != nil {
+			status, body = c.respond(req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return c, srv
+}
+
+func TestSaveUserCreatesTheIndexOnce(t *testing.T) {
+	c, srv := newCluster(t, nil)
+	index := NewOpenSearchIndex(srv.URL+"/", "users v1", "elastic", "secret")
+	created := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	user := &domain.User{
+		ID: "u1", TenantID: "acme", Email: "ada@example.com", Username: "ada",
+		Profile: domain.Profile{FirstName: "Ada", LastName: "Lovelace", Addresses: []domain.Address{
+			{Country: "FR"}, {Country: "GB", Primary: true},
+		}},
+		Tags:      []string{"vip"},
+		CreatedAt: created, UpdatedAt: created,
+	}
+	for range 2 {
+		if err := index.SaveUser(context.Background(), user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(c.requests) != 3 {
+		t.Fatalf("requests = %+v, want the index then the document twice", c.requests)
+	}
+	create := c.requests[0]
+	if create.Method != http.MethodPut || create.Path != "/users%20v1" || create.Body["mappings"].(map[string]any)["dynamic"] != "strict" {
+		t.Errorf("index creation = %+v", create)
+	}
+	document := c.requests[1]
+	want := map[string]any{
+		"id": "u1", "tenant_id": "acme", "email": "ada@example.com", "username": "ada",
+		"first_name": "Ada", "last_name": "Lovelace", "country": "GB", "status": domain.UserStatusActive,
+		"roles": []any{domain.RoleUser}, "tags": []any{"vip"},
+		"created_at": "2024-03-01T12:00:00Z", "updated_at": "2024-03-01T12:00:00Z",
+	}
+	if document.Method != http.MethodPut || document.Path != "/users%20v1/_doc/u1" || !reflect.DeepEqual(document.Body, want) {
+		t.Errorf("document = %+v, want %v", document, want)
+	}
+	if document.User != "elastic" || document.Pass != "secret" {
+		t.Errorf("basic auth = %s:%s", document.User, document.Pass)
+	}
+	// Every field of the documents is mapped, as the mapping is strict
+	properties := create.Body["mappings"].(map[string]any)["properties"].(map[string]any)
+	for field := range want {
+		if _, ok := properties[field]; !ok {
+			t.Errorf("field %s is not mapped", field)
+		}
+	}
+}
+
+func TestSaveUserWithExistingIndex(t *testing.T) {
+	_, srv := newCluster(t, func(r clusterRequest) (int, string) {
+		if r.Path == "/users" {
+			return http.StatusBadRequest, `{"error":{"type":"resource_already_exists_exception","reason":"index [users] already exists"}}`
+		}
+		return http.StatusCreated, `{"result":"created"}`
+	})
+	if err := NewOpenSearchIndex(srv.URL, "users", "", "").SaveUser(context.Background(), &domain.User{ID: "u1"}); err != nil {
+		t.Errorf("SaveUser = %v, want the existing index used", err)
+	}
+}
+
+func TestRemoveUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr bool
+	}{
+		{name: "removed", status: http.StatusOK, body: `{"result":"deleted"}`},
+		{name: "never indexed", status: http.StatusNotFound, body: `{"result":"not_found"}`},
+		{name: "cluster error", status: http.StatusServiceUnavailable, body: `{"error":{"type":"cluster_block_exception","reason":"blocked"}}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, srv := newCluster(t, func(clusterRequest) (int, string) { return tt.status, tt.body })
+			err := NewOpenSearchIndex(srv.URL, "users", "", "").RemoveUser(context.Background(), "u1")
+			var statusErr *statusError
+			if tt.wantErr != (err != nil) || tt.wantErr && (!errors.As(err, &statusErr) || statusErr.errorType != "cluster_block_exception") {
+				t.Errorf("err = %v, want error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSearchUsers(t *testing.T) {
+	c, srv := newCluster(t, func(clusterRequest) (int, string) {
+		return http.StatusOK, `{
+			"hits": {"total": {"value": 42}, "hits": [{"_id": "u2"}, {"_id": "u1"}]},
+			"aggregations": {"country": {"buckets": [{"key": "GB", "doc_count": 30}, {"key": "FR", "doc_count": 12}]}}
+		}`
+	})
+	index := NewOpenSearchIndex(srv.URL, "users", "", "")
+	ctx := domain.WithTenant(context.Background(), "acme")
+	result, err := index.SearchUsers(ctx, ports.UserSearchQuery{
+		Text: "ada love", Tags: []string{"vip"}, SortBy: "last_name", Order: "desc", Page: 3, PageSize: 20,
+		Facets: []string{domain.UserFacetCountry, domain.UserFacetTag},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := &ports.UserSearchResult{
+		IDs:        []string{"u2", "u1"},
+		TotalCount: 42,
+		Facets: map[string][]ports.FacetCount{
+			domain.UserFacetCountry: {{Value: "GB", Count: 30}, {Value: "FR", Count: 12}},
+			domain.UserFacetTag:     {},
+		},
+	}
+	if !reflect.DeepEqual(result, want) {
+		t.Errorf("result = %+v, want %+v", result, want)
+	}
+
+	req := c.requests[0]
+	if req.Method != http.MethodPost || req.Path != "/users/_search" {
+		t.Fatalf("request = %s %s", req.Method, req.Path)
+	}
+	body := req.Body
+	if body["from"] != 40.0 || body["size"] != 20.0 || body["track_total_hits"] != true {
+		t.Errorf("paging = %v", body)
+	}
+	query := body["query"].(map[string]any)["bool"].(map[string]any)
+	filters := []any{
+		map[string]any{"term": map[string]any{"tenant_id": "acme"}},
+		map[string]any{"term": map[string]any{"tags": "vip"}},
+	}
+	if !reflect.DeepEqual(query["filter"], filters) {
+		t.Errorf("filters = %v, want %v", query["filter"], filters)
+	}
+	if match := query["must"].(map[string]any)["multi_match"].(map[string]any); match["query"] != "ada love" || match["type"] != "bool_prefix" || match["operator"] != "and" {
+		t.Errorf("text query = %v", match)
+	}
+	sort := []any{map[string]any{"last_name.keyword": "desc"}, map[string]any{"id": "asc"}}
+	if !reflect.DeepEqual(body["sort"], sort) {
+		t.Errorf("sort = %v, want %v", body["sort"], sort)
+	}
+	aggregations := body["aggs"].(map[string]any)
+	if terms := aggregations[domain.UserFacetTag].(map[string]any)["terms"].(map[string]any); terms["field"] != "tags" || terms["size"] != float64(domain.MaxFacetValues) {
+		t.Errorf("tag facet = %v", terms)
+	}
+}
+
+func TestSearchUsersDefaultSort(t *testing.T) {
+	c, srv := newCluster(t, func(clusterRequest) (int, string) {
+		return http.StatusOK, `{"hits": {"total": {"value": 0}, "hits": []}}`
+	})
+	index := NewOpenSearchIndex(srv.URL, "users", "", "")
+	ctx := domain.WithTenant(context.Background(), "acme")
+	if _, err := index.SearchUsers(ctx, ports.UserSearchQuery{Text: "ada", SortBy: "password_hash", Order: "sideways", Page: 1, PageSize: 10}); err != nil {
+		t.Fatal(err)
+	}
+	body := c.requests[0].Body
+	if sort := []any{map[string]any{"created_at": "asc"}, map[string]any{"id": "asc"}}; !reflect.DeepEqual(body["sort"], sort) {
+		t.Errorf("sort = %v, want %v", body["sort"], sort)
+	}
+	if _, ok := body["aggs"]; ok {
+		t.Errorf("aggregations requested without facets: %v", body["aggs"])
+	}
+}
+
+func TestSearchUsersRequiresATenant(t *testing.T) {
+	c, srv := newCluster(t, nil)
+	_, err := NewOpenSearchIndex(srv.URL, "users", "", "").SearchUsers(context.Background(), ports.UserSearchQuery{Text: "ada", Page: 1, PageSize: 10})
+	if !errors.Is(err, domain.ErrMissingTenant) || len(c.requests) != 0 {
+		t.Errorf("err = %v after %d requests, want ErrMissingTenant before any", err, len(c.requests))
+	}
+}
+
+func TestSearchUsersInvalidResponse(t *testing.T) {
+	_, srv := newCluster(t, func(clusterRequest) (int, string) { return http.StatusOK, `<html>` })
+	ctx := domain.WithTenant(context.Background(), "acme")
+	if _, err := NewOpenSearchIndex(srv.URL, "users", "", "").SearchUsers(ctx, ports.UserSearchQuery{Text: "ada", Page: 1, PageSize: 10}); err == nil {
+		t.Error("SearchUsers succeeded on an invalid response")
+	}
+}
@@ -0,0 +1,39 @@
+// Package siem provides AuditSink adapters shipping the audit events to syslog, a Splunk HTTP Event
+// Collector or S3 batches, for SIEMs to collect.
+package siem
+
+import (
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// requestTimeout bounds each call to a sink, the batch is retried past it
+const requestTimeout = 10 * time.Second
+
+// auditRecord is the exported JSON of an audit event which, unlike the one of the API, holds its tenant
+type auditRecord struct {
+	ID        string            `json:"id"`
+	TenantID  string            `json:"tenant_id,omitempty"`
+	Action    string            `json:"action"`
+	ActorID   string            `json:"actor_id,omitempty"`
+	TargetID  string            `json:"target_id,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+	TokenID   string            `json:"token_id,omitempty"`
+	IP        string            `json:"ip,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+func newAuditRecord(event *domain.AuditEvent) auditRecord {
+	return auditRecord{
+		ID:        event.ID,
+		TenantID:  event.TenantID,
+		Action:    event.Action,
+		ActorID:   event.ActorID,
+		TargetID:  event.TargetID,
+		Details:   event.Details,
+		TokenID:   event.TokenID,
+		IP:        event.IP,
+		CreatedAt: event.CreatedAt.UTC(),
+	}
+}
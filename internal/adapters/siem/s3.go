@@ -0,0 +1,188 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.AuditSink = (*S3Sink)(nil)
+
+// S3Credentials are the AWS credentials the requests are signed with; SessionToken is only set for
+// temporary credentials
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// S3Sink writes each batch of audit events as an NDJSON object, one event per line, under
+// <prefix>YYYY/MM/DD/ of the day of its first event. The key derives from the first event, so a batch
+// written again replaces its object instead of duplicating it. Requests are path-style and signed
+// with AWS Signature Version 4, which S3-compatible stores such as MinIO also accept.
+type S3Sink struct {
+	endpoint    string
+	bucket      string
+	region      string
+	prefix      string
+	credentials S3Credentials
+	client      *http.Client
+}
+
+// NewS3Sink returns a sink writing to bucket of region through endpoint, or the AWS endpoint of the
+// region when it is empty, with the object keys starting with prefix
+func NewS3Sink(endpoint, bucket, region, prefix string, credentials S3Credentials) *S3Sink {
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &S3Sink{
+		endpoint:    strings.TrimRight(endpoint, "/"),
+		bucket:      bucket,
+		region:      region,
+		prefix:      prefix,
+		credentials: credentials,
+		client:      &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (s *S3Sink) WriteEvents(ctx context.Context, events []*domain.AuditEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range events {
+		if err := encoder.Encode(newAuditRecord(event)); err != nil {
+			return err
+		}
+	}
+
+	first := events[0]
+	key := s.prefix + first.CreatedAt.UTC().Format("2006/01/02/150405") + "-" + first.ID + ".ndjson"
+	objectURL, err := url.Parse(s.endpoint + "/" + uriEncode(s.bucket) + "/" + uriEncodePath(key))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	s.sign(req, body.Bytes(), time.Now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiError struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		xml.NewDecoder(resp.Body).Decode(&apiError)
+		return &statusError{sink: "s3", status: resp.StatusCode, reason: strings.TrimSpace(apiError.Code + " " + apiError.Message)}
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// sign adds the AWS Signature Version 4 of the request, with its body, to its headers
+func (s *S3Sink) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.credentials.SecretAccessKey), date)
+	for _, part := range []string{s.region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.credentials.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncodePath encodes every segment of the slash-separated path as S3 signs it
+func uriEncodePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// uriEncode percent-encodes every byte of value but the unreserved characters of RFC 3986
+func uriEncode(value string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			encoded.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&encoded, "%%%02X", c)
+	}
+	return encoded.String()
+}
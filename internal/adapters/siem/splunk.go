@@ -0,0 +1,109 @@
+package siem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.AuditSink = (*SplunkHECSink)(nil)
+
+// SplunkHECSink sends each batch of audit events in a single request to a Splunk HTTP Event Collector
+type SplunkHECSink struct {
+	endpoint   string
+	token      string
+	index      string
+	sourcetype string
+	source     string
+	host       string
+	client     *http.Client
+}
+
+// NewSplunkHECSink returns a sink sending to the collector at baseURL, such as https://splunk:8088,
+// authenticated by token. The events go to index, or the default index of the token when it is empty,
+// with sourcetype, or _json when it is empty, and source.
+func NewSplunkHECSink(baseURL, token, index, sourcetype, source string) *SplunkHECSink {
+	if sourcetype == "" {
+		sourcetype = "_json"
+	}
+	host, _ := os.Hostname()
+	return &SplunkHECSink{
+		endpoint:   strings.TrimRight(baseURL, "/") + "/services/collector/event",
+		token:      token,
+		index:      index,
+		sourcetype: sourcetype,
+		source:     source,
+		host:       host,
+		client:     &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// hecEvent is an event of the collector; a batch is the concatenation of its events
+type hecEvent struct {
+	Time       float64     `json:"time"`
+	Host       string      `json:"host,omitempty"`
+	Source     string      `json:"source,omitempty"`
+	Sourcetype string      `json:"sourcetype"`
+	Index      string      `json:"index,omitempty"`
+	Event      auditRecord `json:"event"`
+}
+
+func (s *SplunkHECSink) WriteEvents(ctx context.Context, events []*domain.AuditEvent) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, event := range events {
+		if err := encoder.Encode(hecEvent{
+			Time:       float64(event.CreatedAt.UnixMilli()) / 1000,
+			Host:       s.host,
+			Source:     s.source,
+			Sourcetype: s.sourcetype,
+			Index:      s.index,
+			Event:      newAuditRecord(event),
+		}); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Splunk "+s.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiError struct {
+			Text string `json:"text"`
+			Code int    `json:"code"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiError)
+		return &statusError{sink: "splunk", status: resp.StatusCode, reason: fmt.Sprintf("%s (code %d)", apiError.Text, apiError.Code)}
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// statusError is an error response of a sink
+type statusError struct {
+	sink   string
+	status int
+	reason string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("%s: HTTP %d %s", e.sink, e.status, e.reason)
+}
@@ -0,0 +1,72 @@
+package siem
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+func TestSplunkHECSinkWriteEvents(t *testing.T) {
+	var gotPath, gotAuthorization string
+	var events []map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuthorization = r.URL.Path, r.Header.Get("Authorization")
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var event map[string]any
+			if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+				t.Error(err)
+			}
+			events = append(events, event)
+		}
+		io.WriteString(w, `{"text":"Success","code":0}`)
+	}))
+	defer srv.Close()
+
+	sink := NewSplunkHECSink(srv.URL+"/", "token", "audit", "", "user-api")
+	err := sink.WriteEvents(context.Background(), []*domain.AuditEvent{
+		{ID: "e1", TenantID: "acme", Action: "user.deleted", CreatedAt: time.UnixMilli(1709296205123)},
+		{ID: "e2", Action: "user.updated", CreatedAt: time.UnixMilli(1709296206000)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if gotPath != "/services/collector/event" || gotAuthorization != "Splunk token" {
+		t.Errorf("path = %s, Authorization = %s", gotPath, gotAuthorization)
+	}
+	if len(events) != 2 {
+		t.Fatalf("events = %d, want 2", len(events))
+	}
+	// Epoch seconds with milliseconds, the default sourcetype of JSON events
+	first := events[0]
+	if first["time"] != 1709296205.123 || first["sourcetype"] != "_json" || first["index"] != "audit" || first["source"] != "user-api" {
+		t.Errorf("first event = %v", first)
+	}
+	if record := first["event"].(map[string]any); record["id"] != "e1" || record["tenant_id"] != "acme" || record["created_at"] != "2024-03-01T12:30:05.123Z" {
+		t.Errorf("first record = %v", record)
+	}
+}
+
+func TestSplunkHECSinkError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, `{"text":"Invalid token","code":4}`)
+	}))
+	defer srv.Close()
+
+	sink := NewSplunkHECSink(srv.URL, "wrong", "", "", "")
+	err := sink.WriteEvents(context.Background(), []*domain.AuditEvent{{ID: "e1", Action: "user.deleted", CreatedAt: time.Now()}})
+	var statusErr *statusError
+	if !errors.As(err, &statusErr) || statusErr.status != http.StatusForbidden || statusErr.reason != "Invalid token (code 4)" {
+		t.Errorf("err = %v, want the collector error", err)
+	}
+}
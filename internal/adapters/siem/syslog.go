@@ -0,0 +1,138 @@
+package siem
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.AuditSink = (*SyslogSink)(nil)
+
+// syslogTimestamp is the timestamp of RFC 5424, which allows up to microseconds
+const syslogTimestamp = "2006-01-02T15:04:05.000000Z07:00"
+
+// syslogPriority is the priority of the messages: facility authpriv (10), severity informational (6)
+const syslogPriority = 10*8 + 6
+
+// SyslogSink writes each audit event as an RFC 5424 message whose content is the JSON of the event.
+// Over UDP every message is a datagram, over TCP and TLS it is framed by octet counting (RFC 6587).
+// The connection is kept between batches and dialed again after a failed write.
+type SyslogSink struct {
+	network  string
+	addr     string
+	appName  string
+	hostname string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink returns a sink writing to the syslog server at addr over network, one of udp, tcp or
+// tls, with the messages of appName
+func NewSyslogSink(network, addr, appName string) (*SyslogSink, error) {
+	switch network {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("syslog: unsupported network %q: must be udp, tcp or tls", network)
+	}
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "-"
+	}
+	return &SyslogSink{
+		network:  network,
+		addr:     addr,
+		appName:  appName,
+		hostname: hostname,
+	}, nil
+}
+
+func (s *SyslogSink) WriteEvents(ctx context.Context, events []*domain.AuditEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(requestTimeout)
+	}
+	s.conn.SetDeadline(deadline)
+
+	for _, event := range events {
+		message, err := s.message(event)
+		if err != nil {
+			return err
+		}
+		if s.network != "udp" {
+			message = append([]byte(strconv.Itoa(len(message))+" "), message...)
+		}
+		if _, err := s.conn.Write(message); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return fmt.Errorf("syslog: writing to %s: %w", s.addr, err)
+		}
+	}
+	return nil
+}
+
+func (s *SyslogSink) dial(ctx context.Context) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: requestTimeout}
+	if s.network == "tls" {
+		host, _, _ := net.SplitHostPort(s.addr)
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host, MinVersion: tls.VersionTLS12}}
+		return tlsDialer.DialContext(ctx, "tcp", s.addr)
+	}
+	return dialer.DialContext(ctx, s.network, s.addr)
+}
+
+// message returns the RFC 5424 message of the event, with the action as its message ID and no
+// structured data
+func (s *SyslogSink) message(event *domain.AuditEvent) ([]byte, error) {
+	content, err := json.Marshal(newAuditRecord(event))
+	if err != nil {
+		return nil, err
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s %d %s - ",
+		syslogPriority,
+		event.CreatedAt.UTC().Format(syslogTimestamp),
+		syslogField(s.hostname, 255),
+		syslogField(s.appName, 48),
+		os.Getpid(),
+		syslogField(event.Action, 32),
+	)
+	return append([]byte(header), content...), nil
+}
+
+// syslogField returns value as a header field of at most size printable ASCII characters, or the nil
+// value when it is empty
+func syslogField(value string, size int) string {
+	field := strings.Map(func(r rune) rune {
+		if r < '!' || r > '~' {
+			return -1
+		}
+		return r
+	}, value)
+	if len(field) > size {
+		field = field[:size]
+	}
+	if field == "" {
+		return "-"
+	}
+	return field
+}
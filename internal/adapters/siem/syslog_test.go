@@ -0,0 +1,154 @@
+package siem
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+var syslogEvent = &domain.AuditEvent{
+	ID: "e1", TenantID: "acme", Action: "user.deleted", ActorID: "admin1", TargetID: "u1",
+	CreatedAt: time.Date(2024, 3, 1, 12, 30, 5, 123456789, time.FixedZone("CET", 3600)),
+}
+
+func TestSyslogMessage(t *testing.T) {
+	sink, err := NewSyslogSink("udp", "127.0.0.1:514", "user api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.hostname = "api-1"
+
+	message, err := sink.message(syslogEvent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Priority of authpriv.info, RFC 5424 version 1, microseconds in UTC, the app name without its space
+	header := fmt.Sprintf("<86>1 2024-03-01T11:30:05.123456Z api-1 userapi %d user.deleted - ", os.Getpid())
+	content, ok := strings.CutPrefix(string(message), header)
+	if !ok {
+		t.Fatalf("message = %s, want the header %s", message, header)
+	}
+	var record auditRecord
+	if err := json.Unmarshal([]byte(content), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.ID != "e1" || record.TenantID != "acme" || record.TargetID != "u1" {
+		t.Errorf("record = %+v", record)
+	}
+}
+
+func TestSyslogField(t *testing.T) {
+	tests := []struct {
+		value string
+		size  int
+		want  string
+	}{
+		{"user.deleted", 32, "user.deleted"},
+		{"", 32, "-"},
+		{" \t\n", 32, "-"},
+		{"résumé app", 48, "rsumapp"},
+		{strings.Repeat("a", 40), 32, strings.Repeat("a", 32)},
+	}
+	for _, tt := range tests {
+		if got := syslogField(tt.value, tt.size); got != tt.want {
+			t.Errorf("syslogField(%q, %d) = %q, want %q", tt.value, tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestSyslogSinkTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	messages := make(chan string, 4)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			length, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+			if err != nil {
+				t.Errorf("frame length %q: %v", length, err)
+				return
+			}
+			message := make([]byte, n)
+			if _, err := io.ReadFull(r, message); err != nil {
+				t.Error(err)
+				return
+			}
+			messages <- string(message)
+		}
+	}()
+
+	sink, err := NewSyslogSink("tcp", ln.Addr().String(), "user-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := *syslogEvent
+	second.ID, second.Action = "e2", "user.updated"
+	if err := sink.WriteEvents(context.Background(), []*domain.AuditEvent{syslogEvent, &second}); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"e1", "e2"} {
+		select {
+		case message := <-messages:
+			if !strings.HasPrefix(message, "<86>1 ") || !strings.HasSuffix(message, `"created_at":"2024-03-01T11:30:05.123456789Z"}`) || !strings.Contains(message, `"id":"`+id+`"`) {
+				t.Errorf("message = %s", message)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("message not received")
+		}
+	}
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	sink, err := NewSyslogSink("udp", conn.LocalAddr().String(), "user-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.WriteEvents(context.Background(), []*domain.AuditEvent{syslogEvent}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Datagrams are not framed
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message := string(buf[:n]); !strings.HasPrefix(message, "<86>1 2024-03-01T11:30:05.123456Z ") {
+		t.Errorf("datagram = %s", message)
+	}
+}
+
+func TestNewSyslogSinkRejectsUnknownNetworks(t *testing.T) {
+	if _, err := NewSyslogSink("unix", "/dev/log", "user-api"); err == nil {
+		t.Error("NewSyslogSink(unix) succeeded")
+	}
+}
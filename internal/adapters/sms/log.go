@@ -0,0 +1,22 @@
+package sms
+
+import (
+	"context"
+	"log"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.SMSSender = (*LogSender)(nil)
+
+// LogSender writes text messages to the log instead of sending them, for development without an SMS provider
+type LogSender struct{}
+
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+func (s *LogSender) Send(_ context.Context, message ports.SMSMessage) error {
+	log.Printf("📱 SMS to %s: %s", message.To, message.Body)
+	return nil
+}
@@ -0,0 +1,38 @@
+package sms
+
+import (
+	"context"
+	"log"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.SMSSender = (*RateLimitedSender)(nil)
+
+// RateLimitedSender bounds the text messages sent to each phone number, whichever user or
+// feature sends them, to keep the SMS provider cost and the abuse of the codes in check
+type RateLimitedSender struct {
+	next    ports.SMSSender
+	limiter ports.RateLimiter
+	limit   ports.RateLimit
+}
+
+func NewRateLimitedSender(next ports.SMSSender, limiter ports.RateLimiter, limit ports.RateLimit) *RateLimitedSender {
+	return &RateLimitedSender{
+		next:    next,
+		limiter: limiter,
+		limit:   limit,
+	}
+}
+
+func (s *RateLimitedSender) Send(ctx context.Context, message ports.SMSMessage) error {
+	result, err := s.limiter.Allow(ctx, "ratelimit:sms:phone:"+message.To, s.limit)
+	if err != nil {
+		// Fail open like the request rate limits: an unavailable limiter must not block the codes
+		log.Printf("Rate limiter error for SMS: %v", err)
+	} else if !result.Allowed {
+		return domain.ErrSMSRateLimited
+	}
+	return s.next.Send(ctx, message)
+}
@@ -0,0 +1,73 @@
+// Package sms provides SMSSender adapters delivering text messages through Twilio or to the log.
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.SMSSender = (*TwilioSender)(nil)
+
+// TwilioAPIURL is the base URL of the Twilio REST API
+const TwilioAPIURL = "https://api.twilio.com/2010-04-01"
+
+// TwilioSender sends text messages with the Messages resource of the Twilio REST API, from a phone
+// number or the SID of a messaging service (MG...)
+type TwilioSender struct {
+	accountSID string
+	authToken  string
+	from       string
+	baseURL    string
+	client     *http.Client
+}
+
+func NewTwilioSender(accountSID, authToken, from, baseURL string) *TwilioSender {
+	return &TwilioSender{
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *TwilioSender) Send(ctx context.Context, message ports.SMSMessage) error {
+	form := url.Values{"To": {message.To}, "Body": {message.Body}}
+	if strings.HasPrefix(s.from, "MG") {
+		form.Set("MessagingServiceSid", s.from)
+	} else {
+		form.Set("From", s.from)
+	}
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", s.baseURL, url.PathEscape(s.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(s.accountSID, s.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiError struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&apiError)
+		return fmt.Errorf("twilio: HTTP %d: error %d %s", resp.StatusCode, apiError.Code, apiError.Message)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+// Package storage provides file storage adapters for binary content such as avatar images and identity documents.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.FileStorage = (*LocalFileStorage)(nil)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// LocalFileStorage stores files on the local disk and serves them under a public base URL
+type LocalFileStorage struct {
+	rootDir string
+	baseURL string
+}
+
+func NewLocalFileStorage(rootDir, baseURL string) *LocalFileStorage {
+	return &LocalFileStorage{
+		rootDir: rootDir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (s *LocalFileStorage) Save(ctx context.Context, key, contentType string, content io.Reader) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+
+	fullPath := filepath.Join(s.rootDir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return "", err
+	}
+
+	// Write to a temporary file first so readers never see partially written content
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), fullPath); err != nil {
+		return "", err
+	}
+
+	return s.baseURL + "/" + key, nil
+}
+
+// validKey reports whether key is a clean relative path, which cannot escape the root directory
+func validKey(key string) bool {
+	return key != "" && path.Clean("/" + key)[1:] == key
+}
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.PresignedFileStorage = (*SignedLocalFileStorage)(nil)
+
+// SignedLocalFileStorage stores private files on the local disk. It serves them itself, as an
+// http.Handler mounted under the base URL, and only answers the URLs signed by PresignURL until
+// they expire, so the root directory must not be served otherwise.
+type SignedLocalFileStorage struct {
+	*LocalFileStorage
+	secret []byte
+}
+
+func NewSignedLocalFileStorage(rootDir, baseURL string, secret []byte) *SignedLocalFileStorage {
+	return &SignedLocalFileStorage{
+		LocalFileStorage: NewLocalFileStorage(rootDir, baseURL),
+		secret:           secret,
+	}
+}
+
+func (s *SignedLocalFileStorage) PresignURL(ctx context.Context, key string, expiresAt time.Time) (string, error) {
+	if !validKey(key) {
+		return "", ErrInvalidKey
+	}
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+	query := url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}
+	return s.baseURL + "/" + key + "?" + query.Encode(), nil
+}
+
+func (s *SignedLocalFileStorage) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	if !validKey(key) {
+		return nil, ErrInvalidKey
+	}
+	return os.Open(filepath.Join(s.rootDir, filepath.FromSlash(key)))
+}
+
+// ServeHTTP serves the file of the request path, relative to the base URL, when the URL is signed and
+// not expired; the handler must be mounted with http.StripPrefix of the base URL
+func (s *SignedLocalFileStorage) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := path.Clean(r.URL.Path)[1:]
+	expires, signature := r.URL.Query().Get("expires"), r.URL.Query().Get("signature")
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if !validKey(key) || err != nil || time.Now().Unix() > expiresAt ||
+		!hmac.Equal([]byte(signature), []byte(s.sign(key, expires))) {
+		http.Error(w, "invalid or expired signature", http.StatusForbidden)
+		return
+	}
+
+	file, err := os.Open(filepath.Join(s.rootDir, filepath.FromSlash(key)))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Signed URLs are personal: neither shared caches nor browsers keep the file
+	w.Header().Set("Cache-Control", "private, no-store")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+path.Base(key)+`"`)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, path.Base(key), info.ModTime(), file)
+}
+
+func (s *SignedLocalFileStorage) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,29 @@
+package domain
+
+import "context"
+
+type actorContextKey struct{}
+
+// Actor is the authenticated caller of a request, recorded in the audit log, the change history
+// and the UpdatedBy field of the users it writes
+type Actor struct {
+	// ID is a user ID, the ID of the admin for impersonation tokens, or "client:<id>" for an OAuth2 client
+	ID string
+	// TokenID is the ID (jti) of the access token, or of the cookie session, the caller authenticated
+	// with; it is empty for the tokens of an external identity provider
+	TokenID string
+	// IP is the client IP address of the request
+	IP string
+}
+
+// WithActor returns a copy of ctx carrying the authenticated caller, see ActorFromContext
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext returns the caller carried by ctx, or the zero Actor for unauthenticated requests
+// and background work
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorContextKey{}).(Actor)
+	return actor
+}
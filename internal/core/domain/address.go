@@ -0,0 +1,218 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/pkg/iso3166"
+	"github.com/google/uuid"
+)
+
+// MaxAddresses bounds the number of addresses of a user
+const MaxAddresses = 10
+
+var (
+	ErrInvalidAddressType = errors.New("invalid address type: must be one of home, work, billing")
+	ErrTooManyAddresses   = errors.New("invalid addresses: a user can have at most 10 addresses")
+	ErrAddressNotFound    = errors.New("address not found")
+	ErrEmptyAddress       = errors.New("invalid address: street, city, state, zip code or country is required")
+	ErrUnknownAddress     = errors.New("invalid address: it could not be located")
+	ErrInvalidCountry     = errors.New("invalid country: must be an ISO 3166-1 code or country name")
+	ErrInvalidState       = errors.New("invalid state: must be an ISO 3166-2 code or name of a subdivision of the country")
+	ErrInvalidNear        = errors.New("invalid near: must be a latitude and longitude separated by a comma")
+	ErrInvalidRadius      = errors.New("invalid radius_km: must be a distance between 0 and 20000 kilometers")
+)
+
+// Bounds of the radius of the users searched near a point, in kilometers
+const (
+	DefaultRadiusKM = 10
+	MaxRadiusKM     = 20000
+)
+
+// Address types accepted for Address.Type
+const (
+	AddressTypeHome    = "home"
+	AddressTypeWork    = "work"
+	AddressTypeBilling = "billing"
+)
+
+// AddressTypes lists the accepted values for Address.Type
+var AddressTypes = []string{AddressTypeHome, AddressTypeWork, AddressTypeBilling}
+
+type Address struct {
+	ID      string `json:"id" bson:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Type    string `json:"type" bson:"type" example:"home"`
+	Primary bool   `json:"primary" bson:"primary" example:"true"`
+	Street  string `json:"street" bson:"street,omitempty" example:"123 Main St"`
+	City    string `json:"city" bson:"city,omitempty" example:"New York"`
+	State   string `json:"state" bson:"state,omitempty" example:"NY"`
+	Country string `json:"country" bson:"country,omitempty" example:"USA"`
+	ZipCode string `json:"zip_code" bson:"zip_code,omitempty" example:"10001"`
+	// Location is set when the address is geocoded on write
+	Location *GeoPoint `json:"location,omitempty" bson:"location,omitempty"`
+	// Geo is Location in the GeoJSON form of the 2dsphere index, set by the repository on write
+	Geo *GeoJSONPoint `json:"-" bson:"geo,omitempty"`
+}
+
+// GeoPoint is a WGS 84 coordinate
+type GeoPoint struct {
+	Lat float64 `json:"lat" bson:"lat" example:"40.7128"`
+	Lng float64 `json:"lng" bson:"lng" example:"-74.006"`
+}
+
+// GeoJSONPoint is a GeoJSON point, its coordinates being the longitude then the latitude
+type GeoJSONPoint struct {
+	Type        string     `bson:"type"`
+	Coordinates [2]float64 `bson:"coordinates"`
+}
+
+// GeoJSON returns the point in GeoJSON form
+func (p GeoPoint) GeoJSON() *GeoJSONPoint {
+	return &GeoJSONPoint{Type: "Point", Coordinates: [2]float64{p.Lng, p.Lat}}
+}
+
+// Distance returns the great-circle distance to another point in km
+func (p GeoPoint) Distance(other GeoPoint) float64 {
+	const earthRadius = 6371
+	lat1, lat2 := p.Lat*math.Pi/180, other.Lat*math.Pi/180
+	dLat, dLng := lat2-lat1, (other.Lng-p.Lng)*math.Pi/180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// ParseGeoPoint parses a "lat,lng" coordinate, as in ?near=40.7128,-74.006
+func ParseGeoPoint(value string) (*GeoPoint, error) {
+	lat, lng, found := strings.Cut(value, ",")
+	if !found {
+		return nil, ErrInvalidNear
+	}
+	point := &GeoPoint{}
+	var err error
+	if point.Lat, err = strconv.ParseFloat(strings.TrimSpace(lat), 64); err != nil || !(point.Lat >= -90 && point.Lat <= 90) {
+		return nil, ErrInvalidNear
+	}
+	if point.Lng, err = strconv.ParseFloat(strings.TrimSpace(lng), 64); err != nil || !(point.Lng >= -180 && point.Lng <= 180) {
+		return nil, ErrInvalidNear
+	}
+	return point, nil
+}
+
+// Query returns the address as a single comma-separated line, as free-text geocoders expect it
+func (a Address) Query() string {
+	parts := make([]string, 0, 5)
+	for _, part := range []string{a.Street, a.City, a.StateName(), a.ZipCode, a.CountryName()} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CountryName returns the ISO 3166-1 name of the country, or the country as stored if it is not a known code
+func (a Address) CountryName() string {
+	if country, ok := iso3166.LookupCountry(a.Country); ok {
+		return country.Name
+	}
+	return a.Country
+}
+
+// StateName returns the ISO 3166-2 name of the state, or the state as stored if it is not a known code
+func (a Address) StateName() string {
+	if country, ok := iso3166.LookupCountry(a.Country); ok {
+		if state, ok := country.LookupSubdivision(a.State); ok {
+			return state.Name
+		}
+	}
+	return a.State
+}
+
+// Validate checks an address being written: its type, defaulting to home, that it is not empty,
+// and its country and state, which are stored as their ISO 3166-1 alpha-2 and 3166-2 codes.
+// States are only checked for the countries whose subdivisions are known.
+func (a *Address) Validate() error {
+	if a.Query() == "" {
+		return ErrEmptyAddress
+	}
+	a.Type = strings.ToLower(strings.TrimSpace(a.Type))
+	if a.Type == "" {
+		a.Type = AddressTypeHome
+	}
+	if !slices.Contains(AddressTypes, a.Type) {
+		return &FieldError{Field: "type", Err: ErrInvalidAddressType}
+	}
+
+	if strings.TrimSpace(a.Country) == "" {
+		return nil
+	}
+	country, ok := iso3166.LookupCountry(a.Country)
+	if !ok {
+		return &FieldError{Field: "country", Err: ErrInvalidCountry}
+	}
+	a.Country = country.Code
+	if strings.TrimSpace(a.State) != "" && country.HasSubdivisions() {
+		state, ok := country.LookupSubdivision(a.State)
+		if !ok {
+			return &FieldError{Field: "state", Err: ErrInvalidState}
+		}
+		a.State = state.Code
+	}
+	return nil
+}
+
+// NormalizeAddresses gives an ID to the new addresses and keeps exactly one primary address:
+// the first flagged one, or the first address. Addresses being written must be validated first.
+func NormalizeAddresses(addresses []Address) ([]Address, error) {
+	if len(addresses) > MaxAddresses {
+		return nil, ErrTooManyAddresses
+	}
+	primary := -1
+	for i := range addresses {
+		if addresses[i].ID == "" {
+			addresses[i].ID = uuid.New().String()
+		}
+		if addresses[i].Primary && primary < 0 {
+			primary = i
+		}
+	}
+	if len(addresses) > 0 {
+		SetPrimaryAddress(addresses, addresses[max(primary, 0)].ID)
+	}
+	return addresses, nil
+}
+
+// SetPrimaryAddress flags the address with the given ID as primary and clears the flag on the others
+func SetPrimaryAddress(addresses []Address, id string) {
+	for i := range addresses {
+		addresses[i].Primary = addresses[i].ID == id
+	}
+}
+
+// PrimaryAddress returns the primary address of the profile, or nil if it has none
+func (p *Profile) PrimaryAddress() *Address {
+	for i := range p.Addresses {
+		if p.Addresses[i].Primary {
+			return &p.Addresses[i]
+		}
+	}
+	return nil
+}
+
+// MigrateLegacyAddress moves the single address of profiles stored before users could have
+// several into Addresses, as their primary home address. Its ID is derived from the user ID
+// so it stays the same until the migrated addresses are written back.
+func (p *Profile) MigrateLegacyAddress(userID string) {
+	if p.LegacyAddress == nil {
+		return
+	}
+	if len(p.Addresses) == 0 && p.LegacyAddress.Query() != "" {
+		address := *p.LegacyAddress
+		address.ID = uuid.NewSHA1(uuid.NameSpaceURL, []byte("address:"+userID)).String()
+		address.Type = AddressTypeHome
+		address.Primary = true
+		p.Addresses = []Address{address}
+	}
+	p.LegacyAddress = nil
+}
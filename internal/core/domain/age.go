@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// BirthdateLayout is the format of Profile.Birthdate
+const BirthdateLayout = time.DateOnly
+
+var (
+	ErrInvalidBirthdate  = errors.New("invalid birthdate: must be a past date formatted as YYYY-MM-DD")
+	ErrBirthdateRequired = errors.New("invalid birthdate: required to check the minimum age")
+	ErrUnderMinimumAge   = errors.New("invalid birthdate: the user is younger than the minimum age")
+)
+
+// AgePolicy is the minimum age users register and keep their profile with
+type AgePolicy struct {
+	MinimumAge int // Minimum age in years; 0 disables the rule
+}
+
+// ParseBirthdate parses a birthdate, which cannot be after today
+func ParseBirthdate(birthdate string, now time.Time) (time.Time, error) {
+	parsed, err := time.Parse(BirthdateLayout, birthdate)
+	if err != nil || parsed.After(now) {
+		return time.Time{}, ErrInvalidBirthdate
+	}
+	return parsed, nil
+}
+
+// AgeAt returns the age in whole years at now of someone born on birthdate
+func AgeAt(birthdate, now time.Time) int {
+	age := now.Year() - birthdate.Year()
+	if now.Month() < birthdate.Month() || (now.Month() == birthdate.Month() && now.Day() < birthdate.Day()) {
+		age--
+	}
+	return age
+}
+
+// Check rejects the birthdates younger than the minimum age at now. The birthdate is required when
+// the rule is enabled.
+func (p AgePolicy) Check(birthdate string, now time.Time) error {
+	if p.MinimumAge <= 0 {
+		return nil
+	}
+	if birthdate == "" {
+		return ErrBirthdateRequired
+	}
+	parsed, err := ParseBirthdate(birthdate, now)
+	if err != nil {
+		return err
+	}
+	if AgeAt(parsed, now) < p.MinimumAge {
+		return ErrUnderMinimumAge
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Intervals of the buckets of the registration analytics; weeks start on Monday, in UTC
+const (
+	AnalyticsIntervalDay   = "day"
+	AnalyticsIntervalWeek  = "week"
+	AnalyticsIntervalMonth = "month"
+)
+
+// AnalyticsIntervals lists the intervals registrations are counted by
+var AnalyticsIntervals = []string{AnalyticsIntervalDay, AnalyticsIntervalWeek, AnalyticsIntervalMonth}
+
+// MaxAnalyticsBuckets bounds the number of buckets of a single query
+const MaxAnalyticsBuckets = 1000
+
+var (
+	ErrInvalidAnalyticsInterval = errors.New("invalid interval: must be day, week or month")
+	ErrInvalidAnalyticsTime     = errors.New("invalid from or to: must be a date (2006-01-02) or an RFC 3339 time")
+	ErrInvalidAnalyticsRange    = errors.New("invalid range: from must be before to, with at most 1000 buckets")
+)
+
+// AnalyticsBucketStart returns the start of the bucket of the interval holding t, in UTC
+func AnalyticsBucketStart(t time.Time, interval string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case AnalyticsIntervalWeek:
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case AnalyticsIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// NextAnalyticsBucket returns the start of the bucket following the one starting at start
+func NextAnalyticsBucket(start time.Time, interval string) time.Time {
+	switch interval {
+	case AnalyticsIntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case AnalyticsIntervalMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
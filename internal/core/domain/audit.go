@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit actions
+const (
+	AuditActionImpersonationStarted  = "impersonation.started"
+	AuditActionImpersonatedRequest   = "impersonation.request"
+	AuditActionUserMerged            = "user.merged"
+	AuditActionLoginReported         = "login.reported"
+	AuditActionUsersExported         = "users.exported"
+	AuditActionUsersBatchUpdated     = "users.batch_updated"
+	AuditActionDirectorySynced       = "directory.synced"
+	AuditActionUserProvisioned       = "user.provisioned"
+	AuditActionUserUpserted          = "user.upserted"
+	AuditActionUserCreated           = "user.created"
+	AuditActionSigningKeyRotated     = "signing_key.rotated"
+	AuditActionClientRegistered      = "oauth_client.registered"
+	AuditActionClientDeleted         = "oauth_client.deleted"
+	AuditActionEmailDomainAdded      = "email_domain.added"
+	AuditActionEmailDomainRemoved    = "email_domain.removed"
+	AuditActionTermsPublished        = "terms.published"
+	AuditActionTermsAccepted         = "terms.accepted"
+	AuditActionJobRetried            = "job.retried"
+	AuditActionLoginFailed           = "login.failed"
+	AuditActionDocumentUploaded      = "document.uploaded"
+	AuditActionDocumentDownloaded    = "document.downloaded"
+	AuditActionVerificationSubmitted = "verification.submitted"
+	AuditActionVerificationVerified  = "verification.verified"
+	AuditActionVerificationRejected  = "verification.rejected"
+	AuditActionVerificationChecked   = "verification.checked"
+	AuditActionMinimumAgeOverridden  = "user.minimum_age_overridden"
+	AuditActionMFAFactorAdded        = "mfa.factor_added"
+	AuditActionMFAFactorRemoved      = "mfa.factor_removed"
+	AuditActionDeviceTrusted         = "mfa.device_trusted"
+	AuditActionDeviceRevoked         = "mfa.device_revoked"
+	AuditActionPasswordChanged       = "user.password_changed"
+	AuditActionEmailChanged          = "user.email_changed"
+	AuditActionLoginAnomaly          = "login.anomaly"
+	AuditActionLoggedOutAll          = "user.logged_out_all"
+	AuditActionUserReverted          = "user.reverted"
+	AuditActionConfigUpdated         = "config.updated"
+	AuditActionConfigReloaded        = "config.reloaded"
+)
+
+// AuditEvent records who performed an action on which resource
+type AuditEvent struct {
+	ID       string            `json:"id" bson:"_id,omitempty" example:"1b4e28ba-2fa1-11d2-883f-0016d3cca427"`
+	TenantID string            `json:"-" bson:"tenant_id,omitempty"`
+	Action   string            `json:"action" bson:"action" example:"impersonation.started"`
+	ActorID  string            `json:"actor_id" bson:"actor_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TargetID string            `json:"target_id,omitempty" bson:"target_id,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Details  map[string]string `json:"details,omitempty" bson:"details,omitempty"`
+	// TokenID and IP identify the token, or cookie session, and the IP address of the request
+	// performing the action, see Actor; they are empty for background work
+	TokenID   string    `json:"token_id,omitempty" bson:"token_id,omitempty" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	IP        string    `json:"ip,omitempty" bson:"ip,omitempty" example:"203.0.113.7"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func NewAuditEvent(action, actorID, targetID string, details map[string]string) *AuditEvent {
+	return &AuditEvent{
+		ID:        uuid.New().String(),
+		Action:    action,
+		ActorID:   actorID,
+		TargetID:  targetID,
+		Details:   details,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "time"
+
+type AvatarStatus string
+
+const (
+	AvatarStatusProcessing AvatarStatus = "processing"
+	AvatarStatusReady      AvatarStatus = "ready"
+	AvatarStatusFailed     AvatarStatus = "failed"
+)
+
+// AvatarSize describes a generated avatar variant and its bounding box in pixels
+type AvatarSize struct {
+	Name      string
+	MaxPixels int
+}
+
+// AvatarSizes lists the resized variants generated for every uploaded avatar
+var AvatarSizes = []AvatarSize{
+	{Name: "thumbnail", MaxPixels: 64},
+	{Name: "medium", MaxPixels: 256},
+}
+
+// Avatar holds the public URLs of every stored avatar size
+type Avatar struct {
+	Version      string       `json:"version" bson:"version,omitempty" example:"1704067200000000000"`
+	Status       AvatarStatus `json:"status" bson:"status,omitempty" example:"ready"`
+	OriginalURL  string       `json:"original_url" bson:"original_url,omitempty" example:"/media/avatars/550e8400-e29b-41d4-a716-446655440000/1704067200000000000/original.png"`
+	MediumURL    string       `json:"medium_url,omitempty" bson:"medium_url,omitempty" example:"/media/avatars/550e8400-e29b-41d4-a716-446655440000/1704067200000000000/medium.png"`
+	ThumbnailURL string       `json:"thumbnail_url,omitempty" bson:"thumbnail_url,omitempty" example:"/media/avatars/550e8400-e29b-41d4-a716-446655440000/1704067200000000000/thumbnail.png"`
+	UpdatedAt    time.Time    `json:"updated_at" bson:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"`
+}
+
+// SetSizeURL records the URL of a generated avatar size
+func (a *Avatar) SetSizeURL(size, url string) {
+	switch size {
+	case "thumbnail":
+		a.ThumbnailURL = url
+	case "medium":
+		a.MediumURL = url
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import "errors"
+
+var (
+	ErrBatchSelectorMissing = errors.New("invalid batch update: select the users with a filter or ids")
+	ErrBatchUpdateEmpty     = errors.New("invalid batch update: set add_tags, remove_tags or deactivated")
+	ErrBatchTagConflict     = errors.New("invalid batch update: add and remove tags in separate batches")
+)
+
+// UserBatchUpdate lists the changes a batch update applies to every user it selects
+type UserBatchUpdate struct {
+	AddTags    []string `json:"add_tags,omitempty" example:"vip"`
+	RemoveTags []string `json:"remove_tags,omitempty" example:"trial"`
+	// Deactivated suspends the users, which cannot log in and whose tokens are revoked, when true
+	// and reactivates them when false
+	Deactivated *bool `json:"deactivated,omitempty" example:"true"`
+}
+
+// Normalize normalizes the tags of the update and checks that it changes something
+func (u *UserBatchUpdate) Normalize() error {
+	var err error
+	if u.AddTags, err = NormalizeTags(u.AddTags); err != nil {
+		return err
+	}
+	if u.RemoveTags, err = NormalizeTags(u.RemoveTags); err != nil {
+		return err
+	}
+	// MongoDB cannot add to and pull from the tags in a single update
+	if len(u.AddTags) > 0 && len(u.RemoveTags) > 0 {
+		return ErrBatchTagConflict
+	}
+	if len(u.AddTags) == 0 && len(u.RemoveTags) == 0 && u.Deactivated == nil {
+		return ErrBatchUpdateEmpty
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"context"
+	"sync"
+)
+
+type consistencyContextKey struct{}
+
+// CausalConsistency is the position in the history of the database reached by the operations of a
+// request: the reads of a request carrying it see at least the writes before that position, even on
+// the secondaries, so callers read their own writes. The position is an opaque token, handed to the
+// client and back so the reads of a request see the writes of the previous ones.
+type CausalConsistency struct {
+	mu    sync.Mutex
+	token string
+}
+
+// WithCausalConsistency returns a copy of ctx whose database operations are causally consistent,
+// starting after token when it is not empty, and the position they reach
+func WithCausalConsistency(ctx context.Context, token string) (context.Context, *CausalConsistency) {
+	consistency := &CausalConsistency{token: token}
+	return context.WithValue(ctx, consistencyContextKey{}, consistency), consistency
+}
+
+// CausalConsistencyFromContext returns the position carried by ctx, or nil when its operations
+// are not causally consistent
+func CausalConsistencyFromContext(ctx context.Context) *CausalConsistency {
+	consistency, _ := ctx.Value(consistencyContextKey{}).(*CausalConsistency)
+	return consistency
+}
+
+// Token returns the position reached, or an empty string before the first operation
+func (c *CausalConsistency) Token() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token
+}
+
+// Advance replaces the position with the one next returns for it, so concurrent operations never
+// move it back
+func (c *CausalConsistency) Advance(next func(token string) string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = next(c.token)
+}
@@ -0,0 +1,166 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Types of the values of the custom registration fields, all stored as strings
+const (
+	CustomFieldText    = "text"
+	CustomFieldNumber  = "number"
+	CustomFieldBoolean = "boolean"
+	CustomFieldDate    = "date" // YYYY-MM-DD
+)
+
+// defaultCustomFieldMaxLength limits the values of the fields defining no max_length
+const defaultCustomFieldMaxLength = 256
+
+var (
+	ErrInvalidCustomFieldDefinition = errors.New("invalid custom field definition")
+	ErrCustomFieldRequired          = errors.New("invalid custom field: required")
+	ErrUnknownCustomField           = errors.New("invalid custom field: not a registration field")
+	ErrCustomFieldTooLong           = errors.New("invalid custom field: exceeds the maximum length")
+	ErrCustomFieldFormat            = errors.New("invalid custom field: does not match the type or pattern of the field")
+	ErrCustomFieldOption            = errors.New("invalid custom field: not one of the options of the field")
+)
+
+// CustomField is an extra registration field configured by the operator, such as a department or
+// an employee ID, stored in User.CustomFields
+type CustomField struct {
+	Name     string `json:"name" example:"department"`
+	Type     string `json:"type" example:"text"`
+	Required bool   `json:"required" example:"true"`
+	// Options lists the accepted values of a text field, any value when empty
+	Options []string `json:"options,omitempty" example:"sales,engineering,support"`
+	// Pattern is a regular expression the whole value of a text field matches
+	Pattern   string `json:"pattern,omitempty" example:"E[0-9]{6}"`
+	MaxLength int    `json:"max_length,omitempty" example:"64"`
+
+	pattern *regexp.Regexp
+}
+
+// CustomFieldSchema is the set of custom fields users register with
+type CustomFieldSchema struct {
+	Fields []CustomField
+}
+
+// ParseCustomFieldSchema parses a JSON array of custom field definitions; an empty definition has
+// no fields
+func ParseCustomFieldSchema(definitions string) (CustomFieldSchema, error) {
+	var schema CustomFieldSchema
+	if strings.TrimSpace(definitions) == "" {
+		return schema, nil
+	}
+	if err := json.Unmarshal([]byte(definitions), &schema.Fields); err != nil {
+		return CustomFieldSchema{}, fmt.Errorf("%w: %v", ErrInvalidCustomFieldDefinition, err)
+	}
+	seen := make(map[string]bool, len(schema.Fields))
+	for i := range schema.Fields {
+		field := &schema.Fields[i]
+		if !ValidMetadataKey(field.Name) || seen[field.Name] {
+			return CustomFieldSchema{}, fmt.Errorf("%w: missing, invalid or repeated name %q", ErrInvalidCustomFieldDefinition, field.Name)
+		}
+		seen[field.Name] = true
+		switch field.Type {
+		case "":
+			field.Type = CustomFieldText
+		case CustomFieldText, CustomFieldNumber, CustomFieldBoolean, CustomFieldDate:
+		default:
+			return CustomFieldSchema{}, fmt.Errorf("%w: unknown type %q of %s", ErrInvalidCustomFieldDefinition, field.Type, field.Name)
+		}
+		if field.Type != CustomFieldText && (len(field.Options) > 0 || field.Pattern != "") {
+			return CustomFieldSchema{}, fmt.Errorf("%w: only text fields have options or a pattern, not %s", ErrInvalidCustomFieldDefinition, field.Name)
+		}
+		if field.Pattern != "" {
+			pattern, err := regexp.Compile(`^(?:` + field.Pattern + `)$`)
+			if err != nil {
+				return CustomFieldSchema{}, fmt.Errorf("%w: pattern of %s: %v", ErrInvalidCustomFieldDefinition, field.Name, err)
+			}
+			field.pattern = pattern
+		}
+		if field.MaxLength < 0 {
+			return CustomFieldSchema{}, fmt.Errorf("%w: negative max_length of %s", ErrInvalidCustomFieldDefinition, field.Name)
+		}
+		if field.MaxLength == 0 {
+			field.MaxLength = defaultCustomFieldMaxLength
+		}
+	}
+	return schema, nil
+}
+
+// Validate checks the values of a registration against the schema and returns them normalized:
+// trimmed, with booleans as true or false, numbers without leading zeros and blank values dropped.
+// Errors are FieldErrors on custom_fields.<name>.
+func (s CustomFieldSchema) Validate(values map[string]string) (map[string]string, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if !slices.ContainsFunc(s.Fields, func(field CustomField) bool { return field.Name == name }) {
+			return nil, &FieldError{Field: "custom_fields." + name, Err: ErrUnknownCustomField}
+		}
+	}
+
+	var result map[string]string
+	for _, field := range s.Fields {
+		value := strings.TrimSpace(values[field.Name])
+		if value == "" {
+			if field.Required {
+				return nil, &FieldError{Field: "custom_fields." + field.Name, Err: ErrCustomFieldRequired}
+			}
+			continue
+		}
+		normalized, err := field.normalize(value)
+		if err != nil {
+			return nil, &FieldError{Field: "custom_fields." + field.Name, Err: err}
+		}
+		if result == nil {
+			result = make(map[string]string, len(s.Fields))
+		}
+		result[field.Name] = normalized
+	}
+	return result, nil
+}
+
+func (f CustomField) normalize(value string) (string, error) {
+	if utf8.RuneCountInString(value) > f.MaxLength {
+		return "", ErrCustomFieldTooLong
+	}
+	switch f.Type {
+	case CustomFieldNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return "", ErrCustomFieldFormat
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case CustomFieldBoolean:
+		flag, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", ErrCustomFieldFormat
+		}
+		return strconv.FormatBool(flag), nil
+	case CustomFieldDate:
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return "", ErrCustomFieldFormat
+		}
+		return value, nil
+	}
+	if len(f.Options) > 0 && !slices.Contains(f.Options, value) {
+		return "", ErrCustomFieldOption
+	}
+	if f.pattern != nil && !f.pattern.MatchString(value) {
+		return "", ErrCustomFieldFormat
+	}
+	return value, nil
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// AdminDigest summarizes the account activity of a tenant over a period, emailed to its admins
+type AdminDigest struct {
+	TenantID string
+	From     time.Time
+	To       time.Time
+	// Registrations and Deletions count the users created and soft-deleted during the period
+	Registrations int64
+	Deletions     int64
+	// FailedLogins counts the logins refused for invalid credentials
+	FailedLogins int64
+	// LockedAccounts counts the accounts locked after a login was reported, see User.PasswordResetRequired
+	LockedAccounts int64
+	// DeactivatedAccounts counts the accounts deactivated by the directory sync
+	DeactivatedAccounts int64
+}
+
+// Quiet reports whether nothing happened during the period
+func (d *AdminDigest) Quiet() bool {
+	return d.Registrations == 0 && d.Deletions == 0 && d.FailedLogins == 0 && d.LockedAccounts == 0 && d.DeactivatedAccounts == 0
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "testing"
+
+func TestAdminDigestQuiet(t *testing.T) {
+	tests := []struct {
+		name   string
+		digest AdminDigest
+		want   bool
+	}{
+		{name: "nothing happened", digest: AdminDigest{TenantID: "acme"}, want: true},
+		{name: "registrations", digest: AdminDigest{Registrations: 1}},
+		{name: "deletions", digest: AdminDigest{Deletions: 1}},
+		{name: "failed logins", digest: AdminDigest{FailedLogins: 1}},
+		{name: "locked accounts", digest: AdminDigest{LockedAccounts: 1}},
+		{name: "deactivated accounts", digest: AdminDigest{DeactivatedAccounts: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.digest.Quiet(); got != tt.want {
+				t.Errorf("Quiet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
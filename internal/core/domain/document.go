@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidDocumentType   = errors.New("invalid document type: must be passport, national_id, drivers_license or proof_of_address")
+	ErrInvalidDocumentFile   = errors.New("invalid document file: must be a PDF, JPEG or PNG file")
+	ErrInvalidDocumentExpiry = errors.New("invalid document expiry: must be a future date formatted as YYYY-MM-DD")
+)
+
+// Identity documents users upload to verify their identity
+const (
+	DocumentTypePassport       = "passport"
+	DocumentTypeNationalID     = "national_id"
+	DocumentTypeDriversLicense = "drivers_license"
+	DocumentTypeProofOfAddress = "proof_of_address"
+)
+
+// DocumentTypes are the types of documents users can upload
+var DocumentTypes = []string{DocumentTypePassport, DocumentTypeNationalID, DocumentTypeDriversLicense, DocumentTypeProofOfAddress}
+
+// Review statuses of a document; uploaded documents are pending until staff reviews them
+const (
+	DocumentStatusPending  = "pending"
+	DocumentStatusVerified = "verified"
+	DocumentStatusRejected = "rejected"
+)
+
+// Document is an identity document uploaded for a user. Its file is kept in private storage,
+// only downloaded through the short-lived URLs handed to staff.
+type Document struct {
+	ID          string     `json:"id" bson:"_id" example:"4d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a"`
+	TenantID    string     `json:"-" bson:"tenant_id,omitempty"`
+	UserID      string     `json:"user_id" bson:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Type        string     `json:"type" bson:"type" example:"passport" enums:"passport,national_id,drivers_license,proof_of_address"`
+	Status      string     `json:"status" bson:"status" example:"pending" enums:"pending,verified,rejected"`
+	FileName    string     `json:"file_name" bson:"file_name" example:"passport.pdf"`
+	ContentType string     `json:"content_type" bson:"content_type" example:"application/pdf"`
+	Size        int64      `json:"size" bson:"size" example:"482133"`
+	StorageKey  string     `json:"-" bson:"storage_key"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty" example:"2030-01-31T00:00:00Z"`
+	UploadedBy  string     `json:"uploaded_by" bson:"uploaded_by" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt   time.Time  `json:"created_at" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time  `json:"updated_at" bson:"updated_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// DocumentDownload is a pre-signed URL granting access to the file of a document until it expires
+type DocumentDownload struct {
+	URL       string    `json:"url" example:"/documents/default/550e8400-e29b-41d4-a716-446655440000/4d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a.pdf?expires=1704067500&signature=9f86d081884c7d65"`
+	ExpiresAt time.Time `json:"expires_at" example:"2024-01-01T00:05:00Z"`
+}
+
+// documentFormats are the accepted file formats, recognized by their leading bytes
+var documentFormats = []struct {
+	magic       []byte
+	contentType string
+	ext         string
+}{
+	{[]byte("%PDF-"), "application/pdf", "pdf"},
+	{[]byte("\xff\xd8\xff"), "image/jpeg", "jpg"},
+	{[]byte("\x89PNG\r\n\x1a\n"), "image/png", "png"},
+}
+
+// DetectDocumentFormat returns the content type and file extension of a document file
+func DetectDocumentFormat(content []byte) (contentType, ext string, err error) {
+	for _, format := range documentFormats {
+		if bytes.HasPrefix(content, format.magic) {
+			return format.contentType, format.ext, nil
+		}
+	}
+	return "", "", ErrInvalidDocumentFile
+}
+
+// ParseDocumentExpiry parses the optional expiry date of a document, which must be in the future
+func ParseDocumentExpiry(value string) (*time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	expiresAt, err := time.Parse(time.DateOnly, value)
+	if err != nil || !expiresAt.After(time.Now()) {
+		return nil, ErrInvalidDocumentExpiry
+	}
+	return &expiresAt, nil
+}
+
+// NewDocument returns a pending document of the user uploaded by uploadedBy, the user itself or staff
+func NewDocument(userID, uploadedBy, documentType, fileName, contentType string, size int64, expiresAt *time.Time) (*Document, error) {
+	if !slices.Contains(DocumentTypes, documentType) {
+		return nil, ErrInvalidDocumentType
+	}
+
+	now := time.Now()
+	return &Document{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		Type:        documentType,
+		Status:      DocumentStatusPending,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		ExpiresAt:   expiresAt,
+		UploadedBy:  uploadedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Reasons for reporting accounts as likely duplicates
+const (
+	// DuplicateReasonNameBirthdate matches users with the same birthdate and the same name,
+	// ignoring case, accents, spaces and punctuation
+	DuplicateReasonNameBirthdate = "name_birthdate"
+	// DuplicateReasonSimilarEmail matches users whose normalized email local parts have the same
+	// letters, whatever the domain, digits and punctuation: "john.doe85@yahoo.com" and "johndoe@gmail.com"
+	DuplicateReasonSimilarEmail = "similar_email"
+)
+
+// MinDuplicateEmailKeyLength keeps short, common local parts such as "info" out of the report
+const MinDuplicateEmailKeyLength = 5
+
+// DuplicateCandidate is a group of accounts that likely belong to the same person
+type DuplicateCandidate struct {
+	Reasons []string `json:"reasons" example:"name_birthdate,similar_email"`
+	Users   []*User  `json:"users"`
+}
+
+// NameKey returns the first and last name folded for fuzzy comparison, or "" if the profile has no name
+func (p Profile) NameKey() string {
+	return letters(p.FirstName + p.LastName)
+}
+
+// EmailKey returns the letters of the local part of a normalized email, see DuplicateReasonSimilarEmail
+func EmailKey(normalizedEmail string) string {
+	local, _, _ := strings.Cut(normalizedEmail, "@")
+	return letters(local)
+}
+
+// letters lowercases s and keeps only its letters, with the accents stripped
+func letters(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), runes.Remove(runes.NotIn(unicode.L)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		return strings.ToLower(s)
+	}
+	return strings.ToLower(folded)
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+var ErrEmailTaken = errors.New("email is already in use")
+
+// emailProvider describes how a mail provider routes address variants to the same mailbox
+type emailProvider struct {
+	canonicalDomain string // Domain all aliases of the provider normalize to
+	ignoreDots      bool   // Dots in the local part are ignored
+	tagSeparator    byte   // Everything after this character in the local part is ignored
+}
+
+var emailProviders = map[string]emailProvider{
+	"gmail.com":      {canonicalDomain: "gmail.com", ignoreDots: true, tagSeparator: '+'},
+	"googlemail.com": {canonicalDomain: "gmail.com", ignoreDots: true, tagSeparator: '+'},
+	"outlook.com":    {canonicalDomain: "outlook.com", tagSeparator: '+'},
+	"hotmail.com":    {canonicalDomain: "hotmail.com", tagSeparator: '+'},
+	"live.com":       {canonicalDomain: "live.com", tagSeparator: '+'},
+	"icloud.com":     {canonicalDomain: "icloud.com", tagSeparator: '+'},
+	"me.com":         {canonicalDomain: "icloud.com", tagSeparator: '+'},
+	"fastmail.com":   {canonicalDomain: "fastmail.com", tagSeparator: '+'},
+	"proton.me":      {canonicalDomain: "proton.me", tagSeparator: '+'},
+	"protonmail.com": {canonicalDomain: "proton.me", tagSeparator: '+'},
+	"yahoo.com":      {canonicalDomain: "yahoo.com", tagSeparator: '-'},
+}
+
+// CanonicalEmail trims and lowercases an email and converts an internationalized domain to its
+// ASCII (punycode) form, e.g. "Jo@Bücher.de" becomes "jo@xn--bcher-kva.de"
+func CanonicalEmail(email string) (string, error) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	at := strings.LastIndexByte(email, '@')
+	if at < 1 || at == len(email)-1 {
+		return "", ErrInvalidEmail
+	}
+	domain, err := idna.Lookup.ToASCII(email[at+1:])
+	if err != nil || !strings.Contains(domain, ".") {
+		return "", ErrInvalidEmail
+	}
+	return email[:at] + "@" + domain, nil
+}
+
+// NormalizeEmail returns the canonical email with the provider aliasing rules applied, so that
+// "John.Doe+news@googlemail.com" and "johndoe@gmail.com" are recognized as the same mailbox
+func NormalizeEmail(email string) (string, error) {
+	email, err := CanonicalEmail(email)
+	if err != nil {
+		return "", err
+	}
+	at := strings.LastIndexByte(email, '@')
+	local, domain := email[:at], email[at+1:]
+
+	provider, ok := emailProviders[domain]
+	if !ok {
+		return email, nil
+	}
+	if i := strings.IndexByte(local, provider.tagSeparator); i > 0 {
+		local = local[:i]
+	}
+	if provider.ignoreDots {
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	if local == "" {
+		return "", ErrInvalidEmail
+	}
+	return local + "@" + provider.canonicalDomain, nil
+}
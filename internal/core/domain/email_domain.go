@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/net/idna"
+)
+
+// Email domain lists restricting registrations: with allowed domains, only their emails register;
+// denied domains never do
+const (
+	EmailDomainAllow = "allow"
+	EmailDomainDeny  = "deny"
+)
+
+var (
+	ErrInvalidEmailDomain     = errors.New("invalid email domain: must be a domain name such as example.com")
+	ErrInvalidEmailDomainList = errors.New("invalid email domain list: must be allow or deny")
+	ErrEmailDomainNotAllowed  = errors.New("email domain not allowed: registrations with this email domain are refused")
+	ErrEmailDomainRuleExists  = errors.New("email domain is already in the list")
+)
+
+// EmailDomainRule is a domain added to the allow or deny list of a tenant at runtime. A domain
+// covers its subdomains.
+type EmailDomainRule struct {
+	ID        string     `json:"id,omitempty" bson:"_id,omitempty" example:"1b4e28ba-2fa1-11d2-883f-0016d3cca427"`
+	TenantID  string     `json:"-" bson:"tenant_id,omitempty"`
+	List      string     `json:"list" bson:"list" example:"allow"`
+	Domain    string     `json:"domain" bson:"domain" example:"example.com"`
+	CreatedBy string     `json:"created_by,omitempty" bson:"created_by,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	CreatedAt *time.Time `json:"created_at,omitempty" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+	// Configured rules come from EMAIL_DOMAIN_ALLOWLIST and EMAIL_DOMAIN_DENYLIST, without ID nor
+	// creation, and cannot be removed
+	Configured bool `json:"configured,omitempty" bson:"-" example:"false"`
+}
+
+// NewEmailDomainRule returns a rule adding the domain to the list on behalf of createdBy
+func NewEmailDomainRule(list, domain, createdBy string) (*EmailDomainRule, error) {
+	if list != EmailDomainAllow && list != EmailDomainDeny {
+		return nil, ErrInvalidEmailDomainList
+	}
+	domain, err := NormalizeEmailDomain(domain)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &EmailDomainRule{
+		ID:        uuid.New().String(),
+		List:      list,
+		Domain:    domain,
+		CreatedBy: createdBy,
+		CreatedAt: &now,
+	}, nil
+}
+
+// NormalizeEmailDomain lowercases a domain, without a leading @, and converts it to its ASCII
+// (punycode) form as CanonicalEmail does
+func NormalizeEmailDomain(domain string) (string, error) {
+	domain = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "@")
+	ascii, err := idna.Lookup.ToASCII(domain)
+	if err != nil || !strings.Contains(ascii, ".") {
+		return "", ErrInvalidEmailDomain
+	}
+	return ascii, nil
+}
+
+// EmailDomainPolicy holds the allowed and denied email domains of the registrations, normalized
+type EmailDomainPolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+// NewEmailDomainPolicy normalizes the domains of the lists
+func NewEmailDomainPolicy(allow, deny []string) (EmailDomainPolicy, error) {
+	var policy EmailDomainPolicy
+	for _, domain := range allow {
+		normalized, err := NormalizeEmailDomain(domain)
+		if err != nil {
+			return EmailDomainPolicy{}, err
+		}
+		policy.Allow = append(policy.Allow, normalized)
+	}
+	for _, domain := range deny {
+		normalized, err := NormalizeEmailDomain(domain)
+		if err != nil {
+			return EmailDomainPolicy{}, err
+		}
+		policy.Deny = append(policy.Deny, normalized)
+	}
+	return policy, nil
+}
+
+// With returns the policy with the domains of the rules added to their lists
+func (p EmailDomainPolicy) With(rules []*EmailDomainRule) EmailDomainPolicy {
+	combined := EmailDomainPolicy{Allow: slices.Clone(p.Allow), Deny: slices.Clone(p.Deny)}
+	for _, rule := range rules {
+		if rule.List == EmailDomainAllow {
+			combined.Allow = append(combined.Allow, rule.Domain)
+		} else {
+			combined.Deny = append(combined.Deny, rule.Domain)
+		}
+	}
+	return combined
+}
+
+// Check refuses the canonical emails of a denied domain, or of none of the allowed domains when
+// some are allowed
+func (p EmailDomainPolicy) Check(email string) error {
+	domain := email[strings.LastIndexByte(email, '@')+1:]
+	if slices.ContainsFunc(p.Deny, func(denied string) bool { return withinDomain(domain, denied) }) {
+		return ErrEmailDomainNotAllowed
+	}
+	if len(p.Allow) > 0 && !slices.ContainsFunc(p.Allow, func(allowed string) bool { return withinDomain(domain, allowed) }) {
+		return ErrEmailDomainNotAllowed
+	}
+	return nil
+}
+
+// withinDomain reports whether domain is parent or one of its subdomains
+func withinDomain(domain, parent string) bool {
+	return domain == parent || strings.HasSuffix(domain, "."+parent)
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Export formats
+const (
+	// ExportFormatNDJSON writes one JSON object per line
+	ExportFormatNDJSON = "ndjson"
+	// ExportFormatParquet writes an Apache Parquet file, roles and tags joined with commas
+	ExportFormatParquet = "parquet"
+)
+
+var ErrInvalidExportFormat = errors.New("invalid export format: must be ndjson or parquet")
+
+func ValidExportFormat(format string) bool {
+	return format == ExportFormatNDJSON || format == ExportFormatParquet
+}
+
+// UserSnapshot is the flat view of a user written by exports, without credentials, national
+// identification number, phone, addresses or metadata
+type UserSnapshot struct {
+	ID            string     `json:"id"`
+	Email         string     `json:"email"`
+	Username      string     `json:"username,omitempty"`
+	FirstName     string     `json:"first_name,omitempty"`
+	LastName      string     `json:"last_name,omitempty"`
+	Birthdate     string     `json:"birthdate,omitempty"`
+	Locale        string     `json:"locale,omitempty"`
+	Timezone      string     `json:"timezone,omitempty"`
+	Country       string     `json:"country,omitempty"` // Country of the primary address
+	Roles         []string   `json:"roles"`
+	Tags          []string   `json:"tags"`
+	EmailVerified bool       `json:"email_verified"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	LastLoginAt   *time.Time `json:"last_login_at,omitempty"`
+	LastSeenAt    *time.Time `json:"last_seen_at,omitempty"`
+}
+
+// NewUserSnapshot flattens the user; login and last-seen times are only kept when includeActivity is set
+func NewUserSnapshot(user *User, includeActivity bool) *UserSnapshot {
+	snapshot := &UserSnapshot{
+		ID:            user.ID,
+		Email:         user.Email,
+		Username:      user.Username,
+		FirstName:     user.Profile.FirstName,
+		LastName:      user.Profile.LastName,
+		Birthdate:     user.Profile.Birthdate,
+		Locale:        user.Profile.Locale,
+		Timezone:      user.Profile.Timezone,
+		Roles:         user.EffectiveRoles(),
+		Tags:          user.Tags,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		CreatedAt:     user.CreatedAt,
+		UpdatedAt:     user.UpdatedAt,
+	}
+	if snapshot.Tags == nil {
+		snapshot.Tags = []string{}
+	}
+	if address := user.Profile.PrimaryAddress(); address != nil {
+		snapshot.Country = address.Country
+	}
+	if includeActivity {
+		snapshot.LastLoginAt = user.LastLoginAt
+		snapshot.LastSeenAt = user.LastSeenAt
+	}
+	return snapshot
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+// Facets of the user lists, counted over every user matching the filters
+const (
+	// UserFacetCountry is the country of the primary address, empty for users without one
+	UserFacetCountry = "country"
+	// UserFacetStatus is the status of the account, see User.Status
+	UserFacetStatus = "status"
+	UserFacetRole   = "role"
+	UserFacetTag    = "tag"
+)
+
+// UserFacets lists the facets users can be counted by
+var UserFacets = []string{UserFacetCountry, UserFacetStatus, UserFacetRole, UserFacetTag}
+
+// MaxFacetValues bounds the values counted per facet, the most frequent first
+const MaxFacetValues = 50
+
+// Statuses of the accounts
+const (
+	UserStatusActive      = "active"
+	UserStatusLocked      = "locked"
+	UserStatusDeactivated = "deactivated"
+)
+
+var ErrInvalidFacet = errors.New("invalid facet: must be country, status, role or tag")
+
+// ParseUserFacets parses a comma-separated list of facets, dropping duplicates
+func ParseUserFacets(value string) ([]string, error) {
+	var facets []string
+	for _, facet := range strings.Split(value, ",") {
+		facet = strings.TrimSpace(facet)
+		if facet == "" {
+			continue
+		}
+		if !slices.Contains(UserFacets, facet) {
+			return nil, ErrInvalidFacet
+		}
+		if !slices.Contains(facets, facet) {
+			facets = append(facets, facet)
+		}
+	}
+	return facets, nil
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// historyIgnoredFields change on their own or are not part of the account, and are left out of the
+// change history
+var historyIgnoredFields = []string{"id", "created_at", "updated_at", "last_login_at", "last_seen_at", "relationships", "updated_by"}
+
+// FieldChange is the change of a field of a user, by its JSON path. Empty values are omitted, and
+// password changes are recorded without values.
+type FieldChange struct {
+	Field string `json:"field" example:"profile.last_name"`
+	Old   any    `json:"old,omitempty"`
+	New   any    `json:"new,omitempty"`
+}
+
+// UserVersion is a version of a user in its change history: the fields a write changed, who made it
+// and when. The first version is the creation of the user.
+type UserVersion struct {
+	ID       string `json:"-" bson:"_id"`
+	TenantID string `json:"-" bson:"tenant_id"`
+	UserID   string `json:"user_id" bson:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Version  int    `json:"version" bson:"version" example:"3"`
+	// ActorID is the caller who made the change, see ActorFromContext
+	ActorID   string        `json:"actor_id,omitempty" bson:"actor_id,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Changes   []FieldChange `json:"changes" bson:"changes"`
+	CreatedAt time.Time     `json:"created_at" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+	// Profile is the profile of the user as of the version
+	Profile Profile `json:"-" bson:"profile"`
+}
+
+// NewUserVersion returns the version of user after a change made by actorID; its number is set when
+// it is stored
+func NewUserVersion(user *User, actorID string, changes []FieldChange) *UserVersion {
+	return &UserVersion{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		ActorID:   actorID,
+		Changes:   changes,
+		CreatedAt: time.Now(),
+		Profile:   user.Profile,
+	}
+}
+
+// DiffUsers returns the changes of the fields shown by the API from before to after, sorted by
+// field, with the settings and the password of the user
+func DiffUsers(before, after *User) []FieldChange {
+	old, updated := historyFields(before), historyFields(after)
+	fields := make([]string, 0, len(old)+len(updated))
+	for field := range old {
+		fields = append(fields, field)
+	}
+	for field := range updated {
+		if _, ok := old[field]; !ok {
+			fields = append(fields, field)
+		}
+	}
+	slices.Sort(fields)
+
+	var changes []FieldChange
+	for _, field := range fields {
+		if !reflect.DeepEqual(old[field], updated[field]) {
+			changes = append(changes, FieldChange{Field: field, Old: old[field], New: updated[field]})
+		}
+	}
+	if before.PasswordHash != after.PasswordHash {
+		changes = append(changes, FieldChange{Field: "password"})
+	}
+	return changes
+}
+
+// historyFields flattens the JSON of the user and of its settings into their non-empty values by
+// path; arrays are values of their own
+func historyFields(user *User) map[string]any {
+	fields := map[string]any{}
+	flattenJSON(user, "", fields)
+	if user.Settings != nil {
+		flattenJSON(user.Settings, "settings.", fields)
+	}
+	if user.PasswordResetRequired {
+		fields["password_reset_required"] = true
+	}
+	for _, field := range historyIgnoredFields {
+		delete(fields, field)
+	}
+	return fields
+}
+
+func flattenJSON(value any, prefix string, fields map[string]any) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return
+	}
+	var object map[string]any
+	if err := json.Unmarshal(encoded, &object); err != nil {
+		return
+	}
+	flattenObject(object, prefix, fields)
+}
+
+func flattenObject(object map[string]any, prefix string, fields map[string]any) {
+	for key, value := range object {
+		switch value := value.(type) {
+		case map[string]any:
+			flattenObject(value, prefix+key+".", fields)
+		case nil:
+		case string:
+			if value != "" {
+				fields[prefix+key] = value
+			}
+		case []any:
+			if len(value) > 0 {
+				fields[prefix+key] = value
+			}
+		default:
+			fields[prefix+key] = value
+		}
+	}
+}
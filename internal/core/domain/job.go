@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrJobExists is returned when a job is created with the unique key of another job
+	ErrJobExists        = errors.New("job already exists")
+	ErrInvalidJobStatus = errors.New("invalid job status: must be queued, running, succeeded or failed")
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"    // Waiting for a worker, first run or retry
+	JobStatusRunning   JobStatus = "running"   // Claimed by a worker
+	JobStatusSucceeded JobStatus = "succeeded" // Done, removed after JobSucceededTTL
+	JobStatusFailed    JobStatus = "failed"    // Dead letter: every attempt failed, kept until retried
+)
+
+// JobStatuses lists the statuses jobs are filtered by
+var JobStatuses = []JobStatus{JobStatusQueued, JobStatusRunning, JobStatusSucceeded, JobStatusFailed}
+
+// Types of the jobs run by the background workers
+const (
+	JobTypeEmail          = "email.send"
+	JobTypeRetentionPurge = "retention.purge"
+	JobTypeDirectorySync  = "directory.sync"
+	JobTypeStatsRollup    = "stats.rollup"
+	JobTypeAdminDigest    = "admin.digest"
+)
+
+// JobSucceededTTL is how long succeeded jobs are kept for inspection
+const JobSucceededTTL = 7 * 24 * time.Hour
+
+// Job is a unit of background work, stored so it survives restarts and runs on any instance. Failed
+// runs are retried with a growing delay; jobs failing MaxAttempts times are failed for good.
+type Job struct {
+	ID       string    `json:"id" bson:"_id" example:"0c4a7b52-1f3e-4d6a-9b8c-2e5f7a9d1c3b"`
+	TenantID string    `json:"-" bson:"tenant_id"`
+	Type     string    `json:"type" bson:"type" example:"email.send"`
+	Status   JobStatus `json:"status" bson:"status" example:"failed" enums:"queued,running,succeeded,failed"`
+	// Payload is the input of the job; it may hold secrets, such as the links of emails, so it is not shown
+	Payload     map[string]string `json:"-" bson:"payload,omitempty"`
+	Attempts    int               `json:"attempts" bson:"attempts" example:"5"`
+	MaxAttempts int               `json:"max_attempts" bson:"max_attempts" example:"5"`
+	LastError   string            `json:"last_error,omitempty" bson:"last_error,omitempty" example:"dial tcp 10.0.0.5:587: connect: connection refused"`
+	// RunAt is when the job is run next, or was last run
+	RunAt time.Time `json:"run_at" bson:"run_at" example:"2024-01-01T00:05:00Z"`
+	// LockedUntil is when a running job is given up as its worker stopped, and claimed again
+	LockedUntil *time.Time `json:"-" bson:"locked_until,omitempty"`
+	// UniqueKey, when set, is unique across the jobs so concurrent schedulers enqueue a job once
+	UniqueKey  string     `json:"-" bson:"unique_key,omitempty"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt  time.Time  `json:"updated_at" bson:"updated_at" example:"2024-01-01T00:05:01Z"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty" example:"2024-01-01T00:05:01Z"`
+	// ExpiresAt is when a succeeded job is removed by the TTL index
+	ExpiresAt *time.Time `json:"-" bson:"expires_at,omitempty"`
+}
+
+// NewJob returns a job of the type queued to run now
+func NewJob(jobType string, payload map[string]string, maxAttempts int) *Job {
+	now := time.Now()
+	return &Job{
+		ID:          uuid.New().String(),
+		Type:        jobType,
+		Status:      JobStatusQueued,
+		Payload:     payload,
+		MaxAttempts: maxAttempts,
+		RunAt:       now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+// Succeed records that the run of the job succeeded
+func (j *Job) Succeed(at time.Time) {
+	expiresAt := at.Add(JobSucceededTTL)
+	j.Status = JobStatusSucceeded
+	j.LastError = ""
+	j.LockedUntil = nil
+	j.UpdatedAt = at
+	j.FinishedAt = &at
+	j.ExpiresAt = &expiresAt
+}
+
+// Fail records that the run of the job failed with err: the job is queued again after retryDelay,
+// or failed for good once it used all its attempts
+func (j *Job) Fail(err error, at time.Time, retryDelay time.Duration) {
+	j.LastError = err.Error()
+	j.LockedUntil = nil
+	j.UpdatedAt = at
+	if j.Attempts >= j.MaxAttempts {
+		j.Status = JobStatusFailed
+		j.FinishedAt = &at
+		return
+	}
+	j.Status = JobStatusQueued
+	j.RunAt = at.Add(retryDelay)
+}
+
+// Retry queues a failed job again to run now, with all its attempts
+func (j *Job) Retry(at time.Time) {
+	j.Status = JobStatusQueued
+	j.Attempts = 0
+	j.RunAt = at
+	j.UpdatedAt = at
+	j.FinishedAt = nil
+}
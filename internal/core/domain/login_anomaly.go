@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rules comparing a login with the previous login of the user
+const (
+	// LoginRuleImpossibleTravel flags logins from too far away to have been reached since the
+	// previous login, at the speed of the policy
+	LoginRuleImpossibleTravel = "impossible_travel"
+	// LoginRuleNewCountry flags logins from another country than the previous login
+	LoginRuleNewCountry = "new_country"
+)
+
+// Actions taken on the logins flagged by a rule, each one adding to the previous
+const (
+	LoginAnomalyFlag      = "flag"      // Record the login in the audit log
+	LoginAnomalyNotify    = "notify"    // Also email the user
+	LoginAnomalyChallenge = "challenge" // Also ask for a second factor, even on a trusted device
+)
+
+// loginAnomalyActions are the actions from the mildest to the strictest
+var loginAnomalyActions = []string{LoginAnomalyFlag, LoginAnomalyNotify, LoginAnomalyChallenge}
+
+// impossibleTravelMinDistance is the distance in km below which logins are never impossible
+// travel, as IP locations are only accurate to the city or region
+const impossibleTravelMinDistance = 500
+
+var (
+	ErrInvalidLoginRule          = errors.New("invalid login rule: must be impossible_travel or new_country")
+	ErrInvalidLoginAnomalyAction = errors.New("invalid login anomaly action: must be flag, notify or challenge")
+)
+
+// LoginAnomalyPolicy is the rules checked on every login and the action each one takes
+type LoginAnomalyPolicy struct {
+	Rules          map[string]string // Action of each enabled rule
+	MaxTravelSpeed float64           // Fastest travel between two logins in km/h, for LoginRuleImpossibleTravel
+}
+
+// DefaultLoginAnomalyPolicy returns the policy used when no configuration is provided
+func DefaultLoginAnomalyPolicy() LoginAnomalyPolicy {
+	return LoginAnomalyPolicy{
+		Rules:          map[string]string{LoginRuleImpossibleTravel: LoginAnomalyNotify, LoginRuleNewCountry: LoginAnomalyFlag},
+		MaxTravelSpeed: 1000,
+	}
+}
+
+// ParseLoginAnomalyRules parses rules as in "impossible_travel=challenge,new_country=flag"; "none"
+// disables every rule
+func ParseLoginAnomalyRules(value string) (map[string]string, error) {
+	rules := map[string]string{}
+	if strings.TrimSpace(value) == "none" {
+		return rules, nil
+	}
+	for _, rule := range strings.Split(value, ",") {
+		name, action, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name != LoginRuleImpossibleTravel && name != LoginRuleNewCountry {
+			return nil, ErrInvalidLoginRule
+		}
+		if !slices.Contains(loginAnomalyActions, action) {
+			return nil, ErrInvalidLoginAnomalyAction
+		}
+		rules[name] = action
+	}
+	return rules, nil
+}
+
+// LoginAnomaly is a rule a login broke, with the locations and times compared
+type LoginAnomaly struct {
+	Rule    string
+	Action  string
+	Details map[string]string
+}
+
+// Evaluate compares a login with the previous login of the user, if any, and returns the rules it
+// breaks. Logins without location, or whose previous login has none, break no rule.
+func (p LoginAnomalyPolicy) Evaluate(previous, current *LoginEvent) []LoginAnomaly {
+	if previous == nil || previous.Location == nil || current.Location == nil {
+		return nil
+	}
+	details := func() map[string]string {
+		return map[string]string{
+			"ip":                current.IP,
+			"location":          current.Location.String(),
+			"previous_ip":       previous.IP,
+			"previous_location": previous.Location.String(),
+			"previous_login_at": previous.CreatedAt.UTC().Format(time.RFC3339),
+		}
+	}
+
+	var anomalies []LoginAnomaly
+	if action, ok := p.Rules[LoginRuleImpossibleTravel]; ok && previous.Location.Point != nil && current.Location.Point != nil {
+		distance := previous.Location.Point.Distance(*current.Location.Point)
+		hours := current.CreatedAt.Sub(previous.CreatedAt).Hours()
+		if distance >= impossibleTravelMinDistance && (hours <= 0 || distance/hours > p.MaxTravelSpeed) {
+			anomaly := LoginAnomaly{Rule: LoginRuleImpossibleTravel, Action: action, Details: details()}
+			anomaly.Details["distance_km"] = strconv.Itoa(int(distance))
+			anomalies = append(anomalies, anomaly)
+		}
+	}
+	if action, ok := p.Rules[LoginRuleNewCountry]; ok && previous.Location.Country != "" && current.Location.Country != "" &&
+		previous.Location.Country != current.Location.Country {
+		anomalies = append(anomalies, LoginAnomaly{Rule: LoginRuleNewCountry, Action: action, Details: details()})
+	}
+	return anomalies
+}
+
+// StrictestLoginAnomalyAction returns the strictest action of the anomalies, or "" if there are none
+func StrictestLoginAnomalyAction(anomalies []LoginAnomaly) string {
+	strictest := -1
+	for _, anomaly := range anomalies {
+		strictest = max(strictest, slices.Index(loginAnomalyActions, anomaly.Action))
+	}
+	if strictest < 0 {
+		return ""
+	}
+	return loginAnomalyActions[strictest]
+}
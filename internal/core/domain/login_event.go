@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/pkg/iso3166"
+	"github.com/google/uuid"
+)
+
+// LoginReportTTL is how long the "wasn't me" link of a new-device notification stays valid
+const LoginReportTTL = 7 * 24 * time.Hour
+
+var ErrInvalidLoginReport = errors.New("invalid or expired login report link")
+
+// LoginEvent records a successful login and the device it came from
+type LoginEvent struct {
+	ID        string `json:"id" bson:"_id,omitempty" example:"1b4e28ba-2fa1-11d2-883f-0016d3cca427"`
+	TenantID  string `json:"-" bson:"tenant_id,omitempty"`
+	UserID    string `json:"user_id" bson:"user_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	IP        string `json:"ip" bson:"ip" example:"203.0.113.7"`
+	UserAgent string `json:"user_agent,omitempty" bson:"user_agent,omitempty" example:"Mozilla/5.0"`
+	// Fingerprint identifies the device, see DeviceFingerprint
+	Fingerprint string `json:"-" bson:"fingerprint"`
+	// Location is set when the IP address could be located, see ports.GeoIPResolver
+	Location *IPLocation `json:"location,omitempty" bson:"location,omitempty"`
+	// NewDevice is set when the user had logged in before, but never from this device
+	NewDevice bool `json:"new_device" bson:"new_device"`
+	// ReportTokenHash is the SHA-256 of the token of the "wasn't me" link sent for new devices
+	ReportTokenHash string     `json:"-" bson:"report_token_hash,omitempty"`
+	ReportedAt      *time.Time `json:"reported_at,omitempty" bson:"reported_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	CreatedAt       time.Time  `json:"created_at" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// IPLocation is the approximate place an IP address is used from
+type IPLocation struct {
+	Country string    `json:"country,omitempty" bson:"country,omitempty" example:"BR"` // ISO 3166-1 alpha-2 code
+	City    string    `json:"city,omitempty" bson:"city,omitempty" example:"São Paulo"`
+	Point   *GeoPoint `json:"point,omitempty" bson:"point,omitempty"`
+}
+
+// String returns the city and country name, e.g. "São Paulo, Brazil"
+func (l IPLocation) String() string {
+	country := l.Country
+	if c, ok := iso3166.LookupCountry(l.Country); ok {
+		country = c.Name
+	}
+	if l.City == "" {
+		return country
+	}
+	if country == "" {
+		return l.City
+	}
+	return l.City + ", " + country
+}
+
+func NewLoginEvent(userID, ip, userAgent string) *LoginEvent {
+	return &LoginEvent{
+		ID:          uuid.New().String(),
+		UserID:      userID,
+		IP:          ip,
+		UserAgent:   userAgent,
+		Fingerprint: DeviceFingerprint(ip, userAgent),
+		CreatedAt:   time.Now(),
+	}
+}
+
+// DeviceFingerprint identifies a device by its user agent and network, the /24 of IPv4
+// addresses or the /48 of IPv6 ones, so address changes within a provider network are not new devices
+func DeviceFingerprint(ip, userAgent string) string {
+	network := ip
+	if parsed := net.ParseIP(ip); parsed != nil {
+		if v4 := parsed.To4(); v4 != nil {
+			network = v4.Mask(net.CIDRMask(24, 32)).String()
+		} else {
+			network = parsed.Mask(net.CIDRMask(48, 128)).String()
+		}
+	}
+	sum := sha256.Sum256([]byte(network + "\n" + strings.TrimSpace(userAgent)))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewLoginReportToken returns the token of a "wasn't me" link and the hash stored to check it.
+// The token starts with the tenant ID, as the link is opened outside of any tenant request.
+func NewLoginReportToken(tenantID string) (token, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	token = tenantID + "." + hex.EncodeToString(secret)
+	return token, HashLoginReportToken(token), nil
+}
+
+// ParseLoginReportToken returns the tenant ID carried by a "wasn't me" token
+func ParseLoginReportToken(token string) (string, error) {
+	tenantID, secret, found := strings.Cut(token, ".")
+	if !found || !ValidTenantID(tenantID) || len(secret) != 64 {
+		return "", ErrInvalidLoginReport
+	}
+	return tenantID, nil
+}
+
+func HashLoginReportToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidMergePolicy = errors.New("invalid merge policy: must be one of keep_primary, prefer_duplicate")
+	ErrMergeSameUser      = errors.New("invalid merge: a user cannot be merged into itself")
+)
+
+// Merge policies deciding which account wins when both set the same profile field
+const (
+	// MergePolicyKeepPrimary keeps the primary values and only fills its blank fields from the duplicate
+	MergePolicyKeepPrimary = "keep_primary"
+	// MergePolicyPreferDuplicate overwrites the primary values with the non-blank duplicate ones
+	MergePolicyPreferDuplicate = "prefer_duplicate"
+)
+
+// ValidMergePolicy reports whether policy is a known merge policy
+func ValidMergePolicy(policy string) bool {
+	return policy == MergePolicyKeepPrimary || policy == MergePolicyPreferDuplicate
+}
+
+// MergeUsers returns the primary user combined with the duplicate account according to policy.
+// Roles, tags and addresses are unioned; the email, password and ID of the primary are always kept.
+func MergeUsers(primary, duplicate *User, policy string) (*User, error) {
+	if policy == "" {
+		policy = MergePolicyKeepPrimary
+	}
+	if !ValidMergePolicy(policy) {
+		return nil, ErrInvalidMergePolicy
+	}
+	if primary.ID == duplicate.ID {
+		return nil, ErrMergeSameUser
+	}
+	preferDuplicate := policy == MergePolicyPreferDuplicate
+
+	merged := *primary
+	pick := func(primaryValue, duplicateValue string) string {
+		if duplicateValue != "" && (primaryValue == "" || preferDuplicate) {
+			return duplicateValue
+		}
+		return primaryValue
+	}
+	merged.Profile = Profile{
+		FirstName: pick(primary.Profile.FirstName, duplicate.Profile.FirstName),
+		LastName:  pick(primary.Profile.LastName, duplicate.Profile.LastName),
+		Phone:     pick(primary.Profile.Phone, duplicate.Profile.Phone),
+		Birthdate: pick(primary.Profile.Birthdate, duplicate.Profile.Birthdate),
+		NIN:       pick(primary.Profile.NIN, duplicate.Profile.NIN),
+		Timezone:  pick(primary.Profile.Timezone, duplicate.Profile.Timezone),
+		Locale:    pick(primary.Profile.Locale, duplicate.Profile.Locale),
+		Addresses: mergeAddresses(primary.Profile.Addresses, duplicate.Profile.Addresses),
+	}
+	if merged.Username == "" {
+		merged.Username = duplicate.Username
+	}
+
+	merged.Roles = unionStrings(primary.EffectiveRoles(), duplicate.EffectiveRoles())
+	merged.LegacyRole = ""
+	merged.Tags = unionStrings(primary.Tags, duplicate.Tags)
+
+	if len(primary.Metadata) > 0 || len(duplicate.Metadata) > 0 {
+		merged.Metadata = make(map[string]string, len(primary.Metadata)+len(duplicate.Metadata))
+		maps.Copy(merged.Metadata, primary.Metadata)
+		for key, value := range duplicate.Metadata {
+			if _, ok := merged.Metadata[key]; !ok || preferDuplicate {
+				merged.Metadata[key] = value
+			}
+		}
+	}
+
+	if merged.Avatar == nil {
+		merged.Avatar = duplicate.Avatar
+	}
+	if merged.Settings == nil {
+		merged.Settings = duplicate.Settings
+	}
+	if !duplicate.CreatedAt.IsZero() && duplicate.CreatedAt.Before(merged.CreatedAt) {
+		merged.CreatedAt = duplicate.CreatedAt
+	}
+	return &merged, nil
+}
+
+// mergeAddresses appends the duplicate addresses missing from the primary ones, up to MaxAddresses.
+// The primary address of the primary user stays primary.
+func mergeAddresses(primary, duplicate []Address) []Address {
+	addresses := slices.Clone(primary)
+	for _, address := range duplicate {
+		if len(addresses) >= MaxAddresses {
+			break
+		}
+		query := strings.ToLower(address.Query())
+		if slices.ContainsFunc(addresses, func(a Address) bool { return strings.ToLower(a.Query()) == query }) {
+			continue
+		}
+		address.Primary = false
+		addresses = append(addresses, address)
+	}
+	if len(primary) == 0 && len(addresses) > 0 {
+		addresses[0].Primary = true
+	}
+	return addresses
+}
+
+// unionStrings returns a followed by the values of b it does not contain
+func unionStrings(a, b []string) []string {
+	union := slices.Clone(a)
+	for _, value := range b {
+		if !slices.Contains(union, value) {
+			union = append(union, value)
+		}
+	}
+	return union
+}
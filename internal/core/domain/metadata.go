@@ -0,0 +1,80 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+)
+
+var (
+	ErrInvalidMetadataKey    = errors.New("invalid metadata key: use 1-64 letters, digits, underscores or dashes")
+	ErrMetadataKeyNotAllowed = errors.New("metadata key is not allowed")
+	ErrMetadataValueTooLong  = errors.New("metadata value exceeds the maximum length")
+	ErrTooManyMetadataKeys   = errors.New("too many metadata keys")
+)
+
+// metadataKeyPattern restricts keys to characters that are safe to use in MongoDB field paths
+var metadataKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// MetadataPolicy limits the custom metadata admins can attach to users
+type MetadataPolicy struct {
+	AllowedKeys    []string // Empty means any valid key is accepted
+	MaxValueLength int      // Maximum value length in bytes
+	MaxKeys        int      // Maximum number of keys per user
+}
+
+// DefaultMetadataPolicy returns the policy used when no configuration is provided
+func DefaultMetadataPolicy() MetadataPolicy {
+	return MetadataPolicy{
+		MaxValueLength: 512,
+		MaxKeys:        50,
+	}
+}
+
+// ValidMetadataKey reports whether key can be used as a metadata key
+func ValidMetadataKey(key string) bool {
+	return metadataKeyPattern.MatchString(key)
+}
+
+// ValidateKey checks the key format and the allowed-key list
+func (p MetadataPolicy) ValidateKey(key string) error {
+	if !ValidMetadataKey(key) {
+		return ErrInvalidMetadataKey
+	}
+	if len(p.AllowedKeys) == 0 {
+		return nil
+	}
+	for _, allowed := range p.AllowedKeys {
+		if key == allowed {
+			return nil
+		}
+	}
+	return ErrMetadataKeyNotAllowed
+}
+
+// ApplyChanges validates the changes and returns the resulting metadata.
+// A nil value removes the key.
+func (p MetadataPolicy) ApplyChanges(current map[string]string, changes map[string]*string) (map[string]string, error) {
+	result := make(map[string]string, len(current)+len(changes))
+	for key, value := range current {
+		result[key] = value
+	}
+
+	for key, value := range changes {
+		if err := p.ValidateKey(key); err != nil {
+			return nil, err
+		}
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		if len(*value) > p.MaxValueLength {
+			return nil, ErrMetadataValueTooLong
+		}
+		result[key] = *value
+	}
+
+	if len(result) > p.MaxKeys {
+		return nil, ErrTooManyMetadataKeys
+	}
+	return result, nil
+}
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Second factor types: codes of an authenticator app (TOTP, RFC 6238) or texted to a verified phone
+const (
+	MFAFactorTOTP = "totp"
+	MFAFactorSMS  = "sms"
+)
+
+// MaxMFAFactors and MaxMFAFactorLabelLength bound the second factors of a user
+const (
+	MaxMFAFactors           = 5
+	MaxMFAFactorLabelLength = 64
+)
+
+var (
+	ErrInvalidMFAFactorType  = errors.New("invalid mfa factor type: must be totp or sms")
+	ErrInvalidMFAFactorLabel = errors.New("invalid mfa factor label: at most 64 characters")
+	ErrTooManyMFAFactors     = errors.New("invalid mfa factor: a user has at most 5 second factors")
+	ErrMFAPhoneUnverified    = errors.New("invalid mfa factor: verify the phone of the profile before using it as a second factor")
+	ErrMFAFactorExists       = errors.New("mfa factor conflict: the phone is already a second factor")
+	ErrMFAFactorConfirmed    = errors.New("mfa factor conflict: the factor is already confirmed")
+	ErrMFAFactorNotFound     = errors.New("mfa factor not found")
+	ErrMFAFactorNotSMS       = errors.New("invalid mfa factor: only sms factors are sent codes")
+	ErrInvalidMFACode        = errors.New("invalid mfa code")
+)
+
+// MFAFactor is a second factor of a user. TOTP factors are only used once confirmed with a first
+// code of the app; SMS factors texting a verified phone are confirmed when added.
+type MFAFactor struct {
+	ID          string     `json:"id" bson:"id" example:"3f2b8c1e-6d4a-4e9b-9c7f-1a2b3c4d5e6f"`
+	Type        string     `json:"type" bson:"type" example:"totp" enums:"totp,sms"`
+	Label       string     `json:"label,omitempty" bson:"label,omitempty" example:"Work phone"`
+	Phone       string     `json:"phone,omitempty" bson:"phone,omitempty" example:"+15551234567"`
+	CreatedAt   time.Time  `json:"created_at" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty" bson:"confirmed_at,omitempty" example:"2024-01-01T00:01:00Z"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty" bson:"last_used_at,omitempty" example:"2024-01-02T00:00:00Z"`
+	// Secret is the base32 TOTP secret shared with the authenticator app
+	Secret string `json:"-" bson:"secret,omitempty"`
+	// LastUsedStep is the TOTP time step of the last code accepted, which cannot be used again
+	LastUsedStep int64 `json:"-" bson:"last_used_step,omitempty"`
+	// Challenge is the code last texted by an SMS factor, pending confirmation
+	Challenge *PhoneChallenge `json:"-" bson:"challenge,omitempty"`
+}
+
+// MFAEnrollment is a factor just added, with the secret of TOTP factors shown once to enroll them in
+// an authenticator app
+type MFAEnrollment struct {
+	Factor MFAFactor `json:"factor"`
+	Secret string    `json:"secret,omitempty" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	// URI is the otpauth:// URI of the secret, usually shown as a QR code
+	URI string `json:"uri,omitempty" example:"otpauth://totp/User%20Management%20API:john.doe@example.com?algorithm=SHA1&digits=6&issuer=User+Management+API&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+}
+
+// NewMFAFactor creates an unconfirmed factor of a type
+func NewMFAFactor(factorType, label string) (MFAFactor, error) {
+	if factorType != MFAFactorTOTP && factorType != MFAFactorSMS {
+		return MFAFactor{}, ErrInvalidMFAFactorType
+	}
+	label, err := NormalizeMFAFactorLabel(label)
+	if err != nil {
+		return MFAFactor{}, err
+	}
+	return MFAFactor{
+		ID:        uuid.New().String(),
+		Type:      factorType,
+		Label:     label,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// NormalizeMFAFactorLabel trims a label and checks its length
+func NormalizeMFAFactorLabel(label string) (string, error) {
+	label = strings.TrimSpace(label)
+	if utf8.RuneCountInString(label) > MaxMFAFactorLabelLength {
+		return "", ErrInvalidMFAFactorLabel
+	}
+	return label, nil
+}
+
+// Confirmed reports whether the factor can be used to log in
+func (f MFAFactor) Confirmed() bool {
+	return f.ConfirmedAt != nil
+}
+
+// MFAEnabled reports whether the user logs in with a second factor
+func (u *User) MFAEnabled() bool {
+	return slices.ContainsFunc(u.MFAFactors, MFAFactor.Confirmed)
+}
+
+// MFAFactor returns the index of the factor of the user with the given ID, or -1
+func (u *User) MFAFactor(id string) int {
+	return slices.IndexFunc(u.MFAFactors, func(f MFAFactor) bool { return f.ID == id })
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidOrganizationName = errors.New("invalid organization name")
+	ErrInvalidOrganizationSlug = errors.New("invalid organization slug: use 3-48 lowercase letters, digits or dashes")
+	ErrInvalidMembershipRole   = errors.New("invalid membership role: must be one of owner, admin, member")
+)
+
+// Membership roles, from most to least privileged
+const (
+	MembershipRoleOwner  = "owner"
+	MembershipRoleAdmin  = "admin"
+	MembershipRoleMember = "member"
+)
+
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,46}[a-z0-9]$`)
+
+type Organization struct {
+	ID        string    `json:"id" bson:"_id,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	TenantID  string    `json:"-" bson:"tenant_id,omitempty"`
+	Name      string    `json:"name" bson:"name,omitempty" example:"Acme Corp"`
+	Slug      string    `json:"slug" bson:"slug,omitempty" example:"acme-corp"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"`
+}
+
+// Membership links a user to an organization with a role
+type Membership struct {
+	ID             string    `json:"id" bson:"_id,omitempty" example:"9b2f6a1e-3c4d-4e5f-8a9b-0c1d2e3f4a5b"`
+	TenantID       string    `json:"-" bson:"tenant_id,omitempty"`
+	OrganizationID string    `json:"organization_id" bson:"organization_id,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	UserID         string    `json:"user_id" bson:"user_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Role           string    `json:"role" bson:"role,omitempty" example:"member"`
+	CreatedAt      time.Time `json:"created_at" bson:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt      time.Time `json:"updated_at" bson:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"`
+}
+
+func NewOrganization(name, slug string) (*Organization, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, ErrInvalidOrganizationName
+	}
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	if !slugPattern.MatchString(slug) {
+		return nil, ErrInvalidOrganizationSlug
+	}
+
+	return &Organization{
+		ID:        uuid.New().String(),
+		Name:      name,
+		Slug:      slug,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}, nil
+}
+
+func NewMembership(organizationID, userID, role string) (*Membership, error) {
+	if !ValidMembershipRole(role) {
+		return nil, ErrInvalidMembershipRole
+	}
+
+	return &Membership{
+		ID:             uuid.New().String(),
+		OrganizationID: organizationID,
+		UserID:         userID,
+		Role:           role,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}, nil
+}
+
+// ValidMembershipRole reports whether role is a known membership role
+func ValidMembershipRole(role string) bool {
+	return role == MembershipRoleOwner || role == MembershipRoleAdmin || role == MembershipRoleMember
+}
+
+// CanManage reports whether the member may change the organization and its memberships
+func (m *Membership) CanManage() bool {
+	return m.Role == MembershipRoleOwner || m.Role == MembershipRoleAdmin
+}
+
+// HigherMembershipRole returns the more privileged of two membership roles
+func HigherMembershipRole(a, b string) string {
+	rank := map[string]int{MembershipRoleMember: 1, MembershipRoleAdmin: 2, MembershipRoleOwner: 3}
+	if rank[b] > rank[a] {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"errors"
+	"unicode/utf8"
+
+	"github.com/frtasoniero/user-management-api/pkg/security"
+)
+
+// MaxPasswordBytes is the longest password bcrypt hashes without truncating it
+const MaxPasswordBytes = 72
+
+var (
+	ErrPasswordTooShort = errors.New("invalid password: shorter than the minimum length")
+	ErrPasswordTooLong  = errors.New("invalid password: longer than 72 bytes")
+	ErrPasswordTooWeak  = errors.New("invalid password: too easy to guess")
+	ErrPasswordBreached = errors.New("invalid password: found in known data breaches")
+)
+
+// PasswordPolicy is the rules passwords are chosen with
+type PasswordPolicy struct {
+	MinLength int // Minimum length in characters
+	MinScore  int // Minimum strength score, from 0 to 4; 0 accepts any password of the minimum length
+}
+
+// DefaultPasswordPolicy returns the policy used when no configuration is provided
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{MinLength: 6}
+}
+
+// PasswordEvaluation is the strength of a password and the verdict of the policy on it
+type PasswordEvaluation struct {
+	Strength security.Strength
+	Policy   PasswordPolicy
+	Err      error // Rule of the policy the password breaks, nil when the password is accepted
+	// Breaches is how many times the password was seen in known data breaches, 0 when never or not checked
+	Breaches int
+}
+
+// Evaluate estimates the strength of password and checks it against the policy. The userInputs,
+// such as the email address and name of the account, make the passwords based on them weaker.
+func (p PasswordPolicy) Evaluate(password string, userInputs ...string) PasswordEvaluation {
+	evaluation := PasswordEvaluation{Strength: security.EstimateStrength(password, userInputs...), Policy: p}
+	switch {
+	case utf8.RuneCountInString(password) < p.MinLength:
+		evaluation.Err = ErrPasswordTooShort
+	case len(password) > MaxPasswordBytes:
+		evaluation.Err = ErrPasswordTooLong
+	case evaluation.Strength.Score < p.MinScore:
+		evaluation.Err = ErrPasswordTooWeak
+	}
+	return evaluation
+}
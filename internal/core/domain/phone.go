@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// PhoneCodeLength and MaxPhoneCodeAttempts bound the one-time codes verifying phone numbers
+const (
+	PhoneCodeLength      = 6
+	MaxPhoneCodeAttempts = 5
+)
+
+var (
+	ErrInvalidPhone         = errors.New("invalid phone: must be an international number, such as +1-555-123-4567")
+	ErrPhoneMissing         = errors.New("invalid phone: the profile has no phone number to verify")
+	ErrPhoneAlreadyVerified = errors.New("phone conflict: the phone number is already verified")
+	ErrInvalidPhoneCode     = errors.New("invalid phone code: the code does not match")
+	ErrPhoneCodeExpired     = errors.New("invalid phone code: no code pending or expired, request a new one")
+	ErrPhoneCodeAttempts    = errors.New("invalid phone code: too many attempts, request a new one")
+	ErrPhoneCodeTooFrequent = errors.New("too many requests: wait before requesting another phone code")
+	ErrSMSRateLimited       = errors.New("too many requests: too many text messages sent to the phone, try again later")
+)
+
+// PhoneChallenge is the one-time code last sent to verify the phone number of a user
+type PhoneChallenge struct {
+	Phone     string    `bson:"phone"`
+	CodeHash  string    `bson:"code_hash"`
+	SentAt    time.Time `bson:"sent_at"`
+	ExpiresAt time.Time `bson:"expires_at"`
+	Attempts  int       `bson:"attempts"`
+}
+
+// NormalizePhone returns the E.164 form of an international phone number, dropping the spaces,
+// dashes, dots and parentheses between its digits
+func NormalizePhone(phone string) (string, error) {
+	phone = strings.TrimSpace(phone)
+	if !strings.HasPrefix(phone, "+") {
+		return "", ErrInvalidPhone
+	}
+	var digits strings.Builder
+	for _, r := range phone[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case strings.ContainsRune(" -.()", r):
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	// E.164 numbers have up to 15 digits and country codes never start with 0
+	if digits.Len() < 8 || digits.Len() > 15 || strings.HasPrefix(digits.String(), "0") {
+		return "", ErrInvalidPhone
+	}
+	return "+" + digits.String(), nil
+}
+
+// Expired reports whether the code can no longer be confirmed at now
+func (c *PhoneChallenge) Expired(now time.Time) bool {
+	return c == nil || !now.Before(c.ExpiresAt)
+}
@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrInvalidTimezone = errors.New("invalid timezone: must be an IANA time zone name")
+	ErrInvalidLocale   = errors.New("invalid locale: must be a BCP 47 language tag")
+)
+
+// Validate checks the birthdate, timezone, locale and addresses, canonicalizes the locale tag
+// and normalizes the addresses, see NormalizeAddresses
+func (p *Profile) Validate() error {
+	for i := range p.Addresses {
+		if err := p.Addresses[i].Validate(); err != nil {
+			return prefixField(fmt.Sprintf("profile.addresses[%d]", i), err)
+		}
+	}
+	addresses, err := NormalizeAddresses(p.Addresses)
+	if err != nil {
+		return err
+	}
+	p.Addresses = addresses
+
+	if p.Birthdate != "" {
+		if _, err := ParseBirthdate(p.Birthdate, time.Now()); err != nil {
+			return &FieldError{Field: "profile.birthdate", Err: err}
+		}
+	}
+	if p.Timezone != "" {
+		// LoadLocation also accepts "Local", which depends on the server and is not a zone name
+		if _, err := time.LoadLocation(p.Timezone); err != nil || p.Timezone == "Local" {
+			return ErrInvalidTimezone
+		}
+	}
+	if p.Locale != "" {
+		tag, err := language.Parse(p.Locale)
+		if err != nil {
+			return ErrInvalidLocale
+		}
+		p.Locale = tag.String()
+	}
+	return nil
+}
+
+// FillNames sets the blank names of the profile to the local part of email; the database requires
+// both names, which the systems creating accounts on behalf of users may not know
+func (p *Profile) FillNames(email string) {
+	local, _, _ := strings.Cut(email, "@")
+	if p.FirstName == "" {
+		p.FirstName = local
+	}
+	if p.LastName == "" {
+		p.LastName = local
+	}
+}
+
+// ProfileUpdate is a partial profile change; nil fields are left untouched and addresses are
+// managed through their own endpoints
+type ProfileUpdate struct {
+	FirstName *string `json:"first_name,omitempty" example:"John"`
+	LastName  *string `json:"last_name,omitempty" example:"Doe"`
+	Phone     *string `json:"phone,omitempty" example:"+1-555-123-4567"`
+	Birthdate *string `json:"birthdate,omitempty" example:"1990-05-15"`
+	Timezone  *string `json:"timezone,omitempty" example:"America/New_York"`
+	Locale    *string `json:"locale,omitempty" example:"en-US"`
+}
+
+// Apply returns a copy of the profile with the update applied, validated as Profile.Validate does
+func (u ProfileUpdate) Apply(profile Profile) (Profile, error) {
+	if u.FirstName != nil {
+		profile.FirstName = strings.TrimSpace(*u.FirstName)
+	}
+	if u.LastName != nil {
+		profile.LastName = strings.TrimSpace(*u.LastName)
+	}
+	if u.Phone != nil {
+		profile.Phone = strings.TrimSpace(*u.Phone)
+	}
+	if u.Birthdate != nil {
+		profile.Birthdate = strings.TrimSpace(*u.Birthdate)
+	}
+	if u.Timezone != nil {
+		profile.Timezone = strings.TrimSpace(*u.Timezone)
+	}
+	if u.Locale != nil {
+		profile.Locale = strings.TrimSpace(*u.Locale)
+	}
+	if err := profile.Validate(); err != nil {
+		// Updates carry the profile fields at the top level of the body
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			return Profile{}, &FieldError{Field: strings.TrimPrefix(fieldErr.Field, "profile."), Err: fieldErr.Err}
+		}
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// Location returns the time zone timestamps shown to the user are rendered in, UTC when unset
+func (p Profile) Location() *time.Location {
+	if p.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// LanguageTag returns the locale messages sent to the user are written in, English when unset
+func (p Profile) LanguageTag() language.Tag {
+	tag, err := language.Parse(p.Locale)
+	if err != nil {
+		return language.English
+	}
+	return tag
+}
+
+// LocalTime renders t in the user time zone
+func (p Profile) LocalTime(t time.Time) time.Time {
+	return t.In(p.Location())
+}
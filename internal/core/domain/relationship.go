@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFollowSelf = errors.New("invalid relationship: users cannot follow themselves")
+	ErrBlockSelf  = errors.New("invalid block: users cannot block themselves")
+	// ErrRelationshipExists is returned when a relationship is created twice
+	ErrRelationshipExists = errors.New("relationship already exists")
+	// ErrBlockExists is returned when a block is created twice
+	ErrBlockExists = errors.New("block already exists")
+)
+
+// Relationship records that the follower follows the followee
+type Relationship struct {
+	ID         string    `json:"id" bson:"_id,omitempty" example:"3f2b8c1d-6e4a-4b7f-9c2d-8a1e5f7b9c3d"`
+	TenantID   string    `json:"-" bson:"tenant_id,omitempty"`
+	FollowerID string    `json:"follower_id" bson:"follower_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	FolloweeID string    `json:"followee_id" bson:"followee_id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+// RelationshipCounts are the numbers of users following a user and followed by it
+type RelationshipCounts struct {
+	Followers int64 `json:"followers" example:"120"`
+	Following int64 `json:"following" example:"80"`
+}
+
+func NewRelationship(followerID, followeeID string) (*Relationship, error) {
+	if followerID == followeeID {
+		return nil, ErrFollowSelf
+	}
+
+	return &Relationship{
+		ID:         uuid.New().String(),
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		CreatedAt:  time.Now(),
+	}, nil
+}
+
+// Block records that the blocker blocked the blocked user, which can then neither view the blocker
+// nor follow it
+type Block struct {
+	ID        string    `json:"id" bson:"_id,omitempty" example:"9b1deb4d-3b7d-4bad-9bdd-2b0d7b3dcb6d"`
+	TenantID  string    `json:"-" bson:"tenant_id,omitempty"`
+	BlockerID string    `json:"blocker_id" bson:"blocker_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	BlockedID string    `json:"blocked_id" bson:"blocked_id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+}
+
+func NewBlock(blockerID, blockedID string) (*Block, error) {
+	if blockerID == blockedID {
+		return nil, ErrBlockSelf
+	}
+
+	return &Block{
+		ID:        uuid.New().String(),
+		BlockerID: blockerID,
+		BlockedID: blockedID,
+		CreatedAt: time.Now(),
+	}, nil
+}
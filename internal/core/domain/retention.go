@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidRetentionAction = errors.New("invalid retention action: must be delete or anonymize")
+
+// Actions of the retention purge on soft-deleted users past their retention window
+const (
+	RetentionDelete    = "delete"    // Remove the user document
+	RetentionAnonymize = "anonymize" // Keep the document without personal data, see User.Anonymize
+)
+
+// AnonymizedEmailDomain is the domain of the emails of anonymized users, reserved by RFC 2606
+const AnonymizedEmailDomain = "anonymized.invalid"
+
+// RetentionPolicy is how long data is kept before the retention purge removes it; zero windows
+// keep the data forever
+type RetentionPolicy struct {
+	// DeletedUsers is how long soft-deleted users are kept, then removed as DeletedUsersAction says
+	DeletedUsers       time.Duration
+	DeletedUsersAction string
+	// LoginEvents is how long the login history is kept
+	LoginEvents time.Duration
+}
+
+func (p RetentionPolicy) Validate() error {
+	if p.DeletedUsersAction != RetentionDelete && p.DeletedUsersAction != RetentionAnonymize {
+		return ErrInvalidRetentionAction
+	}
+	return nil
+}
+
+// Anonymize replaces the personal data of a soft-deleted user, keeping its ID, tenant, roles and
+// the times it was created, deactivated and deleted at, so references to it stay valid. Its email
+// is unique to the user, keeping the unique indexes satisfied, and matches no mailbox.
+func (u *User) Anonymize(at time.Time) {
+	email := "deleted-" + u.ID + "@" + AnonymizedEmailDomain
+	*u = User{
+		ID:              u.ID,
+		TenantID:        u.TenantID,
+		Email:           email,
+		NormalizedEmail: email,
+		PasswordHash:    NoPasswordHash,
+		Roles:           u.Roles,
+		Profile:         Profile{FirstName: "Deleted", LastName: "User"},
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       at,
+		DeletedAt:       u.DeletedAt,
+		MergedInto:      u.MergedInto,
+		DeactivatedAt:   u.DeactivatedAt,
+		AnonymizedAt:    &at,
+	}
+}
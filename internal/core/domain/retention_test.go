@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicyValidate(t *testing.T) {
+	tests := []struct {
+		action string
+		want   error
+	}{
+		{action: RetentionDelete},
+		{action: RetentionAnonymize},
+		{action: "", want: ErrInvalidRetentionAction},
+		{action: "archive", want: ErrInvalidRetentionAction},
+	}
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			if err := (RetentionPolicy{DeletedUsersAction: tt.action}).Validate(); err != tt.want {
+				t.Errorf("Validate() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserAnonymize(t *testing.T) {
+	created := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	deleted := created.AddDate(0, 6, 0)
+	now := deleted.AddDate(0, 1, 0)
+	user := User{
+		ID:              "550e8400-e29b-41d4-a716-446655440000",
+		TenantID:        "acme",
+		Email:           "John.Doe@example.com",
+		NormalizedEmail: "john.doe@example.com",
+		Username:        "johndoe",
+		PasswordHash:    "$2a$10$abcdefghijklmnopqrstuv",
+		Roles:           []string{RoleAdmin},
+		Profile:         Profile{FirstName: "John", LastName: "Doe", Phone: "+14155550100"},
+		Metadata:        map[string]string{"plan": "pro"},
+		Tags:            []string{"vip"},
+		CreatedAt:       created,
+		UpdatedAt:       deleted,
+		LastSeenAt:      &deleted,
+		DeletedAt:       &deleted,
+		MergedInto:      "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+		DirectoryID:     "cn=john,ou=people",
+	}
+
+	user.Anonymize(now)
+	want := User{
+		ID:              "550e8400-e29b-41d4-a716-446655440000",
+		TenantID:        "acme",
+		Email:           "deleted-550e8400-e29b-41d4-a716-446655440000@anonymized.invalid",
+		NormalizedEmail: "deleted-550e8400-e29b-41d4-a716-446655440000@anonymized.invalid",
+		PasswordHash:    NoPasswordHash,
+		Roles:           []string{RoleAdmin},
+		Profile:         Profile{FirstName: "Deleted", LastName: "User"},
+		CreatedAt:       created,
+		UpdatedAt:       now,
+		DeletedAt:       &deleted,
+		MergedInto:      "7c9e6679-7425-40de-944b-e07fc1f90ae7",
+		AnonymizedAt:    &now,
+	}
+	if !reflect.DeepEqual(user, want) {
+		t.Errorf("Anonymize() = %+v, want %+v", user, want)
+	}
+}
@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidRoleName   = errors.New("invalid role name: use 2-32 lowercase letters, digits, underscores or dashes")
+	ErrInvalidPermission = errors.New("invalid permission")
+)
+
+// Built-in role names
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Permissions checked by the API; PermissionAll grants every permission
+const (
+	PermissionAll              = "*"
+	PermissionUsersMeta        = "users:metadata"
+	PermissionUsersTags        = "users:tags"
+	PermissionUsersImpersonate = "users:impersonate"
+	PermissionUsersMerge       = "users:merge"
+	PermissionUsersActivity    = "users:activity"
+	PermissionUsersExport      = "users:export"
+	PermissionUsersSync        = "users:sync"
+	PermissionUsersDocuments   = "users:documents"
+	PermissionUsersVerify      = "users:verify"
+	PermissionUsersProfile     = "users:profile"
+	PermissionUsersBatch       = "users:batch"
+	PermissionUsersHistory     = "users:history"
+	PermissionRolesManage      = "roles:manage"
+	PermissionRolesAssign      = "roles:assign"
+	PermissionAuditRead        = "audit:read"
+	PermissionSecurityManage   = "security:manage"
+	PermissionSystemRead       = "system:read"
+	PermissionSystemDebug      = "system:debug"
+	PermissionSystemConfig     = "system:config"
+	PermissionTermsManage      = "terms:manage"
+	PermissionJobsManage       = "jobs:manage"
+)
+
+// Permissions lists every permission that can be attached to a role
+var Permissions = []string{
+	PermissionAll,
+	PermissionUsersMeta,
+	PermissionUsersTags,
+	PermissionUsersImpersonate,
+	PermissionUsersMerge,
+	PermissionUsersActivity,
+	PermissionUsersExport,
+	PermissionUsersSync,
+	PermissionUsersDocuments,
+	PermissionUsersVerify,
+	PermissionUsersProfile,
+	PermissionUsersBatch,
+	PermissionUsersHistory,
+	PermissionRolesManage,
+	PermissionRolesAssign,
+	PermissionAuditRead,
+	PermissionSecurityManage,
+	PermissionSystemRead,
+	PermissionSystemDebug,
+	PermissionSystemConfig,
+	PermissionTermsManage,
+	PermissionJobsManage,
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{1,31}$`)
+
+// Role is a named set of permissions assigned to users
+type Role struct {
+	ID          string    `json:"id" bson:"_id,omitempty" example:"3f2b8c1a-5d6e-4f70-8a9b-1c2d3e4f5a6b"`
+	TenantID    string    `json:"-" bson:"tenant_id,omitempty"`
+	Name        string    `json:"name" bson:"name,omitempty" example:"support"`
+	Description string    `json:"description" bson:"description" example:"Support agents"`
+	Permissions []string  `json:"permissions" bson:"permissions" example:"users:tags"`
+	System      bool      `json:"system" bson:"-" example:"false"`
+	CreatedAt   time.Time `json:"created_at" bson:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"`
+}
+
+// SystemRoles returns the built-in roles available in every tenant; they cannot be changed or deleted
+func SystemRoles() []*Role {
+	return []*Role{
+		{Name: RoleAdmin, Description: "Full administrative access", Permissions: []string{PermissionAll}, System: true},
+		{Name: RoleUser, Description: "Regular user", Permissions: []string{}, System: true},
+	}
+}
+
+// SystemRole returns the built-in role with the given name, or nil if there is none
+func SystemRole(name string) *Role {
+	for _, role := range SystemRoles() {
+		if role.Name == name {
+			return role
+		}
+	}
+	return nil
+}
+
+func NewRole(name, description string, permissions []string) (*Role, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, ErrInvalidRoleName
+	}
+	normalized, err := NormalizePermissions(permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Role{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: strings.TrimSpace(description),
+		Permissions: normalized,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}, nil
+}
+
+// NormalizePermissions validates the permissions against the catalogue and drops duplicates
+func NormalizePermissions(permissions []string) ([]string, error) {
+	normalized := make([]string, 0, len(permissions))
+	for _, permission := range permissions {
+		if !slices.Contains(Permissions, permission) {
+			return nil, ErrInvalidPermission
+		}
+		if !slices.Contains(normalized, permission) {
+			normalized = append(normalized, permission)
+		}
+	}
+	return normalized, nil
+}
+
+// Grants reports whether the role includes the permission
+func (r *Role) Grants(permission string) bool {
+	return slices.Contains(r.Permissions, PermissionAll) || slices.Contains(r.Permissions, permission)
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+)
+
+var ErrInvalidTokenScope = errors.New("invalid scope: must be users:read, users:write or admin")
+
+// Scopes restricting the routes a token can be used on, whatever the permissions of its user.
+// Tokens issued without scopes are not restricted; scopes never grant a permission.
+const (
+	ScopeUsersRead  = "users:read"  // Reading the caller account and organizations
+	ScopeUsersWrite = "users:write" // Changing the caller account and organizations
+	ScopeAdmin      = "admin"       // The admin routes, also guarded by their permission
+)
+
+// TokenScopes lists every scope a token can be restricted to
+var TokenScopes = []string{ScopeUsersRead, ScopeUsersWrite, ScopeAdmin}
+
+// NormalizeTokenScopes validates the scopes against TokenScopes and drops duplicates
+func NormalizeTokenScopes(scopes []string) ([]string, error) {
+	normalized := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !slices.Contains(TokenScopes, scope) {
+			return nil, ErrInvalidTokenScope
+		}
+		if !slices.Contains(normalized, scope) {
+			normalized = append(normalized, scope)
+		}
+	}
+	return normalized, nil
+}
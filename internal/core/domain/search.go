@@ -0,0 +1,187 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSearch = errors.New("invalid search query")
+
+// Search operators supported by SearchFilter conditions
+const (
+	SearchOpEq       = "eq"
+	SearchOpNe       = "ne"
+	SearchOpIn       = "in"
+	SearchOpContains = "contains"
+	SearchOpPrefix   = "prefix"
+	SearchOpGt       = "gt"
+	SearchOpGte      = "gte"
+	SearchOpLt       = "lt"
+	SearchOpLte      = "lte"
+	SearchOpExists   = "exists"
+)
+
+const (
+	// MaxSearchDepth bounds the nesting of and/or groups
+	MaxSearchDepth = 5
+	// MaxSearchConditions bounds the total number of conditions in a query
+	MaxSearchConditions = 50
+	// MaxSearchValues bounds the number of values of an "in" condition
+	MaxSearchValues = 100
+	// MaxAutocompleteLength bounds the prefixes of the autocomplete, in characters
+	MaxAutocompleteLength = 100
+)
+
+// SearchFieldType describes how values of a searchable field are interpreted
+type SearchFieldType int
+
+const (
+	SearchFieldString SearchFieldType = iota
+	SearchFieldTime
+	SearchFieldList
+)
+
+// searchFields lists the user fields that can be searched, keyed by their JSON path
+var searchFields = map[string]SearchFieldType{
+	"email":                      SearchFieldString,
+	"username":                   SearchFieldString,
+	"roles":                      SearchFieldList,
+	"tags":                       SearchFieldList,
+	"created_at":                 SearchFieldTime,
+	"updated_at":                 SearchFieldTime,
+	"profile.first_name":         SearchFieldString,
+	"profile.last_name":          SearchFieldString,
+	"profile.phone":              SearchFieldString,
+	"profile.birthdate":          SearchFieldString,
+	"profile.timezone":           SearchFieldString,
+	"profile.locale":             SearchFieldString,
+	"profile.addresses.type":     SearchFieldString,
+	"profile.addresses.street":   SearchFieldString,
+	"profile.addresses.city":     SearchFieldString,
+	"profile.addresses.state":    SearchFieldString,
+	"profile.addresses.country":  SearchFieldString,
+	"profile.addresses.zip_code": SearchFieldString,
+}
+
+// SearchField returns the type of a searchable field; metadata.<key> fields are strings
+func SearchField(field string) (SearchFieldType, bool) {
+	if key, found := strings.CutPrefix(field, "metadata."); found {
+		return SearchFieldString, ValidMetadataKey(key)
+	}
+	t, ok := searchFields[field]
+	return t, ok
+}
+
+// SearchFilter is a node of a structured user search.
+// A node is either a group (And or Or, never both) or a single condition on Field.
+type SearchFilter struct {
+	And   []SearchFilter `json:"and,omitempty"`
+	Or    []SearchFilter `json:"or,omitempty"`
+	Field string         `json:"field,omitempty" example:"created_at"`
+	Op    string         `json:"op,omitempty" example:"gte"`
+	Value any            `json:"value,omitempty" swaggertype:"string" example:"2024-01-01T00:00:00Z"`
+}
+
+// IsGroup reports whether the node combines other nodes
+func (f *SearchFilter) IsGroup() bool {
+	return len(f.And) > 0 || len(f.Or) > 0
+}
+
+// Validate checks the structure, fields, operators and value types of the whole filter tree
+func (f *SearchFilter) Validate() error {
+	conditions := 0
+	return f.validate(1, &conditions)
+}
+
+func (f *SearchFilter) validate(depth int, conditions *int) error {
+	if depth > MaxSearchDepth {
+		return fmt.Errorf("%w: groups nested deeper than %d levels", ErrInvalidSearch, MaxSearchDepth)
+	}
+
+	if f.IsGroup() {
+		if len(f.And) > 0 && len(f.Or) > 0 {
+			return fmt.Errorf("%w: a group cannot combine and/or, nest them instead", ErrInvalidSearch)
+		}
+		if f.Field != "" || f.Op != "" || f.Value != nil {
+			return fmt.Errorf("%w: a group cannot also be a condition", ErrInvalidSearch)
+		}
+		for i := range f.And {
+			if err := f.And[i].validate(depth+1, conditions); err != nil {
+				return err
+			}
+		}
+		for i := range f.Or {
+			if err := f.Or[i].validate(depth+1, conditions); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	*conditions++
+	if *conditions > MaxSearchConditions {
+		return fmt.Errorf("%w: more than %d conditions", ErrInvalidSearch, MaxSearchConditions)
+	}
+	return f.validateCondition()
+}
+
+func (f *SearchFilter) validateCondition() error {
+	fieldType, ok := SearchField(f.Field)
+	if !ok {
+		return fmt.Errorf("%w: field %q cannot be searched", ErrInvalidSearch, f.Field)
+	}
+
+	switch f.Op {
+	case SearchOpExists:
+		if _, ok := f.Value.(bool); !ok {
+			return fmt.Errorf("%w: %q expects a boolean value", ErrInvalidSearch, f.Op)
+		}
+		return nil
+	case SearchOpIn:
+		values, ok := f.Value.([]any)
+		if !ok || len(values) == 0 || len(values) > MaxSearchValues {
+			return fmt.Errorf("%w: %q expects a list of 1 to %d values", ErrInvalidSearch, f.Op, MaxSearchValues)
+		}
+		for _, v := range values {
+			if err := checkSearchValue(fieldType, f.Op, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	case SearchOpEq, SearchOpNe:
+	case SearchOpContains, SearchOpPrefix:
+		if fieldType == SearchFieldTime {
+			return fmt.Errorf("%w: %q is not supported on %s", ErrInvalidSearch, f.Op, f.Field)
+		}
+	case SearchOpGt, SearchOpGte, SearchOpLt, SearchOpLte:
+		if fieldType == SearchFieldList {
+			return fmt.Errorf("%w: %q is not supported on %s", ErrInvalidSearch, f.Op, f.Field)
+		}
+	default:
+		return fmt.Errorf("%w: unknown operator %q", ErrInvalidSearch, f.Op)
+	}
+	return checkSearchValue(fieldType, f.Op, f.Value)
+}
+
+func checkSearchValue(fieldType SearchFieldType, op string, value any) error {
+	s, ok := value.(string)
+	if !ok {
+		return fmt.Errorf("%w: %q expects a string value", ErrInvalidSearch, op)
+	}
+	if fieldType == SearchFieldTime {
+		if _, err := ParseSearchTime(s); err != nil {
+			return fmt.Errorf("%w: %q is not an RFC 3339 timestamp or YYYY-MM-DD date", ErrInvalidSearch, s)
+		}
+	}
+	return nil
+}
+
+// ParseSearchTime parses a time value of a search condition (RFC 3339 or a YYYY-MM-DD date)
+func ParseSearchTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
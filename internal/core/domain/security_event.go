@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// SecurityEventNewDeviceLogin is the type of the security events of logins from a new device, the
+// other events have the type of the action recorded in the audit log
+const SecurityEventNewDeviceLogin = "login.new_device"
+
+var ErrInvalidSecurityEventsBefore = errors.New("invalid before: must be an RFC 3339 time")
+
+// SecurityAuditActions are the audit actions on a user listed in their security events
+var SecurityAuditActions = []string{
+	AuditActionPasswordChanged,
+	AuditActionEmailChanged,
+	AuditActionMFAFactorAdded,
+	AuditActionMFAFactorRemoved,
+	AuditActionDeviceTrusted,
+	AuditActionDeviceRevoked,
+	AuditActionLoginReported,
+	AuditActionLoginAnomaly,
+	AuditActionLoggedOutAll,
+}
+
+// SecurityEvent is a change to the credentials or second factors of a user, a login flagged by the
+// login anomaly rules or a login from a device they had never used
+type SecurityEvent struct {
+	Type       string    `json:"type" example:"mfa.factor_added" enums:"user.password_changed,user.email_changed,mfa.factor_added,mfa.factor_removed,mfa.device_trusted,mfa.device_revoked,login.reported,login.anomaly,user.logged_out_all,login.new_device"`
+	OccurredAt time.Time `json:"occurred_at" example:"2024-01-01T00:00:00Z"`
+	// ActorID is set when someone else, such as an admin, made the change
+	ActorID string            `json:"actor_id,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// NewAuditSecurityEvent returns the security event of an audit event targeting userID
+func NewAuditSecurityEvent(event *AuditEvent, userID string) SecurityEvent {
+	security := SecurityEvent{
+		Type:       event.Action,
+		OccurredAt: event.CreatedAt,
+		Details:    event.Details,
+	}
+	if event.ActorID != userID {
+		security.ActorID = event.ActorID
+	}
+	return security
+}
+
+// NewLoginSecurityEvent returns the security event of a login from a new device
+func NewLoginSecurityEvent(event *LoginEvent) SecurityEvent {
+	details := map[string]string{"login_event_id": event.ID, "ip": event.IP}
+	if event.UserAgent != "" {
+		details["user_agent"] = event.UserAgent
+	}
+	if event.Location != nil {
+		details["location"] = event.Location.String()
+	}
+	return SecurityEvent{
+		Type:       SecurityEventNewDeviceLogin,
+		OccurredAt: event.CreatedAt,
+		Details:    details,
+	}
+}
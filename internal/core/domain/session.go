@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Auth modes of the logins: bearer access tokens in the response, or session cookies for
+// first-party web apps, see Session
+const (
+	AuthModeBearer = "bearer"
+	AuthModeCookie = "cookie"
+)
+
+var (
+	ErrInvalidAuthMode = errors.New("invalid auth mode: must be bearer or cookie")
+	ErrSessionNotFound = errors.New("session not found or expired")
+)
+
+// Session is a login of a first-party web app kept on the server, authenticated by the random
+// token of its cookie. Unsafe requests also send its CSRF token, which pages of other sites cannot
+// read.
+type Session struct {
+	// ID is the SHA-256 of the cookie token, see HashSessionToken
+	ID       string   `bson:"_id"`
+	TenantID string   `bson:"tenant_id"`
+	UserID   string   `bson:"user_id"`
+	Roles    []string `bson:"roles,omitempty"`
+	// Scopes restrict the session like the scopes of a restricted token
+	Scopes []string `bson:"scopes,omitempty"`
+	// AuthTime is when the user last proved their password or a second factor, like the auth_time claim
+	AuthTime time.Time `bson:"auth_time"`
+	// Generation is the token generation of the user, revoking the session on a logout from all devices
+	Generation    int       `bson:"generation,omitempty"`
+	CSRFTokenHash string    `bson:"csrf_token_hash"`
+	IP            string    `bson:"ip,omitempty"`
+	UserAgent     string    `bson:"user_agent,omitempty"`
+	CreatedAt     time.Time `bson:"created_at"`
+	ExpiresAt     time.Time `bson:"expires_at"`
+}
+
+// ParseAuthMode checks an auth mode, bearer when empty
+func ParseAuthMode(mode string) (string, error) {
+	switch mode = strings.ToLower(strings.TrimSpace(mode)); mode {
+	case "":
+		return AuthModeBearer, nil
+	case AuthModeBearer, AuthModeCookie:
+		return mode, nil
+	}
+	return "", ErrInvalidAuthMode
+}
+
+// NewSession returns a session of the user valid for ttl, with the tokens of its cookie and of its
+// CSRF header, which are only stored hashed
+func NewSession(user *User, scopes []string, ip, userAgent string, ttl time.Duration) (*Session, string, string, error) {
+	token, err := randomSessionToken()
+	if err != nil {
+		return nil, "", "", err
+	}
+	csrfToken, err := randomSessionToken()
+	if err != nil {
+		return nil, "", "", err
+	}
+	now := time.Now()
+	return &Session{
+		ID:            HashSessionToken(token),
+		TenantID:      user.TenantID,
+		UserID:        user.ID,
+		Roles:         user.EffectiveRoles(),
+		Scopes:        scopes,
+		AuthTime:      now,
+		Generation:    user.TokenGeneration,
+		CSRFTokenHash: HashSessionToken(csrfToken),
+		IP:            ip,
+		UserAgent:     strings.TrimSpace(userAgent),
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(ttl),
+	}, token, csrfToken, nil
+}
+
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomSessionToken() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// RotateCSRFToken replaces the CSRF token of the session and returns the new one
+func (s *Session) RotateCSRFToken() (string, error) {
+	csrfToken, err := randomSessionToken()
+	if err != nil {
+		return "", err
+	}
+	s.CSRFTokenHash = HashSessionToken(csrfToken)
+	return csrfToken, nil
+}
+
+// Expired reports whether the session can no longer authenticate requests at now
+func (s *Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+
+	"golang.org/x/text/language"
+)
+
+var (
+	ErrInvalidTheme    = errors.New("invalid theme: must be one of light, dark, system")
+	ErrInvalidLanguage = errors.New("invalid language: must be a BCP 47 language tag")
+)
+
+// Themes lists the accepted values for Settings.Theme
+var Themes = []string{"light", "dark", "system"}
+
+// Settings holds user preferences, stored apart from the profile so profile updates never overwrite them
+type Settings struct {
+	Theme          string `json:"theme" bson:"theme" example:"dark"`
+	Language       string `json:"language" bson:"language" example:"en-US"`
+	MarketingOptIn bool   `json:"marketing_opt_in" bson:"marketing_opt_in" example:"false"`
+}
+
+// DefaultSettings returns the settings used for users who never changed their preferences
+func DefaultSettings() Settings {
+	return Settings{
+		Theme:    "system",
+		Language: "en",
+	}
+}
+
+// SettingsUpdate is a partial settings change; nil fields are left untouched
+type SettingsUpdate struct {
+	Theme          *string `json:"theme,omitempty" example:"dark"`
+	Language       *string `json:"language,omitempty" example:"pt-BR"`
+	MarketingOptIn *bool   `json:"marketing_opt_in,omitempty" example:"true"`
+}
+
+// Validate checks every provided field and canonicalizes the language tag
+func (u *SettingsUpdate) Validate() error {
+	if u.Theme != nil {
+		valid := false
+		for _, theme := range Themes {
+			if *u.Theme == theme {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return ErrInvalidTheme
+		}
+	}
+	if u.Language != nil {
+		tag, err := language.Parse(*u.Language)
+		if err != nil {
+			return ErrInvalidLanguage
+		}
+		canonical := tag.String()
+		u.Language = &canonical
+	}
+	return nil
+}
+
+// Apply returns a copy of the settings with the update applied
+func (u SettingsUpdate) Apply(settings Settings) Settings {
+	if u.Theme != nil {
+		settings.Theme = *u.Theme
+	}
+	if u.Language != nil {
+		settings.Language = *u.Language
+	}
+	if u.MarketingOptIn != nil {
+		settings.MarketingOptIn = *u.MarketingOptIn
+	}
+	return settings
+}
@@ -0,0 +1,31 @@
+package domain
+
+import "time"
+
+// Statuses of a signing key, see SigningKey.Status
+const (
+	SigningKeyPending = "pending" // Published, signs tokens from ActivatesAt
+	SigningKeyActive  = "active"  // Signs the new tokens
+	SigningKeyRetired = "retired" // Replaced, verifies the tokens it signed until ExpiresAt
+)
+
+// SigningKey is a key pair signing the access tokens, shared by every instance and tenant of the API.
+// The newest key whose activation time has passed signs the tokens; the other keys verify them
+// until they expire, which the database removes them at.
+type SigningKey struct {
+	// ID is the key ID named by the tokens and the key set, the RFC 7638 thumbprint of the public key
+	ID        string `json:"kid" bson:"_id" example:"NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"`
+	Algorithm string `json:"alg" bson:"algorithm" example:"EdDSA"`
+	// PrivateKey is the PKCS #8 PEM of the private key, encrypted with the key encryption key
+	PrivateKey []byte `json:"-" bson:"private_key"`
+	// Status is computed when the keys are listed
+	Status      string     `json:"status" bson:"-" example:"active"`
+	CreatedAt   time.Time  `json:"created_at" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+	ActivatesAt time.Time  `json:"activates_at" bson:"activates_at" example:"2024-01-01T00:10:00Z"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" bson:"expires_at,omitempty" example:"2024-02-01T01:10:00Z"`
+}
+
+// Expired reports whether the key stopped verifying tokens at now
+func (k *SigningKey) Expired(now time.Time) bool {
+	return k.ExpiresAt != nil && !now.Before(*k.ExpiresAt)
+}
@@ -0,0 +1,34 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// StatsDayLayout is the layout of the days of the user statistics, in UTC
+const StatsDayLayout = "2006-01-02"
+
+// MaxStatsDays is how many days of user statistics can be listed at once
+const MaxStatsDays = 366
+
+var ErrInvalidStatsDays = errors.New("days must be between 1 and 366")
+
+// UserStats counts the users of a tenant on a day, rolled up once the day is over
+type UserStats struct {
+	// ID is the tenant and the day, so rolling up a day again replaces its statistics
+	ID       string `json:"-" bson:"_id"`
+	TenantID string `json:"-" bson:"tenant_id"`
+	Day      string `json:"day" bson:"day" example:"2024-01-01"`
+	// Total is the number of users at the end of the day, soft-deleted users excluded
+	Total int64 `json:"total" bson:"total" example:"1250"`
+	New   int64 `json:"new" bson:"new" example:"14"`
+	// Active is the number of users who logged in during the day
+	Active     int64     `json:"active" bson:"active" example:"320"`
+	Deleted    int64     `json:"deleted" bson:"deleted" example:"2"`
+	ComputedAt time.Time `json:"computed_at" bson:"computed_at" example:"2024-01-02T00:15:00Z"`
+}
+
+// UserStatsID returns the ID of the statistics of the tenant on the day
+func UserStatsID(tenantID, day string) string {
+	return tenantID + ":" + day
+}
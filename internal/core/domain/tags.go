@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var ErrInvalidTag = errors.New("invalid tag: use 1-32 lowercase letters, digits, underscores or dashes")
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// NormalizeTag lowercases and trims a tag and validates its format
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if !tagPattern.MatchString(tag) {
+		return "", ErrInvalidTag
+	}
+	return tag, nil
+}
+
+// NormalizeTags normalizes every tag and drops duplicates, preserving order
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	return normalized, nil
+}
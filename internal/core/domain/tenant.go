@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// DefaultTenantID is the tenant assigned to single-tenant deployments and to data created before tenancy existed
+const DefaultTenantID = "default"
+
+var (
+	ErrInvalidTenant = errors.New("invalid tenant identifier")
+	ErrMissingTenant = errors.New("tenant is not set in the request context")
+)
+
+var tenantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+type tenantContextKey struct{}
+
+// ValidTenantID reports whether id is a well-formed tenant identifier
+func ValidTenantID(id string) bool {
+	return tenantPattern.MatchString(id)
+}
+
+// WithTenant returns a copy of ctx carrying the tenant ID
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant ID carried by ctx, or an empty string if none is set
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantContextKey{}).(string)
+	return tenantID
+}
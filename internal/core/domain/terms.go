@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidTermsDocument   = errors.New("invalid terms document: must be terms or privacy")
+	ErrInvalidTermsVersion    = errors.New("invalid terms version: must be 1 to 50 characters")
+	ErrInvalidTermsURL        = errors.New("invalid terms url: must be an http or https URL")
+	ErrTermsVersionExists     = errors.New("terms version is already published")
+	ErrTermsVersionNotCurrent = errors.New("terms version is not the current version of the document")
+)
+
+// Documents users consent to
+const (
+	TermsDocumentTerms   = "terms"   // Terms of service
+	TermsDocumentPrivacy = "privacy" // Privacy policy
+)
+
+// TermsDocuments are the documents versions can be published of
+var TermsDocuments = []string{TermsDocumentTerms, TermsDocumentPrivacy}
+
+// TermsVersion is a published version of a document. The latest version of each document is
+// current, and users must accept every current version before using the API again.
+type TermsVersion struct {
+	ID          string    `json:"id" bson:"_id" example:"8c1d2e3f-4a5b-4c6d-8e7f-9a0b1c2d3e4f"`
+	TenantID    string    `json:"-" bson:"tenant_id,omitempty"`
+	Document    string    `json:"document" bson:"document" example:"terms" enums:"terms,privacy"`
+	Version     string    `json:"version" bson:"version" example:"2024-06-01"`
+	URL         string    `json:"url,omitempty" bson:"url,omitempty" example:"https://example.com/legal/terms/2024-06-01"`
+	PublishedBy string    `json:"published_by,omitempty" bson:"published_by,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	PublishedAt time.Time `json:"published_at" bson:"published_at" example:"2024-06-01T00:00:00Z"`
+}
+
+// TermsAcceptance records that a user accepted a version of a document
+type TermsAcceptance struct {
+	ID         string    `json:"-" bson:"_id"`
+	TenantID   string    `json:"-" bson:"tenant_id,omitempty"`
+	UserID     string    `json:"-" bson:"user_id"`
+	Document   string    `json:"document" bson:"document" example:"terms"`
+	Version    string    `json:"version" bson:"version" example:"2024-06-01"`
+	AcceptedAt time.Time `json:"accepted_at" bson:"accepted_at" example:"2024-06-02T09:30:00Z"`
+}
+
+// TermsStatus is the consent of a user: the current versions left to accept and the versions
+// accepted, newest first
+type TermsStatus struct {
+	Pending  []*TermsVersion    `json:"pending"`
+	Accepted []*TermsAcceptance `json:"accepted"`
+}
+
+// NewTermsVersion returns a version of the document published now by publishedBy; textURL, optional,
+// is where the text of the version is shown
+func NewTermsVersion(document, version, textURL, publishedBy string) (*TermsVersion, error) {
+	if !slices.Contains(TermsDocuments, document) {
+		return nil, ErrInvalidTermsDocument
+	}
+	version = strings.TrimSpace(version)
+	if version == "" || len([]rune(version)) > 50 {
+		return nil, ErrInvalidTermsVersion
+	}
+	textURL = strings.TrimSpace(textURL)
+	if textURL != "" && !isWebURL(textURL) {
+		return nil, ErrInvalidTermsURL
+	}
+
+	return &TermsVersion{
+		ID:          uuid.New().String(),
+		Document:    document,
+		Version:     version,
+		URL:         textURL,
+		PublishedBy: publishedBy,
+		PublishedAt: time.Now(),
+	}, nil
+}
+
+// NewTermsAcceptance records that the user accepts the version now
+func NewTermsAcceptance(userID string, version *TermsVersion) *TermsAcceptance {
+	return &TermsAcceptance{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		Document:   version.Document,
+		Version:    version.Version,
+		AcceptedAt: time.Now(),
+	}
+}
+
+// PendingTerms returns the current versions missing from the accepted ones
+func PendingTerms(current []*TermsVersion, accepted []*TermsAcceptance) []*TermsVersion {
+	pending := make([]*TermsVersion, 0)
+	for _, version := range current {
+		if !version.AcceptedIn(accepted) {
+			pending = append(pending, version)
+		}
+	}
+	return pending
+}
+
+// AcceptedIn reports whether one of the acceptances is of the version
+func (v *TermsVersion) AcceptedIn(accepted []*TermsAcceptance) bool {
+	for _, acceptance := range accepted {
+		if acceptance.Document == v.Document && acceptance.Version == v.Version {
+			return true
+		}
+	}
+	return false
+}
+
+func isWebURL(raw string) bool {
+	parsed, err := url.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxTrustedDevices bounds the devices a user skips the second factor on; trusting another one
+// forgets the one used the longest ago
+const MaxTrustedDevices = 10
+
+var ErrTrustedDeviceNotFound = errors.New("trusted device not found")
+
+// TrustedDevice is a device a user asked to remember after a login with a second factor, which
+// skips the second factor until ExpiresAt when it sends its device token
+type TrustedDevice struct {
+	ID         string     `json:"id" bson:"id" example:"9b2d6e1f-3c4a-4f5b-8e7d-6c5b4a392817"`
+	UserAgent  string     `json:"user_agent,omitempty" bson:"user_agent,omitempty" example:"Mozilla/5.0"`
+	IP         string     `json:"ip" bson:"ip" example:"203.0.113.7"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at" example:"2024-01-01T00:00:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" bson:"last_used_at,omitempty" example:"2024-01-15T00:00:00Z"`
+	ExpiresAt  time.Time  `json:"expires_at" bson:"expires_at" example:"2024-01-31T00:00:00Z"`
+	// TokenHash is the SHA-256 of the device token, see HashTrustedDeviceToken
+	TokenHash string `json:"-" bson:"token_hash"`
+}
+
+// NewTrustedDevice returns a device trusted for ttl and the token it sends at login
+func NewTrustedDevice(userAgent, ip string, ttl time.Duration) (TrustedDevice, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return TrustedDevice{}, "", err
+	}
+	token := hex.EncodeToString(secret)
+	now := time.Now()
+	return TrustedDevice{
+		ID:        uuid.New().String(),
+		UserAgent: strings.TrimSpace(userAgent),
+		IP:        ip,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+		TokenHash: HashTrustedDeviceToken(token),
+	}, token, nil
+}
+
+func HashTrustedDeviceToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// lastUse is when the device last skipped the second factor, or was trusted
+func (d TrustedDevice) lastUse() time.Time {
+	if d.LastUsedAt != nil {
+		return *d.LastUsedAt
+	}
+	return d.CreatedAt
+}
+
+// ActiveTrustedDevices returns the devices of the user still trusted at now
+func (u *User) ActiveTrustedDevices(now time.Time) []TrustedDevice {
+	return slices.DeleteFunc(slices.Clone(u.TrustedDevices), func(d TrustedDevice) bool { return !now.Before(d.ExpiresAt) })
+}
+
+// TrustDevice adds a device to the active devices of the user, forgetting the one used the longest
+// ago past MaxTrustedDevices
+func (u *User) TrustDevice(device TrustedDevice, now time.Time) []TrustedDevice {
+	devices := append(u.ActiveTrustedDevices(now), device)
+	if len(devices) > MaxTrustedDevices {
+		oldest := slices.IndexFunc(devices, func(d TrustedDevice) bool {
+			return !slices.ContainsFunc(devices, func(other TrustedDevice) bool { return other.lastUse().Before(d.lastUse()) })
+		})
+		devices = slices.Delete(devices, oldest, oldest+1)
+	}
+	return devices
+}
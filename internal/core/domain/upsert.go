@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrInvalidUpsertPolicy = errors.New("invalid conflict policy: must be one of overwrite, keep_existing, reject")
+	ErrUpsertConflict      = errors.New("upsert conflict with the current values of the user")
+)
+
+// Upsert policies deciding what happens when a user already has another value for a field the
+// upsert sets; blank fields are always filled
+const (
+	// UpsertPolicyOverwrite replaces the values of the user, the caller being the system of record
+	UpsertPolicyOverwrite = "overwrite"
+	// UpsertPolicyKeepExisting keeps the values of the user and only fills its blank fields
+	UpsertPolicyKeepExisting = "keep_existing"
+	// UpsertPolicyReject fails the whole upsert, listing the conflicting fields
+	UpsertPolicyReject = "reject"
+)
+
+// ParseUpsertPolicy checks an upsert policy, overwrite when empty
+func ParseUpsertPolicy(policy string) (string, error) {
+	switch policy = strings.ToLower(strings.TrimSpace(policy)); policy {
+	case "":
+		return UpsertPolicyOverwrite, nil
+	case UpsertPolicyOverwrite, UpsertPolicyKeepExisting, UpsertPolicyReject:
+		return policy, nil
+	}
+	return "", ErrInvalidUpsertPolicy
+}
+
+// UserUpsert is the state of a user in another system of record, created or applied to the user
+// with the same email; blank fields are left untouched
+type UserUpsert struct {
+	Username  string `json:"username,omitempty" example:"johndoe"`
+	FirstName string `json:"first_name,omitempty" example:"John"`
+	LastName  string `json:"last_name,omitempty" example:"Doe"`
+	Phone     string `json:"phone,omitempty" example:"+1-555-123-4567"`
+	Birthdate string `json:"birthdate,omitempty" example:"1990-05-15"`
+	Timezone  string `json:"timezone,omitempty" example:"America/New_York"`
+	Locale    string `json:"locale,omitempty" example:"en-US"`
+}
+
+// Profile returns the profile fields of the upsert, validated as Profile.Validate does
+func (u UserUpsert) Profile() (Profile, error) {
+	profile := Profile{
+		FirstName: strings.TrimSpace(u.FirstName),
+		LastName:  strings.TrimSpace(u.LastName),
+		Phone:     strings.TrimSpace(u.Phone),
+		Birthdate: strings.TrimSpace(u.Birthdate),
+		Timezone:  strings.TrimSpace(u.Timezone),
+		Locale:    strings.TrimSpace(u.Locale),
+	}
+	if err := profile.Validate(); err != nil {
+		// Upserts carry the profile fields at the top level of the body
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			return Profile{}, &FieldError{Field: strings.TrimPrefix(fieldErr.Field, "profile."), Err: fieldErr.Err}
+		}
+		return Profile{}, err
+	}
+	return profile, nil
+}
+
+// Apply returns a copy of the user with the upsert applied according to policy and the fields it
+// changed. username is the normalized username of the upsert, if any. With the reject policy, an
+// error wrapping ErrUpsertConflict lists the fields the user already has other values for.
+func (u UserUpsert) Apply(user *User, username string, policy string) (*User, []string, error) {
+	incoming, err := u.Profile()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updated := *user
+	var changed, conflicts []string
+	resolve := func(field string, current *string, value string) {
+		if value == "" || *current == value {
+			return
+		}
+		if *current != "" {
+			switch policy {
+			case UpsertPolicyKeepExisting:
+				return
+			case UpsertPolicyReject:
+				conflicts = append(conflicts, field)
+				return
+			}
+		}
+		*current = value
+		changed = append(changed, field)
+	}
+	resolve("username", &updated.Username, username)
+	resolve("first_name", &updated.Profile.FirstName, incoming.FirstName)
+	resolve("last_name", &updated.Profile.LastName, incoming.LastName)
+	resolve("phone", &updated.Profile.Phone, incoming.Phone)
+	resolve("birthdate", &updated.Profile.Birthdate, incoming.Birthdate)
+	resolve("timezone", &updated.Profile.Timezone, incoming.Timezone)
+	resolve("locale", &updated.Profile.Locale, incoming.Locale)
+
+	if len(conflicts) > 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUpsertConflict, strings.Join(conflicts, ", "))
+	}
+	return &updated, changed, nil
+}
@@ -3,7 +3,6 @@ package domain
 
 import (
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -11,44 +10,140 @@ import (
 
 var ErrInvalidEmail = errors.New("invalid email address")
 
-type Address struct {
-	Street  string `json:"street" bson:"street,omitempty" example:"123 Main St"`
-	City    string `json:"city" bson:"city,omitempty" example:"New York"`
-	State   string `json:"state" bson:"state,omitempty" example:"NY"`
-	Country string `json:"country" bson:"country,omitempty" example:"USA"`
-	ZipCode string `json:"zip_code" bson:"zip_code,omitempty" example:"10001"`
-}
+// NoPasswordHash is the password hash of accounts without a password, such as the accounts created
+// by a directory sync; no password matches it
+const NoPasswordHash = "!"
 
 type Profile struct {
-	FirstName string  `json:"first_name" bson:"first_name,omitempty" example:"John"`
-	LastName  string  `json:"last_name" bson:"last_name,omitempty" example:"Doe"`
-	Address   Address `json:"address" bson:"address,omitempty"`
-	Phone     string  `json:"phone" bson:"phone,omitempty" example:"+1-555-123-4567"`
-	Birthdate string  `json:"birthdate" bson:"birthdate,omitempty" example:"1990-05-15"`
-	NIN       string  `json:"nin" bson:"nin,omitempty" example:"123-45-6789"`
+	FirstName string    `json:"first_name" bson:"first_name,omitempty" example:"John"`
+	LastName  string    `json:"last_name" bson:"last_name,omitempty" example:"Doe"`
+	Addresses []Address `json:"addresses,omitempty" bson:"addresses,omitempty"`
+	// LegacyAddress is the single address stored before users could have several, see MigrateLegacyAddress
+	LegacyAddress *Address `json:"-" bson:"address,omitempty"`
+	Phone         string   `json:"phone" bson:"phone,omitempty" example:"+1-555-123-4567"`
+	Birthdate     string   `json:"birthdate" bson:"birthdate,omitempty" example:"1990-05-15"`
+	NIN           string   `json:"nin" bson:"nin,omitempty" example:"123-45-6789"`
+	Timezone      string   `json:"timezone,omitempty" bson:"timezone,omitempty" example:"America/New_York"`
+	Locale        string   `json:"locale,omitempty" bson:"locale,omitempty" example:"en-US"`
 }
 
 type User struct {
-	ID           string    `json:"id" bson:"_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
-	Email        string    `json:"email" bson:"email,omitempty" example:"john.doe@example.com"`
-	PasswordHash string    `json:"-" bson:"password_hash,omitempty"`
-	Profile      Profile   `json:"profile" bson:"profile,omitempty"`
-	CreatedAt    time.Time `json:"created_at" bson:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`
-	UpdatedAt    time.Time `json:"updated_at" bson:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	ID       string `json:"id" bson:"_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	TenantID string `json:"-" bson:"tenant_id,omitempty"`
+	Email    string `json:"email" bson:"email,omitempty" example:"john.doe@example.com"`
+	// NormalizedEmail identifies the mailbox behind Email, see NormalizeEmail
+	NormalizedEmail string     `json:"-" bson:"normalized_email,omitempty"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" bson:"email_verified_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	Username        string     `json:"username,omitempty" bson:"username,omitempty" example:"johndoe"`
+	// PhoneVerifiedAt is set once the user confirms a code sent to the phone of its profile, and
+	// cleared when the phone changes
+	PhoneVerifiedAt *time.Time `json:"phone_verified_at,omitempty" bson:"phone_verified_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	// PhoneChallenge is the code pending confirmation, see POST /users/me/phone/verify
+	PhoneChallenge *PhoneChallenge `json:"-" bson:"phone_challenge,omitempty"`
+	// MFAFactors are the second factors of the user, listed by GET /users/me/mfa/factors
+	MFAFactors []MFAFactor `json:"-" bson:"mfa_factors,omitempty"`
+	// TrustedDevices skip the second factor, listed by GET /users/me/trusted-devices
+	TrustedDevices []TrustedDevice `json:"-" bson:"trusted_devices,omitempty"`
+	PasswordHash   string          `json:"-" bson:"password_hash,omitempty"`
+	// PasswordResetRequired blocks logins until the password is changed, e.g. after a login was reported
+	PasswordResetRequired bool              `json:"-" bson:"password_reset_required,omitempty"`
+	Roles                 []string          `json:"roles" bson:"roles,omitempty" example:"user"`
+	LegacyRole            string            `json:"-" bson:"role,omitempty"`
+	Profile               Profile           `json:"profile" bson:"profile,omitempty"`
+	Avatar                *Avatar           `json:"avatar,omitempty" bson:"avatar,omitempty"`
+	Settings              *Settings         `json:"-" bson:"settings,omitempty"`
+	Metadata              map[string]string `json:"metadata,omitempty" bson:"metadata,omitempty"`
+	// CustomFields are the values of the custom registration fields, see CustomFieldSchema
+	CustomFields map[string]string `json:"custom_fields,omitempty" bson:"custom_fields,omitempty"`
+	Tags         []string          `json:"tags,omitempty" bson:"tags,omitempty" example:"beta,vip"`
+	CreatedAt    time.Time         `json:"created_at" bson:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	UpdatedAt    time.Time         `json:"updated_at" bson:"updated_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	// UpdatedBy is the caller who last updated the user, see Actor; it is empty when the last update
+	// was made by background work, such as a directory sync
+	UpdatedBy string `json:"updated_by,omitempty" bson:"updated_by,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	// LastLoginAt and LastSeenAt are only shown to callers with the users:activity permission
+	LastLoginAt *time.Time `json:"last_login_at,omitempty" bson:"last_login_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty" bson:"last_seen_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	// DeletedAt is set on soft-deleted users, which repositories no longer return
+	DeletedAt *time.Time `json:"-" bson:"deleted_at,omitempty"`
+	// MergedInto is the ID of the user a duplicate account was merged into
+	MergedInto string `json:"-" bson:"merged_into,omitempty"`
+	// AnonymizedAt is set on soft-deleted users whose personal data the retention purge removed
+	AnonymizedAt *time.Time `json:"-" bson:"anonymized_at,omitempty"`
+	// DirectoryID identifies the entry of the external directory the account is synced from
+	DirectoryID string `json:"directory_id,omitempty" bson:"directory_id,omitempty" example:"7d3a9b0e-52c1-4a8e-9f3c-0b6e1d2f4a5c"`
+	// DeactivatedAt is set on accounts disabled in or removed from their directory, which cannot log in
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty" bson:"deactivated_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	// TokenGeneration is bumped by a logout from all devices, revoking the tokens issued before it
+	TokenGeneration int `json:"-" bson:"token_generation,omitempty"`
+	// Verification is the identity verification of the user, only shown through its own endpoints
+	Verification *Verification `json:"-" bson:"verification,omitempty"`
+	// ExternalIdentity is the account of an external identity provider whose tokens authenticate the user
+	ExternalIdentity *ExternalIdentity `json:"external_identity,omitempty" bson:"external_identity,omitempty"`
+	// Relationships are counted from the relationships collection when a single user is fetched
+	Relationships *RelationshipCounts `json:"relationships,omitempty" bson:"-"`
+}
+
+// ExternalIdentity identifies an account of an external identity provider, such as Firebase Auth or Keycloak
+type ExternalIdentity struct {
+	Issuer  string `json:"issuer" bson:"issuer" example:"https://securetoken.google.com/my-project"`
+	Subject string `json:"subject" bson:"subject" example:"kX3mJ9pQ2rT5vW8yZ1aB4cD6eF7g"`
 }
 
 func NewUser(email, passwordHash string, profile Profile) (*User, error) {
-	email = strings.TrimSpace(strings.ToLower(email))
-	if !strings.Contains(email, "@") {
-		return nil, ErrInvalidEmail
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+	email, err := CanonicalEmail(email)
+	if err != nil {
+		return nil, err
+	}
+	normalizedEmail, err := NormalizeEmail(email)
+	if err != nil {
+		return nil, err
 	}
 
 	return &User{
-		ID:           uuid.New().String(),
-		Email:        email,
-		PasswordHash: passwordHash,
-		Profile:      profile,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		ID:              uuid.New().String(),
+		Email:           email,
+		NormalizedEmail: normalizedEmail,
+		PasswordHash:    passwordHash,
+		Roles:           []string{RoleUser},
+		Profile:         profile,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}, nil
 }
+
+// LastActiveAt returns when the user was last seen, falling back to when the account was created
+func (u *User) LastActiveAt() time.Time {
+	if u.LastSeenAt != nil {
+		return *u.LastSeenAt
+	}
+	return u.CreatedAt
+}
+
+// Status returns UserStatusDeactivated for accounts deactivated by the directory sync, or else
+// UserStatusLocked for accounts locked until their password is reset, or else UserStatusActive
+func (u *User) Status() string {
+	switch {
+	case u.DeactivatedAt != nil:
+		return UserStatusDeactivated
+	case u.PasswordResetRequired:
+		return UserStatusLocked
+	default:
+		return UserStatusActive
+	}
+}
+
+// EffectiveRoles returns the user roles, falling back to the single legacy role field
+// and treating users stored before roles existed as regular users
+func (u *User) EffectiveRoles() []string {
+	if len(u.Roles) > 0 {
+		return u.Roles
+	}
+	if u.LegacyRole != "" {
+		return []string{u.LegacyRole}
+	}
+	return []string{RoleUser}
+}
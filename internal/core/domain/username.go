@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var (
+	ErrInvalidUsername  = errors.New("invalid username: use 3-30 lowercase letters, digits, dots, underscores or dashes, starting with a letter or digit")
+	ErrReservedUsername = errors.New("invalid username: this username is reserved")
+	ErrUsernameTaken    = errors.New("username is already in use")
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{2,29}$`)
+
+// reservedUsernames cannot be claimed because they clash with routes or could impersonate staff
+var reservedUsernames = map[string]bool{
+	"admin": true, "administrator": true, "api": true, "auth": true, "by-username": true,
+	"check-email": true, "count": true, "help": true, "login": true, "logout": true,
+	"lookup": true, "me": true, "moderator": true, "null": true, "register": true,
+	"root": true, "search": true, "security": true, "settings": true, "staff": true,
+	"support": true, "system": true, "undefined": true,
+}
+
+// NormalizeUsername lowercases and trims a username and validates its format and the reserved names
+func NormalizeUsername(username string) (string, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if !usernamePattern.MatchString(username) {
+		return "", ErrInvalidUsername
+	}
+	if reservedUsernames[username] {
+		return "", ErrReservedUsername
+	}
+	return username, nil
+}
@@ -0,0 +1,20 @@
+package domain
+
+// FieldError is a validation error on a single input field, so clients can show it next to the field.
+// Field is the JSON path of the field, e.g. "country" or "profile.addresses[0].country".
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string { return e.Err.Error() }
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// prefixField nests the field of a FieldError under parent; other errors are returned unchanged
+func prefixField(parent string, err error) error {
+	if fieldErr, ok := err.(*FieldError); ok {
+		return &FieldError{Field: parent + "." + fieldErr.Field, Err: fieldErr.Err}
+	}
+	return err
+}
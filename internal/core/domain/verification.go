@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// MaxVerificationDocuments and MaxVerificationReasonLength bound submissions and reviews
+const (
+	MaxVerificationDocuments    = 10
+	MaxVerificationReasonLength = 500
+)
+
+var (
+	ErrInvalidVerificationDocuments = errors.New("invalid verification documents: submit 1 to 10 pending documents of the user")
+	ErrInvalidVerificationDecision  = errors.New("invalid verification decision: must be approve or reject")
+	ErrInvalidVerificationReason    = errors.New("invalid verification reason: rejections require a reason of at most 500 characters")
+	ErrVerificationConflict         = errors.New("verification conflict: the current verification status does not allow the action")
+)
+
+// Statuses of the identity verification of a user
+const (
+	VerificationStatusUnverified    = "unverified"
+	VerificationStatusPendingReview = "pending_review"
+	VerificationStatusVerified      = "verified"
+	VerificationStatusRejected      = "rejected"
+)
+
+// Decisions of the staff reviewing a verification
+const (
+	VerificationDecisionApprove = "approve"
+	VerificationDecisionReject  = "reject"
+)
+
+// Results of the automated identity checks of providers; checks reporting consider are left to staff
+const (
+	IdentityCheckResultClear    = "clear"
+	IdentityCheckResultConsider = "consider"
+)
+
+// verificationTransitions lists the statuses each status can move to. Rejected users can submit
+// again, and staff can revoke a verification by rejecting it.
+var verificationTransitions = map[string][]string{
+	VerificationStatusUnverified:    {VerificationStatusPendingReview},
+	VerificationStatusPendingReview: {VerificationStatusVerified, VerificationStatusRejected},
+	VerificationStatusVerified:      {VerificationStatusRejected},
+	VerificationStatusRejected:      {VerificationStatusPendingReview},
+}
+
+// Verification is the identity verification (KYC) of a user: submitting documents moves it to
+// pending_review, from where staff, or a clear check of the provider, verifies or rejects it.
+// Users never submitted have none.
+type Verification struct {
+	Status      string     `json:"status" bson:"status" example:"pending_review" enums:"unverified,pending_review,verified,rejected"`
+	DocumentIDs []string   `json:"document_ids,omitempty" bson:"document_ids,omitempty" example:"4d3c2b1a-9f8e-4d7c-8b6a-5f4e3d2c1b0a"`
+	SubmittedBy string     `json:"submitted_by,omitempty" bson:"submitted_by,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	SubmittedAt *time.Time `json:"submitted_at,omitempty" bson:"submitted_at,omitempty" example:"2024-01-01T00:00:00Z"`
+	// Reason is given by the staff rejecting the verification, and optionally when verifying it
+	Reason     string     `json:"reason,omitempty" bson:"reason,omitempty" example:"The passport photo is not legible"`
+	ReviewedBy string     `json:"reviewed_by,omitempty" bson:"reviewed_by,omitempty" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty" bson:"reviewed_at,omitempty" example:"2024-01-02T00:00:00Z"`
+	// Check is the automated check of the submitted documents by the identity verification provider
+	Check *IdentityCheck `json:"check,omitempty" bson:"check,omitempty"`
+}
+
+// IdentityCheck is an automated check run by an identity verification provider, whose result is
+// reported to the webhook of the API once it completes
+type IdentityCheck struct {
+	Provider    string     `json:"provider" bson:"provider" example:"onfido"`
+	ID          string     `json:"id" bson:"id" example:"8546921-123123-123123"`
+	Result      string     `json:"result,omitempty" bson:"result,omitempty" example:"clear" enums:"clear,consider"`
+	StartedAt   time.Time  `json:"started_at" bson:"started_at" example:"2024-01-01T00:00:05Z"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" bson:"completed_at,omitempty" example:"2024-01-01T00:03:00Z"`
+}
+
+// CurrentStatus returns the status of the verification, unverified when there is none
+func (v *Verification) CurrentStatus() string {
+	if v == nil {
+		return VerificationStatusUnverified
+	}
+	return v.Status
+}
+
+// Submit returns the verification pending review of the documents, submitted by submittedBy
+func (v *Verification) Submit(submittedBy string, documentIDs []string, at time.Time) (*Verification, error) {
+	if len(documentIDs) == 0 || len(documentIDs) > MaxVerificationDocuments {
+		return nil, ErrInvalidVerificationDocuments
+	}
+	if !v.canMoveTo(VerificationStatusPendingReview) {
+		return nil, ErrVerificationConflict
+	}
+	return &Verification{
+		Status:      VerificationStatusPendingReview,
+		DocumentIDs: documentIDs,
+		SubmittedBy: submittedBy,
+		SubmittedAt: &at,
+	}, nil
+}
+
+// Review returns the verification verified or rejected by reviewedBy; rejections require a reason
+func (v *Verification) Review(reviewedBy, decision, reason string, at time.Time) (*Verification, error) {
+	var status string
+	switch decision {
+	case VerificationDecisionApprove:
+		status = VerificationStatusVerified
+	case VerificationDecisionReject:
+		status = VerificationStatusRejected
+	default:
+		return nil, ErrInvalidVerificationDecision
+	}
+	reason = strings.TrimSpace(reason)
+	if utf8.RuneCountInString(reason) > MaxVerificationReasonLength || (status == VerificationStatusRejected && reason == "") {
+		return nil, ErrInvalidVerificationReason
+	}
+	if !v.canMoveTo(status) {
+		return nil, ErrVerificationConflict
+	}
+
+	reviewed := *v
+	reviewed.Status = status
+	reviewed.Reason = reason
+	reviewed.ReviewedBy = reviewedBy
+	reviewed.ReviewedAt = &at
+	return &reviewed, nil
+}
+
+func (v *Verification) canMoveTo(status string) bool {
+	return slices.Contains(verificationTransitions[v.CurrentStatus()], status)
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// DuplicateReport contains paginated groups of likely duplicate accounts
+type DuplicateReport struct {
+	Candidates []*domain.DuplicateCandidate `json:"candidates"`
+	TotalCount int64                        `json:"total_count"`
+	Page       int                          `json:"page"`
+	PageSize   int                          `json:"page_size"`
+	TotalPages int                          `json:"total_pages"`
+}
+
+type AccountMergeUseCase interface {
+	FindDuplicates(ctx context.Context, page, pageSize int) (*DuplicateReport, error)
+	Merge(ctx context.Context, actorID, primaryID, duplicateID, policy string) (*domain.User, error)
+}
@@ -0,0 +1,35 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// AuditQuery filters and paginates audit events; empty fields match every event
+type AuditQuery struct {
+	Action string
+	// Actions matches events of any of the actions, instead of Action
+	Actions  []string
+	ActorID  string
+	TargetID string
+	// Before matches events recorded before the time, if not zero
+	Before   time.Time
+	Page     int
+	PageSize int
+}
+
+// AuditQueryResult contains paginated audit events, newest first
+type AuditQueryResult struct {
+	Events     []*domain.AuditEvent `json:"events"`
+	TotalCount int64                `json:"total_count"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"page_size"`
+	TotalPages int                  `json:"total_pages"`
+}
+
+type AuditRepository interface {
+	CreateAuditEvent(ctx context.Context, event *domain.AuditEvent) error
+	ListAuditEvents(ctx context.Context, query *AuditQuery) (*AuditQueryResult, error)
+}
@@ -0,0 +1,43 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// AuditSink ships audit events to an external system, such as a SIEM
+type AuditSink interface {
+	// WriteEvents delivers a batch of events in the order they were recorded; a batch failing is
+	// written again whole, so sinks may receive an event more than once
+	WriteEvents(ctx context.Context, events []*domain.AuditEvent) error
+}
+
+// AuditExporter queues the recorded audit events for the sinks without waiting for them
+type AuditExporter interface {
+	Export(event *domain.AuditEvent)
+}
+
+// AuditExportPolicy configures how the audit events are buffered and retried for each sink
+type AuditExportPolicy struct {
+	BufferSize    int           // Events waiting for a sink; the newest events are dropped past it
+	BatchSize     int           // Events written in a single batch
+	FlushInterval time.Duration // Longest time an event waits for its batch to fill
+	MaxAttempts   int           // Attempts of a batch including the first one, after which it is dropped
+	BaseDelay     time.Duration // Upper bound of the first backoff, doubled on every further attempt
+	MaxDelay      time.Duration // Upper bound of a single backoff
+}
+
+// DefaultAuditExportPolicy buffers up to 10000 events per sink, written in batches of 100 at least
+// every 5s, making up to 8 attempts of a failed batch over at most about 90s
+func DefaultAuditExportPolicy() AuditExportPolicy {
+	return AuditExportPolicy{
+		BufferSize:    10000,
+		BatchSize:     100,
+		FlushInterval: 5 * time.Second,
+		MaxAttempts:   8,
+		BaseDelay:     time.Second,
+		MaxDelay:      30 * time.Second,
+	}
+}
@@ -0,0 +1,10 @@
+package ports
+
+import (
+	"context"
+)
+
+type AuditUseCase interface {
+	Record(ctx context.Context, action, actorID, targetID string, details map[string]string) error
+	List(ctx context.Context, query *AuditQuery) (*AuditQueryResult, error)
+}
@@ -0,0 +1,11 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type AvatarUseCase interface {
+	UploadAvatar(ctx context.Context, userID string, image []byte) (*domain.Avatar, error)
+}
@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// BreachChecker looks passwords up in the passwords of known data breaches
+type BreachChecker interface {
+	// Breaches returns how many times the password was seen in breaches, 0 if never
+	Breaches(ctx context.Context, password string) (int, error)
+}
+
+// PasswordBreachCheck configures the lookup of new passwords in known data breaches
+type PasswordBreachCheck struct {
+	Checker BreachChecker // Nil disables the check
+	// Reject refuses breached passwords instead of only warning about them in their evaluation
+	Reject bool
+	// Timeout bounds the lookup, after which the password is evaluated as if it was never breached
+	Timeout time.Duration
+}
@@ -0,0 +1,113 @@
+package ports
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDatabaseUnavailable is returned without querying the database while the circuit breaker is open
+var ErrDatabaseUnavailable = errors.New("database temporarily unavailable")
+
+// RetryPolicy configures how repository reads failing with transient database errors are retried
+type RetryPolicy struct {
+	MaxAttempts int           // Attempts of a read including the first one, 1 disables retries
+	BaseDelay   time.Duration // Upper bound of the first backoff, doubled on every further attempt
+	MaxDelay    time.Duration // Upper bound of a single backoff
+}
+
+// DefaultRetryPolicy makes up to 3 attempts, waiting a random time up to 50ms, then 100ms
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   50 * time.Millisecond,
+		MaxDelay:    time.Second,
+	}
+}
+
+// DeadlinePolicy gives the database operations of the repositories started without a deadline, e.g.
+// by a handler without one or by background work, a deadline by kind of operation; zero leaves them
+// unbounded
+type DeadlinePolicy struct {
+	FindOne time.Duration // Deadline of the reads of a single document
+	Find    time.Duration // Deadline of the queries, counts and aggregations, reading their results included
+	Write   time.Duration // Deadline of the inserts, updates and deletes
+}
+
+// DefaultDeadlinePolicy gives single document reads 2s and the other operations 5s
+func DefaultDeadlinePolicy() DeadlinePolicy {
+	return DeadlinePolicy{
+		FindOne: 2 * time.Second,
+		Find:    5 * time.Second,
+		Write:   5 * time.Second,
+	}
+}
+
+// RetryStats are counters of the retried repository reads since startup
+type RetryStats struct {
+	Retries   int64 `json:"retries" example:"14"`  // Attempts made after a transient error
+	Recovered int64 `json:"recovered" example:"9"` // Reads that succeeded after retrying
+	Exhausted int64 `json:"exhausted" example:"2"` // Reads that still failed after the last attempt
+}
+
+// RetryReporter exposes the retry counters of a repository decorator
+type RetryReporter interface {
+	RetryStats() RetryStats
+}
+
+// BreakerPolicy configures when the circuit breaker of a repository stops querying the database
+type BreakerPolicy struct {
+	FailureThreshold int           // Consecutive database failures opening the breaker
+	OpenTimeout      time.Duration // Time the breaker stays open before a probe request is let through
+}
+
+// DefaultBreakerPolicy opens the breaker after 5 consecutive failures and probes the database every 10s
+func DefaultBreakerPolicy() BreakerPolicy {
+	return BreakerPolicy{
+		FailureThreshold: 5,
+		OpenTimeout:      10 * time.Second,
+	}
+}
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"    // Requests reach the database
+	BreakerOpen     = "open"      // Requests fail at once with ErrDatabaseUnavailable
+	BreakerHalfOpen = "half_open" // A single probe request reaches the database
+)
+
+// BreakerStats describe the state of a circuit breaker and its counters since startup
+type BreakerStats struct {
+	State               string     `json:"state" example:"closed"`
+	ConsecutiveFailures int        `json:"consecutive_failures" example:"0"`
+	Opened              int64      `json:"opened" example:"1"`    // Times the breaker opened
+	Rejected            int64      `json:"rejected" example:"42"` // Calls failed without querying the database
+	OpenUntil           *time.Time `json:"open_until,omitempty" example:"2024-01-01T00:00:10Z"`
+}
+
+// BreakerReporter exposes the state of a circuit breaker
+type BreakerReporter interface {
+	BreakerStats() BreakerStats
+}
+
+// SlowQueryStats are counters of the database operations slower than the slow query threshold since startup
+type SlowQueryStats struct {
+	ThresholdMS int64            `json:"threshold_ms" example:"100"` // Zero when slow query logging is disabled
+	Count       int64            `json:"count" example:"12"`
+	Shapes      []SlowQueryShape `json:"shapes"` // Most frequent first
+}
+
+// SlowQueryShape groups the slow operations of a command on a collection with the same filter shape,
+// where every value is replaced by "?"
+type SlowQueryShape struct {
+	Command    string `json:"command" example:"find"`
+	Collection string `json:"collection" example:"users"`
+	Filter     string `json:"filter" example:"{tenant_id: ?, deleted_at: ?, profile.city: ?}"`
+	Sort       string `json:"sort,omitempty" example:"{created_at: ?}"`
+	Count      int64  `json:"count" example:"9"`
+	MaxMS      int64  `json:"max_ms" example:"850"`
+}
+
+// SlowQueryReporter exposes the slow database operations seen by a command monitor
+type SlowQueryReporter interface {
+	SlowQueryStats() SlowQueryStats
+}
@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// DigestRepository reads the account activity summarized in the admin digests
+type DigestRepository interface {
+	// CountAdminDigests returns the digests of every tenant with activity between from and to
+	CountAdminDigests(ctx context.Context, from, to time.Time) ([]*domain.AdminDigest, error)
+	// ListDigestRecipients returns the emails of the admins of the tenant of ctx, deactivated and
+	// soft-deleted accounts excluded
+	ListDigestRecipients(ctx context.Context) ([]string, error)
+}
@@ -0,0 +1,73 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// DirectoryUser is an account read from an external directory, such as LDAP or Active Directory
+type DirectoryUser struct {
+	ID        string // Stable identifier of the entry, such as its entryUUID or objectGUID
+	Email     string
+	Username  string
+	FirstName string
+	LastName  string
+	Disabled  bool // Disabled or locked in the directory
+}
+
+// DirectorySource reads the accounts of an external directory
+type DirectorySource interface {
+	ListUsers(ctx context.Context) ([]DirectoryUser, error)
+}
+
+// Actions of a directory sync on the accounts of the tenant
+const (
+	DirectorySyncCreate     = "create"
+	DirectorySyncUpdate     = "update"
+	DirectorySyncDeactivate = "deactivate"
+	DirectorySyncReactivate = "reactivate"
+	DirectorySyncFail       = "fail"
+)
+
+// DirectorySyncChange is a change a directory sync made, or would make in dry-run mode
+type DirectorySyncChange struct {
+	Action      string `json:"action" example:"update"`
+	DirectoryID string `json:"directory_id,omitempty" example:"7d3a9b0e-52c1-4a8e-9f3c-0b6e1d2f4a5c"`
+	UserID      string `json:"user_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Email       string `json:"email,omitempty" example:"john.doe@example.com"`
+	// Fields are the updated fields of the account
+	Fields []string `json:"fields,omitempty" example:"profile.last_name"`
+	// Error is why the entry could not be synced, for failed changes
+	Error string `json:"error,omitempty" example:"invalid email address"`
+}
+
+// DirectorySyncReport is the outcome of a directory sync
+type DirectorySyncReport struct {
+	StartedAt  time.Time `json:"started_at" example:"2024-01-01T00:00:00Z"`
+	FinishedAt time.Time `json:"finished_at" example:"2024-01-01T00:00:05Z"`
+	// DryRun reports the changes without making them
+	DryRun bool `json:"dry_run" example:"false"`
+	// Entries is the number of accounts read from the directory
+	Entries     int                   `json:"entries" example:"120"`
+	Created     int                   `json:"created" example:"2"`
+	Updated     int                   `json:"updated" example:"5"`
+	Deactivated int                   `json:"deactivated" example:"1"`
+	Reactivated int                   `json:"reactivated" example:"0"`
+	Unchanged   int                   `json:"unchanged" example:"112"`
+	Failed      int                   `json:"failed" example:"0"`
+	Changes     []DirectorySyncChange `json:"changes"`
+	// Error is why the sync stopped before changing any account, such as an unreachable directory
+	Error string `json:"error,omitempty" example:"dial tcp: connection refused"`
+}
+
+// DirectorySyncStatus tells whether a directory sync is running and how the last one went
+type DirectorySyncStatus struct {
+	Running bool `json:"running" example:"false"`
+	// LastReport is the report of the last finished sync, nil until a sync finished
+	LastReport *DirectorySyncReport `json:"last_report,omitempty"`
+}
+
+type DirectorySyncUseCase interface {
+	TriggerSync(ctx context.Context, actorID string, dryRun bool) error
+	Status(ctx context.Context) (*DirectorySyncStatus, error)
+}
@@ -0,0 +1,35 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// DocumentUpload is an identity document file uploaded for a user
+type DocumentUpload struct {
+	UserID string
+	// UploadedBy is the user itself or the staff member uploading the document
+	UploadedBy string
+	Type       string
+	FileName   string
+	ExpiresOn  string // Optional expiry date, YYYY-MM-DD
+	Content    []byte
+}
+
+type DocumentRepository interface {
+	CreateDocument(ctx context.Context, document *domain.Document) error
+	// GetDocument returns nil when the user has no document with the ID
+	GetDocument(ctx context.Context, userID, documentID string) (*domain.Document, error)
+	// ListDocuments returns the documents of the user, newest first
+	ListDocuments(ctx context.Context, userID string) ([]*domain.Document, error)
+	// SetDocumentsStatus sets the review status of the documents of the user with the given IDs
+	SetDocumentsStatus(ctx context.Context, userID string, documentIDs []string, status string) error
+}
+
+type DocumentUseCase interface {
+	UploadDocument(ctx context.Context, upload *DocumentUpload) (*domain.Document, error)
+	ListDocuments(ctx context.Context, userID string) ([]*domain.Document, error)
+	// PresignDownload returns a short-lived URL to the file of the document, recording the access of actorID
+	PresignDownload(ctx context.Context, actorID, userID, documentID string) (*domain.DocumentDownload, error)
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type EmailDomainRepository interface {
+	// CreateRule fails with domain.ErrEmailDomainRuleExists when the list already has the domain
+	CreateRule(ctx context.Context, rule *domain.EmailDomainRule) error
+	// ListRules returns the rules of the tenant, by list and domain
+	ListRules(ctx context.Context) ([]*domain.EmailDomainRule, error)
+	// DeleteRule reports whether the list had the domain
+	DeleteRule(ctx context.Context, list, domain string) (bool, error)
+}
+
+type EmailDomainUseCase interface {
+	// ListRules returns the configured rules followed by the rules of the tenant
+	ListRules(ctx context.Context) ([]*domain.EmailDomainRule, error)
+	AddRule(ctx context.Context, actorID, list, domain string) (*domain.EmailDomainRule, error)
+	RemoveRule(ctx context.Context, actorID, list, domain string) error
+	// CheckEmail fails with domain.ErrEmailDomainNotAllowed when the lists refuse the canonical email
+	CheckEmail(ctx context.Context, email string) error
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"io"
+)
+
+// UserExport selects the users to export and how to write them
+type UserExport struct {
+	Format string           // domain.ExportFormatNDJSON or domain.ExportFormatParquet
+	Filter *GetUsersOptions // Search, metadata, tag and inactivity filters; pagination is ignored
+	// IncludeActivity keeps the login and last-seen times, for callers with the users:activity permission
+	IncludeActivity bool
+}
+
+type ExportUseCase interface {
+	// ExportUsers streams the snapshots of the matching users to w and returns how many were written
+	ExportUsers(ctx context.Context, actorID string, export *UserExport, w io.Writer) (int, error)
+}
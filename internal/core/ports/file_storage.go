@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// FileStorage persists binary objects (e.g. avatar images) and exposes them through public URLs
+type FileStorage interface {
+	// Save stores the content under key and returns the URL clients can use to fetch it
+	Save(ctx context.Context, key, contentType string, content io.Reader) (string, error)
+}
+
+// PresignedFileStorage persists private binary objects (e.g. identity documents): the URL returned
+// by Save only serves the object once signed by PresignURL
+type PresignedFileStorage interface {
+	Save(ctx context.Context, key, contentType string, content io.Reader) (string, error)
+	// Open returns the content of the object under key, for the caller to close
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// PresignURL returns a URL granting access to the object under key until expiresAt
+	PresignURL(ctx context.Context, key string, expiresAt time.Time) (string, error)
+}
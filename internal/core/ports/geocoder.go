@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// Geocoder resolves postal addresses to coordinates
+type Geocoder interface {
+	// Geocode returns the coordinates of the address, or nil if the provider cannot locate it
+	Geocode(ctx context.Context, address domain.Address) (*domain.GeoPoint, error)
+}
+
+// AddressGeocoding configures the geocoding of addresses when they are written
+type AddressGeocoding struct {
+	Geocoder Geocoder // Nil disables geocoding
+	// RejectUnknown refuses addresses the geocoder cannot locate instead of storing them without coordinates
+	RejectUnknown bool
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// GeoIPResolver locates IP addresses
+type GeoIPResolver interface {
+	// Resolve returns the location of the IP address, or nil if it is unknown, e.g. for private networks
+	Resolve(ctx context.Context, ip string) (*domain.IPLocation, error)
+}
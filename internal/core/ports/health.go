@@ -0,0 +1,51 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// Health statuses of a dependency, and of the instance as a whole
+const (
+	HealthUp       = "up"
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+)
+
+// DependencyHealth is the outcome of the check of a dependency of the instance
+type DependencyHealth struct {
+	Name   string `json:"name" example:"mongodb"`
+	Status string `json:"status" example:"up" enums:"up,degraded,down"`
+	// Critical dependencies being down make the instance not ready, the others degrade it
+	Critical  bool    `json:"critical" example:"true"`
+	LatencyMS float64 `json:"latency_ms,omitempty" example:"1.25"`
+	Version   string  `json:"version,omitempty" example:"7.0.12"`
+	Error     string  `json:"error,omitempty" example:"connection refused"`
+	// Details are specific to the dependency, such as the number of live background workers
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// HealthChecker checks a dependency within the deadline of ctx; the readiness check sets the name
+// and criticality of the result
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) DependencyHealth
+}
+
+// HealthCheck is a dependency checked for the readiness of the instance
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	Checker  HealthChecker
+}
+
+// ReadinessReport is the health of every dependency of the instance: down when a critical one is
+// down, degraded when another one is not up
+type ReadinessReport struct {
+	Status       string             `json:"status" example:"degraded" enums:"up,degraded,down"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+	CheckedAt    time.Time          `json:"checked_at" example:"2024-01-01T00:00:00Z"`
+}
+
+type ReadinessUseCase interface {
+	CheckReadiness(ctx context.Context) *ReadinessReport
+}
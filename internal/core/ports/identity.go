@@ -0,0 +1,34 @@
+package ports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// ErrIdentityProviderUnavailable is returned when the signing keys of an identity provider cannot be fetched
+var ErrIdentityProviderUnavailable = errors.New("identity provider temporarily unavailable")
+
+// ExternalToken holds the claims of a verified token of an external identity provider
+type ExternalToken struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Username      string
+	FirstName     string
+	LastName      string
+}
+
+// TokenVerifier verifies the tokens of an external identity provider, such as Firebase Auth or
+// Keycloak; tokens it cannot verify yield security.ErrInvalidToken
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*ExternalToken, error)
+}
+
+// ExternalAuthUseCase authenticates the users of the tenant with the tokens of an external identity provider
+type ExternalAuthUseCase interface {
+	// AuthenticateToken returns the user the token was issued to, creating it on its first request
+	AuthenticateToken(ctx context.Context, token string) (*domain.User, error)
+}
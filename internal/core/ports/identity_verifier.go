@@ -0,0 +1,43 @@
+package ports
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// ErrInvalidWebhookSignature is returned for webhook deliveries not signed by the provider
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// IdentityCheckDocument is a submitted document with the content of its file
+type IdentityCheckDocument struct {
+	Document *domain.Document
+	Content  []byte
+}
+
+// IdentityCheckRequest is the user and the documents an automated identity check runs on
+type IdentityCheckRequest struct {
+	User      *domain.User
+	Documents []IdentityCheckDocument
+}
+
+// IdentityCheckResult is the outcome of a check reported to the webhook: clear or consider
+type IdentityCheckResult struct {
+	CheckID string
+	Result  string
+}
+
+// IdentityVerifier runs automated identity checks with an identity verification provider, such as
+// Onfido. Checks run asynchronously, the provider reporting their result to the webhook of the API.
+type IdentityVerifier interface {
+	// Provider names the provider, recorded on the checks it runs
+	Provider() string
+	// StartCheck submits the documents to the provider and returns the ID of the check started, or
+	// "" when the provider cannot check any of the documents
+	StartCheck(ctx context.Context, request *IdentityCheckRequest) (string, error)
+	// ParseWebhook authenticates a webhook delivery, failing with ErrInvalidWebhookSignature, and
+	// returns the result of the check it reports, or nil for deliveries about anything else
+	ParseWebhook(ctx context.Context, header http.Header, body []byte) (*IdentityCheckResult, error)
+}
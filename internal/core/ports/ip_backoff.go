@@ -0,0 +1,53 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// BackoffPolicy configures how IPs failing to log in are blocked
+type BackoffPolicy struct {
+	Threshold  int           // Failures allowed before the IP is blocked
+	BaseDelay  time.Duration // Block duration at the threshold, doubled on every further failure
+	MaxDelay   time.Duration // Upper bound of a single block
+	ResetAfter time.Duration // Failures are forgotten after this long without a new one
+}
+
+// DefaultBackoffPolicy blocks an IP for 1s after 5 failures, doubling up to 1h, and forgets it after 24h
+func DefaultBackoffPolicy() BackoffPolicy {
+	return BackoffPolicy{
+		Threshold:  5,
+		BaseDelay:  time.Second,
+		MaxDelay:   time.Hour,
+		ResetAfter: 24 * time.Hour,
+	}
+}
+
+// IPBlock describes an IP currently blocked from logging in
+type IPBlock struct {
+	IP            string    `json:"ip" example:"203.0.113.7"`
+	Failures      int       `json:"failures" example:"8"`
+	LastFailureAt time.Time `json:"last_failure_at" example:"2024-01-01T00:00:00Z"`
+	BlockedUntil  time.Time `json:"blocked_until" example:"2024-01-01T00:00:08Z"`
+}
+
+// IPBackoffStats are counters of the login failure tracking since startup
+type IPBackoffStats struct {
+	Failures         int64 `json:"failures" example:"124"`         // Failed logins recorded
+	RejectedRequests int64 `json:"rejected_requests" example:"37"` // Logins refused because the IP was blocked
+	BlocksIssued     int64 `json:"blocks_issued" example:"12"`     // Times an IP was (re)blocked
+	ActiveBlocks     int   `json:"active_blocks" example:"2"`      // IPs currently blocked in the tenant
+	TrackedIPs       int   `json:"tracked_ips" example:"15"`       // IPs with recent failures in the tenant
+}
+
+// IPBackoff tracks failed logins per IP of the request tenant and blocks IPs exceeding the policy
+// with exponentially growing durations, whatever accounts they target
+type IPBackoff interface {
+	// Check returns how long the IP is still blocked, 0 when it may log in
+	Check(ctx context.Context, ip string) (time.Duration, error)
+	RecordFailure(ctx context.Context, ip string) error
+	ListBlocks(ctx context.Context) ([]IPBlock, error)
+	// ClearBlock forgets the failures of the IP and reports whether it was tracked
+	ClearBlock(ctx context.Context, ip string) (bool, error)
+	Stats(ctx context.Context) (*IPBackoffStats, error)
+}
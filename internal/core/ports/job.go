@@ -0,0 +1,58 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// JobQuery filters and paginates jobs; empty fields match every job
+type JobQuery struct {
+	Status   domain.JobStatus
+	Type     string
+	Page     int
+	PageSize int
+}
+
+// JobQueryResult contains paginated jobs, newest first
+type JobQueryResult struct {
+	Jobs       []*domain.Job `json:"jobs"`
+	TotalCount int64         `json:"total_count"`
+	Page       int           `json:"page"`
+	PageSize   int           `json:"page_size"`
+	TotalPages int           `json:"total_pages"`
+}
+
+// JobRepository stores the background jobs. Workers claim and update the jobs of every tenant,
+// the other methods are scoped to the tenant of ctx.
+type JobRepository interface {
+	// CreateJob stores a job of the tenant, failing with domain.ErrJobExists when a job has its unique key
+	CreateJob(ctx context.Context, job *domain.Job) error
+	// ClaimJob marks as running until lockedUntil the next job of the types due at now, queued or
+	// given up by a stopped worker, counting an attempt; it returns nil when no job is due
+	ClaimJob(ctx context.Context, types []string, now, lockedUntil time.Time) (*domain.Job, error)
+	// UpdateJob saves the job after a run, whatever its tenant
+	UpdateJob(ctx context.Context, job *domain.Job) error
+	ListJobs(ctx context.Context, query *JobQuery) (*JobQueryResult, error)
+	GetJob(ctx context.Context, id string) (*domain.Job, error)
+	// RetryJob queues the job again when it is failed, reporting whether it was
+	RetryJob(ctx context.Context, job *domain.Job) (bool, error)
+}
+
+// JobHandler runs a job; the jobs it fails are retried
+type JobHandler func(ctx context.Context, job *domain.Job) error
+
+// JobQueue runs work in the background workers
+type JobQueue interface {
+	// Enqueue stores a job of the type for the tenant of ctx, run by the handler of the type
+	Enqueue(ctx context.Context, jobType string, payload map[string]string) (*domain.Job, error)
+}
+
+type JobUseCase interface {
+	Enqueue(ctx context.Context, jobType string, payload map[string]string) (*domain.Job, error)
+	ListJobs(ctx context.Context, query *JobQuery) (*JobQueryResult, error)
+	GetJob(ctx context.Context, id string) (*domain.Job, error)
+	// RetryJob queues a failed job again with all its attempts
+	RetryJob(ctx context.Context, actorID, id string) (*domain.Job, error)
+}
@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// LoginHistory contains paginated login events of a user, newest first
+type LoginHistory struct {
+	Events     []*domain.LoginEvent `json:"events"`
+	TotalCount int64                `json:"total_count"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"page_size"`
+	TotalPages int                  `json:"total_pages"`
+}
+
+type LoginEventRepository interface {
+	CreateLoginEvent(ctx context.Context, event *domain.LoginEvent) error
+	// LastLoginEvent returns the latest login of the user, from the given device if fingerprint is not empty
+	LastLoginEvent(ctx context.Context, userID, fingerprint string) (*domain.LoginEvent, error)
+	ListLoginEvents(ctx context.Context, userID string, page, pageSize int) (*LoginHistory, error)
+	// ListNewDeviceLogins returns up to limit logins of the user from new devices before the given time, newest first
+	ListNewDeviceLogins(ctx context.Context, userID string, before time.Time, limit int) ([]*domain.LoginEvent, error)
+	GetLoginEventByReportToken(ctx context.Context, tokenHash string) (*domain.LoginEvent, error)
+	SetLoginEventReported(ctx context.Context, id string, at time.Time) error
+}
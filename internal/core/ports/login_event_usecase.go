@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type LoginEventUseCase interface {
+	// AssessLogin checks a login about to succeed against the login anomaly rules, before any second factor
+	AssessLogin(ctx context.Context, user *domain.User, ip, userAgent string) ([]domain.LoginAnomaly, error)
+	// RecordLogin stores a successful login and notifies the user when it comes from a new device
+	RecordLogin(ctx context.Context, user *domain.User, ip, userAgent string) (*domain.LoginEvent, error)
+	ListLoginHistory(ctx context.Context, userID string, page, pageSize int) (*LoginHistory, error)
+	// ReportLogin handles the "wasn't me" link of a new-device notification
+	ReportLogin(ctx context.Context, token string) error
+}
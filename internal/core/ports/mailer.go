@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+)
+
+// EmailMessage is a plain-text email sent to a single recipient
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers emails to users
+type Mailer interface {
+	Send(ctx context.Context, message EmailMessage) error
+}
@@ -0,0 +1,33 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type MFAUseCase interface {
+	// ListFactors returns the second factors of the user
+	ListFactors(ctx context.Context, userID string) ([]domain.MFAFactor, error)
+	// AddFactor adds a TOTP factor, to confirm with a first code, or an SMS factor texting the
+	// verified phone of the user
+	AddFactor(ctx context.Context, userID, factorType, label string) (*domain.MFAEnrollment, error)
+	// ConfirmFactor activates a TOTP factor with a code of the authenticator app
+	ConfirmFactor(ctx context.Context, userID, factorID, code string) (*domain.MFAFactor, error)
+	RemoveFactor(ctx context.Context, userID, factorID string) error
+	// SendCode texts a login code to an SMS factor and returns when it expires
+	SendCode(ctx context.Context, userID, factorID string) (time.Time, error)
+	// Verify checks a login code of a confirmed factor of the user
+	Verify(ctx context.Context, userID, factorID, code string) error
+
+	// TrustDevice remembers the device of a login with a second factor and returns the token it
+	// sends to skip the second factor
+	TrustDevice(ctx context.Context, userID, userAgent, ip string) (*domain.TrustedDevice, string, error)
+	// UseTrustedDevice reports whether token is the token of a device the user still trusts
+	UseTrustedDevice(ctx context.Context, user *domain.User, token string) (bool, error)
+	ListTrustedDevices(ctx context.Context, userID string) ([]domain.TrustedDevice, error)
+	RevokeTrustedDevice(ctx context.Context, userID, deviceID string) error
+	// RevokeTrustedDevices forgets every trusted device of the user and returns how many there were
+	RevokeTrustedDevices(ctx context.Context, userID string) (int, error)
+}
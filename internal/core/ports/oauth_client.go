@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type OAuthClientRepository interface {
+	CreateClient(ctx context.Context, client *domain.OAuthClient) error
+	// GetClientByID returns nil when the tenant has no client with the ID
+	GetClientByID(ctx context.Context, id string) (*domain.OAuthClient, error)
+	ListClients(ctx context.Context) ([]*domain.OAuthClient, error)
+	// DeleteClient reports whether the client existed
+	DeleteClient(ctx context.Context, id string) (bool, error)
+}
+
+type OAuthClientUseCase interface {
+	// RegisterClient returns the client and its secret, which cannot be read again
+	RegisterClient(ctx context.Context, actorID, name string, scopes []string) (*domain.OAuthClient, string, error)
+	GetClient(ctx context.Context, id string) (*domain.OAuthClient, error)
+	ListClients(ctx context.Context) ([]*domain.OAuthClient, error)
+	DeleteClient(ctx context.Context, actorID, id string) error
+	// AuthenticateClient returns the client with the ID when secret is its secret
+	AuthenticateClient(ctx context.Context, id, secret string) (*domain.OAuthClient, error)
+}
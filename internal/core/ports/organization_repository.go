@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type OrganizationRepository interface {
+	CreateOrganization(ctx context.Context, org *domain.Organization) error
+	GetOrganizationByID(ctx context.Context, id string) (*domain.Organization, error)
+	GetOrganizationBySlug(ctx context.Context, slug string) (*domain.Organization, error)
+	UpdateOrganization(ctx context.Context, org *domain.Organization) error
+	DeleteOrganization(ctx context.Context, id string) error
+
+	CreateMembership(ctx context.Context, membership *domain.Membership) error
+	GetMembership(ctx context.Context, organizationID, userID string) (*domain.Membership, error)
+	ListMembers(ctx context.Context, organizationID string) ([]*domain.Membership, error)
+	ListUserMemberships(ctx context.Context, userID string) ([]*domain.Membership, error)
+	UpdateMembershipRole(ctx context.Context, organizationID, userID, role string) error
+	CountMembersWithRole(ctx context.Context, organizationID, role string) (int64, error)
+	DeleteMembership(ctx context.Context, organizationID, userID string) error
+}
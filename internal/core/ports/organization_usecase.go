@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type OrganizationUseCase interface {
+	CreateOrganization(ctx context.Context, actorID, name, slug string) (*domain.Organization, error)
+	GetOrganization(ctx context.Context, actorID, id string) (*domain.Organization, error)
+	UpdateOrganization(ctx context.Context, actorID, id, name string) (*domain.Organization, error)
+	DeleteOrganization(ctx context.Context, actorID, id string) error
+	ListMembers(ctx context.Context, actorID, organizationID string) ([]*domain.Membership, error)
+	SetMember(ctx context.Context, actorID, organizationID, userID, role string) (*domain.Membership, error)
+	RemoveMember(ctx context.Context, actorID, organizationID, userID string) error
+	ListUserMemberships(ctx context.Context, userID string) ([]*domain.Membership, error)
+}
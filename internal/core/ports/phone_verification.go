@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type PhoneVerificationUseCase interface {
+	// SendCode texts a one-time code to the phone of the user and returns when it expires
+	SendCode(ctx context.Context, userID string) (time.Time, error)
+	// ConfirmCode marks the phone of the user verified when code is the one last sent to it
+	ConfirmCode(ctx context.Context, userID, code string) (*domain.User, error)
+}
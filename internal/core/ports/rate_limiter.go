@@ -0,0 +1,62 @@
+package ports
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit allows Requests requests per Window
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// ParseRateLimit parses a limit written as "<requests>/<window>", e.g. "10/1m"
+func ParseRateLimit(value string) (RateLimit, error) {
+	requests, window, found := strings.Cut(value, "/")
+	if !found {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: expected <requests>/<window>", value)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n < 1 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive integer", value)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: window must be a positive duration", value)
+	}
+	return RateLimit{Requests: n, Window: d}, nil
+}
+
+// RateLimitResult is the outcome of a rate limit check
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // Time until the next request is allowed, set when Allowed is false
+}
+
+// RateLimiter counts requests per key and decides whether another one is allowed
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (*RateLimitResult, error)
+}
+
+// String returns the limit as "<requests>/<window>", as ParseRateLimit reads it
+func (l RateLimit) String() string {
+	return strconv.Itoa(l.Requests) + "/" + l.Window.String()
+}
+
+func (l RateLimit) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *RateLimit) UnmarshalText(text []byte) error {
+	limit, err := ParseRateLimit(string(text))
+	if err != nil {
+		return err
+	}
+	*l = limit
+	return nil
+}
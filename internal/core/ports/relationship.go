@@ -0,0 +1,74 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// RelationshipQuery paginates the followers, followed users or blocks of a user
+type RelationshipQuery struct {
+	UserID string
+	// ViewerID is the authenticated caller, empty for anonymous requests
+	ViewerID string
+	Page     int
+	PageSize int
+}
+
+// RelationshipQueryResult contains paginated relationships, newest first
+type RelationshipQueryResult struct {
+	Relationships []*domain.Relationship `json:"relationships"`
+	TotalCount    int64                  `json:"total_count"`
+	Page          int                    `json:"page"`
+	PageSize      int                    `json:"page_size"`
+	TotalPages    int                    `json:"total_pages"`
+}
+
+// BlockQueryResult contains paginated blocks, newest first
+type BlockQueryResult struct {
+	Blocks     []*domain.Block `json:"blocks"`
+	TotalCount int64           `json:"total_count"`
+	Page       int             `json:"page"`
+	PageSize   int             `json:"page_size"`
+	TotalPages int             `json:"total_pages"`
+}
+
+type RelationshipRepository interface {
+	// CreateRelationship stores a relationship, failing with domain.ErrRelationshipExists when the
+	// follower already follows the followee
+	CreateRelationship(ctx context.Context, relationship *domain.Relationship) error
+	GetRelationship(ctx context.Context, followerID, followeeID string) (*domain.Relationship, error)
+	// DeleteRelationship reports whether the follower followed the followee
+	DeleteRelationship(ctx context.Context, followerID, followeeID string) (bool, error)
+	ListFollowers(ctx context.Context, query *RelationshipQuery) (*RelationshipQueryResult, error)
+	ListFollowing(ctx context.Context, query *RelationshipQuery) (*RelationshipQueryResult, error)
+	CountRelationships(ctx context.Context, userID string) (*domain.RelationshipCounts, error)
+	// CreateBlock stores a block, failing with domain.ErrBlockExists when the blocker already blocks
+	// the blocked user
+	CreateBlock(ctx context.Context, block *domain.Block) error
+	GetBlock(ctx context.Context, blockerID, blockedID string) (*domain.Block, error)
+	// DeleteBlock reports whether the blocker blocked the blocked user
+	DeleteBlock(ctx context.Context, blockerID, blockedID string) (bool, error)
+	// ListBlocks paginates the blocks of the query user
+	ListBlocks(ctx context.Context, query *RelationshipQuery) (*BlockQueryResult, error)
+	// ListBlockers returns the IDs, among userIDs, of the users blocking the blocked user
+	ListBlockers(ctx context.Context, blockedID string, userIDs []string) ([]string, error)
+}
+
+type RelationshipUseCase interface {
+	// Follow makes the follower follow the followee, returning the existing relationship when it already does
+	Follow(ctx context.Context, followerID, followeeID string) (*domain.Relationship, error)
+	Unfollow(ctx context.Context, followerID, followeeID string) error
+	// ListFollowers and ListFollowing fail with usecase.ErrUserNotFound when the query user blocks the viewer
+	ListFollowers(ctx context.Context, query *RelationshipQuery) (*RelationshipQueryResult, error)
+	ListFollowing(ctx context.Context, query *RelationshipQuery) (*RelationshipQueryResult, error)
+	CountRelationships(ctx context.Context, userID string) (*domain.RelationshipCounts, error)
+	// Block makes the blocker block the blocked user and removes their relationships in both
+	// directions, returning the existing block when it already does
+	Block(ctx context.Context, blockerID, blockedID string) (*domain.Block, error)
+	Unblock(ctx context.Context, blockerID, blockedID string) error
+	ListBlocks(ctx context.Context, query *RelationshipQuery) (*BlockQueryResult, error)
+	// ListBlockers returns the IDs, among userIDs, of the users blocking the viewer; anonymous
+	// viewers, with an empty ID, are blocked by no one
+	ListBlockers(ctx context.Context, viewerID string, userIDs []string) ([]string, error)
+}
@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// RetentionRepository removes the data of every tenant past its retention window. With dryRun
+// set, its methods only count the documents they would change.
+type RetentionRepository interface {
+	// DeleteUsers removes the users soft-deleted before deletedBefore
+	DeleteUsers(ctx context.Context, deletedBefore time.Time, dryRun bool) (int64, error)
+	// AnonymizeUsers replaces the personal data of the users soft-deleted before deletedBefore,
+	// see domain.User.Anonymize; users already anonymized are left alone
+	AnonymizeUsers(ctx context.Context, deletedBefore time.Time, dryRun bool) (int64, error)
+	DeleteLoginEvents(ctx context.Context, createdBefore time.Time, dryRun bool) (int64, error)
+	// ClearLoginReportTokens removes the tokens of the "wasn't me" links sent before createdBefore
+	ClearLoginReportTokens(ctx context.Context, createdBefore time.Time, dryRun bool) (int64, error)
+}
+
+// RetentionReport is the outcome of a retention purge, the counts of what it removed or, in dry-run
+// mode, would remove
+type RetentionReport struct {
+	StartedAt         time.Time `json:"started_at" example:"2024-01-01T00:00:00Z"`
+	FinishedAt        time.Time `json:"finished_at" example:"2024-01-01T00:00:02Z"`
+	DryRun            bool      `json:"dry_run" example:"false"`
+	DeletedUsers      int64     `json:"deleted_users" example:"3"`
+	AnonymizedUsers   int64     `json:"anonymized_users" example:"0"`
+	LoginEvents       int64     `json:"login_events" example:"1520"`
+	LoginReportTokens int64     `json:"login_report_tokens" example:"12"`
+}
+
+type RetentionUseCase interface {
+	// Purge removes the data past its retention window, or only counts it when dryRun is set
+	Purge(ctx context.Context, dryRun bool) (*RetentionReport, error)
+}
@@ -0,0 +1,16 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type RoleRepository interface {
+	CreateRole(ctx context.Context, role *domain.Role) error
+	GetRoleByName(ctx context.Context, name string) (*domain.Role, error)
+	GetRolesByNames(ctx context.Context, names []string) ([]*domain.Role, error)
+	ListRoles(ctx context.Context) ([]*domain.Role, error)
+	UpdateRole(ctx context.Context, role *domain.Role) error
+	DeleteRole(ctx context.Context, name string) error
+}
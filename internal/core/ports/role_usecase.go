@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type RoleUseCase interface {
+	ListRoles(ctx context.Context) ([]*domain.Role, error)
+	GetRole(ctx context.Context, name string) (*domain.Role, error)
+	CreateRole(ctx context.Context, name, description string, permissions []string) (*domain.Role, error)
+	UpdateRole(ctx context.Context, name string, description *string, permissions []string) (*domain.Role, error)
+	DeleteRole(ctx context.Context, name string) error
+	AssignRole(ctx context.Context, userID, role string) ([]string, error)
+	UnassignRole(ctx context.Context, userID, role string) ([]string, error)
+	HasPermission(ctx context.Context, roles []string, permission string) (bool, error)
+	// CoversRoles reports whether roles grant every permission granted by the roles others
+	CoversRoles(ctx context.Context, roles, others []string) (bool, error)
+}
@@ -0,0 +1,85 @@
+package ports
+
+import (
+	"context"
+	"maps"
+	"slices"
+)
+
+// Log levels of the request log: debug and info log every request, warn the requests failing with a
+// 4xx or 5xx status and error the ones failing with a 5xx status
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// LogLevels lists the valid log levels
+var LogLevels = []string{LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError}
+
+// RuntimeConfig is the configuration of the instance that is reloaded without restarting it. A
+// RuntimeConfig is never modified once in use, reloads replace it.
+type RuntimeConfig struct {
+	LogLevel   string            `json:"log_level" example:"info" enums:"debug,info,warn,error"`
+	RateLimits RuntimeRateLimits `json:"rate_limits"`
+	// Features are the feature flags served to clients at /api/v1/features
+	Features map[string]bool `json:"features"`
+	// CORSOrigins are the origins allowed to call the API from browsers, "*" allowing any
+	CORSOrigins []string `json:"cors_origins" example:"https://app.example.com"`
+}
+
+// RuntimeRateLimits are the request limits of each endpoint class, applied per IP and per account
+type RuntimeRateLimits struct {
+	API        RateLimit `json:"api" swaggertype:"string" example:"300/1m0s"`
+	Auth       RateLimit `json:"auth" swaggertype:"string" example:"10/1m0s"`
+	EmailCheck RateLimit `json:"check_email" swaggertype:"string" example:"10/1m0s"`
+}
+
+// RuntimeConfigUpdate changes the fields of a RuntimeConfig that are set: Features and CORSOrigins
+// are replaced as a whole, an empty object or list clearing them
+type RuntimeConfigUpdate struct {
+	LogLevel    *string                 `json:"log_level,omitempty" example:"warn" enums:"debug,info,warn,error"`
+	RateLimits  RuntimeRateLimitsUpdate `json:"rate_limits"`
+	Features    map[string]bool         `json:"features,omitempty"`
+	CORSOrigins []string                `json:"cors_origins,omitempty" example:"https://app.example.com"`
+}
+
+// RuntimeRateLimitsUpdate changes the rate limits that are set, written as "<requests>/<window>"
+type RuntimeRateLimitsUpdate struct {
+	API        *RateLimit `json:"api,omitempty" swaggertype:"string" example:"600/1m"`
+	Auth       *RateLimit `json:"auth,omitempty" swaggertype:"string" example:"5/1m"`
+	EmailCheck *RateLimit `json:"check_email,omitempty" swaggertype:"string" example:"20/1m"`
+}
+
+// Updated returns a copy of the configuration with the fields of update that are set
+func (c RuntimeConfig) Updated(update *RuntimeConfigUpdate) RuntimeConfig {
+	if update.LogLevel != nil {
+		c.LogLevel = *update.LogLevel
+	}
+	if update.RateLimits.API != nil {
+		c.RateLimits.API = *update.RateLimits.API
+	}
+	if update.RateLimits.Auth != nil {
+		c.RateLimits.Auth = *update.RateLimits.Auth
+	}
+	if update.RateLimits.EmailCheck != nil {
+		c.RateLimits.EmailCheck = *update.RateLimits.EmailCheck
+	}
+	if update.Features != nil {
+		c.Features = maps.Clone(update.Features)
+	}
+	if update.CORSOrigins != nil {
+		c.CORSOrigins = slices.Clone(update.CORSOrigins)
+	}
+	return c
+}
+
+type ConfigUseCase interface {
+	// Config returns the configuration in use, cheaply enough to be called on every request
+	Config() *RuntimeConfig
+	// UpdateConfig validates and applies the update of an admin of the managing tenant
+	UpdateConfig(ctx context.Context, actorID string, update *RuntimeConfigUpdate) (*RuntimeConfig, error)
+	// ReloadConfig validates and applies the update read again from the configuration of the instance
+	ReloadConfig(ctx context.Context, update *RuntimeConfigUpdate) (*RuntimeConfig, error)
+}
@@ -0,0 +1,29 @@
+package ports
+
+import "time"
+
+// Outcomes of the runs of a scheduled task
+const (
+	TaskSucceeded = "succeeded"
+	TaskFailed    = "failed"
+)
+
+// ScheduledTaskStats describe a periodic task of the scheduler and its runs since startup
+type ScheduledTaskStats struct {
+	Name     string `json:"name" example:"retention.purge"`
+	Schedule string `json:"schedule" example:"0 3 * * *"`
+	// NextRunAt is when the task runs next, jitter excluded
+	NextRunAt   *time.Time `json:"next_run_at,omitempty" example:"2024-01-02T03:00:00Z"`
+	LastRunAt   *time.Time `json:"last_run_at,omitempty" example:"2024-01-01T03:00:07Z"`
+	LastOutcome string     `json:"last_outcome,omitempty" example:"succeeded" enums:"succeeded,failed"`
+	LastError   string     `json:"last_error,omitempty" example:"dial tcp: connection refused"`
+	LastMS      int64      `json:"last_duration_ms" example:"42"`
+	Runs        int64      `json:"runs" example:"12"`
+	Failures    int64      `json:"failures" example:"1"`
+	Running     bool       `json:"running" example:"false"`
+}
+
+// SchedulerReporter exposes the periodic tasks of a scheduler
+type SchedulerReporter interface {
+	ScheduledTasks() []ScheduledTaskStats
+}
@@ -0,0 +1,70 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// UserSearchQuery is a full-text query of the users of the tenant of the context
+type UserSearchQuery struct {
+	Text     string   // Matched against the words of the email, username, first and last name
+	Tags     []string // Only users having all of these tags
+	SortBy   string   // email, created_at, updated_at, first_name or last_name
+	Order    string   // asc or desc
+	Page     int      // Page number (1-based)
+	PageSize int
+	// Facets are counted over every matching user, see domain.UserFacets
+	Facets []string
+}
+
+// UserSearchResult is a page of the IDs of the users matching a query or list, in order
+type UserSearchResult struct {
+	IDs        []string
+	TotalCount int64
+	Facets     map[string][]FacetCount
+}
+
+// UserProjection is a copy of the users shaped for queries, kept eventually consistent with the users
+// collection, which remains the source of truth, by applying its changes
+type UserProjection interface {
+	// SaveUser adds or replaces the document of the user
+	SaveUser(ctx context.Context, user *domain.User) error
+	// RemoveUser removes the document of the user, if any
+	RemoveUser(ctx context.Context, id string) error
+}
+
+// SearchIndex is a secondary index of the users serving full-text and faceted queries, and a
+// UserProjection
+type SearchIndex interface {
+	SaveUser(ctx context.Context, user *domain.User) error
+	RemoveUser(ctx context.Context, id string) error
+	SearchUsers(ctx context.Context, query UserSearchQuery) (*UserSearchResult, error)
+}
+
+// UserReadModel is a denormalized copy of the users with flat fields serving the user lists, and a
+// UserProjection
+type UserReadModel interface {
+	SaveUser(ctx context.Context, user *domain.User) error
+	RemoveUser(ctx context.Context, id string) error
+	// ListUsers returns the page of the IDs of the users matching opts, which has no structured filter,
+	// distance filter, field selection nor explain
+	ListUsers(ctx context.Context, opts *GetUsersOptions) (*UserSearchResult, error)
+}
+
+// UserChange is a change to the users collection; User is the document after the change, nil when
+// the user was deleted or soft deleted
+type UserChange struct {
+	UserID string
+	User   *domain.User
+}
+
+// UserChangeStream reads the changes to the users of every tenant as they are written
+type UserChangeStream interface {
+	// Watch calls handle for each change, in order, until ctx is done or the stream fails. It resumes
+	// after the last handled change of a previous watch, or else first calls handle with every user.
+	Watch(ctx context.Context, handle func(ctx context.Context, change UserChange) error) error
+	// Synced reports whether a watch handled every user once, after which the projection it feeds
+	// holds every user
+	Synced(ctx context.Context) (bool, error)
+}
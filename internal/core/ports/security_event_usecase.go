@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// SecurityEventFeed contains security events of a user, newest first
+type SecurityEventFeed struct {
+	Events []domain.SecurityEvent `json:"events"`
+	// NextBefore is the before parameter of the next page, unset on the last page
+	NextBefore *time.Time `json:"next_before,omitempty" example:"2024-01-01T00:00:00Z"`
+}
+
+type SecurityEventUseCase interface {
+	// ListSecurityEvents returns up to limit security events of the user that occurred before the given time
+	ListSecurityEvents(ctx context.Context, userID string, before time.Time, limit int) (*SecurityEventFeed, error)
+}
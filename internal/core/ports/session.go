@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session *domain.Session) error
+	// GetSession returns the session with the ID, or nil when there is none
+	GetSession(ctx context.Context, id string) (*domain.Session, error)
+	SetSessionAuthTime(ctx context.Context, id string, at time.Time) error
+	SetSessionCSRFTokenHash(ctx context.Context, id, csrfTokenHash string) error
+	DeleteSession(ctx context.Context, id string) error
+}
+
+type SessionUseCase interface {
+	// CreateSession logs the user in a new session restricted to the scopes and returns it with the
+	// tokens of its cookie and of its CSRF header
+	CreateSession(ctx context.Context, user *domain.User, scopes []string, ip, userAgent string) (*domain.Session, string, string, error)
+	// Authenticate returns the unexpired session of a cookie token, or domain.ErrSessionNotFound
+	Authenticate(ctx context.Context, token string) (*domain.Session, error)
+	// Reauthenticated records that the user of the session just proved their password or a second factor
+	Reauthenticated(ctx context.Context, session *domain.Session) error
+	// RotateCSRFToken replaces the CSRF token of the session, the previous one no longer passing, and returns the new one
+	RotateCSRFToken(ctx context.Context, session *domain.Session) (string, error)
+	// EndSession logs the session out
+	EndSession(ctx context.Context, session *domain.Session) error
+}
@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"context"
+	"crypto"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type SigningKeyRepository interface {
+	// ListSigningKeys returns the keys not expired yet, by activation time
+	ListSigningKeys(ctx context.Context) ([]*domain.SigningKey, error)
+	CreateSigningKey(ctx context.Context, key *domain.SigningKey) error
+	// ExpireSigningKeys makes the keys other than keepID expire at expiresAt, unless they expire earlier
+	ExpireSigningKeys(ctx context.Context, keepID string, expiresAt time.Time) error
+}
+
+// KeyRing signs and verifies the access tokens with the keys it is given, see security.TokenManager
+type KeyRing interface {
+	SetSigningKeys(signer crypto.Signer, verifying []crypto.PublicKey) error
+}
+
+type SigningKeyUseCase interface {
+	ListSigningKeys(ctx context.Context) ([]*domain.SigningKey, error)
+	// RotateSigningKey adds a key replacing the signing key once every instance has loaded it
+	RotateSigningKey(ctx context.Context, actorID string) (*domain.SigningKey, error)
+}
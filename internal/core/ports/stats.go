@@ -0,0 +1,51 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// RegistrationsQuery counts the users registered from From to To, by bucket of Interval
+type RegistrationsQuery struct {
+	Interval string
+	From     time.Time
+	To       time.Time
+}
+
+// RegistrationBucket counts the users registered during the interval starting at Start
+type RegistrationBucket struct {
+	Start time.Time `json:"start" bson:"_id" example:"2024-01-01T00:00:00Z"`
+	Count int64     `json:"count" bson:"count" example:"14"`
+}
+
+// RegistrationsReport contains the registrations of a period by bucket, oldest first, with a bucket
+// for every interval of the period, counting zero when no user registered
+type RegistrationsReport struct {
+	Interval string               `json:"interval" example:"day" enums:"day,week,month"`
+	From     time.Time            `json:"from" example:"2024-01-01T00:00:00Z"`
+	To       time.Time            `json:"to" example:"2024-01-31T00:00:00Z"`
+	Total    int64                `json:"total" example:"420"`
+	Buckets  []RegistrationBucket `json:"buckets"`
+}
+
+// StatsRepository rolls up and stores the daily user statistics
+type StatsRepository interface {
+	// RollUpUserStats computes the statistics of every tenant on the UTC day starting at day,
+	// replacing those computed before, and returns how many tenants had users
+	RollUpUserStats(ctx context.Context, day time.Time) (int, error)
+	// ListUserStats returns the statistics of the tenant of ctx from the day since on, oldest first
+	ListUserStats(ctx context.Context, since time.Time) ([]*domain.UserStats, error)
+	// CountRegistrations returns the buckets of the tenant of ctx where users registered, oldest
+	// first; soft-deleted users count as registered
+	CountRegistrations(ctx context.Context, query *RegistrationsQuery) ([]RegistrationBucket, error)
+}
+
+type StatsUseCase interface {
+	// ListUserStats returns the statistics of the last days, oldest first; days without users are left out
+	ListUserStats(ctx context.Context, days int) ([]*domain.UserStats, error)
+	// CountRegistrations counts the registrations by bucket; a zero From or To defaults to the last
+	// 30 days, 12 weeks or 12 months
+	CountRegistrations(ctx context.Context, query *RegistrationsQuery) (*RegistrationsReport, error)
+}
@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type TermsRepository interface {
+	// CreateVersion fails with domain.ErrTermsVersionExists when the version of the document is already published
+	CreateVersion(ctx context.Context, version *domain.TermsVersion) error
+	// CurrentVersions returns the latest version of each published document
+	CurrentVersions(ctx context.Context) ([]*domain.TermsVersion, error)
+	// ListVersions returns the versions of the document, or of every document when empty, newest first
+	ListVersions(ctx context.Context, document string) ([]*domain.TermsVersion, error)
+	CreateAcceptance(ctx context.Context, acceptance *domain.TermsAcceptance) error
+	// ListAcceptances returns the acceptances of the user, newest first
+	ListAcceptances(ctx context.Context, userID string) ([]*domain.TermsAcceptance, error)
+}
+
+type TermsUseCase interface {
+	PublishVersion(ctx context.Context, actorID, document, version, url string) (*domain.TermsVersion, error)
+	ListVersions(ctx context.Context, document string) ([]*domain.TermsVersion, error)
+	CurrentVersions(ctx context.Context) ([]*domain.TermsVersion, error)
+	// PendingVersions returns the current versions the user has not accepted
+	PendingVersions(ctx context.Context, userID string) ([]*domain.TermsVersion, error)
+	GetStatus(ctx context.Context, userID string) (*domain.TermsStatus, error)
+	// AcceptVersions records that the user accepts the versions of the documents, keyed by document;
+	// only current versions can be accepted
+	AcceptVersions(ctx context.Context, userID string, versions map[string]string) (*domain.TermsStatus, error)
+}
@@ -0,0 +1,39 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+// UserHistoryQuery paginates the change history of a user
+type UserHistoryQuery struct {
+	UserID   string
+	Page     int
+	PageSize int
+}
+
+// UserHistoryResult contains paginated versions of a user, newest first
+type UserHistoryResult struct {
+	Versions   []*domain.UserVersion `json:"versions"`
+	TotalCount int64                 `json:"total_count"`
+	Page       int                   `json:"page"`
+	PageSize   int                   `json:"page_size"`
+	TotalPages int                   `json:"total_pages"`
+}
+
+type UserHistoryRepository interface {
+	// AppendVersion stores version as the next version of its user, setting its number
+	AppendVersion(ctx context.Context, version *domain.UserVersion) error
+	ListVersions(ctx context.Context, query *UserHistoryQuery) (*UserHistoryResult, error)
+	// GetVersion returns nil when the user has no such version
+	GetVersion(ctx context.Context, userID string, version int) (*domain.UserVersion, error)
+}
+
+type UserHistoryUseCase interface {
+	// ListHistory fails with usecase.ErrUserNotFound when the user does not exist
+	ListHistory(ctx context.Context, query *UserHistoryQuery) (*UserHistoryResult, error)
+	// RevertUser restores the profile of the user as of a version, recorded as a new version; the
+	// password and every other field are kept
+	RevertUser(ctx context.Context, actorID, userID string, version int) (*domain.User, error)
+}
@@ -3,18 +3,41 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/frtasoniero/user-management-api/internal/core/domain"
 )
 
 // GetUsersOptions provides options for querying users
 type GetUsersOptions struct {
-	Page     int      // Page number (1-based)
-	PageSize int      // Number of users per page
-	Fields   []string // Fields to include in response
-	Search   string   // Search term to filter users (searches in email, first_name, last_name)
-	SortBy   string   // Field to sort by (email, created_at, updated_at, first_name, last_name)
-	Order    string   // Sort order (asc, desc)
+	Page     int                  // Page number (1-based)
+	PageSize int                  // Number of users per page
+	Fields   []string             // Fields to include in response
+	Search   string               // Search term to filter users (searches in email, first_name, last_name)
+	SortBy   string               // Field to sort by (email, created_at, updated_at, first_name, last_name)
+	Order    string               // Sort order (asc, desc)
+	Metadata map[string]string    // Exact-match filters on metadata values, keyed by metadata key
+	Tags     []string             // Only users having all of these tags
+	Filter   *domain.SearchFilter // Structured filter, validated by the caller
+	// IDs keeps the users with these IDs
+	IDs []string
+	// InactiveSince keeps the users not seen since then, or created before it and never seen
+	InactiveSince *time.Time
+	// DirectoryLinked keeps the users synced from a directory, see domain.User.DirectoryID
+	DirectoryLinked bool
+	// Explain attaches the query plan and execution statistics of the page query to the result
+	Explain bool
+	// Near keeps the users having an address located within RadiusKM kilometers of it
+	Near     *domain.GeoPoint
+	RadiusKM float64
+	// Facets are counted over every matching user and attached to the result, see domain.UserFacets
+	Facets []string
+}
+
+// FacetCount is the number of users having a value of a facet
+type FacetCount struct {
+	Value string `json:"value" bson:"value" example:"US"`
+	Count int64  `json:"count" bson:"count" example:"42"`
 }
 
 // GetUsersResult contains paginated user results
@@ -24,13 +47,88 @@ type GetUsersResult struct {
 	Page       int            `json:"page"`
 	PageSize   int            `json:"page_size"`
 	TotalPages int            `json:"total_pages"`
+	// Explain is the MongoDB explain output of the page query, when requested with GetUsersOptions.Explain
+	Explain map[string]any `json:"explain,omitempty"`
+	// Facets are the counts of the facets requested with GetUsersOptions.Facets, keyed by facet, the
+	// most frequent values first
+	Facets map[string][]FacetCount `json:"facets,omitempty"`
+}
+
+// UserBatchResult reports the users a batch update selected and changed
+type UserBatchResult struct {
+	Matched  int64 `json:"matched" example:"42"`
+	Modified int64 `json:"modified" example:"40"`
 }
 
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *domain.User) error
+	// FindOrCreateUser returns the active user with the email of user, or else inserts user, in a
+	// single operation so that concurrent calls create one user; it reports whether user was inserted
+	FindOrCreateUser(ctx context.Context, user *domain.User) (*domain.User, bool, error)
 	GetUserByID(ctx context.Context, id string) (*domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetUserByNormalizedEmail(ctx context.Context, normalizedEmail string) (*domain.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
+	GetUserByExternalIdentity(ctx context.Context, issuer, subject string) (*domain.User, error)
+	GetUsersByIDs(ctx context.Context, ids []string) ([]*domain.User, error)
+	// AutocompleteUsers returns up to limit users whose email, username, first or last name starts with
+	// prefix, or whose first and last names start with its two words, with only their ID, email and names
+	AutocompleteUsers(ctx context.Context, prefix string, limit int) ([]*domain.User, error)
+	UserExists(ctx context.Context, id string) (bool, error)
 	GetUsers(ctx context.Context, opts *GetUsersOptions) (*GetUsersResult, error)
+	CountUsers(ctx context.Context, opts *GetUsersOptions) (int64, error)
+	DuplicateGroups(ctx context.Context, reason string) ([][]*domain.User, error)
+	// EachUser calls fn for every user matching the filters of opts, oldest first, stopping at the first error
+	EachUser(ctx context.Context, opts *GetUsersOptions, fn func(*domain.User) error) error
 	UpdateUser(ctx context.Context, user *domain.User) error
+	SetUsername(ctx context.Context, id string, username string) error
+	SetEmailVerified(ctx context.Context, id string, verifiedAt time.Time) error
+	SetPasswordHash(ctx context.Context, id string, passwordHash string) error
+	RequirePasswordReset(ctx context.Context, id string) error
+	// SetDeactivated deactivates the user at at, or reactivates it when at is nil
+	SetDeactivated(ctx context.Context, id string, at *time.Time) error
+	RecordLogin(ctx context.Context, id string, at time.Time) error
+	// GetTokenGeneration returns the token generation of the user, 0 when it does not exist
+	GetTokenGeneration(ctx context.Context, id string) (int, error)
+	// IncrementTokenGeneration bumps the token generation of the user and returns the new one, 0 when it does not exist
+	IncrementTokenGeneration(ctx context.Context, id string) (int, error)
+	SetLastSeen(ctx context.Context, id string, at time.Time) error
+	SetAvatar(ctx context.Context, id string, avatar *domain.Avatar) error
+	// SetVerification replaces the verification of the user if its status is still fromStatus, and
+	// reports whether it did, so that concurrent reviews cannot both apply
+	SetVerification(ctx context.Context, id string, verification *domain.Verification, fromStatus string) (bool, error)
+	// GetUserByVerificationCheck returns the user whose verification the check of the provider runs on
+	GetUserByVerificationCheck(ctx context.Context, provider, checkID string) (*domain.User, error)
+	SetSettings(ctx context.Context, id string, settings domain.Settings) error
+	// SetProfile replaces the profile of the user but its addresses, see SetAddresses
+	SetProfile(ctx context.Context, id string, profile domain.Profile) error
+	// SetPhoneChallenge stores the code pending confirmation of the phone, or removes it when nil
+	SetPhoneChallenge(ctx context.Context, id string, challenge *domain.PhoneChallenge) error
+	// AddPhoneChallengeAttempt counts a wrong code against the pending challenge
+	AddPhoneChallengeAttempt(ctx context.Context, id string) error
+	// SetPhoneVerified marks the phone of the user verified at at, removing its challenge, if the
+	// profile still has that phone, and reports whether it did; a nil at clears the verification
+	SetPhoneVerified(ctx context.Context, id, phone string, at *time.Time) (bool, error)
+	SetMFAFactors(ctx context.Context, id string, factors []domain.MFAFactor) error
+	// AddMFAChallengeAttempt counts an attempt against the pending challenge of the SMS factor and
+	// reports whether it did, false once domain.MaxPhoneCodeAttempts are counted
+	AddMFAChallengeAttempt(ctx context.Context, id, factorID string) (bool, error)
+	// ConsumeMFAChallenge removes the challenge of the SMS factor, marking it used at at, if it is
+	// still the one of the code hash, and reports whether it did, so that a code is accepted once
+	ConsumeMFAChallenge(ctx context.Context, id, factorID, codeHash string, at time.Time) (bool, error)
+	// UseMFAStep records the TOTP time step of the factor, marking it used at at, if it is after the
+	// last step used, and reports whether it did, so that a code is accepted once
+	UseMFAStep(ctx context.Context, id, factorID string, step int64, at time.Time) (bool, error)
+	SetTrustedDevices(ctx context.Context, id string, devices []domain.TrustedDevice) error
+	SetAddresses(ctx context.Context, id string, addresses []domain.Address) error
+	SetMetadata(ctx context.Context, id string, metadata map[string]string) error
+	AddTags(ctx context.Context, id string, tags []string) ([]string, error)
+	RemoveTag(ctx context.Context, id string, tag string) ([]string, error)
+	SetRoles(ctx context.Context, id string, roles []string) error
+	RemoveRoleFromAllUsers(ctx context.Context, role string) error
+	// UpdateUsers applies the update to every user matching the filters of opts, ignoring its
+	// pagination, sorting and projection
+	UpdateUsers(ctx context.Context, opts *GetUsersOptions, update domain.UserBatchUpdate) (*UserBatchResult, error)
+	SoftDeleteUser(ctx context.Context, id string, mergedInto string) error
 	DeleteUser(ctx context.Context, id string) error
 }
@@ -7,10 +7,49 @@ import (
 )
 
 type UserUseCase interface {
-	Register(ctx context.Context, email, password string, profile domain.Profile) error
+	Register(ctx context.Context, email, username, password string, profile domain.Profile, customFields map[string]string) error
+	// RegistrationFields returns the custom fields users register with
+	RegistrationFields() []domain.CustomField
+	EvaluatePassword(ctx context.Context, password string, userInputs ...string) domain.PasswordEvaluation
+	Authenticate(ctx context.Context, email, password string) (*domain.User, error)
+	// VerifyPassword checks the password of the user, e.g. re-authenticating before a sensitive change
+	VerifyPassword(ctx context.Context, userID, password string) (*domain.User, error)
+	RecordLastSeen(ctx context.Context, userID string) error
+	// TokenGeneration returns the generation the tokens of the user must have, see LogoutAll
+	TokenGeneration(ctx context.Context, userID string) (int, error)
+	// LogoutAll revokes every token issued to the user so far
+	LogoutAll(ctx context.Context, actorID, userID string) error
 	GetUsers(ctx context.Context, opts *GetUsersOptions) (*GetUsersResult, error)
+	CountUsers(ctx context.Context, opts *GetUsersOptions) (int64, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*domain.User, error)
+	SetUsername(ctx context.Context, userID, username string) (*domain.User, error)
+	IsEmailAvailable(ctx context.Context, email string) (bool, error)
 	GetUserByID(ctx context.Context, id string) (*domain.User, error)
+	UserExists(ctx context.Context, id string) (bool, error)
+	LookupUsers(ctx context.Context, ids []string) ([]*domain.User, error)
+	AutocompleteUsers(ctx context.Context, prefix string, limit int) ([]*domain.User, error)
 	UpdateUser(ctx context.Context, user *domain.User) error
 	DeleteUser(ctx context.Context, id string) error
+	// UpdateProfile changes the profile of the user; overrideMinimumAge skips the minimum age rule
+	// on behalf of an admin, which is recorded in the audit log
+	UpdateProfile(ctx context.Context, actorID, userID string, update domain.ProfileUpdate, overrideMinimumAge bool) (*domain.User, error)
+	GetSettings(ctx context.Context, userID string) (*domain.Settings, error)
+	UpdateSettings(ctx context.Context, userID string, update domain.SettingsUpdate) (*domain.Settings, error)
+	ListAddresses(ctx context.Context, userID string) ([]domain.Address, error)
+	AddAddress(ctx context.Context, userID string, address domain.Address) (*domain.Address, error)
+	UpdateAddress(ctx context.Context, userID, addressID string, address domain.Address) (*domain.Address, error)
+	RemoveAddress(ctx context.Context, userID, addressID string) error
+	UpdateMetadata(ctx context.Context, userID string, changes map[string]*string) (map[string]string, error)
+	AddTags(ctx context.Context, userID string, tags []string) ([]string, error)
+	RemoveTag(ctx context.Context, userID string, tag string) ([]string, error)
+	// BatchUpdateUsers applies the update to every user matching the filter of opts or listed in its
+	// IDs, at least one of them set, recording it in the audit log
+	BatchUpdateUsers(ctx context.Context, actorID string, opts *GetUsersOptions, update domain.UserBatchUpdate) (*UserBatchResult, error)
+	// UpsertUserByEmail creates the user with the email or applies the upsert to it, resolving
+	// conflicting values with policy (the configured one when empty); it reports whether the user was created
+	UpsertUserByEmail(ctx context.Context, actorID, email string, upsert domain.UserUpsert, policy string) (*domain.User, bool, error)
+	// FindOrCreate returns the user with the email, or else atomically creates a minimal one; it
+	// reports whether the user was created
+	FindOrCreate(ctx context.Context, actorID, email, firstName, lastName string) (*domain.User, bool, error)
 }
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+)
+
+type VerificationUseCase interface {
+	// GetVerification returns the verification of the user, unverified if it never submitted one
+	GetVerification(ctx context.Context, userID string) (*domain.Verification, error)
+	// SubmitVerification submits pending documents of the user for review on behalf of actorID
+	SubmitVerification(ctx context.Context, actorID, userID string, documentIDs []string) (*domain.Verification, error)
+	// ReviewVerification verifies or rejects the verification pending review, or revokes a verified one
+	ReviewVerification(ctx context.Context, reviewerID, userID, decision, reason string) (*domain.Verification, error)
+	// HandleCheckWebhook ingests a webhook delivery of the identity verification provider into the
+	// verification of the checked user
+	HandleCheckWebhook(ctx context.Context, header http.Header, body []byte) error
+}
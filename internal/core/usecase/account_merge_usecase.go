@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.AccountMergeUseCase = (*AccountMergeUseCase)(nil)
+
+type AccountMergeUseCase struct {
+	users ports.UserRepository
+	orgs  ports.OrganizationRepository
+	audit ports.AuditUseCase
+}
+
+func NewAccountMergeUseCase(userRepo ports.UserRepository, orgRepo ports.OrganizationRepository, auditUC ports.AuditUseCase) ports.AccountMergeUseCase {
+	return &AccountMergeUseCase{
+		users: userRepo,
+		orgs:  orgRepo,
+		audit: auditUC,
+	}
+}
+
+// FindDuplicates reports the groups of accounts likely belonging to the same person, for support
+// to review before merging them. Users with the same birthdate are grouped by fuzzy name, and users
+// with similar emails are grouped together; a group matched by both reasons is reported once.
+func (u *AccountMergeUseCase) FindDuplicates(ctx context.Context, page, pageSize int) (*ports.DuplicateReport, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	candidates := []*domain.DuplicateCandidate{}
+	byMembers := map[string]*domain.DuplicateCandidate{}
+	add := func(reason string, users []*domain.User) {
+		ids := make([]string, len(users))
+		for i, user := range users {
+			ids[i] = user.ID
+		}
+		slices.Sort(ids)
+		members := strings.Join(ids, ",")
+		if candidate, ok := byMembers[members]; ok {
+			candidate.Reasons = append(candidate.Reasons, reason)
+			return
+		}
+		candidate := &domain.DuplicateCandidate{Reasons: []string{reason}, Users: users}
+		byMembers[members] = candidate
+		candidates = append(candidates, candidate)
+	}
+
+	sameBirthdate, err := u.users.DuplicateGroups(ctx, domain.DuplicateReasonNameBirthdate)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range sameBirthdate {
+		for _, users := range groupBy(group, func(user *domain.User) string { return user.Profile.NameKey() }) {
+			add(domain.DuplicateReasonNameBirthdate, users)
+		}
+	}
+
+	similarEmail, err := u.users.DuplicateGroups(ctx, domain.DuplicateReasonSimilarEmail)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range similarEmail {
+		add(domain.DuplicateReasonSimilarEmail, group)
+	}
+
+	// Groups matched by several reasons are the most likely duplicates
+	slices.SortStableFunc(candidates, func(a, b *domain.DuplicateCandidate) int {
+		return len(b.Reasons) - len(a.Reasons)
+	})
+
+	totalCount := len(candidates)
+	start := min((page-1)*pageSize, totalCount)
+	end := min(start+pageSize, totalCount)
+	return &ports.DuplicateReport{
+		Candidates: candidates[start:end],
+		TotalCount: int64(totalCount),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: (totalCount + pageSize - 1) / pageSize,
+	}, nil
+}
+
+// groupBy splits users by key, in order of first appearance, keeping the groups of two or more
+// users with a non-empty key
+func groupBy(users []*domain.User, key func(*domain.User) string) [][]*domain.User {
+	var keys []string
+	groups := map[string][]*domain.User{}
+	for _, user := range users {
+		k := key(user)
+		if k == "" {
+			continue
+		}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], user)
+	}
+
+	result := make([][]*domain.User, 0, len(keys))
+	for _, k := range keys {
+		if len(groups[k]) > 1 {
+			result = append(result, groups[k])
+		}
+	}
+	return result
+}
+
+// Merge folds the duplicate account into the primary one: the profiles are combined according
+// to policy, the organization memberships of the duplicate move to the primary user and the
+// duplicate is soft-deleted. The merge is recorded in the audit log.
+func (u *AccountMergeUseCase) Merge(ctx context.Context, actorID, primaryID, duplicateID, policy string) (*domain.User, error) {
+	if policy == "" {
+		policy = domain.MergePolicyKeepPrimary
+	}
+	primary, err := u.users.GetUserByID(ctx, primaryID)
+	if err != nil {
+		return nil, err
+	}
+	duplicate, err := u.users.GetUserByID(ctx, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if primary == nil || duplicate == nil {
+		return nil, ErrUserNotFound
+	}
+
+	merged, err := domain.MergeUsers(primary, duplicate, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Soft-delete the duplicate first so the unique username and NIN it holds are released
+	if err := u.users.SoftDeleteUser(ctx, duplicate.ID, primary.ID); err != nil {
+		return nil, err
+	}
+	if err := u.users.UpdateUser(ctx, merged); err != nil {
+		return nil, err
+	}
+
+	moved, err := u.moveMemberships(ctx, duplicate.ID, primary.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]string{
+		"duplicate_id":      duplicate.ID,
+		"duplicate_email":   duplicate.Email,
+		"policy":            policy,
+		"memberships_moved": strconv.Itoa(moved),
+	}
+	if err := u.audit.Record(ctx, domain.AuditActionUserMerged, actorID, primary.ID, details); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// moveMemberships transfers the organization memberships of one user to another.
+// When both belong to the same organization the more privileged role is kept.
+func (u *AccountMergeUseCase) moveMemberships(ctx context.Context, fromUserID, toUserID string) (int, error) {
+	memberships, err := u.orgs.ListUserMemberships(ctx, fromUserID)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, membership := range memberships {
+		existing, err := u.orgs.GetMembership(ctx, membership.OrganizationID, toUserID)
+		if err != nil {
+			return 0, err
+		}
+		if existing == nil {
+			moved, err := domain.NewMembership(membership.OrganizationID, toUserID, membership.Role)
+			if err != nil {
+				return 0, err
+			}
+			moved.CreatedAt = membership.CreatedAt
+			if err := u.orgs.CreateMembership(ctx, moved); err != nil {
+				return 0, err
+			}
+		} else if role := domain.HigherMembershipRole(existing.Role, membership.Role); role != existing.Role {
+			if err := u.orgs.UpdateMembershipRole(ctx, membership.OrganizationID, toUserID, role); err != nil {
+				return 0, err
+			}
+		}
+		if err := u.orgs.DeleteMembership(ctx, membership.OrganizationID, fromUserID); err != nil {
+			return 0, err
+		}
+	}
+	return len(memberships), nil
+}
@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.AuditExporter = (*AuditExportUseCase)(nil)
+
+// auditFlushTimeout bounds the last write of the buffered events on Stop
+const auditFlushTimeout = 10 * time.Second
+
+// auditExport is the buffer of the events waiting for a sink
+type auditExport struct {
+	name    string
+	sink    ports.AuditSink
+	events  chan *domain.AuditEvent
+	dropped atomic.Int64
+}
+
+// AuditExportUseCase ships the recorded audit events to the sinks in the background. Each sink has its
+// own buffer and writes its batches in order, retrying a failed batch with a random exponential
+// backoff, so a sink being down neither slows the requests nor the other sinks. Events are dropped,
+// and logged, when the buffer of a sink is full or a batch fails every attempt.
+type AuditExportUseCase struct {
+	exports []*auditExport
+	policy  ports.AuditExportPolicy
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewAuditExportUseCase returns the export of the audit events to sinks, keyed by the name they are
+// logged with
+func NewAuditExportUseCase(sinks map[string]ports.AuditSink, policy ports.AuditExportPolicy) *AuditExportUseCase {
+	ctx, cancel := context.WithCancel(context.Background())
+	u := &AuditExportUseCase{policy: policy, ctx: ctx, cancel: cancel}
+	for name, sink := range sinks {
+		u.exports = append(u.exports, &auditExport{
+			name:   name,
+			sink:   sink,
+			events: make(chan *domain.AuditEvent, policy.BufferSize),
+		})
+	}
+	return u
+}
+
+// Export queues the event for every sink
+func (u *AuditExportUseCase) Export(event *domain.AuditEvent) {
+	for _, export := range u.exports {
+		select {
+		case export.events <- event:
+		default:
+			// Logged on the first drop and every 1000 drops after it
+			if dropped := export.dropped.Add(1); dropped%1000 == 1 {
+				log.Printf("Audit export buffer of %s full, %d events dropped since startup", export.name, dropped)
+			}
+		}
+	}
+}
+
+// Start writes the queued events to the sinks until Stop
+func (u *AuditExportUseCase) Start() {
+	for _, export := range u.exports {
+		u.wg.Add(1)
+		go func() {
+			defer u.wg.Done()
+			u.run(export)
+		}()
+	}
+}
+
+// Stop writes the events still buffered once and waits for the sinks
+func (u *AuditExportUseCase) Stop() {
+	u.cancel()
+	u.wg.Wait()
+}
+
+func (u *AuditExportUseCase) run(export *auditExport) {
+	ticker := time.NewTicker(u.policy.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*domain.AuditEvent, 0, u.policy.BatchSize)
+	for {
+		select {
+		case event := <-export.events:
+			batch = append(batch, event)
+			if len(batch) < u.policy.BatchSize {
+				continue
+			}
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+		case <-u.ctx.Done():
+			u.flush(export, batch)
+			return
+		}
+		if !u.write(export, batch) {
+			u.flush(export, batch)
+			return
+		}
+		batch = make([]*domain.AuditEvent, 0, u.policy.BatchSize)
+	}
+}
+
+// write writes the batch to the sink, retrying until it succeeds or every attempt failed; it reports
+// false when interrupted by Stop, leaving the batch to flush
+func (u *AuditExportUseCase) write(export *auditExport, batch []*domain.AuditEvent) bool {
+	for attempt := 1; ; attempt++ {
+		err := export.sink.WriteEvents(u.ctx, batch)
+		if err == nil {
+			return true
+		}
+		if u.ctx.Err() != nil {
+			return false
+		}
+		if attempt >= u.policy.MaxAttempts {
+			log.Printf("Error exporting %d audit events to %s, dropping them after %d attempts: %v", len(batch), export.name, attempt, err)
+			return true
+		}
+		select {
+		case <-time.After(u.backoff(attempt)):
+		case <-u.ctx.Done():
+			return false
+		}
+	}
+}
+
+// flush writes the batch and the events still buffered in a single attempt each, within auditFlushTimeout
+func (u *AuditExportUseCase) flush(export *auditExport, batch []*domain.AuditEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), auditFlushTimeout)
+	defer cancel()
+
+	for {
+		select {
+		case event := <-export.events:
+			batch = append(batch, event)
+			if len(batch) < u.policy.BatchSize {
+				continue
+			}
+		default:
+		}
+		if len(batch) == 0 {
+			return
+		}
+		if err := export.sink.WriteEvents(ctx, batch); err != nil {
+			log.Printf("Error exporting %d audit events to %s on shutdown: %v", len(batch), export.name, err)
+		}
+		if len(batch) < u.policy.BatchSize {
+			return
+		}
+		batch = make([]*domain.AuditEvent, 0, u.policy.BatchSize)
+	}
+}
+
+// backoff returns a random delay up to BaseDelay doubled for each attempt already made, capped at MaxDelay
+func (u *AuditExportUseCase) backoff(attempt int) time.Duration {
+	ceiling := u.policy.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > u.policy.MaxDelay {
+		ceiling = u.policy.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.AuditUseCase = (*AuditUseCase)(nil)
+
+type AuditUseCase struct {
+	audit ports.AuditRepository
+}
+
+func NewAuditUseCase(auditRepo ports.AuditRepository) ports.AuditUseCase {
+	return &AuditUseCase{
+		audit: auditRepo,
+	}
+}
+
+// Record records the action with the token and IP address of the caller carried by ctx, see
+// domain.ActorFromContext, which is also the actor when actorID is empty
+func (u *AuditUseCase) Record(ctx context.Context, action, actorID, targetID string, details map[string]string) error {
+	actor := domain.ActorFromContext(ctx)
+	if actorID == "" {
+		actorID = actor.ID
+	}
+	event := domain.NewAuditEvent(action, actorID, targetID, details)
+	event.TokenID, event.IP = actor.TokenID, actor.IP
+	return u.audit.CreateAuditEvent(ctx, event)
+}
+
+func (u *AuditUseCase) List(ctx context.Context, query *ports.AuditQuery) (*ports.AuditQueryResult, error) {
+	return u.audit.ListAuditEvents(ctx, query)
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/imaging"
+)
+
+// Compile-time interface check
+var _ ports.AvatarUseCase = (*AvatarUseCase)(nil)
+
+// avatarJob is a unit of work for the image-processing workers
+type avatarJob struct {
+	tenantID string
+	userID   string
+	version  string
+	format   string
+	data     []byte
+}
+
+// AvatarUseCase stores uploaded avatars and generates resized variants in background workers
+type AvatarUseCase struct {
+	users   ports.UserRepository
+	storage ports.FileStorage
+	jobs    chan avatarJob
+	wg      sync.WaitGroup
+}
+
+func NewAvatarUseCase(userRepo ports.UserRepository, storage ports.FileStorage, queueSize int) *AvatarUseCase {
+	return &AvatarUseCase{
+		users:   userRepo,
+		storage: storage,
+		jobs:    make(chan avatarJob, queueSize),
+	}
+}
+
+// Start launches the image-processing workers
+func (u *AvatarUseCase) Start(workers int) {
+	for range workers {
+		u.wg.Add(1)
+		go func() {
+			defer u.wg.Done()
+			for job := range u.jobs {
+				u.process(job)
+			}
+		}()
+	}
+}
+
+// Stop stops accepting new jobs and waits for the queued ones to finish
+func (u *AvatarUseCase) Stop() {
+	close(u.jobs)
+	u.wg.Wait()
+}
+
+func (u *AvatarUseCase) UploadAvatar(ctx context.Context, userID string, image []byte) (*domain.Avatar, error) {
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	format, err := imaging.DetectFormat(image)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every upload gets its own version so cached URLs of a previous avatar are never reused
+	tenantID := domain.TenantFromContext(ctx)
+	version := strconv.FormatInt(time.Now().UnixNano(), 10)
+	originalURL, err := u.storage.Save(ctx, avatarKey(tenantID, userID, version, "original", format), "image/"+format, bytes.NewReader(image))
+	if err != nil {
+		return nil, err
+	}
+
+	avatar := &domain.Avatar{
+		Version:     version,
+		Status:      domain.AvatarStatusProcessing,
+		OriginalURL: originalURL,
+		UpdatedAt:   time.Now(),
+	}
+	if err := u.users.SetAvatar(ctx, userID, avatar); err != nil {
+		return nil, err
+	}
+
+	select {
+	case u.jobs <- avatarJob{tenantID: tenantID, userID: userID, version: version, format: format, data: image}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return avatar, nil
+}
+
+func (u *AvatarUseCase) process(job avatarJob) {
+	ctx, cancel := context.WithTimeout(domain.WithTenant(context.Background(), job.tenantID), 30*time.Second)
+	defer cancel()
+
+	avatar, err := u.generateSizes(ctx, job)
+	if err != nil {
+		log.Printf("Error processing avatar for user %s: %v", job.userID, err)
+		avatar.Status = domain.AvatarStatusFailed
+	}
+
+	// Skip the update when a newer avatar was uploaded while this one was processing
+	user, err := u.users.GetUserByID(ctx, job.userID)
+	if err != nil || user == nil || user.Avatar == nil || user.Avatar.Version != job.version {
+		return
+	}
+	avatar.OriginalURL = user.Avatar.OriginalURL
+	avatar.UpdatedAt = time.Now()
+
+	if err := u.users.SetAvatar(ctx, job.userID, avatar); err != nil {
+		log.Printf("Error saving avatar for user %s: %v", job.userID, err)
+	}
+}
+
+func (u *AvatarUseCase) generateSizes(ctx context.Context, job avatarJob) (*domain.Avatar, error) {
+	avatar := &domain.Avatar{Version: job.version, Status: domain.AvatarStatusReady}
+
+	img, err := imaging.Decode(job.data)
+	if err != nil {
+		return avatar, err
+	}
+
+	for _, size := range domain.AvatarSizes {
+		data, contentType, ext, err := imaging.Encode(imaging.Fit(img, size.MaxPixels), job.format)
+		if err != nil {
+			return avatar, err
+		}
+		url, err := u.storage.Save(ctx, avatarKey(job.tenantID, job.userID, job.version, size.Name, ext), contentType, bytes.NewReader(data))
+		if err != nil {
+			return avatar, err
+		}
+		avatar.SetSizeURL(size.Name, url)
+	}
+
+	return avatar, nil
+}
+
+func avatarKey(tenantID, userID, version, size, ext string) string {
+	if ext == "jpeg" {
+		ext = "jpg"
+	}
+	return fmt.Sprintf("avatars/%s/%s/%s/%s.%s", tenantID, userID, version, size, ext)
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.ConfigUseCase = (*ConfigUseCase)(nil)
+
+var (
+	ErrConfigNotManaged   = errors.New("configuration management is not enabled for this tenant")
+	ErrInvalidLogLevel    = errors.New("invalid log level: must be debug, info, warn or error")
+	ErrInvalidRateLimit   = errors.New("invalid rate limit: requests and window must be positive")
+	ErrInvalidFeatureFlag = errors.New("invalid feature flag: names are 1 to 64 lowercase letters, digits, '.', '_' or '-'")
+	ErrInvalidCORSOrigin  = errors.New("invalid CORS origin: must be * or <scheme>://<host>[:<port>]")
+)
+
+var featureFlagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// ConfigUseCase holds the runtime configuration of the instance, replaced as a whole by each update
+// so requests read it without locking. Updates only apply to the instance they are made on.
+type ConfigUseCase struct {
+	audit ports.AuditUseCase
+	// tenantID is the tenant whose admins manage the configuration of the instance
+	tenantID string
+
+	// mu serializes the updates, config is read without it
+	mu     sync.Mutex
+	config atomic.Pointer[ports.RuntimeConfig]
+}
+
+// NewConfigUseCase returns the use case serving initial, or an error when it is not valid
+func NewConfigUseCase(initial ports.RuntimeConfig, auditUC ports.AuditUseCase, tenantID string) (*ConfigUseCase, error) {
+	if err := validateRuntimeConfig(&initial); err != nil {
+		return nil, err
+	}
+	initial.Features = maps.Clone(initial.Features)
+	initial.CORSOrigins = slices.Clone(initial.CORSOrigins)
+	normalizeRuntimeConfig(&initial)
+	u := &ConfigUseCase{
+		audit:    auditUC,
+		tenantID: tenantID,
+	}
+	u.config.Store(&initial)
+	return u, nil
+}
+
+func (u *ConfigUseCase) Config() *ports.RuntimeConfig {
+	return u.config.Load()
+}
+
+func (u *ConfigUseCase) UpdateConfig(ctx context.Context, actorID string, update *ports.RuntimeConfigUpdate) (*ports.RuntimeConfig, error) {
+	if domain.TenantFromContext(ctx) != u.tenantID {
+		return nil, ErrConfigNotManaged
+	}
+	return u.apply(ctx, domain.AuditActionConfigUpdated, actorID, update)
+}
+
+func (u *ConfigUseCase) ReloadConfig(ctx context.Context, update *ports.RuntimeConfigUpdate) (*ports.RuntimeConfig, error) {
+	return u.apply(domain.WithTenant(ctx, u.tenantID), domain.AuditActionConfigReloaded, "", update)
+}
+
+// apply replaces the configuration with the update applied to it and records the changed fields,
+// as "<old> -> <new>", in the audit log
+func (u *ConfigUseCase) apply(ctx context.Context, action, actorID string, update *ports.RuntimeConfigUpdate) (*ports.RuntimeConfig, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	current := u.config.Load()
+	next := current.Updated(update)
+	if err := validateRuntimeConfig(&next); err != nil {
+		return nil, err
+	}
+	normalizeRuntimeConfig(&next)
+
+	changes := map[string]string{}
+	change := func(field, old, new string) {
+		if old != new {
+			changes[field] = old + " -> " + new
+		}
+	}
+	change("log_level", current.LogLevel, next.LogLevel)
+	change("rate_limits.api", current.RateLimits.API.String(), next.RateLimits.API.String())
+	change("rate_limits.auth", current.RateLimits.Auth.String(), next.RateLimits.Auth.String())
+	change("rate_limits.check_email", current.RateLimits.EmailCheck.String(), next.RateLimits.EmailCheck.String())
+	change("features", formatFeatures(current.Features), formatFeatures(next.Features))
+	change("cors_origins", strings.Join(current.CORSOrigins, ","), strings.Join(next.CORSOrigins, ","))
+	if len(changes) == 0 {
+		return current, nil
+	}
+
+	if err := u.audit.Record(ctx, action, actorID, "", changes); err != nil {
+		return nil, err
+	}
+	u.config.Store(&next)
+	return &next, nil
+}
+
+func validateRuntimeConfig(config *ports.RuntimeConfig) error {
+	if !slices.Contains(ports.LogLevels, config.LogLevel) {
+		return fmt.Errorf("%w, got %q", ErrInvalidLogLevel, config.LogLevel)
+	}
+	for _, limit := range []ports.RateLimit{config.RateLimits.API, config.RateLimits.Auth, config.RateLimits.EmailCheck} {
+		if limit.Requests < 1 || limit.Window <= 0 {
+			return fmt.Errorf("%w, got %q", ErrInvalidRateLimit, limit)
+		}
+	}
+	for name := range config.Features {
+		if !featureFlagPattern.MatchString(name) {
+			return fmt.Errorf("%w, got %q", ErrInvalidFeatureFlag, name)
+		}
+	}
+	for _, origin := range config.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" ||
+			parsed.User != nil || parsed.Path != "" || parsed.RawQuery != "" || parsed.Fragment != "" {
+			return fmt.Errorf("%w, got %q", ErrInvalidCORSOrigin, origin)
+		}
+	}
+	return nil
+}
+
+// normalizeRuntimeConfig serves missing feature flags and CORS origins as empty ones
+func normalizeRuntimeConfig(config *ports.RuntimeConfig) {
+	if config.Features == nil {
+		config.Features = map[string]bool{}
+	}
+	if config.CORSOrigins == nil {
+		config.CORSOrigins = []string{}
+	}
+}
+
+// formatFeatures returns the flags as "<name>=<enabled>", sorted by name
+func formatFeatures(features map[string]bool) string {
+	names := slices.Sorted(maps.Keys(features))
+	for i, name := range names {
+		names[i] = name + "=" + strconv.FormatBool(features[name])
+	}
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// DigestPeriod is the period summarized by each admin digest
+const DigestPeriod = 24 * time.Hour
+
+var digestTemplate = template.Must(template.New("digest").Parse(`Hello,
+
+Here is the account activity of the {{.TenantID}} tenant from {{.From.Format "2006-01-02 15:04 MST"}} to {{.To.Format "2006-01-02 15:04 MST"}}:
+
+  New registrations:     {{.Registrations}}
+  Deleted accounts:      {{.Deletions}}
+  Failed logins:         {{.FailedLogins}}
+  Locked accounts:       {{.LockedAccounts}}
+  Deactivated accounts:  {{.DeactivatedAccounts}}
+{{if .LockedAccounts}}
+Locked accounts had a login reported as suspicious by their owner and cannot log in until their password is reset.
+{{end}}
+The details are in the audit log of the tenant.
+`))
+
+// DigestUseCase emails the admins of every tenant a summary of the account activity of the last
+// day in jobs, see RunJob
+type DigestUseCase struct {
+	digests ports.DigestRepository
+	mailer  ports.Mailer
+}
+
+func NewDigestUseCase(digests ports.DigestRepository, mailer ports.Mailer) *DigestUseCase {
+	return &DigestUseCase{
+		digests: digests,
+		mailer:  mailer,
+	}
+}
+
+// RunJob is the handler of the admin digest jobs, summarizing the DigestPeriod before the job was
+// created. Tenants without activity get no digest.
+func (u *DigestUseCase) RunJob(ctx context.Context, job *domain.Job) error {
+	to := job.CreatedAt.UTC()
+	digests, err := u.digests.CountAdminDigests(ctx, to.Add(-DigestPeriod), to)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	sent := 0
+	for _, digest := range digests {
+		if digest.Quiet() {
+			continue
+		}
+		n, err := u.send(domain.WithTenant(ctx, digest.TenantID), digest)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", digest.TenantID, err))
+		}
+		sent += n
+	}
+	log.Printf("Admin digests sent to %d admins of %d tenants", sent, len(digests))
+	return errors.Join(errs...)
+}
+
+// send emails the digest to the admins of its tenant, returning how many it was sent to
+func (u *DigestUseCase) send(ctx context.Context, digest *domain.AdminDigest) (int, error) {
+	recipients, err := u.digests.ListDigestRecipients(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var body strings.Builder
+	if err := digestTemplate.Execute(&body, digest); err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, to := range recipients {
+		if err := u.mailer.Send(ctx, ports.EmailMessage{
+			To:      to,
+			Subject: "Daily account activity of " + digest.TenantID,
+			Body:    body.String(),
+		}); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
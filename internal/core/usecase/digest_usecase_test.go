@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+)
+
+func TestDigestRunJob(t *testing.T) {
+	created := time.Date(2024, 3, 10, 6, 0, 0, 0, time.FixedZone("CET", 3600))
+	to := created.UTC()
+	from := to.Add(-DigestPeriod)
+
+	var period []time.Time
+	var sent []ports.EmailMessage
+	digests := &mocks.DigestRepository{
+		CountAdminDigestsFunc: func(_ context.Context, f, t time.Time) ([]*domain.AdminDigest, error) {
+			period = []time.Time{f, t}
+			return []*domain.AdminDigest{
+				{TenantID: "acme", From: f, To: t, Registrations: 3, Deletions: 1, FailedLogins: 12, LockedAccounts: 2},
+				{TenantID: "quiet", From: f, To: t},
+				{TenantID: "globex", From: f, To: t, DeactivatedAccounts: 4},
+			}, nil
+		},
+		ListDigestRecipientsFunc: func(ctx context.Context) ([]string, error) {
+			switch domain.TenantFromContext(ctx) {
+			case "acme":
+				return []string{"ada@acme.example", "bob@acme.example"}, nil
+			case "globex":
+				return []string{"eve@globex.example"}, nil
+			}
+			t.Errorf("ListDigestRecipients() for tenant %q", domain.TenantFromContext(ctx))
+			return nil, nil
+		},
+	}
+	mailer := &mocks.Mailer{
+		SendFunc: func(_ context.Context, message ports.EmailMessage) error {
+			sent = append(sent, message)
+			return nil
+		},
+	}
+
+	if err := NewDigestUseCase(digests, mailer).RunJob(context.Background(), &domain.Job{CreatedAt: created}); err != nil {
+		t.Fatalf("RunJob() error = %v", err)
+	}
+	if want := []time.Time{from, to}; !reflect.DeepEqual(period, want) {
+		t.Errorf("CountAdminDigests() period = %v, want %v", period, want)
+	}
+
+	acmeBody := `Hello,
+
+Here is the account activity of the acme tenant from 2024-03-09 05:00 UTC to 2024-03-10 05:00 UTC:
+
+  New registrations:     3
+  Deleted accounts:      1
+  Failed logins:         12
+  Locked accounts:       2
+  Deactivated accounts:  0
+
+Locked accounts had a login reported as suspicious by their owner and cannot log in until their password is reset.
+
+The details are in the audit log of the tenant.
+`
+	want := []ports.EmailMessage{
+		{To: "ada@acme.example", Subject: "Daily account activity of acme", Body: acmeBody},
+		{To: "bob@acme.example", Subject: "Daily account activity of acme", Body: acmeBody},
+		{To: "eve@globex.example", Subject: "Daily account activity of globex"},
+	}
+	if len(sent) != len(want) {
+		t.Fatalf("sent %d emails, want %d", len(sent), len(want))
+	}
+	for i := range want {
+		if sent[i].To != want[i].To || sent[i].Subject != want[i].Subject {
+			t.Errorf("email %d = %s %q, want %s %q", i, sent[i].To, sent[i].Subject, want[i].To, want[i].Subject)
+		}
+		if want[i].Body != "" && sent[i].Body != want[i].Body {
+			t.Errorf("email %d body = %q, want %q", i, sent[i].Body, want[i].Body)
+		}
+	}
+	// Without locked accounts, the digest does not explain them
+	if strings.Contains(sent[2].Body, "suspicious") {
+		t.Errorf("email 2 body = %q, explaining locked accounts", sent[2].Body)
+	}
+}
+
+func TestDigestRunJobErrors(t *testing.T) {
+	failure := errors.New("smtp: 451 try again later")
+	tests := []struct {
+		name      string
+		countErr  error
+		sendErr   map[string]error
+		wantSent  []string
+		wantError string
+	}{
+		{
+			name:      "count failure",
+			countErr:  errors.New("not primary"),
+			wantError: "not primary",
+		},
+		{
+			name:      "other tenants still sent",
+			sendErr:   map[string]error{"ada@acme.example": failure},
+			wantSent:  []string{"ada@acme.example", "eve@globex.example"},
+			wantError: "tenant acme: smtp: 451 try again later",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			digests := &mocks.DigestRepository{
+				CountAdminDigestsFunc: func(context.Context, time.Time, time.Time) ([]*domain.AdminDigest, error) {
+					return []*domain.AdminDigest{{TenantID: "acme", Registrations: 1}, {TenantID: "globex", Registrations: 1}}, tt.countErr
+				},
+				ListDigestRecipientsFunc: func(ctx context.Context) ([]string, error) {
+					if domain.TenantFromContext(ctx) == "acme" {
+						return []string{"ada@acme.example", "bob@acme.example"}, nil
+					}
+					return []string{"eve@globex.example"}, nil
+				},
+			}
+			mailer := &mocks.Mailer{
+				SendFunc: func(_ context.Context, message ports.EmailMessage) error {
+					sent = append(sent, message.To)
+					return tt.sendErr[message.To]
+				},
+			}
+
+			err := NewDigestUseCase(digests, mailer).RunJob(context.Background(), &domain.Job{CreatedAt: time.Now()})
+			if err == nil || err.Error() != tt.wantError {
+				t.Errorf("RunJob() error = %v, want %s", err, tt.wantError)
+			}
+			if !reflect.DeepEqual(sent, tt.wantSent) {
+				t.Errorf("sent = %v, want %v", sent, tt.wantSent)
+			}
+		})
+	}
+}
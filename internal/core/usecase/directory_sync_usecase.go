@@ -0,0 +1,338 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.DirectorySyncUseCase = (*DirectorySyncUseCase)(nil)
+
+var (
+	ErrDirectorySyncRunning   = errors.New("a directory sync is already running")
+	ErrDirectorySyncDisabled  = errors.New("directory sync is not enabled for this tenant")
+	ErrEmptyDirectory         = errors.New("the directory returned no users, check the base DN and filter")
+	ErrDirectoryEntryNoID     = errors.New("directory entry has no identifier")
+	ErrDirectoryEmailConflict = errors.New("email address belongs to an account linked to another directory entry")
+)
+
+// DirectorySyncUseCase creates, updates and deactivates the accounts of a tenant to match the
+// users of an external directory, in scheduled jobs or when triggered by an admin
+type DirectorySyncUseCase struct {
+	source   ports.DirectorySource
+	users    ports.UserRepository
+	audit    ports.AuditUseCase
+	tenantID string
+
+	// ctx is the parent of the syncs triggered by admins, canceled by Stop
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	running bool
+	last    *ports.DirectorySyncReport
+}
+
+func NewDirectorySyncUseCase(source ports.DirectorySource, userRepo ports.UserRepository, auditUC ports.AuditUseCase, tenantID string) *DirectorySyncUseCase {
+	ctx, cancel := context.WithCancel(domain.WithTenant(context.Background(), tenantID))
+	return &DirectorySyncUseCase{
+		source:   source,
+		users:    userRepo,
+		audit:    auditUC,
+		tenantID: tenantID,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// RunJob is the handler of the scheduled directory sync jobs, only reporting the changes when the
+// dry_run payload is true. A sync triggered by an admin may still be running, the next job catches up.
+func (u *DirectorySyncUseCase) RunJob(ctx context.Context, job *domain.Job) error {
+	if err := u.begin(); err != nil {
+		log.Printf("Scheduled directory sync of tenant %s skipped: %v", u.tenantID, err)
+		return nil
+	}
+	dryRun, _ := strconv.ParseBool(job.Payload["dry_run"])
+	return u.sync(ctx, "", dryRun)
+}
+
+// Stop cancels the running sync and waits for it to finish
+func (u *DirectorySyncUseCase) Stop() {
+	u.cancel()
+	u.wg.Wait()
+}
+
+// TriggerSync starts a sync in the background on behalf of actorID; its report is found with Status
+func (u *DirectorySyncUseCase) TriggerSync(ctx context.Context, actorID string, dryRun bool) error {
+	if domain.TenantFromContext(ctx) != u.tenantID {
+		return ErrDirectorySyncDisabled
+	}
+	if err := u.begin(); err != nil {
+		return err
+	}
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		// The failure is in the report
+		_ = u.sync(u.ctx, actorID, dryRun)
+	}()
+	return nil
+}
+
+func (u *DirectorySyncUseCase) Status(ctx context.Context) (*ports.DirectorySyncStatus, error) {
+	if domain.TenantFromContext(ctx) != u.tenantID {
+		return nil, ErrDirectorySyncDisabled
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return &ports.DirectorySyncStatus{Running: u.running, LastReport: u.last}, nil
+}
+
+// begin marks a sync as running, syncs never overlap
+func (u *DirectorySyncUseCase) begin() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.running {
+		return ErrDirectorySyncRunning
+	}
+	u.running = true
+	return nil
+}
+
+// sync runs a sync started with begin, keeps its report and returns its error. Syncs making changes
+// are recorded in the audit log with their counts, actorID is empty for scheduled syncs.
+func (u *DirectorySyncUseCase) sync(ctx context.Context, actorID string, dryRun bool) error {
+	report := &ports.DirectorySyncReport{StartedAt: time.Now(), DryRun: dryRun, Changes: []ports.DirectorySyncChange{}}
+	err := u.apply(ctx, report)
+	report.FinishedAt = time.Now()
+	if err != nil {
+		report.Error = err.Error()
+		log.Printf("Directory sync of tenant %s failed: %v", u.tenantID, err)
+	}
+
+	if !dryRun && report.Entries > 0 {
+		details := map[string]string{
+			"entries":     strconv.Itoa(report.Entries),
+			"created":     strconv.Itoa(report.Created),
+			"updated":     strconv.Itoa(report.Updated),
+			"deactivated": strconv.Itoa(report.Deactivated),
+			"reactivated": strconv.Itoa(report.Reactivated),
+			"failed":      strconv.Itoa(report.Failed),
+		}
+		if report.Error != "" {
+			details["error"] = report.Error
+		}
+		if err := u.audit.Record(ctx, domain.AuditActionDirectorySynced, actorID, "", details); err != nil {
+			log.Printf("Error recording directory sync of tenant %s: %v", u.tenantID, err)
+		}
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.running = false
+	u.last = report
+	return err
+}
+
+// apply matches the directory users with the accounts of the tenant, linked by directory ID or
+// else by email, and adds the changes to report; changes are only made outside of dry runs.
+// Accounts linked to an entry missing from the directory are deactivated.
+func (u *DirectorySyncUseCase) apply(ctx context.Context, report *ports.DirectorySyncReport) error {
+	entries, err := u.source.ListUsers(ctx)
+	if err != nil {
+		return err
+	}
+	// An empty result is far more likely a misconfiguration than a directory without users, and
+	// would deactivate every account
+	if len(entries) == 0 {
+		return ErrEmptyDirectory
+	}
+
+	linked := make(map[string]*domain.User)
+	err = u.users.EachUser(ctx, &ports.GetUsersOptions{DirectoryLinked: true}, func(user *domain.User) error {
+		linked[user.DirectoryID] = user
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	report.Entries = len(entries)
+
+	seen := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if entry.ID == "" {
+			recordDirectoryChange(report, ports.DirectorySyncChange{Email: entry.Email}, ErrDirectoryEntryNoID)
+			continue
+		}
+		if seen[entry.ID] {
+			continue
+		}
+		seen[entry.ID] = true
+		change, err := u.syncEntry(ctx, entry, linked[entry.ID], report.DryRun)
+		recordDirectoryChange(report, change, err)
+	}
+
+	ids := make([]string, 0, len(linked))
+	for id := range linked {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		user := linked[id]
+		if seen[id] || user.DeactivatedAt != nil {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		change := ports.DirectorySyncChange{Action: ports.DirectorySyncDeactivate, DirectoryID: id, UserID: user.ID, Email: user.Email}
+		var err error
+		if !report.DryRun {
+			now := time.Now()
+			err = u.users.SetDeactivated(ctx, user.ID, &now)
+		}
+		recordDirectoryChange(report, change, err)
+	}
+	return nil
+}
+
+// syncEntry creates or updates the account of a directory entry; user is the account linked to the
+// entry, if any. The returned change has no action when the account is up to date.
+func (u *DirectorySyncUseCase) syncEntry(ctx context.Context, entry ports.DirectoryUser, user *domain.User, dryRun bool) (ports.DirectorySyncChange, error) {
+	change := ports.DirectorySyncChange{DirectoryID: entry.ID, Email: entry.Email}
+	email, err := domain.CanonicalEmail(entry.Email)
+	if err != nil {
+		return change, err
+	}
+	change.Email = email
+
+	// Accounts registered before the directory was synced are linked by their email
+	if user == nil {
+		existing, err := u.users.GetUserByEmail(ctx, email)
+		if err != nil {
+			return change, err
+		}
+		if existing != nil && existing.DirectoryID != "" {
+			change.UserID = existing.ID
+			return change, ErrDirectoryEmailConflict
+		}
+		user = existing
+	}
+	// Invalid or reserved directory usernames are not synced
+	username, err := domain.NormalizeUsername(entry.Username)
+	if err != nil {
+		username = ""
+	}
+
+	if user == nil {
+		// Accounts disabled in the directory are not created
+		if entry.Disabled {
+			return change, nil
+		}
+		created, err := domain.NewUser(email, domain.NoPasswordHash, domain.Profile{FirstName: entry.FirstName, LastName: entry.LastName})
+		if err != nil {
+			return change, err
+		}
+		created.DirectoryID = entry.ID
+		created.Username = username
+		change.Action, change.UserID = ports.DirectorySyncCreate, created.ID
+		if dryRun {
+			return change, nil
+		}
+		return change, u.users.CreateUser(ctx, created)
+	}
+
+	change.UserID = user.ID
+	updated := *user
+	if user.DirectoryID != entry.ID {
+		updated.DirectoryID = entry.ID
+		change.Fields = append(change.Fields, "directory_id")
+	}
+	if user.Email != email {
+		if updated.NormalizedEmail, err = domain.NormalizeEmail(email); err != nil {
+			return change, err
+		}
+		updated.Email = email
+		change.Fields = append(change.Fields, "email")
+	}
+	if username != "" && user.Username != username {
+		updated.Username = username
+		change.Fields = append(change.Fields, "username")
+	}
+	if entry.FirstName != "" && user.Profile.FirstName != entry.FirstName {
+		updated.Profile.FirstName = entry.FirstName
+		change.Fields = append(change.Fields, "profile.first_name")
+	}
+	if entry.LastName != "" && user.Profile.LastName != entry.LastName {
+		updated.Profile.LastName = entry.LastName
+		change.Fields = append(change.Fields, "profile.last_name")
+	}
+
+	var deactivatedAt *time.Time
+	switch {
+	case entry.Disabled && user.DeactivatedAt == nil:
+		now := time.Now()
+		change.Action, deactivatedAt = ports.DirectorySyncDeactivate, &now
+	case !entry.Disabled && user.DeactivatedAt != nil:
+		change.Action = ports.DirectorySyncReactivate
+	case len(change.Fields) > 0:
+		change.Action = ports.DirectorySyncUpdate
+	default:
+		return change, nil
+	}
+	if dryRun {
+		return change, nil
+	}
+
+	if len(change.Fields) > 0 {
+		if err := u.users.UpdateUser(ctx, &updated); err != nil {
+			return change, err
+		}
+		// Email changes show in the security events of the user, see domain.SecurityAuditActions
+		if slices.Contains(change.Fields, "email") {
+			details := map[string]string{"source": "directory_sync"}
+			if err := u.audit.Record(ctx, domain.AuditActionEmailChanged, "", user.ID, details); err != nil {
+				log.Printf("Error recording email change of user %s: %v", user.ID, err)
+			}
+		}
+	}
+	if change.Action != ports.DirectorySyncUpdate {
+		return change, u.users.SetDeactivated(ctx, user.ID, deactivatedAt)
+	}
+	return change, nil
+}
+
+// recordDirectoryChange counts a change in report, listing every change but the accounts left unchanged
+func recordDirectoryChange(report *ports.DirectorySyncReport, change ports.DirectorySyncChange, err error) {
+	if err != nil {
+		change.Action, change.Error = ports.DirectorySyncFail, err.Error()
+	}
+	switch change.Action {
+	case "":
+		report.Unchanged++
+		return
+	case ports.DirectorySyncCreate:
+		report.Created++
+	case ports.DirectorySyncUpdate:
+		report.Updated++
+	case ports.DirectorySyncDeactivate:
+		report.Deactivated++
+	case ports.DirectorySyncReactivate:
+		report.Reactivated++
+	case ports.DirectorySyncFail:
+		report.Failed++
+	}
+	report.Changes = append(report.Changes, change)
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.DocumentUseCase = (*DocumentUseCase)(nil)
+
+var ErrDocumentNotFound = errors.New("document not found")
+
+// DocumentUseCase stores the identity documents of users in private storage and hands out
+// short-lived URLs to their files
+type DocumentUseCase struct {
+	documents ports.DocumentRepository
+	users     ports.UserRepository
+	storage   ports.PresignedFileStorage
+	audit     ports.AuditUseCase
+	// urlTTL is the lifetime of the download URLs
+	urlTTL time.Duration
+}
+
+func NewDocumentUseCase(documents ports.DocumentRepository, users ports.UserRepository, storage ports.PresignedFileStorage, audit ports.AuditUseCase, urlTTL time.Duration) *DocumentUseCase {
+	return &DocumentUseCase{
+		documents: documents,
+		users:     users,
+		storage:   storage,
+		audit:     audit,
+		urlTTL:    urlTTL,
+	}
+}
+
+func (u *DocumentUseCase) UploadDocument(ctx context.Context, upload *ports.DocumentUpload) (*domain.Document, error) {
+	expiresAt, err := domain.ParseDocumentExpiry(upload.ExpiresOn)
+	if err != nil {
+		return nil, err
+	}
+	contentType, ext, err := domain.DetectDocumentFormat(upload.Content)
+	if err != nil {
+		return nil, err
+	}
+	document, err := domain.NewDocument(upload.UserID, upload.UploadedBy, upload.Type, upload.FileName, contentType, int64(len(upload.Content)), expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.requireUser(ctx, upload.UserID); err != nil {
+		return nil, err
+	}
+
+	// Files are named after the document, never after the uploaded file name
+	document.StorageKey = fmt.Sprintf("%s/%s/%s.%s", domain.TenantFromContext(ctx), upload.UserID, document.ID, ext)
+	if _, err := u.storage.Save(ctx, document.StorageKey, contentType, bytes.NewReader(upload.Content)); err != nil {
+		return nil, err
+	}
+	if err := u.documents.CreateDocument(ctx, document); err != nil {
+		return nil, err
+	}
+
+	details := map[string]string{"document_id": document.ID, "type": document.Type}
+	if err := u.audit.Record(ctx, domain.AuditActionDocumentUploaded, upload.UploadedBy, upload.UserID, details); err != nil {
+		log.Printf("Error recording upload of document %s: %v", document.ID, err)
+	}
+	return document, nil
+}
+
+func (u *DocumentUseCase) ListDocuments(ctx context.Context, userID string) ([]*domain.Document, error) {
+	if err := u.requireUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	return u.documents.ListDocuments(ctx, userID)
+}
+
+func (u *DocumentUseCase) PresignDownload(ctx context.Context, actorID, userID, documentID string) (*domain.DocumentDownload, error) {
+	document, err := u.documents.GetDocument(ctx, userID, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if document == nil {
+		return nil, ErrDocumentNotFound
+	}
+
+	expiresAt := time.Now().Add(u.urlTTL)
+	url, err := u.storage.PresignURL(ctx, document.StorageKey, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every access to an identity document is on record, for the reviews of who saw which documents
+	details := map[string]string{"document_id": document.ID, "type": document.Type}
+	if err := u.audit.Record(ctx, domain.AuditActionDocumentDownloaded, actorID, userID, details); err != nil {
+		return nil, err
+	}
+	return &domain.DocumentDownload{URL: url, ExpiresAt: expiresAt}, nil
+}
+
+func (u *DocumentUseCase) requireUser(ctx context.Context, userID string) error {
+	exists, err := u.users.UserExists(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+	return nil
+}
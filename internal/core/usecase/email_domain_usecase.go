@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.EmailDomainUseCase = (*EmailDomainUseCase)(nil)
+
+var ErrEmailDomainRuleNotFound = errors.New("email domain rule not found")
+
+// EmailDomainUseCase restricts the email domains of the registrations with the lists configured
+// for every tenant and the rules admins add to their tenant at runtime
+type EmailDomainUseCase struct {
+	rules      ports.EmailDomainRepository
+	configured domain.EmailDomainPolicy
+	audit      ports.AuditUseCase
+}
+
+func NewEmailDomainUseCase(ruleRepo ports.EmailDomainRepository, configured domain.EmailDomainPolicy, auditUC ports.AuditUseCase) ports.EmailDomainUseCase {
+	return &EmailDomainUseCase{
+		rules:      ruleRepo,
+		configured: configured,
+		audit:      auditUC,
+	}
+}
+
+func (u *EmailDomainUseCase) ListRules(ctx context.Context) ([]*domain.EmailDomainRule, error) {
+	stored, err := u.rules.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*domain.EmailDomainRule, 0, len(u.configured.Allow)+len(u.configured.Deny)+len(stored))
+	for _, allowed := range u.configured.Allow {
+		rules = append(rules, &domain.EmailDomainRule{List: domain.EmailDomainAllow, Domain: allowed, Configured: true})
+	}
+	for _, denied := range u.configured.Deny {
+		rules = append(rules, &domain.EmailDomainRule{List: domain.EmailDomainDeny, Domain: denied, Configured: true})
+	}
+	return append(rules, stored...), nil
+}
+
+func (u *EmailDomainUseCase) AddRule(ctx context.Context, actorID, list, emailDomain string) (*domain.EmailDomainRule, error) {
+	rule, err := domain.NewEmailDomainRule(list, emailDomain, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.rules.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	details := map[string]string{"list": rule.List, "domain": rule.Domain}
+	if err := u.audit.Record(ctx, domain.AuditActionEmailDomainAdded, actorID, rule.ID, details); err != nil {
+		log.Printf("Error recording addition of email domain %s: %v", rule.Domain, err)
+	}
+	return rule, nil
+}
+
+// RemoveRule removes a rule added at runtime; configured rules are not found
+func (u *EmailDomainUseCase) RemoveRule(ctx context.Context, actorID, list, emailDomain string) error {
+	if list != domain.EmailDomainAllow && list != domain.EmailDomainDeny {
+		return domain.ErrInvalidEmailDomainList
+	}
+	normalized, err := domain.NormalizeEmailDomain(emailDomain)
+	if err != nil {
+		return err
+	}
+	deleted, err := u.rules.DeleteRule(ctx, list, normalized)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrEmailDomainRuleNotFound
+	}
+
+	details := map[string]string{"list": list, "domain": normalized}
+	if err := u.audit.Record(ctx, domain.AuditActionEmailDomainRemoved, actorID, "", details); err != nil {
+		log.Printf("Error recording removal of email domain %s: %v", normalized, err)
+	}
+	return nil
+}
+
+// CheckEmail reads the rules of the tenant on every call, so that changes apply to every instance at once
+func (u *EmailDomainUseCase) CheckEmail(ctx context.Context, email string) error {
+	rules, err := u.rules.ListRules(ctx)
+	if err != nil {
+		return err
+	}
+	return u.configured.With(rules).Check(email)
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.ExternalAuthUseCase = (*ExternalAuthUseCase)(nil)
+
+var (
+	ErrExternalTokenNoEmail = errors.New("token of the identity provider has no email address")
+	// ErrExternalIdentityConflict keeps identity provider accounts from taking over local accounts
+	// whose email they have not verified or that are linked to another of their accounts
+	ErrExternalIdentityConflict = errors.New("email address belongs to an account the identity provider account cannot be linked to")
+)
+
+// ExternalAuthUseCase authenticates users with the tokens of an external identity provider.
+// Accounts are linked to the provider account the first time one of its tokens is used: to the
+// account with the same email when the provider verified it, or else to a new account.
+type ExternalAuthUseCase struct {
+	verifier ports.TokenVerifier
+	users    ports.UserRepository
+	audit    ports.AuditUseCase
+}
+
+func NewExternalAuthUseCase(verifier ports.TokenVerifier, userRepo ports.UserRepository, auditUC ports.AuditUseCase) ports.ExternalAuthUseCase {
+	return &ExternalAuthUseCase{
+		verifier: verifier,
+		users:    userRepo,
+		audit:    auditUC,
+	}
+}
+
+func (u *ExternalAuthUseCase) AuthenticateToken(ctx context.Context, token string) (*domain.User, error) {
+	claims, err := u.verifier.Verify(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.users.GetUserByExternalIdentity(ctx, claims.Issuer, claims.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		if user, err = u.provision(ctx, claims); err != nil {
+			return nil, err
+		}
+	}
+	if user.DeactivatedAt != nil {
+		return nil, ErrAccountDeactivated
+	}
+	return user, nil
+}
+
+// provision links the provider account of claims to the account with its email, or else creates one
+func (u *ExternalAuthUseCase) provision(ctx context.Context, claims *ports.ExternalToken) (*domain.User, error) {
+	if claims.Email == "" {
+		return nil, ErrExternalTokenNoEmail
+	}
+	email, err := domain.CanonicalEmail(claims.Email)
+	if err != nil {
+		return nil, err
+	}
+	identity := &domain.ExternalIdentity{Issuer: claims.Issuer, Subject: claims.Subject}
+
+	profile := domain.Profile{FirstName: claims.FirstName, LastName: claims.LastName}
+	profile.FillNames(email)
+	user, err := domain.NewUser(email, domain.NoPasswordHash, profile)
+	if err != nil {
+		return nil, err
+	}
+	user.ExternalIdentity = identity
+	if claims.EmailVerified {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	// Provider usernames already taken in the tenant are left out
+	if username, err := domain.NormalizeUsername(claims.Username); err == nil {
+		if taken, _ := u.users.GetUserByUsername(ctx, username); taken == nil {
+			user.Username = username
+		}
+	}
+
+	existing, created, err := u.users.FindOrCreateUser(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		u.record(ctx, existing, "created")
+		return existing, nil
+	}
+	// Concurrent first requests of the same account create it once
+	if existing.ExternalIdentity != nil && *existing.ExternalIdentity == *identity {
+		return existing, nil
+	}
+	if !claims.EmailVerified || existing.ExternalIdentity != nil {
+		return nil, ErrExternalIdentityConflict
+	}
+	existing.ExternalIdentity = identity
+	if err := u.users.UpdateUser(ctx, existing); err != nil {
+		return nil, err
+	}
+	u.record(ctx, existing, "linked")
+	return existing, nil
+}
+
+func (u *ExternalAuthUseCase) record(ctx context.Context, user *domain.User, outcome string) {
+	details := map[string]string{"issuer": user.ExternalIdentity.Issuer, "account": outcome}
+	if err := u.audit.Record(ctx, domain.AuditActionUserProvisioned, user.ID, user.ID, details); err != nil {
+		log.Printf("Error recording provisioning of user %s: %v", user.ID, err)
+	}
+}
@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var (
+	_ ports.JobUseCase    = (*JobUseCase)(nil)
+	_ ports.HealthChecker = (*JobUseCase)(nil)
+)
+
+var (
+	ErrJobNotFound  = errors.New("job not found")
+	ErrJobNotFailed = errors.New("job is not failed, only failed jobs can be retried")
+)
+
+// JobPolicy configures the background workers
+type JobPolicy struct {
+	// Workers is the number of jobs run at once by the instance
+	Workers int
+	// PollInterval is how long idle workers wait before looking for due jobs again
+	PollInterval time.Duration
+	// MaxAttempts is how many times a job is run before it is failed for good
+	MaxAttempts int
+	// RetryBaseDelay is the delay before the first retry, doubled on each retry up to RetryMaxDelay
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// Timeout bounds each run; the jobs of a stopped instance are claimed again once it passed
+	Timeout time.Duration
+}
+
+// DefaultJobPolicy returns the policy used when none is configured
+func DefaultJobPolicy() JobPolicy {
+	return JobPolicy{
+		Workers:        4,
+		PollInterval:   time.Second,
+		MaxAttempts:    5,
+		RetryBaseDelay: 30 * time.Second,
+		RetryMaxDelay:  time.Hour,
+		Timeout:        5 * time.Minute,
+	}
+}
+
+// JobUseCase runs the jobs stored in the database with a pool of workers, retrying failed runs and
+// keeping the jobs failing every attempt for admins to inspect and retry. Every instance runs
+// workers, each job is claimed by a single one.
+type JobUseCase struct {
+	jobs   ports.JobRepository
+	audit  ports.AuditUseCase
+	policy JobPolicy
+
+	// handlers are registered before Start and only read afterwards
+	handlers map[string]ports.JobHandler
+
+	// heartbeats are the unix nanoseconds each worker last looked for a job or finished one
+	heartbeats []atomic.Int64
+
+	// ctx is the parent of the runs, canceled by Stop
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewJobUseCase(jobRepo ports.JobRepository, auditUC ports.AuditUseCase, policy JobPolicy) *JobUseCase {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &JobUseCase{
+		jobs:     jobRepo,
+		audit:    auditUC,
+		policy:   policy,
+		handlers: map[string]ports.JobHandler{},
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Register sets the handler running the jobs of the type; jobs of types without a handler on this
+// instance are left to the other instances
+func (u *JobUseCase) Register(jobType string, handler ports.JobHandler) {
+	u.handlers[jobType] = handler
+}
+
+// Start launches the workers
+func (u *JobUseCase) Start() {
+	types := make([]string, 0, len(u.handlers))
+	for jobType := range u.handlers {
+		types = append(types, jobType)
+	}
+	u.heartbeats = make([]atomic.Int64, u.policy.Workers)
+	for i := range u.policy.Workers {
+		u.wg.Add(1)
+		go func() {
+			defer u.wg.Done()
+			for {
+				u.heartbeats[i].Store(time.Now().UnixNano())
+				ran, err := u.runNext(types)
+				if err != nil {
+					log.Printf("Error claiming a job: %v", err)
+				}
+				if ran {
+					continue
+				}
+				select {
+				case <-time.After(u.policy.PollInterval):
+				case <-u.ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+}
+
+// CheckHealth reports the workers of the instance as alive while they look for jobs, or run one for
+// less than the timeout of the runs: down when none is alive, degraded when some are not
+func (u *JobUseCase) CheckHealth(ctx context.Context) ports.DependencyHealth {
+	staleAfter := u.policy.Timeout + 3*u.policy.PollInterval
+	alive := 0
+	for i := range u.heartbeats {
+		if heartbeat := u.heartbeats[i].Load(); heartbeat != 0 && time.Since(time.Unix(0, heartbeat)) < staleAfter {
+			alive++
+		}
+	}
+
+	health := ports.DependencyHealth{
+		Status: ports.HealthUp,
+		Details: map[string]string{
+			"workers": strconv.Itoa(len(u.heartbeats)),
+			"alive":   strconv.Itoa(alive),
+		},
+	}
+	switch {
+	case len(u.heartbeats) == 0 || alive == 0:
+		health.Status, health.Error = ports.HealthDown, "no worker is alive"
+	case alive < len(u.heartbeats):
+		health.Status, health.Error = ports.HealthDegraded, fmt.Sprintf("%d of %d workers are not alive", len(u.heartbeats)-alive, len(u.heartbeats))
+	}
+	return health
+}
+
+// ScheduledTask returns a task of the Scheduler enqueuing a job of the type for the tenant. Jobs
+// are keyed by their scheduled time, so the instances scheduling the same job enqueue it once.
+func (u *JobUseCase) ScheduledTask(tenantID, jobType string, payload map[string]string) ScheduledTask {
+	return func(ctx context.Context, at time.Time) error {
+		job := domain.NewJob(jobType, payload, u.policy.MaxAttempts)
+		job.UniqueKey = jobType + "@" + strconv.FormatInt(at.Unix(), 10)
+		if err := u.jobs.CreateJob(domain.WithTenant(ctx, tenantID), job); err != nil && !errors.Is(err, domain.ErrJobExists) {
+			return err
+		}
+		return nil
+	}
+}
+
+// Stop stops the workers; running jobs are canceled and queued again
+func (u *JobUseCase) Stop() {
+	u.cancel()
+	u.wg.Wait()
+}
+
+func (u *JobUseCase) Enqueue(ctx context.Context, jobType string, payload map[string]string) (*domain.Job, error) {
+	job := domain.NewJob(jobType, payload, u.policy.MaxAttempts)
+	if err := u.jobs.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+func (u *JobUseCase) ListJobs(ctx context.Context, query *ports.JobQuery) (*ports.JobQueryResult, error) {
+	return u.jobs.ListJobs(ctx, query)
+}
+
+func (u *JobUseCase) GetJob(ctx context.Context, id string) (*domain.Job, error) {
+	job, err := u.jobs.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+func (u *JobUseCase) RetryJob(ctx context.Context, actorID, id string) (*domain.Job, error) {
+	job, err := u.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != domain.JobStatusFailed {
+		return nil, ErrJobNotFailed
+	}
+	job.Retry(time.Now())
+	retried, err := u.jobs.RetryJob(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+	// Another admin retried the job first
+	if !retried {
+		return nil, ErrJobNotFailed
+	}
+
+	if err := u.audit.Record(ctx, domain.AuditActionJobRetried, actorID, job.ID, map[string]string{
+		"type":       job.Type,
+		"last_error": job.LastError,
+	}); err != nil {
+		log.Printf("Error recording job retry audit event: %v", err)
+	}
+	return job, nil
+}
+
+// runNext runs the next due job, reporting whether there was one
+func (u *JobUseCase) runNext(types []string) (bool, error) {
+	if u.ctx.Err() != nil {
+		return false, nil
+	}
+	now := time.Now()
+	job, err := u.jobs.ClaimJob(u.ctx, types, now, now.Add(u.policy.Timeout))
+	if err != nil || job == nil {
+		return false, err
+	}
+
+	err = u.run(job)
+	// Saving the outcome must not be canceled by Stop
+	saveCtx, cancel := context.WithTimeout(context.WithoutCancel(u.ctx), 10*time.Second)
+	defer cancel()
+	now = time.Now()
+	switch {
+	case err == nil:
+		job.Succeed(now)
+	case u.ctx.Err() != nil:
+		// Interrupted by Stop, the run does not count
+		job.Attempts--
+		job.Fail(err, now, 0)
+	default:
+		log.Printf("Job %s (%s) failed, attempt %d of %d: %v", job.ID, job.Type, job.Attempts, job.MaxAttempts, err)
+		job.Fail(err, now, u.retryDelay(job.Attempts))
+	}
+	if err := u.jobs.UpdateJob(saveCtx, job); err != nil {
+		log.Printf("Error saving job %s: %v", job.ID, err)
+	}
+	return true, nil
+}
+
+// run calls the handler of the job within the tenant of the job, turning panics into errors
+func (u *JobUseCase) run(job *domain.Job) (err error) {
+	ctx, cancel := context.WithTimeout(domain.WithTenant(u.ctx, job.TenantID), u.policy.Timeout)
+	defer cancel()
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job panicked: %v", recovered)
+		}
+	}()
+	return u.handlers[job.Type](ctx, job)
+}
+
+// retryDelay doubles the base delay on each attempt, up to the max delay
+func (u *JobUseCase) retryDelay(attempts int) time.Duration {
+	delay := u.policy.RetryBaseDelay
+	for i := 1; i < attempts && delay < u.policy.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, u.policy.RetryMaxDelay)
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.LoginEventUseCase = (*LoginEventUseCase)(nil)
+
+// notificationTimeout bounds the delivery of a notification email, sent after the login responded
+const notificationTimeout = 30 * time.Second
+
+type LoginEventUseCase struct {
+	events ports.LoginEventRepository
+	users  ports.UserRepository
+	audit  ports.AuditUseCase
+	mailer ports.Mailer
+	geoip  ports.GeoIPResolver // Nil leaves login events without location
+	// anomalies is the policy of AssessLogin, whose rules need the location of logins
+	anomalies domain.LoginAnomalyPolicy
+	// reportURL is the address of the "wasn't me" link, the token is added as a query parameter
+	reportURL string
+}
+
+func NewLoginEventUseCase(eventRepo ports.LoginEventRepository, userRepo ports.UserRepository, auditUC ports.AuditUseCase, mailer ports.Mailer, geoip ports.GeoIPResolver, anomalies domain.LoginAnomalyPolicy, reportURL string) ports.LoginEventUseCase {
+	return &LoginEventUseCase{
+		events:    eventRepo,
+		users:     userRepo,
+		audit:     auditUC,
+		mailer:    mailer,
+		geoip:     geoip,
+		anomalies: anomalies,
+		reportURL: reportURL,
+	}
+}
+
+// RecordLogin stores the login and, if the user had logged in before but never from this device,
+// emails them the login details with a link to lock the account. The first login of a user is
+// never reported, as every device is new then.
+func (u *LoginEventUseCase) RecordLogin(ctx context.Context, user *domain.User, ip, userAgent string) (*domain.LoginEvent, error) {
+	event := domain.NewLoginEvent(user.ID, ip, userAgent)
+	if u.geoip != nil {
+		// An unavailable location must not fail the login
+		location, err := u.geoip.Resolve(ctx, ip)
+		if err != nil {
+			log.Printf("Error locating IP %s: %v", ip, err)
+		}
+		event.Location = location
+	}
+
+	known, err := u.events.LastLoginEvent(ctx, user.ID, event.Fingerprint)
+	if err != nil {
+		return nil, err
+	}
+	if known == nil {
+		previous, err := u.events.LastLoginEvent(ctx, user.ID, "")
+		if err != nil {
+			return nil, err
+		}
+		event.NewDevice = previous != nil
+	}
+
+	var token string
+	if event.NewDevice {
+		token, event.ReportTokenHash, err = domain.NewLoginReportToken(domain.TenantFromContext(ctx))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := u.events.CreateLoginEvent(ctx, event); err != nil {
+		return nil, err
+	}
+
+	if event.NewDevice {
+		// Send in the background so a slow mail server does not delay the login
+		notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notificationTimeout)
+		go func() {
+			defer cancel()
+			if err := u.mailer.Send(notifyCtx, u.newDeviceEmail(user, event, token)); err != nil {
+				log.Printf("Error sending new device notification to user %s: %v", user.ID, err)
+			}
+		}()
+	}
+	return event, nil
+}
+
+// AssessLogin compares the login with the previous login of the user. Each rule it breaks is
+// recorded in the audit log, and the user is emailed when one of them notifies or challenges.
+func (u *LoginEventUseCase) AssessLogin(ctx context.Context, user *domain.User, ip, userAgent string) ([]domain.LoginAnomaly, error) {
+	if u.geoip == nil || len(u.anomalies.Rules) == 0 {
+		return nil, nil
+	}
+	previous, err := u.events.LastLoginEvent(ctx, user.ID, "")
+	if err != nil || previous == nil || previous.Location == nil {
+		return nil, err
+	}
+	current := domain.NewLoginEvent(user.ID, ip, userAgent)
+	if current.Location, err = u.geoip.Resolve(ctx, ip); err != nil {
+		return nil, err
+	}
+
+	anomalies := u.anomalies.Evaluate(previous, current)
+	for _, anomaly := range anomalies {
+		details := maps.Clone(anomaly.Details)
+		details["rule"] = anomaly.Rule
+		details["action"] = anomaly.Action
+		if err := u.audit.Record(ctx, domain.AuditActionLoginAnomaly, user.ID, user.ID, details); err != nil {
+			log.Printf("Error recording %s login anomaly of user %s: %v", anomaly.Rule, user.ID, err)
+		}
+	}
+	if action := domain.StrictestLoginAnomalyAction(anomalies); action == domain.LoginAnomalyNotify || action == domain.LoginAnomalyChallenge {
+		notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notificationTimeout)
+		go func() {
+			defer cancel()
+			if err := u.mailer.Send(notifyCtx, u.anomalyEmail(user, previous, current)); err != nil {
+				log.Printf("Error sending login anomaly notification to user %s: %v", user.ID, err)
+			}
+		}()
+	}
+	return anomalies, nil
+}
+
+func (u *LoginEventUseCase) anomalyEmail(user *domain.User, previous, current *domain.LoginEvent) ports.EmailMessage {
+	name := user.Profile.FirstName
+	if name == "" {
+		name = user.Email
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", name)
+	body.WriteString("Your password was just used to sign in to your account from an unusual place.\n\n")
+	fmt.Fprintf(&body, "Time: %s\n", user.Profile.LocalTime(current.CreatedAt).Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(&body, "IP address: %s\n", current.IP)
+	fmt.Fprintf(&body, "Location: %s (approximate)\n", current.Location)
+	fmt.Fprintf(&body, "Previous login: %s from %s\n\n", user.Profile.LocalTime(previous.CreatedAt).Format("2006-01-02 15:04 MST"), previous.Location)
+	body.WriteString("If this was you, you can ignore this email.\n")
+	body.WriteString("If it wasn't you, someone knows your password: change it as soon as possible.\n")
+
+	return ports.EmailMessage{
+		To:      user.Email,
+		Subject: "Unusual login to your account",
+		Body:    body.String(),
+	}
+}
+
+func (u *LoginEventUseCase) newDeviceEmail(user *domain.User, event *domain.LoginEvent, token string) ports.EmailMessage {
+	name := user.Profile.FirstName
+	if name == "" {
+		name = user.Email
+	}
+	device := event.UserAgent
+	if device == "" {
+		device = "unknown"
+	}
+	link := u.reportURL + "?" + url.Values{"token": {token}}.Encode()
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", name)
+	body.WriteString("Your account was just accessed from a device we have not seen before.\n\n")
+	fmt.Fprintf(&body, "Time: %s\n", user.Profile.LocalTime(event.CreatedAt).Format("2006-01-02 15:04 MST"))
+	fmt.Fprintf(&body, "IP address: %s\n", event.IP)
+	if event.Location != nil {
+		fmt.Fprintf(&body, "Location: %s (approximate)\n", event.Location)
+	}
+	fmt.Fprintf(&body, "Device: %s\n\n", device)
+	body.WriteString("If this was you, you can ignore this email.\n")
+	body.WriteString("If it wasn't you, open the link below to block logins to your account until its password is reset:\n")
+	fmt.Fprintf(&body, "%s\n\n", link)
+	fmt.Fprintf(&body, "The link expires in %d days.\n", int(domain.LoginReportTTL.Hours()/24))
+
+	return ports.EmailMessage{
+		To:      user.Email,
+		Subject: "New login to your account",
+		Body:    body.String(),
+	}
+}
+
+func (u *LoginEventUseCase) ListLoginHistory(ctx context.Context, userID string, page, pageSize int) (*ports.LoginHistory, error) {
+	return u.events.ListLoginEvents(ctx, userID, page, pageSize)
+}
+
+// ReportLogin locks the account of a login reported through its "wasn't me" link until the
+// password is reset, revokes its tokens and sessions, the reported one included, and records the
+// report in the audit log. Reporting twice has no further effect.
+func (u *LoginEventUseCase) ReportLogin(ctx context.Context, token string) error {
+	tenantID, err := domain.ParseLoginReportToken(token)
+	if err != nil {
+		return err
+	}
+	ctx = domain.WithTenant(ctx, tenantID)
+
+	event, err := u.events.GetLoginEventByReportToken(ctx, domain.HashLoginReportToken(token))
+	if err != nil {
+		return err
+	}
+	if event == nil || time.Since(event.CreatedAt) > domain.LoginReportTTL {
+		return domain.ErrInvalidLoginReport
+	}
+	if event.ReportedAt != nil {
+		return nil
+	}
+
+	if err := u.users.RequirePasswordReset(ctx, event.UserID); err != nil {
+		return err
+	}
+	generation, err := u.users.IncrementTokenGeneration(ctx, event.UserID)
+	if err != nil {
+		return err
+	}
+	if err := u.events.SetLoginEventReported(ctx, event.ID, time.Now()); err != nil {
+		return err
+	}
+	details := map[string]string{
+		"login_event_id":   event.ID,
+		"ip":               event.IP,
+		"user_agent":       event.UserAgent,
+		"token_generation": strconv.Itoa(generation),
+	}
+	return u.audit.Record(ctx, domain.AuditActionLoginReported, event.UserID, event.UserID, details)
+}
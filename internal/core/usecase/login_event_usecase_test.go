@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+)
+
+func TestReportLogin(t *testing.T) {
+	token, hash, err := domain.NewLoginReportToken("acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reportedAt := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name      string
+		event     *domain.LoginEvent
+		wantCalls []string
+		wantErr   error
+	}{
+		{
+			name:      "reported",
+			event:     &domain.LoginEvent{ID: "e1", UserID: "u1", IP: "203.0.113.7", CreatedAt: time.Now().Add(-time.Hour)},
+			wantCalls: []string{"password reset u1", "token generation u1", "reported e1", "audit login.reported token_generation=4"},
+		},
+		{
+			name:  "reported twice",
+			event: &domain.LoginEvent{ID: "e1", UserID: "u1", CreatedAt: time.Now().Add(-time.Hour), ReportedAt: &reportedAt},
+		},
+		{
+			name:    "expired link",
+			event:   &domain.LoginEvent{ID: "e1", UserID: "u1", CreatedAt: time.Now().Add(-domain.LoginReportTTL - time.Hour)},
+			wantErr: domain.ErrInvalidLoginReport,
+		},
+		{name: "unknown link", wantErr: domain.ErrInvalidLoginReport},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			events := &mocks.LoginEventRepository{
+				GetLoginEventByReportTokenFunc: func(ctx context.Context, tokenHash string) (*domain.LoginEvent, error) {
+					if tokenHash != hash || domain.TenantFromContext(ctx) != "acme" {
+						return nil, nil
+					}
+					return tt.event, nil
+				},
+				SetLoginEventReportedFunc: func(_ context.Context, id string, _ time.Time) error {
+					calls = append(calls, "reported "+id)
+					return nil
+				},
+			}
+			users := &mocks.UserRepository{
+				RequirePasswordResetFunc: func(_ context.Context, id string) error {
+					calls = append(calls, "password reset "+id)
+					return nil
+				},
+				IncrementTokenGenerationFunc: func(_ context.Context, id string) (int, error) {
+					calls = append(calls, "token generation "+id)
+					return 4, nil
+				},
+			}
+			audit := &mocks.AuditUseCase{
+				RecordFunc: func(_ context.Context, action, _, _ string, details map[string]string) error {
+					calls = append(calls, "audit "+action+" token_generation="+details["token_generation"])
+					return nil
+				},
+			}
+			u := NewLoginEventUseCase(events, users, audit, &mocks.Mailer{}, nil, domain.LoginAnomalyPolicy{}, "")
+
+			if err := u.ReportLogin(context.Background(), token); err != tt.wantErr {
+				t.Errorf("ReportLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
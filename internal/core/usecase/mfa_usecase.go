@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+)
+
+// Compile-time interface check
+var _ ports.MFAUseCase = (*MFAUseCase)(nil)
+
+// MFAUseCase manages the second factors of users and checks their login codes. TOTP codes are
+// accepted once each; SMS codes are stored hashed like the phone verification codes and stop being
+// accepted once they expire or after domain.MaxPhoneCodeAttempts codes tried. Devices trusted after
+// a login with a second factor skip it for deviceTTL.
+type MFAUseCase struct {
+	users     ports.UserRepository
+	sms       ports.SMSSender
+	audit     ports.AuditUseCase
+	issuer    string
+	ttl       time.Duration
+	deviceTTL time.Duration
+}
+
+// NewMFAUseCase creates the use case; issuer names the TOTP factors in authenticator apps, ttl is
+// the validity of the SMS codes and deviceTTL the time trusted devices skip the second factor
+func NewMFAUseCase(users ports.UserRepository, sms ports.SMSSender, audit ports.AuditUseCase, issuer string, ttl, deviceTTL time.Duration) *MFAUseCase {
+	return &MFAUseCase{
+		users:     users,
+		sms:       sms,
+		audit:     audit,
+		issuer:    issuer,
+		ttl:       ttl,
+		deviceTTL: deviceTTL,
+	}
+}
+
+func (u *MFAUseCase) ListFactors(ctx context.Context, userID string) ([]domain.MFAFactor, error) {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.MFAFactors == nil {
+		return []domain.MFAFactor{}, nil
+	}
+	return user.MFAFactors, nil
+}
+
+func (u *MFAUseCase) AddFactor(ctx context.Context, userID, factorType, label string) (*domain.MFAEnrollment, error) {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	factor, err := domain.NewMFAFactor(factorType, label)
+	if err != nil {
+		return nil, err
+	}
+	if len(user.MFAFactors) >= domain.MaxMFAFactors {
+		return nil, domain.ErrTooManyMFAFactors
+	}
+
+	enrollment := &domain.MFAEnrollment{}
+	switch factor.Type {
+	case domain.MFAFactorTOTP:
+		if factor.Secret, err = security.GenerateTOTPSecret(); err != nil {
+			return nil, err
+		}
+		enrollment.Secret = factor.Secret
+		enrollment.URI = security.TOTPURI(u.issuer, user.Email, factor.Secret)
+	case domain.MFAFactorSMS:
+		// Codes are only texted to a phone the user proved to own
+		if user.PhoneVerifiedAt == nil {
+			return nil, domain.ErrMFAPhoneUnverified
+		}
+		if factor.Phone, err = domain.NormalizePhone(user.Profile.Phone); err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(user.MFAFactors, func(f domain.MFAFactor) bool { return f.Phone == factor.Phone }) {
+			return nil, domain.ErrMFAFactorExists
+		}
+		factor.ConfirmedAt = &factor.CreatedAt
+	}
+
+	if err := u.users.SetMFAFactors(ctx, userID, append(user.MFAFactors, factor)); err != nil {
+		return nil, err
+	}
+	if factor.Confirmed() {
+		u.recordFactor(ctx, domain.AuditActionMFAFactorAdded, userID, factor)
+	}
+	enrollment.Factor = factor
+	return enrollment, nil
+}
+
+func (u *MFAUseCase) ConfirmFactor(ctx context.Context, userID, factorID, code string) (*domain.MFAFactor, error) {
+	user, index, err := u.getFactor(ctx, userID, factorID)
+	if err != nil {
+		return nil, err
+	}
+	factor := &user.MFAFactors[index]
+	if factor.Confirmed() {
+		return nil, domain.ErrMFAFactorConfirmed
+	}
+	if factor.Type != domain.MFAFactorTOTP {
+		return nil, domain.ErrInvalidMFAFactorType
+	}
+
+	now := time.Now()
+	step, ok := security.VerifyTOTP(factor.Secret, code, now, factor.LastUsedStep)
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+	factor.ConfirmedAt = &now
+	factor.LastUsedStep = step
+	if err := u.users.SetMFAFactors(ctx, userID, user.MFAFactors); err != nil {
+		return nil, err
+	}
+	u.recordFactor(ctx, domain.AuditActionMFAFactorAdded, userID, *factor)
+	return factor, nil
+}
+
+func (u *MFAUseCase) RemoveFactor(ctx context.Context, userID, factorID string) error {
+	user, index, err := u.getFactor(ctx, userID, factorID)
+	if err != nil {
+		return err
+	}
+	factor := user.MFAFactors[index]
+	if err := u.users.SetMFAFactors(ctx, userID, slices.Delete(user.MFAFactors, index, index+1)); err != nil {
+		return err
+	}
+	if factor.Confirmed() {
+		u.recordFactor(ctx, domain.AuditActionMFAFactorRemoved, userID, factor)
+	}
+	return nil
+}
+
+func (u *MFAUseCase) SendCode(ctx context.Context, userID, factorID string) (time.Time, error) {
+	user, index, err := u.getFactor(ctx, userID, factorID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	factor := &user.MFAFactors[index]
+	if factor.Type != domain.MFAFactorSMS {
+		return time.Time{}, domain.ErrMFAFactorNotSMS
+	}
+	now := time.Now()
+	if pending := factor.Challenge; pending != nil && now.Before(pending.SentAt.Add(phoneCodeCooldown)) {
+		return time.Time{}, domain.ErrPhoneCodeTooFrequent
+	}
+
+	code, err := newPhoneCode()
+	if err != nil {
+		return time.Time{}, err
+	}
+	hash, err := security.HashPassword(code)
+	if err != nil {
+		return time.Time{}, err
+	}
+	factor.Challenge = &domain.PhoneChallenge{Phone: factor.Phone, CodeHash: hash, SentAt: now, ExpiresAt: now.Add(u.ttl)}
+	if err := u.users.SetMFAFactors(ctx, userID, user.MFAFactors); err != nil {
+		return time.Time{}, err
+	}
+
+	message := ports.SMSMessage{
+		To:   factor.Phone,
+		Body: fmt.Sprintf("Your login code is %s. It expires in %d minutes.", code, int(u.ttl.Minutes())),
+	}
+	if err := u.sms.Send(ctx, message); err != nil {
+		// The code never reached the user, let it request another one right away
+		factor.Challenge = nil
+		u.users.SetMFAFactors(ctx, userID, user.MFAFactors)
+		return time.Time{}, err
+	}
+	return now.Add(u.ttl), nil
+}
+
+func (u *MFAUseCase) Verify(ctx context.Context, userID, factorID, code string) error {
+	user, index, err := u.getFactor(ctx, userID, factorID)
+	if err != nil {
+		return err
+	}
+	factor := &user.MFAFactors[index]
+	if !factor.Confirmed() {
+		return domain.ErrMFAFactorNotFound
+	}
+
+	now := time.Now()
+	switch factor.Type {
+	case domain.MFAFactorTOTP:
+		step, ok := security.VerifyTOTP(factor.Secret, code, now, factor.LastUsedStep)
+		if !ok {
+			return domain.ErrInvalidMFACode
+		}
+		// A concurrent login may have used the same code since the factor was read
+		used, err := u.users.UseMFAStep(ctx, userID, factorID, step, now)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return domain.ErrInvalidMFACode
+		}
+	case domain.MFAFactorSMS:
+		challenge := factor.Challenge
+		if challenge.Expired(now) {
+			return domain.ErrPhoneCodeExpired
+		}
+		// The attempt is counted before the code is checked, so that concurrent guesses cannot go
+		// past domain.MaxPhoneCodeAttempts
+		counted, err := u.users.AddMFAChallengeAttempt(ctx, userID, factorID)
+		if err != nil {
+			return err
+		}
+		if !counted {
+			return domain.ErrPhoneCodeAttempts
+		}
+		if err := security.VerifyPassword(challenge.CodeHash, code); err != nil {
+			return domain.ErrInvalidMFACode
+		}
+		consumed, err := u.users.ConsumeMFAChallenge(ctx, userID, factorID, challenge.CodeHash, now)
+		if err != nil {
+			return err
+		}
+		if !consumed {
+			// A concurrent login used the code, or another one was sent since
+			return domain.ErrPhoneCodeExpired
+		}
+	}
+	return nil
+}
+
+func (u *MFAUseCase) TrustDevice(ctx context.Context, userID, userAgent, ip string) (*domain.TrustedDevice, string, error) {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	device, token, err := domain.NewTrustedDevice(userAgent, ip, u.deviceTTL)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := u.users.SetTrustedDevices(ctx, userID, user.TrustDevice(device, device.CreatedAt)); err != nil {
+		return nil, "", err
+	}
+	details := map[string]string{"device_id": device.ID, "ip": ip}
+	if err := u.audit.Record(ctx, domain.AuditActionDeviceTrusted, userID, userID, details); err != nil {
+		log.Printf("Error recording trusted device %s: %v", device.ID, err)
+	}
+	return &device, token, nil
+}
+
+func (u *MFAUseCase) UseTrustedDevice(ctx context.Context, user *domain.User, token string) (bool, error) {
+	now := time.Now()
+	devices := user.ActiveTrustedDevices(now)
+	hash := domain.HashTrustedDeviceToken(token)
+	index := slices.IndexFunc(devices, func(d domain.TrustedDevice) bool {
+		return subtle.ConstantTimeCompare([]byte(d.TokenHash), []byte(hash)) == 1
+	})
+	if index < 0 {
+		return false, nil
+	}
+	devices[index].LastUsedAt = &now
+	if err := u.users.SetTrustedDevices(ctx, user.ID, devices); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (u *MFAUseCase) ListTrustedDevices(ctx context.Context, userID string) ([]domain.TrustedDevice, error) {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return user.ActiveTrustedDevices(time.Now()), nil
+}
+
+func (u *MFAUseCase) RevokeTrustedDevice(ctx context.Context, userID, deviceID string) error {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+	devices := user.ActiveTrustedDevices(time.Now())
+	index := slices.IndexFunc(devices, func(d domain.TrustedDevice) bool { return d.ID == deviceID })
+	if index < 0 {
+		return domain.ErrTrustedDeviceNotFound
+	}
+	if err := u.users.SetTrustedDevices(ctx, userID, slices.Delete(devices, index, index+1)); err != nil {
+		return err
+	}
+	if err := u.audit.Record(ctx, domain.AuditActionDeviceRevoked, userID, userID, map[string]string{"device_id": deviceID}); err != nil {
+		log.Printf("Error recording revocation of trusted device %s: %v", deviceID, err)
+	}
+	return nil
+}
+
+func (u *MFAUseCase) RevokeTrustedDevices(ctx context.Context, userID string) (int, error) {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	devices := user.ActiveTrustedDevices(time.Now())
+	if len(user.TrustedDevices) == 0 {
+		return 0, nil
+	}
+	if err := u.users.SetTrustedDevices(ctx, userID, nil); err != nil {
+		return 0, err
+	}
+	if len(devices) > 0 {
+		details := map[string]string{"devices": strconv.Itoa(len(devices))}
+		if err := u.audit.Record(ctx, domain.AuditActionDeviceRevoked, userID, userID, details); err != nil {
+			log.Printf("Error recording revocation of the trusted devices of user %s: %v", userID, err)
+		}
+	}
+	return len(devices), nil
+}
+
+// recordFactor records the addition or removal of a confirmed factor in the audit log
+func (u *MFAUseCase) recordFactor(ctx context.Context, action, userID string, factor domain.MFAFactor) {
+	details := map[string]string{"factor_id": factor.ID, "type": factor.Type}
+	if err := u.audit.Record(ctx, action, userID, userID, details); err != nil {
+		log.Printf("Error recording %s of factor %s: %v", action, factor.ID, err)
+	}
+}
+
+func (u *MFAUseCase) getUser(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// getFactor returns the user and the index of its factor
+func (u *MFAUseCase) getFactor(ctx context.Context, userID, factorID string) (*domain.User, int, error) {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	index := user.MFAFactor(factorID)
+	if index < 0 {
+		return nil, 0, domain.ErrMFAFactorNotFound
+	}
+	return user, index, nil
+}
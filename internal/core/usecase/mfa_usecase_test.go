@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+)
+
+func TestMFAVerify(t *testing.T) {
+	secret, err := security.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	step := security.TOTPStep(time.Now())
+	totpCode, err := security.TOTPCode(secret, step)
+	if err != nil {
+		t.Fatal(err)
+	}
+	codeHash, err := security.HashPassword("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	confirmed := time.Now().Add(-time.Hour)
+	totp := domain.MFAFactor{ID: "f1", Type: domain.MFAFactorTOTP, Secret: secret, ConfirmedAt: &confirmed}
+	sms := domain.MFAFactor{
+		ID:          "f1",
+		Type:        domain.MFAFactorSMS,
+		Phone:       "+15551234567",
+		ConfirmedAt: &confirmed,
+		Challenge:   &domain.PhoneChallenge{CodeHash: codeHash, SentAt: time.Now(), ExpiresAt: time.Now().Add(time.Minute)},
+	}
+
+	tests := []struct {
+		name   string
+		factor domain.MFAFactor
+		code   string
+		// stored is what the conditional updates of the store report, as the concurrent logins left it
+		stored    bool
+		attempts  bool
+		wantCalls []string
+		wantErr   error
+	}{
+		{name: "totp code", factor: totp, code: totpCode, stored: true, wantCalls: []string{"step"}},
+		{name: "totp code used concurrently", factor: totp, code: totpCode, wantCalls: []string{"step"}, wantErr: domain.ErrInvalidMFACode},
+		{name: "wrong totp code", factor: totp, code: "000000", wantErr: domain.ErrInvalidMFACode},
+		{name: "sms code", factor: sms, code: "123456", stored: true, attempts: true, wantCalls: []string{"attempt", "consume"}},
+		{name: "wrong sms code", factor: sms, code: "654321", attempts: true, wantCalls: []string{"attempt"}, wantErr: domain.ErrInvalidMFACode},
+		{name: "sms attempts used up concurrently", factor: sms, code: "123456", stored: true, wantCalls: []string{"attempt"}, wantErr: domain.ErrPhoneCodeAttempts},
+		{name: "sms code used concurrently", factor: sms, code: "123456", attempts: true, wantCalls: []string{"attempt", "consume"}, wantErr: domain.ErrPhoneCodeExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			users := &mocks.UserRepository{
+				GetUserByIDFunc: func(context.Context, string) (*domain.User, error) {
+					return &domain.User{ID: "u1", MFAFactors: []domain.MFAFactor{tt.factor}}, nil
+				},
+				UseMFAStepFunc: func(_ context.Context, _, _ string, used int64, _ time.Time) (bool, error) {
+					if used != step {
+						t.Errorf("UseMFAStep() step = %d, want %d", used, step)
+					}
+					calls = append(calls, "step")
+					return tt.stored, nil
+				},
+				AddMFAChallengeAttemptFunc: func(context.Context, string, string) (bool, error) {
+					calls = append(calls, "attempt")
+					return tt.attempts, nil
+				},
+				ConsumeMFAChallengeFunc: func(_ context.Context, _, _, hash string, _ time.Time) (bool, error) {
+					if hash != codeHash {
+						t.Errorf("ConsumeMFAChallenge() code hash = %s, want %s", hash, codeHash)
+					}
+					calls = append(calls, "consume")
+					return tt.stored, nil
+				},
+				SetMFAFactorsFunc: func(context.Context, string, []domain.MFAFactor) error {
+					t.Error("SetMFAFactors() called, want the factor updated on its own")
+					return nil
+				},
+			}
+			u := NewMFAUseCase(users, &mocks.SMSSender{}, &mocks.AuditUseCase{}, "User Management API", 5*time.Minute, time.Hour)
+
+			if err := u.Verify(context.Background(), "u1", "f1", tt.code); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
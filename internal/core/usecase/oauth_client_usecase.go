@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.OAuthClientUseCase = (*OAuthClientUseCase)(nil)
+
+var (
+	ErrClientNotFound           = errors.New("client not found")
+	ErrInvalidClientCredentials = errors.New("invalid client credentials")
+)
+
+type OAuthClientUseCase struct {
+	clients ports.OAuthClientRepository
+	audit   ports.AuditUseCase
+}
+
+func NewOAuthClientUseCase(clientRepo ports.OAuthClientRepository, auditUC ports.AuditUseCase) ports.OAuthClientUseCase {
+	return &OAuthClientUseCase{
+		clients: clientRepo,
+		audit:   auditUC,
+	}
+}
+
+func (u *OAuthClientUseCase) RegisterClient(ctx context.Context, actorID, name string, scopes []string) (*domain.OAuthClient, string, error) {
+	client, secret, err := domain.NewOAuthClient(name, scopes, actorID)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := u.clients.CreateClient(ctx, client); err != nil {
+		return nil, "", err
+	}
+
+	details := map[string]string{"name": client.Name, "scopes": strings.Join(client.Scopes, " ")}
+	if err := u.audit.Record(ctx, domain.AuditActionClientRegistered, actorID, client.ID, details); err != nil {
+		log.Printf("Error recording registration of client %s: %v", client.ID, err)
+	}
+	return client, secret, nil
+}
+
+func (u *OAuthClientUseCase) GetClient(ctx context.Context, id string) (*domain.OAuthClient, error) {
+	client, err := u.clients.GetClientByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		return nil, ErrClientNotFound
+	}
+	return client, nil
+}
+
+func (u *OAuthClientUseCase) ListClients(ctx context.Context) ([]*domain.OAuthClient, error) {
+	return u.clients.ListClients(ctx)
+}
+
+// DeleteClient deletes the client, whose tokens are then refused
+func (u *OAuthClientUseCase) DeleteClient(ctx context.Context, actorID, id string) error {
+	deleted, err := u.clients.DeleteClient(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrClientNotFound
+	}
+	if err := u.audit.Record(ctx, domain.AuditActionClientDeleted, actorID, id, nil); err != nil {
+		log.Printf("Error recording deletion of client %s: %v", id, err)
+	}
+	return nil
+}
+
+// AuthenticateClient fails the same way for unknown clients and wrong secrets
+func (u *OAuthClientUseCase) AuthenticateClient(ctx context.Context, id, secret string) (*domain.OAuthClient, error) {
+	client, err := u.clients.GetClientByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil || !client.VerifySecret(secret) {
+		return nil, ErrInvalidClientCredentials
+	}
+	return client, nil
+}
@@ -0,0 +1,212 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.OrganizationUseCase = (*OrganizationUseCase)(nil)
+
+var (
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrSlugTaken            = errors.New("organization slug is already in use")
+	ErrNotMember            = errors.New("permission denied: not a member of this organization")
+	ErrOrgPermissionDenied  = errors.New("permission denied: organization owner or admin role required")
+	ErrMembershipNotFound   = errors.New("membership not found")
+	ErrLastOwner            = errors.New("permission denied: an organization must keep at least one owner")
+)
+
+type OrganizationUseCase struct {
+	orgs  ports.OrganizationRepository
+	users ports.UserRepository
+}
+
+func NewOrganizationUseCase(orgRepo ports.OrganizationRepository, userRepo ports.UserRepository) ports.OrganizationUseCase {
+	return &OrganizationUseCase{
+		orgs:  orgRepo,
+		users: userRepo,
+	}
+}
+
+// CreateOrganization creates an organization owned by the actor
+func (u *OrganizationUseCase) CreateOrganization(ctx context.Context, actorID, name, slug string) (*domain.Organization, error) {
+	org, err := domain.NewOrganization(name, slug)
+	if err != nil {
+		return nil, err
+	}
+	if existing, _ := u.orgs.GetOrganizationBySlug(ctx, org.Slug); existing != nil {
+		return nil, ErrSlugTaken
+	}
+	owner, err := domain.NewMembership(org.ID, actorID, domain.MembershipRoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.orgs.CreateOrganization(ctx, org); err != nil {
+		return nil, err
+	}
+	if err := u.orgs.CreateMembership(ctx, owner); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+func (u *OrganizationUseCase) GetOrganization(ctx context.Context, actorID, id string) (*domain.Organization, error) {
+	org, _, err := u.authorize(ctx, actorID, id, false)
+	return org, err
+}
+
+func (u *OrganizationUseCase) UpdateOrganization(ctx context.Context, actorID, id, name string) (*domain.Organization, error) {
+	org, _, err := u.authorize(ctx, actorID, id, true)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > 100 {
+		return nil, domain.ErrInvalidOrganizationName
+	}
+	org.Name = name
+	org.UpdatedAt = time.Now()
+	if err := u.orgs.UpdateOrganization(ctx, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// DeleteOrganization deletes the organization; only owners may do so
+func (u *OrganizationUseCase) DeleteOrganization(ctx context.Context, actorID, id string) error {
+	_, membership, err := u.authorize(ctx, actorID, id, true)
+	if err != nil {
+		return err
+	}
+	if membership.Role != domain.MembershipRoleOwner {
+		return ErrOrgPermissionDenied
+	}
+	return u.orgs.DeleteOrganization(ctx, id)
+}
+
+func (u *OrganizationUseCase) ListMembers(ctx context.Context, actorID, organizationID string) ([]*domain.Membership, error) {
+	if _, _, err := u.authorize(ctx, actorID, organizationID, false); err != nil {
+		return nil, err
+	}
+	return u.orgs.ListMembers(ctx, organizationID)
+}
+
+// SetMember adds the user to the organization or changes their role
+func (u *OrganizationUseCase) SetMember(ctx context.Context, actorID, organizationID, userID, role string) (*domain.Membership, error) {
+	_, actor, err := u.authorize(ctx, actorID, organizationID, true)
+	if err != nil {
+		return nil, err
+	}
+	if !domain.ValidMembershipRole(role) {
+		return nil, domain.ErrInvalidMembershipRole
+	}
+	// Only owners can grant or revoke ownership
+	if role == domain.MembershipRoleOwner && actor.Role != domain.MembershipRoleOwner {
+		return nil, ErrOrgPermissionDenied
+	}
+
+	existing, err := u.orgs.GetMembership(ctx, organizationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Role == domain.MembershipRoleOwner && role != domain.MembershipRoleOwner {
+			if actor.Role != domain.MembershipRoleOwner {
+				return nil, ErrOrgPermissionDenied
+			}
+			if err := u.ensureAnotherOwner(ctx, organizationID); err != nil {
+				return nil, err
+			}
+		}
+		if err := u.orgs.UpdateMembershipRole(ctx, organizationID, userID, role); err != nil {
+			return nil, err
+		}
+		existing.Role = role
+		existing.UpdatedAt = time.Now()
+		return existing, nil
+	}
+
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	membership, err := domain.NewMembership(organizationID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.orgs.CreateMembership(ctx, membership); err != nil {
+		return nil, err
+	}
+	return membership, nil
+}
+
+// RemoveMember removes a user from the organization; members may always remove themselves
+func (u *OrganizationUseCase) RemoveMember(ctx context.Context, actorID, organizationID, userID string) error {
+	_, actor, err := u.authorize(ctx, actorID, organizationID, actorID != userID)
+	if err != nil {
+		return err
+	}
+
+	membership, err := u.orgs.GetMembership(ctx, organizationID, userID)
+	if err != nil {
+		return err
+	}
+	if membership == nil {
+		return ErrMembershipNotFound
+	}
+	if membership.Role == domain.MembershipRoleOwner {
+		if actor.Role != domain.MembershipRoleOwner {
+			return ErrOrgPermissionDenied
+		}
+		if err := u.ensureAnotherOwner(ctx, organizationID); err != nil {
+			return err
+		}
+	}
+	return u.orgs.DeleteMembership(ctx, organizationID, userID)
+}
+
+func (u *OrganizationUseCase) ListUserMemberships(ctx context.Context, userID string) ([]*domain.Membership, error) {
+	return u.orgs.ListUserMemberships(ctx, userID)
+}
+
+// authorize loads the organization and the actor's membership, optionally requiring a managing role
+func (u *OrganizationUseCase) authorize(ctx context.Context, actorID, organizationID string, manage bool) (*domain.Organization, *domain.Membership, error) {
+	org, err := u.orgs.GetOrganizationByID(ctx, organizationID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if org == nil {
+		return nil, nil, ErrOrganizationNotFound
+	}
+	membership, err := u.orgs.GetMembership(ctx, organizationID, actorID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if membership == nil {
+		return nil, nil, ErrNotMember
+	}
+	if manage && !membership.CanManage() {
+		return nil, nil, ErrOrgPermissionDenied
+	}
+	return org, membership, nil
+}
+
+func (u *OrganizationUseCase) ensureAnotherOwner(ctx context.Context, organizationID string) error {
+	owners, err := u.orgs.CountMembersWithRole(ctx, organizationID, domain.MembershipRoleOwner)
+	if err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
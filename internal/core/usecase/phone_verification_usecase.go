@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+)
+
+// Compile-time interface check
+var _ ports.PhoneVerificationUseCase = (*PhoneVerificationUseCase)(nil)
+
+// phoneCodeCooldown is the time to wait before another code is sent to the same phone
+const phoneCodeCooldown = time.Minute
+
+// PhoneVerificationUseCase verifies the phone numbers of users with one-time codes sent by SMS.
+// Codes are stored hashed, and a code stops being accepted once it expires, after
+// domain.MaxPhoneCodeAttempts wrong codes, or when the phone of the profile changes.
+type PhoneVerificationUseCase struct {
+	users ports.UserRepository
+	sms   ports.SMSSender
+	ttl   time.Duration
+}
+
+func NewPhoneVerificationUseCase(users ports.UserRepository, sms ports.SMSSender, ttl time.Duration) *PhoneVerificationUseCase {
+	return &PhoneVerificationUseCase{
+		users: users,
+		sms:   sms,
+		ttl:   ttl,
+	}
+}
+
+func (u *PhoneVerificationUseCase) SendCode(ctx context.Context, userID string) (time.Time, error) {
+	user, phone, err := u.requirePhone(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if user.PhoneVerifiedAt != nil {
+		return time.Time{}, domain.ErrPhoneAlreadyVerified
+	}
+	now := time.Now()
+	if pending := user.PhoneChallenge; pending != nil && pending.Phone == phone && now.Before(pending.SentAt.Add(phoneCodeCooldown)) {
+		return time.Time{}, domain.ErrPhoneCodeTooFrequent
+	}
+
+	code, err := newPhoneCode()
+	if err != nil {
+		return time.Time{}, err
+	}
+	hash, err := security.HashPassword(code)
+	if err != nil {
+		return time.Time{}, err
+	}
+	challenge := &domain.PhoneChallenge{Phone: phone, CodeHash: hash, SentAt: now, ExpiresAt: now.Add(u.ttl)}
+	if err := u.users.SetPhoneChallenge(ctx, userID, challenge); err != nil {
+		return time.Time{}, err
+	}
+
+	message := ports.SMSMessage{
+		To:   phone,
+		Body: fmt.Sprintf("Your verification code is %s. It expires in %d minutes.", code, int(u.ttl.Minutes())),
+	}
+	if err := u.sms.Send(ctx, message); err != nil {
+		// The code never reached the user, let it request another one right away
+		u.users.SetPhoneChallenge(ctx, userID, nil)
+		return time.Time{}, err
+	}
+	return challenge.ExpiresAt, nil
+}
+
+func (u *PhoneVerificationUseCase) ConfirmCode(ctx context.Context, userID, code string) (*domain.User, error) {
+	user, phone, err := u.requirePhone(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	challenge := user.PhoneChallenge
+	if challenge.Expired(now) || challenge.Phone != phone {
+		return nil, domain.ErrPhoneCodeExpired
+	}
+	if challenge.Attempts >= domain.MaxPhoneCodeAttempts {
+		return nil, domain.ErrPhoneCodeAttempts
+	}
+
+	if err := security.VerifyPassword(challenge.CodeHash, code); err != nil {
+		if err := u.users.AddPhoneChallengeAttempt(ctx, userID); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidPhoneCode
+	}
+	verified, err := u.users.SetPhoneVerified(ctx, userID, user.Profile.Phone, &now)
+	if err != nil {
+		return nil, err
+	}
+	if !verified {
+		// The phone changed since the code was checked
+		return nil, domain.ErrPhoneCodeExpired
+	}
+	user.PhoneVerifiedAt = &now
+	user.PhoneChallenge = nil
+	return user, nil
+}
+
+// requirePhone returns the user and the E.164 form of the phone of its profile
+func (u *PhoneVerificationUseCase) requirePhone(ctx context.Context, userID string) (*domain.User, string, error) {
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if user == nil {
+		return nil, "", ErrUserNotFound
+	}
+	if user.Profile.Phone == "" {
+		return nil, "", domain.ErrPhoneMissing
+	}
+	phone, err := domain.NormalizePhone(user.Profile.Phone)
+	if err != nil {
+		return nil, "", err
+	}
+	return user, phone, nil
+}
+
+// newPhoneCode returns a random code of domain.PhoneCodeLength digits
+func newPhoneCode() (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(domain.PhoneCodeLength), nil)
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", domain.PhoneCodeLength, n), nil
+}
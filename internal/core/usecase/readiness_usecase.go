@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.ReadinessUseCase = (*ReadinessUseCase)(nil)
+
+// readinessCheckTimeout bounds the check of each dependency, which is down past it
+const readinessCheckTimeout = 2 * time.Second
+
+// ReadinessUseCase checks every dependency of the instance at once, each on its own, so a slow or
+// failing dependency neither hides nor delays the health of the others
+type ReadinessUseCase struct {
+	checks []ports.HealthCheck
+}
+
+func NewReadinessUseCase(checks []ports.HealthCheck) ports.ReadinessUseCase {
+	return &ReadinessUseCase{
+		checks: checks,
+	}
+}
+
+func (u *ReadinessUseCase) CheckReadiness(ctx context.Context) *ports.ReadinessReport {
+	report := &ports.ReadinessReport{
+		Status:       ports.HealthUp,
+		Dependencies: make([]ports.DependencyHealth, len(u.checks)),
+		CheckedAt:    time.Now(),
+	}
+	var wg sync.WaitGroup
+	for i, check := range u.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report.Dependencies[i] = u.check(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	for _, dependency := range report.Dependencies {
+		switch {
+		case dependency.Status == ports.HealthDown && dependency.Critical:
+			report.Status = ports.HealthDown
+		case dependency.Status != ports.HealthUp && report.Status == ports.HealthUp:
+			report.Status = ports.HealthDegraded
+		}
+	}
+	return report
+}
+
+// check runs the check within readinessCheckTimeout, even when the checker ignores its context
+func (u *ReadinessUseCase) check(ctx context.Context, check ports.HealthCheck) ports.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	result := make(chan ports.DependencyHealth, 1)
+	go func() {
+		result <- check.Checker.CheckHealth(ctx)
+	}()
+
+	var health ports.DependencyHealth
+	select {
+	case health = <-result:
+	case <-ctx.Done():
+		health = ports.DependencyHealth{Status: ports.HealthDown, Error: "health check timed out after " + readinessCheckTimeout.String()}
+	}
+	health.Name, health.Critical = check.Name, check.Critical
+	return health
+}
@@ -0,0 +1,172 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.RelationshipUseCase = (*RelationshipUseCase)(nil)
+
+var (
+	ErrRelationshipNotFound = errors.New("relationship not found: the user is not followed")
+	ErrBlockNotFound        = errors.New("block not found: the user is not blocked")
+	// ErrBlockedByUser is returned when following a user blocking the follower
+	ErrBlockedByUser = errors.New("permission denied: the user blocked you")
+	// ErrFollowBlocked is returned when following a user blocked by the follower
+	ErrFollowBlocked = errors.New("invalid relationship: unblock the user before following it")
+)
+
+type RelationshipUseCase struct {
+	relationships ports.RelationshipRepository
+	users         ports.UserRepository
+}
+
+func NewRelationshipUseCase(relationships ports.RelationshipRepository, users ports.UserRepository) *RelationshipUseCase {
+	return &RelationshipUseCase{
+		relationships: relationships,
+		users:         users,
+	}
+}
+
+func (u *RelationshipUseCase) Follow(ctx context.Context, followerID, followeeID string) (*domain.Relationship, error) {
+	relationship, err := domain.NewRelationship(followerID, followeeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.requireUser(ctx, followeeID); err != nil {
+		return nil, err
+	}
+	blocked, err := u.relationships.GetBlock(ctx, followerID, followeeID)
+	if err != nil {
+		return nil, err
+	}
+	if blocked != nil {
+		return nil, ErrFollowBlocked
+	}
+	blockers, err := u.relationships.ListBlockers(ctx, followerID, []string{followeeID})
+	if err != nil {
+		return nil, err
+	}
+	if len(blockers) > 0 {
+		return nil, ErrBlockedByUser
+	}
+	err = u.relationships.CreateRelationship(ctx, relationship)
+	if errors.Is(err, domain.ErrRelationshipExists) {
+		return u.relationships.GetRelationship(ctx, followerID, followeeID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return relationship, nil
+}
+
+// Unfollow also works when the followee was deleted since
+func (u *RelationshipUseCase) Unfollow(ctx context.Context, followerID, followeeID string) error {
+	deleted, err := u.relationships.DeleteRelationship(ctx, followerID, followeeID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrRelationshipNotFound
+	}
+	return nil
+}
+
+func (u *RelationshipUseCase) ListFollowers(ctx context.Context, query *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error) {
+	if err := u.requireVisibleUser(ctx, query.UserID, query.ViewerID); err != nil {
+		return nil, err
+	}
+	return u.relationships.ListFollowers(ctx, query)
+}
+
+func (u *RelationshipUseCase) ListFollowing(ctx context.Context, query *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error) {
+	if err := u.requireVisibleUser(ctx, query.UserID, query.ViewerID); err != nil {
+		return nil, err
+	}
+	return u.relationships.ListFollowing(ctx, query)
+}
+
+func (u *RelationshipUseCase) CountRelationships(ctx context.Context, userID string) (*domain.RelationshipCounts, error) {
+	return u.relationships.CountRelationships(ctx, userID)
+}
+
+func (u *RelationshipUseCase) Block(ctx context.Context, blockerID, blockedID string) (*domain.Block, error) {
+	block, err := domain.NewBlock(blockerID, blockedID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.requireUser(ctx, blockedID); err != nil {
+		return nil, err
+	}
+	err = u.relationships.CreateBlock(ctx, block)
+	if errors.Is(err, domain.ErrBlockExists) {
+		if block, err = u.relationships.GetBlock(ctx, blockerID, blockedID); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Removed after storing the block, so a concurrent follow cannot slip in between
+	if _, err := u.relationships.DeleteRelationship(ctx, blockerID, blockedID); err != nil {
+		return nil, err
+	}
+	if _, err := u.relationships.DeleteRelationship(ctx, blockedID, blockerID); err != nil {
+		return nil, err
+	}
+	return block, nil
+}
+
+// Unblock also works when the blocked user was deleted since; the relationships removed by the
+// block are not restored
+func (u *RelationshipUseCase) Unblock(ctx context.Context, blockerID, blockedID string) error {
+	deleted, err := u.relationships.DeleteBlock(ctx, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrBlockNotFound
+	}
+	return nil
+}
+
+func (u *RelationshipUseCase) ListBlocks(ctx context.Context, query *ports.RelationshipQuery) (*ports.BlockQueryResult, error) {
+	return u.relationships.ListBlocks(ctx, query)
+}
+
+func (u *RelationshipUseCase) ListBlockers(ctx context.Context, viewerID string, userIDs []string) ([]string, error) {
+	if viewerID == "" || len(userIDs) == 0 {
+		return nil, nil
+	}
+	return u.relationships.ListBlockers(ctx, viewerID, userIDs)
+}
+
+// requireVisibleUser hides the users blocking the viewer as if they did not exist
+func (u *RelationshipUseCase) requireVisibleUser(ctx context.Context, userID, viewerID string) error {
+	if err := u.requireUser(ctx, userID); err != nil {
+		return err
+	}
+	blockers, err := u.ListBlockers(ctx, viewerID, []string{userID})
+	if err != nil {
+		return err
+	}
+	if len(blockers) > 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (u *RelationshipUseCase) requireUser(ctx context.Context, userID string) error {
+	exists, err := u.users.UserExists(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUserNotFound
+	}
+	return nil
+}
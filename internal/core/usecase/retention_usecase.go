@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.RetentionUseCase = (*RetentionUseCase)(nil)
+
+// RetentionUseCase permanently removes the data of every tenant kept longer than its retention
+// window: soft-deleted users, the login history and expired login report tokens. Scheduled purges
+// run as jobs, see RunJob.
+type RetentionUseCase struct {
+	store  ports.RetentionRepository
+	policy domain.RetentionPolicy
+}
+
+func NewRetentionUseCase(store ports.RetentionRepository, policy domain.RetentionPolicy) *RetentionUseCase {
+	return &RetentionUseCase{
+		store:  store,
+		policy: policy,
+	}
+}
+
+// RunJob is the handler of the retention purge jobs, purging or, when the dry_run payload is true,
+// counting what would be purged, and logging the report
+func (u *RetentionUseCase) RunJob(ctx context.Context, job *domain.Job) error {
+	dryRun, _ := strconv.ParseBool(job.Payload["dry_run"])
+	report, err := u.Purge(ctx, dryRun)
+	log.Printf("Retention purge (dry run: %t): %d users deleted, %d users anonymized, %d login events deleted, %d login report tokens cleared",
+		report.DryRun, report.DeletedUsers, report.AnonymizedUsers, report.LoginEvents, report.LoginReportTokens)
+	return err
+}
+
+// Purge stops at the first failing step, returning the report of the steps done before
+func (u *RetentionUseCase) Purge(ctx context.Context, dryRun bool) (*ports.RetentionReport, error) {
+	now := time.Now()
+	report := &ports.RetentionReport{StartedAt: now, DryRun: dryRun}
+	defer func() { report.FinishedAt = time.Now() }()
+
+	var err error
+	if u.policy.DeletedUsers > 0 {
+		deletedBefore := now.Add(-u.policy.DeletedUsers)
+		if u.policy.DeletedUsersAction == domain.RetentionAnonymize {
+			report.AnonymizedUsers, err = u.store.AnonymizeUsers(ctx, deletedBefore, dryRun)
+		} else {
+			report.DeletedUsers, err = u.store.DeleteUsers(ctx, deletedBefore, dryRun)
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+	if u.policy.LoginEvents > 0 {
+		if report.LoginEvents, err = u.store.DeleteLoginEvents(ctx, now.Add(-u.policy.LoginEvents), dryRun); err != nil {
+			return report, err
+		}
+	}
+	// Report links cannot be used once expired, so their tokens are always removed
+	if report.LoginReportTokens, err = u.store.ClearLoginReportTokens(ctx, now.Add(-domain.LoginReportTTL), dryRun); err != nil {
+		return report, err
+	}
+	return report, nil
+}
@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+)
+
+// retentionStore returns a store purging count documents at each step, failing the step named
+// failing, and recording each step with the age of its cutoff and whether it was a dry run
+func retentionStore(calls *[]string, count int64, failing string) *mocks.RetentionRepository {
+	step := func(name string) func(context.Context, time.Time, bool) (int64, error) {
+		return func(_ context.Context, before time.Time, dryRun bool) (int64, error) {
+			*calls = append(*calls, fmt.Sprintf("%s %s dry=%t", name, time.Since(before).Round(time.Hour), dryRun))
+			if name == failing {
+				return 0, errors.New("primary stepped down")
+			}
+			return count, nil
+		}
+	}
+	return &mocks.RetentionRepository{
+		DeleteUsersFunc:            step("users"),
+		AnonymizeUsersFunc:         step("anonymize"),
+		DeleteLoginEventsFunc:      step("events"),
+		ClearLoginReportTokensFunc: step("tokens"),
+	}
+}
+
+func TestRetentionPurge(t *testing.T) {
+	day := 24 * time.Hour
+	tests := []struct {
+		name       string
+		policy     domain.RetentionPolicy
+		dryRun     bool
+		failing    string
+		wantCalls  []string
+		wantReport ports.RetentionReport
+		wantErr    bool
+	}{
+		{
+			name:       "windows unset",
+			policy:     domain.RetentionPolicy{DeletedUsersAction: domain.RetentionDelete},
+			wantCalls:  []string{"tokens 168h0m0s dry=false"},
+			wantReport: ports.RetentionReport{LoginReportTokens: 2},
+		},
+		{
+			name:       "delete",
+			policy:     domain.RetentionPolicy{DeletedUsers: 30 * day, DeletedUsersAction: domain.RetentionDelete, LoginEvents: 90 * day},
+			wantCalls:  []string{"users 720h0m0s dry=false", "events 2160h0m0s dry=false", "tokens 168h0m0s dry=false"},
+			wantReport: ports.RetentionReport{DeletedUsers: 2, LoginEvents: 2, LoginReportTokens: 2},
+		},
+		{
+			name:       "anonymize",
+			policy:     domain.RetentionPolicy{DeletedUsers: 30 * day, DeletedUsersAction: domain.RetentionAnonymize},
+			wantCalls:  []string{"anonymize 720h0m0s dry=false", "tokens 168h0m0s dry=false"},
+			wantReport: ports.RetentionReport{AnonymizedUsers: 2, LoginReportTokens: 2},
+		},
+		{
+			name:       "dry run",
+			policy:     domain.RetentionPolicy{DeletedUsers: 30 * day, DeletedUsersAction: domain.RetentionDelete, LoginEvents: 90 * day},
+			dryRun:     true,
+			wantCalls:  []string{"users 720h0m0s dry=true", "events 2160h0m0s dry=true", "tokens 168h0m0s dry=true"},
+			wantReport: ports.RetentionReport{DryRun: true, DeletedUsers: 2, LoginEvents: 2, LoginReportTokens: 2},
+		},
+		{
+			name:       "stops at the failing step",
+			policy:     domain.RetentionPolicy{DeletedUsers: 30 * day, DeletedUsersAction: domain.RetentionDelete, LoginEvents: 90 * day},
+			failing:    "events",
+			wantCalls:  []string{"users 720h0m0s dry=false", "events 2160h0m0s dry=false"},
+			wantReport: ports.RetentionReport{DeletedUsers: 2},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			u := NewRetentionUseCase(retentionStore(&calls, 2, tt.failing), tt.policy)
+
+			report, err := u.Purge(context.Background(), tt.dryRun)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Purge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if report.FinishedAt.Before(report.StartedAt) {
+				t.Errorf("FinishedAt = %v, before StartedAt %v", report.FinishedAt, report.StartedAt)
+			}
+			counts := *report
+			counts.StartedAt, counts.FinishedAt = time.Time{}, time.Time{}
+			if counts != tt.wantReport {
+				t.Errorf("Purge() = %+v, want %+v", counts, tt.wantReport)
+			}
+		})
+	}
+}
+
+func TestRetentionRunJob(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload map[string]string
+		want    string
+	}{
+		{name: "purge", want: "tokens 168h0m0s dry=false"},
+		{name: "dry run", payload: map[string]string{"dry_run": "true"}, want: "tokens 168h0m0s dry=true"},
+		{name: "invalid dry run", payload: map[string]string{"dry_run": "maybe"}, want: "tokens 168h0m0s dry=false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			u := NewRetentionUseCase(retentionStore(&calls, 0, ""), domain.RetentionPolicy{DeletedUsersAction: domain.RetentionDelete})
+
+			if err := u.RunJob(context.Background(), &domain.Job{Payload: tt.payload}); err != nil {
+				t.Fatalf("RunJob() error = %v", err)
+			}
+			if !reflect.DeepEqual(calls, []string{tt.want}) {
+				t.Errorf("calls = %v, want [%s]", calls, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.RoleUseCase = (*RoleUseCase)(nil)
+
+var (
+	ErrRoleNotFound   = errors.New("role not found")
+	ErrRoleExists     = errors.New("role name is already in use")
+	ErrSystemRole     = errors.New("system roles cannot be modified or deleted")
+	ErrRoleNotHeld    = errors.New("role not found on user")
+	ErrLastRoleOfUser = errors.New("invalid role removal: users must keep at least one role")
+)
+
+type RoleUseCase struct {
+	roles ports.RoleRepository
+	users ports.UserRepository
+}
+
+func NewRoleUseCase(roleRepo ports.RoleRepository, userRepo ports.UserRepository) ports.RoleUseCase {
+	return &RoleUseCase{
+		roles: roleRepo,
+		users: userRepo,
+	}
+}
+
+// ListRoles returns the built-in system roles followed by the tenant custom roles
+func (u *RoleUseCase) ListRoles(ctx context.Context) ([]*domain.Role, error) {
+	custom, err := u.roles.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(domain.SystemRoles(), custom...), nil
+}
+
+func (u *RoleUseCase) GetRole(ctx context.Context, name string) (*domain.Role, error) {
+	if role := domain.SystemRole(name); role != nil {
+		return role, nil
+	}
+	role, err := u.roles.GetRoleByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, ErrRoleNotFound
+	}
+	return role, nil
+}
+
+func (u *RoleUseCase) CreateRole(ctx context.Context, name, description string, permissions []string) (*domain.Role, error) {
+	role, err := domain.NewRole(name, description, permissions)
+	if err != nil {
+		return nil, err
+	}
+	if domain.SystemRole(role.Name) != nil {
+		return nil, ErrRoleExists
+	}
+	if existing, _ := u.roles.GetRoleByName(ctx, role.Name); existing != nil {
+		return nil, ErrRoleExists
+	}
+	if err := u.roles.CreateRole(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// UpdateRole changes the description and/or the permissions of a custom role; nil values are left untouched
+func (u *RoleUseCase) UpdateRole(ctx context.Context, name string, description *string, permissions []string) (*domain.Role, error) {
+	if domain.SystemRole(name) != nil {
+		return nil, ErrSystemRole
+	}
+	role, err := u.GetRole(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if description != nil {
+		role.Description = strings.TrimSpace(*description)
+	}
+	if permissions != nil {
+		normalized, err := domain.NormalizePermissions(permissions)
+		if err != nil {
+			return nil, err
+		}
+		role.Permissions = normalized
+	}
+	if err := u.roles.UpdateRole(ctx, role); err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+// DeleteRole deletes a custom role and unassigns it from every user
+func (u *RoleUseCase) DeleteRole(ctx context.Context, name string) error {
+	if domain.SystemRole(name) != nil {
+		return ErrSystemRole
+	}
+	if _, err := u.GetRole(ctx, name); err != nil {
+		return err
+	}
+	if err := u.users.RemoveRoleFromAllUsers(ctx, name); err != nil {
+		return err
+	}
+	return u.roles.DeleteRole(ctx, name)
+}
+
+func (u *RoleUseCase) AssignRole(ctx context.Context, userID, role string) ([]string, error) {
+	if _, err := u.GetRole(ctx, role); err != nil {
+		return nil, err
+	}
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	roles := user.EffectiveRoles()
+	if slices.Contains(roles, role) {
+		return roles, nil
+	}
+	roles = append(slices.Clone(roles), role)
+	if err := u.users.SetRoles(ctx, userID, roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (u *RoleUseCase) UnassignRole(ctx context.Context, userID, role string) ([]string, error) {
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	roles := user.EffectiveRoles()
+	if !slices.Contains(roles, role) {
+		return nil, ErrRoleNotHeld
+	}
+	roles = slices.DeleteFunc(slices.Clone(roles), func(r string) bool { return r == role })
+	if len(roles) == 0 {
+		return nil, ErrLastRoleOfUser
+	}
+	if err := u.users.SetRoles(ctx, userID, roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+// HasPermission reports whether any of the roles grants the permission.
+// Permissions are resolved on every call so role changes apply to already issued tokens.
+func (u *RoleUseCase) HasPermission(ctx context.Context, roles []string, permission string) (bool, error) {
+	var custom []string
+	for _, name := range roles {
+		if role := domain.SystemRole(name); role != nil {
+			if role.Grants(permission) {
+				return true, nil
+			}
+			continue
+		}
+		custom = append(custom, name)
+	}
+	if len(custom) == 0 {
+		return false, nil
+	}
+
+	stored, err := u.roles.GetRolesByNames(ctx, custom)
+	if err != nil {
+		return false, err
+	}
+	for _, role := range stored {
+		if role.Grants(permission) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CoversRoles resolves the permissions of others, unknown roles granting none, and checks that roles
+// grant each of them, "*" only being covered by roles granting "*" too
+func (u *RoleUseCase) CoversRoles(ctx context.Context, roles, others []string) (bool, error) {
+	var permissions, custom []string
+	for _, name := range others {
+		if role := domain.SystemRole(name); role != nil {
+			permissions = append(permissions, role.Permissions...)
+			continue
+		}
+		custom = append(custom, name)
+	}
+	if len(custom) > 0 {
+		stored, err := u.roles.GetRolesByNames(ctx, custom)
+		if err != nil {
+			return false, err
+		}
+		for _, role := range stored {
+			permissions = append(permissions, role.Permissions...)
+		}
+	}
+
+	slices.Sort(permissions)
+	for _, permission := range slices.Compact(permissions) {
+		granted, err := u.HasPermission(ctx, roles, permission)
+		if err != nil || !granted {
+			return false, err
+		}
+	}
+	return true, nil
+}
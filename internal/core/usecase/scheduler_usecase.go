@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/cron"
+)
+
+var _ ports.SchedulerReporter = (*Scheduler)(nil)
+
+// ScheduledTask is run by the scheduler at the times of its schedule; at is the scheduled time,
+// the same on every instance whatever the jitter
+type ScheduledTask func(ctx context.Context, at time.Time) error
+
+// Scheduler runs periodic tasks at the times of their cron schedules, each after a random delay of
+// up to the jitter so instances started together do not hit the database at once. A run still
+// going when the task is due again makes the scheduler skip that time.
+type Scheduler struct {
+	jitter time.Duration
+	tasks  []*scheduledTask
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type scheduledTask struct {
+	name     string
+	schedule *cron.Schedule
+	run      ScheduledTask
+
+	mu    sync.Mutex
+	stats ports.ScheduledTaskStats
+}
+
+func NewScheduler(jitter time.Duration) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		jitter: jitter,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Add schedules the task; tasks are added before Start
+func (s *Scheduler) Add(name string, schedule *cron.Schedule, run ScheduledTask) {
+	s.tasks = append(s.tasks, &scheduledTask{
+		name:     name,
+		schedule: schedule,
+		run:      run,
+		stats:    ports.ScheduledTaskStats{Name: name, Schedule: schedule.String()},
+	})
+}
+
+// Start runs every task in the background until Stop
+func (s *Scheduler) Start() {
+	for _, task := range s.tasks {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(task)
+		}()
+	}
+}
+
+// Stop cancels the running tasks and waits for them to finish
+func (s *Scheduler) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *Scheduler) ScheduledTasks() []ports.ScheduledTaskStats {
+	stats := make([]ports.ScheduledTaskStats, 0, len(s.tasks))
+	for _, task := range s.tasks {
+		task.mu.Lock()
+		stats = append(stats, task.stats)
+		task.mu.Unlock()
+	}
+	return stats
+}
+
+func (s *Scheduler) loop(task *scheduledTask) {
+	next := task.schedule.Next(time.Now())
+	for !next.IsZero() {
+		task.setNext(next)
+		var jitter time.Duration
+		if s.jitter > 0 {
+			jitter = rand.N(s.jitter)
+		}
+		timer := time.NewTimer(time.Until(next) + jitter)
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		s.runTask(task, next)
+		// Times missed while the task ran are skipped
+		now := time.Now()
+		if now.Before(next) {
+			now = next
+		}
+		next = task.schedule.Next(now)
+	}
+	log.Printf("Scheduled task %s never runs again", task.name)
+}
+
+func (s *Scheduler) runTask(task *scheduledTask, at time.Time) {
+	started := time.Now()
+	task.mu.Lock()
+	task.stats.Running = true
+	task.mu.Unlock()
+
+	err := task.run(s.ctx, at)
+
+	task.mu.Lock()
+	defer task.mu.Unlock()
+	task.stats.Running = false
+	task.stats.LastRunAt = &started
+	task.stats.LastMS = time.Since(started).Milliseconds()
+	task.stats.Runs++
+	task.stats.LastOutcome = ports.TaskSucceeded
+	task.stats.LastError = ""
+	if err != nil {
+		log.Printf("Scheduled task %s failed: %v", task.name, err)
+		task.stats.LastOutcome = ports.TaskFailed
+		task.stats.LastError = err.Error()
+		task.stats.Failures++
+	}
+}
+
+func (t *scheduledTask) setNext(next time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats.NextRunAt = &next
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.SecurityEventUseCase = (*SecurityEventUseCase)(nil)
+
+// SecurityEventUseCase lists the security events of a user, merging the audit events targeting
+// them with their logins from new devices
+type SecurityEventUseCase struct {
+	audit  ports.AuditRepository
+	logins ports.LoginEventRepository
+}
+
+func NewSecurityEventUseCase(auditRepo ports.AuditRepository, loginRepo ports.LoginEventRepository) *SecurityEventUseCase {
+	return &SecurityEventUseCase{
+		audit:  auditRepo,
+		logins: loginRepo,
+	}
+}
+
+// ListSecurityEvents pages through the events with a time cursor rather than page numbers, as the
+// events come from two collections. A zero before starts from the latest event.
+func (u *SecurityEventUseCase) ListSecurityEvents(ctx context.Context, userID string, before time.Time, limit int) (*ports.SecurityEventFeed, error) {
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if before.IsZero() {
+		before = time.Now()
+	}
+
+	audited, err := u.audit.ListAuditEvents(ctx, &ports.AuditQuery{
+		Actions:  domain.SecurityAuditActions,
+		TargetID: userID,
+		Before:   before,
+		Page:     1,
+		PageSize: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	// One more login than needed tells whether there is a next page
+	logins, err := u.logins.ListNewDeviceLogins(ctx, userID, before, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]domain.SecurityEvent, 0, len(audited.Events)+len(logins))
+	for _, event := range audited.Events {
+		events = append(events, domain.NewAuditSecurityEvent(event, userID))
+	}
+	for _, event := range logins {
+		events = append(events, domain.NewLoginSecurityEvent(event))
+	}
+	slices.SortStableFunc(events, func(a, b domain.SecurityEvent) int {
+		return b.OccurredAt.Compare(a.OccurredAt)
+	})
+
+	feed := &ports.SecurityEventFeed{Events: events}
+	if len(events) > limit || audited.TotalCount > int64(len(audited.Events)) {
+		feed.Events = events[:min(limit, len(events))]
+		next := feed.Events[len(feed.Events)-1].OccurredAt
+		feed.NextBefore = &next
+	}
+	return feed, nil
+}
@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Statuses of the checks of a self-check
+const (
+	SelfCheckPass = "pass"
+	SelfCheckWarn = "warn"
+	SelfCheckFail = "fail"
+)
+
+// SelfCheckFunc checks the configuration or the state of the database, returning a detail of what it
+// checked or why the check failed
+type SelfCheckFunc func(ctx context.Context) (string, error)
+
+// SelfCheck validates an instance before it starts: failing critical checks fail the self-check,
+// failing other checks only warn. Checks run one after the other, in the order they were added.
+type SelfCheck struct {
+	checks []selfCheck
+}
+
+type selfCheck struct {
+	name     string
+	critical bool
+	run      SelfCheckFunc
+}
+
+// SelfCheckResult is the outcome of a check of a self-check
+type SelfCheckResult struct {
+	Name   string
+	Status string
+	Detail string
+}
+
+// SelfCheckReport is the outcome of every check of a self-check
+type SelfCheckReport struct {
+	Results []SelfCheckResult
+}
+
+func NewSelfCheck() *SelfCheck {
+	return &SelfCheck{}
+}
+
+// Add adds the check; the instance should not start when a critical check fails
+func (s *SelfCheck) Add(name string, critical bool, run SelfCheckFunc) {
+	s.checks = append(s.checks, selfCheck{name: name, critical: critical, run: run})
+}
+
+// Run runs every check, even after a failure, so the report lists all that is wrong at once
+func (s *SelfCheck) Run(ctx context.Context) *SelfCheckReport {
+	report := &SelfCheckReport{Results: make([]SelfCheckResult, 0, len(s.checks))}
+	for _, check := range s.checks {
+		result := SelfCheckResult{Name: check.name, Status: SelfCheckPass}
+		detail, err := check.run(ctx)
+		switch {
+		case err != nil && check.critical:
+			result.Status, detail = SelfCheckFail, err.Error()
+		case err != nil:
+			result.Status, detail = SelfCheckWarn, err.Error()
+		}
+		result.Detail = detail
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+// Failed reports whether a critical check failed
+func (r *SelfCheckReport) Failed() bool {
+	for _, result := range r.Results {
+		if result.Status == SelfCheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns the report as aligned lines, one per check
+func (r *SelfCheckReport) String() string {
+	width := 0
+	for _, result := range r.Results {
+		width = max(width, len(result.Name))
+	}
+	var b strings.Builder
+	for _, result := range r.Results {
+		line := fmt.Sprintf("  %-4s  %-*s  %s", strings.ToUpper(result.Status), width, result.Name, result.Detail)
+		b.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return b.String()
+}
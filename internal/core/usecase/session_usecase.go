@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.SessionUseCase = (*SessionUseCase)(nil)
+
+// SessionUseCase keeps the cookie sessions of first-party web apps, which last ttl from the login
+type SessionUseCase struct {
+	sessions ports.SessionRepository
+	ttl      time.Duration
+}
+
+func NewSessionUseCase(sessionRepo ports.SessionRepository, ttl time.Duration) *SessionUseCase {
+	return &SessionUseCase{
+		sessions: sessionRepo,
+		ttl:      ttl,
+	}
+}
+
+func (u *SessionUseCase) CreateSession(ctx context.Context, user *domain.User, scopes []string, ip, userAgent string) (*domain.Session, string, string, error) {
+	session, token, csrfToken, err := domain.NewSession(user, scopes, ip, userAgent, u.ttl)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if err := u.sessions.CreateSession(ctx, session); err != nil {
+		return nil, "", "", err
+	}
+	return session, token, csrfToken, nil
+}
+
+// Authenticate also refuses the expired sessions the TTL index of the store did not remove yet
+func (u *SessionUseCase) Authenticate(ctx context.Context, token string) (*domain.Session, error) {
+	session, err := u.sessions.GetSession(ctx, domain.HashSessionToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.Expired(time.Now()) {
+		return nil, domain.ErrSessionNotFound
+	}
+	return session, nil
+}
+
+func (u *SessionUseCase) Reauthenticated(ctx context.Context, session *domain.Session) error {
+	now := time.Now()
+	if err := u.sessions.SetSessionAuthTime(ctx, session.ID, now); err != nil {
+		return err
+	}
+	session.AuthTime = now
+	return nil
+}
+
+func (u *SessionUseCase) RotateCSRFToken(ctx context.Context, session *domain.Session) (string, error) {
+	csrfToken, err := session.RotateCSRFToken()
+	if err != nil {
+		return "", err
+	}
+	if err := u.sessions.SetSessionCSRFTokenHash(ctx, session.ID, session.CSRFTokenHash); err != nil {
+		return "", err
+	}
+	return csrfToken, nil
+}
+
+func (u *SessionUseCase) EndSession(ctx context.Context, session *domain.Session) error {
+	return u.sessions.DeleteSession(ctx, session.ID)
+}
@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"crypto"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+)
+
+// Compile-time interface check
+var _ ports.SigningKeyUseCase = (*SigningKeyUseCase)(nil)
+
+var ErrSigningKeysDisabled = errors.New("signing key management is not enabled for this tenant")
+
+// SigningKeyPolicy configures the rotation of the signing keys
+type SigningKeyPolicy struct {
+	// Algorithm of the new keys, EdDSA or RS256
+	Algorithm string
+	// RefreshInterval is how often every instance reloads the keys from the database
+	RefreshInterval time.Duration
+	// PublishDelay is how long a new key is published before it signs tokens, so every instance and
+	// every client caching the key set knows it first; at least RefreshInterval
+	PublishDelay time.Duration
+	// GracePeriod is how long replaced keys keep verifying tokens once the new key signs; at least
+	// the lifetime of the tokens
+	GracePeriod time.Duration
+}
+
+// SigningKeyUseCase keeps the keys signing the access tokens in the database, encrypted, and loads
+// them into the key ring of every instance. Rotating adds a key that signs once published long
+// enough and retires the previous keys after the grace period.
+type SigningKeyUseCase struct {
+	keys   ports.SigningKeyRepository
+	cipher *security.KeyCipher
+	ring   ports.KeyRing
+	audit  ports.AuditUseCase
+	policy SigningKeyPolicy
+	// tenantID is the tenant whose admins manage the keys of every tenant
+	tenantID string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSigningKeyUseCase(keyRepo ports.SigningKeyRepository, cipher *security.KeyCipher, ring ports.KeyRing, auditUC ports.AuditUseCase, policy SigningKeyPolicy, tenantID string) *SigningKeyUseCase {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &SigningKeyUseCase{
+		keys:     keyRepo,
+		cipher:   cipher,
+		ring:     ring,
+		audit:    auditUC,
+		policy:   policy,
+		tenantID: tenantID,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Load loads the keys into the key ring, creating a key signing at once on a new database or when
+// every key expired, as no token nor client is waiting for it
+func (u *SigningKeyUseCase) Load(ctx context.Context) error {
+	keys, err := u.keys.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	if !slices.ContainsFunc(keys, func(key *domain.SigningKey) bool { return !key.ActivatesAt.After(now) && !key.Expired(now) }) {
+		key, err := u.create(ctx, now)
+		if err != nil {
+			return err
+		}
+		log.Printf("Created token signing key %s", key.ID)
+	}
+	return u.refresh(ctx)
+}
+
+// Start reloads the keys every RefreshInterval in the background, picking up the keys rotated on
+// other instances and switching to pending keys once they activate
+func (u *SigningKeyUseCase) Start() {
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		ticker := time.NewTicker(u.policy.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				// The current keys are kept while the database is unreachable
+				if err := u.refresh(u.ctx); err != nil {
+					log.Printf("Error reloading the token signing keys: %v", err)
+				}
+			case <-u.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops reloading the keys
+func (u *SigningKeyUseCase) Stop() {
+	u.cancel()
+	u.wg.Wait()
+}
+
+func (u *SigningKeyUseCase) ListSigningKeys(ctx context.Context) ([]*domain.SigningKey, error) {
+	if domain.TenantFromContext(ctx) != u.tenantID {
+		return nil, ErrSigningKeysDisabled
+	}
+	keys, err := u.keys.ListSigningKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys are listed by activation time, the last activated one signs
+	now := time.Now()
+	listed := make([]*domain.SigningKey, 0, len(keys))
+	var active *domain.SigningKey
+	for _, key := range keys {
+		if key.Expired(now) {
+			continue
+		}
+		if key.ActivatesAt.After(now) {
+			key.Status = domain.SigningKeyPending
+		} else {
+			if active != nil {
+				active.Status = domain.SigningKeyRetired
+			}
+			key.Status = domain.SigningKeyActive
+			active = key
+		}
+		listed = append(listed, key)
+	}
+	return listed, nil
+}
+
+func (u *SigningKeyUseCase) RotateSigningKey(ctx context.Context, actorID string) (*domain.SigningKey, error) {
+	if domain.TenantFromContext(ctx) != u.tenantID {
+		return nil, ErrSigningKeysDisabled
+	}
+	key, err := u.create(ctx, time.Now().Add(u.policy.PublishDelay))
+	if err != nil {
+		return nil, err
+	}
+	// The previous keys verify the tokens they signed until the new key activates, and the grace period after
+	if err := u.keys.ExpireSigningKeys(ctx, key.ID, key.ActivatesAt.Add(u.policy.GracePeriod)); err != nil {
+		return nil, err
+	}
+	key.Status = domain.SigningKeyPending
+
+	details := map[string]string{"kid": key.ID, "algorithm": key.Algorithm, "activates_at": key.ActivatesAt.Format(time.RFC3339)}
+	if err := u.audit.Record(ctx, domain.AuditActionSigningKeyRotated, actorID, "", details); err != nil {
+		log.Printf("Error recording rotation of signing key %s: %v", key.ID, err)
+	}
+	// Other instances publish the key on their next reload, well before it activates
+	if u.ring != nil {
+		if err := u.refresh(ctx); err != nil {
+			log.Printf("Error reloading the token signing keys: %v", err)
+		}
+	}
+	return key, nil
+}
+
+// create generates and stores a key signing from activatesAt
+func (u *SigningKeyUseCase) create(ctx context.Context, activatesAt time.Time) (*domain.SigningKey, error) {
+	signer, err := security.GenerateSigningKey(u.policy.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	kid, err := security.SigningKeyID(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := security.MarshalPrivateKeyPEM(signer)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := u.cipher.Seal(encoded, kid)
+	if err != nil {
+		return nil, err
+	}
+	key := &domain.SigningKey{
+		ID:          kid,
+		Algorithm:   u.policy.Algorithm,
+		PrivateKey:  sealed,
+		CreatedAt:   time.Now(),
+		ActivatesAt: activatesAt,
+	}
+	if err := u.keys.CreateSigningKey(ctx, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// refresh loads the stored keys into the key ring: the last activated key signs, every key verifies
+func (u *SigningKeyUseCase) refresh(ctx context.Context) error {
+	keys, err := u.keys.ListSigningKeys(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var signer crypto.Signer
+	var verifying []crypto.PublicKey
+	for _, key := range keys {
+		if key.Expired(now) {
+			continue
+		}
+		plaintext, err := u.cipher.Open(key.PrivateKey, key.ID)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", key.ID, err)
+		}
+		private, err := security.ParsePrivateKeyPEM(plaintext)
+		if err != nil {
+			return fmt.Errorf("signing key %s: %w", key.ID, err)
+		}
+		verifying = append(verifying, private.Public())
+		if !key.ActivatesAt.After(now) {
+			signer = private
+		}
+	}
+	if signer == nil {
+		return errors.New("no active signing key, the keys expired or are all pending")
+	}
+	return u.ring.SetSigningKeys(signer, verifying)
+}
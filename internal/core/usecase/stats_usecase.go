@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.StatsUseCase = (*StatsUseCase)(nil)
+
+// StatsUseCase rolls up the daily user statistics of every tenant in jobs, see RunJob, and lists
+// those of a tenant and its registrations over time
+type StatsUseCase struct {
+	stats ports.StatsRepository
+}
+
+func NewStatsUseCase(stats ports.StatsRepository) *StatsUseCase {
+	return &StatsUseCase{
+		stats: stats,
+	}
+}
+
+// RunJob is the handler of the stats rollup jobs, rolling up the day of the day payload or else the
+// day before the job was created
+func (u *StatsUseCase) RunJob(ctx context.Context, job *domain.Job) error {
+	day := job.CreatedAt.UTC().Truncate(24*time.Hour).AddDate(0, 0, -1)
+	if value := job.Payload["day"]; value != "" {
+		parsed, err := time.Parse(domain.StatsDayLayout, value)
+		if err != nil {
+			return fmt.Errorf("invalid day %q: %w", value, err)
+		}
+		day = parsed
+	}
+
+	tenants, err := u.stats.RollUpUserStats(ctx, day)
+	if err != nil {
+		return err
+	}
+	log.Printf("User statistics of %s rolled up for %d tenants", day.Format(domain.StatsDayLayout), tenants)
+	return nil
+}
+
+func (u *StatsUseCase) ListUserStats(ctx context.Context, days int) ([]*domain.UserStats, error) {
+	if days < 1 || days > domain.MaxStatsDays {
+		return nil, domain.ErrInvalidStatsDays
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return u.stats.ListUserStats(ctx, today.AddDate(0, 0, -days))
+}
+
+func (u *StatsUseCase) CountRegistrations(ctx context.Context, query *ports.RegistrationsQuery) (*ports.RegistrationsReport, error) {
+	interval := query.Interval
+	if interval == "" {
+		interval = domain.AnalyticsIntervalDay
+	}
+	if !slices.Contains(domain.AnalyticsIntervals, interval) {
+		return nil, domain.ErrInvalidAnalyticsInterval
+	}
+	to := query.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+	from := query.From
+	if from.IsZero() {
+		switch interval {
+		case domain.AnalyticsIntervalWeek:
+			from = to.AddDate(0, 0, -12*7)
+		case domain.AnalyticsIntervalMonth:
+			from = to.AddDate(0, -12, 0)
+		default:
+			from = to.AddDate(0, 0, -30)
+		}
+	}
+	if !from.Before(to) {
+		return nil, domain.ErrInvalidAnalyticsRange
+	}
+
+	// The first bucket is whole, so its count compares with the others
+	from = domain.AnalyticsBucketStart(from, interval)
+	starts := []time.Time{}
+	for start := from; start.Before(to); start = domain.NextAnalyticsBucket(start, interval) {
+		if len(starts) == domain.MaxAnalyticsBuckets {
+			return nil, domain.ErrInvalidAnalyticsRange
+		}
+		starts = append(starts, start)
+	}
+
+	counted, err := u.stats.CountRegistrations(ctx, &ports.RegistrationsQuery{Interval: interval, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[time.Time]int64, len(counted))
+	for _, bucket := range counted {
+		counts[bucket.Start.UTC()] = bucket.Count
+	}
+	report := &ports.RegistrationsReport{Interval: interval, From: from, To: to.UTC(), Buckets: make([]ports.RegistrationBucket, 0, len(starts))}
+	for _, start := range starts {
+		report.Buckets = append(report.Buckets, ports.RegistrationBucket{Start: start, Count: counts[start]})
+		report.Total += counts[start]
+	}
+	return report, nil
+}
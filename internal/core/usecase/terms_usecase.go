@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"slices"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.TermsUseCase = (*TermsUseCase)(nil)
+
+type TermsUseCase struct {
+	terms ports.TermsRepository
+	audit ports.AuditUseCase
+}
+
+func NewTermsUseCase(termsRepo ports.TermsRepository, auditUC ports.AuditUseCase) ports.TermsUseCase {
+	return &TermsUseCase{
+		terms: termsRepo,
+		audit: auditUC,
+	}
+}
+
+// PublishVersion makes the version current, so users must accept it before using the API again
+func (u *TermsUseCase) PublishVersion(ctx context.Context, actorID, document, version, url string) (*domain.TermsVersion, error) {
+	published, err := domain.NewTermsVersion(document, version, url, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.terms.CreateVersion(ctx, published); err != nil {
+		return nil, err
+	}
+
+	details := map[string]string{"document": published.Document, "version": published.Version}
+	if err := u.audit.Record(ctx, domain.AuditActionTermsPublished, actorID, published.ID, details); err != nil {
+		log.Printf("Error recording publication of %s version %s: %v", published.Document, published.Version, err)
+	}
+	return published, nil
+}
+
+func (u *TermsUseCase) ListVersions(ctx context.Context, document string) ([]*domain.TermsVersion, error) {
+	if document != "" && !slices.Contains(domain.TermsDocuments, document) {
+		return nil, domain.ErrInvalidTermsDocument
+	}
+	return u.terms.ListVersions(ctx, document)
+}
+
+func (u *TermsUseCase) CurrentVersions(ctx context.Context) ([]*domain.TermsVersion, error) {
+	return u.terms.CurrentVersions(ctx)
+}
+
+// PendingVersions is called on every authenticated request, so the acceptances are only read
+// once something was published
+func (u *TermsUseCase) PendingVersions(ctx context.Context, userID string) ([]*domain.TermsVersion, error) {
+	current, err := u.terms.CurrentVersions(ctx)
+	if err != nil || len(current) == 0 {
+		return nil, err
+	}
+	accepted, err := u.terms.ListAcceptances(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return domain.PendingTerms(current, accepted), nil
+}
+
+func (u *TermsUseCase) GetStatus(ctx context.Context, userID string) (*domain.TermsStatus, error) {
+	current, err := u.terms.CurrentVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accepted, err := u.terms.ListAcceptances(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.TermsStatus{Pending: domain.PendingTerms(current, accepted), Accepted: accepted}, nil
+}
+
+// AcceptVersions fails without accepting anything when one of the versions is not current, so
+// users do not accept a version published after the one they were shown. Versions accepted
+// before are not recorded again.
+func (u *TermsUseCase) AcceptVersions(ctx context.Context, userID string, versions map[string]string) (*domain.TermsStatus, error) {
+	current, err := u.terms.CurrentVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accepted, err := u.terms.ListAcceptances(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var accepting []*domain.TermsVersion
+	for document, version := range versions {
+		if !slices.Contains(domain.TermsDocuments, document) {
+			return nil, domain.ErrInvalidTermsDocument
+		}
+		i := slices.IndexFunc(current, func(v *domain.TermsVersion) bool { return v.Document == document })
+		if i < 0 || current[i].Version != version {
+			return nil, domain.ErrTermsVersionNotCurrent
+		}
+		if !current[i].AcceptedIn(accepted) {
+			accepting = append(accepting, current[i])
+		}
+	}
+
+	for _, version := range accepting {
+		acceptance := domain.NewTermsAcceptance(userID, version)
+		if err := u.terms.CreateAcceptance(ctx, acceptance); err != nil {
+			return nil, err
+		}
+		accepted = append([]*domain.TermsAcceptance{acceptance}, accepted...)
+
+		details := map[string]string{"document": version.Document, "version": version.Version}
+		if err := u.audit.Record(ctx, domain.AuditActionTermsAccepted, userID, userID, details); err != nil {
+			log.Printf("Error recording acceptance of %s version %s by user %s: %v", version.Document, version.Version, userID, err)
+		}
+	}
+	return &domain.TermsStatus{Pending: domain.PendingTerms(current, accepted), Accepted: accepted}, nil
+}
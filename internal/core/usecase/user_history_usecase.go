@@ -0,0 +1,93 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log"
+	"reflect"
+	"strconv"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.UserHistoryUseCase = (*UserHistoryUseCase)(nil)
+
+var (
+	ErrUserVersionNotFound = errors.New("user version not found")
+	ErrInvalidUserVersion  = errors.New("invalid version: must be a positive number")
+)
+
+// UserHistoryUseCase lists the change history of the users, recorded by the user repository on
+// every write, and reverts users to their previous versions
+type UserHistoryUseCase struct {
+	history ports.UserHistoryRepository
+	users   ports.UserRepository
+	audit   ports.AuditUseCase
+}
+
+func NewUserHistoryUseCase(historyRepo ports.UserHistoryRepository, userRepo ports.UserRepository, auditUC ports.AuditUseCase) ports.UserHistoryUseCase {
+	return &UserHistoryUseCase{
+		history: historyRepo,
+		users:   userRepo,
+		audit:   auditUC,
+	}
+}
+
+func (u *UserHistoryUseCase) ListHistory(ctx context.Context, query *ports.UserHistoryQuery) (*ports.UserHistoryResult, error) {
+	exists, err := u.users.UserExists(ctx, query.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	return u.history.ListVersions(ctx, query)
+}
+
+// RevertUser writes the profile of the version over the current one, then its addresses if they
+// differ, leaving the rest of the user as it is now; the user repository records each write as a
+// new version. As on a profile update, a verified phone does not carry over to another phone.
+func (u *UserHistoryUseCase) RevertUser(ctx context.Context, actorID, userID string, version int) (*domain.User, error) {
+	if version < 1 {
+		return nil, ErrInvalidUserVersion
+	}
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	previous, err := u.history.GetVersion(ctx, userID, version)
+	if err != nil {
+		return nil, err
+	}
+	if previous == nil {
+		return nil, ErrUserVersionNotFound
+	}
+
+	phone, addresses := user.Profile.Phone, user.Profile.Addresses
+	user.Profile = previous.Profile
+	if err := u.users.SetProfile(ctx, userID, user.Profile); err != nil {
+		return nil, err
+	}
+	if !reflect.DeepEqual(user.Profile.Addresses, addresses) {
+		if err := u.users.SetAddresses(ctx, userID, user.Profile.Addresses); err != nil {
+			return nil, err
+		}
+	}
+	if user.Profile.Phone != phone && (user.PhoneVerifiedAt != nil || user.PhoneChallenge != nil) {
+		if _, err := u.users.SetPhoneVerified(ctx, userID, user.Profile.Phone, nil); err != nil {
+			return nil, err
+		}
+		user.PhoneVerifiedAt, user.PhoneChallenge = nil, nil
+	}
+
+	details := map[string]string{"version": strconv.Itoa(version)}
+	if err := u.audit.Record(ctx, domain.AuditActionUserReverted, actorID, userID, details); err != nil {
+		log.Printf("Error recording revert of user %s: %v", userID, err)
+	}
+	return user, nil
+}
@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+)
+
+func TestRevertUser(t *testing.T) {
+	home := []domain.Address{{ID: "a1", Type: "home", Primary: true, City: "Lisbon"}}
+	work := []domain.Address{{ID: "a2", Type: "work", Primary: true, City: "Porto"}}
+	verifiedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		current   domain.Profile
+		previous  domain.Profile
+		wantCalls []string
+	}{
+		{
+			name:      "same addresses",
+			current:   domain.Profile{FirstName: "Ada", Phone: "+15551234567", Addresses: home},
+			previous:  domain.Profile{FirstName: "Augusta", Phone: "+15551234567", Addresses: home},
+			wantCalls: []string{"profile Augusta"},
+		},
+		{
+			name:      "other addresses",
+			current:   domain.Profile{FirstName: "Ada", Phone: "+15551234567", Addresses: home},
+			previous:  domain.Profile{FirstName: "Augusta", Phone: "+15551234567", Addresses: work},
+			wantCalls: []string{"profile Augusta", "addresses a2"},
+		},
+		{
+			name:      "other phone",
+			current:   domain.Profile{FirstName: "Ada", Phone: "+15551234567", Addresses: home},
+			previous:  domain.Profile{FirstName: "Ada", Phone: "+15557654321", Addresses: home},
+			wantCalls: []string{"profile Ada", "phone unverified +15557654321"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			users := &mocks.UserRepository{
+				GetUserByIDFunc: func(context.Context, string) (*domain.User, error) {
+					return &domain.User{ID: "u1", TokenGeneration: 3, PhoneVerifiedAt: &verifiedAt, Profile: tt.current}, nil
+				},
+				SetProfileFunc: func(_ context.Context, _ string, profile domain.Profile) error {
+					calls = append(calls, "profile "+profile.FirstName)
+					return nil
+				},
+				SetAddressesFunc: func(_ context.Context, _ string, addresses []domain.Address) error {
+					calls = append(calls, "addresses "+addresses[0].ID)
+					return nil
+				},
+				SetPhoneVerifiedFunc: func(_ context.Context, _, phone string, at *time.Time) (bool, error) {
+					if at == nil {
+						calls = append(calls, "phone unverified "+phone)
+					}
+					return true, nil
+				},
+				UpdateUserFunc: func(context.Context, *domain.User) error {
+					t.Error("UpdateUser() called, want only the profile written")
+					return nil
+				},
+			}
+			history := &mocks.UserHistoryRepository{
+				GetVersionFunc: func(context.Context, string, int) (*domain.UserVersion, error) {
+					return &domain.UserVersion{UserID: "u1", Version: 1, Profile: tt.previous}, nil
+				},
+			}
+			u := NewUserHistoryUseCase(history, users, &mocks.AuditUseCase{})
+
+			user, err := u.RevertUser(context.Background(), "admin", "u1", 1)
+			if err != nil {
+				t.Fatalf("RevertUser() error = %v", err)
+			}
+			if !reflect.DeepEqual(calls, tt.wantCalls) {
+				t.Errorf("calls = %v, want %v", calls, tt.wantCalls)
+			}
+			if !reflect.DeepEqual(user.Profile, tt.previous) || user.TokenGeneration != 3 {
+				t.Errorf("RevertUser() = %+v, want the current user with the profile %+v", user, tt.previous)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+var _ ports.HealthChecker = (*UserProjectionUseCase)(nil)
+
+// projectionRetryDelay is how long a projection waits before watching again once the change stream
+// or the projection failed
+const projectionRetryDelay = 10 * time.Second
+
+// UserProjectionUseCase keeps a projection of the users, such as the search index or the read model
+// of the lists, in sync with the users collection by applying the changes of the change stream in
+// the background: written users are saved, deleted users removed
+type UserProjectionUseCase struct {
+	name       string
+	changes    ports.UserChangeStream
+	projection ports.UserProjection
+
+	// mu guards the state of the watch reported by CheckHealth
+	mu       sync.Mutex
+	watching bool
+	lastErr  error
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewUserProjectionUseCase returns the sync of projection, named name in the logs
+func NewUserProjectionUseCase(name string, changes ports.UserChangeStream, projection ports.UserProjection) *UserProjectionUseCase {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &UserProjectionUseCase{
+		name:       name,
+		changes:    changes,
+		projection: projection,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start watches the changes until Stop, watching again after projectionRetryDelay when the stream
+// or the projection fails; the watch resumes after the last applied change
+func (u *UserProjectionUseCase) Start() {
+	u.wg.Add(1)
+	go func() {
+		defer u.wg.Done()
+		for {
+			u.setState(true, nil)
+			err := u.changes.Watch(u.ctx, u.apply)
+			u.setState(false, err)
+			if u.ctx.Err() != nil {
+				return
+			}
+			log.Printf("Error syncing the %s, retrying in %s: %v", u.name, projectionRetryDelay, err)
+			select {
+			case <-time.After(projectionRetryDelay):
+			case <-u.ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops watching the changes and waits for the change being applied
+func (u *UserProjectionUseCase) Stop() {
+	u.cancel()
+	u.wg.Wait()
+}
+
+// CheckHealth reports the sync as up while it watches the changes, and as down while it waits to
+// watch again after a failure
+func (u *UserProjectionUseCase) CheckHealth(ctx context.Context) ports.DependencyHealth {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	switch {
+	case u.watching:
+		return ports.DependencyHealth{Status: ports.HealthUp}
+	case u.lastErr != nil:
+		return ports.DependencyHealth{Status: ports.HealthDown, Error: u.lastErr.Error()}
+	default:
+		return ports.DependencyHealth{Status: ports.HealthDown, Error: "not watching the changes"}
+	}
+}
+
+func (u *UserProjectionUseCase) setState(watching bool, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.watching, u.lastErr = watching, err
+}
+
+func (u *UserProjectionUseCase) apply(ctx context.Context, change ports.UserChange) error {
+	if change.User == nil {
+		return u.projection.RemoveUser(ctx, change.UserID)
+	}
+	return u.projection.SaveUser(ctx, change.User)
+}
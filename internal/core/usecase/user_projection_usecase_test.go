@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+)
+
+func TestUserProjectionApply(t *testing.T) {
+	failure := errors.New("index unavailable")
+	tests := []struct {
+		name    string
+		change  ports.UserChange
+		err     error
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "written user",
+			change: ports.UserChange{UserID: "u1", User: &domain.User{ID: "u1"}},
+			want:   []string{"save u1"},
+		},
+		{
+			name:   "deleted user",
+			change: ports.UserChange{UserID: "u1"},
+			want:   []string{"remove u1"},
+		},
+		{
+			name:    "projection failure",
+			change:  ports.UserChange{UserID: "u1", User: &domain.User{ID: "u1"}},
+			err:     failure,
+			want:    []string{"save u1"},
+			wantErr: failure,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			projection := &mocks.UserProjection{
+				SaveUserFunc: func(_ context.Context, user *domain.User) error {
+					calls = append(calls, "save "+user.ID)
+					return tt.err
+				},
+				RemoveUserFunc: func(_ context.Context, id string) error {
+					calls = append(calls, "remove "+id)
+					return tt.err
+				},
+			}
+			changes := &mocks.UserChangeStream{
+				WatchFunc: func(ctx context.Context, handle func(context.Context, ports.UserChange) error) error {
+					return handle(ctx, tt.change)
+				},
+			}
+
+			u := NewUserProjectionUseCase("test projection", changes, projection)
+			if err := u.changes.Watch(context.Background(), u.apply); !errors.Is(err, tt.wantErr) {
+				t.Errorf("apply() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(calls, tt.want) {
+				t.Errorf("calls = %v, want %v", calls, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserProjectionHealth(t *testing.T) {
+	watching := make(chan struct{})
+	fail := make(chan struct{})
+	failed := make(chan struct{})
+	changes := &mocks.UserChangeStream{
+		WatchFunc: func(ctx context.Context, handle func(context.Context, ports.UserChange) error) error {
+			close(watching)
+			select {
+			case <-fail:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			defer close(failed)
+			return errors.New("change stream closed")
+		},
+	}
+	u := NewUserProjectionUseCase("test projection", changes, &mocks.UserProjection{})
+
+	want := ports.DependencyHealth{Status: ports.HealthDown, Error: "not watching the changes"}
+	if got := u.CheckHealth(context.Background()); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckHealth() before Start = %+v, want %+v", got, want)
+	}
+
+	u.Start()
+	defer u.Stop()
+	<-watching
+	want = ports.DependencyHealth{Status: ports.HealthUp}
+	if got := u.CheckHealth(context.Background()); !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckHealth() while watching = %+v, want %+v", got, want)
+	}
+
+	close(fail)
+	<-failed
+	want = ports.DependencyHealth{Status: ports.HealthDown, Error: "change stream closed"}
+	// The state is set right after Watch returned
+	got := u.CheckHealth(context.Background())
+	for got.Status == ports.HealthUp {
+		got = u.CheckHealth(context.Background())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckHealth() after a failure = %+v, want %+v", got, want)
+	}
+}
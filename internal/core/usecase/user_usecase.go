@@ -3,7 +3,15 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/frtasoniero/user-management-api/internal/core/domain"
 	"github.com/frtasoniero/user-management-api/internal/core/ports"
@@ -14,25 +22,82 @@ import (
 var _ ports.UserUseCase = (*UserUseCase)(nil)
 
 var (
-	ErrEmailTaken         = errors.New("email is already in use")
+	ErrEmailTaken         = domain.ErrEmailTaken
 	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrIncorrectPassword  = errors.New("invalid credentials: incorrect password")
+	// ErrPasswordResetRequired is only returned once the password was verified, so it reveals nothing to guessers
+	ErrPasswordResetRequired = errors.New("password reset required: this account was locked after a login was reported as suspicious")
+	// ErrAccountDeactivated is only returned once the password was verified, like ErrPasswordResetRequired
+	ErrAccountDeactivated = errors.New("account deactivated: it was disabled in or removed from its directory")
 	ErrUserNotFound       = errors.New("user not found")
 )
 
 type UserUseCase struct {
-	users ports.UserRepository
+	users          ports.UserRepository
+	metadataPolicy domain.MetadataPolicy
+	passwordPolicy domain.PasswordPolicy
+	agePolicy      domain.AgePolicy
+	geocoding      ports.AddressGeocoding
+	breaches       ports.PasswordBreachCheck
+	audit          ports.AuditUseCase
+	// upsertPolicy resolves the conflicts of the upserts not choosing a policy, see domain.ParseUpsertPolicy
+	upsertPolicy string
+	emailDomains ports.EmailDomainUseCase
+	customFields domain.CustomFieldSchema
 }
 
-func NewUserUseCase(userRepo ports.UserRepository) ports.UserUseCase {
+func NewUserUseCase(userRepo ports.UserRepository, metadataPolicy domain.MetadataPolicy, passwordPolicy domain.PasswordPolicy, agePolicy domain.AgePolicy, geocoding ports.AddressGeocoding, breaches ports.PasswordBreachCheck, audit ports.AuditUseCase, upsertPolicy string, emailDomains ports.EmailDomainUseCase, customFields domain.CustomFieldSchema) ports.UserUseCase {
 	return &UserUseCase{
-		users: userRepo,
+		users:          userRepo,
+		metadataPolicy: metadataPolicy,
+		passwordPolicy: passwordPolicy,
+		agePolicy:      agePolicy,
+		geocoding:      geocoding,
+		breaches:       breaches,
+		audit:          audit,
+		upsertPolicy:   upsertPolicy,
+		emailDomains:   emailDomains,
+		customFields:   customFields,
 	}
 }
 
-func (u *UserUseCase) Register(ctx context.Context, email, password string, profile domain.Profile) error {
-	if existing, _ := u.users.GetUserByEmail(ctx, email); existing != nil {
+// Register creates a user account; username is optional. Emails of a domain the email domain lists
+// refuse cannot register, and customFields are checked against the custom field schema.
+func (u *UserUseCase) Register(ctx context.Context, email, username, password string, profile domain.Profile, customFields map[string]string) error {
+	if evaluation := u.EvaluatePassword(ctx, password, email, username, profile.FirstName, profile.LastName); evaluation.Err != nil {
+		return &domain.FieldError{Field: "password", Err: evaluation.Err}
+	}
+	if err := u.agePolicy.Check(profile.Birthdate, time.Now()); err != nil {
+		return &domain.FieldError{Field: "profile.birthdate", Err: err}
+	}
+	customFields, err := u.customFields.Validate(customFields)
+	if err != nil {
+		return err
+	}
+	canonical, err := domain.CanonicalEmail(email)
+	if err != nil {
+		return err
+	}
+	if err := u.emailDomains.CheckEmail(ctx, canonical); err != nil {
+		return err
+	}
+	taken, err := u.emailTaken(ctx, email)
+	if err != nil {
+		return err
+	}
+	if taken {
 		return ErrEmailTaken
 	}
+	if username != "" {
+		normalized, err := domain.NormalizeUsername(username)
+		if err != nil {
+			return err
+		}
+		if existing, _ := u.users.GetUserByUsername(ctx, normalized); existing != nil {
+			return domain.ErrUsernameTaken
+		}
+		username = normalized
+	}
 	hash, err := security.HashPassword(password)
 	if err != nil {
 		return err
@@ -41,13 +106,132 @@ func (u *UserUseCase) Register(ctx context.Context, email, password string, prof
 	if err != nil {
 		return err
 	}
+	user.Username = username
+	user.CustomFields = customFields
+	for i := range user.Profile.Addresses {
+		if err := u.locate(ctx, &user.Profile.Addresses[i]); err != nil {
+			return err
+		}
+	}
 	if err := u.users.CreateUser(ctx, user); err != nil {
 		return err
 	}
 	return nil
 }
 
+func (u *UserUseCase) RegistrationFields() []domain.CustomField {
+	return u.customFields.Fields
+}
+
+// EvaluatePassword estimates the strength of password with the rules of the password policy, see
+// domain.PasswordPolicy.Evaluate, then looks passwords the policy accepts up in known data breaches.
+// The lookup fails open: passwords are evaluated as never breached when the checker errors or times out.
+func (u *UserUseCase) EvaluatePassword(ctx context.Context, password string, userInputs ...string) domain.PasswordEvaluation {
+	evaluation := u.passwordPolicy.Evaluate(password, userInputs...)
+	if evaluation.Err != nil || u.breaches.Checker == nil {
+		return evaluation
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, u.breaches.Timeout)
+	defer cancel()
+	breaches, err := u.breaches.Checker.Breaches(checkCtx, password)
+	if err != nil {
+		log.Printf("Error checking password breaches: %v", err)
+		return evaluation
+	}
+	evaluation.Breaches = breaches
+	if breaches > 0 && u.breaches.Reject {
+		evaluation.Err = domain.ErrPasswordBreached
+	}
+	return evaluation
+}
+
+func (u *UserUseCase) Authenticate(ctx context.Context, email, password string) (*domain.User, error) {
+	canonical, err := domain.CanonicalEmail(email)
+	if err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	user, err := u.users.GetUserByEmail(ctx, canonical)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := security.VerifyPassword(user.PasswordHash, password); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if user.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+	if user.DeactivatedAt != nil {
+		return nil, ErrAccountDeactivated
+	}
+
+	// Failing to track the login must not lock the user out
+	now := time.Now()
+	if err := u.users.RecordLogin(ctx, user.ID, now); err != nil {
+		log.Printf("Error recording login of user %s: %v", user.ID, err)
+	} else {
+		user.LastLoginAt, user.LastSeenAt = &now, &now
+	}
+	return user, nil
+}
+
+// VerifyPassword checks the password of a signed-in user re-authenticating before a sensitive
+// change. Like Authenticate, locked and deactivated accounts are refused once the password is verified.
+func (u *UserUseCase) VerifyPassword(ctx context.Context, userID, password string) (*domain.User, error) {
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if err := security.VerifyPassword(user.PasswordHash, password); err != nil {
+		return nil, ErrIncorrectPassword
+	}
+	if user.PasswordResetRequired {
+		return nil, ErrPasswordResetRequired
+	}
+	if user.DeactivatedAt != nil {
+		return nil, ErrAccountDeactivated
+	}
+	return user, nil
+}
+
+// TokenGeneration returns the generation the tokens of the user must have to be valid
+func (u *UserUseCase) TokenGeneration(ctx context.Context, userID string) (int, error) {
+	return u.users.GetTokenGeneration(ctx, userID)
+}
+
+// LogoutAll revokes every token issued to the user so far by bumping its token generation
+func (u *UserUseCase) LogoutAll(ctx context.Context, actorID, userID string) error {
+	generation, err := u.users.IncrementTokenGeneration(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if generation == 0 {
+		return ErrUserNotFound
+	}
+	details := map[string]string{"token_generation": strconv.Itoa(generation)}
+	if err := u.audit.Record(ctx, domain.AuditActionLoggedOutAll, actorID, userID, details); err != nil {
+		log.Printf("Error recording logout of user %s from all devices: %v", userID, err)
+	}
+	return nil
+}
+
+// RecordLastSeen marks the user as seen now; callers throttle how often it is called
+func (u *UserUseCase) RecordLastSeen(ctx context.Context, userID string) error {
+	return u.users.SetLastSeen(ctx, userID, time.Now())
+}
+
 func (u *UserUseCase) GetUsers(ctx context.Context, opts *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+	if opts != nil && opts.Filter != nil {
+		if err := opts.Filter.Validate(); err != nil {
+			return nil, err
+		}
+	}
 	users, err := u.users.GetUsers(ctx, opts)
 	if err != nil {
 		return nil, err
@@ -55,6 +239,10 @@ func (u *UserUseCase) GetUsers(ctx context.Context, opts *ports.GetUsersOptions)
 	return users, nil
 }
 
+func (u *UserUseCase) CountUsers(ctx context.Context, opts *ports.GetUsersOptions) (int64, error) {
+	return u.users.CountUsers(ctx, opts)
+}
+
 func (u *UserUseCase) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
 	user, err := u.users.GetUserByEmail(ctx, email)
 	if err != nil {
@@ -66,6 +254,76 @@ func (u *UserUseCase) GetUserByEmail(ctx context.Context, email string) (*domain
 	return user, nil
 }
 
+func (u *UserUseCase) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	user, err := u.users.GetUserByUsername(ctx, strings.ToLower(strings.TrimSpace(username)))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// SetUsername claims a new username for the user
+func (u *UserUseCase) SetUsername(ctx context.Context, userID, username string) (*domain.User, error) {
+	normalized, err := domain.NormalizeUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Username == normalized {
+		return user, nil
+	}
+	if existing, _ := u.users.GetUserByUsername(ctx, normalized); existing != nil {
+		return nil, domain.ErrUsernameTaken
+	}
+	// The unique index still catches two users claiming the same name concurrently
+	if err := u.users.SetUsername(ctx, userID, normalized); err != nil {
+		return nil, err
+	}
+	user.Username = normalized
+	return user, nil
+}
+
+// IsEmailAvailable reports whether no user of the tenant is registered with the email or an alias of it
+func (u *UserUseCase) IsEmailAvailable(ctx context.Context, email string) (bool, error) {
+	taken, err := u.emailTaken(ctx, email)
+	if err != nil {
+		return false, err
+	}
+	return !taken, nil
+}
+
+// emailTaken reports whether the email, or another address reaching the same mailbox, is registered.
+// Users stored before normalization existed are matched on their exact email.
+func (u *UserUseCase) emailTaken(ctx context.Context, email string) (bool, error) {
+	canonical, err := domain.CanonicalEmail(email)
+	if err != nil {
+		return false, err
+	}
+	normalized, err := domain.NormalizeEmail(canonical)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := u.users.GetUserByNormalizedEmail(ctx, normalized)
+	if err != nil || existing != nil {
+		return existing != nil, err
+	}
+	existing, err = u.users.GetUserByEmail(ctx, canonical)
+	if err != nil {
+		return false, err
+	}
+	return existing != nil, nil
+}
+
 func (u *UserUseCase) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
 	user, err := u.users.GetUserByID(ctx, id)
 	if err != nil {
@@ -74,6 +332,54 @@ func (u *UserUseCase) GetUserByID(ctx context.Context, id string) (*domain.User,
 	return user, nil
 }
 
+func (u *UserUseCase) UserExists(ctx context.Context, id string) (bool, error) {
+	return u.users.UserExists(ctx, id)
+}
+
+// AutocompleteUsers returns up to limit users, 10 by default and at most 50, whose email, username or
+// names start with prefix, for the user pickers
+func (u *UserUseCase) AutocompleteUsers(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+	prefix = strings.Join(strings.Fields(prefix), " ")
+	if prefix == "" || utf8.RuneCountInString(prefix) > domain.MaxAutocompleteLength {
+		return nil, fmt.Errorf("%w: q must have 1 to %d characters", domain.ErrInvalidSearch, domain.MaxAutocompleteLength)
+	}
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+	return u.users.AutocompleteUsers(ctx, prefix, limit)
+}
+
+// LookupUsers returns the users matching ids in request order; unknown IDs are skipped
+func (u *UserUseCase) LookupUsers(ctx context.Context, ids []string) ([]*domain.User, error) {
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id = strings.TrimSpace(id); id != "" && !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) == 0 {
+		return []*domain.User{}, nil
+	}
+
+	found, err := u.users.GetUsersByIDs(ctx, unique)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+	users := make([]*domain.User, 0, len(found))
+	for _, id := range unique {
+		if user, ok := byID[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
 func (u *UserUseCase) UpdateUser(ctx context.Context, user *domain.User) error {
 	if err := u.users.UpdateUser(ctx, user); err != nil {
 		return err
@@ -87,3 +393,432 @@ func (u *UserUseCase) DeleteUser(ctx context.Context, id string) error {
 	}
 	return nil
 }
+
+// UpdateProfile applies a partial profile change. The minimum age rule only applies when the
+// birthdate changes, so that users registered before it was enabled can still edit their profile.
+func (u *UserUseCase) UpdateProfile(ctx context.Context, actorID, userID string, update domain.ProfileUpdate, overrideMinimumAge bool) (*domain.User, error) {
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	profile, err := update.Apply(user.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	overridden := false
+	if profile.Birthdate != user.Profile.Birthdate {
+		if err := u.agePolicy.Check(profile.Birthdate, time.Now()); err != nil {
+			if !overrideMinimumAge {
+				return nil, &domain.FieldError{Field: "birthdate", Err: err}
+			}
+			// Malformed birthdates were already rejected by Apply, only the rule itself is overridden
+			overridden = true
+		}
+	}
+	if err := u.users.SetProfile(ctx, userID, profile); err != nil {
+		return nil, err
+	}
+	// A verified phone, or a code sent to it, do not carry over to a new phone
+	if profile.Phone != user.Profile.Phone && (user.PhoneVerifiedAt != nil || user.PhoneChallenge != nil) {
+		if _, err := u.users.SetPhoneVerified(ctx, userID, profile.Phone, nil); err != nil {
+			return nil, err
+		}
+		user.PhoneVerifiedAt, user.PhoneChallenge = nil, nil
+	}
+	if overridden {
+		details := map[string]string{"birthdate": profile.Birthdate, "minimum_age": strconv.Itoa(u.agePolicy.MinimumAge)}
+		if err := u.audit.Record(ctx, domain.AuditActionMinimumAgeOverridden, actorID, userID, details); err != nil {
+			log.Printf("Error recording minimum age override of user %s: %v", userID, err)
+		}
+	}
+	user.Profile = profile
+	return user, nil
+}
+
+func (u *UserUseCase) GetSettings(ctx context.Context, userID string) (*domain.Settings, error) {
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	settings := domain.DefaultSettings()
+	if user.Settings != nil {
+		settings = *user.Settings
+	}
+	return &settings, nil
+}
+
+func (u *UserUseCase) UpdateSettings(ctx context.Context, userID string, update domain.SettingsUpdate) (*domain.Settings, error) {
+	if err := update.Validate(); err != nil {
+		return nil, err
+	}
+	current, err := u.GetSettings(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	settings := update.Apply(*current)
+	if err := u.users.SetSettings(ctx, userID, settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (u *UserUseCase) ListAddresses(ctx context.Context, userID string) ([]domain.Address, error) {
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if user.Profile.Addresses == nil {
+		return []domain.Address{}, nil
+	}
+	return user.Profile.Addresses, nil
+}
+
+// AddAddress adds an address to the user; the first address, or one flagged primary, becomes the primary one
+func (u *UserUseCase) AddAddress(ctx context.Context, userID string, address domain.Address) (*domain.Address, error) {
+	addresses, err := u.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := address.Validate(); err != nil {
+		return nil, err
+	}
+	address.ID = ""
+	if address.Primary {
+		domain.SetPrimaryAddress(addresses, "")
+	}
+	addresses, err = domain.NormalizeAddresses(append(addresses, address))
+	if err != nil {
+		return nil, err
+	}
+	added := &addresses[len(addresses)-1]
+	if err := u.locate(ctx, added); err != nil {
+		return nil, err
+	}
+	if err := u.users.SetAddresses(ctx, userID, addresses); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// UpdateAddress replaces an address of the user, keeping its ID
+func (u *UserUseCase) UpdateAddress(ctx context.Context, userID, addressID string, address domain.Address) (*domain.Address, error) {
+	addresses, err := u.ListAddresses(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	index := slices.IndexFunc(addresses, func(a domain.Address) bool { return a.ID == addressID })
+	if index < 0 {
+		return nil, domain.ErrAddressNotFound
+	}
+	if err := address.Validate(); err != nil {
+		return nil, err
+	}
+	address.ID = addressID
+	if address.Primary {
+		domain.SetPrimaryAddress(addresses, "")
+	} else if addresses[index].Primary {
+		// Unflagging the primary address hands the flag to the first other address
+		address.Primary = len(addresses) == 1
+		for i := range addresses {
+			if i != index {
+				addresses[i].Primary = true
+				break
+			}
+		}
+	}
+	addresses[index] = address
+	addresses, err = domain.NormalizeAddresses(addresses)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.locate(ctx, &addresses[index]); err != nil {
+		return nil, err
+	}
+	if err := u.users.SetAddresses(ctx, userID, addresses); err != nil {
+		return nil, err
+	}
+	return &addresses[index], nil
+}
+
+// locate sets the coordinates of an address when geocoding is enabled. Provider errors never block
+// the write: the address is stored without coordinates and the error is logged.
+func (u *UserUseCase) locate(ctx context.Context, address *domain.Address) error {
+	if u.geocoding.Geocoder == nil {
+		return nil
+	}
+	address.Location = nil
+	point, err := u.geocoding.Geocoder.Geocode(ctx, *address)
+	if err != nil {
+		log.Printf("Error geocoding address %s: %v", address.ID, err)
+		return nil
+	}
+	if point == nil && u.geocoding.RejectUnknown {
+		return domain.ErrUnknownAddress
+	}
+	address.Location = point
+	return nil
+}
+
+// RemoveAddress deletes an address of the user; removing the primary address promotes the first remaining one
+func (u *UserUseCase) RemoveAddress(ctx context.Context, userID, addressID string) error {
+	addresses, err := u.ListAddresses(ctx, userID)
+	if err != nil {
+		return err
+	}
+	index := slices.IndexFunc(addresses, func(a domain.Address) bool { return a.ID == addressID })
+	if index < 0 {
+		return domain.ErrAddressNotFound
+	}
+	addresses, err = domain.NormalizeAddresses(slices.Delete(addresses, index, index+1))
+	if err != nil {
+		return err
+	}
+	return u.users.SetAddresses(ctx, userID, addresses)
+}
+
+func (u *UserUseCase) UpdateMetadata(ctx context.Context, userID string, changes map[string]*string) (map[string]string, error) {
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	metadata, err := u.metadataPolicy.ApplyChanges(user.Metadata, changes)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.users.SetMetadata(ctx, userID, metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+func (u *UserUseCase) AddTags(ctx context.Context, userID string, tags []string) ([]string, error) {
+	normalized, err := domain.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	result, err := u.users.AddTags(ctx, userID, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ErrUserNotFound
+	}
+	return result, nil
+}
+
+func (u *UserUseCase) RemoveTag(ctx context.Context, userID string, tag string) ([]string, error) {
+	normalized, err := domain.NormalizeTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	result, err := u.users.RemoveTag(ctx, userID, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		return nil, ErrUserNotFound
+	}
+	return result, nil
+}
+
+// BatchUpdateUsers applies the update in one query. The audit event is mandatory: it is recorded
+// even when the update fails, and failing to record it fails the request.
+func (u *UserUseCase) BatchUpdateUsers(ctx context.Context, actorID string, opts *ports.GetUsersOptions, update domain.UserBatchUpdate) (*ports.UserBatchResult, error) {
+	if opts == nil || (opts.Filter == nil && len(opts.IDs) == 0) {
+		return nil, domain.ErrBatchSelectorMissing
+	}
+	if opts.Filter != nil {
+		if err := opts.Filter.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := update.Normalize(); err != nil {
+		return nil, err
+	}
+
+	details := map[string]string{}
+	if opts.Filter != nil {
+		filter, err := json.Marshal(opts.Filter)
+		if err != nil {
+			return nil, err
+		}
+		details["filter"] = string(filter)
+	}
+	if len(opts.IDs) > 0 {
+		details["ids"] = strings.Join(opts.IDs, ",")
+	}
+	if len(update.AddTags) > 0 {
+		details["add_tags"] = strings.Join(update.AddTags, ",")
+	}
+	if len(update.RemoveTags) > 0 {
+		details["remove_tags"] = strings.Join(update.RemoveTags, ",")
+	}
+	if update.Deactivated != nil {
+		details["deactivated"] = strconv.FormatBool(*update.Deactivated)
+	}
+
+	result, err := u.users.UpdateUsers(ctx, opts, update)
+	if err != nil {
+		details["error"] = err.Error()
+	} else {
+		details["matched"] = strconv.FormatInt(result.Matched, 10)
+		details["modified"] = strconv.FormatInt(result.Modified, 10)
+	}
+	if auditErr := u.audit.Record(ctx, domain.AuditActionUsersBatchUpdated, actorID, "", details); auditErr != nil {
+		return result, errors.Join(err, auditErr)
+	}
+	return result, err
+}
+
+// UpsertUserByEmail mirrors a user of another system of record. Users are created without a password,
+// like the accounts of the directory sync; existing users get the fields of the upsert according to
+// policy. The minimum age rule applies to new users and changed birthdates, as on registration and
+// profile updates.
+func (u *UserUseCase) UpsertUserByEmail(ctx context.Context, actorID, email string, upsert domain.UserUpsert, policy string) (*domain.User, bool, error) {
+	if policy == "" {
+		policy = u.upsertPolicy
+	}
+	policy, err := domain.ParseUpsertPolicy(policy)
+	if err != nil {
+		return nil, false, err
+	}
+	canonical, err := domain.CanonicalEmail(email)
+	if err != nil {
+		return nil, false, err
+	}
+	var username string
+	if upsert.Username != "" {
+		if username, err = domain.NormalizeUsername(upsert.Username); err != nil {
+			return nil, false, err
+		}
+	}
+
+	user, err := u.users.GetUserByEmail(ctx, canonical)
+	if err != nil {
+		return nil, false, err
+	}
+	if user == nil {
+		user, err = u.createUpserted(ctx, canonical, username, upsert)
+		if err != nil {
+			return nil, false, err
+		}
+		u.recordUpsert(ctx, actorID, user.ID, "created", policy, nil)
+		return user, true, nil
+	}
+
+	updated, changed, err := upsert.Apply(user, username, policy)
+	if err != nil || len(changed) == 0 {
+		return user, false, err
+	}
+	if updated.Profile.Birthdate != user.Profile.Birthdate {
+		if err := u.agePolicy.Check(updated.Profile.Birthdate, time.Now()); err != nil {
+			return nil, false, &domain.FieldError{Field: "birthdate", Err: err}
+		}
+	}
+	if updated.Username != user.Username {
+		if existing, _ := u.users.GetUserByUsername(ctx, updated.Username); existing != nil {
+			return nil, false, domain.ErrUsernameTaken
+		}
+		// The unique index still catches two users claiming the same name concurrently
+		if err := u.users.SetUsername(ctx, user.ID, updated.Username); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := u.users.SetProfile(ctx, user.ID, updated.Profile); err != nil {
+		return nil, false, err
+	}
+	// A verified phone, or a code sent to it, do not carry over to a new phone
+	if updated.Profile.Phone != user.Profile.Phone && (user.PhoneVerifiedAt != nil || user.PhoneChallenge != nil) {
+		if _, err := u.users.SetPhoneVerified(ctx, user.ID, updated.Profile.Phone, nil); err != nil {
+			return nil, false, err
+		}
+		updated.PhoneVerifiedAt, updated.PhoneChallenge = nil, nil
+	}
+	u.recordUpsert(ctx, actorID, user.ID, "updated", policy, changed)
+	return updated, false, nil
+}
+
+// createUpserted creates the user of an upsert whose email no user has
+func (u *UserUseCase) createUpserted(ctx context.Context, email, username string, upsert domain.UserUpsert) (*domain.User, error) {
+	// Another address reaching the same mailbox is not the same key, but cannot be registered twice
+	taken, err := u.emailTaken(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrEmailTaken
+	}
+	profile, err := upsert.Profile()
+	if err != nil {
+		return nil, err
+	}
+	profile.FillNames(email)
+	if err := u.agePolicy.Check(profile.Birthdate, time.Now()); err != nil {
+		return nil, &domain.FieldError{Field: "birthdate", Err: err}
+	}
+	if username != "" {
+		if existing, _ := u.users.GetUserByUsername(ctx, username); existing != nil {
+			return nil, domain.ErrUsernameTaken
+		}
+	}
+	user, err := domain.NewUser(email, domain.NoPasswordHash, profile)
+	if err != nil {
+		return nil, err
+	}
+	user.Username = username
+	if err := u.users.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// FindOrCreate creates users without a password, named after their email when the names are blank,
+// for sign-ins and imports that must not create the same user twice. Unlike upserts, existing users
+// are returned unchanged.
+func (u *UserUseCase) FindOrCreate(ctx context.Context, actorID, email, firstName, lastName string) (*domain.User, bool, error) {
+	canonical, err := domain.CanonicalEmail(email)
+	if err != nil {
+		return nil, false, err
+	}
+	profile := domain.Profile{FirstName: strings.TrimSpace(firstName), LastName: strings.TrimSpace(lastName)}
+	profile.FillNames(canonical)
+	user, err := domain.NewUser(canonical, domain.NoPasswordHash, profile)
+	if err != nil {
+		return nil, false, err
+	}
+
+	found, created, err := u.users.FindOrCreateUser(ctx, user)
+	if err != nil {
+		return nil, false, err
+	}
+	if created {
+		details := map[string]string{"source": "find_or_create"}
+		if err := u.audit.Record(ctx, domain.AuditActionUserCreated, actorID, found.ID, details); err != nil {
+			log.Printf("Error recording creation of user %s: %v", found.ID, err)
+		}
+	}
+	return found, created, nil
+}
+
+func (u *UserUseCase) recordUpsert(ctx context.Context, actorID, userID, result, policy string, fields []string) {
+	details := map[string]string{"result": result, "policy": policy}
+	if len(fields) > 0 {
+		details["fields"] = strings.Join(fields, ",")
+	}
+	if err := u.audit.Record(ctx, domain.AuditActionUserUpserted, actorID, userID, details); err != nil {
+		log.Printf("Error recording upsert of user %s: %v", userID, err)
+	}
+}
@@ -0,0 +1,310 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.VerificationUseCase = (*VerificationUseCase)(nil)
+
+var (
+	ErrVerificationSelfReview   = errors.New("permission denied: users cannot review their own verification")
+	ErrIdentityCheckNotFound    = errors.New("identity check not found")
+	ErrIdentityVerifierDisabled = errors.New("identity verification provider not configured")
+)
+
+// identityCheckTimeout bounds the start of an automated check, which uploads the documents to the provider
+const identityCheckTimeout = 2 * time.Minute
+
+// VerificationUseCase moves users through the identity verification workflow. Every change of
+// status is recorded in the audit log, and reviews are emailed to the user.
+type VerificationUseCase struct {
+	users     ports.UserRepository
+	documents ports.DocumentRepository
+	storage   ports.PresignedFileStorage
+	verifier  ports.IdentityVerifier // Nil leaves every submission to staff
+	audit     ports.AuditUseCase
+	mailer    ports.Mailer
+}
+
+func NewVerificationUseCase(users ports.UserRepository, documents ports.DocumentRepository, storage ports.PresignedFileStorage, verifier ports.IdentityVerifier, audit ports.AuditUseCase, mailer ports.Mailer) *VerificationUseCase {
+	return &VerificationUseCase{
+		users:     users,
+		documents: documents,
+		storage:   storage,
+		verifier:  verifier,
+		audit:     audit,
+		mailer:    mailer,
+	}
+}
+
+func (u *VerificationUseCase) GetVerification(ctx context.Context, userID string) (*domain.Verification, error) {
+	user, err := u.requireUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Verification == nil {
+		return &domain.Verification{Status: domain.VerificationStatusUnverified}, nil
+	}
+	return user.Verification, nil
+}
+
+func (u *VerificationUseCase) SubmitVerification(ctx context.Context, actorID, userID string, documentIDs []string) (*domain.Verification, error) {
+	user, err := u.requireUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	documentIDs = slices.Compact(slices.Sorted(slices.Values(documentIDs)))
+	from := user.Verification.CurrentStatus()
+	verification, err := user.Verification.Submit(actorID, documentIDs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	// Only documents of the user still awaiting review can back a submission
+	documents := make([]*domain.Document, 0, len(documentIDs))
+	for _, id := range documentIDs {
+		document, err := u.documents.GetDocument(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		if document == nil || document.Status != domain.DocumentStatusPending {
+			return nil, domain.ErrInvalidVerificationDocuments
+		}
+		documents = append(documents, document)
+	}
+
+	if err := u.transition(ctx, user, verification, from); err != nil {
+		return nil, err
+	}
+	u.record(ctx, domain.AuditActionVerificationSubmitted, actorID, userID, from, verification)
+	if u.verifier != nil {
+		u.startCheck(ctx, user, verification, documents)
+	}
+	return verification, nil
+}
+
+func (u *VerificationUseCase) ReviewVerification(ctx context.Context, reviewerID, userID, decision, reason string) (*domain.Verification, error) {
+	if reviewerID == userID {
+		return nil, ErrVerificationSelfReview
+	}
+	user, err := u.requireUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	from := user.Verification.CurrentStatus()
+	verification, err := user.Verification.Review(reviewerID, decision, reason, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := u.applyReview(ctx, reviewerID, user, verification, from); err != nil {
+		return nil, err
+	}
+	return verification, nil
+}
+
+// HandleCheckWebhook verifies the user once the provider clears its check, and leaves the checks to
+// consider to staff. Results of checks already completed, or of verifications staff reviewed in
+// the meantime, are ignored.
+func (u *VerificationUseCase) HandleCheckWebhook(ctx context.Context, header http.Header, body []byte) error {
+	if u.verifier == nil {
+		return ErrIdentityVerifierDisabled
+	}
+	result, err := u.verifier.ParseWebhook(ctx, header, body)
+	if err != nil || result == nil {
+		return err
+	}
+
+	// The provider retries the deliveries answered with an error, such as one arriving before the
+	// check it reports is stored
+	user, err := u.users.GetUserByVerificationCheck(ctx, u.verifier.Provider(), result.CheckID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return ErrIdentityCheckNotFound
+	}
+	current := user.Verification
+	if current.Status != domain.VerificationStatusPendingReview || current.Check.Result != "" {
+		return nil
+	}
+
+	now := time.Now()
+	check := *current.Check
+	check.Result = result.Result
+	check.CompletedAt = &now
+	actorID := "provider:" + check.Provider
+
+	if result.Result == domain.IdentityCheckResultClear {
+		verification, err := current.Review(actorID, domain.VerificationDecisionApprove, "", now)
+		if err != nil {
+			return err
+		}
+		verification.Check = &check
+		return u.applyReview(ctx, actorID, user, verification, current.Status)
+	}
+
+	checked := *current
+	checked.Check = &check
+	if err := u.transition(ctx, user, &checked, current.Status); err != nil {
+		return err
+	}
+	u.record(ctx, domain.AuditActionVerificationChecked, actorID, user.ID, current.Status, &checked)
+	return nil
+}
+
+// applyReview stores the reviewed verification, gives its outcome to the documents it reviewed and
+// emits it
+func (u *VerificationUseCase) applyReview(ctx context.Context, reviewerID string, user *domain.User, verification *domain.Verification, from string) error {
+	if err := u.transition(ctx, user, verification, from); err != nil {
+		return err
+	}
+
+	documentStatus := domain.DocumentStatusVerified
+	action := domain.AuditActionVerificationVerified
+	if verification.Status == domain.VerificationStatusRejected {
+		documentStatus = domain.DocumentStatusRejected
+		action = domain.AuditActionVerificationRejected
+	}
+	if err := u.documents.SetDocumentsStatus(ctx, user.ID, verification.DocumentIDs, documentStatus); err != nil {
+		return err
+	}
+
+	u.record(ctx, action, reviewerID, user.ID, from, verification)
+	u.notify(ctx, user, verification)
+	return nil
+}
+
+// startCheck submits the documents to the identity verification provider in the background; when
+// the check cannot start, the verification is left to staff
+func (u *VerificationUseCase) startCheck(ctx context.Context, user *domain.User, verification *domain.Verification, documents []*domain.Document) {
+	checkCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), identityCheckTimeout)
+	go func() {
+		defer cancel()
+		if err := u.runCheck(checkCtx, user, verification, documents); err != nil {
+			log.Printf("Error starting identity check of user %s: %v", user.ID, err)
+		}
+	}()
+}
+
+func (u *VerificationUseCase) runCheck(ctx context.Context, user *domain.User, verification *domain.Verification, documents []*domain.Document) error {
+	request := &ports.IdentityCheckRequest{User: user}
+	for _, document := range documents {
+		content, err := u.readFile(ctx, document.StorageKey)
+		if err != nil {
+			return err
+		}
+		request.Documents = append(request.Documents, ports.IdentityCheckDocument{Document: document, Content: content})
+	}
+
+	checkID, err := u.verifier.StartCheck(ctx, request)
+	if err != nil {
+		return err
+	}
+	if checkID == "" {
+		log.Printf("No document of user %s can be checked by %s, leaving its verification to staff", user.ID, u.verifier.Provider())
+		return nil
+	}
+
+	checked := *verification
+	checked.Check = &domain.IdentityCheck{Provider: u.verifier.Provider(), ID: checkID, StartedAt: time.Now()}
+	return u.transition(ctx, user, &checked, domain.VerificationStatusPendingReview)
+}
+
+func (u *VerificationUseCase) readFile(ctx context.Context, key string) ([]byte, error) {
+	file, err := u.storage.Open(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
+
+// transition stores the verification unless another request changed it since it was read
+func (u *VerificationUseCase) transition(ctx context.Context, user *domain.User, verification *domain.Verification, from string) error {
+	updated, err := u.users.SetVerification(ctx, user.ID, verification, from)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return domain.ErrVerificationConflict
+	}
+	return nil
+}
+
+// record emits the change of status to the audit log; a failure does not undo the change
+func (u *VerificationUseCase) record(ctx context.Context, action, actorID, userID, from string, verification *domain.Verification) {
+	details := map[string]string{
+		"from":         from,
+		"to":           verification.Status,
+		"document_ids": strings.Join(verification.DocumentIDs, ","),
+	}
+	if verification.Reason != "" {
+		details["reason"] = verification.Reason
+	}
+	if verification.Check != nil && verification.Check.Result != "" {
+		details["check_result"] = verification.Check.Result
+	}
+	if err := u.audit.Record(ctx, action, actorID, userID, details); err != nil {
+		log.Printf("Error recording verification of user %s moving to %s: %v", userID, verification.Status, err)
+	}
+}
+
+// notify emails the outcome of the review to the user in the background
+func (u *VerificationUseCase) notify(ctx context.Context, user *domain.User, verification *domain.Verification) {
+	notifyCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notificationTimeout)
+	go func() {
+		defer cancel()
+		if err := u.mailer.Send(notifyCtx, verificationEmail(user, verification)); err != nil {
+			log.Printf("Error sending verification outcome to user %s: %v", user.ID, err)
+		}
+	}()
+}
+
+func verificationEmail(user *domain.User, verification *domain.Verification) ports.EmailMessage {
+	name := user.Profile.FirstName
+	if name == "" {
+		name = user.Email
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hello %s,\n\n", name)
+	subject := "Your identity is verified"
+	if verification.Status == domain.VerificationStatusVerified {
+		body.WriteString("We reviewed your identity documents and your identity is now verified.\n")
+	} else {
+		subject = "Your identity verification was rejected"
+		body.WriteString("We could not verify your identity with the documents you submitted.\n\n")
+		fmt.Fprintf(&body, "Reason: %s\n\n", verification.Reason)
+		body.WriteString("You can upload new documents and submit them for review again.\n")
+	}
+
+	return ports.EmailMessage{
+		To:      user.Email,
+		Subject: subject,
+		Body:    body.String(),
+	}
+}
+
+func (u *VerificationUseCase) requireUser(ctx context.Context, userID string) (*domain.User, error) {
+	user, err := u.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
@@ -0,0 +1,7 @@
+// Package mocks provides fakes of the ports interfaces for tests. Every fake has a <Method>Func field
+// per method, called by the method when set; unset methods return zero values, so tests only stub
+// the calls they exercise. The fakes are generated from package ports, regenerate them after
+// changing a port with go generate ./internal/mocks.
+package mocks
+
+//go:generate go run ./gen
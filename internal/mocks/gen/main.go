@@ -0,0 +1,264 @@
+// Command gen writes the fakes of package mocks from the interfaces of package ports:
+// one struct per interface with a <Method>Func field per method. Run it with go generate.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	portsDir    = "../core/ports"
+	portsImport = "github.com/frtasoniero/user-management-api/internal/core/ports"
+	output      = "ports.go"
+)
+
+func main() {
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(portsDir, "*.go"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(files)
+
+	imports := map[string]string{"ports": portsImport}
+	var body bytes.Buffer
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fileImports := map[string]string{}
+		for _, spec := range file.Imports {
+			importPath, _ := strconv.Unquote(spec.Path.Value)
+			name := filepath.Base(importPath)
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			fileImports[name] = importPath
+		}
+
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				iface, ok := typeSpec.Type.(*ast.InterfaceType)
+				if !ok || !typeSpec.Name.IsExported() {
+					continue
+				}
+				g := &generator{fileImports: fileImports, imports: imports}
+				g.writeFake(&body, typeSpec.Name.Name, iface)
+			}
+		}
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by go run ./gen; DO NOT EDIT.\n\npackage mocks\n\nimport (\n")
+	var std, module []string
+	for _, path := range imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			module = append(module, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(module)
+	for _, path := range std {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString("\n")
+	for _, path := range module {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	out.WriteString(")\n")
+	out.Write(body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatalf("formatting generated code: %v\n%s", err, out.Bytes())
+	}
+	if err := os.WriteFile(output, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type generator struct {
+	fileImports map[string]string // Imports of the ports file being read, by package name
+	imports     map[string]string // Imports of the generated file, by package name
+}
+
+func (g *generator) writeFake(w *bytes.Buffer, name string, iface *ast.InterfaceType) {
+	type method struct {
+		name            string
+		params, results []string // Types
+		variadic        bool
+	}
+	var methods []method
+	for _, field := range iface.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			log.Fatalf("%s: embedded interfaces are not supported", name)
+		}
+		m := method{name: field.Names[0].Name}
+		for _, param := range fn.Params.List {
+			typ := param.Type
+			if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+				m.variadic = true
+				typ = ellipsis.Elt
+			}
+			for range max(1, len(param.Names)) {
+				m.params = append(m.params, g.typeString(typ))
+			}
+		}
+		if fn.Results != nil {
+			for _, result := range fn.Results.List {
+				for range max(1, len(result.Names)) {
+					m.results = append(m.results, g.typeString(result.Type))
+				}
+			}
+		}
+		methods = append(methods, m)
+	}
+
+	fmt.Fprintf(w, "\n// %s is a fake ports.%s; each method calls the field of the same name with a Func suffix,\n", name, name)
+	fmt.Fprintf(w, "// or returns zero values when it is nil\ntype %s struct {\n", name)
+	for _, m := range methods {
+		fmt.Fprintf(w, "\t%sFunc func(%s) %s\n", m.name, signature(m.params, m.variadic, false), results(m.results, false))
+	}
+	fmt.Fprintf(w, "}\n\nvar _ ports.%s = (*%s)(nil)\n", name, name)
+
+	for _, m := range methods {
+		args := make([]string, len(m.params))
+		for i := range m.params {
+			args[i] = "p" + strconv.Itoa(i)
+		}
+		if m.variadic {
+			args[len(args)-1] += "..."
+		}
+		fmt.Fprintf(w, "\nfunc (m *%s) %s(%s) %s {\n", name, m.name, signature(m.params, m.variadic, true), results(m.results, true))
+		call := fmt.Sprintf("m.%sFunc(%s)", m.name, strings.Join(args, ", "))
+		if len(m.results) == 0 {
+			fmt.Fprintf(w, "\tif m.%sFunc != nil {\n\t\t%s\n\t}\n}\n", m.name, call)
+		} else {
+			fmt.Fprintf(w, "\tif m.%sFunc != nil {\n\t\treturn %s\n\t}\n\treturn\n}\n", m.name, call)
+		}
+	}
+}
+
+// signature renders parameter types, named p0, p1... when named is set
+func signature(params []string, variadic, named bool) string {
+	parts := make([]string, len(params))
+	for i, typ := range params {
+		if variadic && i == len(params)-1 {
+			typ = "..." + typ
+		}
+		if named {
+			typ = "p" + strconv.Itoa(i) + " " + typ
+		}
+		parts[i] = typ
+	}
+	return strings.Join(parts, ", ")
+}
+
+// results renders result types, named r0, r1... when named is set so methods can return zero values
+func results(types []string, named bool) string {
+	if len(types) == 0 {
+		return ""
+	}
+	if !named && len(types) == 1 {
+		return types[0]
+	}
+	parts := make([]string, len(types))
+	for i, typ := range types {
+		if named {
+			typ = "r" + strconv.Itoa(i) + " " + typ
+		}
+		parts[i] = typ
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+// typeString renders a type of the ports package as seen from package mocks: exported identifiers
+// of ports are qualified and the packages referenced are added to the imports
+func (g *generator) typeString(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if e.IsExported() {
+			return "ports." + e.Name
+		}
+		return e.Name
+	case *ast.SelectorExpr:
+		pkg := e.X.(*ast.Ident).Name
+		path, ok := g.fileImports[pkg]
+		if !ok {
+			log.Fatalf("unknown package %s", pkg)
+		}
+		g.imports[pkg] = path
+		return pkg + "." + e.Sel.Name
+	case *ast.StarExpr:
+		return "*" + g.typeString(e.X)
+	case *ast.ArrayType:
+		if e.Len != nil {
+			return "[" + e.Len.(*ast.BasicLit).Value + "]" + g.typeString(e.Elt)
+		}
+		return "[]" + g.typeString(e.Elt)
+	case *ast.MapType:
+		return "map[" + g.typeString(e.Key) + "]" + g.typeString(e.Value)
+	case *ast.ChanType:
+		switch e.Dir {
+		case ast.SEND:
+			return "chan<- " + g.typeString(e.Value)
+		case ast.RECV:
+			return "<-chan " + g.typeString(e.Value)
+		}
+		return "chan " + g.typeString(e.Value)
+	case *ast.FuncType:
+		var params, resultTypes []string
+		variadic := false
+		for _, field := range e.Params.List {
+			typ := field.Type
+			if ellipsis, ok := typ.(*ast.Ellipsis); ok {
+				variadic = true
+				typ = ellipsis.Elt
+			}
+			for range max(1, len(field.Names)) {
+				params = append(params, g.typeString(typ))
+			}
+		}
+		if e.Results != nil {
+			for _, field := range e.Results.List {
+				for range max(1, len(field.Names)) {
+					resultTypes = append(resultTypes, g.typeString(field.Type))
+				}
+			}
+		}
+		return strings.TrimSpace("func(" + signature(params, variadic, false) + ") " + results(resultTypes, false))
+	case *ast.InterfaceType:
+		if len(e.Methods.List) == 0 {
+			return "any"
+		}
+	case *ast.StructType:
+		if len(e.Fields.List) == 0 {
+			return "struct{}"
+		}
+	}
+	log.Fatalf("unsupported type %T", expr)
+	return ""
+}
@@ -0,0 +1,2832 @@
+// Code generated by go run ./gen; DO NOT EDIT.
+
+package mocks
+
+import (
+	"context"
+	"crypto"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// AccountMergeUseCase is a fake ports.AccountMergeUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type AccountMergeUseCase struct {
+	FindDuplicatesFunc func(context.Context, int, int) (*ports.DuplicateReport, error)
+	MergeFunc          func(context.Context, string, string, string, string) (*domain.User, error)
+}
+
+var _ ports.AccountMergeUseCase = (*AccountMergeUseCase)(nil)
+
+func (m *AccountMergeUseCase) FindDuplicates(p0 context.Context, p1 int, p2 int) (r0 *ports.DuplicateReport, r1 error) {
+	if m.FindDuplicatesFunc != nil {
+		return m.FindDuplicatesFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *AccountMergeUseCase) Merge(p0 context.Context, p1 string, p2 string, p3 string, p4 string) (r0 *domain.User, r1 error) {
+	if m.MergeFunc != nil {
+		return m.MergeFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+// AuditRepository is a fake ports.AuditRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type AuditRepository struct {
+	CreateAuditEventFunc func(context.Context, *domain.AuditEvent) error
+	ListAuditEventsFunc  func(context.Context, *ports.AuditQuery) (*ports.AuditQueryResult, error)
+}
+
+var _ ports.AuditRepository = (*AuditRepository)(nil)
+
+func (m *AuditRepository) CreateAuditEvent(p0 context.Context, p1 *domain.AuditEvent) (r0 error) {
+	if m.CreateAuditEventFunc != nil {
+		return m.CreateAuditEventFunc(p0, p1)
+	}
+	return
+}
+
+func (m *AuditRepository) ListAuditEvents(p0 context.Context, p1 *ports.AuditQuery) (r0 *ports.AuditQueryResult, r1 error) {
+	if m.ListAuditEventsFunc != nil {
+		return m.ListAuditEventsFunc(p0, p1)
+	}
+	return
+}
+
+// AuditSink is a fake ports.AuditSink; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type AuditSink struct {
+	WriteEventsFunc func(context.Context, []*domain.AuditEvent) error
+}
+
+var _ ports.AuditSink = (*AuditSink)(nil)
+
+func (m *AuditSink) WriteEvents(p0 context.Context, p1 []*domain.AuditEvent) (r0 error) {
+	if m.WriteEventsFunc != nil {
+		return m.WriteEventsFunc(p0, p1)
+	}
+	return
+}
+
+// AuditExporter is a fake ports.AuditExporter; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type AuditExporter struct {
+	ExportFunc func(*domain.AuditEvent)
+}
+
+var _ ports.AuditExporter = (*AuditExporter)(nil)
+
+func (m *AuditExporter) Export(p0 *domain.AuditEvent) {
+	if m.ExportFunc != nil {
+		m.ExportFunc(p0)
+	}
+}
+
+// AuditUseCase is a fake ports.AuditUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type AuditUseCase struct {
+	RecordFunc func(context.Context, string, string, string, map[string]string) error
+	ListFunc   func(context.Context, *ports.AuditQuery) (*ports.AuditQueryResult, error)
+}
+
+var _ ports.AuditUseCase = (*AuditUseCase)(nil)
+
+func (m *AuditUseCase) Record(p0 context.Context, p1 string, p2 string, p3 string, p4 map[string]string) (r0 error) {
+	if m.RecordFunc != nil {
+		return m.RecordFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+func (m *AuditUseCase) List(p0 context.Context, p1 *ports.AuditQuery) (r0 *ports.AuditQueryResult, r1 error) {
+	if m.ListFunc != nil {
+		return m.ListFunc(p0, p1)
+	}
+	return
+}
+
+// AvatarUseCase is a fake ports.AvatarUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type AvatarUseCase struct {
+	UploadAvatarFunc func(context.Context, string, []byte) (*domain.Avatar, error)
+}
+
+var _ ports.AvatarUseCase = (*AvatarUseCase)(nil)
+
+func (m *AvatarUseCase) UploadAvatar(p0 context.Context, p1 string, p2 []byte) (r0 *domain.Avatar, r1 error) {
+	if m.UploadAvatarFunc != nil {
+		return m.UploadAvatarFunc(p0, p1, p2)
+	}
+	return
+}
+
+// BreachChecker is a fake ports.BreachChecker; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type BreachChecker struct {
+	BreachesFunc func(context.Context, string) (int, error)
+}
+
+var _ ports.BreachChecker = (*BreachChecker)(nil)
+
+func (m *BreachChecker) Breaches(p0 context.Context, p1 string) (r0 int, r1 error) {
+	if m.BreachesFunc != nil {
+		return m.BreachesFunc(p0, p1)
+	}
+	return
+}
+
+// RetryReporter is a fake ports.RetryReporter; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type RetryReporter struct {
+	RetryStatsFunc func() ports.RetryStats
+}
+
+var _ ports.RetryReporter = (*RetryReporter)(nil)
+
+func (m *RetryReporter) RetryStats() (r0 ports.RetryStats) {
+	if m.RetryStatsFunc != nil {
+		return m.RetryStatsFunc()
+	}
+	return
+}
+
+// BreakerReporter is a fake ports.BreakerReporter; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type BreakerReporter struct {
+	BreakerStatsFunc func() ports.BreakerStats
+}
+
+var _ ports.BreakerReporter = (*BreakerReporter)(nil)
+
+func (m *BreakerReporter) BreakerStats() (r0 ports.BreakerStats) {
+	if m.BreakerStatsFunc != nil {
+		return m.BreakerStatsFunc()
+	}
+	return
+}
+
+// SlowQueryReporter is a fake ports.SlowQueryReporter; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type SlowQueryReporter struct {
+	SlowQueryStatsFunc func() ports.SlowQueryStats
+}
+
+var _ ports.SlowQueryReporter = (*SlowQueryReporter)(nil)
+
+func (m *SlowQueryReporter) SlowQueryStats() (r0 ports.SlowQueryStats) {
+	if m.SlowQueryStatsFunc != nil {
+		return m.SlowQueryStatsFunc()
+	}
+	return
+}
+
+// DigestRepository is a fake ports.DigestRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type DigestRepository struct {
+	CountAdminDigestsFunc    func(context.Context, time.Time, time.Time) ([]*domain.AdminDigest, error)
+	ListDigestRecipientsFunc func(context.Context) ([]string, error)
+}
+
+var _ ports.DigestRepository = (*DigestRepository)(nil)
+
+func (m *DigestRepository) CountAdminDigests(p0 context.Context, p1 time.Time, p2 time.Time) (r0 []*domain.AdminDigest, r1 error) {
+	if m.CountAdminDigestsFunc != nil {
+		return m.CountAdminDigestsFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *DigestRepository) ListDigestRecipients(p0 context.Context) (r0 []string, r1 error) {
+	if m.ListDigestRecipientsFunc != nil {
+		return m.ListDigestRecipientsFunc(p0)
+	}
+	return
+}
+
+// DirectorySource is a fake ports.DirectorySource; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type DirectorySource struct {
+	ListUsersFunc func(context.Context) ([]ports.DirectoryUser, error)
+}
+
+var _ ports.DirectorySource = (*DirectorySource)(nil)
+
+func (m *DirectorySource) ListUsers(p0 context.Context) (r0 []ports.DirectoryUser, r1 error) {
+	if m.ListUsersFunc != nil {
+		return m.ListUsersFunc(p0)
+	}
+	return
+}
+
+// DirectorySyncUseCase is a fake ports.DirectorySyncUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type DirectorySyncUseCase struct {
+	TriggerSyncFunc func(context.Context, string, bool) error
+	StatusFunc      func(context.Context) (*ports.DirectorySyncStatus, error)
+}
+
+var _ ports.DirectorySyncUseCase = (*DirectorySyncUseCase)(nil)
+
+func (m *DirectorySyncUseCase) TriggerSync(p0 context.Context, p1 string, p2 bool) (r0 error) {
+	if m.TriggerSyncFunc != nil {
+		return m.TriggerSyncFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *DirectorySyncUseCase) Status(p0 context.Context) (r0 *ports.DirectorySyncStatus, r1 error) {
+	if m.StatusFunc != nil {
+		return m.StatusFunc(p0)
+	}
+	return
+}
+
+// DocumentRepository is a fake ports.DocumentRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type DocumentRepository struct {
+	CreateDocumentFunc     func(context.Context, *domain.Document) error
+	GetDocumentFunc        func(context.Context, string, string) (*domain.Document, error)
+	ListDocumentsFunc      func(context.Context, string) ([]*domain.Document, error)
+	SetDocumentsStatusFunc func(context.Context, string, []string, string) error
+}
+
+var _ ports.DocumentRepository = (*DocumentRepository)(nil)
+
+func (m *DocumentRepository) CreateDocument(p0 context.Context, p1 *domain.Document) (r0 error) {
+	if m.CreateDocumentFunc != nil {
+		return m.CreateDocumentFunc(p0, p1)
+	}
+	return
+}
+
+func (m *DocumentRepository) GetDocument(p0 context.Context, p1 string, p2 string) (r0 *domain.Document, r1 error) {
+	if m.GetDocumentFunc != nil {
+		return m.GetDocumentFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *DocumentRepository) ListDocuments(p0 context.Context, p1 string) (r0 []*domain.Document, r1 error) {
+	if m.ListDocumentsFunc != nil {
+		return m.ListDocumentsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *DocumentRepository) SetDocumentsStatus(p0 context.Context, p1 string, p2 []string, p3 string) (r0 error) {
+	if m.SetDocumentsStatusFunc != nil {
+		return m.SetDocumentsStatusFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+// DocumentUseCase is a fake ports.DocumentUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type DocumentUseCase struct {
+	UploadDocumentFunc  func(context.Context, *ports.DocumentUpload) (*domain.Document, error)
+	ListDocumentsFunc   func(context.Context, string) ([]*domain.Document, error)
+	PresignDownloadFunc func(context.Context, string, string, string) (*domain.DocumentDownload, error)
+}
+
+var _ ports.DocumentUseCase = (*DocumentUseCase)(nil)
+
+func (m *DocumentUseCase) UploadDocument(p0 context.Context, p1 *ports.DocumentUpload) (r0 *domain.Document, r1 error) {
+	if m.UploadDocumentFunc != nil {
+		return m.UploadDocumentFunc(p0, p1)
+	}
+	return
+}
+
+func (m *DocumentUseCase) ListDocuments(p0 context.Context, p1 string) (r0 []*domain.Document, r1 error) {
+	if m.ListDocumentsFunc != nil {
+		return m.ListDocumentsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *DocumentUseCase) PresignDownload(p0 context.Context, p1 string, p2 string, p3 string) (r0 *domain.DocumentDownload, r1 error) {
+	if m.PresignDownloadFunc != nil {
+		return m.PresignDownloadFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+// EmailDomainRepository is a fake ports.EmailDomainRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type EmailDomainRepository struct {
+	CreateRuleFunc func(context.Context, *domain.EmailDomainRule) error
+	ListRulesFunc  func(context.Context) ([]*domain.EmailDomainRule, error)
+	DeleteRuleFunc func(context.Context, string, string) (bool, error)
+}
+
+var _ ports.EmailDomainRepository = (*EmailDomainRepository)(nil)
+
+func (m *EmailDomainRepository) CreateRule(p0 context.Context, p1 *domain.EmailDomainRule) (r0 error) {
+	if m.CreateRuleFunc != nil {
+		return m.CreateRuleFunc(p0, p1)
+	}
+	return
+}
+
+func (m *EmailDomainRepository) ListRules(p0 context.Context) (r0 []*domain.EmailDomainRule, r1 error) {
+	if m.ListRulesFunc != nil {
+		return m.ListRulesFunc(p0)
+	}
+	return
+}
+
+func (m *EmailDomainRepository) DeleteRule(p0 context.Context, p1 string, p2 string) (r0 bool, r1 error) {
+	if m.DeleteRuleFunc != nil {
+		return m.DeleteRuleFunc(p0, p1, p2)
+	}
+	return
+}
+
+// EmailDomainUseCase is a fake ports.EmailDomainUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type EmailDomainUseCase struct {
+	ListRulesFunc  func(context.Context) ([]*domain.EmailDomainRule, error)
+	AddRuleFunc    func(context.Context, string, string, string) (*domain.EmailDomainRule, error)
+	RemoveRuleFunc func(context.Context, string, string, string) error
+	CheckEmailFunc func(context.Context, string) error
+}
+
+var _ ports.EmailDomainUseCase = (*EmailDomainUseCase)(nil)
+
+func (m *EmailDomainUseCase) ListRules(p0 context.Context) (r0 []*domain.EmailDomainRule, r1 error) {
+	if m.ListRulesFunc != nil {
+		return m.ListRulesFunc(p0)
+	}
+	return
+}
+
+func (m *EmailDomainUseCase) AddRule(p0 context.Context, p1 string, p2 string, p3 string) (r0 *domain.EmailDomainRule, r1 error) {
+	if m.AddRuleFunc != nil {
+		return m.AddRuleFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *EmailDomainUseCase) RemoveRule(p0 context.Context, p1 string, p2 string, p3 string) (r0 error) {
+	if m.RemoveRuleFunc != nil {
+		return m.RemoveRuleFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *EmailDomainUseCase) CheckEmail(p0 context.Context, p1 string) (r0 error) {
+	if m.CheckEmailFunc != nil {
+		return m.CheckEmailFunc(p0, p1)
+	}
+	return
+}
+
+// ExportUseCase is a fake ports.ExportUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type ExportUseCase struct {
+	ExportUsersFunc func(context.Context, string, *ports.UserExport, io.Writer) (int, error)
+}
+
+var _ ports.ExportUseCase = (*ExportUseCase)(nil)
+
+func (m *ExportUseCase) ExportUsers(p0 context.Context, p1 string, p2 *ports.UserExport, p3 io.Writer) (r0 int, r1 error) {
+	if m.ExportUsersFunc != nil {
+		return m.ExportUsersFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+// FileStorage is a fake ports.FileStorage; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type FileStorage struct {
+	SaveFunc func(context.Context, string, string, io.Reader) (string, error)
+}
+
+var _ ports.FileStorage = (*FileStorage)(nil)
+
+func (m *FileStorage) Save(p0 context.Context, p1 string, p2 string, p3 io.Reader) (r0 string, r1 error) {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+// PresignedFileStorage is a fake ports.PresignedFileStorage; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type PresignedFileStorage struct {
+	SaveFunc       func(context.Context, string, string, io.Reader) (string, error)
+	OpenFunc       func(context.Context, string) (io.ReadCloser, error)
+	PresignURLFunc func(context.Context, string, time.Time) (string, error)
+}
+
+var _ ports.PresignedFileStorage = (*PresignedFileStorage)(nil)
+
+func (m *PresignedFileStorage) Save(p0 context.Context, p1 string, p2 string, p3 io.Reader) (r0 string, r1 error) {
+	if m.SaveFunc != nil {
+		return m.SaveFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *PresignedFileStorage) Open(p0 context.Context, p1 string) (r0 io.ReadCloser, r1 error) {
+	if m.OpenFunc != nil {
+		return m.OpenFunc(p0, p1)
+	}
+	return
+}
+
+func (m *PresignedFileStorage) PresignURL(p0 context.Context, p1 string, p2 time.Time) (r0 string, r1 error) {
+	if m.PresignURLFunc != nil {
+		return m.PresignURLFunc(p0, p1, p2)
+	}
+	return
+}
+
+// Geocoder is a fake ports.Geocoder; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type Geocoder struct {
+	GeocodeFunc func(context.Context, domain.Address) (*domain.GeoPoint, error)
+}
+
+var _ ports.Geocoder = (*Geocoder)(nil)
+
+func (m *Geocoder) Geocode(p0 context.Context, p1 domain.Address) (r0 *domain.GeoPoint, r1 error) {
+	if m.GeocodeFunc != nil {
+		return m.GeocodeFunc(p0, p1)
+	}
+	return
+}
+
+// GeoIPResolver is a fake ports.GeoIPResolver; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type GeoIPResolver struct {
+	ResolveFunc func(context.Context, string) (*domain.IPLocation, error)
+}
+
+var _ ports.GeoIPResolver = (*GeoIPResolver)(nil)
+
+func (m *GeoIPResolver) Resolve(p0 context.Context, p1 string) (r0 *domain.IPLocation, r1 error) {
+	if m.ResolveFunc != nil {
+		return m.ResolveFunc(p0, p1)
+	}
+	return
+}
+
+// HealthChecker is a fake ports.HealthChecker; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type HealthChecker struct {
+	CheckHealthFunc func(context.Context) ports.DependencyHealth
+}
+
+var _ ports.HealthChecker = (*HealthChecker)(nil)
+
+func (m *HealthChecker) CheckHealth(p0 context.Context) (r0 ports.DependencyHealth) {
+	if m.CheckHealthFunc != nil {
+		return m.CheckHealthFunc(p0)
+	}
+	return
+}
+
+// ReadinessUseCase is a fake ports.ReadinessUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type ReadinessUseCase struct {
+	CheckReadinessFunc func(context.Context) *ports.ReadinessReport
+}
+
+var _ ports.ReadinessUseCase = (*ReadinessUseCase)(nil)
+
+func (m *ReadinessUseCase) CheckReadiness(p0 context.Context) (r0 *ports.ReadinessReport) {
+	if m.CheckReadinessFunc != nil {
+		return m.CheckReadinessFunc(p0)
+	}
+	return
+}
+
+// TokenVerifier is a fake ports.TokenVerifier; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type TokenVerifier struct {
+	VerifyFunc func(context.Context, string) (*ports.ExternalToken, error)
+}
+
+var _ ports.TokenVerifier = (*TokenVerifier)(nil)
+
+func (m *TokenVerifier) Verify(p0 context.Context, p1 string) (r0 *ports.ExternalToken, r1 error) {
+	if m.VerifyFunc != nil {
+		return m.VerifyFunc(p0, p1)
+	}
+	return
+}
+
+// ExternalAuthUseCase is a fake ports.ExternalAuthUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type ExternalAuthUseCase struct {
+	AuthenticateTokenFunc func(context.Context, string) (*domain.User, error)
+}
+
+var _ ports.ExternalAuthUseCase = (*ExternalAuthUseCase)(nil)
+
+func (m *ExternalAuthUseCase) AuthenticateToken(p0 context.Context, p1 string) (r0 *domain.User, r1 error) {
+	if m.AuthenticateTokenFunc != nil {
+		return m.AuthenticateTokenFunc(p0, p1)
+	}
+	return
+}
+
+// IdentityVerifier is a fake ports.IdentityVerifier; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type IdentityVerifier struct {
+	ProviderFunc     func() string
+	StartCheckFunc   func(context.Context, *ports.IdentityCheckRequest) (string, error)
+	ParseWebhookFunc func(context.Context, http.Header, []byte) (*ports.IdentityCheckResult, error)
+}
+
+var _ ports.IdentityVerifier = (*IdentityVerifier)(nil)
+
+func (m *IdentityVerifier) Provider() (r0 string) {
+	if m.ProviderFunc != nil {
+		return m.ProviderFunc()
+	}
+	return
+}
+
+func (m *IdentityVerifier) StartCheck(p0 context.Context, p1 *ports.IdentityCheckRequest) (r0 string, r1 error) {
+	if m.StartCheckFunc != nil {
+		return m.StartCheckFunc(p0, p1)
+	}
+	return
+}
+
+func (m *IdentityVerifier) ParseWebhook(p0 context.Context, p1 http.Header, p2 []byte) (r0 *ports.IdentityCheckResult, r1 error) {
+	if m.ParseWebhookFunc != nil {
+		return m.ParseWebhookFunc(p0, p1, p2)
+	}
+	return
+}
+
+// IPBackoff is a fake ports.IPBackoff; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type IPBackoff struct {
+	CheckFunc         func(context.Context, string) (time.Duration, error)
+	RecordFailureFunc func(context.Context, string) error
+	ListBlocksFunc    func(context.Context) ([]ports.IPBlock, error)
+	ClearBlockFunc    func(context.Context, string) (bool, error)
+	StatsFunc         func(context.Context) (*ports.IPBackoffStats, error)
+}
+
+var _ ports.IPBackoff = (*IPBackoff)(nil)
+
+func (m *IPBackoff) Check(p0 context.Context, p1 string) (r0 time.Duration, r1 error) {
+	if m.CheckFunc != nil {
+		return m.CheckFunc(p0, p1)
+	}
+	return
+}
+
+func (m *IPBackoff) RecordFailure(p0 context.Context, p1 string) (r0 error) {
+	if m.RecordFailureFunc != nil {
+		return m.RecordFailureFunc(p0, p1)
+	}
+	return
+}
+
+func (m *IPBackoff) ListBlocks(p0 context.Context) (r0 []ports.IPBlock, r1 error) {
+	if m.ListBlocksFunc != nil {
+		return m.ListBlocksFunc(p0)
+	}
+	return
+}
+
+func (m *IPBackoff) ClearBlock(p0 context.Context, p1 string) (r0 bool, r1 error) {
+	if m.ClearBlockFunc != nil {
+		return m.ClearBlockFunc(p0, p1)
+	}
+	return
+}
+
+func (m *IPBackoff) Stats(p0 context.Context) (r0 *ports.IPBackoffStats, r1 error) {
+	if m.StatsFunc != nil {
+		return m.StatsFunc(p0)
+	}
+	return
+}
+
+// JobRepository is a fake ports.JobRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type JobRepository struct {
+	CreateJobFunc func(context.Context, *domain.Job) error
+	ClaimJobFunc  func(context.Context, []string, time.Time, time.Time) (*domain.Job, error)
+	UpdateJobFunc func(context.Context, *domain.Job) error
+	ListJobsFunc  func(context.Context, *ports.JobQuery) (*ports.JobQueryResult, error)
+	GetJobFunc    func(context.Context, string) (*domain.Job, error)
+	RetryJobFunc  func(context.Context, *domain.Job) (bool, error)
+}
+
+var _ ports.JobRepository = (*JobRepository)(nil)
+
+func (m *JobRepository) CreateJob(p0 context.Context, p1 *domain.Job) (r0 error) {
+	if m.CreateJobFunc != nil {
+		return m.CreateJobFunc(p0, p1)
+	}
+	return
+}
+
+func (m *JobRepository) ClaimJob(p0 context.Context, p1 []string, p2 time.Time, p3 time.Time) (r0 *domain.Job, r1 error) {
+	if m.ClaimJobFunc != nil {
+		return m.ClaimJobFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *JobRepository) UpdateJob(p0 context.Context, p1 *domain.Job) (r0 error) {
+	if m.UpdateJobFunc != nil {
+		return m.UpdateJobFunc(p0, p1)
+	}
+	return
+}
+
+func (m *JobRepository) ListJobs(p0 context.Context, p1 *ports.JobQuery) (r0 *ports.JobQueryResult, r1 error) {
+	if m.ListJobsFunc != nil {
+		return m.ListJobsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *JobRepository) GetJob(p0 context.Context, p1 string) (r0 *domain.Job, r1 error) {
+	if m.GetJobFunc != nil {
+		return m.GetJobFunc(p0, p1)
+	}
+	return
+}
+
+func (m *JobRepository) RetryJob(p0 context.Context, p1 *domain.Job) (r0 bool, r1 error) {
+	if m.RetryJobFunc != nil {
+		return m.RetryJobFunc(p0, p1)
+	}
+	return
+}
+
+// JobQueue is a fake ports.JobQueue; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type JobQueue struct {
+	EnqueueFunc func(context.Context, string, map[string]string) (*domain.Job, error)
+}
+
+var _ ports.JobQueue = (*JobQueue)(nil)
+
+func (m *JobQueue) Enqueue(p0 context.Context, p1 string, p2 map[string]string) (r0 *domain.Job, r1 error) {
+	if m.EnqueueFunc != nil {
+		return m.EnqueueFunc(p0, p1, p2)
+	}
+	return
+}
+
+// JobUseCase is a fake ports.JobUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type JobUseCase struct {
+	EnqueueFunc  func(context.Context, string, map[string]string) (*domain.Job, error)
+	ListJobsFunc func(context.Context, *ports.JobQuery) (*ports.JobQueryResult, error)
+	GetJobFunc   func(context.Context, string) (*domain.Job, error)
+	RetryJobFunc func(context.Context, string, string) (*domain.Job, error)
+}
+
+var _ ports.JobUseCase = (*JobUseCase)(nil)
+
+func (m *JobUseCase) Enqueue(p0 context.Context, p1 string, p2 map[string]string) (r0 *domain.Job, r1 error) {
+	if m.EnqueueFunc != nil {
+		return m.EnqueueFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *JobUseCase) ListJobs(p0 context.Context, p1 *ports.JobQuery) (r0 *ports.JobQueryResult, r1 error) {
+	if m.ListJobsFunc != nil {
+		return m.ListJobsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *JobUseCase) GetJob(p0 context.Context, p1 string) (r0 *domain.Job, r1 error) {
+	if m.GetJobFunc != nil {
+		return m.GetJobFunc(p0, p1)
+	}
+	return
+}
+
+func (m *JobUseCase) RetryJob(p0 context.Context, p1 string, p2 string) (r0 *domain.Job, r1 error) {
+	if m.RetryJobFunc != nil {
+		return m.RetryJobFunc(p0, p1, p2)
+	}
+	return
+}
+
+// LoginEventRepository is a fake ports.LoginEventRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type LoginEventRepository struct {
+	CreateLoginEventFunc           func(context.Context, *domain.LoginEvent) error
+	LastLoginEventFunc             func(context.Context, string, string) (*domain.LoginEvent, error)
+	ListLoginEventsFunc            func(context.Context, string, int, int) (*ports.LoginHistory, error)
+	ListNewDeviceLoginsFunc        func(context.Context, string, time.Time, int) ([]*domain.LoginEvent, error)
+	GetLoginEventByReportTokenFunc func(context.Context, string) (*domain.LoginEvent, error)
+	SetLoginEventReportedFunc      func(context.Context, string, time.Time) error
+}
+
+var _ ports.LoginEventRepository = (*LoginEventRepository)(nil)
+
+func (m *LoginEventRepository) CreateLoginEvent(p0 context.Context, p1 *domain.LoginEvent) (r0 error) {
+	if m.CreateLoginEventFunc != nil {
+		return m.CreateLoginEventFunc(p0, p1)
+	}
+	return
+}
+
+func (m *LoginEventRepository) LastLoginEvent(p0 context.Context, p1 string, p2 string) (r0 *domain.LoginEvent, r1 error) {
+	if m.LastLoginEventFunc != nil {
+		return m.LastLoginEventFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *LoginEventRepository) ListLoginEvents(p0 context.Context, p1 string, p2 int, p3 int) (r0 *ports.LoginHistory, r1 error) {
+	if m.ListLoginEventsFunc != nil {
+		return m.ListLoginEventsFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *LoginEventRepository) ListNewDeviceLogins(p0 context.Context, p1 string, p2 time.Time, p3 int) (r0 []*domain.LoginEvent, r1 error) {
+	if m.ListNewDeviceLoginsFunc != nil {
+		return m.ListNewDeviceLoginsFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *LoginEventRepository) GetLoginEventByReportToken(p0 context.Context, p1 string) (r0 *domain.LoginEvent, r1 error) {
+	if m.GetLoginEventByReportTokenFunc != nil {
+		return m.GetLoginEventByReportTokenFunc(p0, p1)
+	}
+	return
+}
+
+func (m *LoginEventRepository) SetLoginEventReported(p0 context.Context, p1 string, p2 time.Time) (r0 error) {
+	if m.SetLoginEventReportedFunc != nil {
+		return m.SetLoginEventReportedFunc(p0, p1, p2)
+	}
+	return
+}
+
+// LoginEventUseCase is a fake ports.LoginEventUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type LoginEventUseCase struct {
+	AssessLoginFunc      func(context.Context, *domain.User, string, string) ([]domain.LoginAnomaly, error)
+	RecordLoginFunc      func(context.Context, *domain.User, string, string) (*domain.LoginEvent, error)
+	ListLoginHistoryFunc func(context.Context, string, int, int) (*ports.LoginHistory, error)
+	ReportLoginFunc      func(context.Context, string) error
+}
+
+var _ ports.LoginEventUseCase = (*LoginEventUseCase)(nil)
+
+func (m *LoginEventUseCase) AssessLogin(p0 context.Context, p1 *domain.User, p2 string, p3 string) (r0 []domain.LoginAnomaly, r1 error) {
+	if m.AssessLoginFunc != nil {
+		return m.AssessLoginFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *LoginEventUseCase) RecordLogin(p0 context.Context, p1 *domain.User, p2 string, p3 string) (r0 *domain.LoginEvent, r1 error) {
+	if m.RecordLoginFunc != nil {
+		return m.RecordLoginFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *LoginEventUseCase) ListLoginHistory(p0 context.Context, p1 string, p2 int, p3 int) (r0 *ports.LoginHistory, r1 error) {
+	if m.ListLoginHistoryFunc != nil {
+		return m.ListLoginHistoryFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *LoginEventUseCase) ReportLogin(p0 context.Context, p1 string) (r0 error) {
+	if m.ReportLoginFunc != nil {
+		return m.ReportLoginFunc(p0, p1)
+	}
+	return
+}
+
+// Mailer is a fake ports.Mailer; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type Mailer struct {
+	SendFunc func(context.Context, ports.EmailMessage) error
+}
+
+var _ ports.Mailer = (*Mailer)(nil)
+
+func (m *Mailer) Send(p0 context.Context, p1 ports.EmailMessage) (r0 error) {
+	if m.SendFunc != nil {
+		return m.SendFunc(p0, p1)
+	}
+	return
+}
+
+// MFAUseCase is a fake ports.MFAUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type MFAUseCase struct {
+	ListFactorsFunc          func(context.Context, string) ([]domain.MFAFactor, error)
+	AddFactorFunc            func(context.Context, string, string, string) (*domain.MFAEnrollment, error)
+	ConfirmFactorFunc        func(context.Context, string, string, string) (*domain.MFAFactor, error)
+	RemoveFactorFunc         func(context.Context, string, string) error
+	SendCodeFunc             func(context.Context, string, string) (time.Time, error)
+	VerifyFunc               func(context.Context, string, string, string) error
+	TrustDeviceFunc          func(context.Context, string, string, string) (*domain.TrustedDevice, string, error)
+	UseTrustedDeviceFunc     func(context.Context, *domain.User, string) (bool, error)
+	ListTrustedDevicesFunc   func(context.Context, string) ([]domain.TrustedDevice, error)
+	RevokeTrustedDeviceFunc  func(context.Context, string, string) error
+	RevokeTrustedDevicesFunc func(context.Context, string) (int, error)
+}
+
+var _ ports.MFAUseCase = (*MFAUseCase)(nil)
+
+func (m *MFAUseCase) ListFactors(p0 context.Context, p1 string) (r0 []domain.MFAFactor, r1 error) {
+	if m.ListFactorsFunc != nil {
+		return m.ListFactorsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *MFAUseCase) AddFactor(p0 context.Context, p1 string, p2 string, p3 string) (r0 *domain.MFAEnrollment, r1 error) {
+	if m.AddFactorFunc != nil {
+		return m.AddFactorFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *MFAUseCase) ConfirmFactor(p0 context.Context, p1 string, p2 string, p3 string) (r0 *domain.MFAFactor, r1 error) {
+	if m.ConfirmFactorFunc != nil {
+		return m.ConfirmFactorFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *MFAUseCase) RemoveFactor(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.RemoveFactorFunc != nil {
+		return m.RemoveFactorFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *MFAUseCase) SendCode(p0 context.Context, p1 string, p2 string) (r0 time.Time, r1 error) {
+	if m.SendCodeFunc != nil {
+		return m.SendCodeFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *MFAUseCase) Verify(p0 context.Context, p1 string, p2 string, p3 string) (r0 error) {
+	if m.VerifyFunc != nil {
+		return m.VerifyFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *MFAUseCase) TrustDevice(p0 context.Context, p1 string, p2 string, p3 string) (r0 *domain.TrustedDevice, r1 string, r2 error) {
+	if m.TrustDeviceFunc != nil {
+		return m.TrustDeviceFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *MFAUseCase) UseTrustedDevice(p0 context.Context, p1 *domain.User, p2 string) (r0 bool, r1 error) {
+	if m.UseTrustedDeviceFunc != nil {
+		return m.UseTrustedDeviceFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *MFAUseCase) ListTrustedDevices(p0 context.Context, p1 string) (r0 []domain.TrustedDevice, r1 error) {
+	if m.ListTrustedDevicesFunc != nil {
+		return m.ListTrustedDevicesFunc(p0, p1)
+	}
+	return
+}
+
+func (m *MFAUseCase) RevokeTrustedDevice(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.RevokeTrustedDeviceFunc != nil {
+		return m.RevokeTrustedDeviceFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *MFAUseCase) RevokeTrustedDevices(p0 context.Context, p1 string) (r0 int, r1 error) {
+	if m.RevokeTrustedDevicesFunc != nil {
+		return m.RevokeTrustedDevicesFunc(p0, p1)
+	}
+	return
+}
+
+// OAuthClientRepository is a fake ports.OAuthClientRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type OAuthClientRepository struct {
+	CreateClientFunc  func(context.Context, *domain.OAuthClient) error
+	GetClientByIDFunc func(context.Context, string) (*domain.OAuthClient, error)
+	ListClientsFunc   func(context.Context) ([]*domain.OAuthClient, error)
+	DeleteClientFunc  func(context.Context, string) (bool, error)
+}
+
+var _ ports.OAuthClientRepository = (*OAuthClientRepository)(nil)
+
+func (m *OAuthClientRepository) CreateClient(p0 context.Context, p1 *domain.OAuthClient) (r0 error) {
+	if m.CreateClientFunc != nil {
+		return m.CreateClientFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OAuthClientRepository) GetClientByID(p0 context.Context, p1 string) (r0 *domain.OAuthClient, r1 error) {
+	if m.GetClientByIDFunc != nil {
+		return m.GetClientByIDFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OAuthClientRepository) ListClients(p0 context.Context) (r0 []*domain.OAuthClient, r1 error) {
+	if m.ListClientsFunc != nil {
+		return m.ListClientsFunc(p0)
+	}
+	return
+}
+
+func (m *OAuthClientRepository) DeleteClient(p0 context.Context, p1 string) (r0 bool, r1 error) {
+	if m.DeleteClientFunc != nil {
+		return m.DeleteClientFunc(p0, p1)
+	}
+	return
+}
+
+// OAuthClientUseCase is a fake ports.OAuthClientUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type OAuthClientUseCase struct {
+	RegisterClientFunc     func(context.Context, string, string, []string) (*domain.OAuthClient, string, error)
+	GetClientFunc          func(context.Context, string) (*domain.OAuthClient, error)
+	ListClientsFunc        func(context.Context) ([]*domain.OAuthClient, error)
+	DeleteClientFunc       func(context.Context, string, string) error
+	AuthenticateClientFunc func(context.Context, string, string) (*domain.OAuthClient, error)
+}
+
+var _ ports.OAuthClientUseCase = (*OAuthClientUseCase)(nil)
+
+func (m *OAuthClientUseCase) RegisterClient(p0 context.Context, p1 string, p2 string, p3 []string) (r0 *domain.OAuthClient, r1 string, r2 error) {
+	if m.RegisterClientFunc != nil {
+		return m.RegisterClientFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *OAuthClientUseCase) GetClient(p0 context.Context, p1 string) (r0 *domain.OAuthClient, r1 error) {
+	if m.GetClientFunc != nil {
+		return m.GetClientFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OAuthClientUseCase) ListClients(p0 context.Context) (r0 []*domain.OAuthClient, r1 error) {
+	if m.ListClientsFunc != nil {
+		return m.ListClientsFunc(p0)
+	}
+	return
+}
+
+func (m *OAuthClientUseCase) DeleteClient(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.DeleteClientFunc != nil {
+		return m.DeleteClientFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *OAuthClientUseCase) AuthenticateClient(p0 context.Context, p1 string, p2 string) (r0 *domain.OAuthClient, r1 error) {
+	if m.AuthenticateClientFunc != nil {
+		return m.AuthenticateClientFunc(p0, p1, p2)
+	}
+	return
+}
+
+// OrganizationRepository is a fake ports.OrganizationRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type OrganizationRepository struct {
+	CreateOrganizationFunc    func(context.Context, *domain.Organization) error
+	GetOrganizationByIDFunc   func(context.Context, string) (*domain.Organization, error)
+	GetOrganizationBySlugFunc func(context.Context, string) (*domain.Organization, error)
+	UpdateOrganizationFunc    func(context.Context, *domain.Organization) error
+	DeleteOrganizationFunc    func(context.Context, string) error
+	CreateMembershipFunc      func(context.Context, *domain.Membership) error
+	GetMembershipFunc         func(context.Context, string, string) (*domain.Membership, error)
+	ListMembersFunc           func(context.Context, string) ([]*domain.Membership, error)
+	ListUserMembershipsFunc   func(context.Context, string) ([]*domain.Membership, error)
+	UpdateMembershipRoleFunc  func(context.Context, string, string, string) error
+	CountMembersWithRoleFunc  func(context.Context, string, string) (int64, error)
+	DeleteMembershipFunc      func(context.Context, string, string) error
+}
+
+var _ ports.OrganizationRepository = (*OrganizationRepository)(nil)
+
+func (m *OrganizationRepository) CreateOrganization(p0 context.Context, p1 *domain.Organization) (r0 error) {
+	if m.CreateOrganizationFunc != nil {
+		return m.CreateOrganizationFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OrganizationRepository) GetOrganizationByID(p0 context.Context, p1 string) (r0 *domain.Organization, r1 error) {
+	if m.GetOrganizationByIDFunc != nil {
+		return m.GetOrganizationByIDFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OrganizationRepository) GetOrganizationBySlug(p0 context.Context, p1 string) (r0 *domain.Organization, r1 error) {
+	if m.GetOrganizationBySlugFunc != nil {
+		return m.GetOrganizationBySlugFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OrganizationRepository) UpdateOrganization(p0 context.Context, p1 *domain.Organization) (r0 error) {
+	if m.UpdateOrganizationFunc != nil {
+		return m.UpdateOrganizationFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OrganizationRepository) DeleteOrganization(p0 context.Context, p1 string) (r0 error) {
+	if m.DeleteOrganizationFunc != nil {
+		return m.DeleteOrganizationFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OrganizationRepository) CreateMembership(p0 context.Context, p1 *domain.Membership) (r0 error) {
+	if m.CreateMembershipFunc != nil {
+		return m.CreateMembershipFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OrganizationRepository) GetMembership(p0 context.Context, p1 string, p2 string) (r0 *domain.Membership, r1 error) {
+	if m.GetMembershipFunc != nil {
+		return m.GetMembershipFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *OrganizationRepository) ListMembers(p0 context.Context, p1 string) (r0 []*domain.Membership, r1 error) {
+	if m.ListMembersFunc != nil {
+		return m.ListMembersFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OrganizationRepository) ListUserMemberships(p0 context.Context, p1 string) (r0 []*domain.Membership, r1 error) {
+	if m.ListUserMembershipsFunc != nil {
+		return m.ListUserMembershipsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *OrganizationRepository) UpdateMembershipRole(p0 context.Context, p1 string, p2 string, p3 string) (r0 error) {
+	if m.UpdateMembershipRoleFunc != nil {
+		return m.UpdateMembershipRoleFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *OrganizationRepository) CountMembersWithRole(p0 context.Context, p1 string, p2 string) (r0 int64, r1 error) {
+	if m.CountMembersWithRoleFunc != nil {
+		return m.CountMembersWithRoleFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *OrganizationRepository) DeleteMembership(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.DeleteMembershipFunc != nil {
+		return m.DeleteMembershipFunc(p0, p1, p2)
+	}
+	return
+}
+
+// OrganizationUseCase is a fake ports.OrganizationUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type OrganizationUseCase struct {
+	CreateOrganizationFunc  func(context.Context, string, string, string) (*domain.Organization, error)
+	GetOrganizationFunc     func(context.Context, string, string) (*domain.Organization, error)
+	UpdateOrganizationFunc  func(context.Context, string, string, string) (*domain.Organization, error)
+	DeleteOrganizationFunc  func(context.Context, string, string) error
+	ListMembersFunc         func(context.Context, string, string) ([]*domain.Membership, error)
+	SetMemberFunc           func(context.Context, string, string, string, string) (*domain.Membership, error)
+	RemoveMemberFunc        func(context.Context, string, string, string) error
+	ListUserMembershipsFunc func(context.Context, string) ([]*domain.Membership, error)
+}
+
+var _ ports.OrganizationUseCase = (*OrganizationUseCase)(nil)
+
+func (m *OrganizationUseCase) CreateOrganization(p0 context.Context, p1 string, p2 string, p3 string) (r0 *domain.Organization, r1 error) {
+	if m.CreateOrganizationFunc != nil {
+		return m.CreateOrganizationFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *OrganizationUseCase) GetOrganization(p0 context.Context, p1 string, p2 string) (r0 *domain.Organization, r1 error) {
+	if m.GetOrganizationFunc != nil {
+		return m.GetOrganizationFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *OrganizationUseCase) UpdateOrganization(p0 context.Context, p1 string, p2 string, p3 string) (r0 *domain.Organization, r1 error) {
+	if m.UpdateOrganizationFunc != nil {
+		return m.UpdateOrganizationFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *OrganizationUseCase) DeleteOrganization(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.DeleteOrganizationFunc != nil {
+		return m.DeleteOrganizationFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *OrganizationUseCase) ListMembers(p0 context.Context, p1 string, p2 string) (r0 []*domain.Membership, r1 error) {
+	if m.ListMembersFunc != nil {
+		return m.ListMembersFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *OrganizationUseCase) SetMember(p0 context.Context, p1 string, p2 string, p3 string, p4 string) (r0 *domain.Membership, r1 error) {
+	if m.SetMemberFunc != nil {
+		return m.SetMemberFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+func (m *OrganizationUseCase) RemoveMember(p0 context.Context, p1 string, p2 string, p3 string) (r0 error) {
+	if m.RemoveMemberFunc != nil {
+		return m.RemoveMemberFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *OrganizationUseCase) ListUserMemberships(p0 context.Context, p1 string) (r0 []*domain.Membership, r1 error) {
+	if m.ListUserMembershipsFunc != nil {
+		return m.ListUserMembershipsFunc(p0, p1)
+	}
+	return
+}
+
+// PhoneVerificationUseCase is a fake ports.PhoneVerificationUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type PhoneVerificationUseCase struct {
+	SendCodeFunc    func(context.Context, string) (time.Time, error)
+	ConfirmCodeFunc func(context.Context, string, string) (*domain.User, error)
+}
+
+var _ ports.PhoneVerificationUseCase = (*PhoneVerificationUseCase)(nil)
+
+func (m *PhoneVerificationUseCase) SendCode(p0 context.Context, p1 string) (r0 time.Time, r1 error) {
+	if m.SendCodeFunc != nil {
+		return m.SendCodeFunc(p0, p1)
+	}
+	return
+}
+
+func (m *PhoneVerificationUseCase) ConfirmCode(p0 context.Context, p1 string, p2 string) (r0 *domain.User, r1 error) {
+	if m.ConfirmCodeFunc != nil {
+		return m.ConfirmCodeFunc(p0, p1, p2)
+	}
+	return
+}
+
+// RateLimiter is a fake ports.RateLimiter; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type RateLimiter struct {
+	AllowFunc func(context.Context, string, ports.RateLimit) (*ports.RateLimitResult, error)
+}
+
+var _ ports.RateLimiter = (*RateLimiter)(nil)
+
+func (m *RateLimiter) Allow(p0 context.Context, p1 string, p2 ports.RateLimit) (r0 *ports.RateLimitResult, r1 error) {
+	if m.AllowFunc != nil {
+		return m.AllowFunc(p0, p1, p2)
+	}
+	return
+}
+
+// RelationshipRepository is a fake ports.RelationshipRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type RelationshipRepository struct {
+	CreateRelationshipFunc func(context.Context, *domain.Relationship) error
+	GetRelationshipFunc    func(context.Context, string, string) (*domain.Relationship, error)
+	DeleteRelationshipFunc func(context.Context, string, string) (bool, error)
+	ListFollowersFunc      func(context.Context, *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error)
+	ListFollowingFunc      func(context.Context, *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error)
+	CountRelationshipsFunc func(context.Context, string) (*domain.RelationshipCounts, error)
+	CreateBlockFunc        func(context.Context, *domain.Block) error
+	GetBlockFunc           func(context.Context, string, string) (*domain.Block, error)
+	DeleteBlockFunc        func(context.Context, string, string) (bool, error)
+	ListBlocksFunc         func(context.Context, *ports.RelationshipQuery) (*ports.BlockQueryResult, error)
+	ListBlockersFunc       func(context.Context, string, []string) ([]string, error)
+}
+
+var _ ports.RelationshipRepository = (*RelationshipRepository)(nil)
+
+func (m *RelationshipRepository) CreateRelationship(p0 context.Context, p1 *domain.Relationship) (r0 error) {
+	if m.CreateRelationshipFunc != nil {
+		return m.CreateRelationshipFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RelationshipRepository) GetRelationship(p0 context.Context, p1 string, p2 string) (r0 *domain.Relationship, r1 error) {
+	if m.GetRelationshipFunc != nil {
+		return m.GetRelationshipFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RelationshipRepository) DeleteRelationship(p0 context.Context, p1 string, p2 string) (r0 bool, r1 error) {
+	if m.DeleteRelationshipFunc != nil {
+		return m.DeleteRelationshipFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RelationshipRepository) ListFollowers(p0 context.Context, p1 *ports.RelationshipQuery) (r0 *ports.RelationshipQueryResult, r1 error) {
+	if m.ListFollowersFunc != nil {
+		return m.ListFollowersFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RelationshipRepository) ListFollowing(p0 context.Context, p1 *ports.RelationshipQuery) (r0 *ports.RelationshipQueryResult, r1 error) {
+	if m.ListFollowingFunc != nil {
+		return m.ListFollowingFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RelationshipRepository) CountRelationships(p0 context.Context, p1 string) (r0 *domain.RelationshipCounts, r1 error) {
+	if m.CountRelationshipsFunc != nil {
+		return m.CountRelationshipsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RelationshipRepository) CreateBlock(p0 context.Context, p1 *domain.Block) (r0 error) {
+	if m.CreateBlockFunc != nil {
+		return m.CreateBlockFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RelationshipRepository) GetBlock(p0 context.Context, p1 string, p2 string) (r0 *domain.Block, r1 error) {
+	if m.GetBlockFunc != nil {
+		return m.GetBlockFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RelationshipRepository) DeleteBlock(p0 context.Context, p1 string, p2 string) (r0 bool, r1 error) {
+	if m.DeleteBlockFunc != nil {
+		return m.DeleteBlockFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RelationshipRepository) ListBlocks(p0 context.Context, p1 *ports.RelationshipQuery) (r0 *ports.BlockQueryResult, r1 error) {
+	if m.ListBlocksFunc != nil {
+		return m.ListBlocksFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RelationshipRepository) ListBlockers(p0 context.Context, p1 string, p2 []string) (r0 []string, r1 error) {
+	if m.ListBlockersFunc != nil {
+		return m.ListBlockersFunc(p0, p1, p2)
+	}
+	return
+}
+
+// RelationshipUseCase is a fake ports.RelationshipUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type RelationshipUseCase struct {
+	FollowFunc             func(context.Context, string, string) (*domain.Relationship, error)
+	UnfollowFunc           func(context.Context, string, string) error
+	ListFollowersFunc      func(context.Context, *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error)
+	ListFollowingFunc      func(context.Context, *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error)
+	CountRelationshipsFunc func(context.Context, string) (*domain.RelationshipCounts, error)
+	BlockFunc              func(context.Context, string, string) (*domain.Block, error)
+	UnblockFunc            func(context.Context, string, string) error
+	ListBlocksFunc         func(context.Context, *ports.RelationshipQuery) (*ports.BlockQueryResult, error)
+	ListBlockersFunc       func(context.Context, string, []string) ([]string, error)
+}
+
+var _ ports.RelationshipUseCase = (*RelationshipUseCase)(nil)
+
+func (m *RelationshipUseCase) Follow(p0 context.Context, p1 string, p2 string) (r0 *domain.Relationship, r1 error) {
+	if m.FollowFunc != nil {
+		return m.FollowFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RelationshipUseCase) Unfollow(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.UnfollowFunc != nil {
+		return m.UnfollowFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RelationshipUseCase) ListFollowers(p0 context.Context, p1 *ports.RelationshipQuery) (r0 *ports.RelationshipQueryResult, r1 error) {
+	if m.ListFollowersFunc != nil {
+		return m.ListFollowersFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RelationshipUseCase) ListFollowing(p0 context.Context, p1 *ports.RelationshipQuery) (r0 *ports.RelationshipQueryResult, r1 error) {
+	if m.ListFollowingFunc != nil {
+		return m.ListFollowingFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RelationshipUseCase) CountRelationships(p0 context.Context, p1 string) (r0 *domain.RelationshipCounts, r1 error) {
+	if m.CountRelationshipsFunc != nil {
+		return m.CountRelationshipsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RelationshipUseCase) Block(p0 context.Context, p1 string, p2 string) (r0 *domain.Block, r1 error) {
+	if m.BlockFunc != nil {
+		return m.BlockFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RelationshipUseCase) Unblock(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.UnblockFunc != nil {
+		return m.UnblockFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RelationshipUseCase) ListBlocks(p0 context.Context, p1 *ports.RelationshipQuery) (r0 *ports.BlockQueryResult, r1 error) {
+	if m.ListBlocksFunc != nil {
+		return m.ListBlocksFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RelationshipUseCase) ListBlockers(p0 context.Context, p1 string, p2 []string) (r0 []string, r1 error) {
+	if m.ListBlockersFunc != nil {
+		return m.ListBlockersFunc(p0, p1, p2)
+	}
+	return
+}
+
+// RetentionRepository is a fake ports.RetentionRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type RetentionRepository struct {
+	DeleteUsersFunc            func(context.Context, time.Time, bool) (int64, error)
+	AnonymizeUsersFunc         func(context.Context, time.Time, bool) (int64, error)
+	DeleteLoginEventsFunc      func(context.Context, time.Time, bool) (int64, error)
+	ClearLoginReportTokensFunc func(context.Context, time.Time, bool) (int64, error)
+}
+
+var _ ports.RetentionRepository = (*RetentionRepository)(nil)
+
+func (m *RetentionRepository) DeleteUsers(p0 context.Context, p1 time.Time, p2 bool) (r0 int64, r1 error) {
+	if m.DeleteUsersFunc != nil {
+		return m.DeleteUsersFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RetentionRepository) AnonymizeUsers(p0 context.Context, p1 time.Time, p2 bool) (r0 int64, r1 error) {
+	if m.AnonymizeUsersFunc != nil {
+		return m.AnonymizeUsersFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RetentionRepository) DeleteLoginEvents(p0 context.Context, p1 time.Time, p2 bool) (r0 int64, r1 error) {
+	if m.DeleteLoginEventsFunc != nil {
+		return m.DeleteLoginEventsFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RetentionRepository) ClearLoginReportTokens(p0 context.Context, p1 time.Time, p2 bool) (r0 int64, r1 error) {
+	if m.ClearLoginReportTokensFunc != nil {
+		return m.ClearLoginReportTokensFunc(p0, p1, p2)
+	}
+	return
+}
+
+// RetentionUseCase is a fake ports.RetentionUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type RetentionUseCase struct {
+	PurgeFunc func(context.Context, bool) (*ports.RetentionReport, error)
+}
+
+var _ ports.RetentionUseCase = (*RetentionUseCase)(nil)
+
+func (m *RetentionUseCase) Purge(p0 context.Context, p1 bool) (r0 *ports.RetentionReport, r1 error) {
+	if m.PurgeFunc != nil {
+		return m.PurgeFunc(p0, p1)
+	}
+	return
+}
+
+// RoleRepository is a fake ports.RoleRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type RoleRepository struct {
+	CreateRoleFunc      func(context.Context, *domain.Role) error
+	GetRoleByNameFunc   func(context.Context, string) (*domain.Role, error)
+	GetRolesByNamesFunc func(context.Context, []string) ([]*domain.Role, error)
+	ListRolesFunc       func(context.Context) ([]*domain.Role, error)
+	UpdateRoleFunc      func(context.Context, *domain.Role) error
+	DeleteRoleFunc      func(context.Context, string) error
+}
+
+var _ ports.RoleRepository = (*RoleRepository)(nil)
+
+func (m *RoleRepository) CreateRole(p0 context.Context, p1 *domain.Role) (r0 error) {
+	if m.CreateRoleFunc != nil {
+		return m.CreateRoleFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RoleRepository) GetRoleByName(p0 context.Context, p1 string) (r0 *domain.Role, r1 error) {
+	if m.GetRoleByNameFunc != nil {
+		return m.GetRoleByNameFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RoleRepository) GetRolesByNames(p0 context.Context, p1 []string) (r0 []*domain.Role, r1 error) {
+	if m.GetRolesByNamesFunc != nil {
+		return m.GetRolesByNamesFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RoleRepository) ListRoles(p0 context.Context) (r0 []*domain.Role, r1 error) {
+	if m.ListRolesFunc != nil {
+		return m.ListRolesFunc(p0)
+	}
+	return
+}
+
+func (m *RoleRepository) UpdateRole(p0 context.Context, p1 *domain.Role) (r0 error) {
+	if m.UpdateRoleFunc != nil {
+		return m.UpdateRoleFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RoleRepository) DeleteRole(p0 context.Context, p1 string) (r0 error) {
+	if m.DeleteRoleFunc != nil {
+		return m.DeleteRoleFunc(p0, p1)
+	}
+	return
+}
+
+// RoleUseCase is a fake ports.RoleUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type RoleUseCase struct {
+	ListRolesFunc     func(context.Context) ([]*domain.Role, error)
+	GetRoleFunc       func(context.Context, string) (*domain.Role, error)
+	CreateRoleFunc    func(context.Context, string, string, []string) (*domain.Role, error)
+	UpdateRoleFunc    func(context.Context, string, *string, []string) (*domain.Role, error)
+	DeleteRoleFunc    func(context.Context, string) error
+	AssignRoleFunc    func(context.Context, string, string) ([]string, error)
+	UnassignRoleFunc  func(context.Context, string, string) ([]string, error)
+	HasPermissionFunc func(context.Context, []string, string) (bool, error)
+	CoversRolesFunc   func(context.Context, []string, []string) (bool, error)
+}
+
+var _ ports.RoleUseCase = (*RoleUseCase)(nil)
+
+func (m *RoleUseCase) ListRoles(p0 context.Context) (r0 []*domain.Role, r1 error) {
+	if m.ListRolesFunc != nil {
+		return m.ListRolesFunc(p0)
+	}
+	return
+}
+
+func (m *RoleUseCase) GetRole(p0 context.Context, p1 string) (r0 *domain.Role, r1 error) {
+	if m.GetRoleFunc != nil {
+		return m.GetRoleFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RoleUseCase) CreateRole(p0 context.Context, p1 string, p2 string, p3 []string) (r0 *domain.Role, r1 error) {
+	if m.CreateRoleFunc != nil {
+		return m.CreateRoleFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *RoleUseCase) UpdateRole(p0 context.Context, p1 string, p2 *string, p3 []string) (r0 *domain.Role, r1 error) {
+	if m.UpdateRoleFunc != nil {
+		return m.UpdateRoleFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *RoleUseCase) DeleteRole(p0 context.Context, p1 string) (r0 error) {
+	if m.DeleteRoleFunc != nil {
+		return m.DeleteRoleFunc(p0, p1)
+	}
+	return
+}
+
+func (m *RoleUseCase) AssignRole(p0 context.Context, p1 string, p2 string) (r0 []string, r1 error) {
+	if m.AssignRoleFunc != nil {
+		return m.AssignRoleFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RoleUseCase) UnassignRole(p0 context.Context, p1 string, p2 string) (r0 []string, r1 error) {
+	if m.UnassignRoleFunc != nil {
+		return m.UnassignRoleFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RoleUseCase) HasPermission(p0 context.Context, p1 []string, p2 string) (r0 bool, r1 error) {
+	if m.HasPermissionFunc != nil {
+		return m.HasPermissionFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *RoleUseCase) CoversRoles(p0 context.Context, p1 []string, p2 []string) (r0 bool, r1 error) {
+	if m.CoversRolesFunc != nil {
+		return m.CoversRolesFunc(p0, p1, p2)
+	}
+	return
+}
+
+// ConfigUseCase is a fake ports.ConfigUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type ConfigUseCase struct {
+	ConfigFunc       func() *ports.RuntimeConfig
+	UpdateConfigFunc func(context.Context, string, *ports.RuntimeConfigUpdate) (*ports.RuntimeConfig, error)
+	ReloadConfigFunc func(context.Context, *ports.RuntimeConfigUpdate) (*ports.RuntimeConfig, error)
+}
+
+var _ ports.ConfigUseCase = (*ConfigUseCase)(nil)
+
+func (m *ConfigUseCase) Config() (r0 *ports.RuntimeConfig) {
+	if m.ConfigFunc != nil {
+		return m.ConfigFunc()
+	}
+	return
+}
+
+func (m *ConfigUseCase) UpdateConfig(p0 context.Context, p1 string, p2 *ports.RuntimeConfigUpdate) (r0 *ports.RuntimeConfig, r1 error) {
+	if m.UpdateConfigFunc != nil {
+		return m.UpdateConfigFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *ConfigUseCase) ReloadConfig(p0 context.Context, p1 *ports.RuntimeConfigUpdate) (r0 *ports.RuntimeConfig, r1 error) {
+	if m.ReloadConfigFunc != nil {
+		return m.ReloadConfigFunc(p0, p1)
+	}
+	return
+}
+
+// SchedulerReporter is a fake ports.SchedulerReporter; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type SchedulerReporter struct {
+	ScheduledTasksFunc func() []ports.ScheduledTaskStats
+}
+
+var _ ports.SchedulerReporter = (*SchedulerReporter)(nil)
+
+func (m *SchedulerReporter) ScheduledTasks() (r0 []ports.ScheduledTaskStats) {
+	if m.ScheduledTasksFunc != nil {
+		return m.ScheduledTasksFunc()
+	}
+	return
+}
+
+// UserProjection is a fake ports.UserProjection; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type UserProjection struct {
+	SaveUserFunc   func(context.Context, *domain.User) error
+	RemoveUserFunc func(context.Context, string) error
+}
+
+var _ ports.UserProjection = (*UserProjection)(nil)
+
+func (m *UserProjection) SaveUser(p0 context.Context, p1 *domain.User) (r0 error) {
+	if m.SaveUserFunc != nil {
+		return m.SaveUserFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserProjection) RemoveUser(p0 context.Context, p1 string) (r0 error) {
+	if m.RemoveUserFunc != nil {
+		return m.RemoveUserFunc(p0, p1)
+	}
+	return
+}
+
+// SearchIndex is a fake ports.SearchIndex; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type SearchIndex struct {
+	SaveUserFunc    func(context.Context, *domain.User) error
+	RemoveUserFunc  func(context.Context, string) error
+	SearchUsersFunc func(context.Context, ports.UserSearchQuery) (*ports.UserSearchResult, error)
+}
+
+var _ ports.SearchIndex = (*SearchIndex)(nil)
+
+func (m *SearchIndex) SaveUser(p0 context.Context, p1 *domain.User) (r0 error) {
+	if m.SaveUserFunc != nil {
+		return m.SaveUserFunc(p0, p1)
+	}
+	return
+}
+
+func (m *SearchIndex) RemoveUser(p0 context.Context, p1 string) (r0 error) {
+	if m.RemoveUserFunc != nil {
+		return m.RemoveUserFunc(p0, p1)
+	}
+	return
+}
+
+func (m *SearchIndex) SearchUsers(p0 context.Context, p1 ports.UserSearchQuery) (r0 *ports.UserSearchResult, r1 error) {
+	if m.SearchUsersFunc != nil {
+		return m.SearchUsersFunc(p0, p1)
+	}
+	return
+}
+
+// UserReadModel is a fake ports.UserReadModel; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type UserReadModel struct {
+	SaveUserFunc   func(context.Context, *domain.User) error
+	RemoveUserFunc func(context.Context, string) error
+	ListUsersFunc  func(context.Context, *ports.GetUsersOptions) (*ports.UserSearchResult, error)
+}
+
+var _ ports.UserReadModel = (*UserReadModel)(nil)
+
+func (m *UserReadModel) SaveUser(p0 context.Context, p1 *domain.User) (r0 error) {
+	if m.SaveUserFunc != nil {
+		return m.SaveUserFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserReadModel) RemoveUser(p0 context.Context, p1 string) (r0 error) {
+	if m.RemoveUserFunc != nil {
+		return m.RemoveUserFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserReadModel) ListUsers(p0 context.Context, p1 *ports.GetUsersOptions) (r0 *ports.UserSearchResult, r1 error) {
+	if m.ListUsersFunc != nil {
+		return m.ListUsersFunc(p0, p1)
+	}
+	return
+}
+
+// UserChangeStream is a fake ports.UserChangeStream; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type UserChangeStream struct {
+	WatchFunc  func(context.Context, func(context.Context, ports.UserChange) error) error
+	SyncedFunc func(context.Context) (bool, error)
+}
+
+var _ ports.UserChangeStream = (*UserChangeStream)(nil)
+
+func (m *UserChangeStream) Watch(p0 context.Context, p1 func(context.Context, ports.UserChange) error) (r0 error) {
+	if m.WatchFunc != nil {
+		return m.WatchFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserChangeStream) Synced(p0 context.Context) (r0 bool, r1 error) {
+	if m.SyncedFunc != nil {
+		return m.SyncedFunc(p0)
+	}
+	return
+}
+
+// SecurityEventUseCase is a fake ports.SecurityEventUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type SecurityEventUseCase struct {
+	ListSecurityEventsFunc func(context.Context, string, time.Time, int) (*ports.SecurityEventFeed, error)
+}
+
+var _ ports.SecurityEventUseCase = (*SecurityEventUseCase)(nil)
+
+func (m *SecurityEventUseCase) ListSecurityEvents(p0 context.Context, p1 string, p2 time.Time, p3 int) (r0 *ports.SecurityEventFeed, r1 error) {
+	if m.ListSecurityEventsFunc != nil {
+		return m.ListSecurityEventsFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+// SessionRepository is a fake ports.SessionRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type SessionRepository struct {
+	CreateSessionFunc           func(context.Context, *domain.Session) error
+	GetSessionFunc              func(context.Context, string) (*domain.Session, error)
+	SetSessionAuthTimeFunc      func(context.Context, string, time.Time) error
+	SetSessionCSRFTokenHashFunc func(context.Context, string, string) error
+	DeleteSessionFunc           func(context.Context, string) error
+}
+
+var _ ports.SessionRepository = (*SessionRepository)(nil)
+
+func (m *SessionRepository) CreateSession(p0 context.Context, p1 *domain.Session) (r0 error) {
+	if m.CreateSessionFunc != nil {
+		return m.CreateSessionFunc(p0, p1)
+	}
+	return
+}
+
+func (m *SessionRepository) GetSession(p0 context.Context, p1 string) (r0 *domain.Session, r1 error) {
+	if m.GetSessionFunc != nil {
+		return m.GetSessionFunc(p0, p1)
+	}
+	return
+}
+
+func (m *SessionRepository) SetSessionAuthTime(p0 context.Context, p1 string, p2 time.Time) (r0 error) {
+	if m.SetSessionAuthTimeFunc != nil {
+		return m.SetSessionAuthTimeFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *SessionRepository) SetSessionCSRFTokenHash(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.SetSessionCSRFTokenHashFunc != nil {
+		return m.SetSessionCSRFTokenHashFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *SessionRepository) DeleteSession(p0 context.Context, p1 string) (r0 error) {
+	if m.DeleteSessionFunc != nil {
+		return m.DeleteSessionFunc(p0, p1)
+	}
+	return
+}
+
+// SessionUseCase is a fake ports.SessionUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type SessionUseCase struct {
+	CreateSessionFunc   func(context.Context, *domain.User, []string, string, string) (*domain.Session, string, string, error)
+	AuthenticateFunc    func(context.Context, string) (*domain.Session, error)
+	ReauthenticatedFunc func(context.Context, *domain.Session) error
+	RotateCSRFTokenFunc func(context.Context, *domain.Session) (string, error)
+	EndSessionFunc      func(context.Context, *domain.Session) error
+}
+
+var _ ports.SessionUseCase = (*SessionUseCase)(nil)
+
+func (m *SessionUseCase) CreateSession(p0 context.Context, p1 *domain.User, p2 []string, p3 string, p4 string) (r0 *domain.Session, r1 string, r2 string, r3 error) {
+	if m.CreateSessionFunc != nil {
+		return m.CreateSessionFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+func (m *SessionUseCase) Authenticate(p0 context.Context, p1 string) (r0 *domain.Session, r1 error) {
+	if m.AuthenticateFunc != nil {
+		return m.AuthenticateFunc(p0, p1)
+	}
+	return
+}
+
+func (m *SessionUseCase) Reauthenticated(p0 context.Context, p1 *domain.Session) (r0 error) {
+	if m.ReauthenticatedFunc != nil {
+		return m.ReauthenticatedFunc(p0, p1)
+	}
+	return
+}
+
+func (m *SessionUseCase) RotateCSRFToken(p0 context.Context, p1 *domain.Session) (r0 string, r1 error) {
+	if m.RotateCSRFTokenFunc != nil {
+		return m.RotateCSRFTokenFunc(p0, p1)
+	}
+	return
+}
+
+func (m *SessionUseCase) EndSession(p0 context.Context, p1 *domain.Session) (r0 error) {
+	if m.EndSessionFunc != nil {
+		return m.EndSessionFunc(p0, p1)
+	}
+	return
+}
+
+// SigningKeyRepository is a fake ports.SigningKeyRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type SigningKeyRepository struct {
+	ListSigningKeysFunc   func(context.Context) ([]*domain.SigningKey, error)
+	CreateSigningKeyFunc  func(context.Context, *domain.SigningKey) error
+	ExpireSigningKeysFunc func(context.Context, string, time.Time) error
+}
+
+var _ ports.SigningKeyRepository = (*SigningKeyRepository)(nil)
+
+func (m *SigningKeyRepository) ListSigningKeys(p0 context.Context) (r0 []*domain.SigningKey, r1 error) {
+	if m.ListSigningKeysFunc != nil {
+		return m.ListSigningKeysFunc(p0)
+	}
+	return
+}
+
+func (m *SigningKeyRepository) CreateSigningKey(p0 context.Context, p1 *domain.SigningKey) (r0 error) {
+	if m.CreateSigningKeyFunc != nil {
+		return m.CreateSigningKeyFunc(p0, p1)
+	}
+	return
+}
+
+func (m *SigningKeyRepository) ExpireSigningKeys(p0 context.Context, p1 string, p2 time.Time) (r0 error) {
+	if m.ExpireSigningKeysFunc != nil {
+		return m.ExpireSigningKeysFunc(p0, p1, p2)
+	}
+	return
+}
+
+// KeyRing is a fake ports.KeyRing; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type KeyRing struct {
+	SetSigningKeysFunc func(crypto.Signer, []crypto.PublicKey) error
+}
+
+var _ ports.KeyRing = (*KeyRing)(nil)
+
+func (m *KeyRing) SetSigningKeys(p0 crypto.Signer, p1 []crypto.PublicKey) (r0 error) {
+	if m.SetSigningKeysFunc != nil {
+		return m.SetSigningKeysFunc(p0, p1)
+	}
+	return
+}
+
+// SigningKeyUseCase is a fake ports.SigningKeyUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type SigningKeyUseCase struct {
+	ListSigningKeysFunc  func(context.Context) ([]*domain.SigningKey, error)
+	RotateSigningKeyFunc func(context.Context, string) (*domain.SigningKey, error)
+}
+
+var _ ports.SigningKeyUseCase = (*SigningKeyUseCase)(nil)
+
+func (m *SigningKeyUseCase) ListSigningKeys(p0 context.Context) (r0 []*domain.SigningKey, r1 error) {
+	if m.ListSigningKeysFunc != nil {
+		return m.ListSigningKeysFunc(p0)
+	}
+	return
+}
+
+func (m *SigningKeyUseCase) RotateSigningKey(p0 context.Context, p1 string) (r0 *domain.SigningKey, r1 error) {
+	if m.RotateSigningKeyFunc != nil {
+		return m.RotateSigningKeyFunc(p0, p1)
+	}
+	return
+}
+
+// SMSSender is a fake ports.SMSSender; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type SMSSender struct {
+	SendFunc func(context.Context, ports.SMSMessage) error
+}
+
+var _ ports.SMSSender = (*SMSSender)(nil)
+
+func (m *SMSSender) Send(p0 context.Context, p1 ports.SMSMessage) (r0 error) {
+	if m.SendFunc != nil {
+		return m.SendFunc(p0, p1)
+	}
+	return
+}
+
+// StatsRepository is a fake ports.StatsRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type StatsRepository struct {
+	RollUpUserStatsFunc    func(context.Context, time.Time) (int, error)
+	ListUserStatsFunc      func(context.Context, time.Time) ([]*domain.UserStats, error)
+	CountRegistrationsFunc func(context.Context, *ports.RegistrationsQuery) ([]ports.RegistrationBucket, error)
+}
+
+var _ ports.StatsRepository = (*StatsRepository)(nil)
+
+func (m *StatsRepository) RollUpUserStats(p0 context.Context, p1 time.Time) (r0 int, r1 error) {
+	if m.RollUpUserStatsFunc != nil {
+		return m.RollUpUserStatsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *StatsRepository) ListUserStats(p0 context.Context, p1 time.Time) (r0 []*domain.UserStats, r1 error) {
+	if m.ListUserStatsFunc != nil {
+		return m.ListUserStatsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *StatsRepository) CountRegistrations(p0 context.Context, p1 *ports.RegistrationsQuery) (r0 []ports.RegistrationBucket, r1 error) {
+	if m.CountRegistrationsFunc != nil {
+		return m.CountRegistrationsFunc(p0, p1)
+	}
+	return
+}
+
+// StatsUseCase is a fake ports.StatsUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type StatsUseCase struct {
+	ListUserStatsFunc      func(context.Context, int) ([]*domain.UserStats, error)
+	CountRegistrationsFunc func(context.Context, *ports.RegistrationsQuery) (*ports.RegistrationsReport, error)
+}
+
+var _ ports.StatsUseCase = (*StatsUseCase)(nil)
+
+func (m *StatsUseCase) ListUserStats(p0 context.Context, p1 int) (r0 []*domain.UserStats, r1 error) {
+	if m.ListUserStatsFunc != nil {
+		return m.ListUserStatsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *StatsUseCase) CountRegistrations(p0 context.Context, p1 *ports.RegistrationsQuery) (r0 *ports.RegistrationsReport, r1 error) {
+	if m.CountRegistrationsFunc != nil {
+		return m.CountRegistrationsFunc(p0, p1)
+	}
+	return
+}
+
+// TermsRepository is a fake ports.TermsRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type TermsRepository struct {
+	CreateVersionFunc    func(context.Context, *domain.TermsVersion) error
+	CurrentVersionsFunc  func(context.Context) ([]*domain.TermsVersion, error)
+	ListVersionsFunc     func(context.Context, string) ([]*domain.TermsVersion, error)
+	CreateAcceptanceFunc func(context.Context, *domain.TermsAcceptance) error
+	ListAcceptancesFunc  func(context.Context, string) ([]*domain.TermsAcceptance, error)
+}
+
+var _ ports.TermsRepository = (*TermsRepository)(nil)
+
+func (m *TermsRepository) CreateVersion(p0 context.Context, p1 *domain.TermsVersion) (r0 error) {
+	if m.CreateVersionFunc != nil {
+		return m.CreateVersionFunc(p0, p1)
+	}
+	return
+}
+
+func (m *TermsRepository) CurrentVersions(p0 context.Context) (r0 []*domain.TermsVersion, r1 error) {
+	if m.CurrentVersionsFunc != nil {
+		return m.CurrentVersionsFunc(p0)
+	}
+	return
+}
+
+func (m *TermsRepository) ListVersions(p0 context.Context, p1 string) (r0 []*domain.TermsVersion, r1 error) {
+	if m.ListVersionsFunc != nil {
+		return m.ListVersionsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *TermsRepository) CreateAcceptance(p0 context.Context, p1 *domain.TermsAcceptance) (r0 error) {
+	if m.CreateAcceptanceFunc != nil {
+		return m.CreateAcceptanceFunc(p0, p1)
+	}
+	return
+}
+
+func (m *TermsRepository) ListAcceptances(p0 context.Context, p1 string) (r0 []*domain.TermsAcceptance, r1 error) {
+	if m.ListAcceptancesFunc != nil {
+		return m.ListAcceptancesFunc(p0, p1)
+	}
+	return
+}
+
+// TermsUseCase is a fake ports.TermsUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type TermsUseCase struct {
+	PublishVersionFunc  func(context.Context, string, string, string, string) (*domain.TermsVersion, error)
+	ListVersionsFunc    func(context.Context, string) ([]*domain.TermsVersion, error)
+	CurrentVersionsFunc func(context.Context) ([]*domain.TermsVersion, error)
+	PendingVersionsFunc func(context.Context, string) ([]*domain.TermsVersion, error)
+	GetStatusFunc       func(context.Context, string) (*domain.TermsStatus, error)
+	AcceptVersionsFunc  func(context.Context, string, map[string]string) (*domain.TermsStatus, error)
+}
+
+var _ ports.TermsUseCase = (*TermsUseCase)(nil)
+
+func (m *TermsUseCase) PublishVersion(p0 context.Context, p1 string, p2 string, p3 string, p4 string) (r0 *domain.TermsVersion, r1 error) {
+	if m.PublishVersionFunc != nil {
+		return m.PublishVersionFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+func (m *TermsUseCase) ListVersions(p0 context.Context, p1 string) (r0 []*domain.TermsVersion, r1 error) {
+	if m.ListVersionsFunc != nil {
+		return m.ListVersionsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *TermsUseCase) CurrentVersions(p0 context.Context) (r0 []*domain.TermsVersion, r1 error) {
+	if m.CurrentVersionsFunc != nil {
+		return m.CurrentVersionsFunc(p0)
+	}
+	return
+}
+
+func (m *TermsUseCase) PendingVersions(p0 context.Context, p1 string) (r0 []*domain.TermsVersion, r1 error) {
+	if m.PendingVersionsFunc != nil {
+		return m.PendingVersionsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *TermsUseCase) GetStatus(p0 context.Context, p1 string) (r0 *domain.TermsStatus, r1 error) {
+	if m.GetStatusFunc != nil {
+		return m.GetStatusFunc(p0, p1)
+	}
+	return
+}
+
+func (m *TermsUseCase) AcceptVersions(p0 context.Context, p1 string, p2 map[string]string) (r0 *domain.TermsStatus, r1 error) {
+	if m.AcceptVersionsFunc != nil {
+		return m.AcceptVersionsFunc(p0, p1, p2)
+	}
+	return
+}
+
+// UserHistoryRepository is a fake ports.UserHistoryRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type UserHistoryRepository struct {
+	AppendVersionFunc func(context.Context, *domain.UserVersion) error
+	ListVersionsFunc  func(context.Context, *ports.UserHistoryQuery) (*ports.UserHistoryResult, error)
+	GetVersionFunc    func(context.Context, string, int) (*domain.UserVersion, error)
+}
+
+var _ ports.UserHistoryRepository = (*UserHistoryRepository)(nil)
+
+func (m *UserHistoryRepository) AppendVersion(p0 context.Context, p1 *domain.UserVersion) (r0 error) {
+	if m.AppendVersionFunc != nil {
+		return m.AppendVersionFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserHistoryRepository) ListVersions(p0 context.Context, p1 *ports.UserHistoryQuery) (r0 *ports.UserHistoryResult, r1 error) {
+	if m.ListVersionsFunc != nil {
+		return m.ListVersionsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserHistoryRepository) GetVersion(p0 context.Context, p1 string, p2 int) (r0 *domain.UserVersion, r1 error) {
+	if m.GetVersionFunc != nil {
+		return m.GetVersionFunc(p0, p1, p2)
+	}
+	return
+}
+
+// UserHistoryUseCase is a fake ports.UserHistoryUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type UserHistoryUseCase struct {
+	ListHistoryFunc func(context.Context, *ports.UserHistoryQuery) (*ports.UserHistoryResult, error)
+	RevertUserFunc  func(context.Context, string, string, int) (*domain.User, error)
+}
+
+var _ ports.UserHistoryUseCase = (*UserHistoryUseCase)(nil)
+
+func (m *UserHistoryUseCase) ListHistory(p0 context.Context, p1 *ports.UserHistoryQuery) (r0 *ports.UserHistoryResult, r1 error) {
+	if m.ListHistoryFunc != nil {
+		return m.ListHistoryFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserHistoryUseCase) RevertUser(p0 context.Context, p1 string, p2 string, p3 int) (r0 *domain.User, r1 error) {
+	if m.RevertUserFunc != nil {
+		return m.RevertUserFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+// UserRepository is a fake ports.UserRepository; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type UserRepository struct {
+	CreateUserFunc                 func(context.Context, *domain.User) error
+	FindOrCreateUserFunc           func(context.Context, *domain.User) (*domain.User, bool, error)
+	GetUserByIDFunc                func(context.Context, string) (*domain.User, error)
+	GetUserByEmailFunc             func(context.Context, string) (*domain.User, error)
+	GetUserByNormalizedEmailFunc   func(context.Context, string) (*domain.User, error)
+	GetUserByUsernameFunc          func(context.Context, string) (*domain.User, error)
+	GetUserByExternalIdentityFunc  func(context.Context, string, string) (*domain.User, error)
+	GetUsersByIDsFunc              func(context.Context, []string) ([]*domain.User, error)
+	AutocompleteUsersFunc          func(context.Context, string, int) ([]*domain.User, error)
+	UserExistsFunc                 func(context.Context, string) (bool, error)
+	GetUsersFunc                   func(context.Context, *ports.GetUsersOptions) (*ports.GetUsersResult, error)
+	CountUsersFunc                 func(context.Context, *ports.GetUsersOptions) (int64, error)
+	DuplicateGroupsFunc            func(context.Context, string) ([][]*domain.User, error)
+	EachUserFunc                   func(context.Context, *ports.GetUsersOptions, func(*domain.User) error) error
+	UpdateUserFunc                 func(context.Context, *domain.User) error
+	SetUsernameFunc                func(context.Context, string, string) error
+	SetEmailVerifiedFunc           func(context.Context, string, time.Time) error
+	SetPasswordHashFunc            func(context.Context, string, string) error
+	RequirePasswordResetFunc       func(context.Context, string) error
+	SetDeactivatedFunc             func(context.Context, string, *time.Time) error
+	RecordLoginFunc                func(context.Context, string, time.Time) error
+	GetTokenGenerationFunc         func(context.Context, string) (int, error)
+	IncrementTokenGenerationFunc   func(context.Context, string) (int, error)
+	SetLastSeenFunc                func(context.Context, string, time.Time) error
+	SetAvatarFunc                  func(context.Context, string, *domain.Avatar) error
+	SetVerificationFunc            func(context.Context, string, *domain.Verification, string) (bool, error)
+	GetUserByVerificationCheckFunc func(context.Context, string, string) (*domain.User, error)
+	SetSettingsFunc                func(context.Context, string, domain.Settings) error
+	SetProfileFunc                 func(context.Context, string, domain.Profile) error
+	SetPhoneChallengeFunc          func(context.Context, string, *domain.PhoneChallenge) error
+	AddPhoneChallengeAttemptFunc   func(context.Context, string) error
+	SetPhoneVerifiedFunc           func(context.Context, string, string, *time.Time) (bool, error)
+	SetMFAFactorsFunc              func(context.Context, string, []domain.MFAFactor) error
+	AddMFAChallengeAttemptFunc     func(context.Context, string, string) (bool, error)
+	ConsumeMFAChallengeFunc        func(context.Context, string, string, string, time.Time) (bool, error)
+	UseMFAStepFunc                 func(context.Context, string, string, int64, time.Time) (bool, error)
+	SetTrustedDevicesFunc          func(context.Context, string, []domain.TrustedDevice) error
+	SetAddressesFunc               func(context.Context, string, []domain.Address) error
+	SetMetadataFunc                func(context.Context, string, map[string]string) error
+	AddTagsFunc                    func(context.Context, string, []string) ([]string, error)
+	RemoveTagFunc                  func(context.Context, string, string) ([]string, error)
+	SetRolesFunc                   func(context.Context, string, []string) error
+	RemoveRoleFromAllUsersFunc     func(context.Context, string) error
+	UpdateUsersFunc                func(context.Context, *ports.GetUsersOptions, domain.UserBatchUpdate) (*ports.UserBatchResult, error)
+	SoftDeleteUserFunc             func(context.Context, string, string) error
+	DeleteUserFunc                 func(context.Context, string) error
+}
+
+var _ ports.UserRepository = (*UserRepository)(nil)
+
+func (m *UserRepository) CreateUser(p0 context.Context, p1 *domain.User) (r0 error) {
+	if m.CreateUserFunc != nil {
+		return m.CreateUserFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) FindOrCreateUser(p0 context.Context, p1 *domain.User) (r0 *domain.User, r1 bool, r2 error) {
+	if m.FindOrCreateUserFunc != nil {
+		return m.FindOrCreateUserFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) GetUserByID(p0 context.Context, p1 string) (r0 *domain.User, r1 error) {
+	if m.GetUserByIDFunc != nil {
+		return m.GetUserByIDFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) GetUserByEmail(p0 context.Context, p1 string) (r0 *domain.User, r1 error) {
+	if m.GetUserByEmailFunc != nil {
+		return m.GetUserByEmailFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) GetUserByNormalizedEmail(p0 context.Context, p1 string) (r0 *domain.User, r1 error) {
+	if m.GetUserByNormalizedEmailFunc != nil {
+		return m.GetUserByNormalizedEmailFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) GetUserByUsername(p0 context.Context, p1 string) (r0 *domain.User, r1 error) {
+	if m.GetUserByUsernameFunc != nil {
+		return m.GetUserByUsernameFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) GetUserByExternalIdentity(p0 context.Context, p1 string, p2 string) (r0 *domain.User, r1 error) {
+	if m.GetUserByExternalIdentityFunc != nil {
+		return m.GetUserByExternalIdentityFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) GetUsersByIDs(p0 context.Context, p1 []string) (r0 []*domain.User, r1 error) {
+	if m.GetUsersByIDsFunc != nil {
+		return m.GetUsersByIDsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) AutocompleteUsers(p0 context.Context, p1 string, p2 int) (r0 []*domain.User, r1 error) {
+	if m.AutocompleteUsersFunc != nil {
+		return m.AutocompleteUsersFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) UserExists(p0 context.Context, p1 string) (r0 bool, r1 error) {
+	if m.UserExistsFunc != nil {
+		return m.UserExistsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) GetUsers(p0 context.Context, p1 *ports.GetUsersOptions) (r0 *ports.GetUsersResult, r1 error) {
+	if m.GetUsersFunc != nil {
+		return m.GetUsersFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) CountUsers(p0 context.Context, p1 *ports.GetUsersOptions) (r0 int64, r1 error) {
+	if m.CountUsersFunc != nil {
+		return m.CountUsersFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) DuplicateGroups(p0 context.Context, p1 string) (r0 [][]*domain.User, r1 error) {
+	if m.DuplicateGroupsFunc != nil {
+		return m.DuplicateGroupsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) EachUser(p0 context.Context, p1 *ports.GetUsersOptions, p2 func(*domain.User) error) (r0 error) {
+	if m.EachUserFunc != nil {
+		return m.EachUserFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) UpdateUser(p0 context.Context, p1 *domain.User) (r0 error) {
+	if m.UpdateUserFunc != nil {
+		return m.UpdateUserFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) SetUsername(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.SetUsernameFunc != nil {
+		return m.SetUsernameFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SetEmailVerified(p0 context.Context, p1 string, p2 time.Time) (r0 error) {
+	if m.SetEmailVerifiedFunc != nil {
+		return m.SetEmailVerifiedFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SetPasswordHash(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.SetPasswordHashFunc != nil {
+		return m.SetPasswordHashFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) RequirePasswordReset(p0 context.Context, p1 string) (r0 error) {
+	if m.RequirePasswordResetFunc != nil {
+		return m.RequirePasswordResetFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) SetDeactivated(p0 context.Context, p1 string, p2 *time.Time) (r0 error) {
+	if m.SetDeactivatedFunc != nil {
+		return m.SetDeactivatedFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) RecordLogin(p0 context.Context, p1 string, p2 time.Time) (r0 error) {
+	if m.RecordLoginFunc != nil {
+		return m.RecordLoginFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) GetTokenGeneration(p0 context.Context, p1 string) (r0 int, r1 error) {
+	if m.GetTokenGenerationFunc != nil {
+		return m.GetTokenGenerationFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) IncrementTokenGeneration(p0 context.Context, p1 string) (r0 int, r1 error) {
+	if m.IncrementTokenGenerationFunc != nil {
+		return m.IncrementTokenGenerationFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) SetLastSeen(p0 context.Context, p1 string, p2 time.Time) (r0 error) {
+	if m.SetLastSeenFunc != nil {
+		return m.SetLastSeenFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SetAvatar(p0 context.Context, p1 string, p2 *domain.Avatar) (r0 error) {
+	if m.SetAvatarFunc != nil {
+		return m.SetAvatarFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SetVerification(p0 context.Context, p1 string, p2 *domain.Verification, p3 string) (r0 bool, r1 error) {
+	if m.SetVerificationFunc != nil {
+		return m.SetVerificationFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *UserRepository) GetUserByVerificationCheck(p0 context.Context, p1 string, p2 string) (r0 *domain.User, r1 error) {
+	if m.GetUserByVerificationCheckFunc != nil {
+		return m.GetUserByVerificationCheckFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SetSettings(p0 context.Context, p1 string, p2 domain.Settings) (r0 error) {
+	if m.SetSettingsFunc != nil {
+		return m.SetSettingsFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SetProfile(p0 context.Context, p1 string, p2 domain.Profile) (r0 error) {
+	if m.SetProfileFunc != nil {
+		return m.SetProfileFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SetPhoneChallenge(p0 context.Context, p1 string, p2 *domain.PhoneChallenge) (r0 error) {
+	if m.SetPhoneChallengeFunc != nil {
+		return m.SetPhoneChallengeFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) AddPhoneChallengeAttempt(p0 context.Context, p1 string) (r0 error) {
+	if m.AddPhoneChallengeAttemptFunc != nil {
+		return m.AddPhoneChallengeAttemptFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) SetPhoneVerified(p0 context.Context, p1 string, p2 string, p3 *time.Time) (r0 bool, r1 error) {
+	if m.SetPhoneVerifiedFunc != nil {
+		return m.SetPhoneVerifiedFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *UserRepository) SetMFAFactors(p0 context.Context, p1 string, p2 []domain.MFAFactor) (r0 error) {
+	if m.SetMFAFactorsFunc != nil {
+		return m.SetMFAFactorsFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) AddMFAChallengeAttempt(p0 context.Context, p1 string, p2 string) (r0 bool, r1 error) {
+	if m.AddMFAChallengeAttemptFunc != nil {
+		return m.AddMFAChallengeAttemptFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) ConsumeMFAChallenge(p0 context.Context, p1 string, p2 string, p3 string, p4 time.Time) (r0 bool, r1 error) {
+	if m.ConsumeMFAChallengeFunc != nil {
+		return m.ConsumeMFAChallengeFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+func (m *UserRepository) UseMFAStep(p0 context.Context, p1 string, p2 string, p3 int64, p4 time.Time) (r0 bool, r1 error) {
+	if m.UseMFAStepFunc != nil {
+		return m.UseMFAStepFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+func (m *UserRepository) SetTrustedDevices(p0 context.Context, p1 string, p2 []domain.TrustedDevice) (r0 error) {
+	if m.SetTrustedDevicesFunc != nil {
+		return m.SetTrustedDevicesFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SetAddresses(p0 context.Context, p1 string, p2 []domain.Address) (r0 error) {
+	if m.SetAddressesFunc != nil {
+		return m.SetAddressesFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SetMetadata(p0 context.Context, p1 string, p2 map[string]string) (r0 error) {
+	if m.SetMetadataFunc != nil {
+		return m.SetMetadataFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) AddTags(p0 context.Context, p1 string, p2 []string) (r0 []string, r1 error) {
+	if m.AddTagsFunc != nil {
+		return m.AddTagsFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) RemoveTag(p0 context.Context, p1 string, p2 string) (r0 []string, r1 error) {
+	if m.RemoveTagFunc != nil {
+		return m.RemoveTagFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SetRoles(p0 context.Context, p1 string, p2 []string) (r0 error) {
+	if m.SetRolesFunc != nil {
+		return m.SetRolesFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) RemoveRoleFromAllUsers(p0 context.Context, p1 string) (r0 error) {
+	if m.RemoveRoleFromAllUsersFunc != nil {
+		return m.RemoveRoleFromAllUsersFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserRepository) UpdateUsers(p0 context.Context, p1 *ports.GetUsersOptions, p2 domain.UserBatchUpdate) (r0 *ports.UserBatchResult, r1 error) {
+	if m.UpdateUsersFunc != nil {
+		return m.UpdateUsersFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) SoftDeleteUser(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.SoftDeleteUserFunc != nil {
+		return m.SoftDeleteUserFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserRepository) DeleteUser(p0 context.Context, p1 string) (r0 error) {
+	if m.DeleteUserFunc != nil {
+		return m.DeleteUserFunc(p0, p1)
+	}
+	return
+}
+
+// UserUseCase is a fake ports.UserUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type UserUseCase struct {
+	RegisterFunc           func(context.Context, string, string, string, domain.Profile, map[string]string) error
+	RegistrationFieldsFunc func() []domain.CustomField
+	EvaluatePasswordFunc   func(context.Context, string, ...string) domain.PasswordEvaluation
+	AuthenticateFunc       func(context.Context, string, string) (*domain.User, error)
+	VerifyPasswordFunc     func(context.Context, string, string) (*domain.User, error)
+	RecordLastSeenFunc     func(context.Context, string) error
+	TokenGenerationFunc    func(context.Context, string) (int, error)
+	LogoutAllFunc          func(context.Context, string, string) error
+	GetUsersFunc           func(context.Context, *ports.GetUsersOptions) (*ports.GetUsersResult, error)
+	CountUsersFunc         func(context.Context, *ports.GetUsersOptions) (int64, error)
+	GetUserByEmailFunc     func(context.Context, string) (*domain.User, error)
+	GetUserByUsernameFunc  func(context.Context, string) (*domain.User, error)
+	SetUsernameFunc        func(context.Context, string, string) (*domain.User, error)
+	IsEmailAvailableFunc   func(context.Context, string) (bool, error)
+	GetUserByIDFunc        func(context.Context, string) (*domain.User, error)
+	UserExistsFunc         func(context.Context, string) (bool, error)
+	LookupUsersFunc        func(context.Context, []string) ([]*domain.User, error)
+	AutocompleteUsersFunc  func(context.Context, string, int) ([]*domain.User, error)
+	UpdateUserFunc         func(context.Context, *domain.User) error
+	DeleteUserFunc         func(context.Context, string) error
+	UpdateProfileFunc      func(context.Context, string, string, domain.ProfileUpdate, bool) (*domain.User, error)
+	GetSettingsFunc        func(context.Context, string) (*domain.Settings, error)
+	UpdateSettingsFunc     func(context.Context, string, domain.SettingsUpdate) (*domain.Settings, error)
+	ListAddressesFunc      func(context.Context, string) ([]domain.Address, error)
+	AddAddressFunc         func(context.Context, string, domain.Address) (*domain.Address, error)
+	UpdateAddressFunc      func(context.Context, string, string, domain.Address) (*domain.Address, error)
+	RemoveAddressFunc      func(context.Context, string, string) error
+	UpdateMetadataFunc     func(context.Context, string, map[string]*string) (map[string]string, error)
+	AddTagsFunc            func(context.Context, string, []string) ([]string, error)
+	RemoveTagFunc          func(context.Context, string, string) ([]string, error)
+	BatchUpdateUsersFunc   func(context.Context, string, *ports.GetUsersOptions, domain.UserBatchUpdate) (*ports.UserBatchResult, error)
+	UpsertUserByEmailFunc  func(context.Context, string, string, domain.UserUpsert, string) (*domain.User, bool, error)
+	FindOrCreateFunc       func(context.Context, string, string, string, string) (*domain.User, bool, error)
+}
+
+var _ ports.UserUseCase = (*UserUseCase)(nil)
+
+func (m *UserUseCase) Register(p0 context.Context, p1 string, p2 string, p3 string, p4 domain.Profile, p5 map[string]string) (r0 error) {
+	if m.RegisterFunc != nil {
+		return m.RegisterFunc(p0, p1, p2, p3, p4, p5)
+	}
+	return
+}
+
+func (m *UserUseCase) RegistrationFields() (r0 []domain.CustomField) {
+	if m.RegistrationFieldsFunc != nil {
+		return m.RegistrationFieldsFunc()
+	}
+	return
+}
+
+func (m *UserUseCase) EvaluatePassword(p0 context.Context, p1 string, p2 ...string) (r0 domain.PasswordEvaluation) {
+	if m.EvaluatePasswordFunc != nil {
+		return m.EvaluatePasswordFunc(p0, p1, p2...)
+	}
+	return
+}
+
+func (m *UserUseCase) Authenticate(p0 context.Context, p1 string, p2 string) (r0 *domain.User, r1 error) {
+	if m.AuthenticateFunc != nil {
+		return m.AuthenticateFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) VerifyPassword(p0 context.Context, p1 string, p2 string) (r0 *domain.User, r1 error) {
+	if m.VerifyPasswordFunc != nil {
+		return m.VerifyPasswordFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) RecordLastSeen(p0 context.Context, p1 string) (r0 error) {
+	if m.RecordLastSeenFunc != nil {
+		return m.RecordLastSeenFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) TokenGeneration(p0 context.Context, p1 string) (r0 int, r1 error) {
+	if m.TokenGenerationFunc != nil {
+		return m.TokenGenerationFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) LogoutAll(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.LogoutAllFunc != nil {
+		return m.LogoutAllFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) GetUsers(p0 context.Context, p1 *ports.GetUsersOptions) (r0 *ports.GetUsersResult, r1 error) {
+	if m.GetUsersFunc != nil {
+		return m.GetUsersFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) CountUsers(p0 context.Context, p1 *ports.GetUsersOptions) (r0 int64, r1 error) {
+	if m.CountUsersFunc != nil {
+		return m.CountUsersFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) GetUserByEmail(p0 context.Context, p1 string) (r0 *domain.User, r1 error) {
+	if m.GetUserByEmailFunc != nil {
+		return m.GetUserByEmailFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) GetUserByUsername(p0 context.Context, p1 string) (r0 *domain.User, r1 error) {
+	if m.GetUserByUsernameFunc != nil {
+		return m.GetUserByUsernameFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) SetUsername(p0 context.Context, p1 string, p2 string) (r0 *domain.User, r1 error) {
+	if m.SetUsernameFunc != nil {
+		return m.SetUsernameFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) IsEmailAvailable(p0 context.Context, p1 string) (r0 bool, r1 error) {
+	if m.IsEmailAvailableFunc != nil {
+		return m.IsEmailAvailableFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) GetUserByID(p0 context.Context, p1 string) (r0 *domain.User, r1 error) {
+	if m.GetUserByIDFunc != nil {
+		return m.GetUserByIDFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) UserExists(p0 context.Context, p1 string) (r0 bool, r1 error) {
+	if m.UserExistsFunc != nil {
+		return m.UserExistsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) LookupUsers(p0 context.Context, p1 []string) (r0 []*domain.User, r1 error) {
+	if m.LookupUsersFunc != nil {
+		return m.LookupUsersFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) AutocompleteUsers(p0 context.Context, p1 string, p2 int) (r0 []*domain.User, r1 error) {
+	if m.AutocompleteUsersFunc != nil {
+		return m.AutocompleteUsersFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) UpdateUser(p0 context.Context, p1 *domain.User) (r0 error) {
+	if m.UpdateUserFunc != nil {
+		return m.UpdateUserFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) DeleteUser(p0 context.Context, p1 string) (r0 error) {
+	if m.DeleteUserFunc != nil {
+		return m.DeleteUserFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) UpdateProfile(p0 context.Context, p1 string, p2 string, p3 domain.ProfileUpdate, p4 bool) (r0 *domain.User, r1 error) {
+	if m.UpdateProfileFunc != nil {
+		return m.UpdateProfileFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+func (m *UserUseCase) GetSettings(p0 context.Context, p1 string) (r0 *domain.Settings, r1 error) {
+	if m.GetSettingsFunc != nil {
+		return m.GetSettingsFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) UpdateSettings(p0 context.Context, p1 string, p2 domain.SettingsUpdate) (r0 *domain.Settings, r1 error) {
+	if m.UpdateSettingsFunc != nil {
+		return m.UpdateSettingsFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) ListAddresses(p0 context.Context, p1 string) (r0 []domain.Address, r1 error) {
+	if m.ListAddressesFunc != nil {
+		return m.ListAddressesFunc(p0, p1)
+	}
+	return
+}
+
+func (m *UserUseCase) AddAddress(p0 context.Context, p1 string, p2 domain.Address) (r0 *domain.Address, r1 error) {
+	if m.AddAddressFunc != nil {
+		return m.AddAddressFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) UpdateAddress(p0 context.Context, p1 string, p2 string, p3 domain.Address) (r0 *domain.Address, r1 error) {
+	if m.UpdateAddressFunc != nil {
+		return m.UpdateAddressFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *UserUseCase) RemoveAddress(p0 context.Context, p1 string, p2 string) (r0 error) {
+	if m.RemoveAddressFunc != nil {
+		return m.RemoveAddressFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) UpdateMetadata(p0 context.Context, p1 string, p2 map[string]*string) (r0 map[string]string, r1 error) {
+	if m.UpdateMetadataFunc != nil {
+		return m.UpdateMetadataFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) AddTags(p0 context.Context, p1 string, p2 []string) (r0 []string, r1 error) {
+	if m.AddTagsFunc != nil {
+		return m.AddTagsFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) RemoveTag(p0 context.Context, p1 string, p2 string) (r0 []string, r1 error) {
+	if m.RemoveTagFunc != nil {
+		return m.RemoveTagFunc(p0, p1, p2)
+	}
+	return
+}
+
+func (m *UserUseCase) BatchUpdateUsers(p0 context.Context, p1 string, p2 *ports.GetUsersOptions, p3 domain.UserBatchUpdate) (r0 *ports.UserBatchResult, r1 error) {
+	if m.BatchUpdateUsersFunc != nil {
+		return m.BatchUpdateUsersFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *UserUseCase) UpsertUserByEmail(p0 context.Context, p1 string, p2 string, p3 domain.UserUpsert, p4 string) (r0 *domain.User, r1 bool, r2 error) {
+	if m.UpsertUserByEmailFunc != nil {
+		return m.UpsertUserByEmailFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+func (m *UserUseCase) FindOrCreate(p0 context.Context, p1 string, p2 string, p3 string, p4 string) (r0 *domain.User, r1 bool, r2 error) {
+	if m.FindOrCreateFunc != nil {
+		return m.FindOrCreateFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+// VerificationUseCase is a fake ports.VerificationUseCase; each method calls the field of the same name with a Func suffix,
+// or returns zero values when it is nil
+type VerificationUseCase struct {
+	GetVerificationFunc    func(context.Context, string) (*domain.Verification, error)
+	SubmitVerificationFunc func(context.Context, string, string, []string) (*domain.Verification, error)
+	ReviewVerificationFunc func(context.Context, string, string, string, string) (*domain.Verification, error)
+	HandleCheckWebhookFunc func(context.Context, http.Header, []byte) error
+}
+
+var _ ports.VerificationUseCase = (*VerificationUseCase)(nil)
+
+func (m *VerificationUseCase) GetVerification(p0 context.Context, p1 string) (r0 *domain.Verification, r1 error) {
+	if m.GetVerificationFunc != nil {
+		return m.GetVerificationFunc(p0, p1)
+	}
+	return
+}
+
+func (m *VerificationUseCase) SubmitVerification(p0 context.Context, p1 string, p2 string, p3 []string) (r0 *domain.Verification, r1 error) {
+	if m.SubmitVerificationFunc != nil {
+		return m.SubmitVerificationFunc(p0, p1, p2, p3)
+	}
+	return
+}
+
+func (m *VerificationUseCase) ReviewVerification(p0 context.Context, p1 string, p2 string, p3 string, p4 string) (r0 *domain.Verification, r1 error) {
+	if m.ReviewVerificationFunc != nil {
+		return m.ReviewVerificationFunc(p0, p1, p2, p3, p4)
+	}
+	return
+}
+
+func (m *VerificationUseCase) HandleCheckWebhook(p0 context.Context, p1 http.Header, p2 []byte) (r0 error) {
+	if m.HandleCheckWebhookFunc != nil {
+		return m.HandleCheckWebhookFunc(p0, p1, p2)
+	}
+	return
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.AuditRepository = (*AuditRepository)(nil)
+
+type AuditRepository struct {
+	collection tenantCollection
+}
+
+func NewAuditRepository(databases *TenantDatabases, collectionName string) *AuditRepository {
+	return &AuditRepository{
+		collection: newTenantCollection(databases, collectionName),
+	}
+}
+
+func (r *AuditRepository) CreateAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	event.TenantID = tenantID
+
+	_, err := r.collection.in(ctx).InsertOne(ctx, event)
+	return err
+}
+
+func (r *AuditRepository) ListAuditEvents(ctx context.Context, query *ports.AuditQuery) (*ports.AuditQueryResult, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+
+	filter := bson.M{}
+	if len(query.Actions) > 0 {
+		filter["action"] = bson.M{"$in": query.Actions}
+	} else if query.Action != "" {
+		filter["action"] = query.Action
+	}
+	if query.ActorID != "" {
+		filter["actor_id"] = query.ActorID
+	}
+	if query.TargetID != "" {
+		filter["target_id"] = query.TargetID
+	}
+	if !query.Before.IsZero() {
+		filter["created_at"] = bson.M{"$lt": query.Before}
+	}
+	filter, err := tenantScoped(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := r.collection.in(ctx).CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((query.Page - 1) * query.PageSize)).
+		SetLimit(int64(query.PageSize))
+	cursor, err := r.collection.in(ctx).Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := make([]*domain.AuditEvent, 0, query.PageSize)
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return &ports.AuditQueryResult{
+		Events:     events,
+		TotalCount: totalCount,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: int(totalCount+int64(query.PageSize)-1) / query.PageSize,
+	}, nil
+}
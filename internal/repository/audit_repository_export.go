@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.AuditRepository = (*ExportingAuditRepository)(nil)
+
+// ExportingAuditRepository hands every audit event stored by the wrapped repository to an exporter,
+// shipping them to external systems such as a SIEM. Events failing to be stored are not exported.
+type ExportingAuditRepository struct {
+	ports.AuditRepository
+	exporter ports.AuditExporter
+}
+
+func NewExportingAuditRepository(audit ports.AuditRepository, exporter ports.AuditExporter) *ExportingAuditRepository {
+	return &ExportingAuditRepository{
+		AuditRepository: audit,
+		exporter:        exporter,
+	}
+}
+
+func (r *ExportingAuditRepository) CreateAuditEvent(ctx context.Context, event *domain.AuditEvent) error {
+	if err := r.AuditRepository.CreateAuditEvent(ctx, event); err != nil {
+		return err
+	}
+	r.exporter.Export(event)
+	return nil
+}
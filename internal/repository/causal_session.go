@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// causalPosition is the position of a domain.CausalConsistency: the cluster time, signed by the
+// deployment, and the time of the last operation, in the database of the tenant
+type causalPosition struct {
+	TenantID      string              `bson:"t"`
+	ClusterTime   bson.Raw            `bson:"c"`
+	OperationTime primitive.Timestamp `bson:"o"`
+}
+
+// causal returns ctx running the operations on the collection in a causally consistent session,
+// after the position of the domain.CausalConsistency of ctx, when it carries one. end ends the
+// session and advances the position to its last operation. A session is started for each call
+// rather than for the request, as sessions cannot be used concurrently nor after they ended, which
+// the background work started by requests would otherwise do.
+func (c tenantCollection) causal(ctx context.Context) (_ context.Context, end func()) {
+	consistency := domain.CausalConsistencyFromContext(ctx)
+	if consistency == nil {
+		return ctx, func() {}
+	}
+	session, err := c.databases.Database(ctx).Client().StartSession(options.Session().SetCausalConsistency(true))
+	if err != nil {
+		// Operations are still run, only without reading their own writes
+		return ctx, func() {}
+	}
+
+	tenantID := domain.TenantFromContext(ctx)
+	if position, ok := decodeCausalPosition(consistency.Token()); ok && position.TenantID == tenantID {
+		if err := session.AdvanceClusterTime(position.ClusterTime); err == nil {
+			_ = session.AdvanceOperationTime(&position.OperationTime)
+		}
+	}
+	return mongo.NewSessionContext(ctx, session), func() {
+		defer session.EndSession(ctx)
+		operationTime := session.OperationTime()
+		if operationTime == nil {
+			// Standalone servers have no operation times
+			return
+		}
+		consistency.Advance(func(token string) string {
+			if position, ok := decodeCausalPosition(token); ok && position.TenantID == tenantID && position.OperationTime.After(*operationTime) {
+				return token
+			}
+			return encodeCausalPosition(causalPosition{TenantID: tenantID, ClusterTime: session.ClusterTime(), OperationTime: *operationTime})
+		})
+	}
+}
+
+func encodeCausalPosition(position causalPosition) string {
+	data, err := bson.Marshal(position)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCausalPosition returns the position of token, or false when it is empty or invalid
+func decodeCausalPosition(token string) (causalPosition, bool) {
+	var position causalPosition
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || token == "" || bson.Unmarshal(data, &position) != nil || position.ClusterTime == nil {
+		return causalPosition{}, false
+	}
+	return position, true
+}
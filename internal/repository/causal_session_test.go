@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"testing"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/repository/repositorytest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// samplePosition returns the position of tenantID at the operation time ts, encoded as a token
+// from an independently built document
+func samplePosition(t *testing.T, tenantID string, ts primitive.Timestamp) (causalPosition, string) {
+	t.Helper()
+	clusterTime, err := bson.Marshal(bson.D{{Key: "$clusterTime", Value: bson.D{
+		{Key: "clusterTime", Value: ts},
+		{Key: "signature", Value: bson.D{{Key: "hash", Value: primitive.Binary{Data: make([]byte, 20)}}, {Key: "keyId", Value: int64(0)}}},
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := bson.Marshal(bson.D{{Key: "t", Value: tenantID}, {Key: "c", Value: bson.Raw(clusterTime)}, {Key: "o", Value: ts}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return causalPosition{TenantID: tenantID, ClusterTime: clusterTime, OperationTime: ts}, base64.RawURLEncoding.EncodeToString(doc)
+}
+
+func TestEncodeCausalPosition(t *testing.T) {
+	position, token := samplePosition(t, "acme", primitive.Timestamp{T: 1700000000, I: 7})
+	if got := encodeCausalPosition(position); got != token {
+		t.Errorf("encodeCausalPosition() = %s, want %s", got, token)
+	}
+	got, ok := decodeCausalPosition(token)
+	if !ok || !reflect.DeepEqual(got, position) {
+		t.Errorf("decodeCausalPosition() = %+v, %v, want %+v", got, ok, position)
+	}
+}
+
+func TestDecodeCausalPositionInvalid(t *testing.T) {
+	_, token := samplePosition(t, "acme", primitive.Timestamp{T: 1700000000, I: 7})
+	withoutClusterTime, err := bson.Marshal(bson.D{{Key: "t", Value: "acme"}, {Key: "o", Value: primitive.Timestamp{T: 1700000000, I: 7}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "empty"},
+		{name: "not base64", token: "not a token!"},
+		{name: "standard base64 alphabet", token: base64.RawStdEncoding.EncodeToString([]byte{0xfb, 0xff})},
+		{name: "not bson", token: base64.RawURLEncoding.EncodeToString([]byte("hello, world"))},
+		{name: "truncated bson", token: base64.RawURLEncoding.EncodeToString(data[:len(data)-1])},
+		{name: "no cluster time", token: base64.RawURLEncoding.EncodeToString(withoutClusterTime)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := decodeCausalPosition(tt.token); ok {
+				t.Errorf("decodeCausalPosition(%q) = %+v, want false", tt.token, got)
+			}
+		})
+	}
+}
+
+func TestCausalSessions(t *testing.T) {
+	// earlier stands for the position reached by a first request of acme
+	const earlier = "earlier"
+	later, laterToken := samplePosition(t, "acme", primitive.Timestamp{T: repositorytest.ClusterTime + 1, I: 1})
+	tests := []struct {
+		name       string
+		tenant     string
+		consistent bool
+		token      string // Position the request starts after
+		// wantAfter is the position the read waits for, earlier, later or none
+		wantAfter string
+		// wantKept keeps the position the request started after, instead of the one of its read
+		wantKept bool
+	}{
+		{name: "not causally consistent", tenant: "acme"},
+		{name: "first request", tenant: "acme", consistent: true},
+		{name: "after the position of the previous request", tenant: "acme", consistent: true, token: earlier, wantAfter: earlier},
+		{name: "position of another tenant", tenant: "globex", consistent: true, token: earlier},
+		{name: "invalid position", tenant: "acme", consistent: true, token: "not a token!"},
+		{name: "later position is kept", tenant: "acme", consistent: true, token: laterToken, wantAfter: "later", wantKept: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := repositorytest.NewServer(t)
+			users := NewUserRepository(NewTenantDatabases(server.Client(t).Database("app"), nil), "users")
+
+			ctx, first := domain.WithCausalConsistency(domain.WithTenant(context.Background(), "acme"), "")
+			if _, err := users.GetUserByID(ctx, "u1"); err != nil {
+				t.Fatalf("GetUserByID() error = %v", err)
+			}
+			position, ok := decodeCausalPosition(first.Token())
+			if !ok || position.TenantID != "acme" || position.OperationTime.T != repositorytest.ClusterTime {
+				t.Fatalf("position of the first request = %+v, %v, want one of acme", position, ok)
+			}
+
+			ctx = domain.WithTenant(context.Background(), tt.tenant)
+			var consistency *domain.CausalConsistency
+			if tt.consistent {
+				token := tt.token
+				if token == earlier {
+					token = first.Token()
+				}
+				ctx, consistency = domain.WithCausalConsistency(ctx, token)
+			}
+			if _, err := users.GetUserByID(ctx, "u1"); err != nil {
+				t.Fatalf("GetUserByID() error = %v", err)
+			}
+
+			commands := server.Commands()
+			var after *primitive.Timestamp
+			if seconds, increment, ok := commands[len(commands)-1].Body.Lookup("readConcern", "afterClusterTime").TimestampOK(); ok {
+				after = &primitive.Timestamp{T: seconds, I: increment}
+			}
+			wantAfter := map[string]*primitive.Timestamp{earlier: &position.OperationTime, "later": &later.OperationTime}[tt.wantAfter]
+			if !reflect.DeepEqual(after, wantAfter) {
+				t.Errorf("afterClusterTime = %v, want %v", after, wantAfter)
+			}
+
+			if consistency == nil {
+				return
+			}
+			reached, ok := decodeCausalPosition(consistency.Token())
+			switch {
+			case !ok:
+				t.Errorf("position reached %q is invalid", consistency.Token())
+			case tt.wantKept && !reflect.DeepEqual(reached, later):
+				t.Errorf("position reached = %+v, want %+v", reached, later)
+			case !tt.wantKept && (reached.TenantID != tt.tenant || !reached.OperationTime.After(position.OperationTime)):
+				t.Errorf("position reached = %+v, want one of %s after %v", reached, tt.tenant, position.OperationTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+var _ ports.HealthChecker = (*DatabaseHealthChecker)(nil)
+
+// DatabaseHealthChecker checks the database by pinging its primary, the server handling the writes,
+// and reports the version of the server
+type DatabaseHealthChecker struct {
+	db *mongo.Database
+}
+
+func NewDatabaseHealthChecker(db *mongo.Database) *DatabaseHealthChecker {
+	return &DatabaseHealthChecker{
+		db: db,
+	}
+}
+
+func (c *DatabaseHealthChecker) CheckHealth(ctx context.Context) ports.DependencyHealth {
+	start := time.Now()
+	if err := c.db.Client().Ping(ctx, readpref.Primary()); err != nil {
+		return ports.DependencyHealth{Status: ports.HealthDown, Error: err.Error()}
+	}
+	health := ports.DependencyHealth{Status: ports.HealthUp, LatencyMS: latencyMS(time.Since(start))}
+
+	var info struct {
+		Version string `bson:"version"`
+	}
+	if err := c.db.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err == nil {
+		health.Version = info.Version
+	}
+	return health
+}
+
+// latencyMS returns the duration in milliseconds, rounded to the microsecond
+func latencyMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// deadlineCollection runs the operations started without a deadline with the deadline of their kind,
+// so a caller forgetting one never waits on the database forever. The cursors of Find and Aggregate
+// read every batch within the deadline of their query.
+type deadlineCollection struct {
+	*mongo.Collection
+	deadlines ports.DeadlinePolicy
+}
+
+// withDefaultDeadline returns ctx with the deadline timeout from now, unless it has one already or
+// timeout is zero
+func withDefaultDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// FindOne reads the document before returning, so the result is decoded after the deadline is released
+func (c deadlineCollection) FindOne(ctx context.Context, filter any, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.FindOne)
+	defer cancel()
+	result := c.Collection.FindOne(ctx, filter, opts...)
+	_ = result.Err()
+	return result
+}
+
+func (c deadlineCollection) Find(ctx context.Context, filter any, opts ...*options.FindOptions) (*deadlineCursor, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Find)
+	cursor, err := c.Collection.Find(ctx, filter, opts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &deadlineCursor{Cursor: cursor, deadline: ctx, cancel: cancel}, nil
+}
+
+// FindStream is Find for the cursors read for as long as their reader takes, such as those of the
+// exports streamed to a client: only the query up to its first batch is bounded
+func (c deadlineCollection) FindStream(ctx context.Context, filter any, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Find)
+	defer cancel()
+	return c.Collection.Find(ctx, filter, opts...)
+}
+
+func (c deadlineCollection) CountDocuments(ctx context.Context, filter any, opts ...*options.CountOptions) (int64, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Find)
+	defer cancel()
+	return c.Collection.CountDocuments(ctx, filter, opts...)
+}
+
+func (c deadlineCollection) Aggregate(ctx context.Context, pipeline any, opts ...*options.AggregateOptions) (*deadlineCursor, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Find)
+	cursor, err := c.Collection.Aggregate(ctx, pipeline, opts...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &deadlineCursor{Cursor: cursor, deadline: ctx, cancel: cancel}, nil
+}
+
+func (c deadlineCollection) Distinct(ctx context.Context, fieldName string, filter any, opts ...*options.DistinctOptions) ([]any, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Find)
+	defer cancel()
+	return c.Collection.Distinct(ctx, fieldName, filter, opts...)
+}
+
+func (c deadlineCollection) InsertOne(ctx context.Context, document any, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Write)
+	defer cancel()
+	return c.Collection.InsertOne(ctx, document, opts...)
+}
+
+func (c deadlineCollection) InsertMany(ctx context.Context, documents []any, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Write)
+	defer cancel()
+	return c.Collection.InsertMany(ctx, documents, opts...)
+}
+
+func (c deadlineCollection) UpdateOne(ctx context.Context, filter any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Write)
+	defer cancel()
+	return c.Collection.UpdateOne(ctx, filter, update, opts...)
+}
+
+func (c deadlineCollection) UpdateMany(ctx context.Context, filter any, update any, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Write)
+	defer cancel()
+	return c.Collection.UpdateMany(ctx, filter, update, opts...)
+}
+
+func (c deadlineCollection) ReplaceOne(ctx context.Context, filter any, replacement any, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Write)
+	defer cancel()
+	return c.Collection.ReplaceOne(ctx, filter, replacement, opts...)
+}
+
+// FindOneAndUpdate writes the document before returning, like FindOne
+func (c deadlineCollection) FindOneAndUpdate(ctx context.Context, filter any, update any, opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Write)
+	defer cancel()
+	return c.Collection.FindOneAndUpdate(ctx, filter, update, opts...)
+}
+
+func (c deadlineCollection) DeleteOne(ctx context.Context, filter any, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Write)
+	defer cancel()
+	return c.Collection.DeleteOne(ctx, filter, opts...)
+}
+
+func (c deadlineCollection) DeleteMany(ctx context.Context, filter any, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	ctx, cancel := withDefaultDeadline(ctx, c.deadlines.Write)
+	defer cancel()
+	return c.Collection.DeleteMany(ctx, filter, opts...)
+}
+
+// deadlineCursor keeps the context of the query that opened the cursor until it is closed, so the
+// getMore reading its next batches are bounded by the deadline of the query too, whatever the context
+// the cursor is iterated with
+type deadlineCursor struct {
+	*mongo.Cursor
+	deadline context.Context
+	cancel   context.CancelFunc
+}
+
+// within returns ctx bounded by the deadline of the query as well, when it has one
+func (c *deadlineCursor) within(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := c.deadline.Deadline(); ok {
+		return context.WithDeadline(ctx, deadline)
+	}
+	return ctx, func() {}
+}
+
+func (c *deadlineCursor) Next(ctx context.Context) bool {
+	ctx, cancel := c.within(ctx)
+	defer cancel()
+	return c.Cursor.Next(ctx)
+}
+
+func (c *deadlineCursor) TryNext(ctx context.Context) bool {
+	ctx, cancel := c.within(ctx)
+	defer cancel()
+	return c.Cursor.TryNext(ctx)
+}
+
+// All closes the cursor, like mongo.Cursor.All
+func (c *deadlineCursor) All(ctx context.Context, results any) error {
+	defer c.cancel()
+	ctx, cancel := c.within(ctx)
+	defer cancel()
+	return c.Cursor.All(ctx, results)
+}
+
+func (c *deadlineCursor) Close(ctx context.Context) error {
+	defer c.cancel()
+	ctx, cancel := c.within(ctx)
+	defer cancel()
+	return c.Cursor.Close(ctx)
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/repository/repositorytest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestWithDefaultDeadline(t *testing.T) {
+	callerDeadline := time.Now().Add(time.Hour)
+	withDeadline, cancel := context.WithDeadline(context.Background(), callerDeadline)
+	defer cancel()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		timeout time.Duration
+		// want is the deadline from now, zero for none
+		want time.Duration
+	}{
+		{name: "no deadline", ctx: context.Background(), timeout: 5 * time.Second, want: 5 * time.Second},
+		{name: "deadline of the caller", ctx: withDeadline, timeout: 5 * time.Second, want: time.Until(callerDeadline)},
+		{name: "no deadline of its kind", ctx: context.Background()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := withDefaultDeadline(tt.ctx, tt.timeout)
+			defer cancel()
+			deadline, ok := ctx.Deadline()
+			if ok != (tt.want != 0) {
+				t.Fatalf("Deadline() = %v, %v, want one in %v", deadline, ok, tt.want)
+			}
+			if got := time.Until(deadline); ok && (got > tt.want || got < tt.want-time.Second) {
+				t.Errorf("Deadline() in %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// pagedUsers serves queries of users whose first batch has u1 and whose getMore has u2
+func pagedUsers(t *testing.T) *repositorytest.Server {
+	server := repositorytest.NewServer(t)
+	server.Reply = func(cmd repositorytest.Command) bson.D {
+		switch cmd.Name {
+		case "find", "aggregate":
+			return repositorytest.FirstBatch(cmd.Namespace(), 42, bson.M{"_id": "u1"})
+		case "getMore":
+			return repositorytest.NextBatch(cmd.Database+".users", 0, bson.M{"_id": "u2"})
+		}
+		return nil
+	}
+	return server
+}
+
+// cursor is what the tests read of a *mongo.Cursor or a *deadlineCursor
+type cursor interface {
+	Next(ctx context.Context) bool
+	Decode(v any) error
+	Err() error
+	Close(ctx context.Context) error
+}
+
+func TestDeadlineCursor(t *testing.T) {
+	const timeout = 50 * time.Millisecond
+	find := func(ctx context.Context, c deadlineCollection) (cursor, error) { return c.Find(ctx, bson.M{}) }
+	tests := []struct {
+		name string
+		open func(ctx context.Context, c deadlineCollection) (cursor, error)
+		// pause is how long the reader takes with each user
+		pause         time.Duration
+		callerTimeout time.Duration // Zero for a caller without a deadline
+		wantIDs       []string
+	}{
+		{name: "find within the deadline", open: find, wantIDs: []string{"u1", "u2"}},
+		{name: "find past the deadline", open: find, pause: 2 * timeout, wantIDs: []string{"u1"}},
+		{
+			name: "aggregate past the deadline",
+			open: func(ctx context.Context, c deadlineCollection) (cursor, error) {
+				return c.Aggregate(ctx, mongo.Pipeline{})
+			},
+			pause:   2 * timeout,
+			wantIDs: []string{"u1"},
+		},
+		{name: "deadline of the caller", open: find, pause: 2 * timeout, callerTimeout: time.Hour, wantIDs: []string{"u1", "u2"}},
+		{
+			name: "stream past the deadline",
+			open: func(ctx context.Context, c deadlineCollection) (cursor, error) {
+				return c.FindStream(ctx, bson.M{})
+			},
+			pause:   2 * timeout,
+			wantIDs: []string{"u1", "u2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := pagedUsers(t)
+			c := deadlineCollection{server.Client(t).Database("app").Collection("users"), ports.DeadlinePolicy{Find: timeout}}
+			ctx := context.Background()
+			if tt.callerTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.callerTimeout)
+				defer cancel()
+			}
+
+			users, err := tt.open(ctx, c)
+			if err != nil {
+				t.Fatalf("opening the cursor: %v", err)
+			}
+			defer users.Close(context.Background())
+
+			// The users are read with a context without deadline
+			var ids []string
+			for users.Next(context.Background()) {
+				var user domain.User
+				if err := users.Decode(&user); err != nil {
+					t.Fatalf("Decode() error = %v", err)
+				}
+				ids = append(ids, user.ID)
+				time.Sleep(tt.pause)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Fatalf("read %v, want %v (error %v)", ids, tt.wantIDs, users.Err())
+			}
+			if len(ids) < 2 && !errors.Is(users.Err(), context.DeadlineExceeded) {
+				t.Errorf("Err() = %v, want %v", users.Err(), context.DeadlineExceeded)
+			}
+		})
+	}
+}
+
+func TestDeadlineCursorAll(t *testing.T) {
+	server := pagedUsers(t)
+	c := deadlineCollection{server.Client(t).Database("app").Collection("users"), ports.DefaultDeadlinePolicy()}
+
+	cursor, err := c.Find(context.Background(), bson.M{})
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+	var users []domain.User
+	if err := cursor.All(context.Background(), &users); err != nil {
+		t.Fatalf("All() error = %v", err)
+	}
+	if len(users) != 2 || users[0].ID != "u1" || users[1].ID != "u2" {
+		t.Errorf("All() = %+v, want u1 and u2", users)
+	}
+	// The deadline of the query is released with the cursor
+	if err := cursor.deadline.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("deadline context error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestDeadlineFindOne(t *testing.T) {
+	server := repositorytest.NewServer(t)
+	server.Reply = func(cmd repositorytest.Command) bson.D {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	}
+	c := deadlineCollection{server.Client(t).Database("app").Collection("users"), ports.DeadlinePolicy{FindOne: 20 * time.Millisecond}}
+
+	err := c.FindOne(context.Background(), bson.M{"_id": "u1"}).Err()
+	if !mongo.IsTimeout(err) {
+		t.Errorf("FindOne() error = %v, want a timeout", err)
+	}
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.DigestRepository = (*DigestRepository)(nil)
+
+// DigestRepository counts the activity of every tenant in the users and audit_logs collections of
+// every database
+type DigestRepository struct {
+	users     tenantCollection
+	auditLogs tenantCollection
+}
+
+func NewDigestRepository(databases *TenantDatabases, usersCollection, auditCollection string) *DigestRepository {
+	return &DigestRepository{
+		users:     newTenantCollection(databases, usersCollection),
+		auditLogs: newTenantCollection(databases, auditCollection),
+	}
+}
+
+func (r *DigestRepository) CountAdminDigests(ctx context.Context, from, to time.Time) ([]*domain.AdminDigest, error) {
+	period := bson.M{"$gte": from, "$lt": to}
+	during := func(field string) bson.M {
+		value := bson.M{"$ifNull": bson.A{field, to}}
+		return bson.M{"$cond": bson.A{bson.M{"$and": bson.A{bson.M{"$gte": bson.A{value, from}}, bson.M{"$lt": bson.A{value, to}}}}, 1, 0}}
+	}
+	digests := map[string]*domain.AdminDigest{}
+	digest := func(tenantID string) *domain.AdminDigest {
+		if digests[tenantID] == nil {
+			digests[tenantID] = &domain.AdminDigest{TenantID: tenantID, From: from, To: to}
+		}
+		return digests[tenantID]
+	}
+	auditLogs := r.auditLogs.listAll()
+	for i, users := range r.users.listAll() {
+		cursor, err := users.Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{"$or": bson.A{
+				bson.M{"created_at": period},
+				bson.M{"deleted_at": period},
+				bson.M{"deactivated_at": period},
+			}}}},
+			{{Key: "$group", Value: bson.M{
+				"_id":         "$tenant_id",
+				"created":     bson.M{"$sum": during("$created_at")},
+				"deleted":     bson.M{"$sum": during("$deleted_at")},
+				"deactivated": bson.M{"$sum": during("$deactivated_at")},
+			}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var userCounts []struct {
+			TenantID    string `bson:"_id"`
+			Created     int64  `bson:"created"`
+			Deleted     int64  `bson:"deleted"`
+			Deactivated int64  `bson:"deactivated"`
+		}
+		if err := cursor.All(ctx, &userCounts); err != nil {
+			return nil, err
+		}
+
+		cursor, err = auditLogs[i].Aggregate(ctx, mongo.Pipeline{
+			{{Key: "$match", Value: bson.M{
+				"action":     bson.M{"$in": bson.A{domain.AuditActionLoginFailed, domain.AuditActionLoginReported}},
+				"created_at": period,
+			}}},
+			{{Key: "$group", Value: bson.M{
+				"_id":     bson.M{"tenant_id": "$tenant_id", "action": "$action"},
+				"count":   bson.M{"$sum": 1},
+				"targets": bson.M{"$addToSet": "$target_id"},
+			}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		var events []struct {
+			ID struct {
+				TenantID string `bson:"tenant_id"`
+				Action   string `bson:"action"`
+			} `bson:"_id"`
+			Count   int64    `bson:"count"`
+			Targets []string `bson:"targets"`
+		}
+		if err := cursor.All(ctx, &events); err != nil {
+			return nil, err
+		}
+
+		for _, count := range userCounts {
+			d := digest(count.TenantID)
+			d.Registrations += count.Created
+			d.Deletions += count.Deleted
+			d.DeactivatedAccounts += count.Deactivated
+		}
+		for _, count := range events {
+			d := digest(count.ID.TenantID)
+			if count.ID.Action == domain.AuditActionLoginFailed {
+				d.FailedLogins += count.Count
+			} else {
+				// Reporting a login twice locks the account once
+				d.LockedAccounts += int64(len(count.Targets))
+			}
+		}
+	}
+
+	result := make([]*domain.AdminDigest, 0, len(digests))
+	for _, d := range digests {
+		result = append(result, d)
+	}
+	return result, nil
+}
+
+func (r *DigestRepository) ListDigestRecipients(ctx context.Context) ([]string, error) {
+	filter, err := activeUsers(ctx, bson.M{
+		"$or":            bson.A{bson.M{"roles": domain.RoleAdmin}, bson.M{"roles": bson.M{"$exists": false}, "role": domain.RoleAdmin}},
+		"deactivated_at": bson.M{"$exists": false},
+	})
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := r.users.in(ctx).Find(ctx, filter, options.Find().SetProjection(bson.M{"email": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var admins []struct {
+		Email string `bson:"email"`
+	}
+	if err := cursor.All(ctx, &admins); err != nil {
+		return nil, err
+	}
+	emails := make([]string, 0, len(admins))
+	for _, admin := range admins {
+		emails = append(emails, admin.Email)
+	}
+	return emails, nil
+}
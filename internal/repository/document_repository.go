@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.DocumentRepository = (*DocumentRepository)(nil)
+
+type DocumentRepository struct {
+	collection tenantCollection
+}
+
+func NewDocumentRepository(databases *TenantDatabases, collectionName string) *DocumentRepository {
+	return &DocumentRepository{
+		collection: newTenantCollection(databases, collectionName),
+	}
+}
+
+func (r *DocumentRepository) CreateDocument(ctx context.Context, document *domain.Document) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	document.TenantID = tenantID
+
+	_, err := r.collection.in(ctx).InsertOne(ctx, document)
+	return err
+}
+
+func (r *DocumentRepository) GetDocument(ctx context.Context, userID, documentID string) (*domain.Document, error) {
+	filter, err := tenantScoped(ctx, bson.M{"_id": documentID, "user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	var document domain.Document
+	if err := r.collection.in(ctx).FindOne(ctx, filter).Decode(&document); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (r *DocumentRepository) ListDocuments(ctx context.Context, userID string) ([]*domain.Document, error) {
+	filter, err := tenantScoped(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.collection.in(ctx).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	documents := make([]*domain.Document, 0)
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+	return documents, nil
+}
+
+func (r *DocumentRepository) SetDocumentsStatus(ctx context.Context, userID string, documentIDs []string, status string) error {
+	filter, err := tenantScoped(ctx, bson.M{"_id": bson.M{"$in": documentIDs}, "user_id": userID})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.collection.in(ctx).UpdateMany(ctx, filter, bson.M{"$set": bson.M{"status": status, "updated_at": time.Now()}})
+	return err
+}
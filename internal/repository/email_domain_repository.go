@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.EmailDomainRepository = (*EmailDomainRepository)(nil)
+
+type EmailDomainRepository struct {
+	collection tenantCollection
+}
+
+func NewEmailDomainRepository(databases *TenantDatabases, collectionName string) *EmailDomainRepository {
+	return &EmailDomainRepository{
+		collection: newTenantCollection(databases, collectionName),
+	}
+}
+
+func (r *EmailDomainRepository) CreateRule(ctx context.Context, rule *domain.EmailDomainRule) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	rule.TenantID = tenantID
+
+	if _, err := r.collection.in(ctx).InsertOne(ctx, rule); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrEmailDomainRuleExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *EmailDomainRepository) ListRules(ctx context.Context) ([]*domain.EmailDomainRule, error) {
+	filter, err := tenantScoped(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.collection.in(ctx).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "list", Value: 1}, {Key: "domain", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	rules := make([]*domain.EmailDomainRule, 0)
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *EmailDomainRepository) DeleteRule(ctx context.Context, list, emailDomain string) (bool, error) {
+	filter, err := tenantScoped(ctx, bson.M{"list": list, "domain": emailDomain})
+	if err != nil {
+		return false, err
+	}
+	result, err := r.collection.in(ctx).DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,605 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The indexes below mirror scripts/mongo-init.js so they can be rebuilt on existing databases.
+// Creating an index that already exists with the same definition is a no-op.
+
+// EnsureIndexes creates the indexes of the users collection, first giving their tenant to the users
+// created before tenancy and their GeoJSON point to the addresses located before the distance filter
+func (r *UserRepository) EnsureIndexes(ctx context.Context) error {
+	if err := backfillTenantID(ctx, r.collection.all()...); err != nil {
+		return err
+	}
+	if err := r.backfillAddressGeo(ctx); err != nil {
+		return err
+	}
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *UserRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.collection.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "email", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_email_unique_idx"),
+			},
+			{
+				Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "normalized_email", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_normalized_email_unique_partial_idx").
+					SetPartialFilterExpression(bson.M{"normalized_email": bson.M{"$exists": true}}),
+			},
+			{
+				Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "username", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_username_unique_partial_idx").
+					SetPartialFilterExpression(bson.M{"username": bson.M{"$exists": true}}),
+			},
+			{
+				Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "directory_id", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_directory_id_unique_partial_idx").
+					SetPartialFilterExpression(bson.M{"directory_id": bson.M{"$exists": true}}),
+			},
+			{
+				Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "external_identity.issuer", Value: 1}, {Key: "external_identity.subject", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_external_identity_unique_partial_idx").
+					SetPartialFilterExpression(bson.M{"external_identity": bson.M{"$exists": true}}),
+			},
+			{
+				Keys:    bson.D{{Key: "profile.first_name", Value: 1}, {Key: "profile.last_name", Value: 1}},
+				Options: options.Index().SetName("name_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "profile.first_name", Value: 1}},
+				Options: options.Index().SetName("tenant_first_name_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "profile.last_name", Value: 1}},
+				Options: options.Index().SetName("tenant_last_name_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "profile.phone", Value: 1}},
+				Options: options.Index().SetSparse(true).SetName("phone_sparse_idx"),
+			},
+			{
+				Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "profile.nin", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_nin_unique_partial_idx").
+					SetPartialFilterExpression(bson.M{"profile.nin": bson.M{"$exists": true}}),
+			},
+			{
+				Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "verification.check.provider", Value: 1}, {Key: "verification.check.id", Value: 1}},
+				Options: options.Index().SetName("tenant_verification_check_partial_idx").
+					SetPartialFilterExpression(bson.M{"verification.check": bson.M{"$exists": true}}),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: 1}},
+				Options: options.Index().SetName("tenant_created_at_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "last_seen_at", Value: 1}},
+				Options: options.Index().SetName("tenant_last_seen_at_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "deleted_at", Value: 1}},
+				Options: options.Index().SetSparse(true).SetName("deleted_at_sparse_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tags", Value: 1}},
+				Options: options.Index().SetName("tags_multikey_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "metadata.$**", Value: 1}},
+				Options: options.Index().SetName("metadata_wildcard_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "profile.addresses.geo", Value: "2dsphere"}},
+				Options: options.Index().SetName("tenant_address_geo_idx"),
+			},
+		}},
+	}
+}
+
+// PendingMigrations returns the changes started after the fact that some stored users are still
+// waiting for, as "<name>: <remedy>", across tenants and their databases
+func (r *UserRepository) PendingMigrations(ctx context.Context) ([]string, error) {
+	migrations := []struct {
+		name   string
+		filter bson.M
+		remedy string
+	}{
+		{"tenant_id", withoutTenant, "users created before tenancy are hidden from every tenant, run admincli reindex"},
+		{"address_geo", addressesWithoutGeo, "located addresses miss the GeoJSON point of the distance filter, run admincli reindex"},
+		{"legacy_address", bson.M{"profile.address": bson.M{"$exists": true}}, "profiles keep their single address until the user is written"},
+	}
+	var pending []string
+	for _, migration := range migrations {
+		for _, users := range r.collection.all() {
+			count, err := users.CountDocuments(ctx, migration.filter, options.Count().SetLimit(1))
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				pending = append(pending, migration.name+": "+migration.remedy)
+				break
+			}
+		}
+	}
+	return pending, nil
+}
+
+// withoutTenant matches the documents created before tenancy, which tenantScoped never matches
+var withoutTenant = bson.M{"tenant_id": bson.M{"$exists": false}}
+
+// backfillTenantID gives the documents of collections created before tenancy the tenant of ctx, the
+// default tenant unless admincli is given another one
+func backfillTenantID(ctx context.Context, collections ...*mongo.Collection) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		tenantID = domain.DefaultTenantID
+	}
+	for _, collection := range collections {
+		if _, err := collection.UpdateMany(ctx, withoutTenant, bson.M{"$set": bson.M{"tenant_id": tenantID}}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addressesWithoutGeo matches the users with a located address missing its GeoJSON point
+var addressesWithoutGeo = bson.M{"profile.addresses": bson.M{"$elemMatch": bson.M{"location": bson.M{"$exists": true}, "geo": bson.M{"$exists": false}}}}
+
+// backfillAddressGeo sets the GeoJSON point of the located addresses missing one, see indexLocations
+func (r *UserRepository) backfillAddressGeo(ctx context.Context) error {
+	for _, users := range r.collection.all() {
+		_, err := users.UpdateMany(ctx, addressesWithoutGeo,
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{"profile.addresses": bson.M{"$map": bson.M{
+				"input": "$profile.addresses",
+				"in": bson.M{"$cond": bson.A{
+					bson.M{"$gt": bson.A{"$$this.location", nil}},
+					bson.M{"$mergeObjects": bson.A{"$$this", bson.M{"geo": bson.M{
+						"type":        "Point",
+						"coordinates": bson.A{"$$this.location.lng", "$$this.location.lat"},
+					}}}},
+					"$$this",
+				}},
+			}}}}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureIndexes creates the indexes of the organizations and memberships collections, first giving
+// their tenant to those created before tenancy
+func (r *OrganizationRepository) EnsureIndexes(ctx context.Context) error {
+	if err := backfillTenantID(ctx, append(r.organizations.all(), r.memberships.all()...)...); err != nil {
+		return err
+	}
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *OrganizationRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.organizations.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "slug", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_slug_unique_idx"),
+			},
+		}},
+		{r.memberships.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "organization_id", Value: 1}, {Key: "user_id", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("organization_user_unique_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}},
+				Options: options.Index().SetName("tenant_user_id_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the roles collection
+func (r *RoleRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *RoleRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.collection.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "name", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_name_unique_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the audit_logs collection
+func (r *AuditRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *AuditRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.collection.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("tenant_created_at_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("tenant_actor_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "target_id", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("tenant_target_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "action", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("action_created_at_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the login_events collection
+func (r *LoginEventRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *LoginEventRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.collection.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "fingerprint", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("tenant_user_fingerprint_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("tenant_user_created_at_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "created_at", Value: 1}},
+				Options: options.Index().SetName("created_at_idx"),
+			},
+			{
+				Keys: bson.D{{Key: "report_token_hash", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("report_token_hash_unique_partial_idx").
+					SetPartialFilterExpression(bson.M{"report_token_hash": bson.M{"$exists": true}}),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the sessions collection, which drops the expired sessions
+func (r *SessionRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *SessionRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.collection.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}},
+				Options: options.Index().SetName("tenant_user_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the signing keys collection
+func (r *SigningKeyRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *SigningKeyRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{[]*mongo.Collection{r.collection}, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the oauth_clients collection
+func (r *OAuthClientRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *OAuthClientRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.collection.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: 1}},
+				Options: options.Index().SetName("tenant_created_at_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the read model of the user lists, one per sort field, with
+// the tenant first
+func (r *UserReadModelRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *UserReadModelRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{[]*mongo.Collection{r.collection}, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: 1}},
+				Options: options.Index().SetName("tenant_created_at_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "updated_at", Value: 1}},
+				Options: options.Index().SetName("tenant_updated_at_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "email", Value: 1}},
+				Options: options.Index().SetName("tenant_email_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "first_name", Value: 1}},
+				Options: options.Index().SetName("tenant_first_name_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "last_name", Value: 1}},
+				Options: options.Index().SetName("tenant_last_name_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "tags", Value: 1}},
+				Options: options.Index().SetName("tenant_tags_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the user_history collection, which number the versions of
+// each user once
+func (r *UserHistoryRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *UserHistoryRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.collection.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "version", Value: -1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_user_version_unique_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the email domain rules collection
+func (r *EmailDomainRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *EmailDomainRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.collection.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "list", Value: 1}, {Key: "domain", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_list_domain_unique_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the terms_versions and terms_acceptances collections
+func (r *TermsRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *TermsRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.versions.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "document", Value: 1}, {Key: "version", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_document_version_unique_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "document", Value: 1}, {Key: "published_at", Value: -1}},
+				Options: options.Index().SetName("tenant_document_published_at_idx"),
+			},
+		}},
+		{r.acceptances.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "accepted_at", Value: -1}},
+				Options: options.Index().SetName("tenant_user_accepted_at_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the jobs collection
+func (r *JobRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *JobRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{[]*mongo.Collection{r.collection}, []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "status", Value: 1}, {Key: "type", Value: 1}, {Key: "run_at", Value: 1}},
+				Options: options.Index().SetName("status_type_run_at_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("tenant_status_created_at_idx"),
+			},
+			{
+				Keys: bson.D{{Key: "unique_key", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("unique_key_unique_partial_idx").
+					SetPartialFilterExpression(bson.M{"unique_key": bson.M{"$exists": true}}),
+			},
+			{
+				Keys:    bson.D{{Key: "expires_at", Value: 1}},
+				Options: options.Index().SetExpireAfterSeconds(0).SetName("expires_at_ttl_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the user_stats collection
+func (r *StatsRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *StatsRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.stats.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "day", Value: 1}},
+				Options: options.Index().SetName("tenant_day_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the relationships and blocks collections
+func (r *RelationshipRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *RelationshipRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.blocks.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "blocker_id", Value: 1}, {Key: "blocked_id", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_blocker_blocked_unique_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "blocked_id", Value: 1}, {Key: "blocker_id", Value: 1}},
+				Options: options.Index().SetName("tenant_blocked_blocker_idx"),
+			},
+		}},
+		{r.collection.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "follower_id", Value: 1}, {Key: "followee_id", Value: 1}},
+				Options: options.Index().SetUnique(true).SetName("tenant_follower_followee_unique_idx"),
+			},
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "followee_id", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("tenant_followee_created_at_idx"),
+			},
+		}},
+	}
+}
+
+// EnsureIndexes creates the indexes of the documents collection
+func (r *DocumentRepository) EnsureIndexes(ctx context.Context) error {
+	return createIndexes(ctx, r.indexes())
+}
+
+func (r *DocumentRepository) indexes() []collectionIndexes {
+	return []collectionIndexes{
+		{r.collection.all(), []mongo.IndexModel{
+			{
+				Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}},
+				Options: options.Index().SetName("tenant_user_created_at_idx"),
+			},
+		}},
+	}
+}
+
+// collectionIndexes are the indexes of a collection, in each database it has
+type collectionIndexes struct {
+	collections []*mongo.Collection
+	models      []mongo.IndexModel
+}
+
+// IndexedRepository is a repository of this package creating the indexes of its collections
+type IndexedRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	indexes() []collectionIndexes
+}
+
+// MissingIndex is an index of a repository a database does not have
+type MissingIndex struct {
+	Database   string
+	Collection string
+	Name       string
+	// Unique indexes enforce invariants, such as one user per email address, the others speed up queries
+	Unique bool
+}
+
+// MissingIndexes returns the indexes of the repositories missing from the database, matched by name
+// as EnsureIndexes creates none that exists by the same name
+func MissingIndexes(ctx context.Context, repos ...IndexedRepository) ([]MissingIndex, error) {
+	var missing []MissingIndex
+	for _, repo := range repos {
+		for _, index := range repo.indexes() {
+			for _, collection := range index.collections {
+				existing, err := indexNames(ctx, collection)
+				if err != nil {
+					return nil, err
+				}
+				for _, model := range index.models {
+					name := *model.Options.Name
+					if !existing[name] {
+						unique := model.Options.Unique != nil && *model.Options.Unique
+						missing = append(missing, MissingIndex{
+							Database:   collection.Database().Name(),
+							Collection: collection.Name(),
+							Name:       name,
+							Unique:     unique,
+						})
+					}
+				}
+			}
+		}
+	}
+	return missing, nil
+}
+
+// indexNames returns the names of the indexes of the collection, none when it does not exist yet
+func indexNames(ctx context.Context, collection *mongo.Collection) (map[string]bool, error) {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.Name == "NamespaceNotFound" {
+			return map[string]bool{}, nil
+		}
+		return nil, err
+	}
+	var indexes []struct {
+		Name string `bson:"name"`
+	}
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(indexes))
+	for _, index := range indexes {
+		names[index.Name] = true
+	}
+	return names, nil
+}
+
+func createIndexes(ctx context.Context, indexes []collectionIndexes) error {
+	for _, index := range indexes {
+		for _, collection := range index.collections {
+			if _, err := collection.Indexes().CreateMany(ctx, index.models); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
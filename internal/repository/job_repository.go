@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.JobRepository = (*JobRepository)(nil)
+
+// JobRepository stores the background jobs; the workers claim the jobs of every tenant
+type JobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewJobRepository(db *mongo.Database, collectionName string) *JobRepository {
+	return &JobRepository{
+		collection: db.Collection(collectionName),
+	}
+}
+
+func (r *JobRepository) CreateJob(ctx context.Context, job *domain.Job) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	job.TenantID = tenantID
+
+	if _, err := r.collection.InsertOne(ctx, job); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrJobExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *JobRepository) ClaimJob(ctx context.Context, types []string, now, lockedUntil time.Time) (*domain.Job, error) {
+	filter := bson.M{
+		"type": bson.M{"$in": types},
+		"$or": bson.A{
+			bson.M{"status": domain.JobStatusQueued, "run_at": bson.M{"$lte": now}},
+			bson.M{"status": domain.JobStatusRunning, "locked_until": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{"status": domain.JobStatusRunning, "run_at": now, "locked_until": lockedUntil, "updated_at": now},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "run_at", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	var job domain.Job
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *JobRepository) UpdateJob(ctx context.Context, job *domain.Job) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": job.ID, "tenant_id": job.TenantID}, job)
+	return err
+}
+
+func (r *JobRepository) ListJobs(ctx context.Context, query *ports.JobQuery) (*ports.JobQueryResult, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+
+	filter := bson.M{}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+	if query.Type != "" {
+		filter["type"] = query.Type
+	}
+	filter, err := tenantScoped(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((query.Page - 1) * query.PageSize)).
+		SetLimit(int64(query.PageSize))
+	cursor, err := r.collection.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	jobs := make([]*domain.Job, 0, query.PageSize)
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, err
+	}
+
+	return &ports.JobQueryResult{
+		Jobs:       jobs,
+		TotalCount: totalCount,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: int(totalCount+int64(query.PageSize)-1) / query.PageSize,
+	}, nil
+}
+
+func (r *JobRepository) GetJob(ctx context.Context, id string) (*domain.Job, error) {
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	var job domain.Job
+	if err := r.collection.FindOne(ctx, filter).Decode(&job); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *JobRepository) RetryJob(ctx context.Context, job *domain.Job) (bool, error) {
+	filter, err := tenantScoped(ctx, bson.M{"_id": job.ID, "status": domain.JobStatusFailed})
+	if err != nil {
+		return false, err
+	}
+	result, err := r.collection.ReplaceOne(ctx, filter, job)
+	if err != nil {
+		return false, err
+	}
+	return result.ModifiedCount > 0, nil
+}
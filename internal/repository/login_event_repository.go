@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.LoginEventRepository = (*LoginEventRepository)(nil)
+
+type LoginEventRepository struct {
+	collection tenantCollection
+}
+
+func NewLoginEventRepository(databases *TenantDatabases, collectionName string) *LoginEventRepository {
+	return &LoginEventRepository{
+		collection: newTenantCollection(databases, collectionName),
+	}
+}
+
+func (r *LoginEventRepository) CreateLoginEvent(ctx context.Context, event *domain.LoginEvent) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	event.TenantID = tenantID
+
+	_, err := r.collection.in(ctx).InsertOne(ctx, event)
+	return err
+}
+
+func (r *LoginEventRepository) LastLoginEvent(ctx context.Context, userID, fingerprint string) (*domain.LoginEvent, error) {
+	filter := bson.M{"user_id": userID}
+	if fingerprint != "" {
+		filter["fingerprint"] = fingerprint
+	}
+	return r.findOne(ctx, filter, options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+}
+
+func (r *LoginEventRepository) ListLoginEvents(ctx context.Context, userID string, page, pageSize int) (*ports.LoginHistory, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+	filter, err := tenantScoped(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := r.collection.in(ctx).CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((page - 1) * pageSize)).
+		SetLimit(int64(pageSize))
+	cursor, err := r.collection.in(ctx).Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := make([]*domain.LoginEvent, 0, pageSize)
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+
+	return &ports.LoginHistory{
+		Events:     events,
+		TotalCount: totalCount,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int(totalCount+int64(pageSize)-1) / pageSize,
+	}, nil
+}
+
+func (r *LoginEventRepository) ListNewDeviceLogins(ctx context.Context, userID string, before time.Time, limit int) ([]*domain.LoginEvent, error) {
+	filter, err := tenantScoped(ctx, bson.M{"user_id": userID, "new_device": true, "created_at": bson.M{"$lt": before}})
+	if err != nil {
+		return nil, err
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.in(ctx).Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	events := make([]*domain.LoginEvent, 0, limit)
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *LoginEventRepository) GetLoginEventByReportToken(ctx context.Context, tokenHash string) (*domain.LoginEvent, error) {
+	return r.findOne(ctx, bson.M{"report_token_hash": tokenHash})
+}
+
+func (r *LoginEventRepository) SetLoginEventReported(ctx context.Context, id string, at time.Time) error {
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.in(ctx).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"reported_at": at}})
+	return err
+}
+
+// findOne returns the tenant login event matching filter, or nil if there is none
+func (r *LoginEventRepository) findOne(ctx context.Context, filter bson.M, opts ...*options.FindOneOptions) (*domain.LoginEvent, error) {
+	filter, err := tenantScoped(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var event domain.LoginEvent
+	if err := r.collection.in(ctx).FindOne(ctx, filter, opts...).Decode(&event); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &event, nil
+}
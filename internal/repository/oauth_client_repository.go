@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.OAuthClientRepository = (*OAuthClientRepository)(nil)
+
+type OAuthClientRepository struct {
+	collection tenantCollection
+}
+
+func NewOAuthClientRepository(databases *TenantDatabases, collectionName string) *OAuthClientRepository {
+	return &OAuthClientRepository{
+		collection: newTenantCollection(databases, collectionName),
+	}
+}
+
+func (r *OAuthClientRepository) CreateClient(ctx context.Context, client *domain.OAuthClient) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	client.TenantID = tenantID
+
+	_, err := r.collection.in(ctx).InsertOne(ctx, client)
+	return err
+}
+
+func (r *OAuthClientRepository) GetClientByID(ctx context.Context, id string) (*domain.OAuthClient, error) {
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	var client domain.OAuthClient
+	if err := r.collection.in(ctx).FindOne(ctx, filter).Decode(&client); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &client, nil
+}
+
+func (r *OAuthClientRepository) ListClients(ctx context.Context) ([]*domain.OAuthClient, error) {
+	filter, err := tenantScoped(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.collection.in(ctx).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	clients := make([]*domain.OAuthClient, 0)
+	if err := cursor.All(ctx, &clients); err != nil {
+		return nil, err
+	}
+	return clients, nil
+}
+
+func (r *OAuthClientRepository) DeleteClient(ctx context.Context, id string) (bool, error) {
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	result, err := r.collection.in(ctx).DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.OrganizationRepository = (*OrganizationRepository)(nil)
+
+type OrganizationRepository struct {
+	organizations tenantCollection
+	memberships   tenantCollection
+}
+
+func NewOrganizationRepository(databases *TenantDatabases, organizationsCollection, membershipsCollection string) *OrganizationRepository {
+	return &OrganizationRepository{
+		organizations: newTenantCollection(databases, organizationsCollection),
+		memberships:   newTenantCollection(databases, membershipsCollection),
+	}
+}
+
+func (r *OrganizationRepository) CreateOrganization(ctx context.Context, org *domain.Organization) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	org.TenantID = tenantID
+
+	_, err := r.organizations.in(ctx).InsertOne(ctx, org)
+	return err
+}
+
+func (r *OrganizationRepository) GetOrganizationByID(ctx context.Context, id string) (*domain.Organization, error) {
+	return r.findOrganization(ctx, bson.M{"_id": id})
+}
+
+func (r *OrganizationRepository) GetOrganizationBySlug(ctx context.Context, slug string) (*domain.Organization, error) {
+	return r.findOrganization(ctx, bson.M{"slug": slug})
+}
+
+func (r *OrganizationRepository) findOrganization(ctx context.Context, filter bson.M) (*domain.Organization, error) {
+	filter, err := tenantScoped(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var org domain.Organization
+	if err := r.organizations.in(ctx).FindOne(ctx, filter).Decode(&org); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &org, nil
+}
+
+func (r *OrganizationRepository) UpdateOrganization(ctx context.Context, org *domain.Organization) error {
+	filter, err := tenantScoped(ctx, bson.M{"_id": org.ID})
+	if err != nil {
+		return err
+	}
+	org.TenantID = filter["tenant_id"].(string)
+	org.UpdatedAt = time.Now()
+	_, err = r.organizations.in(ctx).UpdateOne(
+		ctx,
+		filter,
+		bson.M{"$set": org},
+	)
+	return err
+}
+
+// DeleteOrganization removes the organization together with all of its memberships
+func (r *OrganizationRepository) DeleteOrganization(ctx context.Context, id string) error {
+	membershipFilter, err := tenantScoped(ctx, bson.M{"organization_id": id})
+	if err != nil {
+		return err
+	}
+	if _, err := r.memberships.in(ctx).DeleteMany(ctx, membershipFilter); err != nil {
+		return err
+	}
+	orgFilter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	_, err = r.organizations.in(ctx).DeleteOne(ctx, orgFilter)
+	return err
+}
+
+func (r *OrganizationRepository) CreateMembership(ctx context.Context, membership *domain.Membership) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	membership.TenantID = tenantID
+
+	_, err := r.memberships.in(ctx).InsertOne(ctx, membership)
+	return err
+}
+
+func (r *OrganizationRepository) GetMembership(ctx context.Context, organizationID, userID string) (*domain.Membership, error) {
+	filter, err := tenantScoped(ctx, bson.M{"organization_id": organizationID, "user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	var membership domain.Membership
+	if err := r.memberships.in(ctx).FindOne(ctx, filter).Decode(&membership); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &membership, nil
+}
+
+func (r *OrganizationRepository) ListMembers(ctx context.Context, organizationID string) ([]*domain.Membership, error) {
+	return r.findMemberships(ctx, bson.M{"organization_id": organizationID})
+}
+
+func (r *OrganizationRepository) ListUserMemberships(ctx context.Context, userID string) ([]*domain.Membership, error) {
+	return r.findMemberships(ctx, bson.M{"user_id": userID})
+}
+
+func (r *OrganizationRepository) findMemberships(ctx context.Context, filter bson.M) ([]*domain.Membership, error) {
+	filter, err := tenantScoped(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	findOpts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.memberships.in(ctx).Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	memberships := make([]*domain.Membership, 0)
+	if err := cursor.All(ctx, &memberships); err != nil {
+		return nil, err
+	}
+	return memberships, nil
+}
+
+func (r *OrganizationRepository) UpdateMembershipRole(ctx context.Context, organizationID, userID, role string) error {
+	filter, err := tenantScoped(ctx, bson.M{"organization_id": organizationID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	_, err = r.memberships.in(ctx).UpdateOne(
+		ctx,
+		filter,
+		bson.M{"$set": bson.M{"role": role, "updated_at": time.Now()}},
+	)
+	return err
+}
+
+func (r *OrganizationRepository) CountMembersWithRole(ctx context.Context, organizationID, role string) (int64, error) {
+	filter, err := tenantScoped(ctx, bson.M{"organization_id": organizationID, "role": role})
+	if err != nil {
+		return 0, err
+	}
+	return r.memberships.in(ctx).CountDocuments(ctx, filter)
+}
+
+func (r *OrganizationRepository) DeleteMembership(ctx context.Context, organizationID, userID string) error {
+	filter, err := tenantScoped(ctx, bson.M{"organization_id": organizationID, "user_id": userID})
+	if err != nil {
+		return err
+	}
+	_, err = r.memberships.in(ctx).DeleteOne(ctx, filter)
+	return err
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.RelationshipRepository = (*RelationshipRepository)(nil)
+
+type RelationshipRepository struct {
+	collection tenantCollection
+	blocks     tenantCollection
+}
+
+func NewRelationshipRepository(databases *TenantDatabases, relationshipsCollection, blocksCollection string) *RelationshipRepository {
+	return &RelationshipRepository{
+		collection: newTenantCollection(databases, relationshipsCollection),
+		blocks:     newTenantCollection(databases, blocksCollection),
+	}
+}
+
+func (r *RelationshipRepository) CreateRelationship(ctx context.Context, relationship *domain.Relationship) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	relationship.TenantID = tenantID
+
+	if _, err := r.collection.in(ctx).InsertOne(ctx, relationship); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrRelationshipExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RelationshipRepository) GetRelationship(ctx context.Context, followerID, followeeID string) (*domain.Relationship, error) {
+	filter, err := tenantScoped(ctx, bson.M{"follower_id": followerID, "followee_id": followeeID})
+	if err != nil {
+		return nil, err
+	}
+
+	var relationship domain.Relationship
+	if err := r.collection.in(ctx).FindOne(ctx, filter).Decode(&relationship); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &relationship, nil
+}
+
+func (r *RelationshipRepository) DeleteRelationship(ctx context.Context, followerID, followeeID string) (bool, error) {
+	filter, err := tenantScoped(ctx, bson.M{"follower_id": followerID, "followee_id": followeeID})
+	if err != nil {
+		return false, err
+	}
+	result, err := r.collection.in(ctx).DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (r *RelationshipRepository) ListFollowers(ctx context.Context, query *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error) {
+	return r.listRelationships(ctx, bson.M{"followee_id": query.UserID}, query)
+}
+
+func (r *RelationshipRepository) ListFollowing(ctx context.Context, query *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error) {
+	return r.listRelationships(ctx, bson.M{"follower_id": query.UserID}, query)
+}
+
+func (r *RelationshipRepository) listRelationships(ctx context.Context, filter bson.M, query *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error) {
+	relationships := make([]*domain.Relationship, 0, query.PageSize)
+	totalCount, err := findPage(ctx, r.collection.in(ctx), filter, query, &relationships)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ports.RelationshipQueryResult{
+		Relationships: relationships,
+		TotalCount:    totalCount,
+		Page:          query.Page,
+		PageSize:      query.PageSize,
+		TotalPages:    int(totalCount+int64(query.PageSize)-1) / query.PageSize,
+	}, nil
+}
+
+// findPage decodes into results the page of the query among the tenant documents matching filter,
+// newest first, and returns the number of matching documents
+func findPage(ctx context.Context, collection deadlineCollection, filter bson.M, query *ports.RelationshipQuery, results any) (int64, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+	filter, err := tenantScoped(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	totalCount, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: 1}}).
+		SetSkip(int64((query.Page - 1) * query.PageSize)).
+		SetLimit(int64(query.PageSize))
+	cursor, err := collection.Find(ctx, filter, findOpts)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, results); err != nil {
+		return 0, err
+	}
+	return totalCount, nil
+}
+
+func (r *RelationshipRepository) CountRelationships(ctx context.Context, userID string) (*domain.RelationshipCounts, error) {
+	followers, err := tenantScoped(ctx, bson.M{"followee_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	counts := &domain.RelationshipCounts{}
+	if counts.Followers, err = r.collection.in(ctx).CountDocuments(ctx, followers); err != nil {
+		return nil, err
+	}
+	following, err := tenantScoped(ctx, bson.M{"follower_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	if counts.Following, err = r.collection.in(ctx).CountDocuments(ctx, following); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+func (r *RelationshipRepository) CreateBlock(ctx context.Context, block *domain.Block) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	block.TenantID = tenantID
+
+	if _, err := r.blocks.in(ctx).InsertOne(ctx, block); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrBlockExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *RelationshipRepository) GetBlock(ctx context.Context, blockerID, blockedID string) (*domain.Block, error) {
+	filter, err := tenantScoped(ctx, bson.M{"blocker_id": blockerID, "blocked_id": blockedID})
+	if err != nil {
+		return nil, err
+	}
+
+	var block domain.Block
+	if err := r.blocks.in(ctx).FindOne(ctx, filter).Decode(&block); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &block, nil
+}
+
+func (r *RelationshipRepository) DeleteBlock(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	filter, err := tenantScoped(ctx, bson.M{"blocker_id": blockerID, "blocked_id": blockedID})
+	if err != nil {
+		return false, err
+	}
+	result, err := r.blocks.in(ctx).DeleteOne(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+	return result.DeletedCount > 0, nil
+}
+
+func (r *RelationshipRepository) ListBlocks(ctx context.Context, query *ports.RelationshipQuery) (*ports.BlockQueryResult, error) {
+	blocks := make([]*domain.Block, 0, query.PageSize)
+	totalCount, err := findPage(ctx, r.blocks.in(ctx), bson.M{"blocker_id": query.UserID}, query, &blocks)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ports.BlockQueryResult{
+		Blocks:     blocks,
+		TotalCount: totalCount,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: int(totalCount+int64(query.PageSize)-1) / query.PageSize,
+	}, nil
+}
+
+func (r *RelationshipRepository) ListBlockers(ctx context.Context, blockedID string, userIDs []string) ([]string, error) {
+	filter, err := tenantScoped(ctx, bson.M{"blocked_id": blockedID, "blocker_id": bson.M{"$in": userIDs}})
+	if err != nil {
+		return nil, err
+	}
+	values, err := r.blocks.in(ctx).Distinct(ctx, "blocker_id", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	blockers := make([]string, 0, len(values))
+	for _, value := range values {
+		if id, ok := value.(string); ok {
+			blockers = append(blockers, id)
+		}
+	}
+	return blockers, nil
+}
@@ -0,0 +1,411 @@
+// Package repositorytest serves MongoDB commands from a fake deployment speaking the wire protocol,
+// so tests can run the repositories against a real driver client and check the commands they send
+// without a database server.
+package repositorytest
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Op codes of the wire protocol
+const (
+	opReply = 1
+	opQuery = 2004
+	opMsg   = 2013
+)
+
+// Flags of OP_MSG
+const (
+	msgChecksumPresent = 1 << 0
+	msgMoreToCome      = 1 << 1
+)
+
+// ClusterTime is the seconds of the cluster and operation times of the replies; their increment
+// counts the commands served
+const ClusterTime = 1700000000
+
+// Command is a command received by a Server
+type Command struct {
+	Database string
+	Name     string
+	// Body is the command document, with the documents of its sequences, such as those of an
+	// insert, in arrays named after them
+	Body bson.Raw
+}
+
+// Collection returns the collection the command runs on, the value of its name
+func (c Command) Collection() string {
+	value, _ := c.Body.Lookup(c.Name).StringValueOK()
+	return value
+}
+
+// Namespace returns the database and collection of the command, as in the cursors of replies
+func (c Command) Namespace() string {
+	return c.Database + "." + c.Collection()
+}
+
+// Server is a MongoDB deployment answering the commands of the repositories with Reply. It presents
+// itself as a mongos, which supports sessions and is sent the read preferences of the operations.
+type Server struct {
+	// Reply returns the reply of a command other than the handshake, pings and the ends of the
+	// sessions and cursors, or nil for {ok: 1}, with an empty first batch for queries. It may be
+	// called concurrently.
+	Reply func(cmd Command) bson.D
+
+	listener net.Listener
+	mu       sync.Mutex
+	conns    map[net.Conn]bool
+	commands []Command
+	clock    uint32
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a Server on a loopback port, stopped when the test ends
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	s := &Server{listener: listener, conns: map[net.Conn]bool{}}
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(s.close)
+	return s
+}
+
+// Client returns a client of the server, disconnected when the test ends
+func (s *Server) Client(t testing.TB) *mongo.Client {
+	t.Helper()
+	opts := options.Client().
+		ApplyURI("mongodb://" + s.listener.Addr().String() + "/?directConnection=true").
+		SetServerSelectionTimeout(2 * time.Second)
+	client, err := mongo.Connect(t.Context(), opts)
+	if err != nil {
+		t.Fatalf("connecting to %s: %v", s.listener.Addr(), err)
+	}
+	t.Cleanup(func() { _ = client.Disconnect(t.Context()) })
+	return client
+}
+
+// Commands returns the commands received other than the handshake, pings and the ends of the
+// sessions and cursors, in order
+func (s *Server) Commands() []Command {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Command(nil), s.commands...)
+}
+
+// FirstBatch is the reply of a query returning docs, with more to read with getMore unless id is 0
+func FirstBatch(ns string, id int64, docs ...any) bson.D {
+	return cursorReply("firstBatch", ns, id, docs)
+}
+
+// NextBatch is the reply of a getMore returning docs
+func NextBatch(ns string, id int64, docs ...any) bson.D {
+	return cursorReply("nextBatch", ns, id, docs)
+}
+
+func cursorReply(batch, ns string, id int64, docs []any) bson.D {
+	if docs == nil {
+		docs = []any{}
+	}
+	return bson.D{{Key: "cursor", Value: bson.D{{Key: "id", Value: id}, {Key: "ns", Value: ns}, {Key: batch, Value: docs}}}}
+}
+
+func (s *Server) close() {
+	_ = s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.serveConn(conn)
+		}()
+	}
+}
+
+// serveConn answers the messages of conn, OP_QUERY for the legacy handshake and OP_MSG for the rest
+func (s *Server) serveConn(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	for {
+		var header [16]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return
+		}
+		length := int(binary.LittleEndian.Uint32(header[0:]))
+		requestID := binary.LittleEndian.Uint32(header[4:])
+		opCode := binary.LittleEndian.Uint32(header[12:])
+		if length < len(header) {
+			return
+		}
+		body := make([]byte, length-len(header))
+		if _, err := io.ReadFull(r, body); err != nil {
+			return
+		}
+
+		var reply []byte
+		switch opCode {
+		case opQuery:
+			cmd, err := parseQuery(body)
+			if err != nil {
+				return
+			}
+			reply = replyMessage(requestID, opReply, s.handle(cmd))
+		case opMsg:
+			cmd, flags, err := parseMsg(body)
+			if err != nil {
+				return
+			}
+			doc := s.handle(cmd)
+			if flags&msgMoreToCome != 0 {
+				continue
+			}
+			reply = replyMessage(requestID, opMsg, doc)
+		default:
+			return
+		}
+		if _, err := conn.Write(reply); err != nil {
+			return
+		}
+	}
+}
+
+// handle returns the reply of cmd, with the cluster and operation times of the deployment
+func (s *Server) handle(cmd Command) []byte {
+	var reply bson.D
+	switch cmd.Name {
+	case "hello", "isMaster", "ismaster":
+		reply = bson.D{
+			{Key: "isWritablePrimary", Value: true},
+			{Key: "ismaster", Value: true},
+			{Key: "helloOk", Value: true},
+			{Key: "msg", Value: "isdbgrid"},
+			{Key: "maxBsonObjectSize", Value: int32(16 * 1024 * 1024)},
+			{Key: "maxMessageSizeBytes", Value: int32(48000000)},
+			{Key: "maxWriteBatchSize", Value: int32(100000)},
+			{Key: "logicalSessionTimeoutMinutes", Value: int32(30)},
+			{Key: "localTime", Value: primitive.NewDateTimeFromTime(time.Now())},
+			{Key: "minWireVersion", Value: int32(0)},
+			{Key: "maxWireVersion", Value: int32(17)},
+		}
+	case "ping", "endSessions", "killCursors", "buildInfo":
+	default:
+		s.mu.Lock()
+		s.commands = append(s.commands, cmd)
+		s.mu.Unlock()
+		if s.Reply != nil {
+			reply = s.Reply(cmd)
+		}
+		if reply == nil && (cmd.Name == "find" || cmd.Name == "aggregate") {
+			reply = FirstBatch(cmd.Namespace(), 0)
+		}
+	}
+
+	hasOK := false
+	for _, e := range reply {
+		hasOK = hasOK || e.Key == "ok"
+	}
+	if !hasOK {
+		reply = append(reply, bson.E{Key: "ok", Value: 1.0})
+	}
+	s.mu.Lock()
+	s.clock++
+	now := primitive.Timestamp{T: ClusterTime, I: s.clock}
+	s.mu.Unlock()
+	reply = append(reply,
+		bson.E{Key: "operationTime", Value: now},
+		bson.E{Key: "$clusterTime", Value: bson.D{
+			{Key: "clusterTime", Value: now},
+			{Key: "signature", Value: bson.D{
+				{Key: "hash", Value: primitive.Binary{Data: make([]byte, 20)}},
+				{Key: "keyId", Value: int64(0)},
+			}},
+		}},
+	)
+	doc, err := bson.Marshal(reply)
+	if err != nil {
+		panic(err)
+	}
+	return doc
+}
+
+// parseQuery reads the command of an OP_QUERY on the $cmd collection of a database
+func parseQuery(body []byte) (Command, error) {
+	if len(body) < 4 {
+		return Command{}, errors.New("short OP_QUERY")
+	}
+	rest := body[4:]
+	collection, rest, ok := cstring(rest)
+	if !ok || len(rest) < 8 {
+		return Command{}, errors.New("malformed OP_QUERY")
+	}
+	doc, _, err := document(rest[8:])
+	if err != nil {
+		return Command{}, err
+	}
+	database := collection
+	for i := range collection {
+		if collection[i] == '.' {
+			database = collection[:i]
+			break
+		}
+	}
+	return command(database, doc)
+}
+
+// parseMsg reads the command of an OP_MSG and its flags
+func parseMsg(body []byte) (Command, uint32, error) {
+	if len(body) < 4 {
+		return Command{}, 0, errors.New("short OP_MSG")
+	}
+	flags := binary.LittleEndian.Uint32(body)
+	rest := body[4:]
+	if flags&msgChecksumPresent != 0 {
+		if len(rest) < 4 {
+			return Command{}, 0, errors.New("short OP_MSG checksum")
+		}
+		rest = rest[:len(rest)-4]
+	}
+
+	var main bson.Raw
+	var sequences bson.D
+	for len(rest) > 0 {
+		kind := rest[0]
+		rest = rest[1:]
+		switch kind {
+		case 0:
+			doc, next, err := document(rest)
+			if err != nil {
+				return Command{}, 0, err
+			}
+			main, rest = doc, next
+		case 1:
+			if len(rest) < 4 {
+				return Command{}, 0, errors.New("short document sequence")
+			}
+			size := int(binary.LittleEndian.Uint32(rest))
+			if size < 4 || size > len(rest) {
+				return Command{}, 0, errors.New("malformed document sequence")
+			}
+			identifier, docs, ok := cstring(rest[4:size])
+			if !ok {
+				return Command{}, 0, errors.New("malformed document sequence")
+			}
+			var values bson.A
+			for len(docs) > 0 {
+				doc, next, err := document(docs)
+				if err != nil {
+					return Command{}, 0, err
+				}
+				values, docs = append(values, doc), next
+			}
+			sequences = append(sequences, bson.E{Key: identifier, Value: values})
+			rest = rest[size:]
+		default:
+			return Command{}, 0, errors.New("unknown OP_MSG section")
+		}
+	}
+	if main == nil {
+		return Command{}, 0, errors.New("OP_MSG without a body")
+	}
+
+	if len(sequences) > 0 {
+		var merged bson.D
+		if err := bson.Unmarshal(main, &merged); err != nil {
+			return Command{}, 0, err
+		}
+		data, err := bson.Marshal(append(merged, sequences...))
+		if err != nil {
+			return Command{}, 0, err
+		}
+		main = data
+	}
+	database, _ := main.Lookup("$db").StringValueOK()
+	cmd, err := command(database, main)
+	return cmd, flags, err
+}
+
+func command(database string, doc bson.Raw) (Command, error) {
+	elements, err := doc.Elements()
+	if err != nil {
+		return Command{}, err
+	}
+	if len(elements) == 0 {
+		return Command{}, errors.New("empty command")
+	}
+	return Command{Database: database, Name: elements[0].Key(), Body: doc}, nil
+}
+
+func document(data []byte) (bson.Raw, []byte, error) {
+	if len(data) < 5 {
+		return nil, nil, errors.New("short document")
+	}
+	size := int(binary.LittleEndian.Uint32(data))
+	if size < 5 || size > len(data) {
+		return nil, nil, errors.New("malformed document")
+	}
+	doc := bson.Raw(data[:size])
+	if err := doc.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return doc, data[size:], nil
+}
+
+func cstring(data []byte) (string, []byte, bool) {
+	for i, b := range data {
+		if b == 0 {
+			return string(data[:i]), data[i+1:], true
+		}
+	}
+	return "", nil, false
+}
+
+// replyMessage frames doc as the reply to requestID, an OP_REPLY to an OP_QUERY or an OP_MSG
+func replyMessage(requestID uint32, opCode uint32, doc []byte) []byte {
+	var prefix []byte
+	if opCode == opReply {
+		// responseFlags, cursorID, startingFrom, numberReturned
+		prefix = make([]byte, 20)
+		binary.LittleEndian.PutUint32(prefix[16:], 1)
+	} else {
+		// flagBits, then a body section
+		prefix = []byte{0, 0, 0, 0, 0}
+	}
+	message := make([]byte, 16, 16+len(prefix)+len(doc))
+	message = append(append(message, prefix...), doc...)
+	binary.LittleEndian.PutUint32(message[0:], uint32(len(message)))
+	binary.LittleEndian.PutUint32(message[4:], requestID+1<<30)
+	binary.LittleEndian.PutUint32(message[8:], requestID)
+	binary.LittleEndian.PutUint32(message[12:], opCode)
+	return message
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var _ ports.RetentionRepository = (*RetentionRepository)(nil)
+
+// RetentionRepository purges the collections of the other repositories. Unlike them it is not
+// scoped to a tenant: retention windows apply to every tenant, in every database.
+type RetentionRepository struct {
+	users       tenantCollection
+	loginEvents tenantCollection
+}
+
+func NewRetentionRepository(databases *TenantDatabases, usersCollection, loginEventsCollection string) *RetentionRepository {
+	return &RetentionRepository{
+		users:       newTenantCollection(databases, usersCollection),
+		loginEvents: newTenantCollection(databases, loginEventsCollection),
+	}
+}
+
+func (r *RetentionRepository) DeleteUsers(ctx context.Context, deletedBefore time.Time, dryRun bool) (int64, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": deletedBefore}}
+	var deleted int64
+	for _, users := range r.users.all() {
+		if dryRun {
+			count, err := users.CountDocuments(ctx, filter)
+			if err != nil {
+				return deleted, err
+			}
+			deleted += count
+			continue
+		}
+		result, err := users.DeleteMany(ctx, filter)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += result.DeletedCount
+	}
+	return deleted, nil
+}
+
+// AnonymizeUsers replaces the users one at a time: when it fails, the users replaced before stay
+// anonymized and the count returned includes them
+func (r *RetentionRepository) AnonymizeUsers(ctx context.Context, deletedBefore time.Time, dryRun bool) (int64, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": deletedBefore}, "anonymized_at": bson.M{"$exists": false}}
+	var anonymized int64
+	for _, users := range r.users.all() {
+		if dryRun {
+			count, err := users.CountDocuments(ctx, filter)
+			if err != nil {
+				return anonymized, err
+			}
+			anonymized += count
+			continue
+		}
+		count, err := anonymizeUsers(ctx, users, filter)
+		anonymized += count
+		if err != nil {
+			return anonymized, err
+		}
+	}
+	return anonymized, nil
+}
+
+func anonymizeUsers(ctx context.Context, users *mongo.Collection, filter bson.M) (int64, error) {
+	cursor, err := users.Find(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var anonymized int64
+	for cursor.Next(ctx) {
+		var user domain.User
+		if err := cursor.Decode(&user); err != nil {
+			return anonymized, err
+		}
+		user.Anonymize(time.Now())
+		if _, err := users.ReplaceOne(ctx, bson.M{"_id": user.ID, "tenant_id": user.TenantID}, &user); err != nil {
+			return anonymized, err
+		}
+		anonymized++
+	}
+	return anonymized, cursor.Err()
+}
+
+func (r *RetentionRepository) DeleteLoginEvents(ctx context.Context, createdBefore time.Time, dryRun bool) (int64, error) {
+	filter := bson.M{"created_at": bson.M{"$lt": createdBefore}}
+	var deleted int64
+	for _, loginEvents := range r.loginEvents.all() {
+		if dryRun {
+			count, err := loginEvents.CountDocuments(ctx, filter)
+			if err != nil {
+				return deleted, err
+			}
+			deleted += count
+			continue
+		}
+		result, err := loginEvents.DeleteMany(ctx, filter)
+		if err != nil {
+			return deleted, err
+		}
+		deleted += result.DeletedCount
+	}
+	return deleted, nil
+}
+
+func (r *RetentionRepository) ClearLoginReportTokens(ctx context.Context, createdBefore time.Time, dryRun bool) (int64, error) {
+	filter := bson.M{"created_at": bson.M{"$lt": createdBefore}, "report_token_hash": bson.M{"$exists": true}}
+	var cleared int64
+	for _, loginEvents := range r.loginEvents.all() {
+		if dryRun {
+			count, err := loginEvents.CountDocuments(ctx, filter)
+			if err != nil {
+				return cleared, err
+			}
+			cleared += count
+			continue
+		}
+		result, err := loginEvents.UpdateMany(ctx, filter, bson.M{"$unset": bson.M{"report_token_hash": ""}})
+		if err != nil {
+			return cleared, err
+		}
+		cleared += result.ModifiedCount
+	}
+	return cleared, nil
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.RoleRepository = (*RoleRepository)(nil)
+
+type RoleRepository struct {
+	collection tenantCollection
+}
+
+func NewRoleRepository(databases *TenantDatabases, collectionName string) *RoleRepository {
+	return &RoleRepository{
+		collection: newTenantCollection(databases, collectionName),
+	}
+}
+
+func (r *RoleRepository) CreateRole(ctx context.Context, role *domain.Role) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	role.TenantID = tenantID
+
+	_, err := r.collection.in(ctx).InsertOne(ctx, role)
+	return err
+}
+
+func (r *RoleRepository) GetRoleByName(ctx context.Context, name string) (*domain.Role, error) {
+	filter, err := tenantScoped(ctx, bson.M{"name": name})
+	if err != nil {
+		return nil, err
+	}
+
+	var role domain.Role
+	if err := r.collection.in(ctx).FindOne(ctx, filter).Decode(&role); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &role, nil
+}
+
+func (r *RoleRepository) GetRolesByNames(ctx context.Context, names []string) ([]*domain.Role, error) {
+	return r.find(ctx, bson.M{"name": bson.M{"$in": names}})
+}
+
+func (r *RoleRepository) ListRoles(ctx context.Context) ([]*domain.Role, error) {
+	return r.find(ctx, bson.M{})
+}
+
+func (r *RoleRepository) find(ctx context.Context, filter bson.M) ([]*domain.Role, error) {
+	filter, err := tenantScoped(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.collection.in(ctx).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	roles := make([]*domain.Role, 0)
+	if err := cursor.All(ctx, &roles); err != nil {
+		return nil, err
+	}
+	return roles, nil
+}
+
+func (r *RoleRepository) UpdateRole(ctx context.Context, role *domain.Role) error {
+	filter, err := tenantScoped(ctx, bson.M{"name": role.Name})
+	if err != nil {
+		return err
+	}
+	role.UpdatedAt = time.Now()
+	_, err = r.collection.in(ctx).UpdateOne(ctx, filter, bson.M{"$set": bson.M{
+		"description": role.Description,
+		"permissions": role.Permissions,
+		"updated_at":  role.UpdatedAt,
+	}})
+	return err
+}
+
+func (r *RoleRepository) DeleteRole(ctx context.Context, name string) error {
+	filter, err := tenantScoped(ctx, bson.M{"name": name})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.in(ctx).DeleteOne(ctx, filter)
+	return err
+}
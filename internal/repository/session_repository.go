@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var _ ports.SessionRepository = (*SessionRepository)(nil)
+
+type SessionRepository struct {
+	collection tenantCollection
+}
+
+func NewSessionRepository(databases *TenantDatabases, collectionName string) *SessionRepository {
+	return &SessionRepository{
+		collection: newTenantCollection(databases, collectionName),
+	}
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session *domain.Session) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	session.TenantID = tenantID
+
+	_, err := r.collection.in(ctx).InsertOne(ctx, session)
+	return err
+}
+
+func (r *SessionRepository) GetSession(ctx context.Context, id string) (*domain.Session, error) {
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	var session domain.Session
+	if err := r.collection.in(ctx).FindOne(ctx, filter).Decode(&session); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *SessionRepository) SetSessionAuthTime(ctx context.Context, id string, at time.Time) error {
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.in(ctx).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"auth_time": at}})
+	return err
+}
+
+func (r *SessionRepository) SetSessionCSRFTokenHash(ctx context.Context, id, csrfTokenHash string) error {
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.in(ctx).UpdateOne(ctx, filter, bson.M{"$set": bson.M{"csrf_token_hash": csrfTokenHash}})
+	return err
+}
+
+func (r *SessionRepository) DeleteSession(ctx context.Context, id string) error {
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.in(ctx).DeleteOne(ctx, filter)
+	return err
+}
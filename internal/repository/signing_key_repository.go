@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.SigningKeyRepository = (*SigningKeyRepository)(nil)
+
+// SigningKeyRepository stores the token signing keys, which are shared by every tenant
+type SigningKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSigningKeyRepository(db *mongo.Database, collectionName string) *SigningKeyRepository {
+	return &SigningKeyRepository{
+		collection: db.Collection(collectionName),
+	}
+}
+
+func (r *SigningKeyRepository) ListSigningKeys(ctx context.Context) ([]*domain.SigningKey, error) {
+	// The TTL index removes expired keys about once a minute, they are filtered out until then
+	filter := bson.M{"$or": bson.A{
+		bson.M{"expires_at": bson.M{"$exists": false}},
+		bson.M{"expires_at": bson.M{"$gt": time.Now()}},
+	}}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "activates_at", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []*domain.SigningKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (r *SigningKeyRepository) CreateSigningKey(ctx context.Context, key *domain.SigningKey) error {
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
+
+func (r *SigningKeyRepository) ExpireSigningKeys(ctx context.Context, keepID string, expiresAt time.Time) error {
+	filter := bson.M{
+		"_id": bson.M{"$ne": keepID},
+		"$or": bson.A{
+			bson.M{"expires_at": bson.M{"$exists": false}},
+			bson.M{"expires_at": bson.M{"$gt": expiresAt}},
+		},
+	}
+	_, err := r.collection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"expires_at": expiresAt}})
+	return err
+}
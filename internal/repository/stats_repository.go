@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.StatsRepository = (*StatsRepository)(nil)
+
+// StatsRepository rolls up the users and login events of every tenant into the daily statistics
+// it stores in the database of the tenant; the statistics and the registrations are counted per tenant
+type StatsRepository struct {
+	stats       tenantCollection
+	users       tenantCollection
+	loginEvents tenantCollection
+}
+
+func NewStatsRepository(databases *TenantDatabases, statsCollection, usersCollection, loginEventsCollection string) *StatsRepository {
+	return &StatsRepository{
+		stats:       newTenantCollection(databases, statsCollection),
+		users:       newTenantCollection(databases, usersCollection),
+		loginEvents: newTenantCollection(databases, loginEventsCollection),
+	}
+}
+
+func (r *StatsRepository) RollUpUserStats(ctx context.Context, day time.Time) (int, error) {
+	start := day.UTC().Truncate(24 * time.Hour)
+	loginEvents, stats := r.loginEvents.listAll(), r.stats.all()
+	tenants := 0
+	for i, users := range r.users.listAll() {
+		n, err := rollUpUserStats(ctx, users, loginEvents[i], stats[i], start)
+		tenants += n
+		if err != nil {
+			return tenants, err
+		}
+	}
+	return tenants, nil
+}
+
+// rollUpUserStats stores the statistics of the day starting at start of the tenants of a database
+func rollUpUserStats(ctx context.Context, users, loginEvents, stats *mongo.Collection, start time.Time) (int, error) {
+	end := start.AddDate(0, 0, 1)
+	dayText := start.Format(domain.StatsDayLayout)
+
+	// Users without deleted_at are deleted at end, that is not during the day
+	deletedAt := bson.M{"$ifNull": bson.A{"$deleted_at", end}}
+	deletedBefore := bson.M{"$lt": bson.A{deletedAt, end}}
+	cursor, err := users.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$lt": end}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$tenant_id",
+			"total": bson.M{"$sum": bson.M{"$cond": bson.A{deletedBefore, 0, 1}}},
+			"new":   bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$gte": bson.A{"$created_at", start}}, 1, 0}}},
+			"deleted": bson.M{"$sum": bson.M{"$cond": bson.A{
+				bson.M{"$and": bson.A{deletedBefore, bson.M{"$gte": bson.A{deletedAt, start}}}}, 1, 0,
+			}}},
+		}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var counts []struct {
+		TenantID string `bson:"_id"`
+		Total    int64  `bson:"total"`
+		New      int64  `bson:"new"`
+		Deleted  int64  `bson:"deleted"`
+	}
+	if err := cursor.All(ctx, &counts); err != nil {
+		return 0, err
+	}
+
+	cursor, err = loginEvents.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"created_at": bson.M{"$gte": start, "$lt": end}}}},
+		{{Key: "$group", Value: bson.M{"_id": bson.M{"tenant_id": "$tenant_id", "user_id": "$user_id"}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$_id.tenant_id", "active": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	var logins []struct {
+		TenantID string `bson:"_id"`
+		Active   int64  `bson:"active"`
+	}
+	if err := cursor.All(ctx, &logins); err != nil {
+		return 0, err
+	}
+	active := make(map[string]int64, len(logins))
+	for _, login := range logins {
+		active[login.TenantID] = login.Active
+	}
+
+	now := time.Now()
+	for _, count := range counts {
+		dayStats := &domain.UserStats{
+			ID:         domain.UserStatsID(count.TenantID, dayText),
+			TenantID:   count.TenantID,
+			Day:        dayText,
+			Total:      count.Total,
+			New:        count.New,
+			Active:     active[count.TenantID],
+			Deleted:    count.Deleted,
+			ComputedAt: now,
+		}
+		if _, err := stats.ReplaceOne(ctx, bson.M{"_id": dayStats.ID}, dayStats, options.Replace().SetUpsert(true)); err != nil {
+			return 0, err
+		}
+	}
+	return len(counts), nil
+}
+
+func (r *StatsRepository) ListUserStats(ctx context.Context, since time.Time) ([]*domain.UserStats, error) {
+	filter, err := tenantScoped(ctx, bson.M{"day": bson.M{"$gte": since.UTC().Format(domain.StatsDayLayout)}})
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := r.stats.listIn(ctx).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "day", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	stats := []*domain.UserStats{}
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *StatsRepository) CountRegistrations(ctx context.Context, query *ports.RegistrationsQuery) ([]ports.RegistrationBucket, error) {
+	filter, err := tenantScoped(ctx, bson.M{"created_at": bson.M{"$gte": query.From, "$lt": query.To}})
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := r.users.listIn(ctx).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   bson.M{"$dateTrunc": bson.M{"date": "$created_at", "unit": query.Interval, "startOfWeek": "monday"}},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	buckets := []ports.RegistrationBucket{}
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return nil, err
+	}
+	return buckets, nil
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"slices"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// tenantScoped restricts filter to the tenant carried by ctx.
+// Queries without a tenant are refused so data can never leak across tenants.
+func tenantScoped(ctx context.Context, filter bson.M) (bson.M, error) {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return nil, domain.ErrMissingTenant
+	}
+	filter["tenant_id"] = tenantID
+	return filter, nil
+}
+
+// TenantDatabases is the registry of the databases of the tenants: the tenants it lists have their
+// own database, on the deployment of the shared database or on another one, the others share the
+// shared database
+type TenantDatabases struct {
+	shared  *mongo.Database
+	tenants map[string]*mongo.Database
+	// listReadPref is the read preference of the list queries and the reports, nil for the read
+	// preference of the client
+	listReadPref *readpref.ReadPref
+	// deadlines are the default deadlines of the operations on the database of a tenant
+	deadlines ports.DeadlinePolicy
+}
+
+// NewTenantDatabases routes the tenants of tenants to their database and the others to shared
+func NewTenantDatabases(shared *mongo.Database, tenants map[string]*mongo.Database) *TenantDatabases {
+	return &TenantDatabases{
+		shared:  shared,
+		tenants: tenants,
+	}
+}
+
+// SetListReadPreference makes the list queries and the reports, such as the user lists and the
+// statistics, read with rp, for instance from the secondaries so they do not compete with the writes
+// of the primary. It must be called before the repositories are used.
+func (d *TenantDatabases) SetListReadPreference(rp *readpref.ReadPref) {
+	d.listReadPref = rp
+}
+
+// SetDeadlinePolicy gives the operations on the database of the tenant of their context the deadlines
+// of policy when their context has none. It must be called before the repositories are used.
+func (d *TenantDatabases) SetDeadlinePolicy(policy ports.DeadlinePolicy) {
+	d.deadlines = policy
+}
+
+// Shared returns the database of the tenants without their own and of the data of the instance,
+// such as the jobs and the signing keys
+func (d *TenantDatabases) Shared() *mongo.Database {
+	return d.shared
+}
+
+// Database returns the database of the tenant carried by ctx, the shared one for operations
+// without a tenant
+func (d *TenantDatabases) Database(ctx context.Context) *mongo.Database {
+	if db, ok := d.tenants[domain.TenantFromContext(ctx)]; ok {
+		return db
+	}
+	return d.shared
+}
+
+// All returns the shared database then each database of the tenants once, sorted by tenant, for the
+// operations spanning every tenant
+func (d *TenantDatabases) All() []*mongo.Database {
+	type key struct {
+		client *mongo.Client
+		name   string
+	}
+	all := []*mongo.Database{d.shared}
+	seen := map[key]bool{{d.shared.Client(), d.shared.Name()}: true}
+	for _, tenantID := range slices.Sorted(maps.Keys(d.tenants)) {
+		db := d.tenants[tenantID]
+		if !seen[key{db.Client(), db.Name()}] {
+			seen[key{db.Client(), db.Name()}] = true
+			all = append(all, db)
+		}
+	}
+	return all
+}
+
+// tenantCollection is a collection in the database of the tenant of each operation
+type tenantCollection struct {
+	databases *TenantDatabases
+	name      string
+	opts      []*options.CollectionOptions
+}
+
+func newTenantCollection(databases *TenantDatabases, name string, opts ...*options.CollectionOptions) tenantCollection {
+	return tenantCollection{
+		databases: databases,
+		name:      name,
+		opts:      opts,
+	}
+}
+
+// in returns the collection in the database of the tenant carried by ctx
+func (c tenantCollection) in(ctx context.Context) deadlineCollection {
+	return deadlineCollection{c.databases.Database(ctx).Collection(c.name, c.opts...), c.databases.deadlines}
+}
+
+// all returns the collection in every database
+func (c tenantCollection) all() []*mongo.Collection {
+	return c.collections(c.opts)
+}
+
+// listIn returns the collection in the database of the tenant carried by ctx, read with the read
+// preference of the list queries
+func (c tenantCollection) listIn(ctx context.Context) deadlineCollection {
+	return deadlineCollection{c.databases.Database(ctx).Collection(c.name, c.listOptions()...), c.databases.deadlines}
+}
+
+// listAll returns the collection in every database, read with the read preference of the list queries
+func (c tenantCollection) listAll() []*mongo.Collection {
+	return c.collections(c.listOptions())
+}
+
+func (c tenantCollection) collections(opts []*options.CollectionOptions) []*mongo.Collection {
+	databases := c.databases.All()
+	collections := make([]*mongo.Collection, len(databases))
+	for i, db := range databases {
+		collections[i] = db.Collection(c.name, opts...)
+	}
+	return collections
+}
+
+func (c tenantCollection) listOptions() []*options.CollectionOptions {
+	if c.databases.listReadPref == nil {
+		return c.opts
+	}
+	return append(slices.Clip(c.opts), options.Collection().SetReadPreference(c.databases.listReadPref))
+}
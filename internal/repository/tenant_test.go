@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/repository/repositorytest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+func TestTenantScoped(t *testing.T) {
+	filter, err := tenantScoped(domain.WithTenant(context.Background(), "acme"), bson.M{"email": "ada@acme.example"})
+	if err != nil {
+		t.Fatalf("tenantScoped() error = %v", err)
+	}
+	if want := (bson.M{"email": "ada@acme.example", "tenant_id": "acme"}); !reflect.DeepEqual(filter, want) {
+		t.Errorf("tenantScoped() = %v, want %v", filter, want)
+	}
+	if _, err := tenantScoped(context.Background(), bson.M{}); err != domain.ErrMissingTenant {
+		t.Errorf("tenantScoped() without a tenant error = %v, want %v", err, domain.ErrMissingTenant)
+	}
+}
+
+func TestTenantDatabasesDatabase(t *testing.T) {
+	shared := repositorytest.NewServer(t).Client(t).Database("app")
+	acme := repositorytest.NewServer(t).Client(t).Database("acme")
+	databases := NewTenantDatabases(shared, map[string]*mongo.Database{"acme": acme})
+
+	tests := []struct {
+		name   string
+		tenant string
+		want   *mongo.Database
+	}{
+		{name: "tenant with its own database", tenant: "acme", want: acme},
+		{name: "tenant sharing the shared database", tenant: "globex", want: shared},
+		{name: "default tenant", tenant: domain.DefaultTenantID, want: shared},
+		{name: "no tenant", want: shared},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.tenant != "" {
+				ctx = domain.WithTenant(ctx, tt.tenant)
+			}
+			if got := databases.Database(ctx); got != tt.want {
+				t.Errorf("Database() = %s, want %s", got.Name(), tt.want.Name())
+			}
+		})
+	}
+	if got := databases.Shared(); got != shared {
+		t.Errorf("Shared() = %s, want %s", got.Name(), shared.Name())
+	}
+}
+
+func TestTenantDatabasesAll(t *testing.T) {
+	first := repositorytest.NewServer(t).Client(t)
+	second := repositorytest.NewServer(t).Client(t)
+	shared := first.Database("app")
+	databases := NewTenantDatabases(shared, map[string]*mongo.Database{
+		"zeta":    first.Database("acme"),
+		"initech": second.Database("app"),
+		"globex":  first.Database("app"),
+		"acme":    first.Database("acme"),
+	})
+
+	type database struct {
+		client *mongo.Client
+		name   string
+	}
+	var got []database
+	for _, db := range databases.All() {
+		got = append(got, database{db.Client(), db.Name()})
+	}
+	// globex shares the shared database, zeta the database of acme, initech has the name of the
+	// shared database on another deployment
+	want := []database{{first, "app"}, {first, "acme"}, {second, "app"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("All() = %v, want %v", got, want)
+	}
+}
+
+func TestTenantCollectionRoutesOperations(t *testing.T) {
+	sharedServer, acmeServer := repositorytest.NewServer(t), repositorytest.NewServer(t)
+	databases := NewTenantDatabases(sharedServer.Client(t).Database("app"), map[string]*mongo.Database{
+		"acme": acmeServer.Client(t).Database("acme"),
+	})
+	users := NewUserRepository(databases, "users")
+
+	for _, tenantID := range []string{"acme", "globex"} {
+		if _, err := users.GetUserByID(domain.WithTenant(context.Background(), tenantID), "u1"); err != nil {
+			t.Fatalf("GetUserByID() for %s error = %v", tenantID, err)
+		}
+	}
+	if _, err := NewRetentionRepository(databases, "users", "login_events").DeleteUsers(context.Background(), time.Now(), false); err != nil {
+		t.Fatalf("DeleteUsers() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		server *repositorytest.Server
+		want   []string
+	}{
+		{name: "shared database", server: sharedServer, want: []string{"find app.users globex", "delete app.users"}},
+		{name: "database of acme", server: acmeServer, want: []string{"find acme.users acme", "delete acme.users"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, cmd := range tt.server.Commands() {
+				operation := cmd.Name + " " + cmd.Namespace()
+				if tenantID, ok := cmd.Body.Lookup("filter", "tenant_id").StringValueOK(); ok {
+					operation += " " + tenantID
+				}
+				got = append(got, operation)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commands = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListReadPreference(t *testing.T) {
+	type readPreference struct {
+		Mode                string `bson:"mode"`
+		MaxStalenessSeconds int64  `bson:"maxStalenessSeconds"`
+	}
+	tests := []struct {
+		name string
+		rp   *readpref.ReadPref
+		// want are the commands sent and their read preference, empty for the primary
+		want []string
+	}{
+		{
+			name: "primary",
+			want: []string{"aggregate users", "find users", "aggregate users", "find users", "explain users", "find users", "aggregate users", "aggregate audit_logs"},
+		},
+		{
+			name: "secondary",
+			rp:   readpref.Secondary(),
+			want: []string{"aggregate users secondary", "find users secondary", "aggregate users secondary", "find users secondary", "explain users secondary", "find users",
+				"aggregate users secondary", "aggregate audit_logs secondary"},
+		},
+		{
+			name: "secondary preferred with a maximum staleness",
+			rp:   readpref.SecondaryPreferred(readpref.WithMaxStaleness(90 * time.Second)),
+			want: []string{"aggregate users secondaryPreferred 90", "find users secondaryPreferred 90", "aggregate users secondaryPreferred 90", "find users secondaryPreferred 90",
+				"explain users secondaryPreferred 90", "find users", "aggregate users secondaryPreferred 90", "aggregate audit_logs secondaryPreferred 90"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := repositorytest.NewServer(t)
+			databases := NewTenantDatabases(server.Client(t).Database("app"), nil)
+			databases.SetListReadPreference(tt.rp)
+			ctx := domain.WithTenant(context.Background(), "acme")
+
+			// Lists, with their count and plan, and reports are read with the read preference, single
+			// users from the primary
+			users := NewUserRepository(databases, "users")
+			if _, err := users.GetUsers(ctx, &ports.GetUsersOptions{Page: 1, PageSize: 10}); err != nil {
+				t.Fatalf("GetUsers() error = %v", err)
+			}
+			if _, err := users.GetUsers(ctx, &ports.GetUsersOptions{Page: 1, PageSize: 10, Explain: true}); err != nil {
+				t.Fatalf("GetUsers() with explain error = %v", err)
+			}
+			if _, err := users.GetUserByID(ctx, "u1"); err != nil {
+				t.Fatalf("GetUserByID() error = %v", err)
+			}
+			if _, err := NewDigestRepository(databases, "users", "audit_logs").CountAdminDigests(ctx, time.Now().Add(-time.Hour), time.Now()); err != nil {
+				t.Fatalf("CountAdminDigests() error = %v", err)
+			}
+
+			var got []string
+			for _, cmd := range server.Commands() {
+				collection := cmd.Collection()
+				if cmd.Name == "explain" {
+					collection, _ = cmd.Body.Lookup("explain", "find").StringValueOK()
+				}
+				command := cmd.Name + " " + collection
+				if value, err := cmd.Body.LookupErr("$readPreference"); err == nil {
+					var rp readPreference
+					if err := value.Unmarshal(&rp); err != nil {
+						t.Fatalf("decoding $readPreference %s: %v", value, err)
+					}
+					command += " " + rp.Mode
+					if rp.MaxStalenessSeconds > 0 {
+						command += fmt.Sprintf(" %d", rp.MaxStalenessSeconds)
+					}
+				}
+				got = append(got, command)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("commands = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
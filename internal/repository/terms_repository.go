@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.TermsRepository = (*TermsRepository)(nil)
+
+type TermsRepository struct {
+	versions    tenantCollection
+	acceptances tenantCollection
+}
+
+func NewTermsRepository(databases *TenantDatabases, versionsCollection, acceptancesCollection string) *TermsRepository {
+	return &TermsRepository{
+		versions:    newTenantCollection(databases, versionsCollection),
+		acceptances: newTenantCollection(databases, acceptancesCollection),
+	}
+}
+
+func (r *TermsRepository) CreateVersion(ctx context.Context, version *domain.TermsVersion) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	version.TenantID = tenantID
+
+	if _, err := r.versions.in(ctx).InsertOne(ctx, version); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return domain.ErrTermsVersionExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *TermsRepository) CurrentVersions(ctx context.Context) ([]*domain.TermsVersion, error) {
+	filter, err := tenantScoped(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: bson.D{{Key: "document", Value: 1}, {Key: "published_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$document", "version": bson.M{"$first": "$$ROOT"}}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$version"}}},
+		{{Key: "$sort", Value: bson.D{{Key: "document", Value: 1}}}},
+	}
+	cursor, err := r.versions.in(ctx).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := make([]*domain.TermsVersion, 0)
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *TermsRepository) ListVersions(ctx context.Context, document string) ([]*domain.TermsVersion, error) {
+	filter := bson.M{}
+	if document != "" {
+		filter["document"] = document
+	}
+	filter, err := tenantScoped(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.versions.in(ctx).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "published_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := make([]*domain.TermsVersion, 0)
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+func (r *TermsRepository) CreateAcceptance(ctx context.Context, acceptance *domain.TermsAcceptance) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	acceptance.TenantID = tenantID
+
+	_, err := r.acceptances.in(ctx).InsertOne(ctx, acceptance)
+	return err
+}
+
+func (r *TermsRepository) ListAcceptances(ctx context.Context, userID string) ([]*domain.TermsAcceptance, error) {
+	filter, err := tenantScoped(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.acceptances.in(ctx).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "accepted_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	acceptances := make([]*domain.TermsAcceptance, 0)
+	if err := cursor.All(ctx, &acceptances); err != nil {
+		return nil, err
+	}
+	return acceptances, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.UserChangeStream = (*UserChangeStream)(nil)
+
+// UserChangeStream reads the changes to the users collection with a MongoDB change stream, which
+// requires a replica set. The resume token of the last handled change is stored under name, so a
+// restarted watch picks up where the previous one stopped, as long as the oplog still holds it.
+type UserChangeStream struct {
+	users  *mongo.Collection
+	tokens *mongo.Collection
+	name   string
+}
+
+func NewUserChangeStream(db *mongo.Database, usersCollection, tokensCollection, name string) *UserChangeStream {
+	return &UserChangeStream{
+		users:  db.Collection(usersCollection),
+		tokens: db.Collection(tokensCollection),
+		name:   name,
+	}
+}
+
+// userChangeEvent holds the fields of a change event the stream reads
+type userChangeEvent struct {
+	DocumentKey struct {
+		ID string `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *domain.User `bson:"fullDocument"`
+}
+
+func (s *UserChangeStream) Watch(ctx context.Context, handle func(ctx context.Context, change ports.UserChange) error) error {
+	var saved struct {
+		Token bson.Raw `bson:"token"`
+	}
+	err := s.tokens.FindOne(ctx, bson.M{"_id": s.name}).Decode(&saved)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return err
+	}
+
+	// Updates look the whole document up, so every change carries the user as it is now
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if saved.Token != nil {
+		opts.SetResumeAfter(saved.Token)
+	}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType": bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}},
+	}}}}
+	stream, err := s.users.Watch(ctx, pipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(ctx)
+
+	// A first watch handles every user once the stream is open; the changes written meanwhile are
+	// handled again afterwards, which is harmless as they carry the whole document
+	if saved.Token == nil {
+		if err := s.scan(ctx, handle); err != nil {
+			return err
+		}
+		if err := s.saveToken(ctx, stream.ResumeToken()); err != nil {
+			return err
+		}
+	}
+
+	for stream.Next(ctx) {
+		var event userChangeEvent
+		if err := stream.Decode(&event); err != nil {
+			return err
+		}
+		if err := handle(ctx, userChange(event.DocumentKey.ID, event.FullDocument)); err != nil {
+			return err
+		}
+		if err := s.saveToken(ctx, stream.ResumeToken()); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
+
+// Synced reports whether a resume token is stored, which is first stored once every user was handled
+func (s *UserChangeStream) Synced(ctx context.Context) (bool, error) {
+	count, err := s.tokens.CountDocuments(ctx, bson.M{"_id": s.name}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// scan handles every active user of every tenant
+func (s *UserChangeStream) scan(ctx context.Context, handle func(ctx context.Context, change ports.UserChange) error) error {
+	cursor, err := s.users.Find(ctx, bson.M{"deleted_at": bson.M{"$exists": false}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user domain.User
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+		if err := handle(ctx, userChange(user.ID, &user)); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+func (s *UserChangeStream) saveToken(ctx context.Context, token bson.Raw) error {
+	if token == nil {
+		return nil
+	}
+	_, err := s.tokens.UpdateOne(ctx,
+		bson.M{"_id": s.name},
+		bson.M{"$set": bson.M{"token": token, "updated_at": time.Now()}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// userChange returns the change of a user, without the document of soft-deleted users nor of users
+// deleted before an update was looked up
+func userChange(id string, user *domain.User) ports.UserChange {
+	if user == nil || user.DeletedAt != nil {
+		return ports.UserChange{UserID: id}
+	}
+	user.Profile.MigrateLegacyAddress(user.ID)
+	return ports.UserChange{UserID: id, User: user}
+}
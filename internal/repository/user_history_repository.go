@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.UserHistoryRepository = (*UserHistoryRepository)(nil)
+
+// appendVersionAttempts bounds the retries of AppendVersion when concurrent writes of a user take
+// the same version number
+const appendVersionAttempts = 5
+
+type UserHistoryRepository struct {
+	collection tenantCollection
+}
+
+func NewUserHistoryRepository(databases *TenantDatabases, collectionName string) *UserHistoryRepository {
+	return &UserHistoryRepository{
+		// Changed values holding objects, such as addresses, decode into maps rather than into
+		// ordered documents, so they are shown as JSON objects
+		collection: newTenantCollection(databases, collectionName, options.Collection().SetBSONOptions(&options.BSONOptions{DefaultDocumentM: true})),
+	}
+}
+
+// AppendVersion numbers version after the latest version of its user; the unique index on the
+// version numbers makes a concurrent write of the same number fail, and the version is numbered again
+func (r *UserHistoryRepository) AppendVersion(ctx context.Context, version *domain.UserVersion) error {
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	version.TenantID = tenantID
+
+	var err error
+	for range appendVersionAttempts {
+		var latest domain.UserVersion
+		findOpts := options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}).SetProjection(bson.M{"version": 1})
+		err = r.collection.in(ctx).FindOne(ctx, bson.M{"tenant_id": tenantID, "user_id": version.UserID}, findOpts).Decode(&latest)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return err
+		}
+		version.Version = latest.Version + 1
+
+		if _, err = r.collection.in(ctx).InsertOne(ctx, version); !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// ListVersions paginates the versions of the query user, newest first
+func (r *UserHistoryRepository) ListVersions(ctx context.Context, query *ports.UserHistoryQuery) (*ports.UserHistoryResult, error) {
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 || query.PageSize > 100 {
+		query.PageSize = 20
+	}
+	filter, err := tenantScoped(ctx, bson.M{"user_id": query.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	totalCount, err := r.collection.in(ctx).CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: "version", Value: -1}}).
+		SetSkip(int64((query.Page - 1) * query.PageSize)).
+		SetLimit(int64(query.PageSize))
+	cursor, err := r.collection.in(ctx).Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	versions := make([]*domain.UserVersion, 0, query.PageSize)
+	if err := cursor.All(ctx, &versions); err != nil {
+		return nil, err
+	}
+
+	return &ports.UserHistoryResult{
+		Versions:   versions,
+		TotalCount: totalCount,
+		Page:       query.Page,
+		PageSize:   query.PageSize,
+		TotalPages: int(totalCount+int64(query.PageSize)-1) / query.PageSize,
+	}, nil
+}
+
+func (r *UserHistoryRepository) GetVersion(ctx context.Context, userID string, version int) (*domain.UserVersion, error) {
+	filter, err := tenantScoped(ctx, bson.M{"user_id": userID, "version": version})
+	if err != nil {
+		return nil, err
+	}
+
+	var found domain.UserVersion
+	if err := r.collection.in(ctx).FindOne(ctx, filter).Decode(&found); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &found, nil
+}
@@ -0,0 +1,214 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var _ ports.UserReadModel = (*UserReadModelRepository)(nil)
+
+// userListEntry is the read model of a user: the fields the lists filter, sort and count by,
+// flattened out of the profile, addresses and roles of the user
+type userListEntry struct {
+	ID        string            `bson:"_id"`
+	TenantID  string            `bson:"tenant_id"`
+	Email     string            `bson:"email"`
+	Username  string            `bson:"username,omitempty"`
+	FirstName string            `bson:"first_name"`
+	LastName  string            `bson:"last_name"`
+	Country   string            `bson:"country"`
+	Status    string            `bson:"status"`
+	Roles     []string          `bson:"roles"`
+	Tags      []string          `bson:"tags,omitempty"`
+	Metadata  map[string]string `bson:"metadata,omitempty"`
+	// DirectoryLinked is set on the users synced from a directory
+	DirectoryLinked bool       `bson:"directory_linked"`
+	LastSeenAt      *time.Time `bson:"last_seen_at,omitempty"`
+	CreatedAt       time.Time  `bson:"created_at"`
+	UpdatedAt       time.Time  `bson:"updated_at"`
+}
+
+// UserReadModelRepository keeps the read model of the user lists in its own collection, one flat
+// document per active user, indexed for the sorts of the lists
+type UserReadModelRepository struct {
+	collection *mongo.Collection
+}
+
+func NewUserReadModelRepository(db *mongo.Database, collectionName string) *UserReadModelRepository {
+	return &UserReadModelRepository{
+		collection: db.Collection(collectionName),
+	}
+}
+
+func (r *UserReadModelRepository) SaveUser(ctx context.Context, user *domain.User) error {
+	entry := userListEntry{
+		ID:              user.ID,
+		TenantID:        user.TenantID,
+		Email:           user.Email,
+		Username:        user.Username,
+		FirstName:       user.Profile.FirstName,
+		LastName:        user.Profile.LastName,
+		Status:          user.Status(),
+		Roles:           user.EffectiveRoles(),
+		Tags:            user.Tags,
+		Metadata:        user.Metadata,
+		DirectoryLinked: user.DirectoryID != "",
+		LastSeenAt:      user.LastSeenAt,
+		CreatedAt:       user.CreatedAt,
+		UpdatedAt:       user.UpdatedAt,
+	}
+	if address := user.Profile.PrimaryAddress(); address != nil {
+		entry.Country = address.Country
+	}
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": user.ID}, entry, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *UserReadModelRepository) RemoveUser(ctx context.Context, id string) error {
+	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// readModelSortFields maps the sort fields of the lists to those of the read model
+var readModelSortFields = map[string]string{
+	"email":      "email",
+	"first_name": "first_name",
+	"last_name":  "last_name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// readModelFacetFields maps the facets to the fields of the read model
+var readModelFacetFields = map[string]string{
+	domain.UserFacetCountry: "country",
+	domain.UserFacetStatus:  "status",
+	domain.UserFacetRole:    "roles",
+	domain.UserFacetTag:     "tags",
+}
+
+// ListUsers filters the read model as userFilter does the users, with the same defaults as GetUsers
+func (r *UserReadModelRepository) ListUsers(ctx context.Context, opts *ports.GetUsersOptions) (*ports.UserSearchResult, error) {
+	filter := bson.M{}
+	if opts.Search != "" {
+		// The search is matched as plain text, as the prefix of AutocompleteUsers is
+		search := regexp.QuoteMeta(opts.Search)
+		filter["$or"] = []bson.M{
+			{"email": bson.M{"$regex": search, "$options": "i"}},
+			{"username": bson.M{"$regex": search, "$options": "i"}},
+			{"first_name": bson.M{"$regex": search, "$options": "i"}},
+			{"last_name": bson.M{"$regex": search, "$options": "i"}},
+		}
+	}
+	filter, err := tenantScoped(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range opts.Metadata {
+		filter["metadata."+key] = value
+	}
+	if opts.DirectoryLinked {
+		filter["directory_linked"] = true
+	}
+	if len(opts.IDs) > 0 {
+		filter["_id"] = bson.M{"$in": opts.IDs}
+	}
+	if len(opts.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": opts.Tags}
+	}
+	if opts.InactiveSince != nil {
+		filter["$and"] = []bson.M{{"$or": []bson.M{
+			{"last_seen_at": bson.M{"$lt": *opts.InactiveSince}},
+			{"last_seen_at": bson.M{"$exists": false}, "created_at": bson.M{"$lt": *opts.InactiveSince}},
+		}}}
+	}
+
+	sortField, ok := readModelSortFields[opts.SortBy]
+	if !ok {
+		sortField = "created_at"
+	}
+	sortOrder := 1
+	if opts.Order == "desc" {
+		sortOrder = -1
+	}
+	findOpts := options.Find().
+		SetSort(bson.D{{Key: sortField, Value: sortOrder}, {Key: "_id", Value: 1}}).
+		SetSkip(int64((opts.Page - 1) * opts.PageSize)).
+		SetLimit(int64(opts.PageSize)).
+		SetProjection(bson.M{"_id": 1})
+
+	totalCount, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := r.collection.Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+	result := &ports.UserSearchResult{IDs: make([]string, 0, len(entries)), TotalCount: totalCount}
+	for _, entry := range entries {
+		result.IDs = append(result.IDs, entry.ID)
+	}
+	if len(opts.Facets) > 0 {
+		if result.Facets, err = r.countFacets(ctx, filter, opts.Facets); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// countFacets counts the entries matching filter by the values of each facet in a single $facet
+// stage, reading the flat fields countFacets of UserRepository computes
+func (r *UserReadModelRepository) countFacets(ctx context.Context, filter bson.M, facets []string) (map[string][]ports.FacetCount, error) {
+	stages := bson.M{}
+	for _, facet := range facets {
+		field := "$" + readModelFacetFields[facet]
+		var pipeline bson.A
+		// Users have several roles and tags, counted once for each
+		if facet == domain.UserFacetRole || facet == domain.UserFacetTag {
+			pipeline = append(pipeline, bson.M{"$unwind": field})
+		}
+		stages[facet] = append(pipeline,
+			bson.M{"$group": bson.M{"_id": field, "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": domain.MaxFacetValues},
+			bson.M{"$project": bson.M{"_id": 0, "value": "$_id", "count": 1}},
+		)
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$facet", Value: stages}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []map[string][]ports.FacetCount
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	counts := make(map[string][]ports.FacetCount, len(facets))
+	for _, facet := range facets {
+		counts[facet] = []ports.FacetCount{}
+		if len(results) > 0 && results[0][facet] != nil {
+			counts[facet] = results[0][facet]
+		}
+	}
+	return counts, nil
+}
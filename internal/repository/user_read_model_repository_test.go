@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/repository/repositorytest"
+)
+
+func TestReadModelListUsersSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		search    string
+		wantRegex string
+	}{
+		{name: "text", search: "ada", wantRegex: "ada"},
+		{name: "regex operators", search: "(a+)+$", wantRegex: `\(a\+\)\+\$`},
+		{name: "email", search: "ada.l@example.com", wantRegex: `ada\.l@example\.com`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := repositorytest.NewServer(t)
+			readModel := NewUserReadModelRepository(server.Client(t).Database("app"), "user_list")
+
+			opts := &ports.GetUsersOptions{Search: tt.search, Page: 1, PageSize: 10}
+			if _, err := readModel.ListUsers(domain.WithTenant(context.Background(), "acme"), opts); err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+
+			for _, cmd := range server.Commands() {
+				filter := cmd.Body.Lookup("filter")
+				if cmd.Name == "aggregate" {
+					filter = cmd.Body.Lookup("pipeline", "0", "$match")
+				}
+				for i, field := range []string{"email", "username", "first_name", "last_name"} {
+					regex := filter.Document().Lookup("$or", strconv.Itoa(i), field, "$regex").StringValue()
+					if regex != tt.wantRegex {
+						t.Errorf("%s %s regex = %s, want %s", cmd.Name, field, regex, tt.wantRegex)
+					}
+				}
+			}
+			if len(server.Commands()) != 2 {
+				t.Errorf("commands = %d, want the count and the find", len(server.Commands()))
+			}
+		})
+	}
+}
@@ -3,6 +3,9 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/frtasoniero/user-management-api/internal/core/domain"
@@ -15,16 +18,19 @@ import (
 var _ ports.UserRepository = (*UserRepository)(nil)
 
 type UserRepository struct {
-	collection *mongo.Collection
+	collection tenantCollection
 }
 
-func NewUserRepository(db *mongo.Database, collectionName string) *UserRepository {
+func NewUserRepository(databases *TenantDatabases, collectionName string) *UserRepository {
 	return &UserRepository{
-		collection: db.Collection(collectionName),
+		collection: newTenantCollection(databases, collectionName),
 	}
 }
 
 func (r *UserRepository) GetUsers(ctx context.Context, opts *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
 	// Set defaults
 	if opts == nil {
 		opts = &ports.GetUsersOptions{Page: 1, PageSize: 10, SortBy: "created_at", Order: "asc"}
@@ -42,17 +48,9 @@ func (r *UserRepository) GetUsers(ctx context.Context, opts *ports.GetUsersOptio
 		opts.Order = "asc"
 	}
 
-	// Build query filter for search
-	filter := bson.M{}
-	if opts.Search != "" {
-		// Search in multiple fields using regex (case-insensitive)
-		filter = bson.M{
-			"$or": []bson.M{
-				{"email": bson.M{"$regex": opts.Search, "$options": "i"}},
-				{"profile.first_name": bson.M{"$regex": opts.Search, "$options": "i"}},
-				{"profile.last_name": bson.M{"$regex": opts.Search, "$options": "i"}},
-			},
-		}
+	filter, err := userFilter(ctx, opts)
+	if err != nil {
+		return nil, err
 	}
 
 	// Build find options
@@ -94,13 +92,13 @@ func (r *UserRepository) GetUsers(ctx context.Context, opts *ports.GetUsersOptio
 	findOpts.SetSort(bson.D{{Key: sortField, Value: sortOrder}})
 
 	// Get total count for pagination info (with search filter)
-	totalCount, err := r.collection.CountDocuments(ctx, filter)
+	totalCount, err := r.collection.listIn(ctx).CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
 
 	// Execute query with filter
-	cursor, err := r.collection.Find(ctx, filter, findOpts)
+	cursor, err := r.collection.listIn(ctx).Find(ctx, filter, findOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -114,6 +112,7 @@ func (r *UserRepository) GetUsers(ctx context.Context, opts *ports.GetUsersOptio
 		if err := cursor.Decode(&user); err != nil {
 			return nil, err
 		}
+		user.Profile.MigrateLegacyAddress(user.ID)
 		users = append(users, &user)
 	}
 
@@ -124,55 +123,958 @@ func (r *UserRepository) GetUsers(ctx context.Context, opts *ports.GetUsersOptio
 	// Calculate total pages
 	totalPages := int(totalCount+int64(opts.PageSize)-1) / opts.PageSize
 
-	return &ports.GetUsersResult{
+	result := &ports.GetUsersResult{
 		Users:      users,
 		TotalCount: totalCount,
 		Page:       opts.Page,
 		PageSize:   opts.PageSize,
 		TotalPages: totalPages,
+	}
+	if opts.Explain {
+		if result.Explain, err = r.explainFind(ctx, filter, findOpts); err != nil {
+			return nil, err
+		}
+	}
+	if len(opts.Facets) > 0 {
+		if result.Facets, err = r.countFacets(ctx, filter, opts.Facets); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// facetValues are the expressions of the values users are counted by, mirroring
+// domain.Profile.PrimaryAddress, domain.User.Status and domain.User.EffectiveRoles
+var facetValues = map[string]any{
+	domain.UserFacetCountry: bson.M{"$ifNull": bson.A{
+		bson.M{"$first": bson.M{"$map": bson.M{
+			"input": bson.M{"$filter": bson.M{"input": bson.M{"$ifNull": bson.A{"$profile.addresses", bson.A{}}}, "cond": "$$this.primary"}},
+			"in":    "$$this.country",
+		}}},
+		"$profile.address.country",
+		"",
+	}},
+	domain.UserFacetStatus: bson.M{"$switch": bson.M{
+		"branches": bson.A{
+			bson.M{"case": bson.M{"$gt": bson.A{"$deactivated_at", nil}}, "then": domain.UserStatusDeactivated},
+			bson.M{"case": bson.M{"$eq": bson.A{"$password_reset_required", true}}, "then": domain.UserStatusLocked},
+		},
+		"default": domain.UserStatusActive,
+	}},
+	domain.UserFacetRole: bson.M{"$cond": bson.A{
+		bson.M{"$gt": bson.A{bson.M{"$size": bson.M{"$ifNull": bson.A{"$roles", bson.A{}}}}, 0}},
+		"$roles",
+		bson.A{bson.M{"$ifNull": bson.A{"$role", domain.RoleUser}}},
+	}},
+	domain.UserFacetTag: bson.M{"$ifNull": bson.A{"$tags", bson.A{}}},
+}
+
+// countFacets counts the users matching filter by the values of each facet in a single $facet stage
+func (r *UserRepository) countFacets(ctx context.Context, filter bson.M, facets []string) (map[string][]ports.FacetCount, error) {
+	stages := bson.M{}
+	for _, facet := range facets {
+		pipeline := bson.A{bson.M{"$project": bson.M{"value": facetValues[facet]}}}
+		// Users have several roles and tags, counted once for each
+		if facet == domain.UserFacetRole || facet == domain.UserFacetTag {
+			pipeline = append(pipeline, bson.M{"$unwind": "$value"})
+		}
+		stages[facet] = append(pipeline,
+			bson.M{"$group": bson.M{"_id": "$value", "count": bson.M{"$sum": 1}}},
+			bson.M{"$sort": bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}},
+			bson.M{"$limit": domain.MaxFacetValues},
+			bson.M{"$project": bson.M{"_id": 0, "value": "$_id", "count": 1}},
+		)
+	}
+
+	cursor, err := r.collection.listIn(ctx).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$facet", Value: stages}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []map[string][]ports.FacetCount
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	counts := make(map[string][]ports.FacetCount, len(facets))
+	for _, facet := range facets {
+		counts[facet] = []ports.FacetCount{}
+		if len(results) > 0 && results[0][facet] != nil {
+			counts[facet] = results[0][facet]
+		}
+	}
+	return counts, nil
+}
+
+// explainFind returns the winning and rejected plans of a find and its execution statistics, which
+// run the query once more against the server
+func (r *UserRepository) explainFind(ctx context.Context, filter bson.M, findOpts *options.FindOptions) (map[string]any, error) {
+	users := r.collection.in(ctx)
+	find := bson.D{{Key: "find", Value: users.Name()}, {Key: "filter", Value: filter}}
+	if findOpts.Sort != nil {
+		find = append(find, bson.E{Key: "sort", Value: findOpts.Sort})
+	}
+	if findOpts.Projection != nil {
+		find = append(find, bson.E{Key: "projection", Value: findOpts.Projection})
+	}
+	if findOpts.Skip != nil {
+		find = append(find, bson.E{Key: "skip", Value: *findOpts.Skip})
+	}
+	if findOpts.Limit != nil {
+		find = append(find, bson.E{Key: "limit", Value: *findOpts.Limit})
+	}
+
+	// The plans are those of the members the lists are read from
+	runOpts := options.RunCmd()
+	if rp := r.collection.databases.listReadPref; rp != nil {
+		runOpts.SetReadPreference(rp)
+	}
+	var explain bson.M
+	err := users.Database().RunCommand(ctx, bson.D{
+		{Key: "explain", Value: find},
+		{Key: "verbosity", Value: "executionStats"},
+	}, runOpts).Decode(&explain)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"queryPlanner":   explain["queryPlanner"],
+		"executionStats": explain["executionStats"],
 	}, nil
 }
 
+// CountUsers counts the users matching the filters of opts without fetching them
+func (r *UserRepository) CountUsers(ctx context.Context, opts *ports.GetUsersOptions) (int64, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	if opts == nil {
+		opts = &ports.GetUsersOptions{}
+	}
+	filter, err := userFilter(ctx, opts)
+	if err != nil {
+		return 0, err
+	}
+	return r.collection.listIn(ctx).CountDocuments(ctx, filter)
+}
+
+// EachUser streams the users matching the filters of opts from a cursor, ignoring its pagination,
+// sorting and projection, so exports do not hold the whole tenant in memory
+func (r *UserRepository) EachUser(ctx context.Context, opts *ports.GetUsersOptions, fn func(*domain.User) error) error {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	if opts == nil {
+		opts = &ports.GetUsersOptions{}
+	}
+	filter, err := userFilter(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	// Sorting on the tenant_created_at_idx order avoids an in-memory sort of the whole tenant
+	findOpts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.listIn(ctx).FindStream(ctx, filter, findOpts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var user domain.User
+		if err := cursor.Decode(&user); err != nil {
+			return err
+		}
+		user.Profile.MigrateLegacyAddress(user.ID)
+		if err := fn(&user); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
+
+// userFilter builds the MongoDB filter for the search, metadata, tag and structured filters of opts
+func userFilter(ctx context.Context, opts *ports.GetUsersOptions) (bson.M, error) {
+	// Build query filter for search
+	filter := bson.M{}
+	if opts.Search != "" {
+		// Search in multiple fields using regex (case-insensitive)
+		filter = bson.M{
+			"$or": []bson.M{
+				{"email": bson.M{"$regex": opts.Search, "$options": "i"}},
+				{"username": bson.M{"$regex": opts.Search, "$options": "i"}},
+				{"profile.first_name": bson.M{"$regex": opts.Search, "$options": "i"}},
+				{"profile.last_name": bson.M{"$regex": opts.Search, "$options": "i"}},
+			},
+		}
+	}
+
+	// Restrict the query to the request tenant
+	filter, err := activeUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add exact-match metadata filters (keys are validated by the caller)
+	for key, value := range opts.Metadata {
+		filter["metadata."+key] = value
+	}
+
+	if opts.DirectoryLinked {
+		filter["directory_id"] = bson.M{"$exists": true}
+	}
+
+	if len(opts.IDs) > 0 {
+		filter["_id"] = bson.M{"$in": opts.IDs}
+	}
+
+	// Add tag filter (users must have every requested tag)
+	if len(opts.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": opts.Tags}
+	}
+
+	// Add the structured search filter
+	var and []bson.M
+	if opts.Filter != nil {
+		search, err := searchFilterToBSON(opts.Filter)
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, search)
+	}
+
+	// Add the inactivity filter, counting users never seen from their creation
+	if opts.InactiveSince != nil {
+		and = append(and, bson.M{"$or": []bson.M{
+			{"last_seen_at": bson.M{"$lt": *opts.InactiveSince}},
+			{"last_seen_at": bson.M{"$exists": false}, "created_at": bson.M{"$lt": *opts.InactiveSince}},
+		}})
+	}
+	// Add the distance filter, matching users having any address within the radius
+	if opts.Near != nil {
+		filter["profile.addresses.geo"] = bson.M{"$geoWithin": bson.M{
+			"$centerSphere": bson.A{bson.A{opts.Near.Lng, opts.Near.Lat}, opts.RadiusKM / earthRadiusKM},
+		}}
+	}
+	if len(and) > 0 {
+		filter["$and"] = and
+	}
+
+	return filter, nil
+}
+
+// earthRadiusKM converts distances to the radians of $centerSphere
+const earthRadiusKM = 6378.1
+
+// indexLocations sets the GeoJSON point of the located addresses, which the 2dsphere index reads
+func indexLocations(addresses []domain.Address) {
+	for i := range addresses {
+		addresses[i].Geo = nil
+		if addresses[i].Location != nil {
+			addresses[i].Geo = addresses[i].Location.GeoJSON()
+		}
+	}
+}
+
 func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
-	var user domain.User
-	if err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user); err != nil {
+	return r.findOne(ctx, bson.M{"email": email})
+}
+
+func (r *UserRepository) GetUserByNormalizedEmail(ctx context.Context, normalizedEmail string) (*domain.User, error) {
+	return r.findOne(ctx, bson.M{"normalized_email": normalizedEmail})
+}
+
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return r.findOne(ctx, bson.M{"username": username})
+}
+
+func (r *UserRepository) GetUserByExternalIdentity(ctx context.Context, issuer, subject string) (*domain.User, error) {
+	return r.findOne(ctx, bson.M{"external_identity.issuer": issuer, "external_identity.subject": subject})
+}
+
+func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	return r.findOne(ctx, bson.M{"_id": id})
+}
+
+// GetUsersByIDs returns the tenant users whose ID is in ids with a single $in query
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := activeUsers(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.collection.in(ctx).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := make([]*domain.User, 0, len(ids))
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		user.Profile.MigrateLegacyAddress(user.ID)
+	}
+	return users, nil
+}
+
+// AutocompleteUsers matches the prefix with anchored regexes: the email and username are stored
+// lowercased, so their prefixes get tight bounds on the tenant email and username indexes, while names
+// are matched case-insensitively, scanning the keys of the tenant name indexes only
+func (r *UserRepository) AutocompleteUsers(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	lower := "^" + regexp.QuoteMeta(strings.ToLower(prefix))
+	clauses := bson.A{
+		bson.M{"email": bson.M{"$regex": lower}},
+		bson.M{"username": bson.M{"$regex": lower}},
+	}
+	if first, last, found := strings.Cut(prefix, " "); found {
+		clauses = append(clauses, bson.M{
+			"profile.first_name": bson.M{"$regex": "^" + regexp.QuoteMeta(first), "$options": "i"},
+			"profile.last_name":  bson.M{"$regex": "^" + regexp.QuoteMeta(strings.TrimSpace(last)), "$options": "i"},
+		})
+	} else {
+		name := bson.M{"$regex": "^" + regexp.QuoteMeta(prefix), "$options": "i"}
+		clauses = append(clauses, bson.M{"profile.first_name": name}, bson.M{"profile.last_name": name})
+	}
+	filter, err := activeUsers(ctx, bson.M{"$or": clauses})
+	if err != nil {
+		return nil, err
+	}
+
+	findOpts := options.Find().
+		SetProjection(bson.M{"email": 1, "profile.first_name": 1, "profile.last_name": 1}).
+		SetSort(bson.D{{Key: "profile.first_name", Value: 1}, {Key: "profile.last_name", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.in(ctx).Find(ctx, filter, findOpts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := make([]*domain.User, 0, limit)
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UserExists reports whether the tenant has a user with the given ID, fetching only its _id
+func (r *UserRepository) UserExists(ctx context.Context, id string) (bool, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := activeUsers(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+
+	findOpts := options.FindOne().SetProjection(bson.M{"_id": 1})
+	if err := r.collection.in(ctx).FindOne(ctx, filter, findOpts).Err(); err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, nil
+			return false, nil
 		}
+		return false, err
+	}
+	return true, nil
+}
+
+// DuplicateGroups groups the active tenant users sharing the key of a duplicate reason:
+// the birthdate for domain.DuplicateReasonNameBirthdate, to be refined by name by the caller,
+// or the letters of the normalized email local part for domain.DuplicateReasonSimilarEmail.
+// Only groups of two or more users are returned.
+func (r *UserRepository) DuplicateGroups(ctx context.Context, reason string) ([][]*domain.User, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := activeUsers(ctx, bson.M{})
+	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+
+	var key any
+	keyFilter := bson.M{"count": bson.M{"$gte": 2}}
+	switch reason {
+	case domain.DuplicateReasonNameBirthdate:
+		filter["profile.birthdate"] = bson.M{"$nin": bson.A{"", nil}}
+		key = "$profile.birthdate"
+	case domain.DuplicateReasonSimilarEmail:
+		local := bson.M{"$arrayElemAt": bson.A{bson.M{"$split": bson.A{bson.M{"$ifNull": bson.A{"$normalized_email", "$email"}}, "@"}}, 0}}
+		key = bson.M{"$reduce": bson.M{
+			"input":        bson.M{"$regexFindAll": bson.M{"input": local, "regex": "[a-z]+"}},
+			"initialValue": "",
+			"in":           bson.M{"$concat": bson.A{"$$value", "$$this.match"}},
+		}}
+		keyFilter["$expr"] = bson.M{"$gte": bson.A{bson.M{"$strLenCP": "$_id"}, domain.MinDuplicateEmailKeyLength}}
+	default:
+		return nil, errors.New("unknown duplicate reason " + reason)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.M{"_id": key, "users": bson.M{"$push": "$$ROOT"}, "count": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: keyFilter}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+	cursor, err := r.collection.in(ctx).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var groups []struct {
+		Users []*domain.User `bson:"users"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	users := make([][]*domain.User, 0, len(groups))
+	for _, group := range groups {
+		for _, user := range group.Users {
+			user.Profile.MigrateLegacyAddress(user.ID)
+		}
+		users = append(users, group.Users)
+	}
+	return users, nil
 }
 
-func (r *UserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+// activeUsers restricts filter to the tenant users that are not soft-deleted
+func activeUsers(ctx context.Context, filter bson.M) (bson.M, error) {
+	filter, err := tenantScoped(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	filter["deleted_at"] = bson.M{"$exists": false}
+	return filter, nil
+}
+
+// findOne returns the active tenant user matching filter, or nil if there is none
+func (r *UserRepository) findOne(ctx context.Context, filter bson.M) (*domain.User, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := activeUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
 	var user domain.User
-	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user); err != nil {
+	if err := r.collection.in(ctx).FindOne(ctx, filter).Decode(&user); err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
 		}
 		return nil, err
 	}
+	user.Profile.MigrateLegacyAddress(user.ID)
 	return &user, nil
 }
 
 func (r *UserRepository) CreateUser(ctx context.Context, user *domain.User) error {
-	if _, err := r.collection.InsertOne(ctx, user); err != nil {
-		return err
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return domain.ErrMissingTenant
+	}
+	user.TenantID = tenantID
+	indexLocations(user.Profile.Addresses)
+
+	if _, err := r.collection.in(ctx).InsertOne(ctx, user); err != nil {
+		return duplicateKey(err)
 	}
 	return nil
 }
 
+// FindOrCreateUser upserts with $setOnInsert, leaving existing users untouched. Two concurrent upserts
+// can both miss and insert; the unique email index fails the second one, which then finds the user
+// of the first when run again.
+func (r *UserRepository) FindOrCreateUser(ctx context.Context, user *domain.User) (*domain.User, bool, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := activeUsers(ctx, bson.M{"email": user.Email})
+	if err != nil {
+		return nil, false, err
+	}
+	user.TenantID = filter["tenant_id"].(string)
+	indexLocations(user.Profile.Addresses)
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var found domain.User
+	err = r.collection.in(ctx).FindOneAndUpdate(ctx, filter, bson.M{"$setOnInsert": user}, opts).Decode(&found)
+	if mongo.IsDuplicateKeyError(err) {
+		err = r.collection.in(ctx).FindOneAndUpdate(ctx, filter, bson.M{"$setOnInsert": user}, opts).Decode(&found)
+	}
+	if err != nil {
+		// Still a duplicate when another user has the username or an alias of the email
+		return nil, false, duplicateKey(err)
+	}
+	found.Profile.MigrateLegacyAddress(found.ID)
+	return &found, found.ID == user.ID, nil
+}
+
+// CreateUsers inserts users into the tenant in one unordered batch, skipping the users whose
+// ID or unique fields already exist, and returns how many were inserted
+func (r *UserRepository) CreateUsers(ctx context.Context, users []*domain.User) (int, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	tenantID := domain.TenantFromContext(ctx)
+	if tenantID == "" {
+		return 0, domain.ErrMissingTenant
+	}
+	docs := make([]any, len(users))
+	for i, user := range users {
+		user.TenantID = tenantID
+		indexLocations(user.Profile.Addresses)
+		docs[i] = user
+	}
+
+	result, err := r.collection.in(ctx).InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) {
+		for _, writeErr := range bulkErr.WriteErrors {
+			if !mongo.IsDuplicateKeyError(writeErr) {
+				return 0, err
+			}
+		}
+		return len(users) - len(bulkErr.WriteErrors), nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(result.InsertedIDs), nil
+}
+
 func (r *UserRepository) UpdateUser(ctx context.Context, user *domain.User) error {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := tenantScoped(ctx, bson.M{"_id": user.ID})
+	if err != nil {
+		return err
+	}
+	// Never move a user to another tenant
+	user.TenantID = filter["tenant_id"].(string)
 	user.UpdatedAt = time.Now()
-	_, err := r.collection.UpdateOne(
-		ctx,
-		bson.M{"_id": user.ID},
-		bson.M{"$set": user},
-	)
+	user.UpdatedBy = domain.ActorFromContext(ctx).ID
+	indexLocations(user.Profile.Addresses)
+	update := bson.M{"$set": user}
+	if user.UpdatedBy == "" {
+		update["$unset"] = bson.M{"updated_by": ""}
+	}
+	_, err = r.collection.in(ctx).UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (r *UserRepository) SetUsername(ctx context.Context, id string, username string) error {
+	err := r.updateOne(ctx, id, bson.M{"$set": bson.M{"username": username, "updated_at": time.Now()}})
+	return duplicateKey(err)
+}
+
+// duplicateKey turns unique index violations on usernames and emails into domain errors.
+// Soft-deleted users keep their email, which the lookups made before inserting do not see.
+func duplicateKey(err error) error {
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	switch {
+	case strings.Contains(err.Error(), "username"):
+		return domain.ErrUsernameTaken
+	case strings.Contains(err.Error(), "email"):
+		return domain.ErrEmailTaken
+	}
 	return err
 }
 
+// SetEmailVerified records when the user proved to own their email
+func (r *UserRepository) SetEmailVerified(ctx context.Context, id string, verifiedAt time.Time) error {
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"email_verified_at": verifiedAt, "updated_at": time.Now()}})
+}
+
+// SetPasswordHash changes the password, which also lifts a required password reset
+func (r *UserRepository) SetPasswordHash(ctx context.Context, id string, passwordHash string) error {
+	return r.updateOne(ctx, id, bson.M{
+		"$set":   bson.M{"password_hash": passwordHash, "updated_at": time.Now()},
+		"$unset": bson.M{"password_reset_required": ""},
+	})
+}
+
+// RequirePasswordReset blocks the logins of the user until its password is changed
+func (r *UserRepository) RequirePasswordReset(ctx context.Context, id string) error {
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"password_reset_required": true, "updated_at": time.Now()}})
+}
+
+// SetDeactivated sets the deactivation time, or removes it to reactivate the user when at is nil
+func (r *UserRepository) SetDeactivated(ctx context.Context, id string, at *time.Time) error {
+	if at == nil {
+		return r.updateOne(ctx, id, bson.M{"$unset": bson.M{"deactivated_at": ""}, "$set": bson.M{"updated_at": time.Now()}})
+	}
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"deactivated_at": *at, "updated_at": time.Now()}})
+}
+
+// RecordLogin sets the last login time, which also counts as the user being seen
+func (r *UserRepository) RecordLogin(ctx context.Context, id string, at time.Time) error {
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"last_login_at": at, "last_seen_at": at}})
+}
+
+func (r *UserRepository) GetTokenGeneration(ctx context.Context, id string) (int, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return 0, err
+	}
+
+	var user domain.User
+	findOpts := options.FindOne().SetProjection(bson.M{"token_generation": 1})
+	if err := r.collection.in(ctx).FindOne(ctx, filter, findOpts).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return user.TokenGeneration, nil
+}
+
+func (r *UserRepository) IncrementTokenGeneration(ctx context.Context, id string) (int, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return 0, err
+	}
+
+	update := bson.M{"$inc": bson.M{"token_generation": 1}, "$set": bson.M{"updated_at": time.Now()}}
+	findOpts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"token_generation": 1})
+
+	var user domain.User
+	if err := r.collection.in(ctx).FindOneAndUpdate(ctx, filter, withUpdatedBy(ctx, update), findOpts).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return user.TokenGeneration, nil
+}
+
+func (r *UserRepository) SetLastSeen(ctx context.Context, id string, at time.Time) error {
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"last_seen_at": at}})
+}
+
+func (r *UserRepository) SetAvatar(ctx context.Context, id string, avatar *domain.Avatar) error {
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"avatar": avatar, "updated_at": time.Now()}})
+}
+
+func (r *UserRepository) SetVerification(ctx context.Context, id string, verification *domain.Verification, fromStatus string) (bool, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return false, err
+	}
+	if fromStatus == domain.VerificationStatusUnverified {
+		filter["verification"] = bson.M{"$exists": false}
+	} else {
+		filter["verification.status"] = fromStatus
+	}
+
+	result, err := r.collection.in(ctx).UpdateOne(ctx, filter, withUpdatedBy(ctx, bson.M{"$set": bson.M{"verification": verification, "updated_at": time.Now()}}))
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+func (r *UserRepository) GetUserByVerificationCheck(ctx context.Context, provider, checkID string) (*domain.User, error) {
+	return r.findOne(ctx, bson.M{"verification.check.provider": provider, "verification.check.id": checkID})
+}
+
+func (r *UserRepository) SetSettings(ctx context.Context, id string, settings domain.Settings) error {
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"settings": settings, "updated_at": time.Now()}})
+}
+
+// SetProfile sets every profile field but the addresses, so that concurrent address changes are kept
+func (r *UserRepository) SetProfile(ctx context.Context, id string, profile domain.Profile) error {
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{
+		"profile.first_name": profile.FirstName,
+		"profile.last_name":  profile.LastName,
+		"profile.phone":      profile.Phone,
+		"profile.birthdate":  profile.Birthdate,
+		"profile.nin":        profile.NIN,
+		"profile.timezone":   profile.Timezone,
+		"profile.locale":     profile.Locale,
+		"updated_at":         time.Now(),
+	}})
+}
+
+func (r *UserRepository) SetPhoneChallenge(ctx context.Context, id string, challenge *domain.PhoneChallenge) error {
+	if challenge == nil {
+		return r.updateOne(ctx, id, bson.M{"$unset": bson.M{"phone_challenge": ""}})
+	}
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"phone_challenge": challenge}})
+}
+
+func (r *UserRepository) AddPhoneChallengeAttempt(ctx context.Context, id string) error {
+	return r.updateOne(ctx, id, bson.M{"$inc": bson.M{"phone_challenge.attempts": 1}})
+}
+
+// SetPhoneVerified matches the phone of the profile, so that a code sent to a phone since replaced
+// cannot verify the new one
+func (r *UserRepository) SetPhoneVerified(ctx context.Context, id, phone string, at *time.Time) (bool, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := tenantScoped(ctx, bson.M{"_id": id, "profile.phone": phone})
+	if err != nil {
+		return false, err
+	}
+	update := bson.M{"$unset": bson.M{"phone_challenge": "", "phone_verified_at": ""}}
+	if at != nil {
+		update = bson.M{"$set": bson.M{"phone_verified_at": *at, "updated_at": time.Now()}, "$unset": bson.M{"phone_challenge": ""}}
+	}
+	result, err := r.collection.in(ctx).UpdateOne(ctx, filter, withUpdatedBy(ctx, update))
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+func (r *UserRepository) SetMFAFactors(ctx context.Context, id string, factors []domain.MFAFactor) error {
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"mfa_factors": factors, "updated_at": time.Now()}})
+}
+
+func (r *UserRepository) AddMFAChallengeAttempt(ctx context.Context, id, factorID string) (bool, error) {
+	factor := bson.M{"id": factorID, "challenge.attempts": bson.M{"$lt": domain.MaxPhoneCodeAttempts}}
+	return r.updateMFAFactor(ctx, id, factor, bson.M{"$inc": bson.M{"mfa_factors.$[f].challenge.attempts": 1}})
+}
+
+func (r *UserRepository) ConsumeMFAChallenge(ctx context.Context, id, factorID, codeHash string, at time.Time) (bool, error) {
+	factor := bson.M{"id": factorID, "challenge.code_hash": codeHash}
+	return r.updateMFAFactor(ctx, id, factor, bson.M{
+		"$set":   bson.M{"mfa_factors.$[f].last_used_at": at, "updated_at": time.Now()},
+		"$unset": bson.M{"mfa_factors.$[f].challenge": ""},
+	})
+}
+
+func (r *UserRepository) UseMFAStep(ctx context.Context, id, factorID string, step int64, at time.Time) (bool, error) {
+	// The last step is not stored until a first code is used
+	factor := bson.M{"id": factorID, "last_used_step": bson.M{"$not": bson.M{"$gte": step}}}
+	return r.updateMFAFactor(ctx, id, factor, bson.M{"$set": bson.M{
+		"mfa_factors.$[f].last_used_step": step,
+		"mfa_factors.$[f].last_used_at":   at,
+		"updated_at":                      time.Now(),
+	}})
+}
+
+// updateMFAFactor applies update to the MFA factor of the tenant user matching factor, known as f in
+// update, and reports whether there was one
+func (r *UserRepository) updateMFAFactor(ctx context.Context, id string, factor bson.M, update bson.M) (bool, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := tenantScoped(ctx, bson.M{"_id": id, "mfa_factors": bson.M{"$elemMatch": factor}})
+	if err != nil {
+		return false, err
+	}
+	arrayFilter := bson.M{}
+	for field, condition := range factor {
+		arrayFilter["f."+field] = condition
+	}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: []any{arrayFilter}})
+	result, err := r.collection.in(ctx).UpdateOne(ctx, filter, withUpdatedBy(ctx, update), opts)
+	if err != nil {
+		return false, err
+	}
+	return result.MatchedCount == 1, nil
+}
+
+func (r *UserRepository) SetTrustedDevices(ctx context.Context, id string, devices []domain.TrustedDevice) error {
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"trusted_devices": devices, "updated_at": time.Now()}})
+}
+
+// SetAddresses replaces the user addresses, dropping the legacy single address they were migrated from
+func (r *UserRepository) SetAddresses(ctx context.Context, id string, addresses []domain.Address) error {
+	indexLocations(addresses)
+	return r.updateOne(ctx, id, bson.M{
+		"$set":   bson.M{"profile.addresses": addresses, "updated_at": time.Now()},
+		"$unset": bson.M{"profile.address": ""},
+	})
+}
+
+func (r *UserRepository) SetMetadata(ctx context.Context, id string, metadata map[string]string) error {
+	return r.updateOne(ctx, id, bson.M{"$set": bson.M{"metadata": metadata, "updated_at": time.Now()}})
+}
+
+// updateOne applies update to the tenant user with the given ID
+func (r *UserRepository) updateOne(ctx context.Context, id string, update bson.M) error {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.in(ctx).UpdateOne(ctx, filter, withUpdatedBy(ctx, update))
+	return err
+}
+
+// withUpdatedBy records the caller carried by ctx, see domain.ActorFromContext, as the last to update
+// the users of an update setting updated_at, and clears it for the updates of background work
+func withUpdatedBy(ctx context.Context, update bson.M) bson.M {
+	set, ok := update["$set"].(bson.M)
+	if !ok || set["updated_at"] == nil {
+		return update
+	}
+	if actorID := domain.ActorFromContext(ctx).ID; actorID != "" {
+		set["updated_by"] = actorID
+		return update
+	}
+	unset, ok := update["$unset"].(bson.M)
+	if !ok {
+		unset = bson.M{}
+		update["$unset"] = unset
+	}
+	unset["updated_by"] = ""
+	return update
+}
+
+func (r *UserRepository) AddTags(ctx context.Context, id string, tags []string) ([]string, error) {
+	return r.updateTags(ctx, id, bson.M{
+		"$addToSet": bson.M{"tags": bson.M{"$each": tags}},
+		"$set":      bson.M{"updated_at": time.Now()},
+	})
+}
+
+func (r *UserRepository) RemoveTag(ctx context.Context, id string, tag string) ([]string, error) {
+	return r.updateTags(ctx, id, bson.M{
+		"$pull": bson.M{"tags": tag},
+		"$set":  bson.M{"updated_at": time.Now()},
+	})
+}
+
+// updateTags applies a tag update and returns the resulting tags, or nil if the user does not exist
+func (r *UserRepository) updateTags(ctx context.Context, id string, update bson.M) ([]string, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	findOpts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"tags": 1})
+
+	var user domain.User
+	if err := r.collection.in(ctx).FindOneAndUpdate(ctx, filter, withUpdatedBy(ctx, update), findOpts).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if user.Tags == nil {
+		user.Tags = []string{}
+	}
+	return user.Tags, nil
+}
+
+// SetRoles replaces the user roles and drops the legacy single-role field
+func (r *UserRepository) SetRoles(ctx context.Context, id string, roles []string) error {
+	return r.updateOne(ctx, id, bson.M{
+		"$set":   bson.M{"roles": roles, "updated_at": time.Now()},
+		"$unset": bson.M{"role": ""},
+	})
+}
+
+func (r *UserRepository) RemoveRoleFromAllUsers(ctx context.Context, role string) error {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := tenantScoped(ctx, bson.M{"roles": role})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.in(ctx).UpdateMany(ctx, filter, withUpdatedBy(ctx, bson.M{
+		"$pull": bson.M{"roles": role},
+		"$set":  bson.M{"updated_at": time.Now()},
+	}))
+	return err
+}
+
+// UpdateUsers applies a batch update with a single UpdateMany. Suspended users have their token
+// generation bumped, revoking their tokens like a logout from all devices.
+func (r *UserRepository) UpdateUsers(ctx context.Context, opts *ports.GetUsersOptions, update domain.UserBatchUpdate) (*ports.UserBatchResult, error) {
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	if opts == nil {
+		opts = &ports.GetUsersOptions{}
+	}
+	filter, err := userFilter(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	set := bson.M{"updated_at": now}
+	changes := bson.M{"$set": set}
+	if len(update.AddTags) > 0 {
+		changes["$addToSet"] = bson.M{"tags": bson.M{"$each": update.AddTags}}
+	}
+	if len(update.RemoveTags) > 0 {
+		changes["$pull"] = bson.M{"tags": bson.M{"$in": update.RemoveTags}}
+	}
+	if update.Deactivated != nil {
+		if *update.Deactivated {
+			set["deactivated_at"] = now
+			changes["$inc"] = bson.M{"token_generation": 1}
+		} else {
+			changes["$unset"] = bson.M{"deactivated_at": ""}
+		}
+	}
+
+	result, err := r.collection.in(ctx).UpdateMany(ctx, filter, withUpdatedBy(ctx, changes))
+	if err != nil {
+		return nil, err
+	}
+	return &ports.UserBatchResult{Matched: result.MatchedCount, Modified: result.ModifiedCount}, nil
+}
+
+// SoftDeleteUser hides a user merged into another one. Its username and NIN are released
+// so the user it was merged into can take them; its email stays reserved.
+func (r *UserRepository) SoftDeleteUser(ctx context.Context, id string, mergedInto string) error {
+	now := time.Now()
+	return r.updateOne(ctx, id, bson.M{
+		"$set":   bson.M{"deleted_at": now, "merged_into": mergedInto, "updated_at": now},
+		"$unset": bson.M{"username": "", "profile.nin": ""},
+	})
+}
+
 func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	ctx, end := r.collection.causal(ctx)
+	defer end()
+
+	filter, err := tenantScoped(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	_, err = r.collection.in(ctx).DeleteOne(ctx, filter)
 	return err
 }
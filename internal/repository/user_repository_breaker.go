@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// Compile-time interface checks
+var (
+	_ ports.UserRepository  = (*CircuitBreakerUserRepository)(nil)
+	_ ports.BreakerReporter = (*CircuitBreakerUserRepository)(nil)
+)
+
+// CircuitBreakerUserRepository is a circuit breaker around a user repository. After FailureThreshold
+// consecutive calls failed because the database is unreachable, calls fail at once with
+// ports.ErrDatabaseUnavailable for OpenTimeout; then a single probe call is let through, closing the
+// breaker when it succeeds and opening it again when it fails. Errors such as duplicate keys or
+// validation failures show that the database answers and do not count as failures.
+type CircuitBreakerUserRepository struct {
+	next   ports.UserRepository
+	policy ports.BreakerPolicy
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time // Zero while closed
+	probing   bool      // A half-open probe is in flight
+	opened    int64
+	rejected  int64
+}
+
+func NewCircuitBreakerUserRepository(users ports.UserRepository, policy ports.BreakerPolicy) *CircuitBreakerUserRepository {
+	return &CircuitBreakerUserRepository{
+		next:   users,
+		policy: policy,
+	}
+}
+
+func (b *CircuitBreakerUserRepository) BreakerStats() ports.BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := ports.BreakerStats{
+		State:               b.state(time.Now()),
+		ConsecutiveFailures: b.failures,
+		Opened:              b.opened,
+		Rejected:            b.rejected,
+	}
+	if stats.State == ports.BreakerOpen {
+		openUntil := b.openUntil
+		stats.OpenUntil = &openUntil
+	}
+	return stats
+}
+
+// state returns the breaker state at now; the caller holds mu
+func (b *CircuitBreakerUserRepository) state(now time.Time) string {
+	switch {
+	case b.openUntil.IsZero():
+		return ports.BreakerClosed
+	case now.Before(b.openUntil):
+		return ports.BreakerOpen
+	default:
+		return ports.BreakerHalfOpen
+	}
+}
+
+// allow reports whether a call may reach the database and claims the probe when half-open
+func (b *CircuitBreakerUserRepository) allow() (probe bool, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state(time.Now()) {
+	case ports.BreakerClosed:
+		return false, nil
+	case ports.BreakerHalfOpen:
+		if !b.probing {
+			b.probing = true
+			return true, nil
+		}
+	}
+	b.rejected++
+	return false, ports.ErrDatabaseUnavailable
+}
+
+// record updates the breaker with the outcome of a call let through by allow
+func (b *CircuitBreakerUserRepository) record(ctx context.Context, probe bool, err error) {
+	failed := err != nil && isUnavailableError(ctx, err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probing = false
+	}
+	if !failed {
+		if probe || err == nil {
+			b.failures = 0
+			b.openUntil = time.Time{}
+		}
+		return
+	}
+
+	b.failures++
+	if probe || (b.openUntil.IsZero() && b.failures >= b.policy.FailureThreshold) {
+		b.openUntil = time.Now().Add(b.policy.OpenTimeout)
+		b.opened++
+	}
+}
+
+// isUnavailableError reports whether err shows that the database could not be reached: a lost
+// connection, no server to select or a replica set state change. Timeouts are left out, as slow
+// queries also run past the deadlines of requests and operations, and calls whose context ended say
+// nothing about the database.
+func isUnavailableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var selectionErr topology.ServerSelectionError
+	return isTransientError(err) || errors.As(err, &selectionErr)
+}
+
+// call runs fn through the breaker
+func call[T any](b *CircuitBreakerUserRepository, ctx context.Context, fn func() (T, error)) (T, error) {
+	probe, err := b.allow()
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	result, err := fn()
+	b.record(ctx, probe, err)
+	return result, err
+}
+
+// exec runs fn, which returns no result, through the breaker
+func (b *CircuitBreakerUserRepository) exec(ctx context.Context, fn func() error) error {
+	_, err := call(b, ctx, func() (struct{}, error) {
+		return struct{}{}, fn()
+	})
+	return err
+}
+
+func (b *CircuitBreakerUserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	return b.exec(ctx, func() error { return b.next.CreateUser(ctx, user) })
+}
+
+func (b *CircuitBreakerUserRepository) FindOrCreateUser(ctx context.Context, user *domain.User) (*domain.User, bool, error) {
+	var created bool
+	found, err := call(b, ctx, func() (*domain.User, error) {
+		found, inserted, err := b.next.FindOrCreateUser(ctx, user)
+		created = inserted
+		return found, err
+	})
+	return found, created, err
+}
+
+func (b *CircuitBreakerUserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	return call(b, ctx, func() (*domain.User, error) { return b.next.GetUserByID(ctx, id) })
+}
+
+func (b *CircuitBreakerUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return call(b, ctx, func() (*domain.User, error) { return b.next.GetUserByEmail(ctx, email) })
+}
+
+func (b *CircuitBreakerUserRepository) GetUserByNormalizedEmail(ctx context.Context, normalizedEmail string) (*domain.User, error) {
+	return call(b, ctx, func() (*domain.User, error) { return b.next.GetUserByNormalizedEmail(ctx, normalizedEmail) })
+}
+
+func (b *CircuitBreakerUserRepository) GetUserByUsername(ctx context.Context, username string) (*domain.User, error) {
+	return call(b, ctx, func() (*domain.User, error) { return b.next.GetUserByUsername(ctx, username) })
+}
+
+func (b *CircuitBreakerUserRepository) GetUserByExternalIdentity(ctx context.Context, issuer, subject string) (*domain.User, error) {
+	return call(b, ctx, func() (*domain.User, error) { return b.next.GetUserByExternalIdentity(ctx, issuer, subject) })
+}
+
+func (b *CircuitBreakerUserRepository) AutocompleteUsers(ctx context.Context, prefix string, limit int) ([]*domain.User, error) {
+	return call(b, ctx, func() ([]*domain.User, error) { return b.next.AutocompleteUsers(ctx, prefix, limit) })
+}
+
+func (b *CircuitBreakerUserRepository) GetUsersByIDs(ctx context.Context, ids []string) ([]*domain.User, error) {
+	return call(b, ctx, func() ([]*domain.User, error) { return b.next.GetUsersByIDs(ctx, ids) })
+}
+
+func (b *CircuitBreakerUserRepository) UserExists(ctx context.Context, id string) (bool, error) {
+	return call(b, ctx, func() (bool, error) { return b.next.UserExists(ctx, id) })
+}
+
+func (b *CircuitBreakerUserRepository) GetUsers(ctx context.Context, opts *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+	return call(b, ctx, func() (*ports.GetUsersResult, error) { return b.next.GetUsers(ctx, opts) })
+}
+
+func (b *CircuitBreakerUserRepository) CountUsers(ctx context.Context, opts *ports.GetUsersOptions) (int64, error) {
+	return call(b, ctx, func() (int64, error) { return b.next.CountUsers(ctx, opts) })
+}
+
+func (b *CircuitBreakerUserRepository) DuplicateGroups(ctx context.Context, reason string) ([][]*domain.User, error) {
+	return call(b, ctx, func() ([][]*domain.User, error) { return b.next.DuplicateGroups(ctx, reason) })
+}
+
+func (b *CircuitBreakerUserRepository) EachUser(ctx context.Context, opts *ports.GetUsersOptions, fn func(*domain.User) error) error {
+	return b.exec(ctx, func() error { return b.next.EachUser(ctx, opts, fn) })
+}
+
+func (b *CircuitBreakerUserRepository) UpdateUser(ctx context.Context, user *domain.User) error {
+	return b.exec(ctx, func() error { return b.next.UpdateUser(ctx, user) })
+}
+
+func (b *CircuitBreakerUserRepository) SetUsername(ctx context.Context, id string, username string) error {
+	return b.exec(ctx, func() error { return b.next.SetUsername(ctx, id, username) })
+}
+
+func (b *CircuitBreakerUserRepository) SetEmailVerified(ctx context.Context, id string, verifiedAt time.Time) error {
+	return b.exec(ctx, func() error { return b.next.SetEmailVerified(ctx, id, verifiedAt) })
+}
+
+func (b *CircuitBreakerUserRepository) SetPasswordHash(ctx context.Context, id string, passwordHash string) error {
+	return b.exec(ctx, func() error { return b.next.SetPasswordHash(ctx, id, passwordHash) })
+}
+
+func (b *CircuitBreakerUserRepository) RequirePasswordReset(ctx context.Context, id string) error {
+	return b.exec(ctx, func() error { return b.next.RequirePasswordReset(ctx, id) })
+}
+
+func (b *CircuitBreakerUserRepository) SetDeactivated(ctx context.Context, id string, at *time.Time) error {
+	return b.exec(ctx, func() error { return b.next.SetDeactivated(ctx, id, at) })
+}
+
+func (b *CircuitBreakerUserRepository) RecordLogin(ctx context.Context, id string, at time.Time) error {
+	return b.exec(ctx, func() error { return b.next.RecordLogin(ctx, id, at) })
+}
+
+func (b *CircuitBreakerUserRepository) GetTokenGeneration(ctx context.Context, id string) (int, error) {
+	return call(b, ctx, func() (int, error) { return b.next.GetTokenGeneration(ctx, id) })
+}
+
+func (b *CircuitBreakerUserRepository) IncrementTokenGeneration(ctx context.Context, id string) (int, error) {
+	return call(b, ctx, func() (int, error) { return b.next.IncrementTokenGeneration(ctx, id) })
+}
+
+func (b *CircuitBreakerUserRepository) SetLastSeen(ctx context.Context, id string, at time.Time) error {
+	return b.exec(ctx, func() error { return b.next.SetLastSeen(ctx, id, at) })
+}
+
+func (b *CircuitBreakerUserRepository) SetAvatar(ctx context.Context, id string, avatar *domain.Avatar) error {
+	return b.exec(ctx, func() error { return b.next.SetAvatar(ctx, id, avatar) })
+}
+
+func (b *CircuitBreakerUserRepository) SetVerification(ctx context.Context, id string, verification *domain.Verification, fromStatus string) (bool, error) {
+	return call(b, ctx, func() (bool, error) { return b.next.SetVerification(ctx, id, verification, fromStatus) })
+}
+
+func (b *CircuitBreakerUserRepository) GetUserByVerificationCheck(ctx context.Context, provider, checkID string) (*domain.User, error) {
+	return call(b, ctx, func() (*domain.User, error) { return b.next.GetUserByVerificationCheck(ctx, provider, checkID) })
+}
+
+func (b *CircuitBreakerUserRepository) SetSettings(ctx context.Context, id string, settings domain.Settings) error {
+	return b.exec(ctx, func() error { return b.next.SetSettings(ctx, id, settings) })
+}
+
+func (b *CircuitBreakerUserRepository) SetProfile(ctx context.Context, id string, profile domain.Profile) error {
+	return b.exec(ctx, func() error { return b.next.SetProfile(ctx, id, profile) })
+}
+
+func (b *CircuitBreakerUserRepository) SetPhoneChallenge(ctx context.Context, id string, challenge *domain.PhoneChallenge) error {
+	return b.exec(ctx, func() error { return b.next.SetPhoneChallenge(ctx, id, challenge) })
+}
+
+func (b *CircuitBreakerUserRepository) AddPhoneChallengeAttempt(ctx context.Context, id string) error {
+	return b.exec(ctx, func() error { return b.next.AddPhoneChallengeAttempt(ctx, id) })
+}
+
+func (b *CircuitBreakerUserRepository) SetPhoneVerified(ctx context.Context, id, phone string, at *time.Time) (bool, error) {
+	return call(b, ctx, func() (bool, error) { return b.next.SetPhoneVerified(ctx, id, phone, at) })
+}
+
+func (b *CircuitBreakerUserRepository) SetMFAFactors(ctx context.Context, id string, factors []domain.MFAFactor) error {
+	return b.exec(ctx, func() error { return b.next.SetMFAFactors(ctx, id, factors) })
+}
+
+func (b *CircuitBreakerUserRepository) AddMFAChallengeAttempt(ctx context.Context, id, factorID string) (bool, error) {
+	return call(b, ctx, func() (bool, error) { return b.next.AddMFAChallengeAttempt(ctx, id, factorID) })
+}
+
+func (b *CircuitBreakerUserRepository) ConsumeMFAChallenge(ctx context.Context, id, factorID, codeHash string, at time.Time) (bool, error) {
+	return call(b, ctx, func() (bool, error) { return b.next.ConsumeMFAChallenge(ctx, id, factorID, codeHash, at) })
+}
+
+func (b *CircuitBreakerUserRepository) UseMFAStep(ctx context.Context, id, factorID string, step int64, at time.Time) (bool, error) {
+	return call(b, ctx, func() (bool, error) { return b.next.UseMFAStep(ctx, id, factorID, step, at) })
+}
+
+func (b *CircuitBreakerUserRepository) SetTrustedDevices(ctx context.Context, id string, devices []domain.TrustedDevice) error {
+	return b.exec(ctx, func() error { return b.next.SetTrustedDevices(ctx, id, devices) })
+}
+
+func (b *CircuitBreakerUserRepository) SetAddresses(ctx context.Context, id string, addresses []domain.Address) error {
+	return b.exec(ctx, func() error { return b.next.SetAddresses(ctx, id, addresses) })
+}
+
+func (b *CircuitBreakerUserRepository) SetMetadata(ctx context.Context, id string, metadata map[string]string) error {
+	return b.exec(ctx, func() error { return b.next.SetMetadata(ctx, id, metadata) })
+}
+
+func (b *CircuitBreakerUserRepository) AddTags(ctx context.Context, id string, tags []string) ([]string, error) {
+	return call(b, ctx, func() ([]string, error) { return b.next.AddTags(ctx, id, tags) })
+}
+
+func (b *CircuitBreakerUserRepository) RemoveTag(ctx context.Context, id string, tag string) ([]string, error) {
+	return call(b, ctx, func() ([]string, error) { return b.next.RemoveTag(ctx, id, tag) })
+}
+
+func (b *CircuitBreakerUserRepository) SetRoles(ctx context.Context, id string, roles []string) error {
+	return b.exec(ctx, func() error { return b.next.SetRoles(ctx, id, roles) })
+}
+
+func (b *CircuitBreakerUserRepository) RemoveRoleFromAllUsers(ctx context.Context, role string) error {
+	return b.exec(ctx, func() error { return b.next.RemoveRoleFromAllUsers(ctx, role) })
+}
+
+func (b *CircuitBreakerUserRepository) UpdateUsers(ctx context.Context, opts *ports.GetUsersOptions, update domain.UserBatchUpdate) (*ports.UserBatchResult, error) {
+	return call(b, ctx, func() (*ports.UserBatchResult, error) { return b.next.UpdateUsers(ctx, opts, update) })
+}
+
+func (b *CircuitBreakerUserRepository) SoftDeleteUser(ctx context.Context, id string, mergedInto string) error {
+	return b.exec(ctx, func() error { return b.next.SoftDeleteUser(ctx, id, mergedInto) })
+}
+
+func (b *CircuitBreakerUserRepository) DeleteUser(ctx context.Context, id string) error {
+	return b.exec(ctx, func() error { return b.next.DeleteUser(ctx, id) })
+}
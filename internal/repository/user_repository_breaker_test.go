@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+var (
+	errNetwork   = mongo.CommandError{Message: "connection reset", Labels: []string{"NetworkError"}}
+	errStepdown  = mongo.CommandError{Code: 189, Message: "primary stepped down"}
+	errSelection = topology.ServerSelectionError{Wrapped: errors.New("no reachable servers")}
+)
+
+func TestIsUnavailableError(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want bool
+	}{
+		{name: "network error", err: errNetwork, want: true},
+		{name: "wrapped network error", err: fmt.Errorf("finding user: %w", errNetwork), want: true},
+		{name: "replica set state change", err: errStepdown, want: true},
+		{name: "server selection", err: errSelection, want: true},
+		{name: "deadline of the operation", err: context.DeadlineExceeded},
+		{name: "network error past the deadline", err: mongo.CommandError{Labels: []string{"NetworkError"}, Wrapped: context.DeadlineExceeded}},
+		{name: "server time limit", err: mongo.CommandError{Code: 50, Name: "MaxTimeMSExpired"}},
+		{name: "duplicate key", err: mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}},
+		{name: "request cancelled", ctx: cancelled, err: errNetwork},
+		{name: "request past its deadline", ctx: expired, err: errSelection},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			if ctx == nil {
+				ctx = context.Background()
+			}
+			if got := isUnavailableError(ctx, tt.err); got != tt.want {
+				t.Errorf("isUnavailableError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error // Errors of the successive calls
+		wantState string
+	}{
+		{name: "unreachable database", errs: []error{errNetwork, errSelection, errStepdown}, wantState: ports.BreakerOpen},
+		{name: "slow queries", errs: []error{context.DeadlineExceeded, context.DeadlineExceeded, context.DeadlineExceeded}, wantState: ports.BreakerClosed},
+		{name: "failures not in a row", errs: []error{errNetwork, errNetwork, nil, errNetwork}, wantState: ports.BreakerClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			users := &mocks.UserRepository{
+				GetUserByIDFunc: func(context.Context, string) (*domain.User, error) {
+					err := tt.errs[calls]
+					calls++
+					return nil, err
+				},
+			}
+			breaker := NewCircuitBreakerUserRepository(users, ports.BreakerPolicy{FailureThreshold: 3, OpenTimeout: time.Minute})
+			for range tt.errs {
+				breaker.GetUserByID(context.Background(), "u1")
+			}
+			if state := breaker.BreakerStats().State; state != tt.wantState {
+				t.Fatalf("state = %s, want %s", state, tt.wantState)
+			}
+			if tt.wantState != ports.BreakerOpen {
+				return
+			}
+			if _, err := breaker.GetUserByID(context.Background(), "u1"); !errors.Is(err, ports.ErrDatabaseUnavailable) {
+				t.Errorf("GetUserByID() once open error = %v, want %v", err, ports.ErrDatabaseUnavailable)
+			}
+			if calls != len(tt.errs) {
+				t.Errorf("database called %d times, want %d", calls, len(tt.errs))
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.UserRepository = (*HistoryUserRepository)(nil)
+
+// HistoryUserRepository records a version in the change history of a user for every write changing
+// the fields the API shows of it, its settings or its password, by reading the user before and
+// after the write. The writes of the login, activity, MFA and verification state of users, and the
+// deletions, are not versioned. Failing to record a version does not fail the write.
+type HistoryUserRepository struct {
+	ports.UserRepository
+	history ports.UserHistoryRepository
+}
+
+func NewHistoryUserRepository(users ports.UserRepository, history ports.UserHistoryRepository) *HistoryUserRepository {
+	return &HistoryUserRepository{
+		UserRepository: users,
+		history:        history,
+	}
+}
+
+func (r *HistoryUserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	if err := r.UserRepository.CreateUser(ctx, user); err != nil {
+		return err
+	}
+	r.record(ctx, &domain.User{}, user)
+	return nil
+}
+
+func (r *HistoryUserRepository) FindOrCreateUser(ctx context.Context, user *domain.User) (*domain.User, bool, error) {
+	found, created, err := r.UserRepository.FindOrCreateUser(ctx, user)
+	if err == nil && created {
+		r.record(ctx, &domain.User{}, found)
+	}
+	return found, created, err
+}
+
+func (r *HistoryUserRepository) UpdateUser(ctx context.Context, user *domain.User) error {
+	return r.track(ctx, user.ID, func() error {
+		return r.UserRepository.UpdateUser(ctx, user)
+	})
+}
+
+func (r *HistoryUserRepository) SetUsername(ctx context.Context, id string, username string) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.SetUsername(ctx, id, username)
+	})
+}
+
+func (r *HistoryUserRepository) SetEmailVerified(ctx context.Context, id string, verifiedAt time.Time) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.SetEmailVerified(ctx, id, verifiedAt)
+	})
+}
+
+func (r *HistoryUserRepository) SetPasswordHash(ctx context.Context, id string, passwordHash string) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.SetPasswordHash(ctx, id, passwordHash)
+	})
+}
+
+func (r *HistoryUserRepository) RequirePasswordReset(ctx context.Context, id string) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.RequirePasswordReset(ctx, id)
+	})
+}
+
+func (r *HistoryUserRepository) SetDeactivated(ctx context.Context, id string, at *time.Time) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.SetDeactivated(ctx, id, at)
+	})
+}
+
+func (r *HistoryUserRepository) SetAvatar(ctx context.Context, id string, avatar *domain.Avatar) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.SetAvatar(ctx, id, avatar)
+	})
+}
+
+func (r *HistoryUserRepository) SetSettings(ctx context.Context, id string, settings domain.Settings) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.SetSettings(ctx, id, settings)
+	})
+}
+
+func (r *HistoryUserRepository) SetProfile(ctx context.Context, id string, profile domain.Profile) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.SetProfile(ctx, id, profile)
+	})
+}
+
+func (r *HistoryUserRepository) SetPhoneVerified(ctx context.Context, id, phone string, at *time.Time) (bool, error) {
+	var updated bool
+	err := r.track(ctx, id, func() error {
+		var err error
+		updated, err = r.UserRepository.SetPhoneVerified(ctx, id, phone, at)
+		return err
+	})
+	return updated, err
+}
+
+func (r *HistoryUserRepository) SetAddresses(ctx context.Context, id string, addresses []domain.Address) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.SetAddresses(ctx, id, addresses)
+	})
+}
+
+func (r *HistoryUserRepository) SetMetadata(ctx context.Context, id string, metadata map[string]string) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.SetMetadata(ctx, id, metadata)
+	})
+}
+
+func (r *HistoryUserRepository) AddTags(ctx context.Context, id string, tags []string) ([]string, error) {
+	var updated []string
+	err := r.track(ctx, id, func() error {
+		var err error
+		updated, err = r.UserRepository.AddTags(ctx, id, tags)
+		return err
+	})
+	return updated, err
+}
+
+func (r *HistoryUserRepository) RemoveTag(ctx context.Context, id string, tag string) ([]string, error) {
+	var updated []string
+	err := r.track(ctx, id, func() error {
+		var err error
+		updated, err = r.UserRepository.RemoveTag(ctx, id, tag)
+		return err
+	})
+	return updated, err
+}
+
+func (r *HistoryUserRepository) SetRoles(ctx context.Context, id string, roles []string) error {
+	return r.track(ctx, id, func() error {
+		return r.UserRepository.SetRoles(ctx, id, roles)
+	})
+}
+
+func (r *HistoryUserRepository) RemoveRoleFromAllUsers(ctx context.Context, role string) error {
+	opts := &ports.GetUsersOptions{Filter: &domain.SearchFilter{Field: "roles", Op: domain.SearchOpEq, Value: role}}
+	return r.trackMany(ctx, opts, func() error {
+		return r.UserRepository.RemoveRoleFromAllUsers(ctx, role)
+	})
+}
+
+func (r *HistoryUserRepository) UpdateUsers(ctx context.Context, opts *ports.GetUsersOptions, update domain.UserBatchUpdate) (*ports.UserBatchResult, error) {
+	var result *ports.UserBatchResult
+	err := r.trackMany(ctx, opts, func() error {
+		var err error
+		result, err = r.UserRepository.UpdateUsers(ctx, opts, update)
+		return err
+	})
+	return result, err
+}
+
+// track runs the write of the user with the ID and records its changes
+func (r *HistoryUserRepository) track(ctx context.Context, id string, write func() error) error {
+	before, err := r.UserRepository.GetUserByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	if before == nil {
+		return nil
+	}
+
+	after, err := r.UserRepository.GetUserByID(ctx, id)
+	if err != nil {
+		log.Printf("Error reading user %s for its change history: %v", id, err)
+		return nil
+	}
+	if after != nil {
+		r.record(ctx, before, after)
+	}
+	return nil
+}
+
+// trackMany runs the write of the users matching opts and records the changes of each
+func (r *HistoryUserRepository) trackMany(ctx context.Context, opts *ports.GetUsersOptions, write func() error) error {
+	var (
+		ids    []string
+		before = map[string]*domain.User{}
+	)
+	if err := r.UserRepository.EachUser(ctx, opts, func(user *domain.User) error {
+		ids = append(ids, user.ID)
+		before[user.ID] = user
+		return nil
+	}); err != nil {
+		return err
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	after, err := r.UserRepository.GetUsersByIDs(ctx, ids)
+	if err != nil {
+		log.Printf("Error reading the updated users for their change history: %v", err)
+		return nil
+	}
+	for _, user := range after {
+		r.record(ctx, before[user.ID], user)
+	}
+	return nil
+}
+
+// record appends the changes from before to after, if any, as a version of the user by the actor of
+// ctx
+func (r *HistoryUserRepository) record(ctx context.Context, before, after *domain.User) {
+	changes := domain.DiffUsers(before, after)
+	if len(changes) == 0 {
+		return
+	}
+	if err := r.history.AppendVersion(ctx, domain.NewUserVersion(after, domain.ActorFromContext(ctx).ID, changes)); err != nil {
+		log.Printf("Error recording the change history of user %s: %v", after.ID, err)
+	}
+}
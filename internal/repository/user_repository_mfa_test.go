@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/repository/repositorytest"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestMFAFactorUpdates(t *testing.T) {
+	at := time.Now()
+	tests := []struct {
+		name   string
+		update func(ctx context.Context, users *UserRepository) (bool, error)
+		// wantFactor is the factor the filter and the array filter match, with its conditions as
+		// extended JSON
+		wantFactor map[string]string
+		// wantOperator is an operator of the update
+		wantOperator string
+	}{
+		{
+			name: "challenge attempt",
+			update: func(ctx context.Context, users *UserRepository) (bool, error) {
+				return users.AddMFAChallengeAttempt(ctx, "u1", "f1")
+			},
+			wantFactor:   map[string]string{"id": `"f1"`, "challenge.attempts": `{"$lt": {"$numberInt":"5"}}`},
+			wantOperator: "$inc",
+		},
+		{
+			name: "challenge consumed",
+			update: func(ctx context.Context, users *UserRepository) (bool, error) {
+				return users.ConsumeMFAChallenge(ctx, "u1", "f1", "hash", at)
+			},
+			wantFactor:   map[string]string{"id": `"f1"`, "challenge.code_hash": `"hash"`},
+			wantOperator: "$unset",
+		},
+		{
+			name: "totp step",
+			update: func(ctx context.Context, users *UserRepository) (bool, error) {
+				return users.UseMFAStep(ctx, "u1", "f1", 42, at)
+			},
+			wantFactor:   map[string]string{"id": `"f1"`, "last_used_step": `{"$not": {"$gte": {"$numberLong":"42"}}}`},
+			wantOperator: "$set",
+		},
+	}
+	for _, tt := range tests {
+		for _, matched := range []int32{0, 1} {
+			t.Run(fmt.Sprintf("%s matching %d", tt.name, matched), func(t *testing.T) {
+				server := repositorytest.NewServer(t)
+				server.Reply = func(cmd repositorytest.Command) bson.D {
+					return bson.D{{Key: "n", Value: matched}, {Key: "nModified", Value: matched}}
+				}
+				users := NewUserRepository(NewTenantDatabases(server.Client(t).Database("app"), nil), "users")
+
+				updated, err := tt.update(domain.WithTenant(context.Background(), "acme"), users)
+				if err != nil {
+					t.Fatalf("update error = %v", err)
+				}
+				if updated != (matched == 1) {
+					t.Errorf("update = %v, want %v", updated, matched == 1)
+				}
+
+				statement := server.Commands()[0].Body.Lookup("updates", "0").Document()
+				factor, err := statement.Lookup("q", "mfa_factors", "$elemMatch").Document().Elements()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got := map[string]string{}
+				for _, element := range factor {
+					got[element.Key()] = element.Value().String()
+				}
+				if !reflect.DeepEqual(got, tt.wantFactor) {
+					t.Errorf("filter of the factor = %v, want %v", got, tt.wantFactor)
+				}
+				if tenantID := statement.Lookup("q", "tenant_id").StringValue(); tenantID != "acme" {
+					t.Errorf("filter tenant = %s, want acme", tenantID)
+				}
+				arrayFilter, err := statement.Lookup("arrayFilters", "0").Document().Elements()
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, element := range arrayFilter {
+					if field, ok := strings.CutPrefix(element.Key(), "f."); !ok || got[field] != element.Value().String() {
+						t.Errorf("array filter %s, want the filter of the factor %v", element, tt.wantFactor)
+					}
+				}
+				if len(arrayFilter) != len(factor) {
+					t.Errorf("array filter = %s, want the filter of the factor %v", arrayFilter, tt.wantFactor)
+				}
+				if _, err := statement.Lookup("u").Document().LookupErr(tt.wantOperator); err != nil {
+					t.Errorf("update = %s, want a %s", statement.Lookup("u"), tt.wantOperator)
+				}
+			})
+		}
+	}
+}
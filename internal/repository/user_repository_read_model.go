@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"sync/atomic"
+
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.UserRepository = (*ReadModelUserRepository)(nil)
+
+// ReadModelUserRepository serves the user lists from the read model, whose flat and indexed fields
+// do not depend on the shape of the user documents, and fetches the users of the page from the
+// wrapped repository. Lists are served by the wrapped repository until the read model was built by
+// a first sync, when they have a structured or distance filter, a field selection or explain, and
+// while the read model fails.
+type ReadModelUserRepository struct {
+	ports.UserRepository
+	readModel ports.UserReadModel
+	changes   ports.UserChangeStream
+
+	built atomic.Bool
+}
+
+// NewReadModelUserRepository returns users serving the lists from readModel once the sync of changes
+// built it
+func NewReadModelUserRepository(users ports.UserRepository, readModel ports.UserReadModel, changes ports.UserChangeStream) *ReadModelUserRepository {
+	return &ReadModelUserRepository{
+		UserRepository: users,
+		readModel:      readModel,
+		changes:        changes,
+	}
+}
+
+func (r *ReadModelUserRepository) GetUsers(ctx context.Context, opts *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+	if opts == nil {
+		opts = &ports.GetUsersOptions{}
+	}
+	if opts.Filter != nil || opts.Near != nil || len(opts.Fields) > 0 || opts.Explain || !r.isBuilt(ctx) {
+		return r.UserRepository.GetUsers(ctx, opts)
+	}
+
+	page, pageSize := pageDefaults(opts)
+	listed := *opts
+	listed.Page, listed.PageSize = page, pageSize
+	ids, err := r.readModel.ListUsers(ctx, &listed)
+	if err != nil {
+		log.Printf("Error listing the users from the read model, listing the database: %v", err)
+		return r.UserRepository.GetUsers(ctx, opts)
+	}
+	return usersOfPage(ctx, r.UserRepository, ids, page, pageSize)
+}
+
+// isBuilt reports whether a first sync built the read model, remembering it once it was
+func (r *ReadModelUserRepository) isBuilt(ctx context.Context) bool {
+	if r.built.Load() {
+		return true
+	}
+	synced, err := r.changes.Synced(ctx)
+	if err != nil {
+		log.Printf("Error checking the sync of the read model, listing the database: %v", err)
+		return false
+	}
+	if synced {
+		r.built.Store(true)
+	}
+	return synced
+}
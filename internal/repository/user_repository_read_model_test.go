@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+)
+
+func TestReadModelUserRepositoryGetUsers(t *testing.T) {
+	fromDatabase := &ports.GetUsersResult{Users: []*domain.User{{ID: "db"}}}
+	tests := []struct {
+		name      string
+		opts      *ports.GetUsersOptions
+		synced    bool
+		syncedErr error
+		listErr   error
+		wantIDs   []string
+		wantList  *ports.GetUsersOptions
+	}{
+		{
+			name:    "read model not built",
+			opts:    &ports.GetUsersOptions{},
+			wantIDs: []string{"db"},
+		},
+		{
+			name:      "sync check failure",
+			opts:      &ports.GetUsersOptions{},
+			syncedErr: errors.New("connection refused"),
+			wantIDs:   []string{"db"},
+		},
+		{
+			name:    "structured filter",
+			opts:    &ports.GetUsersOptions{Filter: &domain.SearchFilter{}},
+			synced:  true,
+			wantIDs: []string{"db"},
+		},
+		{
+			name:    "distance filter",
+			opts:    &ports.GetUsersOptions{Near: &domain.GeoPoint{}},
+			synced:  true,
+			wantIDs: []string{"db"},
+		},
+		{
+			name:    "field selection",
+			opts:    &ports.GetUsersOptions{Fields: []string{"email"}},
+			synced:  true,
+			wantIDs: []string{"db"},
+		},
+		{
+			name:    "explain",
+			opts:    &ports.GetUsersOptions{Explain: true},
+			synced:  true,
+			wantIDs: []string{"db"},
+		},
+		{
+			name:     "read model failure",
+			opts:     &ports.GetUsersOptions{},
+			synced:   true,
+			listErr:  errors.New("timeout"),
+			wantIDs:  []string{"db"},
+			wantList: &ports.GetUsersOptions{Page: 1, PageSize: 10},
+		},
+		{
+			name:     "from the read model in its order",
+			opts:     &ports.GetUsersOptions{Search: "john", Page: 2, PageSize: 500},
+			synced:   true,
+			wantIDs:  []string{"u3", "u1"},
+			wantList: &ports.GetUsersOptions{Search: "john", Page: 2, PageSize: 10},
+		},
+		{
+			name:     "no options",
+			synced:   true,
+			wantIDs:  []string{"u3", "u1"},
+			wantList: &ports.GetUsersOptions{Page: 1, PageSize: 10},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var listed *ports.GetUsersOptions
+			users := &mocks.UserRepository{
+				GetUsersFunc: func(context.Context, *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+					return fromDatabase, nil
+				},
+				// u2 was deleted since the read model was read
+				GetUsersByIDsFunc: func(_ context.Context, ids []string) ([]*domain.User, error) {
+					return []*domain.User{{ID: "u1"}, {ID: "u3"}}, nil
+				},
+			}
+			readModel := &mocks.UserReadModel{
+				ListUsersFunc: func(_ context.Context, opts *ports.GetUsersOptions) (*ports.UserSearchResult, error) {
+					listed = opts
+					return &ports.UserSearchResult{IDs: []string{"u3", "u2", "u1"}, TotalCount: 3}, tt.listErr
+				},
+			}
+			changes := &mocks.UserChangeStream{
+				SyncedFunc: func(context.Context) (bool, error) { return tt.synced, tt.syncedErr },
+			}
+
+			got, err := NewReadModelUserRepository(users, readModel, changes).GetUsers(context.Background(), tt.opts)
+			if err != nil {
+				t.Fatalf("GetUsers() error = %v", err)
+			}
+			var ids []string
+			for _, user := range got.Users {
+				ids = append(ids, user.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("GetUsers() IDs = %v, want %v", ids, tt.wantIDs)
+			}
+			if !reflect.DeepEqual(listed, tt.wantList) {
+				t.Errorf("ListUsers() opts = %+v, want %+v", listed, tt.wantList)
+			}
+		})
+	}
+}
+
+func TestReadModelUserRepositoryRemembersBuilt(t *testing.T) {
+	checks := 0
+	users := &mocks.UserRepository{}
+	readModel := &mocks.UserReadModel{
+		ListUsersFunc: func(context.Context, *ports.GetUsersOptions) (*ports.UserSearchResult, error) {
+			return &ports.UserSearchResult{}, nil
+		},
+	}
+	changes := &mocks.UserChangeStream{
+		SyncedFunc: func(context.Context) (bool, error) {
+			checks++
+			return true, nil
+		},
+	}
+
+	repo := NewReadModelUserRepository(users, readModel, changes)
+	for range 3 {
+		if _, err := repo.GetUsers(context.Background(), nil); err != nil {
+			t.Fatalf("GetUsers() error = %v", err)
+		}
+	}
+	if checks != 1 {
+		t.Errorf("Synced() calls = %d, want 1", checks)
+	}
+}
+
+func TestUsersOfPage(t *testing.T) {
+	users := &mocks.UserRepository{
+		GetUsersByIDsFunc: func(context.Context, []string) ([]*domain.User, error) {
+			return []*domain.User{{ID: "b"}, {ID: "a"}}, nil
+		},
+	}
+	hits := &ports.UserSearchResult{IDs: []string{"a", "b"}, TotalCount: 21, Facets: map[string][]ports.FacetCount{"status": {{Value: "active", Count: 21}}}}
+
+	got, err := usersOfPage(context.Background(), users, hits, 3, 10)
+	if err != nil {
+		t.Fatalf("usersOfPage() error = %v", err)
+	}
+	want := &ports.GetUsersResult{
+		Users:      []*domain.User{{ID: "a"}, {ID: "b"}},
+		TotalCount: 21,
+		Page:       3,
+		PageSize:   10,
+		TotalPages: 3,
+		Facets:     hits.Facets,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("usersOfPage() = %+v, want %+v", got, want)
+	}
+}
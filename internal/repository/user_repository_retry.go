@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync/atomic"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Compile-time interface checks
+var (
+	_ ports.UserRepository = (*RetryingUserRepository)(nil)
+	_ ports.RetryReporter  = (*RetryingUserRepository)(nil)
+)
+
+// transientErrorCodes are the server errors of elections, stepdowns and shutdowns, which the
+// driver also treats as retryable
+var transientErrorCodes = []int{11600, 11602, 10107, 13435, 13436, 189, 91, 7, 6, 89, 9001, 262}
+
+// RetryingUserRepository retries the reads of a user repository failing with transient database
+// errors, with exponential backoff and full jitter. Writes are passed through untouched, as they
+// are not all idempotent. This comes on top of the single immediate retry of the driver.
+type RetryingUserRepository struct {
+	ports.UserRepository
+	policy ports.RetryPolicy
+
+	retries   atomic.Int64
+	recovered atomic.Int64
+	exhausted atomic.Int64
+}
+
+func NewRetryingUserRepository(users ports.UserRepository, policy ports.RetryPolicy) *RetryingUserRepository {
+	return &RetryingUserRepository{
+		UserRepository: users,
+		policy:         policy,
+	}
+}
+
+func (r *RetryingUserRepository) RetryStats() ports.RetryStats {
+	return ports.RetryStats{
+		Retries:   r.retries.Load(),
+		Recovered: r.recovered.Load(),
+		Exhausted: r.exhausted.Load(),
+	}
+}
+
+// retry runs read until it succeeds, fails with a permanent error, runs out of attempts or the
+// context ends
+func (r *RetryingUserRepository) retry(ctx context.Context, read func() error) error {
+	err := read()
+	for attempt := 1; err != nil && isTransientError(err) && ctx.Err() == nil; attempt++ {
+		if attempt >= r.policy.MaxAttempts {
+			r.exhausted.Add(1)
+			return err
+		}
+
+		timer := time.NewTimer(r.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		r.retries.Add(1)
+		if err = read(); err == nil {
+			r.recovered.Add(1)
+		}
+	}
+	return err
+}
+
+// backoff returns a random delay up to BaseDelay doubled for each attempt already made, capped at MaxDelay
+func (r *RetryingUserRepository) backoff(attempt int) time.Duration {
+	ceiling := r.policy.BaseDelay << (attempt - 1)
+	if ceiling <= 0 || ceiling > r.policy.MaxDelay {
+		ceiling = r.policy.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
+
+// isTransientError reports whether err comes from a lost connection or a replica set state change,
+// such as a primary stepping down, after which the same read is expected to succeed
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if mongo.IsNetworkError(err) {
+		return true
+	}
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) {
+		for _, code := range transientErrorCodes {
+			if serverErr.HasErrorCode(code) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *RetryingUserRepository) GetUserByID(ctx context.Context, id string) (user *domain.User, err error) {
+	err = r.retry(ctx, func() error {
+		user, err = r.UserRepository.GetUserByID(ctx, id)
+		return err
+	})
+	return user, err
+}
+
+func (r *RetryingUserRepository) GetUserByEmail(ctx context.Context, email string) (user *domain.User, err error) {
+	err = r.retry(ctx, func() error {
+		user, err = r.UserRepository.GetUserByEmail(ctx, email)
+		return err
+	})
+	return user, err
+}
+
+func (r *RetryingUserRepository) GetUserByNormalizedEmail(ctx context.Context, normalizedEmail string) (user *domain.User, err error) {
+	err = r.retry(ctx, func() error {
+		user, err = r.UserRepository.GetUserByNormalizedEmail(ctx, normalizedEmail)
+		return err
+	})
+	return user, err
+}
+
+func (r *RetryingUserRepository) GetUserByUsername(ctx context.Context, username string) (user *domain.User, err error) {
+	err = r.retry(ctx, func() error {
+		user, err = r.UserRepository.GetUserByUsername(ctx, username)
+		return err
+	})
+	return user, err
+}
+
+func (r *RetryingUserRepository) GetUserByExternalIdentity(ctx context.Context, issuer, subject string) (user *domain.User, err error) {
+	err = r.retry(ctx, func() error {
+		user, err = r.UserRepository.GetUserByExternalIdentity(ctx, issuer, subject)
+		return err
+	})
+	return user, err
+}
+
+func (r *RetryingUserRepository) GetUsersByIDs(ctx context.Context, ids []string) (users []*domain.User, err error) {
+	err = r.retry(ctx, func() error {
+		users, err = r.UserRepository.GetUsersByIDs(ctx, ids)
+		return err
+	})
+	return users, err
+}
+
+func (r *RetryingUserRepository) AutocompleteUsers(ctx context.Context, prefix string, limit int) (users []*domain.User, err error) {
+	err = r.retry(ctx, func() error {
+		users, err = r.UserRepository.AutocompleteUsers(ctx, prefix, limit)
+		return err
+	})
+	return users, err
+}
+
+func (r *RetryingUserRepository) GetTokenGeneration(ctx context.Context, id string) (generation int, err error) {
+	err = r.retry(ctx, func() error {
+		generation, err = r.UserRepository.GetTokenGeneration(ctx, id)
+		return err
+	})
+	return generation, err
+}
+
+func (r *RetryingUserRepository) UserExists(ctx context.Context, id string) (exists bool, err error) {
+	err = r.retry(ctx, func() error {
+		exists, err = r.UserRepository.UserExists(ctx, id)
+		return err
+	})
+	return exists, err
+}
+
+func (r *RetryingUserRepository) GetUsers(ctx context.Context, opts *ports.GetUsersOptions) (result *ports.GetUsersResult, err error) {
+	err = r.retry(ctx, func() error {
+		result, err = r.UserRepository.GetUsers(ctx, opts)
+		return err
+	})
+	return result, err
+}
+
+func (r *RetryingUserRepository) CountUsers(ctx context.Context, opts *ports.GetUsersOptions) (count int64, err error) {
+	err = r.retry(ctx, func() error {
+		count, err = r.UserRepository.CountUsers(ctx, opts)
+		return err
+	})
+	return count, err
+}
+
+func (r *RetryingUserRepository) DuplicateGroups(ctx context.Context, reason string) (groups [][]*domain.User, err error) {
+	err = r.retry(ctx, func() error {
+		groups, err = r.UserRepository.DuplicateGroups(ctx, reason)
+		return err
+	})
+	return groups, err
+}
+
+// EachUser is only retried while no user was passed to fn, so no user is seen twice
+func (r *RetryingUserRepository) EachUser(ctx context.Context, opts *ports.GetUsersOptions, fn func(*domain.User) error) error {
+	started := false
+	var err error
+	retryErr := r.retry(ctx, func() error {
+		err = r.UserRepository.EachUser(ctx, opts, func(user *domain.User) error {
+			started = true
+			return fn(user)
+		})
+		if started {
+			return nil // The read went through, later errors are returned as is
+		}
+		return err
+	})
+	if started {
+		return err
+	}
+	return retryErr
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "network error", err: errNetwork, want: true},
+		{name: "wrapped network error", err: fmt.Errorf("finding user: %w", errNetwork), want: true},
+		{name: "primary stepped down", err: errStepdown, want: true},
+		{name: "not primary", err: mongo.CommandError{Code: 10107}, want: true},
+		{name: "shutting down", err: mongo.CommandError{Code: 91}, want: true},
+		{name: "write concern of a stepdown", err: mongo.WriteException{WriteConcernError: &mongo.WriteConcernError{Code: 11602}}, want: true},
+		{name: "duplicate key", err: mongo.WriteException{WriteErrors: []mongo.WriteError{{Code: 11000}}}},
+		{name: "unauthorized", err: mongo.CommandError{Code: 13, Name: "Unauthorized"}},
+		{name: "no document", err: mongo.ErrNoDocuments},
+		{name: "request cancelled", err: context.Canceled},
+		{name: "network error past the deadline", err: mongo.CommandError{Labels: []string{"NetworkError"}, Wrapped: context.DeadlineExceeded}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientError(tt.err); got != tt.want {
+				t.Errorf("isTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	errPermanent := errors.New("invalid filter")
+	tests := []struct {
+		name      string
+		errs      []error // Errors of the successive reads, the last one repeated
+		wantCalls int
+		wantErr   error
+		wantStats ports.RetryStats
+	}{
+		{name: "success", errs: []error{nil}, wantCalls: 1},
+		{name: "recovered", errs: []error{errNetwork, errStepdown, nil}, wantCalls: 3, wantStats: ports.RetryStats{Retries: 2, Recovered: 1}},
+		{name: "exhausted", errs: []error{errStepdown}, wantCalls: 3, wantErr: errStepdown, wantStats: ports.RetryStats{Retries: 2, Exhausted: 1}},
+		{name: "permanent error", errs: []error{errPermanent}, wantCalls: 1, wantErr: errPermanent},
+		{name: "permanent error after a retry", errs: []error{errNetwork, errPermanent}, wantCalls: 2, wantErr: errPermanent, wantStats: ports.RetryStats{Retries: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			users := &mocks.UserRepository{
+				GetUserByIDFunc: func(context.Context, string) (*domain.User, error) {
+					err := tt.errs[min(calls, len(tt.errs)-1)]
+					calls++
+					if err != nil {
+						return nil, err
+					}
+					return &domain.User{ID: "u1"}, nil
+				},
+			}
+			retrying := NewRetryingUserRepository(users, ports.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond})
+
+			user, err := retrying.GetUserByID(context.Background(), "u1")
+			if !reflect.DeepEqual(err, tt.wantErr) {
+				t.Errorf("GetUserByID() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (user == nil || user.ID != "u1") {
+				t.Errorf("GetUserByID() = %v, want the user u1", user)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("reads = %d, want %d", calls, tt.wantCalls)
+			}
+			if stats := retrying.RetryStats(); stats != tt.wantStats {
+				t.Errorf("RetryStats() = %+v, want %+v", stats, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestRetryContextEnded(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	waiting, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{name: "ended before the read", ctx: cancelled},
+		{name: "ended during the backoff", ctx: waiting},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			users := &mocks.UserRepository{
+				GetUserByIDFunc: func(context.Context, string) (*domain.User, error) {
+					calls++
+					return nil, errNetwork
+				},
+			}
+			retrying := NewRetryingUserRepository(users, ports.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour})
+
+			if _, err := retrying.GetUserByID(tt.ctx, "u1"); !reflect.DeepEqual(err, errNetwork) {
+				t.Errorf("GetUserByID() error = %v, want %v", err, errNetwork)
+			}
+			if calls != 1 {
+				t.Errorf("reads = %d, want 1", calls)
+			}
+			if stats := retrying.RetryStats(); stats != (ports.RetryStats{}) {
+				t.Errorf("RetryStats() = %+v, want no retry", stats)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  ports.RetryPolicy
+		attempt int
+		want    time.Duration // Ceiling of the delay
+	}{
+		{name: "first attempt", policy: ports.RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}, attempt: 1, want: 100 * time.Millisecond},
+		{name: "doubled", policy: ports.RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}, attempt: 3, want: 400 * time.Millisecond},
+		{name: "capped", policy: ports.RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}, attempt: 5, want: time.Second},
+		{name: "overflow capped", policy: ports.RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}, attempt: 64, want: time.Second},
+		{name: "no delay", policy: ports.RetryPolicy{}, attempt: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRetryingUserRepository(&mocks.UserRepository{}, tt.policy)
+			for range 100 {
+				got := r.backoff(tt.attempt)
+				if got > tt.want || (tt.want > 0 && got <= 0) {
+					t.Fatalf("backoff(%d) = %v, want in (0, %v]", tt.attempt, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+)
+
+// Compile-time interface check
+var _ ports.UserRepository = (*SearchIndexedUserRepository)(nil)
+
+// SearchIndexedUserRepository serves the full-text searches of the user lists from a search index
+// and fetches the users of the page from the wrapped repository, so lists hold the users as stored.
+// Lists with filters the index does not hold, and every list while the index fails, are served by
+// the wrapped repository.
+type SearchIndexedUserRepository struct {
+	ports.UserRepository
+	index ports.SearchIndex
+}
+
+func NewSearchIndexedUserRepository(users ports.UserRepository, index ports.SearchIndex) *SearchIndexedUserRepository {
+	return &SearchIndexedUserRepository{
+		UserRepository: users,
+		index:          index,
+	}
+}
+
+func (r *SearchIndexedUserRepository) GetUsers(ctx context.Context, opts *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+	if !indexedSearch(opts) {
+		return r.UserRepository.GetUsers(ctx, opts)
+	}
+	page, pageSize := pageDefaults(opts)
+	hits, err := r.index.SearchUsers(ctx, ports.UserSearchQuery{
+		Text:     opts.Search,
+		Tags:     opts.Tags,
+		SortBy:   opts.SortBy,
+		Order:    opts.Order,
+		Page:     page,
+		PageSize: pageSize,
+		Facets:   opts.Facets,
+	})
+	if err != nil {
+		log.Printf("Error searching the users in the search index, searching the database: %v", err)
+		return r.UserRepository.GetUsers(ctx, opts)
+	}
+	return usersOfPage(ctx, r.UserRepository, hits, page, pageSize)
+}
+
+// pageDefaults returns the page and page size of opts with the defaults of GetUsers
+func pageDefaults(opts *ports.GetUsersOptions) (int, int) {
+	page, pageSize := opts.Page, opts.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+	return page, pageSize
+}
+
+// usersOfPage reads the users of a page of IDs from users, in the order of the page
+func usersOfPage(ctx context.Context, users ports.UserRepository, hits *ports.UserSearchResult, page, pageSize int) (*ports.GetUsersResult, error) {
+	found, err := users.GetUsersByIDs(ctx, hits.IDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*domain.User, len(found))
+	for _, user := range found {
+		byID[user.ID] = user
+	}
+	// Users deleted since the page was read are left out of it
+	ordered := make([]*domain.User, 0, len(hits.IDs))
+	for _, id := range hits.IDs {
+		if user, ok := byID[id]; ok {
+			ordered = append(ordered, user)
+		}
+	}
+
+	return &ports.GetUsersResult{
+		Users:      ordered,
+		TotalCount: hits.TotalCount,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: int(hits.TotalCount+int64(pageSize)-1) / pageSize,
+		Facets:     hits.Facets,
+	}, nil
+}
+
+// indexedSearch reports whether the index serves the list: a full-text search, optionally filtered
+// by tags and counting facets, of whole users
+func indexedSearch(opts *ports.GetUsersOptions) bool {
+	return opts != nil && opts.Search != "" &&
+		len(opts.Fields) == 0 && len(opts.Metadata) == 0 && opts.Filter == nil && len(opts.IDs) == 0 &&
+		opts.InactiveSince == nil && !opts.DirectoryLinked && !opts.Explain && opts.Near == nil
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// searchOperators maps comparison operators to their MongoDB equivalent
+var searchOperators = map[string]string{
+	domain.SearchOpNe:     "$ne",
+	domain.SearchOpIn:     "$in",
+	domain.SearchOpGt:     "$gt",
+	domain.SearchOpGte:    "$gte",
+	domain.SearchOpLt:     "$lt",
+	domain.SearchOpLte:    "$lte",
+	domain.SearchOpExists: "$exists",
+}
+
+// searchFilterToBSON translates a validated search filter into a MongoDB filter.
+// Fields come from a whitelist and user values never become operators, text is regex-escaped.
+func searchFilterToBSON(f *domain.SearchFilter) (bson.M, error) {
+	if f.IsGroup() {
+		operator, children := "$and", f.And
+		if len(f.Or) > 0 {
+			operator, children = "$or", f.Or
+		}
+		clauses := make([]bson.M, 0, len(children))
+		for i := range children {
+			clause, err := searchFilterToBSON(&children[i])
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, clause)
+		}
+		return bson.M{operator: clauses}, nil
+	}
+
+	fieldType, ok := domain.SearchField(f.Field)
+	if !ok {
+		return nil, fmt.Errorf("%w: field %q cannot be searched", domain.ErrInvalidSearch, f.Field)
+	}
+
+	switch f.Op {
+	case domain.SearchOpEq:
+		value, err := searchValue(fieldType, f.Value)
+		if err != nil {
+			return nil, err
+		}
+		return bson.M{f.Field: value}, nil
+	case domain.SearchOpContains, domain.SearchOpPrefix:
+		pattern := regexp.QuoteMeta(f.Value.(string))
+		if f.Op == domain.SearchOpPrefix {
+			pattern = "^" + pattern
+		}
+		return bson.M{f.Field: bson.M{"$regex": pattern, "$options": "i"}}, nil
+	case domain.SearchOpExists:
+		return bson.M{f.Field: bson.M{"$exists": f.Value}}, nil
+	case domain.SearchOpIn:
+		values := f.Value.([]any)
+		converted := make([]any, 0, len(values))
+		for _, v := range values {
+			value, err := searchValue(fieldType, v)
+			if err != nil {
+				return nil, err
+			}
+			converted = append(converted, value)
+		}
+		return bson.M{f.Field: bson.M{"$in": converted}}, nil
+	}
+
+	operator, ok := searchOperators[f.Op]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown operator %q", domain.ErrInvalidSearch, f.Op)
+	}
+	value, err := searchValue(fieldType, f.Value)
+	if err != nil {
+		return nil, err
+	}
+	return bson.M{f.Field: bson.M{operator: value}}, nil
+}
+
+// searchValue converts a JSON value into the type stored for the field
+func searchValue(fieldType domain.SearchFieldType, value any) (any, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: expected a string value", domain.ErrInvalidSearch)
+	}
+	if fieldType == domain.SearchFieldTime {
+		t, err := domain.ParseSearchTime(s)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSearch, err)
+		}
+		return t, nil
+	}
+	return s, nil
+}
@@ -0,0 +1,205 @@
+// Package cron parses cron expressions and computes the times they fire at.
+//
+// Expressions have the five standard fields, minute, hour, day of month, month and day of week,
+// each a *, a value, a range (1-5), a list (1,15) or a step (*/10, 0-30/5). Months and days of
+// week may be given by their first three letters, and Sunday is 0 or 7. As in cron, a day matches
+// when both day fields do, or either of them when neither starts with *, so a */2 day field narrows
+// the other one down. The descriptors @yearly (@annually), @monthly, @weekly, @daily (@midnight)
+// and @hourly are accepted, and @every <duration> fires at the multiples of the duration since the
+// zero time, the same times on every machine.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidExpression is wrapped by the errors of Parse
+var ErrInvalidExpression = errors.New("invalid cron expression")
+
+// Schedule is a parsed cron expression
+type Schedule struct {
+	expr string
+	// every is the interval of @every schedules, zero for field schedules
+	every time.Duration
+
+	minute, hour, dom, month, dow uint64
+	// domAny and dowAny are set when the day fields start with *, see the package documentation
+	domAny, dowAny bool
+}
+
+type field struct {
+	name     string
+	min, max int
+	names    []string
+}
+
+var (
+	minuteField = field{name: "minute", min: 0, max: 59}
+	hourField   = field{name: "hour", min: 0, max: 23}
+	domField    = field{name: "day of month", min: 1, max: 31}
+	monthField  = field{name: "month", min: 1, max: 12,
+		names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	// Day 7 is Sunday too, folded into 0 after parsing
+	dowField = field{name: "day of week", min: 0, max: 7,
+		names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if interval, ok := strings.CutPrefix(expr, "@every "); ok {
+		every, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil || every < time.Second {
+			return nil, fmt.Errorf("%w %q: @every needs a duration of at least 1s", ErrInvalidExpression, expr)
+		}
+		return &Schedule{expr: expr, every: every}, nil
+	}
+
+	fields := strings.Fields(expr)
+	if descriptor, ok := descriptors[strings.ToLower(expr)]; ok {
+		fields = strings.Fields(descriptor)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w %q: must have 5 fields, minute hour day-of-month month day-of-week", ErrInvalidExpression, expr)
+	}
+
+	s := &Schedule{expr: expr, domAny: strings.HasPrefix(fields[2], "*"), dowAny: strings.HasPrefix(fields[4], "*")}
+	var err error
+	for i, target := range []*uint64{&s.minute, &s.hour, &s.dom, &s.month, &s.dow} {
+		f := []field{minuteField, hourField, domField, monthField, dowField}[i]
+		if *target, err = f.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidExpression, expr, err)
+		}
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	if s.Next(time.Now()).IsZero() {
+		return nil, fmt.Errorf("%w %q: never fires", ErrInvalidExpression, expr)
+	}
+	return s, nil
+}
+
+// String returns the expression the schedule was parsed from
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first time after t the schedule fires at, in the location of t. Field schedules
+// fire on whole minutes.
+func (s *Schedule) Next(t time.Time) time.Time {
+	if s.every > 0 {
+		return t.Truncate(s.every).Add(s.every)
+	}
+
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	// Every combination of the fields repeats within 5 years, leap days included
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !s.has(s.month, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.has(s.hour, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.has(s.minute, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom, dow := s.has(s.dom, t.Day()), s.has(s.dow, int(t.Weekday()))
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+func (s *Schedule) has(set uint64, value int) bool {
+	return set&(1<<value) != 0
+}
+
+// parse returns the set of values of the field as a bit set
+func (f field) parse(text string) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return 0, fmt.Errorf("invalid %s step %q", f.name, stepText)
+			}
+			step = n
+		}
+
+		var low, high int
+		switch {
+		case rangeText == "*":
+			low, high = f.min, f.max
+		case strings.Contains(rangeText, "-"):
+			lowText, highText, _ := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = f.value(lowText); err != nil {
+				return 0, err
+			}
+			if high, err = f.value(highText); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("invalid %s range %q", f.name, rangeText)
+			}
+		default:
+			value, err := f.value(rangeText)
+			if err != nil {
+				return 0, err
+			}
+			// 5/15 runs from 5 to the end of the field, as in cron
+			low, high = value, value
+			if hasStep {
+				high = f.max
+			}
+		}
+		for v := low; v <= high; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+func (f field) value(text string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(text, name) {
+			return i + f.min, nil
+		}
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n < f.min || n > f.max {
+		return 0, fmt.Errorf("invalid %s %q: must be %d to %d", f.name, text, f.min, f.max)
+	}
+	return n, nil
+}
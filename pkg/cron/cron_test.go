@@ -0,0 +1,72 @@
+package cron
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	// Monday 15 January 2024
+	from := time.Date(2024, 1, 15, 10, 30, 20, 0, time.UTC)
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 15, 10, 31, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 3 * * *", time.Date(2024, 1, 16, 3, 0, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2024, 1, 16, 10, 30, 0, 0, time.UTC)},
+		{"5/20 9-17 * * *", time.Date(2024, 1, 15, 10, 45, 0, 0, time.UTC)},
+		{"0 0 1,15 * *", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * feb mon", time.Date(2024, 2, 5, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 * * sat,sun", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Either day field matches when neither starts with *
+		{"0 0 20 * fri", time.Date(2024, 1, 19, 0, 0, 0, 0, time.UTC)},
+		// A day field starting with * narrows the other one down, as in cron
+		{"0 0 */2 * mon", time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 13 * */3", time.Date(2024, 3, 13, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@every 1h", time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@every 45s", time.Date(2024, 1, 15, 10, 30, 45, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", from.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"0 0 30 2 *",
+		"@every 10ms",
+		"@fortnightly",
+	} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := Parse(expr); !errors.Is(err, ErrInvalidExpression) {
+				t.Errorf("Parse(%q) = %v, want ErrInvalidExpression", expr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Package errcode defines the codes of API error responses. Codes are stable, unlike error
+// messages, which are reworded and translated, so clients branch on them; a code is never
+// renamed or given another meaning once released.
+package errcode
+
+// Code identifies the cause of an error response
+type Code string
+
+// Codes of errors without a more specific code, by status
+const (
+	InvalidRequest      Code = "INVALID_REQUEST"      // 400
+	ValidationFailed    Code = "VALIDATION_FAILED"    // 400, the body or a field of the request is invalid
+	Unauthorized        Code = "UNAUTHORIZED"         // 401, missing, invalid or expired token
+	PermissionDenied    Code = "PERMISSION_DENIED"    // 403
+	NotFound            Code = "NOT_FOUND"            // 404
+	Conflict            Code = "CONFLICT"             // 409
+	PayloadTooLarge     Code = "PAYLOAD_TOO_LARGE"    // 413
+	RateLimited         Code = "RATE_LIMITED"         // 429
+	Internal            Code = "INTERNAL_ERROR"       // 500
+	DatabaseUnavailable Code = "DATABASE_UNAVAILABLE" // 503, retry after the Retry-After delay
+	RequestTimeout      Code = "REQUEST_TIMEOUT"      // 503, the request exceeded its deadline
+)
+
+// Tenant and authentication codes
+const (
+	TenantRequired          Code = "TENANT_REQUIRED"
+	TenantInvalid           Code = "TENANT_INVALID"
+	InvalidCredentials      Code = "AUTH_INVALID_CREDENTIALS"
+	PasswordResetRequired   Code = "AUTH_PASSWORD_RESET_REQUIRED"
+	AccountDeactivated      Code = "AUTH_ACCOUNT_DEACTIVATED"
+	TooManyLoginAttempts    Code = "AUTH_TOO_MANY_ATTEMPTS"
+	LoginReportInvalid      Code = "AUTH_LOGIN_REPORT_INVALID"
+	ImpersonationNotAllowed Code = "AUTH_IMPERSONATION_NOT_ALLOWED"
+	ExternalTokenNoEmail    Code = "AUTH_TOKEN_NO_EMAIL"
+	IdentityConflict        Code = "AUTH_IDENTITY_CONFLICT"
+	IdentityProviderDown    Code = "AUTH_PROVIDER_UNAVAILABLE" // 503
+	ClientInvalid           Code = "AUTH_INVALID_CLIENT"
+	GrantTypeUnsupported    Code = "AUTH_UNSUPPORTED_GRANT_TYPE"
+	ScopeInvalid            Code = "AUTH_INVALID_SCOPE"
+	UserTokenRequired       Code = "AUTH_USER_TOKEN_REQUIRED" // 401, client tokens on routes acting for a user
+	ScopeInsufficient       Code = "AUTH_INSUFFICIENT_SCOPE"  // 403, the token scopes do not allow the route
+	MFATokenInvalid         Code = "AUTH_MFA_TOKEN_INVALID"   // 401, the MFA token of the login is invalid or expired
+	MFACodeInvalid          Code = "AUTH_MFA_CODE_INVALID"
+	TokenRevoked            Code = "AUTH_TOKEN_REVOKED"             // 401, the user logged out of all devices since the token was issued
+	ReauthenticationNeeded  Code = "AUTH_REAUTHENTICATION_REQUIRED" // 401, the route needs a password or second factor proven within RECENT_AUTH_MAX_AGE
+	SessionExpired          Code = "AUTH_SESSION_EXPIRED"           // 401, the session cookie is unknown, expired or logged out
+	CSRFTokenInvalid        Code = "AUTH_CSRF_TOKEN_INVALID"        // 403, an unsafe request of a cookie session without its CSRF token, or cross-site
+)
+
+// User codes
+const (
+	UserNotFound           Code = "USER_NOT_FOUND"
+	UserEmailInvalid       Code = "USER_EMAIL_INVALID"
+	UserEmailTaken         Code = "USER_EMAIL_TAKEN"
+	UsernameInvalid        Code = "USER_USERNAME_INVALID"
+	UsernameTaken          Code = "USER_USERNAME_TAKEN"
+	PasswordTooWeak        Code = "USER_PASSWORD_TOO_WEAK"
+	PasswordBreached       Code = "USER_PASSWORD_BREACHED" // 400, the password is in known data breaches and PASSWORD_BREACH_CHECK=reject
+	UserSearchInvalid      Code = "USER_SEARCH_INVALID"
+	UserMetadataInvalid    Code = "USER_METADATA_INVALID"
+	UserCustomFieldInvalid Code = "USER_CUSTOM_FIELD_INVALID" // 400, a custom registration field is missing, unknown or invalid
+	UserTagInvalid         Code = "USER_TAG_INVALID"
+	UserMergeSameUser      Code = "USER_MERGE_SAME_USER"
+	UserVersionNotFound    Code = "USER_VERSION_NOT_FOUND"
+	UserVersionInvalid     Code = "USER_VERSION_INVALID"   // 400, the version is not a positive number
+	BirthdateInvalid       Code = "USER_BIRTHDATE_INVALID" // 400, malformed, future, or missing while a minimum age applies
+	UserUnderMinimumAge    Code = "USER_UNDER_MINIMUM_AGE" // 400, the birthdate is younger than MINIMUM_AGE
+	AddressNotFound        Code = "ADDRESS_NOT_FOUND"
+	AddressLimitReached    Code = "ADDRESS_LIMIT_REACHED"
+	AddressNotLocated      Code = "ADDRESS_NOT_LOCATED"
+	ExportFormatInvalid    Code = "EXPORT_FORMAT_INVALID"
+	UserBatchInvalid       Code = "USER_BATCH_UPDATE_INVALID"     // 400, no users selected, no change, or tags both added and removed
+	UserUpsertConflict     Code = "USER_UPSERT_CONFLICT"          // 409, on_conflict=reject and the user already has other values
+	EmailDomainNotAllowed  Code = "USER_EMAIL_DOMAIN_NOT_ALLOWED" // 403, the email domain is denied, or not allowed while some are
+)
+
+// Relationship codes
+const (
+	RelationshipNotFound      Code = "RELATIONSHIP_NOT_FOUND"
+	RelationshipSelf          Code = "RELATIONSHIP_SELF"            // 400, users cannot follow themselves
+	RelationshipUserBlocked   Code = "RELATIONSHIP_USER_BLOCKED"    // 400, users cannot follow the users they block
+	RelationshipBlockedByUser Code = "RELATIONSHIP_BLOCKED_BY_USER" // 403, users cannot follow the users blocking them
+	BlockNotFound             Code = "BLOCK_NOT_FOUND"
+	BlockSelf                 Code = "BLOCK_SELF" // 400, users cannot block themselves
+)
+
+// Identity document codes
+const (
+	DocumentNotFound      Code = "DOCUMENT_NOT_FOUND"
+	DocumentTypeInvalid   Code = "DOCUMENT_TYPE_INVALID"
+	DocumentFileInvalid   Code = "DOCUMENT_FILE_INVALID"   // 400, not a PDF, JPEG or PNG file
+	DocumentExpiryInvalid Code = "DOCUMENT_EXPIRY_INVALID" // 400, not a future YYYY-MM-DD date
+)
+
+// Identity verification codes
+const (
+	VerificationDocumentsInvalid Code = "VERIFICATION_DOCUMENTS_INVALID" // 400, documents missing, not pending or of another user
+	VerificationDecisionInvalid  Code = "VERIFICATION_DECISION_INVALID"
+	VerificationReasonInvalid    Code = "VERIFICATION_REASON_INVALID" // 400, rejections require a reason
+	VerificationConflict         Code = "VERIFICATION_CONFLICT"       // 409, the status does not allow the action
+	VerificationSelfReview       Code = "VERIFICATION_SELF_REVIEW"    // 403, staff cannot review their own verification
+	IdentityCheckNotFound        Code = "IDENTITY_CHECK_NOT_FOUND"
+	IdentityVerifierDisabled     Code = "IDENTITY_VERIFIER_DISABLED" // 404, IDENTITY_VERIFIER is not set
+	WebhookSignatureInvalid      Code = "WEBHOOK_SIGNATURE_INVALID"
+)
+
+// Phone verification codes
+const (
+	PhoneInvalid         Code = "PHONE_INVALID" // 400, the phone of the profile is not an international number
+	PhoneMissing         Code = "PHONE_MISSING"
+	PhoneAlreadyVerified Code = "PHONE_ALREADY_VERIFIED"
+	PhoneCodeInvalid     Code = "PHONE_CODE_INVALID"
+	PhoneCodeExpired     Code = "PHONE_CODE_EXPIRED"      // 400, no code pending, expired, or sent to a phone since replaced
+	PhoneCodeAttempts    Code = "PHONE_CODE_ATTEMPTS"     // 400, too many wrong codes, a new one must be requested
+	PhoneCodeTooFrequent Code = "PHONE_CODE_TOO_FREQUENT" // 429, a code was sent less than a minute ago
+	SMSRateLimited       Code = "PHONE_SMS_RATE_LIMITED"  // 429, too many text messages sent to the phone (RATE_LIMIT_SMS)
+)
+
+// Second factor codes
+const (
+	MFAFactorTypeInvalid  Code = "MFA_FACTOR_TYPE_INVALID"
+	MFAFactorLabelInvalid Code = "MFA_FACTOR_LABEL_INVALID"
+	MFAFactorLimit        Code = "MFA_FACTOR_LIMIT"
+	MFAPhoneUnverified    Code = "MFA_PHONE_UNVERIFIED" // 400, SMS factors text the verified phone of the profile
+	MFAFactorExists       Code = "MFA_FACTOR_EXISTS"
+	MFAFactorConfirmed    Code = "MFA_FACTOR_ALREADY_CONFIRMED"
+	MFAFactorNotFound     Code = "MFA_FACTOR_NOT_FOUND"
+	MFAFactorNotSMS       Code = "MFA_FACTOR_NOT_SMS"
+	TrustedDeviceNotFound Code = "MFA_TRUSTED_DEVICE_NOT_FOUND"
+)
+
+// Organization and role codes
+const (
+	OrganizationNotFound         Code = "ORGANIZATION_NOT_FOUND"
+	OrganizationSlugTaken        Code = "ORGANIZATION_SLUG_TAKEN"
+	OrganizationNotMember        Code = "ORGANIZATION_NOT_MEMBER"
+	OrganizationPermissionDenied Code = "ORGANIZATION_PERMISSION_DENIED"
+	OrganizationLastOwner        Code = "ORGANIZATION_LAST_OWNER"
+	MembershipNotFound           Code = "ORGANIZATION_MEMBERSHIP_NOT_FOUND"
+	RoleNotFound                 Code = "ROLE_NOT_FOUND"
+	RoleNameTaken                Code = "ROLE_NAME_TAKEN"
+	RoleSystemImmutable          Code = "ROLE_SYSTEM_IMMUTABLE"
+	RoleNotHeld                  Code = "ROLE_NOT_HELD"
+	RoleLastOfUser               Code = "ROLE_LAST_OF_USER"
+)
+
+// Reference data, security and directory codes
+const (
+	CountryNotFound       Code = "COUNTRY_NOT_FOUND"
+	IPNotBlocked          Code = "SECURITY_IP_NOT_BLOCKED"
+	SigningKeysDisabled   Code = "SECURITY_SIGNING_KEYS_DISABLED"
+	ClientNotFound        Code = "SECURITY_CLIENT_NOT_FOUND"
+	EmailDomainExists     Code = "SECURITY_EMAIL_DOMAIN_EXISTS"
+	EmailDomainNotFound   Code = "SECURITY_EMAIL_DOMAIN_NOT_FOUND"
+	DirectorySyncDisabled Code = "DIRECTORY_SYNC_DISABLED"
+	DirectorySyncRunning  Code = "DIRECTORY_SYNC_RUNNING"
+)
+
+// Terms of service codes
+const (
+	TermsAcceptanceRequired Code = "TERMS_ACCEPTANCE_REQUIRED" // 451, current versions must be accepted first
+	TermsVersionNotCurrent  Code = "TERMS_VERSION_NOT_CURRENT"
+	TermsVersionExists      Code = "TERMS_VERSION_EXISTS"
+)
+
+// Background job codes
+const (
+	JobNotFound  Code = "JOB_NOT_FOUND"
+	JobNotFailed Code = "JOB_NOT_FAILED" // 409, only failed jobs can be retried
+)
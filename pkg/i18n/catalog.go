@@ -0,0 +1,116 @@
+// Package i18n translates API messages using catalogs embedded in the binary.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// validationPattern matches the messages of gin binding (go-playground/validator) errors
+var validationPattern = regexp.MustCompile(`^Key: '[^']*' Error:Field validation for '([^']*)' failed on the '([^']*)' tag$`)
+
+// Catalog holds the messages of every embedded locale, keyed by their English text.
+// Validation messages are keyed "validation.<tag>" and may use the {field} and {param} placeholders.
+type Catalog struct {
+	messages map[language.Tag]map[string]string
+	tags     []language.Tag
+	matcher  language.Matcher
+}
+
+// Load reads the embedded locale catalogs; English is always the first, fallback, language
+func Load() (*Catalog, error) {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Catalog{messages: make(map[language.Tag]map[string]string)}
+	tags := []language.Tag{language.English}
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ".json")
+		tag, err := language.Parse(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid locale file %s: %w", file.Name(), err)
+		}
+		data, err := locales.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		messages := make(map[string]string)
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("invalid locale file %s: %w", file.Name(), err)
+		}
+		c.messages[tag] = messages
+		if tag != language.English {
+			tags = append(tags, tag)
+		}
+	}
+	c.tags = tags
+	c.matcher = language.NewMatcher(tags)
+	return c, nil
+}
+
+// Match returns the supported language best matching an Accept-Language header
+func (c *Catalog) Match(acceptLanguage string) language.Tag {
+	preferred, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(preferred) == 0 {
+		return language.English
+	}
+	_, index, _ := c.matcher.Match(preferred...)
+	return c.tags[index]
+}
+
+// Translate translates a message, falling back to English and then to the message itself.
+// Multi-line messages are translated line by line and "<known message>: <detail>" keeps the detail.
+func (c *Catalog) Translate(tag language.Tag, message string) string {
+	lines := strings.Split(message, "\n")
+	for i, line := range lines {
+		lines[i] = c.translateLine(tag, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (c *Catalog) translateLine(tag language.Tag, line string) string {
+	if translated, ok := c.lookup(tag, line); ok {
+		return translated
+	}
+	if m := validationPattern.FindStringSubmatch(line); m != nil {
+		if template, ok := c.Validation(tag, m[2]); ok {
+			return strings.ReplaceAll(template, "{field}", m[1])
+		}
+		return line
+	}
+	if prefix, detail, found := strings.Cut(line, ": "); found {
+		if translated, ok := c.lookup(tag, prefix); ok {
+			return translated + ": " + detail
+		}
+	}
+	return line
+}
+
+// Validation returns the message of a validation rule, such as required or min, from its
+// validation.<rule> message or else validation.default, with the {field} and {param} placeholders
+// left to fill
+func (c *Catalog) Validation(tag language.Tag, rule string) (string, bool) {
+	if template, ok := c.lookup(tag, "validation."+rule); ok {
+		return template, true
+	}
+	return c.lookup(tag, "validation.default")
+}
+
+func (c *Catalog) lookup(tag language.Tag, key string) (string, bool) {
+	if translated, ok := c.messages[tag][key]; ok {
+		return translated, true
+	}
+	translated, ok := c.messages[language.English][key]
+	return translated, ok
+}
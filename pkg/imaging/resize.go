@@ -0,0 +1,82 @@
+// Package imaging provides image decoding, resizing and encoding utilities.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+
+	// Register the GIF decoder for image.Decode
+	_ "image/gif"
+
+	"golang.org/x/image/draw"
+)
+
+// MaxDimension bounds the width and height of accepted images to avoid decompression bombs
+const MaxDimension = 4096
+
+var (
+	ErrUnsupportedFormat = errors.New("unsupported image format")
+	ErrImageTooLarge     = errors.New("image dimensions are too large")
+)
+
+// DetectFormat validates the image header and returns its format name (jpeg, png or gif)
+func DetectFormat(data []byte) (string, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", ErrUnsupportedFormat
+	}
+	if cfg.Width > MaxDimension || cfg.Height > MaxDimension {
+		return "", ErrImageTooLarge
+	}
+	return format, nil
+}
+
+// Decode decodes a JPEG, PNG or GIF image
+func Decode(data []byte) (image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	return img, nil
+}
+
+// Fit scales the image down so that its longest side is at most maxPixels, preserving the aspect ratio.
+// Images already within the bounds are returned unchanged.
+func Fit(src image.Image, maxPixels int) image.Image {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= maxPixels && height <= maxPixels {
+		return src
+	}
+
+	if width >= height {
+		height = max(1, height*maxPixels/width)
+		width = maxPixels
+	} else {
+		width = max(1, width*maxPixels/height)
+		height = maxPixels
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, draw.Over, nil)
+	return dst
+}
+
+// Encode encodes the image as PNG when format is png or gif (to keep transparency), JPEG otherwise.
+// It returns the encoded bytes along with their content type and file extension.
+func Encode(img image.Image, format string) ([]byte, string, string, error) {
+	var buf bytes.Buffer
+	if format == "png" || format == "gif" {
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, "", "", err
+		}
+		return buf.Bytes(), "image/png", "png", nil
+	}
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, "", "", err
+	}
+	return buf.Bytes(), "image/jpeg", "jpg", nil
+}
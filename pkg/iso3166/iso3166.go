@@ -0,0 +1,89 @@
+// Package iso3166 looks up ISO 3166-1 countries and ISO 3166-2 subdivisions by code or name.
+// Subdivisions are only listed for the countries whose addresses commonly carry a state or province.
+package iso3166
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+//go:embed countries.json
+var countriesJSON []byte
+
+// Country is an ISO 3166-1 country
+type Country struct {
+	Code         string        `json:"code" example:"US"`    // Alpha-2 code
+	Alpha3       string        `json:"alpha3" example:"USA"` // Alpha-3 code
+	Name         string        `json:"name" example:"United States"`
+	Aliases      []string      `json:"aliases,omitempty"` // Other common names, matched on lookup
+	Subdivisions []Subdivision `json:"subdivisions,omitempty"`
+}
+
+// Subdivision is an ISO 3166-2 country subdivision (state, province, territory...)
+type Subdivision struct {
+	Code string `json:"code" example:"US-NY"`
+	Name string `json:"name" example:"New York"`
+}
+
+var (
+	countries []Country
+	byKey     = make(map[string]int)
+)
+
+func init() {
+	if err := json.Unmarshal(countriesJSON, &countries); err != nil {
+		panic("iso3166: invalid embedded data: " + err.Error())
+	}
+	for i, c := range countries {
+		for _, key := range append([]string{c.Code, c.Alpha3, c.Name}, c.Aliases...) {
+			byKey[fold(key)] = i
+		}
+	}
+}
+
+// Countries returns every country, sorted by alpha-2 code
+func Countries() []Country {
+	return countries
+}
+
+// LookupCountry finds a country by alpha-2 or alpha-3 code or by name, ignoring case and accents
+func LookupCountry(codeOrName string) (Country, bool) {
+	i, ok := byKey[fold(codeOrName)]
+	if !ok {
+		return Country{}, false
+	}
+	return countries[i], true
+}
+
+// HasSubdivisions reports whether the subdivisions of the country are known
+func (c Country) HasSubdivisions() bool {
+	return len(c.Subdivisions) > 0
+}
+
+// LookupSubdivision finds a subdivision of the country by its full ("US-NY") or
+// short ("NY") code or by name, ignoring case and accents
+func (c Country) LookupSubdivision(codeOrName string) (Subdivision, bool) {
+	key := fold(codeOrName)
+	for _, s := range c.Subdivisions {
+		if key == fold(s.Code) || key == fold(strings.TrimPrefix(s.Code, c.Code+"-")) || key == fold(s.Name) {
+			return s, true
+		}
+	}
+	return Subdivision{}, false
+}
+
+// fold lowercases s and strips its accents and surrounding spaces
+func fold(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, strings.TrimSpace(s))
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
@@ -0,0 +1,157 @@
+package ldap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// BER identifiers of the universal types used by LDAP, see RFC 4511 section 5.1
+const (
+	tagBoolean     = 0x01
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagEnumerated  = 0x0a
+	tagSequence    = 0x30
+	tagSet         = 0x31
+)
+
+// Identifier classes, combined with the tag number of application and context-specific types
+const (
+	classApplication = 0x40
+	classContext     = 0x80
+	constructed      = 0x20
+)
+
+// maxMessageSize bounds the messages read from servers
+const maxMessageSize = 16 << 20
+
+var errMalformed = errors.New("ldap: malformed BER encoding")
+
+// element is a decoded BER type-length-value; constructed elements hold their encoded children
+type element struct {
+	tag  byte
+	data []byte
+}
+
+// encode returns the BER encoding of an element with the identifier tag and the contents data
+func encode(tag byte, data ...[]byte) []byte {
+	var length int
+	for _, d := range data {
+		length += len(d)
+	}
+	out := append([]byte{tag}, encodeLength(length)...)
+	for _, d := range data {
+		out = append(out, d...)
+	}
+	return out
+}
+
+func encodeLength(n int) []byte {
+	if n < 0x80 {
+		return []byte{byte(n)}
+	}
+	var digits []byte
+	for ; n > 0; n >>= 8 {
+		digits = append([]byte{byte(n)}, digits...)
+	}
+	return append([]byte{0x80 | byte(len(digits))}, digits...)
+}
+
+// encodeInt returns the BER encoding of an integer of type tag, in minimal two's complement
+func encodeInt(tag byte, n int64) []byte {
+	digits := binary.BigEndian.AppendUint64(nil, uint64(n))
+	// Leading bytes only repeating the sign bit of the next one are dropped
+	for len(digits) > 1 && (digits[0] == 0x00 && digits[1]&0x80 == 0 || digits[0] == 0xff && digits[1]&0x80 != 0) {
+		digits = digits[1:]
+	}
+	return encode(tag, digits)
+}
+
+func encodeString(tag byte, s string) []byte {
+	return encode(tag, []byte(s))
+}
+
+func encodeBool(b bool) []byte {
+	if b {
+		return encode(tagBoolean, []byte{0xff})
+	}
+	return encode(tagBoolean, []byte{0x00})
+}
+
+// readElement reads the next element from r
+func readElement(r *bufio.Reader) (element, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return element{}, err
+	}
+	first, err := r.ReadByte()
+	if err != nil {
+		return element{}, err
+	}
+	length := int(first)
+	if first&0x80 != 0 {
+		size := int(first & 0x7f)
+		if size == 0 || size > 4 {
+			return element{}, errMalformed
+		}
+		length = 0
+		for range size {
+			b, err := r.ReadByte()
+			if err != nil {
+				return element{}, err
+			}
+			length = length<<8 | int(b)
+		}
+	}
+	if length > maxMessageSize {
+		return element{}, fmt.Errorf("ldap: message of %d bytes exceeds the limit", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return element{}, err
+	}
+	return element{tag: tag, data: data}, nil
+}
+
+// children decodes the elements nested in a constructed element
+func (e element) children() ([]element, error) {
+	var elements []element
+	for data := e.data; len(data) > 0; {
+		if len(data) < 2 {
+			return nil, errMalformed
+		}
+		tag, length, header := data[0], int(data[1]), 2
+		if length&0x80 != 0 {
+			size := length & 0x7f
+			if size == 0 || size > 4 || len(data) < 2+size {
+				return nil, errMalformed
+			}
+			length = 0
+			for _, b := range data[2 : 2+size] {
+				length = length<<8 | int(b)
+			}
+			header += size
+		}
+		if length > len(data)-header {
+			return nil, errMalformed
+		}
+		elements = append(elements, element{tag: tag, data: data[header : header+length]})
+		data = data[header+length:]
+	}
+	return elements, nil
+}
+
+// int decodes the two's complement contents of an integer or enumerated element
+func (e element) int() int64 {
+	var n int64
+	for i, b := range e.data {
+		if i == 0 && b&0x80 != 0 {
+			n = -1
+		}
+		n = n<<8 | int64(b)
+	}
+	return n
+}
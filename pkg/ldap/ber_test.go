@@ -0,0 +1,181 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestEncodeLength(t *testing.T) {
+	tests := []struct {
+		n    int
+		want string
+	}{
+		{0, "00"},
+		{127, "7f"},
+		{128, "8180"},
+		{255, "81ff"},
+		{256, "820100"},
+		{65536, "83010000"},
+	}
+	for _, tt := range tests {
+		if got := hex.EncodeToString(encodeLength(tt.n)); got != tt.want {
+			t.Errorf("encodeLength(%d) = %s, want %s", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestEncodeInt(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "020100"},
+		{1, "020101"},
+		{127, "02017f"},
+		{128, "02020080"},
+		{256, "02020100"},
+		{-1, "0201ff"},
+		{-128, "020180"},
+		{-129, "0202ff7f"},
+		{1<<31 - 1, "02047fffffff"},
+	}
+	for _, tt := range tests {
+		encoded := encodeInt(tagInteger, tt.n)
+		if got := hex.EncodeToString(encoded); got != tt.want {
+			t.Errorf("encodeInt(%d) = %s, want %s", tt.n, got, tt.want)
+		}
+		e, err := readElement(bufio.NewReader(bytes.NewReader(encoded)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := e.int(); got != tt.n {
+			t.Errorf("int() of %s = %d, want %d", tt.want, got, tt.n)
+		}
+	}
+}
+
+func TestElementRoundTrip(t *testing.T) {
+	long := strings.Repeat("x", 300)
+	encoded := encode(tagSequence, encodeString(tagOctetString, "dc=example,dc=com"), encodeBool(true), encodeString(tagOctetString, long), encode(tagSet))
+
+	e, err := readElement(bufio.NewReader(bytes.NewReader(encoded)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.tag != tagSequence {
+		t.Fatalf("tag = %#x, want %#x", e.tag, tagSequence)
+	}
+	children, err := e.children()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []element{
+		{tag: tagOctetString, data: []byte("dc=example,dc=com")},
+		{tag: tagBoolean, data: []byte{0xff}},
+		{tag: tagOctetString, data: []byte(long)},
+		{tag: tagSet, data: []byte{}},
+	}
+	if len(children) != len(want) {
+		t.Fatalf("children = %d, want %d", len(children), len(want))
+	}
+	for i, child := range children {
+		if child.tag != want[i].tag || !bytes.Equal(child.data, want[i].data) {
+			t.Errorf("child %d = %#x %q, want %#x %q", i, child.tag, child.data, want[i].tag, want[i].data)
+		}
+	}
+}
+
+func TestReadElementMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		encoded string
+	}{
+		{"empty", ""},
+		{"missing length", "30"},
+		{"indefinite length", "3080"},
+		{"length of 5 bytes", "30850000000001"},
+		{"truncated length", "3082ff"},
+		{"truncated contents", "04056162"},
+		{"over the size limit", "0484ffffffff"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.encoded)
+			if e, err := readElement(bufio.NewReader(bytes.NewReader(data))); err == nil {
+				t.Errorf("readElement = %+v, want an error", e)
+			}
+		})
+	}
+}
+
+func TestChildrenMalformed(t *testing.T) {
+	for _, data := range []string{"04", "0405616263", "0480", "048201", "0481ff00"} {
+		contents, _ := hex.DecodeString(data)
+		if children, err := (element{tag: tagSequence, data: contents}).children(); err == nil {
+			t.Errorf("children of %s = %+v, want an error", data, children)
+		}
+	}
+}
+
+func TestCompileFilter(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		// Filters from the examples of RFC 4515 section 4
+		{"(cn=Babs Jensen)", "a3110402636e040b42616273204a656e73656e"},
+		{"(objectClass=*)", "870b6f626a656374436c617373"},
+		{"(!(cn=Tim Howes))", "a211a30f0402636e040954696d20486f776573"},
+		{"(sn>=Jensen)", "a50c0402736e04064a656e73656e"},
+		{"(sn<=Jensen)", "a60c0402736e04064a656e73656e"},
+		{"(sn~=Jensen)", "a80c0402736e04064a656e73656e"},
+		// A trailing * leaves no final substring
+		{"(o=univ*of*mich*)", "a41504016f30108004756e697681026f6681046d696368"},
+		{"(cn=*Jensen)", "a40e0402636e300882064a656e73656e"},
+		{"(&(objectClass=person)(|(sn=Jensen)(cn=Babs J*)))",
+			"a037a315040b6f626a656374436c6173730406706572736f6e" +
+				"a11ea30c0402736e04064a656e73656ea40e0402636e3008800642616273204a"},
+		{"(cn=*\\2a*)", "a4090402636e300381012a"},
+		{"(cn=Lu\\c4\\8di\\c4\\87)", "a30d0402636e04074c75c48d69c487"},
+		{"(seeAlso=)", "a30b0407736565416c736f0400"},
+		{"(&)", "a000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			encoded, err := compileFilter(tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(encoded); got != tt.want {
+				t.Errorf("compileFilter = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileFilterInvalid(t *testing.T) {
+	for _, filter := range []string{
+		"",
+		"cn=x",
+		"(cn=x",
+		"(cn=x))",
+		"(=x)",
+		"(cn)",
+		"(c n=x)",
+		"(cn=a(b)",
+		"(cn=\\2)",
+		"(cn=\\zz)",
+		"(cn:dn:=x)",
+		"(&(cn=x)",
+		"(!cn=x)",
+	} {
+		t.Run(filter, func(t *testing.T) {
+			if encoded, err := compileFilter(filter); err == nil {
+				t.Errorf("compileFilter = %x, want an error", encoded)
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+// Package ldap provides a minimal LDAPv3 client, see RFC 4511: simple binds and paged subtree
+// searches, over plain TCP or TLS.
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Application tags of the protocol operations used by the client
+const (
+	opBindRequest         = classApplication | constructed | 0
+	opBindResponse        = classApplication | constructed | 1
+	opUnbindRequest       = classApplication | 2
+	opSearchRequest       = classApplication | constructed | 3
+	opSearchResultEntry   = classApplication | constructed | 4
+	opSearchResultDone    = classApplication | constructed | 5
+	opSearchResultRef     = classApplication | constructed | 19
+	opExtendedResponse    = classApplication | constructed | 24
+	tagControls           = classContext | constructed | 0
+	tagSimpleAuth         = classContext | 0
+	pagedResultsControlID = "1.2.840.113556.1.4.319" // RFC 2696
+)
+
+// Search scopes and alias dereferencing, see RFC 4511 section 4.5.1
+const (
+	scopeWholeSubtree = 2
+	derefNever        = 0
+)
+
+// ResultSuccess is the result code of successful operations
+const ResultSuccess = 0
+
+// Error is a result other than success returned by the server
+type Error struct {
+	Code    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("ldap: result code %d", e.Code)
+	}
+	return fmt.Sprintf("ldap: result code %d: %s", e.Code, e.Message)
+}
+
+// Options configures a Conn
+type Options struct {
+	URL          string // ldap://host[:389] or ldaps://host[:636]
+	BindDN       string // Empty binds anonymously
+	BindPassword string
+	Timeout      time.Duration // Timeout of the connection and of each operation
+	TLSConfig    *tls.Config   // TLS configuration of ldaps URLs; nil verifies the server with the system roots
+}
+
+// Conn is a connection to an LDAP server; it is not safe for concurrent use
+type Conn struct {
+	conn      net.Conn
+	r         *bufio.Reader
+	timeout   time.Duration
+	messageID int64
+}
+
+// Dial connects to the server of opts.URL and binds with the credentials of opts
+func Dial(ctx context.Context, opts Options) (*Conn, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil {
+		return nil, err
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		conn, err = dialer.DialContext(ctx, "tcp", hostPort(u, "389"))
+	case "ldaps":
+		config := opts.TLSConfig
+		if config == nil {
+			config = &tls.Config{ServerName: u.Hostname()}
+		}
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: config}).DialContext(ctx, "tcp", hostPort(u, "636"))
+	default:
+		return nil, fmt.Errorf("ldap: unsupported URL scheme %q", u.Scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Conn{conn: conn, r: bufio.NewReader(conn), timeout: timeout}
+	if err := c.Bind(ctx, opts.BindDN, opts.BindPassword); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func hostPort(u *url.URL, defaultPort string) string {
+	if u.Port() == "" {
+		return net.JoinHostPort(u.Hostname(), defaultPort)
+	}
+	return u.Host
+}
+
+// Close unbinds and closes the connection
+func (c *Conn) Close() error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.timeout))
+	c.send(encode(opUnbindRequest))
+	return c.conn.Close()
+}
+
+// Bind authenticates with a simple bind; an empty dn binds anonymously
+func (c *Conn) Bind(ctx context.Context, dn, password string) error {
+	op := encode(opBindRequest, encodeInt(tagInteger, 3), encodeString(tagOctetString, dn), encodeString(tagSimpleAuth, password))
+	id, err := c.request(ctx, op, nil)
+	if err != nil {
+		return err
+	}
+	response, _, err := c.response(id)
+	if err != nil {
+		return err
+	}
+	if response.tag != opBindResponse {
+		return errMalformed
+	}
+	return result(response)
+}
+
+// SearchRequest is a search of the subtree of BaseDN
+type SearchRequest struct {
+	BaseDN     string
+	Filter     string   // RFC 4515 filter, such as (objectClass=person)
+	Attributes []string // Attributes returned, empty returns all user attributes
+	// PageSize pages the results with the simple paged results control, so servers limiting the
+	// size of results, such as Active Directory, return every entry; 0 disables paging
+	PageSize int
+}
+
+// Entry is an entry returned by a search
+type Entry struct {
+	DN         string
+	Attributes map[string][][]byte // By lowercased attribute name
+}
+
+// Get returns the first value of the attribute name, or an empty string
+func (e *Entry) Get(name string) string {
+	if values := e.Attributes[strings.ToLower(name)]; len(values) > 0 {
+		return string(values[0])
+	}
+	return ""
+}
+
+// GetRaw returns the first value of the attribute name, or nil
+func (e *Entry) GetRaw(name string) []byte {
+	if values := e.Attributes[strings.ToLower(name)]; len(values) > 0 {
+		return values[0]
+	}
+	return nil
+}
+
+// Search returns the entries of the subtree of req.BaseDN matching req.Filter
+func (c *Conn) Search(ctx context.Context, req SearchRequest) ([]*Entry, error) {
+	filter, err := compileFilter(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+	attributes := make([][]byte, len(req.Attributes))
+	for i, attribute := range req.Attributes {
+		attributes[i] = encodeString(tagOctetString, attribute)
+	}
+	op := encode(opSearchRequest,
+		encodeString(tagOctetString, req.BaseDN),
+		encodeInt(tagEnumerated, scopeWholeSubtree),
+		encodeInt(tagEnumerated, derefNever),
+		encodeInt(tagInteger, 0), // No size limit
+		encodeInt(tagInteger, 0), // No time limit
+		encodeBool(false),        // Types and values
+		filter,
+		encode(tagSequence, attributes...),
+	)
+
+	var entries []*Entry
+	var cookie []byte
+	for {
+		var controls []byte
+		if req.PageSize > 0 {
+			controls = encode(tagControls, pagedResultsControl(req.PageSize, cookie))
+		}
+		id, err := c.request(ctx, op, controls)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			response, responseControls, err := c.response(id)
+			if err != nil {
+				return nil, err
+			}
+			switch response.tag {
+			case opSearchResultEntry:
+				entry, err := parseEntry(response)
+				if err != nil {
+					return nil, err
+				}
+				entries = append(entries, entry)
+				continue
+			case opSearchResultRef:
+				// Referrals to other servers are not followed
+				continue
+			case opSearchResultDone:
+				if err := result(response); err != nil {
+					return nil, err
+				}
+				cookie, err = pagedResultsCookie(responseControls)
+				if err != nil {
+					return nil, err
+				}
+			default:
+				return nil, errMalformed
+			}
+			break
+		}
+		if req.PageSize == 0 || len(cookie) == 0 {
+			return entries, nil
+		}
+	}
+}
+
+// request sends the protocol operation op with the encoded controls and returns its message ID
+func (c *Conn) request(ctx context.Context, op, controls []byte) (int64, error) {
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return 0, err
+	}
+	c.messageID++
+	message := encode(tagSequence, encodeInt(tagInteger, c.messageID), op, controls)
+	return c.messageID, c.send(message)
+}
+
+func (c *Conn) send(message []byte) error {
+	_, err := c.conn.Write(message)
+	return err
+}
+
+// response reads the next message answering the request id, and returns its protocol operation and
+// its controls
+func (c *Conn) response(id int64) (element, []element, error) {
+	for {
+		message, err := readElement(c.r)
+		if err != nil {
+			return element{}, nil, err
+		}
+		parts, err := message.children()
+		if err != nil || message.tag != tagSequence || len(parts) < 2 {
+			return element{}, nil, errMalformed
+		}
+		switch messageID := parts[0].int(); {
+		case messageID == 0 && parts[1].tag == opExtendedResponse:
+			// Unsolicited notification, such as the notice of disconnection
+			if err := result(parts[1]); err != nil {
+				return element{}, nil, err
+			}
+			return element{}, nil, errors.New("ldap: connection closed by the server")
+		case messageID != id:
+			continue
+		}
+		var controls []element
+		if len(parts) > 2 && parts[2].tag == tagControls {
+			if controls, err = parts[2].children(); err != nil {
+				return element{}, nil, err
+			}
+		}
+		return parts[1], controls, nil
+	}
+}
+
+// result returns the error of an LDAPResult other than success
+func result(op element) error {
+	parts, err := op.children()
+	if err != nil || len(parts) < 3 {
+		return errMalformed
+	}
+	if code := int(parts[0].int()); code != ResultSuccess {
+		return &Error{Code: code, Message: string(parts[2].data)}
+	}
+	return nil
+}
+
+func parseEntry(op element) (*Entry, error) {
+	parts, err := op.children()
+	if err != nil || len(parts) < 2 {
+		return nil, errMalformed
+	}
+	entry := &Entry{DN: string(parts[0].data), Attributes: make(map[string][][]byte)}
+	attributes, err := parts[1].children()
+	if err != nil {
+		return nil, err
+	}
+	for _, attribute := range attributes {
+		fields, err := attribute.children()
+		if err != nil || len(fields) < 2 {
+			return nil, errMalformed
+		}
+		values, err := fields[1].children()
+		if err != nil {
+			return nil, err
+		}
+		name := strings.ToLower(string(fields[0].data))
+		for _, value := range values {
+			entry.Attributes[name] = append(entry.Attributes[name], value.data)
+		}
+	}
+	return entry, nil
+}
+
+// pagedResultsControl encodes the paged results control requesting the page after cookie
+func pagedResultsControl(size int, cookie []byte) []byte {
+	value := encode(tagSequence, encodeInt(tagInteger, int64(size)), encode(tagOctetString, cookie))
+	return encode(tagSequence, encodeString(tagOctetString, pagedResultsControlID), encode(tagOctetString, value))
+}
+
+// pagedResultsCookie returns the cookie of the next page from the response controls, empty after
+// the last page
+func pagedResultsCookie(controls []element) ([]byte, error) {
+	for _, control := range controls {
+		fields, err := control.children()
+		if err != nil || len(fields) < 2 || string(fields[0].data) != pagedResultsControlID {
+			continue
+		}
+		value := fields[len(fields)-1]
+		parts, err := element{data: value.data}.children()
+		if err != nil || len(parts) != 1 {
+			return nil, errMalformed
+		}
+		page, err := parts[0].children()
+		if err != nil || len(page) < 2 {
+			return nil, errMalformed
+		}
+		return page[1].data, nil
+	}
+	return nil, nil
+}
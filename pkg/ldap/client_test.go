@@ -0,0 +1,148 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// scriptedServer answers each request read from the other end of a net.Pipe with the messages
+// respond returns for it, and hands the requests over to the test
+func scriptedServer(t *testing.T, respond func(id int64, op element) [][]byte) (*Conn, <-chan element) {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() { client.Close() })
+	requests := make(chan element, 16)
+	go func() {
+		defer server.Close()
+		r := bufio.NewReader(server)
+		for {
+			message, err := readElement(r)
+			if err != nil {
+				return
+			}
+			requests <- message
+			parts, err := message.children()
+			if err != nil || len(parts) < 2 {
+				return
+			}
+			for _, response := range respond(parts[0].int(), parts[1]) {
+				if _, err := server.Write(response); err != nil {
+					return
+				}
+			}
+		}
+	}()
+	return &Conn{conn: client, r: bufio.NewReader(client), timeout: time.Second}, requests
+}
+
+func message(id int64, op []byte, controls ...[]byte) []byte {
+	return encode(tagSequence, append([][]byte{encodeInt(tagInteger, id), op}, controls...)...)
+}
+
+func ldapResult(tag byte, code int64, diagnostic string) []byte {
+	return encode(tag, encodeInt(tagEnumerated, code), encodeString(tagOctetString, ""), encodeString(tagOctetString, diagnostic))
+}
+
+func searchEntry(dn string, attributes map[string][]string) []byte {
+	var encoded [][]byte
+	for name, values := range attributes {
+		var vals [][]byte
+		for _, v := range values {
+			vals = append(vals, encodeString(tagOctetString, v))
+		}
+		encoded = append(encoded, encode(tagSequence, encodeString(tagOctetString, name), encode(tagSet, vals...)))
+	}
+	return encode(opSearchResultEntry, encodeString(tagOctetString, dn), encode(tagSequence, encoded...))
+}
+
+func TestBind(t *testing.T) {
+	conn, requests := scriptedServer(t, func(id int64, op element) [][]byte {
+		return [][]byte{message(id, ldapResult(opBindResponse, ResultSuccess, ""))}
+	})
+	if err := conn.Bind(context.Background(), "cn=admin", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Message 1, bindRequest of version 3 with the simple authentication secret
+	want := "301a02010160150201030408636e3d61646d696e8006736563726574"
+	request := <-requests
+	if got := hex.EncodeToString(encode(request.tag, request.data)); got != want {
+		t.Errorf("request = %s, want %s", got, want)
+	}
+}
+
+func TestBindInvalidCredentials(t *testing.T) {
+	conn, _ := scriptedServer(t, func(id int64, op element) [][]byte {
+		return [][]byte{message(id, ldapResult(opBindResponse, 49, "invalid credentials"))}
+	})
+	var ldapErr *Error
+	if err := conn.Bind(context.Background(), "cn=admin", "wrong"); !errors.As(err, &ldapErr) || ldapErr.Code != 49 || ldapErr.Message != "invalid credentials" {
+		t.Errorf("err = %v, want result code 49", err)
+	}
+}
+
+func TestSearchPages(t *testing.T) {
+	pages := []struct {
+		entry  []byte
+		cookie string
+	}{
+		{searchEntry("uid=ada,dc=example,dc=com", map[string][]string{"mail": {"ada@example.com"}, "memberOf": {"cn=a", "cn=b"}}), "next"},
+		{searchEntry("uid=alan,dc=example,dc=com", map[string][]string{"MAIL": {"alan@example.com"}}), ""},
+	}
+	served := 0
+	conn, _ := scriptedServer(t, func(id int64, op element) [][]byte {
+		if op.tag != opSearchRequest {
+			t.Errorf("op = %#x, want a search request", op.tag)
+			return nil
+		}
+		page := pages[served]
+		served++
+		control := encode(tagSequence, encodeString(tagOctetString, pagedResultsControlID),
+			encode(tagOctetString, encode(tagSequence, encodeInt(tagInteger, 0), encodeString(tagOctetString, page.cookie))))
+		return [][]byte{
+			// Responses to other messages are skipped
+			message(id+100, ldapResult(opSearchResultDone, ResultSuccess, "")),
+			message(id, page.entry),
+			message(id, encode(opSearchResultRef, encodeString(tagOctetString, "ldap://other/"))),
+			message(id, ldapResult(opSearchResultDone, ResultSuccess, ""), encode(tagControls, control)),
+		}
+	})
+
+	entries, err := conn.Search(context.Background(), SearchRequest{BaseDN: "dc=example,dc=com", Filter: "(mail=*)", PageSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || served != 2 {
+		t.Fatalf("entries = %d in %d pages, want 2 in 2", len(entries), served)
+	}
+	if entries[0].DN != "uid=ada,dc=example,dc=com" || entries[0].Get("Mail") != "ada@example.com" || len(entries[0].Attributes["memberof"]) != 2 {
+		t.Errorf("first entry = %+v", entries[0])
+	}
+	if entries[1].Get("mail") != "alan@example.com" || !bytes.Equal(entries[1].GetRaw("mail"), []byte("alan@example.com")) || entries[1].Get("cn") != "" {
+		t.Errorf("second entry = %+v", entries[1])
+	}
+}
+
+func TestSearchNoticeOfDisconnection(t *testing.T) {
+	conn, _ := scriptedServer(t, func(id int64, op element) [][]byte {
+		return [][]byte{message(0, ldapResult(opExtendedResponse, 52, "server shutting down"))}
+	})
+	var ldapErr *Error
+	if _, err := conn.Search(context.Background(), SearchRequest{BaseDN: "dc=example,dc=com", Filter: "(uid=ada)"}); !errors.As(err, &ldapErr) || ldapErr.Code != 52 {
+		t.Errorf("err = %v, want result code 52", err)
+	}
+}
+
+func TestPagedResultsControl(t *testing.T) {
+	// Control of RFC 2696 asking for pages of 500 entries after the cookie abc
+	want := "3025" + "0416" + hex.EncodeToString([]byte(pagedResultsControlID)) + "040b3009020201f40403616263"
+	if got := hex.EncodeToString(pagedResultsControl(500, []byte("abc"))); got != want {
+		t.Errorf("pagedResultsControl = %s, want %s", got, want)
+	}
+}
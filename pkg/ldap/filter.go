@@ -0,0 +1,183 @@
+package ldap
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Context-specific tags of the filter choices, see RFC 4511 section 4.5.1
+const (
+	filterAnd            = classContext | constructed | 0
+	filterOr             = classContext | constructed | 1
+	filterNot            = classContext | constructed | 2
+	filterEqualityMatch  = classContext | constructed | 3
+	filterSubstrings     = classContext | constructed | 4
+	filterGreaterOrEqual = classContext | constructed | 5
+	filterLessOrEqual    = classContext | constructed | 6
+	filterPresent        = classContext | 7
+	filterApproxMatch    = classContext | constructed | 8
+
+	substringInitial = classContext | 0
+	substringAny     = classContext | 1
+	substringFinal   = classContext | 2
+)
+
+// compileFilter returns the BER encoding of a string filter such as (&(objectClass=person)(mail=*)),
+// see RFC 4515; extensible matches are not supported
+func compileFilter(filter string) ([]byte, error) {
+	p := &filterParser{filter: filter}
+	encoded, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(filter) {
+		return nil, p.errorf("unexpected %q", filter[p.pos:])
+	}
+	return encoded, nil
+}
+
+type filterParser struct {
+	filter string
+	pos    int
+}
+
+func (p *filterParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("ldap: invalid filter %q at offset %d: %s", p.filter, p.pos, fmt.Sprintf(format, args...))
+}
+
+// parse compiles the parenthesized filter at the current position
+func (p *filterParser) parse() ([]byte, error) {
+	if p.pos >= len(p.filter) || p.filter[p.pos] != '(' {
+		return nil, p.errorf("expected (")
+	}
+	p.pos++
+	if p.pos >= len(p.filter) {
+		return nil, p.errorf("unterminated filter")
+	}
+
+	var encoded []byte
+	var err error
+	switch p.filter[p.pos] {
+	case '&', '|':
+		tag := byte(filterAnd)
+		if p.filter[p.pos] == '|' {
+			tag = filterOr
+		}
+		p.pos++
+		var filters [][]byte
+		for p.pos < len(p.filter) && p.filter[p.pos] == '(' {
+			f, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, f)
+		}
+		encoded = encode(tag, filters...)
+	case '!':
+		p.pos++
+		var f []byte
+		if f, err = p.parse(); err != nil {
+			return nil, err
+		}
+		encoded = encode(filterNot, f)
+	default:
+		if encoded, err = p.parseItem(); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.pos >= len(p.filter) || p.filter[p.pos] != ')' {
+		return nil, p.errorf("expected )")
+	}
+	p.pos++
+	return encoded, nil
+}
+
+// parseItem compiles an attribute comparison, up to the closing parenthesis
+func (p *filterParser) parseItem() ([]byte, error) {
+	end := strings.IndexByte(p.filter[p.pos:], ')')
+	if end < 0 {
+		return nil, p.errorf("expected )")
+	}
+	item := p.filter[p.pos : p.pos+end]
+	eq := strings.IndexByte(item, '=')
+	if eq <= 0 {
+		return nil, p.errorf("expected attribute=value")
+	}
+	attribute, value := item[:eq], item[eq+1:]
+
+	tag := byte(filterEqualityMatch)
+	switch attribute[len(attribute)-1] {
+	case '>':
+		tag = filterGreaterOrEqual
+	case '<':
+		tag = filterLessOrEqual
+	case '~':
+		tag = filterApproxMatch
+	case ':':
+		return nil, p.errorf("extensible matches are not supported")
+	}
+	if tag != filterEqualityMatch {
+		attribute = attribute[:len(attribute)-1]
+	}
+	if attribute == "" || strings.ContainsAny(attribute, "()*\\ ") {
+		return nil, p.errorf("invalid attribute %q", attribute)
+	}
+	p.pos += end
+
+	switch {
+	case tag == filterEqualityMatch && value == "*":
+		return encodeString(filterPresent, attribute), nil
+	case tag == filterEqualityMatch && strings.Contains(value, "*"):
+		parts := strings.Split(value, "*")
+		var substrings [][]byte
+		for i, part := range parts {
+			if part == "" {
+				continue
+			}
+			unescaped, err := p.unescape(part)
+			if err != nil {
+				return nil, err
+			}
+			partTag := byte(substringAny)
+			switch i {
+			case 0:
+				partTag = substringInitial
+			case len(parts) - 1:
+				partTag = substringFinal
+			}
+			substrings = append(substrings, encodeString(partTag, unescaped))
+		}
+		return encode(filterSubstrings, encodeString(tagOctetString, attribute), encode(tagSequence, substrings...)), nil
+	}
+	unescaped, err := p.unescape(value)
+	if err != nil {
+		return nil, err
+	}
+	return encode(tag, encodeString(tagOctetString, attribute), encodeString(tagOctetString, unescaped)), nil
+}
+
+// unescape decodes the \XX hex escapes of a filter value
+func (p *filterParser) unescape(value string) (string, error) {
+	if strings.ContainsRune(value, '(') {
+		return "", p.errorf("unescaped ( in value")
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' {
+			b.WriteByte(value[i])
+			continue
+		}
+		if i+2 >= len(value) {
+			return "", p.errorf("incomplete escape in value")
+		}
+		decoded, err := hex.DecodeString(value[i+1 : i+3])
+		if err != nil {
+			return "", p.errorf("invalid escape in value")
+		}
+		b.Write(decoded)
+		i += 2
+	}
+	return b.String(), nil
+}
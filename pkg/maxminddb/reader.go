@@ -0,0 +1,344 @@
+// Package maxminddb provides a minimal reader of MaxMind DB (.mmdb) files, such as the GeoLite2 and
+// GeoIP2 databases, following https://maxmind.github.io/MaxMind-DB/.
+package maxminddb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+	"os"
+)
+
+// metadataMarker starts the metadata section at the end of the file
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// dataSectionSeparator is the size of the zeroed gap between the search tree and the data section
+const dataSectionSeparator = 16
+
+// maxDecodeDepth bounds the nesting of decoded values so a corrupt file cannot recurse forever
+const maxDecodeDepth = 64
+
+var ErrInvalidDatabase = errors.New("maxminddb: invalid database file")
+
+// Metadata describes a database
+type Metadata struct {
+	DatabaseType string
+	IPVersion    int
+	NodeCount    int
+	RecordSize   int
+	BuildEpoch   uint64
+}
+
+// Reader looks up IP addresses in a database loaded in memory; it is safe for concurrent use
+type Reader struct {
+	Metadata  Metadata
+	buffer    []byte
+	treeSize  int
+	ipv4Start int // Node of the ::/96 subtree holding the IPv4 addresses of IPv6 databases
+}
+
+// Open reads the database file at path
+func Open(path string) (*Reader, error) {
+	buffer, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return FromBytes(buffer)
+}
+
+// FromBytes parses a database held in memory
+func FromBytes(buffer []byte) (*Reader, error) {
+	start := bytes.LastIndex(buffer, metadataMarker)
+	if start < 0 {
+		return nil, ErrInvalidDatabase
+	}
+	meta := decoder{buffer: buffer[start+len(metadataMarker):]}
+	value, _, err := meta.decode(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	fields, ok := value.(map[string]any)
+	if !ok {
+		return nil, ErrInvalidDatabase
+	}
+
+	r := &Reader{buffer: buffer}
+	r.Metadata.DatabaseType, _ = fields["database_type"].(string)
+	r.Metadata.IPVersion = int(toUint(fields["ip_version"]))
+	r.Metadata.NodeCount = int(toUint(fields["node_count"]))
+	r.Metadata.RecordSize = int(toUint(fields["record_size"]))
+	r.Metadata.BuildEpoch = toUint(fields["build_epoch"])
+
+	switch r.Metadata.RecordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("maxminddb: unsupported record size %d", r.Metadata.RecordSize)
+	}
+	r.treeSize = r.Metadata.NodeCount * r.Metadata.RecordSize / 4
+	if r.treeSize+dataSectionSeparator > start {
+		return nil, ErrInvalidDatabase
+	}
+
+	if r.Metadata.IPVersion == 6 {
+		node := 0
+		for i := 0; i < 96 && node < r.Metadata.NodeCount; i++ {
+			if node, err = r.record(node, 0); err != nil {
+				return nil, err
+			}
+		}
+		r.ipv4Start = node
+	}
+	return r, nil
+}
+
+// Lookup returns the record of the network containing ip, or nil if the database has none
+func (r *Reader) Lookup(ip net.IP) (any, error) {
+	node := 0
+	var bits []byte
+	if v4 := ip.To4(); v4 != nil {
+		bits = v4
+		node = r.ipv4Start
+	} else if v6 := ip.To16(); v6 != nil {
+		if r.Metadata.IPVersion == 4 {
+			return nil, nil
+		}
+		bits = v6
+	} else {
+		return nil, fmt.Errorf("maxminddb: invalid IP address %q", ip)
+	}
+
+	for i := 0; i < len(bits)*8 && node < r.Metadata.NodeCount; i++ {
+		bit := int(bits[i/8]>>(7-i%8)) & 1
+		next, err := r.record(node, bit)
+		if err != nil {
+			return nil, err
+		}
+		node = next
+	}
+
+	switch {
+	case node == r.Metadata.NodeCount:
+		return nil, nil
+	case node < r.Metadata.NodeCount:
+		return nil, ErrInvalidDatabase
+	}
+	offset := node - r.Metadata.NodeCount - dataSectionSeparator
+	data := decoder{buffer: r.buffer[r.treeSize+dataSectionSeparator:]}
+	value, _, err := data.decode(offset, 0)
+	return value, err
+}
+
+// record returns the left (bit 0) or right (bit 1) record of a search tree node
+func (r *Reader) record(node, bit int) (int, error) {
+	size := r.Metadata.RecordSize / 4
+	offset := node * size
+	if offset+size > r.treeSize {
+		return 0, ErrInvalidDatabase
+	}
+	b := r.buffer[offset : offset+size]
+
+	switch r.Metadata.RecordSize {
+	case 24:
+		b = b[bit*3 : bit*3+3]
+		return int(b[0])<<16 | int(b[1])<<8 | int(b[2]), nil
+	case 28:
+		if bit == 0 {
+			return int(b[3]&0xF0)<<20 | int(b[0])<<16 | int(b[1])<<8 | int(b[2]), nil
+		}
+		return int(b[3]&0x0F)<<24 | int(b[4])<<16 | int(b[5])<<8 | int(b[6]), nil
+	default:
+		return int(binary.BigEndian.Uint32(b[bit*4 : bit*4+4])), nil
+	}
+}
+
+// Data field types
+const (
+	typeExtended = iota
+	typePointer
+	typeString
+	typeDouble
+	typeBytes
+	typeUint16
+	typeUint32
+	typeMap
+	typeInt32
+	typeUint64
+	typeUint128
+	typeArray
+	typeContainer
+	typeEndMarker
+	typeBool
+	typeFloat
+)
+
+// decoder decodes the values of a data section into strings, float64, []byte, uint64, int32,
+// *big.Int, bool, map[string]any and []any
+type decoder struct {
+	buffer []byte
+}
+
+// decode returns the value at offset and the offset following it
+func (d decoder) decode(offset, depth int) (any, int, error) {
+	if depth > maxDecodeDepth {
+		return nil, 0, ErrInvalidDatabase
+	}
+	if offset >= len(d.buffer) {
+		return nil, 0, ErrInvalidDatabase
+	}
+	ctrl := d.buffer[offset]
+	offset++
+	kind := int(ctrl >> 5)
+
+	if kind == typePointer {
+		pointer, next, err := d.pointer(ctrl, offset)
+		if err != nil {
+			return nil, 0, err
+		}
+		value, _, err := d.decode(pointer, depth+1)
+		return value, next, err
+	}
+
+	if kind == typeExtended {
+		if offset >= len(d.buffer) {
+			return nil, 0, ErrInvalidDatabase
+		}
+		kind = 7 + int(d.buffer[offset])
+		offset++
+	}
+
+	size, offset, err := d.size(ctrl, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	switch kind {
+	case typeMap:
+		value := make(map[string]any, size)
+		for range size {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			name, ok := key.(string)
+			if !ok {
+				return nil, 0, ErrInvalidDatabase
+			}
+			if value[name], offset, err = d.decode(next, depth+1); err != nil {
+				return nil, 0, err
+			}
+		}
+		return value, offset, nil
+	case typeArray:
+		value := make([]any, size)
+		for i := range value {
+			if value[i], offset, err = d.decode(offset, depth+1); err != nil {
+				return nil, 0, err
+			}
+		}
+		return value, offset, nil
+	case typeBool:
+		return size != 0, offset, nil
+	}
+
+	if offset+size > len(d.buffer) {
+		return nil, 0, ErrInvalidDatabase
+	}
+	b := d.buffer[offset : offset+size]
+	next := offset + size
+
+	switch kind {
+	case typeString:
+		return string(b), next, nil
+	case typeBytes:
+		return bytes.Clone(b), next, nil
+	case typeDouble:
+		if size != 8 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), next, nil
+	case typeFloat:
+		if size != 4 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(b))), next, nil
+	case typeUint16, typeUint32, typeUint64:
+		if size > 8 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		var value uint64
+		for _, c := range b {
+			value = value<<8 | uint64(c)
+		}
+		return value, next, nil
+	case typeInt32:
+		if size > 4 {
+			return nil, 0, ErrInvalidDatabase
+		}
+		var value uint32
+		for _, c := range b {
+			value = value<<8 | uint32(c)
+		}
+		return int32(value), next, nil
+	case typeUint128:
+		return new(big.Int).SetBytes(b), next, nil
+	default:
+		return nil, 0, fmt.Errorf("maxminddb: unsupported data type %d", kind)
+	}
+}
+
+// size reads the payload size of a field from its control byte and the bytes following it
+func (d decoder) size(ctrl byte, offset int) (int, int, error) {
+	size := int(ctrl & 0x1F)
+	if size < 29 {
+		return size, offset, nil
+	}
+	extra := size - 28
+	if offset+extra > len(d.buffer) {
+		return 0, 0, ErrInvalidDatabase
+	}
+	b := d.buffer[offset : offset+extra]
+	switch extra {
+	case 1:
+		size = 29 + int(b[0])
+	case 2:
+		size = 285 + (int(b[0])<<8 | int(b[1]))
+	default:
+		size = 65821 + (int(b[0])<<16 | int(b[1])<<8 | int(b[2]))
+	}
+	return size, offset + extra, nil
+}
+
+// pointer reads the data section offset a pointer field refers to
+func (d decoder) pointer(ctrl byte, offset int) (int, int, error) {
+	extra := int(ctrl>>3)&0x3 + 1
+	if offset+extra > len(d.buffer) {
+		return 0, 0, ErrInvalidDatabase
+	}
+	b := d.buffer[offset : offset+extra]
+	value := int(ctrl & 0x7)
+	switch extra {
+	case 1:
+		value = value<<8 | int(b[0])
+	case 2:
+		value = (value<<16 | int(b[0])<<8 | int(b[1])) + 2048
+	case 3:
+		value = (value<<24 | int(b[0])<<16 | int(b[1])<<8 | int(b[2])) + 526336
+	default:
+		value = int(binary.BigEndian.Uint32(b))
+	}
+	return value, offset + extra, nil
+}
+
+func toUint(value any) uint64 {
+	switch v := value.(type) {
+	case uint64:
+		return v
+	case int32:
+		return uint64(v)
+	}
+	return 0
+}
@@ -0,0 +1,344 @@
+package maxminddb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"math"
+	"math/big"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	uint128, _ := new(big.Int).SetString("ffffffffffffffffffffffffffffffff", 16)
+	tests := []struct {
+		name    string
+		encoded string
+		want    any
+	}{
+		{"empty string", "40", ""},
+		{"string", "43466f6f", "Foo"},
+		{"string of 29 bytes", "5d00" + strings.Repeat("78", 29), strings.Repeat("x", 29)},
+		{"string of 285 bytes", "5e0000" + strings.Repeat("78", 285), strings.Repeat("x", 285)},
+		{"string of 65821 bytes", "5f000000" + strings.Repeat("78", 65821), strings.Repeat("x", 65821)},
+		{"double", "68400921fb54442d18", math.Pi},
+		{"float", "04083fc00000", 1.5},
+		{"bytes", "830102ff", []byte{1, 2, 0xff}},
+		{"uint16 zero", "a0", uint64(0)},
+		{"uint16", "a2ffff", uint64(math.MaxUint16)},
+		{"uint32", "c4ffffffff", uint64(math.MaxUint32)},
+		{"uint64", "0802ffffffffffffffff", uint64(math.MaxUint64)},
+		{"uint128", "1003ffffffffffffffffffffffffffffffff", uint128},
+		{"int32 zero", "0001", int32(0)},
+		{"int32", "0401ffffffff", int32(-1)},
+		{"int32 short", "0101 7f", int32(127)},
+		{"false", "0007", false},
+		{"true", "0107", true},
+		{"map", "e142656e43466f6f", map[string]any{"en": "Foo"}},
+		{"empty map", "e0", map[string]any{}},
+		{"array", "020443466f6f43426172", []any{"Foo", "Bar"}},
+		{"nested", "e1456e616d6573e142656e43466f6f", map[string]any{"names": map[string]any{"en": "Foo"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(strings.ReplaceAll(tt.encoded, " ", ""))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, next, err := decoder{buffer: data}.decode(0, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next != len(data) {
+				t.Errorf("next = %d, want %d", next, len(data))
+			}
+			if uint128, ok := tt.want.(*big.Int); ok {
+				if got, ok := got.(*big.Int); !ok || got.Cmp(uint128) != 0 {
+					t.Errorf("value = %v, want %v", got, uint128)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("value = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePointer(t *testing.T) {
+	tests := []struct {
+		pointer string
+		want    int
+	}{
+		{"2000", 0},
+		{"27ff", 2047},
+		{"280000", 2048},
+		{"2fffff", 526335},
+		{"30000000", 526336},
+		{"37ffffff", 134744063},
+		{"3800000010", 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pointer, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.pointer)
+			got, next, err := decoder{buffer: data}.pointer(data[0], 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want || next != len(data) {
+				t.Errorf("pointer = %d, next %d, want %d, next %d", got, next, tt.want, len(data))
+			}
+		})
+	}
+
+	// A map whose value points back to the string of its key, followed by another value
+	data, _ := hex.DecodeString("e142656e2001" + "a1ff")
+	value, next, err := decoder{buffer: data}.decode(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(value, map[string]any{"en": "en"}) || next != 6 {
+		t.Errorf("value = %#v, next %d, want the key as value, next 6", value, next)
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	for name, encoded := range map[string]string{
+		"empty":              "",
+		"truncated string":   "43466f",
+		"truncated size":     "5e00",
+		"truncated extended": "00",
+		"truncated pointer":  "28",
+		"pointer loop":       "2000",
+		"double of 4 bytes":  "6400000000",
+		"uint64 of 9 bytes":  "0902000000000000000000",
+		"int32 of 5 bytes":   "05010000000000",
+		"key not a string":   "e1a10143466f6f",
+		"end marker":         "0006",
+		"container":          "0005",
+	} {
+		t.Run(name, func(t *testing.T) {
+			data, _ := hex.DecodeString(encoded)
+			if value, _, err := (decoder{buffer: data}).decode(0, 0); err == nil {
+				t.Errorf("decode = %#v, want an error", value)
+			}
+		})
+	}
+}
+
+func TestRecordSizes(t *testing.T) {
+	for _, tt := range []struct {
+		size        int
+		left, right int
+	}{
+		{24, 0xabcdef, 0x123456},
+		{28, 0xfabcdef, 0x1234567},
+		{32, 0xfabcdef0, 0x12345678},
+	} {
+		node := encodeNode(tt.size, tt.left, tt.right)
+		r := &Reader{Metadata: Metadata{RecordSize: tt.size, NodeCount: 1}, buffer: node, treeSize: len(node)}
+		left, err := r.record(0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		right, err := r.record(0, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if left != tt.left || right != tt.right {
+			t.Errorf("records of size %d = %#x, %#x, want %#x, %#x", tt.size, left, right, tt.left, tt.right)
+		}
+		if _, err := r.record(1, 0); !errors.Is(err, ErrInvalidDatabase) {
+			t.Errorf("record past the tree = %v, want ErrInvalidDatabase", err)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	city := encodeMap("city", "Lisbon")
+	country := encodeMap("country", "PT")
+	for _, ipVersion := range []int{4, 6} {
+		for _, recordSize := range []int{24, 28, 32} {
+			networks := map[string][]byte{
+				"81.84.0.0/14":    city,
+				"81.92.0.0/16":    country,
+				"192.168.1.42/32": country,
+			}
+			if ipVersion == 6 {
+				networks["2001:db8::/32"] = city
+			}
+			r, err := FromBytes(buildDatabase(t, ipVersion, recordSize, networks))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Metadata.DatabaseType != "Test-City" || r.Metadata.IPVersion != ipVersion || r.Metadata.RecordSize != recordSize || r.Metadata.BuildEpoch != 1700000000 {
+				t.Errorf("metadata = %+v", r.Metadata)
+			}
+
+			tests := []struct {
+				ip   string
+				want any
+			}{
+				{"81.84.1.1", map[string]any{"city": "Lisbon"}},
+				{"81.87.255.255", map[string]any{"city": "Lisbon"}},
+				{"81.92.10.1", map[string]any{"country": "PT"}},
+				{"::ffff:81.92.10.1", map[string]any{"country": "PT"}},
+				{"192.168.1.42", map[string]any{"country": "PT"}},
+				{"192.168.1.43", nil},
+				{"81.88.0.1", nil},
+				{"8.8.8.8", nil},
+				{"2001:db8::1", nil},
+			}
+			if ipVersion == 6 {
+				tests[len(tests)-1].want = map[string]any{"city": "Lisbon"}
+				tests = append(tests, struct {
+					ip   string
+					want any
+				}{"2001:db9::1", nil})
+			}
+			for _, tt := range tests {
+				got, err := r.Lookup(net.ParseIP(tt.ip))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("IPv%d database of %d-bit records: Lookup(%s) = %v, want %v", ipVersion, recordSize, tt.ip, got, tt.want)
+				}
+			}
+		}
+	}
+}
+
+func TestFromBytesInvalid(t *testing.T) {
+	valid := buildDatabase(t, 4, 24, map[string][]byte{"10.0.0.0/8": encodeMap("city", "Porto")})
+	unsupported := bytes.Replace(valid, []byte("record_size\xa1\x18"), []byte("record_size\xa1\x10"), 1)
+	if bytes.Equal(unsupported, valid) {
+		t.Fatal("record size not found in the metadata")
+	}
+	tests := map[string][]byte{
+		"no metadata":           valid[:bytes.LastIndex(valid, metadataMarker)],
+		"metadata not a map":    append(bytes.Clone(metadataMarker), 0x43, 'F', 'o', 'o'),
+		"unsupported record":    unsupported,
+		"tree beyond the file":  append(bytes.Clone(metadataMarker), encodeMetadata(4, 24, 1000)...),
+		"truncated metadata":    valid[:len(valid)-3],
+		"empty":                 nil,
+		"marker only":           bytes.Clone(metadataMarker),
+		"metadata of one value": append(bytes.Clone(metadataMarker), 0xe0),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if r, err := FromBytes(data); err == nil {
+				t.Errorf("FromBytes = %+v, want an error", r.Metadata)
+			}
+		})
+	}
+}
+
+// buildDatabase writes a database of the networks, mapped to their encoded data, following the
+// MaxMind DB format independently of the reader
+func buildDatabase(t *testing.T, ipVersion, recordSize int, networks map[string][]byte) []byte {
+	t.Helper()
+	const (
+		empty = -1
+		leaf  = -2
+	)
+	type record struct {
+		kind   int // Index of the next node, or empty or leaf
+		offset int // Offset of the data of leaves in the data section
+	}
+	nodes := [][2]record{{{kind: empty}, {kind: empty}}}
+	var data []byte
+	for cidr, value := range networks {
+		ip, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ones, _ := network.Mask.Size()
+		bits := []byte(ip.To4())
+		switch {
+		case ipVersion == 6 && bits != nil:
+			// The IPv4 addresses of IPv6 databases are in the ::/96 subtree
+			bits = append(make([]byte, 12), bits...)
+			ones += 96
+		case ipVersion == 6:
+			bits = ip.To16()
+		}
+		if ip.To4() == nil && ipVersion == 4 {
+			t.Fatalf("%s in an IPv4 database", cidr)
+		}
+
+		node := 0
+		for i := 0; i < ones; i++ {
+			bit := int(bits[i/8]>>(7-i%8)) & 1
+			if i == ones-1 {
+				nodes[node][bit] = record{kind: leaf, offset: len(data)}
+				break
+			}
+			if nodes[node][bit].kind == empty {
+				nodes = append(nodes, [2]record{{kind: empty}, {kind: empty}})
+				nodes[node][bit] = record{kind: len(nodes) - 1}
+			}
+			node = nodes[node][bit].kind
+		}
+		data = append(data, value...)
+	}
+
+	var db []byte
+	value := func(r record) int {
+		switch r.kind {
+		case empty:
+			return len(nodes)
+		case leaf:
+			return len(nodes) + dataSectionSeparator + r.offset
+		}
+		return r.kind
+	}
+	for _, node := range nodes {
+		db = append(db, encodeNode(recordSize, value(node[0]), value(node[1]))...)
+	}
+	db = append(db, make([]byte, dataSectionSeparator)...)
+	db = append(db, data...)
+	db = append(db, metadataMarker...)
+	return append(db, encodeMetadata(ipVersion, recordSize, len(nodes))...)
+}
+
+// encodeNode encodes the left and right records of a search tree node
+func encodeNode(recordSize, left, right int) []byte {
+	switch recordSize {
+	case 24:
+		return []byte{byte(left >> 16), byte(left >> 8), byte(left), byte(right >> 16), byte(right >> 8), byte(right)}
+	case 28:
+		return []byte{byte(left >> 16), byte(left >> 8), byte(left), byte(left>>24)<<4 | byte(right>>24), byte(right >> 16), byte(right >> 8), byte(right)}
+	}
+	return binary.BigEndian.AppendUint32(binary.BigEndian.AppendUint32(nil, uint32(left)), uint32(right))
+}
+
+func encodeMetadata(ipVersion, recordSize, nodeCount int) []byte {
+	meta := []byte{0xe5}
+	meta = append(meta, encodeString("database_type")...)
+	meta = append(meta, encodeString("Test-City")...)
+	meta = append(meta, encodeString("ip_version")...)
+	meta = append(meta, 0xa1, byte(ipVersion))
+	meta = append(meta, encodeString("record_size")...)
+	meta = append(meta, 0xa1, byte(recordSize))
+	meta = append(meta, encodeString("node_count")...)
+	meta = append(meta, 0xc4)
+	meta = binary.BigEndian.AppendUint32(meta, uint32(nodeCount))
+	meta = append(meta, encodeString("build_epoch")...)
+	meta = append(meta, 0x08, 0x02)
+	return binary.BigEndian.AppendUint64(meta, 1700000000)
+}
+
+// encodeMap encodes a map of a single string value
+func encodeMap(key, value string) []byte {
+	return append(append([]byte{0xe1}, encodeString(key)...), encodeString(value)...)
+}
+
+// encodeString encodes a string shorter than 29 bytes
+func encodeString(s string) []byte {
+	return append([]byte{0x40 | byte(len(s))}, s...)
+}
@@ -0,0 +1,272 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Route is a method and path registered on the router, such as a gin.RouteInfo
+type Route struct {
+	Method string
+	Path   string
+}
+
+// Build returns doc, a Swagger 2.0 document, updated to the running binary. Its paths are the
+// routes under its basePath: documented operations are kept and the others only get their path
+// parameters and a default response. The definitions of the struct types, and of the struct types
+// their fields refer to, are generated from their fields, keeping the documentation of the
+// properties whose type did not change.
+func Build(doc []byte, routes []Route, types ...any) ([]byte, error) {
+	var document map[string]any
+	if err := decodeInto(doc, &document); err != nil {
+		return nil, fmt.Errorf("parsing document: %w", err)
+	}
+	basePath, _ := document["basePath"].(string)
+	basePath = strings.TrimSuffix(basePath, "/")
+
+	documented, _ := document["paths"].(map[string]any)
+	paths := make(map[string]any)
+	for _, route := range routes {
+		if route.Path != basePath && !strings.HasPrefix(route.Path, basePath+"/") {
+			continue
+		}
+		path := pathOf(basePath, route.Path)
+		method := strings.ToLower(route.Method)
+		methods, ok := paths[path].(map[string]any)
+		if !ok {
+			methods = make(map[string]any)
+			paths[path] = methods
+		}
+		documentedMethods, _ := documented[path].(map[string]any)
+		if operation, ok := documentedMethods[method]; ok {
+			methods[method] = operation
+		} else {
+			methods[method] = undocumentedOperation(path)
+		}
+	}
+	document["paths"] = paths
+
+	definitions, ok := document["definitions"].(map[string]any)
+	if !ok {
+		definitions = make(map[string]any)
+	}
+	g := &generator{documented: definitions, generated: make(map[string]map[string]any)}
+	for _, value := range types {
+		g.schema(reflect.TypeOf(value))
+	}
+	for name, schema := range g.generated {
+		definitions[name] = schema
+	}
+	document["definitions"] = definitions
+
+	return json.Marshal(document)
+}
+
+// decodeInto reads JSON keeping numbers as json.Number, so examples are written back unchanged
+func decodeInto(data []byte, value any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(value)
+}
+
+// undocumentedOperation describes a route without Swagger annotations
+func undocumentedOperation(path string) map[string]any {
+	var parameters []any
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			parameters = append(parameters, map[string]any{
+				"name":     strings.TrimSuffix(name, "}"),
+				"in":       "path",
+				"required": true,
+				"type":     "string",
+			})
+		}
+	}
+	operation := map[string]any{
+		"summary":   "Undocumented",
+		"responses": map[string]any{"default": map[string]any{"description": "Not documented"}},
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+	return operation
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// generator builds the definitions of struct types the way swag names them, <package>.<type>
+type generator struct {
+	documented map[string]any
+	generated  map[string]map[string]any
+}
+
+// schema returns the schema of a value of type t, generating the definitions of the struct types
+// it refers to
+func (g *generator) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	if name := definitionName(t); name != "" {
+		if t.Kind() == reflect.Struct {
+			g.define(name, t)
+			return map[string]any{"$ref": "#/definitions/" + name}
+		}
+		// Named types with other kinds, such as enums of strings, keep their documented definition
+		if _, ok := g.documented[name]; ok {
+			return map[string]any{"$ref": "#/definitions/" + name}
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		return g.object(t, nil)
+	default:
+		return map[string]any{}
+	}
+}
+
+// define generates the definition of a named struct type once
+func (g *generator) define(name string, t reflect.Type) {
+	if _, ok := g.generated[name]; ok {
+		return
+	}
+	// Reserved first, so types referring to themselves terminate
+	g.generated[name] = map[string]any{}
+	documented, _ := g.documented[name].(map[string]any)
+	for key, value := range g.object(t, documented) {
+		g.generated[name][key] = value
+	}
+}
+
+// object returns the schema of the JSON fields of a struct type, keeping the description of the
+// documented schema and its properties of the same shape
+func (g *generator) object(t reflect.Type, documented map[string]any) map[string]any {
+	properties := make(map[string]any)
+	var required []string
+	g.fields(t, properties, &required)
+
+	documentedProperties, _ := documented["properties"].(map[string]any)
+	for name, property := range properties {
+		if previous, ok := documentedProperties[name]; ok && shapeOf(previous) == shapeOf(property) {
+			properties[name] = previous
+		}
+	}
+
+	schema := map[string]any{"type": "object"}
+	if description, ok := documented["description"]; ok {
+		schema["description"] = description
+	}
+	if len(properties) > 0 {
+		schema["properties"] = properties
+	}
+	if len(required) > 0 {
+		slices.Sort(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fields adds the properties encoding/json writes for the fields of a struct type, including the
+// fields of embedded structs, and collects those bound with binding:"required"
+func (g *generator) fields(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || field.Tag.Get("swaggerignore") == "true" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.fields(embedded, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		switch {
+		case field.Tag.Get("swaggertype") != "":
+			properties[name] = swaggerType(field.Tag.Get("swaggertype"))
+		case slices.Contains(strings.Split(options, ","), "string"):
+			properties[name] = map[string]any{"type": "string"}
+		default:
+			properties[name] = g.schema(field.Type)
+		}
+		if slices.Contains(strings.Split(field.Tag.Get("binding"), ","), "required") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// swaggerType returns the schema of a swaggertype tag, such as "string" or "array,string"
+func swaggerType(tag string) map[string]any {
+	parts := strings.Split(strings.TrimPrefix(tag, "primitive,"), ",")
+	if parts[0] == "array" && len(parts) > 1 {
+		return map[string]any{"type": "array", "items": map[string]any{"type": parts[1]}}
+	}
+	return map[string]any{"type": parts[0]}
+}
+
+// definitionName returns the name swag gives the definition of a named type, or "" for unnamed types
+func definitionName(t reflect.Type) string {
+	if t.Name() == "" || t.PkgPath() == "" {
+		return ""
+	}
+	return path.Base(t.PkgPath()) + "." + t.Name()
+}
+
+// shapeOf describes the type of a schema, ignoring its documentation: a reference, written as a
+// $ref or an allOf of one $ref, or a type with the shape of its items or values
+func shapeOf(value any) string {
+	schema, _ := value.(map[string]any)
+	if ref, ok := schema["$ref"].(string); ok {
+		return ref
+	}
+	if allOf, ok := schema["allOf"].([]any); ok && len(allOf) == 1 {
+		return shapeOf(allOf[0])
+	}
+	shape, _ := schema["type"].(string)
+	switch shape {
+	case "array":
+		shape += "[" + shapeOf(schema["items"]) + "]"
+	case "object":
+		if additional, ok := schema["additionalProperties"]; ok {
+			shape += "{" + shapeOf(additional) + "}"
+		}
+	}
+	return shape
+}
@@ -0,0 +1,416 @@
+// Package openapi checks HTTP requests and responses against a Swagger 2.0 document, such as the one
+// swag generates in package docs, so the documentation cannot silently drift from the handlers, and
+// updates such a document to the routes and types of the running binary with Build.
+//
+// Schemas are checked for the types, properties, required properties, items, enums, allOf and
+// additionalProperties of their objects; formats and numeric or length bounds are not checked.
+// Objects may not hold properties their schema does not document, unless it sets additionalProperties.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrUndocumented is returned for the routes, methods and statuses the document does not describe
+var ErrUndocumented = errors.New("undocumented")
+
+// Spec is a parsed Swagger 2.0 document
+type Spec struct {
+	BasePath    string                           `json:"basePath"`
+	Paths       map[string]map[string]*Operation `json:"paths"` // Path to lower-case method
+	Definitions map[string]*Schema               `json:"definitions"`
+
+	// Shared are the responses any operation may return although it does not document them, such
+	// as the 429 of rate limits, by status; nil schemas accept any body
+	Shared map[int]*Schema `json:"-"`
+}
+
+// Operation is the documentation of a method of a path
+type Operation struct {
+	Consumes   []string            `json:"consumes"`
+	Produces   []string            `json:"produces"`
+	Parameters []*Parameter        `json:"parameters"`
+	Responses  map[string]Response `json:"responses"`
+}
+
+// Parameter is a path, query, header, body or form parameter of an operation
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Type     string  `json:"type"`
+	Enum     []any   `json:"enum"`
+	Items    *Schema `json:"items"`
+	Schema   *Schema `json:"schema"` // Body parameters only
+}
+
+// Response is a documented response of an operation
+type Response struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a JSON schema of the document
+type Schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 string             `json:"type"`
+	Properties           map[string]*Schema `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *Schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	AllOf                []*Schema          `json:"allOf"`
+	AdditionalProperties *Additional        `json:"additionalProperties"`
+}
+
+// Additional is the additionalProperties of a schema, either a boolean or the schema of the values
+type Additional struct {
+	Allowed bool
+	Schema  *Schema
+}
+
+func (a *Additional) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &a.Allowed); err == nil {
+		return nil
+	}
+	a.Allowed = true
+	return json.Unmarshal(data, &a.Schema)
+}
+
+// Load parses a Swagger 2.0 document
+func Load(doc []byte) (*Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal(doc, &spec); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI document: %w", err)
+	}
+	return &spec, nil
+}
+
+// Definition returns the schema referenced by #/definitions/<name>, or nil
+func (s *Spec) Definition(name string) *Schema {
+	return s.Definitions[name]
+}
+
+// PathOf turns a gin route such as /api/v1/users/:id into its path in the document, /users/{id}
+func (s *Spec) PathOf(route string) string {
+	return pathOf(s.BasePath, route)
+}
+
+func pathOf(basePath, route string) string {
+	path := strings.TrimPrefix(route, strings.TrimSuffix(basePath, "/"))
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		} else if name, ok := strings.CutPrefix(segment, "*"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Operation returns the documentation of a method of a gin route
+func (s *Spec) Operation(method, route string) (*Operation, error) {
+	path := s.PathOf(route)
+	methods, ok := s.Paths[path]
+	if !ok {
+		return nil, fmt.Errorf("path %s: %w", path, ErrUndocumented)
+	}
+	op, ok := methods[strings.ToLower(method)]
+	if !ok {
+		return nil, fmt.Errorf("%s %s: %w", method, path, ErrUndocumented)
+	}
+	return op, nil
+}
+
+// ValidateRequest checks the query, header and body parameters of a request to a gin route; body
+// is the request body, which the caller reads as it is consumed by the handler
+func (s *Spec) ValidateRequest(route string, r *http.Request, body []byte) error {
+	op, err := s.Operation(r.Method, route)
+	if err != nil {
+		return err
+	}
+
+	v := &validator{spec: s}
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "query":
+			values, ok := query[param.Name]
+			if !ok {
+				if param.Required {
+					v.fail("query "+param.Name, "is required")
+				}
+				continue
+			}
+			for _, value := range values {
+				v.parameter("query "+param.Name, param, value)
+			}
+		case "header":
+			value := r.Header.Get(param.Name)
+			if value == "" {
+				if param.Required {
+					v.fail("header "+param.Name, "is required")
+				}
+				continue
+			}
+			v.parameter("header "+param.Name, param, value)
+		case "body":
+			if len(bytes.TrimSpace(body)) == 0 {
+				if param.Required {
+					v.fail("request body", "is required")
+				}
+				continue
+			}
+			if mediaType(r.Header.Get("Content-Type")) != "application/json" {
+				continue
+			}
+			value, err := decode(body)
+			if err != nil {
+				v.fail("request body", "is not valid JSON: "+err.Error())
+				continue
+			}
+			v.schema("request body", param.Schema, value)
+		}
+		// Path parameters are matched by the router; form fields are read by the handlers
+	}
+	return v.err()
+}
+
+// ValidateResponse checks that the status of a response to a gin route is documented and that its
+// JSON body matches the documented schema
+func (s *Spec) ValidateResponse(method, route string, status int, header http.Header, body []byte) error {
+	op, err := s.Operation(method, route)
+	if err != nil {
+		return err
+	}
+
+	var schema *Schema
+	if resp, ok := op.Responses[strconv.Itoa(status)]; ok {
+		schema = resp.Schema
+	} else if shared, ok := s.Shared[status]; ok {
+		schema = shared
+	} else if resp, ok := op.Responses["default"]; ok {
+		schema = resp.Schema
+	} else {
+		return fmt.Errorf("%s %s status %d: %w", method, s.PathOf(route), status, ErrUndocumented)
+	}
+
+	if len(body) == 0 {
+		return nil
+	}
+	v := &validator{spec: s}
+	contentType := mediaType(header.Get("Content-Type"))
+	if len(op.Produces) > 0 && !slices.Contains(op.Produces, contentType) {
+		v.fail("response Content-Type", fmt.Sprintf("%s is not one of %s", contentType, strings.Join(op.Produces, ", ")))
+	}
+	if schema != nil && contentType == "application/json" {
+		value, err := decode(body)
+		if err != nil {
+			v.fail("response body", "is not valid JSON: "+err.Error())
+		} else {
+			v.schema("response body", schema, value)
+		}
+	}
+	return v.err()
+}
+
+func mediaType(contentType string) string {
+	media, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return contentType
+	}
+	return media
+}
+
+// decode reads JSON keeping numbers as json.Number, so integers can be told from floats
+func decode(body []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// validator collects the violations found in a request or response
+type validator struct {
+	spec   *Spec
+	failed []string
+}
+
+func (v *validator) fail(at, problem string) {
+	v.failed = append(v.failed, at+" "+problem)
+}
+
+func (v *validator) err() error {
+	if len(v.failed) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(v.failed, "; "))
+}
+
+// parameter checks a query or header value against the type and enum of its parameter
+func (v *validator) parameter(at string, param *Parameter, value string) {
+	if param.Type == "array" && param.Items != nil {
+		// swag documents repeated parameters (?tag=a&tag=b) as arrays of their items
+		v.parameter(at, &Parameter{Type: param.Items.Type, Enum: param.Items.Enum}, value)
+		return
+	}
+	switch param.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			v.fail(at, fmt.Sprintf("%q is not an integer", value))
+			return
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			v.fail(at, fmt.Sprintf("%q is not a number", value))
+			return
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(value); err != nil {
+			v.fail(at, fmt.Sprintf("%q is not a boolean", value))
+			return
+		}
+	}
+	if len(param.Enum) > 0 && !slices.ContainsFunc(param.Enum, func(e any) bool { return fmt.Sprint(e) == value }) {
+		v.fail(at, fmt.Sprintf("%q is not one of %v", value, param.Enum))
+	}
+}
+
+// schema checks a decoded JSON value. Null is accepted for any value, the way Go encodes nil
+// pointers, slices and maps.
+func (v *validator) schema(at string, schema *Schema, value any) {
+	if schema == nil || value == nil {
+		return
+	}
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/definitions/")
+		def := v.spec.Definition(name)
+		if def == nil {
+			v.fail(at, "references the undefined schema "+name)
+			return
+		}
+		v.schema(at, def, value)
+		return
+	}
+	if len(schema.AllOf) > 0 {
+		v.allOf(at, schema, value)
+		return
+	}
+
+	switch schema.Type {
+	case "string":
+		if _, ok := value.(string); !ok {
+			v.fail(at, "is not a string")
+			return
+		}
+	case "integer":
+		n, ok := value.(json.Number)
+		if _, err := n.Int64(); !ok || err != nil {
+			v.fail(at, "is not an integer")
+			return
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			v.fail(at, "is not a number")
+			return
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(at, "is not a boolean")
+			return
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			v.fail(at, "is not an array")
+			return
+		}
+		for i, item := range items {
+			v.schema(at+"["+strconv.Itoa(i)+"]", schema.Items, item)
+		}
+	case "object", "":
+		object, ok := value.(map[string]any)
+		if !ok {
+			if schema.Type == "object" {
+				v.fail(at, "is not an object")
+			}
+			return
+		}
+		v.object(at, schema, object)
+	}
+
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(value) }) {
+		v.fail(at, fmt.Sprintf("%v is not one of %v", value, schema.Enum))
+	}
+}
+
+// object checks the properties of an object; objects without documented properties or
+// additionalProperties, such as map[string]any, accept any property
+func (v *validator) object(at string, schema *Schema, object map[string]any, known ...string) {
+	for _, name := range schema.Required {
+		if _, ok := object[name]; !ok {
+			v.fail(at+"."+name, "is required")
+		}
+	}
+
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if prop, ok := schema.Properties[key]; ok {
+			v.schema(at+"."+key, prop, object[key])
+			continue
+		}
+		switch {
+		case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+			v.schema(at+"."+key, schema.AdditionalProperties.Schema, object[key])
+		case schema.AdditionalProperties != nil && schema.AdditionalProperties.Allowed,
+			schema.AdditionalProperties == nil && len(schema.Properties) == 0,
+			slices.Contains(known, key):
+		default:
+			v.fail(at+"."+key, "is not documented")
+		}
+	}
+}
+
+// allOf checks a value against every schema of an allOf, where the properties of one schema are
+// not undocumented for the others
+func (v *validator) allOf(at string, schema *Schema, value any) {
+	var known []string
+	var parts []*Schema
+	for _, part := range schema.AllOf {
+		for part != nil && part.Ref != "" {
+			part = v.spec.Definition(strings.TrimPrefix(part.Ref, "#/definitions/"))
+		}
+		if part == nil {
+			v.fail(at, "references an undefined schema")
+			return
+		}
+		parts = append(parts, part)
+		for name := range part.Properties {
+			known = append(known, name)
+		}
+	}
+
+	object, ok := value.(map[string]any)
+	for _, part := range parts {
+		if ok && (part.Type == "object" || part.Type == "") {
+			v.object(at, part, object, known...)
+		} else {
+			v.schema(at, part, value)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Thrift compact protocol types
+const (
+	compactTrue   = 1
+	compactFalse  = 2
+	compactI32    = 5
+	compactI64    = 6
+	compactBinary = 8
+	compactList   = 9
+	compactStruct = 12
+)
+
+// compactEncoder writes the Thrift compact protocol encoding of the Parquet metadata structures
+type compactEncoder struct {
+	buf bytes.Buffer
+	// lastIDs holds the ID of the last field written in each open struct, as field IDs are delta encoded
+	lastIDs []int16
+}
+
+func newCompactEncoder() *compactEncoder {
+	return &compactEncoder{lastIDs: []int16{0}}
+}
+
+func (e *compactEncoder) field(id int16, kind byte) {
+	last := &e.lastIDs[len(e.lastIDs)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		e.buf.WriteByte(byte(delta)<<4 | kind)
+	} else {
+		e.buf.WriteByte(kind)
+		e.varint(int64(id))
+	}
+	*last = id
+}
+
+func (e *compactEncoder) varint(v int64) {
+	e.buf.Write(binary.AppendUvarint(nil, uint64(v<<1^v>>63)))
+}
+
+func (e *compactEncoder) uvarint(v uint64) {
+	e.buf.Write(binary.AppendUvarint(nil, v))
+}
+
+func (e *compactEncoder) i32(id int16, v int32) {
+	e.field(id, compactI32)
+	e.varint(int64(v))
+}
+
+func (e *compactEncoder) i64(id int16, v int64) {
+	e.field(id, compactI64)
+	e.varint(v)
+}
+
+func (e *compactEncoder) bool(id int16, v bool) {
+	if v {
+		e.field(id, compactTrue)
+	} else {
+		e.field(id, compactFalse)
+	}
+}
+
+func (e *compactEncoder) string(id int16, v string) {
+	e.field(id, compactBinary)
+	e.rawString(v)
+}
+
+func (e *compactEncoder) rawString(v string) {
+	e.uvarint(uint64(len(v)))
+	e.buf.WriteString(v)
+}
+
+// list writes the header of a list field of size elements
+func (e *compactEncoder) list(id int16, kind byte, size int) {
+	e.field(id, compactList)
+	if size < 15 {
+		e.buf.WriteByte(byte(size)<<4 | kind)
+	} else {
+		e.buf.WriteByte(0xF0 | kind)
+		e.uvarint(uint64(size))
+	}
+}
+
+func (e *compactEncoder) i32List(id int16, values ...int32) {
+	e.list(id, compactI32, len(values))
+	for _, v := range values {
+		e.varint(int64(v))
+	}
+}
+
+func (e *compactEncoder) stringList(id int16, values ...string) {
+	e.list(id, compactBinary, len(values))
+	for _, v := range values {
+		e.rawString(v)
+	}
+}
+
+// structField starts a struct value of field id, closed by end
+func (e *compactEncoder) structField(id int16) {
+	e.field(id, compactStruct)
+	e.begin()
+}
+
+// begin starts a struct value, such as a list element or the top-level structure
+func (e *compactEncoder) begin() {
+	e.lastIDs = append(e.lastIDs, 0)
+}
+
+// end writes the stop byte of the current struct
+func (e *compactEncoder) end() {
+	e.buf.WriteByte(0)
+	e.lastIDs = e.lastIDs[:len(e.lastIDs)-1]
+}
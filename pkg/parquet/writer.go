@@ -0,0 +1,387 @@
+// Package parquet provides a minimal streaming writer of Apache Parquet files with a flat schema of
+// optional columns, following https://parquet.apache.org/docs/file-format/. Each row group holds one
+// GZIP-compressed PLAIN data page per column, readable by Spark, pandas/pyarrow, DuckDB and the
+// warehouse loaders of BigQuery, Snowflake and Redshift.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// magic starts and ends every Parquet file
+const magic = "PAR1"
+
+// DefaultRowGroupSize is the number of rows buffered before a row group is written
+const DefaultRowGroupSize = 10000
+
+// Type is the type of the values of a column
+type Type int
+
+const (
+	String    Type = iota // UTF-8 string, written from string values
+	Int64                 // 64-bit signed integer, written from int64 values
+	Bool                  // Boolean, written from bool values
+	Timestamp             // UTC timestamp in milliseconds, written from time.Time values
+)
+
+// Column describes a column of the file; every column is optional (nullable)
+type Column struct {
+	Name string
+	Type Type
+}
+
+// Parquet physical types, converted types, encodings and codecs used by the writer
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalByteArray = 6
+
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+
+	encodingPlain = 0
+	encodingRLE   = 3
+
+	codecGzip       = 2
+	pageTypeData    = 0
+	repetitionOpt   = 1
+	fileMetaVersion = 1
+)
+
+var ErrClosed = errors.New("parquet: writer is closed")
+
+// Writer writes rows to a Parquet file, flushing a row group every RowGroupSize rows.
+// Close must be called to write the file footer.
+type Writer struct {
+	// RowGroupSize is the number of rows per row group, DefaultRowGroupSize when zero
+	RowGroupSize int
+	// CreatedBy is recorded in the file metadata
+	CreatedBy string
+
+	w         io.Writer
+	columns   []Column
+	chunks    []columnBuffer
+	rows      int
+	totalRows int64
+	offset    int64
+	groups    []rowGroup
+	closed    bool
+}
+
+// columnBuffer holds the encoded values of a column for the current row group
+type columnBuffer struct {
+	defined []bool
+	values  bytes.Buffer
+	bools   []bool
+}
+
+type rowGroup struct {
+	rows     int64
+	size     int64
+	metadata []columnChunk
+}
+
+type columnChunk struct {
+	offset           int64
+	numValues        int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+// NewWriter returns a writer of a file with the given columns; the file header is written on the first row group
+func NewWriter(w io.Writer, columns []Column) (*Writer, error) {
+	if len(columns) == 0 {
+		return nil, errors.New("parquet: at least one column is required")
+	}
+	for _, column := range columns {
+		if column.Name == "" || column.Type < String || column.Type > Timestamp {
+			return nil, fmt.Errorf("parquet: invalid column %q", column.Name)
+		}
+	}
+	return &Writer{
+		w:       w,
+		columns: columns,
+		chunks:  make([]columnBuffer, len(columns)),
+	}, nil
+}
+
+// Write appends a row holding one value per column; nil values are written as nulls
+func (w *Writer) Write(row []any) error {
+	if w.closed {
+		return ErrClosed
+	}
+	if len(row) != len(w.columns) {
+		return fmt.Errorf("parquet: row has %d values, want %d", len(row), len(w.columns))
+	}
+	for i, value := range row {
+		if err := w.chunks[i].append(w.columns[i], value); err != nil {
+			return err
+		}
+	}
+	w.rows++
+
+	size := w.RowGroupSize
+	if size <= 0 {
+		size = DefaultRowGroupSize
+	}
+	if w.rows >= size {
+		return w.Flush()
+	}
+	return nil
+}
+
+func (c *columnBuffer) append(column Column, value any) error {
+	if value == nil {
+		c.defined = append(c.defined, false)
+		return nil
+	}
+
+	switch column.Type {
+	case String:
+		v, ok := value.(string)
+		if !ok {
+			return typeError(column, value)
+		}
+		c.values.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(v))))
+		c.values.WriteString(v)
+	case Int64:
+		v, ok := value.(int64)
+		if !ok {
+			return typeError(column, value)
+		}
+		c.values.Write(binary.LittleEndian.AppendUint64(nil, uint64(v)))
+	case Bool:
+		v, ok := value.(bool)
+		if !ok {
+			return typeError(column, value)
+		}
+		c.bools = append(c.bools, v)
+	case Timestamp:
+		v, ok := value.(time.Time)
+		if !ok {
+			return typeError(column, value)
+		}
+		c.values.Write(binary.LittleEndian.AppendUint64(nil, uint64(v.UnixMilli())))
+	}
+	c.defined = append(c.defined, true)
+	return nil
+}
+
+func typeError(column Column, value any) error {
+	return fmt.Errorf("parquet: unexpected %T value for column %q", value, column.Name)
+}
+
+// Flush writes the buffered rows as a row group
+func (w *Writer) Flush() error {
+	if w.closed {
+		return ErrClosed
+	}
+	if w.rows == 0 {
+		return nil
+	}
+	if w.offset == 0 {
+		if err := w.write([]byte(magic)); err != nil {
+			return err
+		}
+	}
+
+	group := rowGroup{rows: int64(w.rows), metadata: make([]columnChunk, len(w.columns))}
+	for i := range w.chunks {
+		chunk, err := w.writeChunk(&w.chunks[i])
+		if err != nil {
+			return err
+		}
+		group.metadata[i] = chunk
+		group.size += chunk.uncompressedSize
+		w.chunks[i] = columnBuffer{}
+	}
+	w.groups = append(w.groups, group)
+	w.totalRows += int64(w.rows)
+	w.rows = 0
+	return nil
+}
+
+// writeChunk writes the column chunk of a row group as a single data page
+func (w *Writer) writeChunk(c *columnBuffer) (columnChunk, error) {
+	var page bytes.Buffer
+	levels := bitPackedRun(c.defined)
+	page.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(levels))))
+	page.Write(levels)
+	if c.bools != nil {
+		page.Write(packBits(c.bools))
+	} else {
+		page.Write(c.values.Bytes())
+	}
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	if _, err := gz.Write(page.Bytes()); err != nil {
+		return columnChunk{}, err
+	}
+	if err := gz.Close(); err != nil {
+		return columnChunk{}, err
+	}
+	if page.Len() > math.MaxInt32 || compressed.Len() > math.MaxInt32 {
+		return columnChunk{}, errors.New("parquet: page too large, lower the row group size")
+	}
+
+	header := newCompactEncoder()
+	header.i32(1, pageTypeData)
+	header.i32(2, int32(page.Len()))
+	header.i32(3, int32(compressed.Len()))
+	header.structField(5)
+	header.i32(1, int32(len(c.defined)))
+	header.i32(2, encodingPlain)
+	header.i32(3, encodingRLE)
+	header.i32(4, encodingRLE)
+	header.end()
+	header.buf.WriteByte(0)
+
+	chunk := columnChunk{
+		offset:           w.offset,
+		numValues:        int64(len(c.defined)),
+		uncompressedSize: int64(header.buf.Len() + page.Len()),
+		compressedSize:   int64(header.buf.Len() + compressed.Len()),
+	}
+	if err := w.write(header.buf.Bytes()); err != nil {
+		return columnChunk{}, err
+	}
+	if err := w.write(compressed.Bytes()); err != nil {
+		return columnChunk{}, err
+	}
+	return chunk, nil
+}
+
+// Close flushes the buffered rows and writes the file metadata; it does not close the underlying writer
+func (w *Writer) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	w.closed = true
+	if w.offset == 0 {
+		if err := w.write([]byte(magic)); err != nil {
+			return err
+		}
+	}
+
+	meta := w.fileMetadata()
+	if err := w.write(meta); err != nil {
+		return err
+	}
+	if err := w.write(binary.LittleEndian.AppendUint32(nil, uint32(len(meta)))); err != nil {
+		return err
+	}
+	return w.write([]byte(magic))
+}
+
+func (w *Writer) fileMetadata() []byte {
+	e := newCompactEncoder()
+	e.i32(1, fileMetaVersion)
+
+	e.list(2, compactStruct, len(w.columns)+1)
+	e.begin()
+	e.string(4, "schema")
+	e.i32(5, int32(len(w.columns)))
+	e.end()
+	for _, column := range w.columns {
+		e.begin()
+		e.i32(1, physicalType(column.Type))
+		e.i32(3, repetitionOpt)
+		e.string(4, column.Name)
+		switch column.Type {
+		case String:
+			e.i32(6, convertedUTF8)
+		case Timestamp:
+			e.i32(6, convertedTimestampMillis)
+			// TIMESTAMP logical type, adjusted to UTC, in milliseconds
+			e.structField(10)
+			e.structField(8)
+			e.bool(1, true)
+			e.structField(2)
+			e.structField(1)
+			e.end()
+			e.end()
+			e.end()
+			e.end()
+		}
+		e.end()
+	}
+
+	e.i64(3, w.totalRows)
+
+	e.list(4, compactStruct, len(w.groups))
+	for _, group := range w.groups {
+		e.begin()
+		e.list(1, compactStruct, len(group.metadata))
+		for i, chunk := range group.metadata {
+			column := w.columns[i]
+			e.begin()
+			e.i64(2, chunk.offset)
+			e.structField(3)
+			e.i32(1, physicalType(column.Type))
+			e.i32List(2, encodingPlain, encodingRLE)
+			e.stringList(3, column.Name)
+			e.i32(4, codecGzip)
+			e.i64(5, chunk.numValues)
+			e.i64(6, chunk.uncompressedSize)
+			e.i64(7, chunk.compressedSize)
+			e.i64(9, chunk.offset)
+			e.end()
+			e.end()
+		}
+		e.i64(2, group.size)
+		e.i64(3, group.rows)
+		e.end()
+	}
+
+	if w.CreatedBy != "" {
+		e.string(6, w.CreatedBy)
+	}
+	e.buf.WriteByte(0)
+	return e.buf.Bytes()
+}
+
+func physicalType(t Type) int32 {
+	switch t {
+	case Int64, Timestamp:
+		return physicalInt64
+	case Bool:
+		return physicalBoolean
+	}
+	return physicalByteArray
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+// bitPackedRun encodes definition levels of bit width 1 as a single bit-packed run of the
+// RLE/bit-packing hybrid encoding
+func bitPackedRun(values []bool) []byte {
+	groups := (len(values) + 7) / 8
+	run := binary.AppendUvarint(nil, uint64(groups)<<1|1)
+	return append(run, packBits(values)...)
+}
+
+// packBits packs booleans LSB first, padding the last byte with zeros
+func packBits(values []bool) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
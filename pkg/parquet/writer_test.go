@@ -0,0 +1,338 @@
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompactEncoder(t *testing.T) {
+	e := newCompactEncoder()
+	e.i32(1, 1)       // Delta 1, zigzag 2
+	e.i32(2, -1)      // Delta 1, zigzag 1
+	e.i64(20, 300)    // Delta 18 writes the zigzag ID 40, then zigzag 600
+	e.string(21, "a") // Delta 1 and a length prefix
+	e.bool(22, true)  // The value is in the type
+	e.bool(23, false)
+	e.list(24, compactI32, 15) // 15 elements and more take a varint size
+	e.structField(25)
+	e.i32(1, 0) // IDs restart in each struct
+	e.end()
+	e.i32List(26, 1, 2)
+	e.buf.WriteByte(0)
+
+	want := "1502" + "1501" + "0628d804" + "180161" + "11" + "12" + "19f50f" + "1c" + "150000" + "192502" + "04" + "00"
+	if got := hex.EncodeToString(e.buf.Bytes()); got != want {
+		t.Errorf("encoding = %s, want %s", got, want)
+	}
+}
+
+var testColumns = []Column{
+	{Name: "id", Type: String},
+	{Name: "logins", Type: Int64},
+	{Name: "verified", Type: Bool},
+	{Name: "created_at", Type: Timestamp},
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 0, 123e6, time.UTC)
+	rows := [][]any{
+		{"u1", int64(3), true, created},
+		{"ü-2", nil, false, nil},
+		{nil, int64(-1 << 40), nil, created.Add(time.Hour)},
+		{"", int64(0), true, time.UnixMilli(0).UTC()},
+		{"u5", nil, nil, nil},
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.RowGroupSize = 2
+	w.CreatedBy = "user-management-api"
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	meta := readFooter(t, buf.Bytes())
+	if meta[1] != int64(fileMetaVersion) || meta[3] != int64(len(rows)) || meta[6] != "user-management-api" {
+		t.Errorf("metadata = %v", meta)
+	}
+	schema := meta[2].([]any)
+	if len(schema) != len(testColumns)+1 || schema[0].(map[int16]any)[5] != int64(len(testColumns)) {
+		t.Fatalf("schema = %v", schema)
+	}
+	for i, column := range testColumns {
+		element := schema[i+1].(map[int16]any)
+		if element[4] != column.Name || element[1] != int64(physicalType(column.Type)) || element[3] != int64(repetitionOpt) {
+			t.Errorf("schema of %s = %v", column.Name, element)
+		}
+	}
+	timestamp := schema[4].(map[int16]any)[10].(map[int16]any)[8].(map[int16]any)
+	if timestamp[1] != true || timestamp[2].(map[int16]any)[1] == nil {
+		t.Errorf("logical type of created_at = %v, want a UTC timestamp in milliseconds", timestamp)
+	}
+
+	groups := meta[4].([]any)
+	if len(groups) != 3 {
+		t.Fatalf("row groups = %d, want 3", len(groups))
+	}
+	var got [][]any
+	for _, g := range groups {
+		group := g.(map[int16]any)
+		numRows := int(group[3].(int64))
+		columns := make([][]any, len(testColumns))
+		var size int64
+		for i, c := range group[1].([]any) {
+			chunk := c.(map[int16]any)[3].(map[int16]any)
+			if chunk[3].([]any)[0] != testColumns[i].Name || chunk[4] != int64(codecGzip) || chunk[5] != int64(numRows) {
+				t.Errorf("column chunk = %v", chunk)
+			}
+			columns[i] = readChunk(t, buf.Bytes(), chunk, testColumns[i].Type)
+			size += chunk[6].(int64)
+		}
+		if group[2] != size {
+			t.Errorf("total byte size = %v, want %d", group[2], size)
+		}
+		for r := range numRows {
+			row := make([]any, len(testColumns))
+			for i := range columns {
+				row[i] = columns[i][r]
+			}
+			got = append(got, row)
+		}
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("rows = %v, want %v", got, rows)
+	}
+}
+
+func TestWriterWithoutRows(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, testColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	meta := readFooter(t, buf.Bytes())
+	if meta[3] != int64(0) || len(meta[4].([]any)) != 0 || len(meta[2].([]any)) != len(testColumns)+1 {
+		t.Errorf("metadata = %v", meta)
+	}
+	if err := w.Write([]any{"u1", nil, nil, nil}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Write after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestWriterRejectsInvalidRows(t *testing.T) {
+	if _, err := NewWriter(io.Discard, nil); err == nil {
+		t.Error("NewWriter without columns succeeded")
+	}
+	if _, err := NewWriter(io.Discard, []Column{{Name: "x", Type: Timestamp + 1}}); err == nil {
+		t.Error("NewWriter with an unknown type succeeded")
+	}
+	w, err := NewWriter(io.Discard, testColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range [][]any{
+		{"u1"},
+		{1, nil, nil, nil},
+		{nil, 3, nil, nil},
+		{nil, nil, "true", nil},
+		{nil, nil, nil, "2024-03-01"},
+	} {
+		if err := w.Write(row); err == nil {
+			t.Errorf("Write(%v) succeeded", row)
+		}
+	}
+}
+
+// readFooter checks the magic numbers framing data and decodes its file metadata
+func readFooter(t *testing.T, data []byte) map[int16]any {
+	t.Helper()
+	if len(data) < 12 || string(data[:4]) != magic || string(data[len(data)-4:]) != magic {
+		t.Fatalf("file is not framed by %s", magic)
+	}
+	length := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	r := &compactReader{data: data[len(data)-8-length : len(data)-8]}
+	meta := r.readStruct()
+	if r.err != nil || r.pos != length {
+		t.Fatalf("metadata: %v after %d of %d bytes", r.err, r.pos, length)
+	}
+	return meta
+}
+
+// readChunk decodes the values of the single data page of a column chunk, nil for nulls
+func readChunk(t *testing.T, data []byte, chunk map[int16]any, typ Type) []any {
+	t.Helper()
+	offset := chunk[9].(int64)
+	r := &compactReader{data: data[offset:]}
+	header := r.readStruct()
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if int64(r.pos)+header[3].(int64) != chunk[7].(int64) || int64(r.pos)+header[2].(int64) != chunk[6].(int64) {
+		t.Fatalf("sizes of page %v do not add up to those of chunk %v", header, chunk)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data[offset+int64(r.pos) : offset+chunk[7].(int64)]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	page, err := io.ReadAll(gz)
+	if err != nil || int64(len(page)) != header[2].(int64) {
+		t.Fatalf("page of %d bytes: %v", len(page), err)
+	}
+
+	dataPage := header[5].(map[int16]any)
+	numValues := int(dataPage[1].(int64))
+	levelsLength := int(binary.LittleEndian.Uint32(page))
+	levels := page[4 : 4+levelsLength]
+	runHeader, n := binary.Uvarint(levels)
+	if runHeader&1 != 1 || int(runHeader>>1) != (numValues+7)/8 {
+		t.Fatalf("definition levels run header = %d for %d values", runHeader, numValues)
+	}
+	defined := unpackBits(levels[n:], numValues)
+	values := page[4+levelsLength:]
+
+	column := make([]any, numValues)
+	var bools []bool
+	if typ == Bool {
+		count := 0
+		for _, d := range defined {
+			if d {
+				count++
+			}
+		}
+		bools = unpackBits(values, count)
+	}
+	for i, d := range defined {
+		if !d {
+			continue
+		}
+		switch typ {
+		case String:
+			length := binary.LittleEndian.Uint32(values)
+			column[i] = string(values[4 : 4+length])
+			values = values[4+length:]
+		case Int64:
+			column[i] = int64(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case Timestamp:
+			column[i] = time.UnixMilli(int64(binary.LittleEndian.Uint64(values))).UTC()
+			values = values[8:]
+		case Bool:
+			column[i], bools = bools[0], bools[1:]
+		}
+	}
+	if typ != Bool && len(values) != 0 {
+		t.Errorf("%d bytes left after the values", len(values))
+	}
+	return column
+}
+
+func unpackBits(packed []byte, n int) []bool {
+	values := make([]bool, n)
+	for i := range values {
+		values[i] = packed[i/8]&(1<<(i%8)) != 0
+	}
+	return values
+}
+
+// compactReader decodes the Thrift compact protocol into maps of field IDs, independently of the
+// encoder, see https://github.com/apache/thrift/blob/master/doc/specs/thrift-compact-protocol.md
+type compactReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (r *compactReader) byte() byte {
+	if r.pos >= len(r.data) {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	b := r.data[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *compactReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.data[min(r.pos, len(r.data)):])
+	if n <= 0 {
+		r.err = io.ErrUnexpectedEOF
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *compactReader) varint() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *compactReader) readStruct() map[int16]any {
+	fields := map[int16]any{}
+	var id int16
+	for r.err == nil {
+		header := r.byte()
+		if header == 0 {
+			break
+		}
+		if delta := int16(header >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.varint())
+		}
+		fields[id] = r.readValue(header & 0x0f)
+	}
+	return fields
+}
+
+func (r *compactReader) readValue(kind byte) any {
+	switch kind {
+	case compactTrue:
+		return true
+	case compactFalse:
+		return false
+	case compactI32, compactI64:
+		return r.varint()
+	case compactBinary:
+		length := int(r.uvarint())
+		if r.pos+length > len(r.data) {
+			r.err = io.ErrUnexpectedEOF
+			return nil
+		}
+		r.pos += length
+		return string(r.data[r.pos-length : r.pos])
+	case compactList:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]any, 0, size)
+		for range size {
+			list = append(list, r.readValue(header&0x0f))
+		}
+		return list
+	case compactStruct:
+		return r.readStruct()
+	}
+	r.err = errors.New("unknown compact type")
+	return nil
+}
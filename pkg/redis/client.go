@@ -0,0 +1,280 @@
+// Package redis provides a minimal Redis client speaking the RESP protocol, with connection pooling.
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNil is returned by Do when Redis replies with a nil value
+var ErrNil = errors.New("redis: nil reply")
+
+// Error is an error reply sent by the Redis server
+type Error string
+
+func (e Error) Error() string { return string(e) }
+
+// Options configures a Client
+type Options struct {
+	Addr        string // host:port
+	Username    string // ACL user (Redis 6+), empty for the default user
+	Password    string
+	DB          int
+	PoolSize    int           // Maximum number of idle connections kept open
+	DialTimeout time.Duration // Timeout for opening new connections
+	TLSConfig   *tls.Config   // Connects over TLS when set
+}
+
+// ParseURL parses a redis://[[user]:password@]host[:port][/db] URL into Options, or a rediss:// one
+// connecting over TLS
+func ParseURL(rawURL string) (Options, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Options{}, err
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return Options{}, fmt.Errorf("redis: unsupported URL scheme %q", u.Scheme)
+	}
+
+	opts := Options{Addr: u.Host, Username: u.User.Username()}
+	if u.Port() == "" {
+		opts.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if password, ok := u.User.Password(); ok {
+		opts.Password = password
+	}
+	if u.Scheme == "rediss" {
+		opts.TLSConfig = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	}
+	if db := strings.Trim(u.Path, "/"); db != "" {
+		opts.DB, err = strconv.Atoi(db)
+		if err != nil {
+			return Options{}, fmt.Errorf("redis: invalid database %q", db)
+		}
+	}
+	return opts, nil
+}
+
+// Client is a pooled Redis client, safe for concurrent use
+type Client struct {
+	opts Options
+	mu   sync.Mutex
+	idle []*conn
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func NewClient(opts Options) *Client {
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 10
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 5 * time.Second
+	}
+	return &Client{opts: opts}
+}
+
+// Ping checks that the server is reachable
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.Do(ctx, "PING")
+	return err
+}
+
+// Do sends a command and returns its reply: string, int64, []any, or an Error
+func (c *Client) Do(ctx context.Context, args ...any) (any, error) {
+	cn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := cn.do(ctx, args)
+	var serverErr Error
+	if err != nil && !errors.As(err, &serverErr) && !errors.Is(err, ErrNil) {
+		// The connection state is unknown after a network error
+		cn.Close()
+		return nil, err
+	}
+	c.put(cn)
+	return reply, err
+}
+
+// Close closes the idle connections of the pool
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cn := range c.idle {
+		cn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+func (c *Client) get(ctx context.Context) (*conn, error) {
+	c.mu.Lock()
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+	return c.dial(ctx)
+}
+
+func (c *Client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.idle) >= c.opts.PoolSize {
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+}
+
+func (c *Client) dial(ctx context.Context) (*conn, error) {
+	dialer := &net.Dialer{Timeout: c.opts.DialTimeout}
+	var nc net.Conn
+	var err error
+	if c.opts.TLSConfig != nil {
+		nc, err = (&tls.Dialer{NetDialer: dialer, Config: c.opts.TLSConfig}).DialContext(ctx, "tcp", c.opts.Addr)
+	} else {
+		nc, err = dialer.DialContext(ctx, "tcp", c.opts.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	cn := &conn{Conn: nc, r: bufio.NewReader(nc)}
+
+	if c.opts.Password != "" {
+		auth := []any{"AUTH", c.opts.Password}
+		if c.opts.Username != "" {
+			auth = []any{"AUTH", c.opts.Username, c.opts.Password}
+		}
+		if _, err := cn.do(ctx, auth); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	if c.opts.DB != 0 {
+		if _, err := cn.do(ctx, []any{"SELECT", c.opts.DB}); err != nil {
+			cn.Close()
+			return nil, err
+		}
+	}
+	return cn, nil
+}
+
+func (cn *conn) do(ctx context.Context, args []any) (any, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Time{}
+	}
+	if err := cn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := cn.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+	return readReply(cn.r)
+}
+
+// encodeCommand encodes a command as a RESP array of bulk strings
+func encodeCommand(args []any) []byte {
+	buf := make([]byte, 0, 64)
+	buf = append(buf, '*')
+	buf = strconv.AppendInt(buf, int64(len(args)), 10)
+	buf = append(buf, '\r', '\n')
+	for _, arg := range args {
+		var s string
+		switch v := arg.(type) {
+		case string:
+			s = v
+		case []byte:
+			s = string(v)
+		case int:
+			s = strconv.Itoa(v)
+		case int64:
+			s = strconv.FormatInt(v, 10)
+		default:
+			s = fmt.Sprint(v)
+		}
+		buf = append(buf, '$')
+		buf = strconv.AppendInt(buf, int64(len(s)), 10)
+		buf = append(buf, '\r', '\n')
+		buf = append(buf, s...)
+		buf = append(buf, '\r', '\n')
+	}
+	return buf
+}
+
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	// The shortest reply is the empty simple string, +\r\n
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, Error(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, ErrNil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, ErrNil
+		}
+		values := make([]any, n)
+		for i := range values {
+			// Nested error and nil replies become elements so the rest of the array is still read
+			value, err := readReply(r)
+			var serverErr Error
+			switch {
+			case errors.As(err, &serverErr):
+				value = serverErr
+			case err != nil && !errors.Is(err, ErrNil):
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("redis: unknown reply type %q", kind)
+	}
+}
@@ -0,0 +1,30 @@
+package security
+
+// commonPasswords are frequent passwords and words found in leaked password lists, most common
+// first; EstimateStrength ranks them by their position
+var commonPasswords = []string{
+	"123456", "password", "12345678", "qwerty", "123456789", "12345", "1234", "111111", "1234567", "dragon",
+	"123123", "baseball", "abc123", "football", "monkey", "letmein", "696969", "shadow", "master", "666666",
+	"qwertyuiop", "123321", "mustang", "1234567890", "michael", "654321", "superman", "1qaz2wsx", "7777777", "121212",
+	"000000", "qazwsx", "123qwe", "killer", "trustno1", "jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter",
+	"buster", "soccer", "harley", "batman", "andrew", "tigger", "sunshine", "iloveyou", "2000", "charlie",
+	"robert", "thomas", "hockey", "ranger", "daniel", "starwars", "klaster", "112233", "george", "computer",
+	"michelle", "jessica", "pepper", "1111", "zxcvbn", "555555", "11111111", "131313", "freedom", "777777",
+	"pass", "maggie", "159753", "aaaaaa", "ginger", "princess", "joshua", "cheese", "amanda", "summer",
+	"love", "ashley", "nicole", "chelsea", "biteme", "matthew", "access", "yankees", "987654321", "dallas",
+	"austin", "thunder", "taylor", "matrix", "welcome", "admin", "login", "passw0rd", "password1", "password123",
+	"qwerty123", "secret", "hello", "whatever", "monday", "friday", "flower", "hottie", "loveme", "zaq1zaq1",
+	"internet", "samsung", "orange", "chocolate", "banana", "apple", "cookie", "purple", "silver", "golden",
+	"diamond", "angel", "angels", "baby", "babygirl", "lovely", "family", "forever", "jesus", "christ",
+	"blessed", "heaven", "money", "business", "dollar", "winner", "success", "victory", "champion", "legend",
+	"wizard", "magic", "merlin", "phoenix", "falcon", "eagle", "tiger", "lion", "wolf", "bear",
+	"dolphin", "spider", "snake", "horse", "rabbit", "kitten", "puppy", "doggy", "cat", "dog",
+	"music", "guitar", "rock", "metal", "dance", "party", "summer1", "winter", "spring", "autumn",
+	"london", "paris", "berlin", "madrid", "brasil", "brazil", "mexico", "america", "canada", "england",
+	"google", "facebook", "twitter", "youtube", "microsoft", "windows", "oracle", "server", "system", "network",
+	"root", "toor", "test", "test123", "guest", "user", "default", "changeme", "temp", "demo",
+	"asdf", "asdfasdf", "qwer", "zxcv", "1q2w3e4r", "1q2w3e", "q1w2e3r4", "a1b2c3", "aa123456", "abcdef",
+	"abcd1234", "abc", "xyz", "iloveu", "lovers", "sweet", "sweety", "honey", "sexy", "beautiful",
+	"pokemon", "naruto", "minecraft", "fortnite", "gaming", "player", "superstar", "freedom1", "starlight", "letmein1",
+	"senha", "senha123", "contraseña", "contrasena", "amor", "teamo", "futebol", "flamengo", "corinthians", "palmeiras",
+}
@@ -0,0 +1,55 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+// KeyCipher encrypts the private keys stored in the database with AES-256-GCM, so reading the
+// database is not enough to sign tokens
+type KeyCipher struct {
+	aead cipher.AEAD
+}
+
+// NewKeyCipher returns a cipher using encodedKey, 32 random bytes in standard base64 such as the
+// output of openssl rand -base64 32
+func NewKeyCipher(encodedKey string) (*KeyCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("key encryption key must be 32 bytes encoded in base64")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &KeyCipher{aead: aead}, nil
+}
+
+// Seal encrypts plaintext bound to keyID, the random nonce is prepended to the ciphertext
+func (c *KeyCipher) Seal(plaintext []byte, keyID string) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, []byte(keyID)), nil
+}
+
+// Open decrypts a ciphertext of Seal bound to the same keyID
+func (c *KeyCipher) Open(ciphertext []byte, keyID string) ([]byte, error) {
+	if len(ciphertext) < c.aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:c.aead.NonceSize()], ciphertext[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, sealed, []byte(keyID))
+	if err != nil {
+		return nil, errors.New("cannot decrypt the private key, check the key encryption key")
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,148 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing keys
+const minRSABits = 2048
+
+// JWK is a public key of a JSON Web Key Set, see RFC 7517
+type JWK struct {
+	KeyType   string `json:"kty" example:"RSA"`
+	KeyID     string `json:"kid" example:"NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs"`
+	Use       string `json:"use" example:"sig"`
+	Algorithm string `json:"alg" example:"RS256"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // Ed25519 keys
+	X         string `json:"x,omitempty"`   // Ed25519 public key
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// verificationKey is a public key verifying the tokens signed with its private key
+type verificationKey struct {
+	method jwt.SigningMethod
+	public crypto.PublicKey
+	jwk    JWK
+}
+
+func newVerificationKey(public crypto.PublicKey) (*verificationKey, error) {
+	var key verificationKey
+	var thumbprint map[string]string
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		key.method = jwt.SigningMethodRS256
+		key.jwk = JWK{
+			KeyType: "RSA",
+			N:       base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}
+		thumbprint = map[string]string{"e": key.jwk.E, "kty": "RSA", "n": key.jwk.N}
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+		key.jwk = JWK{KeyType: "OKP", Curve: "Ed25519", X: base64.RawURLEncoding.EncodeToString(public)}
+		thumbprint = map[string]string{"crv": "Ed25519", "kty": "OKP", "x": key.jwk.X}
+	default:
+		return nil, fmt.Errorf("unsupported key type %T: use RSA or Ed25519 keys", public)
+	}
+	key.public = public
+	key.jwk.Use = "sig"
+	key.jwk.Algorithm = key.method.Alg()
+
+	// The key ID is the RFC 7638 thumbprint, so it changes with the key; json.Marshal sorts the
+	// members as the thumbprint requires
+	members, err := json.Marshal(thumbprint)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha256.Sum256(members)
+	key.jwk.KeyID = base64.RawURLEncoding.EncodeToString(digest[:])
+	return &key, nil
+}
+
+// SigningKeyID returns the key ID of a public key, as published in the key set and named by the
+// tokens it verifies
+func SigningKeyID(public crypto.PublicKey) (string, error) {
+	key, err := newVerificationKey(public)
+	if err != nil {
+		return "", err
+	}
+	return key.jwk.KeyID, nil
+}
+
+// GenerateSigningKey generates a private key signing tokens with algorithm, EdDSA or RS256
+func GenerateSigningKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case jwt.SigningMethodEdDSA.Alg():
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		return key, err
+	case jwt.SigningMethodRS256.Alg():
+		return rsa.GenerateKey(rand.Reader, minRSABits)
+	}
+	return nil, fmt.Errorf("unsupported signing algorithm %q: use EdDSA or RS256", algorithm)
+}
+
+// MarshalPrivateKeyPEM encodes a private key in PKCS #8 PEM, as read by ParsePrivateKeyPEM
+func MarshalPrivateKeyPEM(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParsePrivateKeyPEM parses an RSA or Ed25519 private key in PKCS #8 PEM, or an RSA key in PKCS #1 PEM
+func ParsePrivateKeyPEM(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	var key any
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
+
+// ParsePublicKeyPEM parses an RSA or Ed25519 public key in PKIX PEM
+func ParsePublicKeyPEM(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("no PUBLIC KEY PEM block found")
+	}
+	return x509.ParsePKIXPublicKey(block.Bytes)
+}
@@ -0,0 +1,570 @@
+package security
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Strength is a zxcvbn-style estimate of how hard a password is to guess: the password is split
+// into the sequence of patterns, such as common passwords, keyboard rows, sequences, repeats and
+// years, that an attacker would need the fewest guesses to try, and scored from the guesses needed
+type Strength struct {
+	// Score rates the password from 0, too guessable, to 4, very unguessable, as zxcvbn does
+	Score int
+	// GuessesLog10 is the log10 of the estimated number of guesses needed
+	GuessesLog10 float64
+	// Warning explains the weakest pattern of passwords scored below 3
+	Warning string
+	// Suggestions help picking a stronger password; empty for passwords scored 3 or more
+	Suggestions []string
+}
+
+const (
+	// maxEstimatedRunes bounds the runes estimated, passwords are scored on their beginning
+	maxEstimatedRunes = 100
+	// minYearSpace is the number of years guessed around the current one
+	minYearSpace = 20
+	// minGuessesBeforeGrowingSequence discourages splitting passwords into many short patterns
+	minGuessesBeforeGrowingSequence = 10000
+)
+
+// scoreThresholds are the log10 guesses reaching each score, with the levels of zxcvbn: an online
+// attack throttled per account, unthrottled, and offline attacks on slow and fast hashes
+var scoreThresholds = []float64{3, 6, 8, 10}
+
+// EstimateStrength estimates the strength of password. The userInputs, such as the email address
+// and name of the account, are treated as the most common passwords.
+func EstimateStrength(password string, userInputs ...string) Strength {
+	runes := []rune(password)
+	if len(runes) > maxEstimatedRunes {
+		runes = runes[:maxEstimatedRunes]
+	}
+	guesses, sequence := mostGuessableSequence(runes, rankedDictionary(userInputs))
+
+	strength := Strength{GuessesLog10: math.Round(guesses*100) / 100}
+	for _, threshold := range scoreThresholds {
+		if guesses > threshold {
+			strength.Score++
+		}
+	}
+	strength.Warning, strength.Suggestions = feedback(strength.Score, sequence)
+	return strength
+}
+
+// match is a pattern found in runes[i:j+1]
+type match struct {
+	i, j    int
+	pattern string
+	guesses float64 // log10
+	token   string
+
+	rank      int  // Dictionary rank, from 1
+	userInput bool // Dictionary matches of a user input
+	reversed  bool
+	l33t      bool
+	turns     int // Direction changes of keyboard patterns
+	repeated  string
+}
+
+// rankedDictionary returns the user inputs, their local part when they are email addresses and their
+// words, followed by the common passwords, ranked from 1
+func rankedDictionary(userInputs []string) map[string]dictionaryEntry {
+	entries := make(map[string]dictionaryEntry, len(commonPasswords)+len(userInputs))
+	rank := 1
+	for _, input := range userInputs {
+		input = strings.ToLower(input)
+		parts := strings.FieldsFunc(input, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		local, _, _ := strings.Cut(input, "@")
+		for _, part := range append([]string{input, local}, parts...) {
+			if _, ok := entries[part]; !ok && len([]rune(part)) >= 3 {
+				entries[part] = dictionaryEntry{rank: rank, userInput: true}
+				rank++
+			}
+		}
+	}
+	for i, word := range commonPasswords {
+		if _, ok := entries[word]; !ok {
+			entries[word] = dictionaryEntry{rank: i + 1}
+		}
+	}
+	return entries
+}
+
+type dictionaryEntry struct {
+	rank      int
+	userInput bool
+}
+
+// mostGuessableSequence returns the log10 guesses of the sequence of non-overlapping matches, filled
+// with brute force, needing the fewest guesses, and that sequence
+func mostGuessableSequence(runes []rune, dictionary map[string]dictionaryEntry) (float64, []match) {
+	n := len(runes)
+	if n == 0 {
+		return 0, nil
+	}
+
+	// The fewest guesses of each span
+	spans := make(map[[2]int]match)
+	for _, m := range findMatches(runes, dictionary) {
+		if m.i != 0 || m.j != n-1 {
+			// Parts of a password are at least as hard to guess as a short brute force
+			floor := math.Log10(50)
+			if m.j == m.i {
+				floor = 1
+			}
+			m.guesses = math.Max(m.guesses, floor)
+		}
+		if best, ok := spans[[2]int{m.i, m.j}]; !ok || m.guesses < best.guesses {
+			spans[[2]int{m.i, m.j}] = m
+		}
+	}
+
+	// best[k][l] is the fewest log10 guesses of runes[:k] split into l matches
+	inf := math.Inf(1)
+	best := make([][]float64, n+1)
+	from := make([][]match, n+1)
+	for k := range best {
+		best[k] = slices.Repeat([]float64{inf}, n+1)
+		from[k] = make([]match, n+1)
+	}
+	best[0][0] = 0
+	for k := 1; k <= n; k++ {
+		for i := 0; i < k; i++ {
+			m, ok := spans[[2]int{i, k - 1}]
+			if !ok {
+				m = match{i: i, j: k - 1, pattern: "bruteforce", guesses: float64(k - i), token: string(runes[i:k])}
+			}
+			for l := 1; l <= k; l++ {
+				if g := best[i][l-1] + m.guesses; g < best[k][l] {
+					best[k][l] = g
+					from[k][l] = m
+				}
+			}
+		}
+	}
+
+	// Attackers also have to guess how many patterns make up the password
+	total, length := inf, 0
+	for l := 1; l <= n; l++ {
+		if math.IsInf(best[n][l], 1) {
+			continue
+		}
+		lgamma, _ := math.Lgamma(float64(l + 1))
+		g := logAdd(lgamma/math.Ln10+best[n][l], float64(l-1)*math.Log10(minGuessesBeforeGrowingSequence))
+		if g < total {
+			total, length = g, l
+		}
+	}
+
+	sequence := make([]match, 0, length)
+	for k, l := n, length; l > 0; l-- {
+		m := from[k][l]
+		sequence = append(sequence, m)
+		k = m.i
+	}
+	slices.Reverse(sequence)
+	return total, sequence
+}
+
+// logAdd returns log10(10^a + 10^b)
+func logAdd(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log10(1+math.Pow(10, b-a))
+}
+
+func findMatches(runes []rune, dictionary map[string]dictionaryEntry) []match {
+	var matches []match
+	matches = append(matches, dictionaryMatches(runes, dictionary)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, repeatMatches(runes, dictionary)...)
+	matches = append(matches, spatialMatches(runes)...)
+	matches = append(matches, yearMatches(runes)...)
+	return matches
+}
+
+// l33tSubstitutions undoes the common substitutions of letters by digits and symbols
+var l33tSubstitutions = map[rune]rune{
+	'4': 'a', '@': 'a', '8': 'b', '(': 'c', '3': 'e', '6': 'g', '1': 'i', '!': 'i',
+	'|': 'l', '0': 'o', '$': 's', '5': 's', '7': 't', '+': 't', '2': 'z',
+}
+
+func dictionaryMatches(runes []rune, dictionary map[string]dictionaryEntry) []match {
+	var matches []match
+	for i := range runes {
+		for j := i + 2; j < len(runes); j++ {
+			token := string(runes[i : j+1])
+			word := strings.ToLower(token)
+			variations := uppercaseVariations(runes[i : j+1])
+
+			if entry, ok := dictionary[word]; ok {
+				matches = append(matches, dictionaryMatch(i, j, token, entry, variations))
+			}
+			if entry, ok := dictionary[reverse(word)]; ok && len(word) > 3 {
+				m := dictionaryMatch(i, j, token, entry, variations+math.Log10(2))
+				m.reversed = true
+				matches = append(matches, m)
+			}
+			unleeted, substitutions := unleet(word)
+			if entry, ok := dictionary[unleeted]; ok && substitutions > 0 {
+				m := dictionaryMatch(i, j, token, entry, variations+float64(substitutions)*math.Log10(2))
+				m.l33t = true
+				matches = append(matches, m)
+			}
+		}
+	}
+	return matches
+}
+
+func dictionaryMatch(i, j int, token string, entry dictionaryEntry, variations float64) match {
+	return match{
+		i: i, j: j, pattern: "dictionary", token: token,
+		guesses: math.Log10(float64(entry.rank)) + variations,
+		rank:    entry.rank, userInput: entry.userInput,
+	}
+}
+
+// uppercaseVariations returns the log10 of the capitalizations an attacker tries to reach token
+func uppercaseVariations(token []rune) float64 {
+	var upper, lower int
+	for _, r := range token {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	switch {
+	case upper == 0:
+		return 0
+	case lower == 0, upper == 1 && (unicode.IsUpper(token[0]) || unicode.IsUpper(token[len(token)-1])):
+		return math.Log10(2)
+	}
+	var variations float64
+	for k := 1; k <= min(upper, lower); k++ {
+		variations += binomial(upper+lower, k)
+	}
+	return math.Log10(variations)
+}
+
+func binomial(n, k int) float64 {
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+func unleet(word string) (string, int) {
+	substitutions := 0
+	unleeted := []rune(word)
+	for i, r := range unleeted {
+		if letter, ok := l33tSubstitutions[r]; ok {
+			unleeted[i] = letter
+			substitutions++
+		}
+	}
+	return string(unleeted), substitutions
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	slices.Reverse(runes)
+	return string(runes)
+}
+
+// sequenceMatches finds runs of at least 3 runes with the same small step, such as abc, 7531 or zyx
+func sequenceMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i < len(runes)-2; {
+		delta := runes[i+1] - runes[i]
+		j := i + 1
+		for j+1 < len(runes) && runes[j+1]-runes[j] == delta {
+			j++
+		}
+		if j-i >= 2 && delta != 0 && delta >= -5 && delta <= 5 {
+			token := string(runes[i : j+1])
+			base := 26.0
+			switch {
+			case strings.ContainsRune("aAzZ019", runes[i]):
+				base = 4
+			case unicode.IsDigit(runes[i]):
+				base = 10
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, match{i: i, j: j, pattern: "sequence", token: token, guesses: math.Log10(base * float64(j-i+1))})
+			i = j
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+// repeatMatches finds repeated runes and repeated chunks, such as aaa or abcabc
+func repeatMatches(runes []rune, dictionary map[string]dictionaryEntry) []match {
+	var matches []match
+	for i := range runes {
+		for size := 1; size <= (len(runes)-i)/2; size++ {
+			chunk := runes[i : i+size]
+			count := 1
+			for next := i + size; next+size <= len(runes) && slices.Equal(runes[next:next+size], chunk); next += size {
+				count++
+			}
+			if count < 2 || (size == 1 && count < 3) {
+				continue
+			}
+			j := i + size*count - 1
+			base := float64(size) * math.Log10(cardinality(chunk))
+			if size > 1 {
+				base, _ = mostGuessableSequence(chunk, dictionary)
+			}
+			matches = append(matches, match{
+				i: i, j: j, pattern: "repeat", token: string(runes[i : j+1]), repeated: string(chunk),
+				guesses: base + math.Log10(float64(count)),
+			})
+		}
+	}
+	return matches
+}
+
+// cardinality is the size of the character classes of runes
+func cardinality(runes []rune) float64 {
+	var digits, lower, upper, other bool
+	for _, r := range runes {
+		switch {
+		case unicode.IsDigit(r):
+			digits = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		default:
+			other = true
+		}
+	}
+	size := 0.0
+	if digits {
+		size += 10
+	}
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if other {
+		size += 33
+	}
+	return size
+}
+
+// keyboardRows are the rows of a QWERTY keyboard, unshifted and shifted; each row starts half a key
+// further right than the row above, the first one a key and a half from the left
+var keyboardRows = [][2]string{
+	{"`1234567890-=", "~!@#$%^&*()_+"},
+	{"qwertyuiop[]\\", "QWERTYUIOP{}|"},
+	{"asdfghjkl;'", "ASDFGHJKL:\""},
+	{"zxcvbnm,./", "ZXCVBNM<>?"},
+}
+
+// keyPosition is the row and column of a key, in half keys
+type keyPosition struct {
+	row, column int
+	shifted     bool
+}
+
+var keyPositions = func() map[rune]keyPosition {
+	positions := make(map[rune]keyPosition)
+	for row, keys := range keyboardRows {
+		offset := row
+		if row > 0 {
+			offset++
+		}
+		for shifted, chars := range keys {
+			for column, r := range []rune(chars) {
+				positions[r] = keyPosition{row: row, column: 2*column + offset, shifted: shifted == 1}
+			}
+		}
+	}
+	return positions
+}()
+
+const (
+	keyboardKeys          = 47
+	keyboardAverageDegree = 4.6
+)
+
+// spatialMatches finds runs of at least 3 adjacent keys, such as qwerty or zaq1
+func spatialMatches(runes []rune) []match {
+	var matches []match
+	for i := 0; i < len(runes)-2; {
+		j, turns, shifted := i, 0, 0
+		var direction [2]int
+		for j+1 < len(runes) {
+			from, ok1 := keyPositions[runes[j]]
+			to, ok2 := keyPositions[runes[j+1]]
+			step := [2]int{to.row - from.row, to.column - from.column}
+			adjacent := ok1 && ok2 && (step[0] == 0 && (step[1] == 2 || step[1] == -2) ||
+				(step[0] == 1 || step[0] == -1) && (step[1] == 1 || step[1] == -1))
+			if !adjacent {
+				break
+			}
+			if step != direction {
+				turns++
+				direction = step
+			}
+			j++
+		}
+		if j-i >= 2 {
+			for _, r := range runes[i : j+1] {
+				if keyPositions[r].shifted {
+					shifted++
+				}
+			}
+			matches = append(matches, match{
+				i: i, j: j, pattern: "spatial", token: string(runes[i : j+1]), turns: turns,
+				guesses: spatialGuesses(j-i+1, turns, shifted),
+			})
+			i = j
+			continue
+		}
+		i++
+	}
+	return matches
+}
+
+// spatialGuesses counts the keyboard patterns up to length keys with up to turns direction
+// changes, times the shift variations, as zxcvbn does
+func spatialGuesses(length, turns, shifted int) float64 {
+	var guesses float64
+	for i := 2; i <= length; i++ {
+		for j := 1; j <= min(turns, i-1); j++ {
+			guesses += binomial(i-1, j-1) * keyboardKeys * math.Pow(keyboardAverageDegree, float64(j))
+		}
+	}
+	if shifted > 0 {
+		unshifted := length - shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			var variations float64
+			for k := 1; k <= min(shifted, unshifted); k++ {
+				variations += binomial(length, k)
+			}
+			guesses *= variations
+		}
+	}
+	return math.Log10(guesses)
+}
+
+var yearPattern = regexp.MustCompile(`19\d\d|20\d\d`)
+
+// yearMatches finds the years 1900 to 2099, guessed outward from the current year
+func yearMatches(runes []rune) []match {
+	var matches []match
+	token := string(runes)
+	for _, loc := range yearPattern.FindAllStringIndex(token, -1) {
+		year, _ := strconv.Atoi(token[loc[0]:loc[1]])
+		space := max(abs(year-time.Now().Year()), minYearSpace)
+		i := len([]rune(token[:loc[0]]))
+		matches = append(matches, match{i: i, j: i + 3, pattern: "year", token: token[loc[0]:loc[1]], guesses: math.Log10(float64(space))})
+	}
+	return matches
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// feedback returns the warning and suggestions of a password from the longest pattern of its
+// sequence, as zxcvbn does
+func feedback(score int, sequence []match) (string, []string) {
+	if len(sequence) == 0 {
+		return "", []string{
+			"Use a few words, avoid common phrases",
+			"No need for symbols, digits, or uppercase letters",
+		}
+	}
+	if score > 2 {
+		return "", nil
+	}
+
+	var longest *match
+	for i := range sequence {
+		if sequence[i].pattern == "bruteforce" {
+			continue
+		}
+		if longest == nil || len([]rune(sequence[i].token)) > len([]rune(longest.token)) {
+			longest = &sequence[i]
+		}
+	}
+	suggestions := []string{"Add another word or two. Uncommon words are better."}
+	if longest == nil {
+		return "", suggestions
+	}
+
+	switch longest.pattern {
+	case "dictionary":
+		return dictionaryFeedback(*longest, len(sequence) == 1, suggestions)
+	case "spatial":
+		warning := "Short keyboard patterns are easy to guess"
+		if longest.turns == 1 {
+			warning = "Straight rows of keys are easy to guess"
+		}
+		return warning, append(suggestions, "Use a longer keyboard pattern with more turns")
+	case "repeat":
+		warning := `Repeats like "abcabcabc" are only slightly harder to guess than "abc"`
+		if len([]rune(longest.repeated)) == 1 {
+			warning = `Repeats like "aaa" are easy to guess`
+		}
+		return warning, append(suggestions, "Avoid repeated words and characters")
+	case "sequence":
+		return "Sequences like abc or 6543 are easy to guess", append(suggestions, "Avoid sequences")
+	case "year":
+		return "Recent years are easy to guess", append(suggestions, "Avoid recent years", "Avoid years that are associated with you")
+	}
+	return "", suggestions
+}
+
+func dictionaryFeedback(m match, sole bool, suggestions []string) (string, []string) {
+	var warning string
+	switch {
+	case m.userInput:
+		warning = "Passwords based on your name or email address are easy to guess"
+	case sole && !m.l33t && !m.reversed && m.rank <= 10:
+		warning = "This is a top-10 common password"
+	case sole && !m.l33t && !m.reversed && m.rank <= 100:
+		warning = "This is a top-100 common password"
+	case sole:
+		warning = "This is a very common password"
+	case m.guesses <= 4:
+		warning = "This is similar to a commonly used password"
+	}
+
+	runes := []rune(m.token)
+	switch {
+	case strings.ToUpper(m.token) == m.token && strings.ToLower(m.token) != m.token:
+		suggestions = append(suggestions, "All-uppercase is almost as easy to guess as all-lowercase")
+	case unicode.IsUpper(runes[0]):
+		suggestions = append(suggestions, "Capitalization doesn't help very much")
+	}
+	if m.reversed {
+		suggestions = append(suggestions, "Reversed words aren't much harder to guess")
+	}
+	if m.l33t {
+		suggestions = append(suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much")
+	}
+	return warning, suggestions
+}
@@ -0,0 +1,122 @@
+package security
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEstimateStrength(t *testing.T) {
+	userInputs := []string{"jdoe@example.com", "John Doe"}
+	tests := []struct {
+		password     string
+		wantScore    int
+		wantGuesses  float64 // log10, -1 when not checked
+		wantWarning  string
+		wantAdvice   string // One of the suggestions, empty when not checked
+		noSuggestion bool
+	}{
+		// 2 guesses for the rank of "password" and 1 for the number of patterns
+		{password: "password", wantScore: 0, wantGuesses: math.Log10(3), wantWarning: "This is a top-10 common password"},
+		{password: "Password", wantScore: 0, wantGuesses: math.Log10(5), wantWarning: "This is a top-10 common password",
+			wantAdvice: "Capitalization doesn't help very much"},
+		{password: "PASSWORD", wantScore: 0, wantGuesses: math.Log10(5), wantWarning: "This is a top-10 common password",
+			wantAdvice: "All-uppercase is almost as easy to guess as all-lowercase"},
+		{password: "p@ssw0rd", wantScore: 0, wantGuesses: -1, wantWarning: "This is a very common password",
+			wantAdvice: "Predictable substitutions like '@' instead of 'a' don't help very much"},
+		{password: "drowssap", wantScore: 0, wantGuesses: math.Log10(5), wantWarning: "This is a very common password",
+			wantAdvice: "Reversed words aren't much harder to guess"},
+		{password: "zxcvbnm", wantScore: 0, wantGuesses: -1, wantWarning: "This is a top-100 common password"},
+		// Digit sequences starting at 1 have 4 starts, times 5 runes
+		{password: "13579", wantScore: 0, wantGuesses: math.Log10(21), wantWarning: "Sequences like abc or 6543 are easy to guess"},
+		// Descending sequences have twice the guesses
+		{password: "zyxwvu", wantScore: 0, wantGuesses: math.Log10(49), wantWarning: "Sequences like abc or 6543 are easy to guess"},
+		// 26 lowercase letters repeated 8 times
+		{password: "aaaaaaaa", wantScore: 0, wantGuesses: math.Log10(26*8 + 1), wantWarning: `Repeats like "aaa" are easy to guess`},
+		{password: "abcabcabc", wantScore: 0, wantGuesses: -1, wantWarning: `Repeats like "abcabcabc" are only slightly harder to guess than "abc"`},
+		{password: "asdfghjkl", wantScore: 1, wantGuesses: -1, wantWarning: "Straight rows of keys are easy to guess"},
+		// The current year is one of the 20 years tried first
+		{password: strconv.Itoa(time.Now().Year()), wantScore: 0, wantGuesses: math.Log10(21), wantWarning: "Recent years are easy to guess"},
+		{password: "jdoe2024", wantScore: 1, wantGuesses: -1, wantWarning: "Passwords based on your name or email address are easy to guess"},
+		{password: "correcthorsebatterystaple", wantScore: 4, wantGuesses: -1, noSuggestion: true},
+		{password: "kX9#mQ2$vL7!", wantScore: 4, wantGuesses: 12, noSuggestion: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			got := EstimateStrength(tt.password, userInputs...)
+			if got.Score != tt.wantScore {
+				t.Errorf("Score = %d, want %d", got.Score, tt.wantScore)
+			}
+			if want := math.Round(tt.wantGuesses*100) / 100; tt.wantGuesses >= 0 && got.GuessesLog10 != want {
+				t.Errorf("GuessesLog10 = %.2f, want %.2f", got.GuessesLog10, want)
+			}
+			if got.Warning != tt.wantWarning {
+				t.Errorf("Warning = %q, want %q", got.Warning, tt.wantWarning)
+			}
+			if tt.noSuggestion != (len(got.Suggestions) == 0) {
+				t.Errorf("Suggestions = %q", got.Suggestions)
+			}
+			if tt.wantAdvice != "" && !strings.Contains(strings.Join(got.Suggestions, "\n"), tt.wantAdvice) {
+				t.Errorf("Suggestions = %q, want %q", got.Suggestions, tt.wantAdvice)
+			}
+		})
+	}
+}
+
+func TestEstimateStrengthEmpty(t *testing.T) {
+	got := EstimateStrength("")
+	if got.Score != 0 || got.GuessesLog10 != 0 || got.Warning != "" || len(got.Suggestions) != 2 {
+		t.Errorf("strength = %+v", got)
+	}
+}
+
+func TestEstimateStrengthEstimatesTheFirstRunes(t *testing.T) {
+	long := strings.Repeat("kX9#mQ2$vL", 10)
+	if got, want := EstimateStrength(long+"password"), EstimateStrength(long); got.GuessesLog10 != want.GuessesLog10 {
+		t.Errorf("GuessesLog10 = %.2f, want %.2f of the first %d runes", got.GuessesLog10, want.GuessesLog10, maxEstimatedRunes)
+	}
+}
+
+func TestUppercaseVariations(t *testing.T) {
+	tests := []struct {
+		token string
+		want  float64
+	}{
+		{"password", 0},
+		{"Password", math.Log10(2)},
+		{"passworD", math.Log10(2)},
+		{"PASSWORD", math.Log10(2)},
+		// 2 uppercase letters among 8: C(8,1) + C(8,2)
+		{"PassWord", math.Log10(36)},
+		{"pAssword", math.Log10(8)},
+		{"1234", 0},
+	}
+	for _, tt := range tests {
+		if got := uppercaseVariations([]rune(tt.token)); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("uppercaseVariations(%q) = %f, want %f", tt.token, got, tt.want)
+		}
+	}
+}
+
+func TestSpatialGuesses(t *testing.T) {
+	// zxcvbn counts sum(C(i-1, j-1) * keys * degree^j) over the lengths i and the turns j
+	perTurn := keyboardKeys * keyboardAverageDegree
+	tests := []struct {
+		length, turns, shifted int
+		want                   float64
+	}{
+		{3, 1, 0, 2 * perTurn},
+		{4, 2, 0, 3*perTurn + (2+3)*perTurn*keyboardAverageDegree},
+		// Entirely shifted patterns double the guesses
+		{3, 1, 3, 4 * perTurn},
+		// One shifted key among 3 has C(3,1) variations
+		{3, 1, 1, 6 * perTurn},
+	}
+	for _, tt := range tests {
+		if got := spatialGuesses(tt.length, tt.turns, tt.shifted); math.Abs(got-math.Log10(tt.want)) > 1e-9 {
+			t.Errorf("spatialGuesses(%d, %d, %d) = %f, want %f", tt.length, tt.turns, tt.shifted, got, math.Log10(tt.want))
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package security
+
+import (
+	"crypto"
+	"errors"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const TokenIssuer = "user-management-api"
+
+// TokenPurposeMFA is the purpose of the tokens proving the password of a user whose login awaits
+// its second factor, see GenerateMFA
+const TokenPurposeMFA = "mfa"
+
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// Claims are the JWT claims carried by access tokens
+type Claims struct {
+	Roles  []string     `json:"roles,omitempty"`
+	Tenant string       `json:"tenant,omitempty"`
+	Actor  *ActorClaims `json:"act,omitempty"`
+	// ClientID is set only on the tokens of OAuth2 clients, whose subject is the client ID
+	ClientID string `json:"client_id,omitempty"`
+	// Scope lists the space-delimited scopes restricting the token; user tokens without a scope
+	// are unrestricted, the scopes of clients also hold the permissions they are granted
+	Scope string `json:"scope,omitempty"`
+	// Purpose is set on the tokens that are not access tokens, such as TokenPurposeMFA
+	Purpose string `json:"purpose,omitempty"`
+	// AuthTime is when the user last proved their password or a second factor (OpenID Connect
+	// auth_time), set on the tokens issued by logins and re-authentications
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+	// Generation is the token generation of the user when the token was issued; a logout from all
+	// devices bumps the generation of the user, revoking the tokens of older ones
+	Generation int `json:"gen,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ActorClaims identifies who is acting on behalf of the subject (RFC 8693 "act" claim),
+// set only on impersonation tokens
+type ActorClaims struct {
+	Subject string `json:"sub"`
+}
+
+// IsImpersonation reports whether the token was issued for an admin impersonating the subject
+func (c *Claims) IsImpersonation() bool {
+	return c.Actor != nil && c.Actor.Subject != ""
+}
+
+// IsClient reports whether the token was issued to an OAuth2 client instead of a user
+func (c *Claims) IsClient() bool {
+	return c.ClientID != ""
+}
+
+// Scopes returns the scopes of the token
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// Restricted reports whether the token can only be used on the routes its scopes allow
+func (c *Claims) Restricted() bool {
+	return c.IsClient() || c.Scope != ""
+}
+
+// TokenManager issues and validates access tokens, signed with a shared secret (HS256) or with a
+// private key (RS256 or EdDSA) whose public key is published in the key set returned by JWKS
+type TokenManager struct {
+	secret []byte // Nil rejects HS256 tokens
+	ttl    time.Duration
+	ring   atomic.Pointer[keyRing] // Replaced by SetSigningKeys as keys are rotated
+}
+
+// keyRing holds the keys signing and verifying the tokens
+type keyRing struct {
+	// signer signs the tokens with signingKey, instead of secret, when set
+	signer     crypto.Signer
+	signingKey *verificationKey
+	keys       map[string]*verificationKey // By key ID
+	jwks       JWKS
+	methods    []string
+}
+
+// NewTokenManager signs tokens with secret; an empty secret issues no token until SetSigningKeys is called
+func NewTokenManager(secret string, ttl time.Duration) *TokenManager {
+	m := &TokenManager{ttl: ttl}
+	ring := &keyRing{}
+	if secret != "" {
+		m.secret = []byte(secret)
+		ring.methods = []string{jwt.SigningMethodHS256.Alg()}
+	}
+	m.ring.Store(ring)
+	return m
+}
+
+// NewKeyPairTokenManager signs tokens with signer, an RSA (RS256) or Ed25519 (EdDSA) private key.
+// Tokens signed with the previous keys, such as keys rotated out, are accepted until they expire,
+// and the keys stay published. A non-empty secret keeps accepting the HS256 tokens issued before
+// the switch to key pairs.
+func NewKeyPairTokenManager(signer crypto.Signer, previous []crypto.PublicKey, secret string, ttl time.Duration) (*TokenManager, error) {
+	m := &TokenManager{ttl: ttl}
+	if secret != "" {
+		m.secret = []byte(secret)
+	}
+	if err := m.SetSigningKeys(signer, previous); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// SetSigningKeys switches to signing the tokens with signer and verifying them with its key and
+// the verifying keys, such as the keys it replaces or the keys about to replace it. New keys are
+// published in the key set at once, so every service knows them before they sign tokens.
+func (m *TokenManager) SetSigningKeys(signer crypto.Signer, verifying []crypto.PublicKey) error {
+	signingKey, err := newVerificationKey(signer.Public())
+	if err != nil {
+		return err
+	}
+	ring := &keyRing{
+		signer:     signer,
+		signingKey: signingKey,
+		keys:       make(map[string]*verificationKey),
+	}
+	if m.secret != nil {
+		ring.methods = append(ring.methods, jwt.SigningMethodHS256.Alg())
+	}
+	for _, public := range append([]crypto.PublicKey{signer.Public()}, verifying...) {
+		key, err := newVerificationKey(public)
+		if err != nil {
+			return err
+		}
+		if ring.keys[key.jwk.KeyID] != nil {
+			continue
+		}
+		ring.keys[key.jwk.KeyID] = key
+		ring.jwks.Keys = append(ring.jwks.Keys, key.jwk)
+		if !slices.Contains(ring.methods, key.method.Alg()) {
+			ring.methods = append(ring.methods, key.method.Alg())
+		}
+	}
+	m.ring.Store(ring)
+	return nil
+}
+
+// JWKS returns the public keys verifying the tokens, for services validating them without the
+// secret; the set is empty when tokens are signed with a shared secret
+func (m *TokenManager) JWKS() JWKS {
+	return JWKS{Keys: slices.Clone(m.ring.Load().jwks.Keys)}
+}
+
+// Generate issues a signed access token for the given user ID, roles and tenant
+func (m *TokenManager) Generate(userID string, roles []string, tenantID string) (string, time.Time, error) {
+	return m.GenerateScoped(userID, roles, tenantID, 0, nil)
+}
+
+// GenerateScoped issues an access token like Generate of the token generation of the user, restricted
+// to the scopes, unrestricted when there are none. The user is taken to have just authenticated, see
+// Claims.AuthTime.
+func (m *TokenManager) GenerateScoped(userID string, roles []string, tenantID string, generation int, scopes []string) (string, time.Time, error) {
+	claims := Claims{
+		Roles:      roles,
+		Tenant:     tenantID,
+		Scope:      strings.Join(scopes, " "),
+		AuthTime:   jwt.NewNumericDate(time.Now()),
+		Generation: generation,
+	}
+	return m.sign(claims, userID, m.ttl)
+}
+
+// GenerateImpersonation issues a short-lived token for userID flagged with the impersonating admin in the "act" claim,
+// restricted to the scopes of the admin token and revoked like the tokens of the user with its generation
+func (m *TokenManager) GenerateImpersonation(userID string, roles []string, tenantID, impersonatorID string, generation int, scopes []string, ttl time.Duration) (string, time.Time, error) {
+	claims := Claims{
+		Roles:      roles,
+		Tenant:     tenantID,
+		Actor:      &ActorClaims{Subject: impersonatorID},
+		Scope:      strings.Join(scopes, " "),
+		Generation: generation,
+	}
+	return m.sign(claims, userID, ttl)
+}
+
+// GenerateClient issues a token for an OAuth2 client granting the scopes, without user or roles
+func (m *TokenManager) GenerateClient(clientID string, scopes []string, tenantID string) (string, time.Time, error) {
+	claims := Claims{
+		Tenant:   tenantID,
+		ClientID: clientID,
+		Scope:    strings.Join(scopes, " "),
+	}
+	return m.sign(claims, clientID, m.ttl)
+}
+
+// GenerateMFA issues a short-lived token for userID once its password is verified, exchanged for an
+// access token restricted to the scopes once its second factor is verified. Parse refuses it.
+func (m *TokenManager) GenerateMFA(userID, tenantID string, scopes []string, ttl time.Duration) (string, time.Time, error) {
+	claims := Claims{
+		Tenant:  tenantID,
+		Scope:   strings.Join(scopes, " "),
+		Purpose: TokenPurposeMFA,
+	}
+	return m.sign(claims, userID, ttl)
+}
+
+func (m *TokenManager) sign(claims Claims, subject string, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		Issuer:    TokenIssuer,
+		Subject:   subject,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	var token string
+	var err error
+	if ring := m.ring.Load(); ring.signer != nil {
+		t := jwt.NewWithClaims(ring.signingKey.method, claims)
+		t.Header["kid"] = ring.signingKey.jwk.KeyID
+		token, err = t.SignedString(ring.signer)
+	} else if m.secret != nil {
+		token, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+	} else {
+		err = errors.New("no signing key loaded")
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// Parse validates the signature and expiration of an access token and returns its claims
+func (m *TokenManager) Parse(tokenString string) (*Claims, error) {
+	claims, err := m.parse(tokenString)
+	if err != nil || claims.Purpose != "" {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// ParseMFA validates a token issued by GenerateMFA and returns its claims
+func (m *TokenManager) ParseMFA(tokenString string) (*Claims, error) {
+	claims, err := m.parse(tokenString)
+	if err != nil || claims.Purpose != TokenPurposeMFA {
+		return nil, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+func (m *TokenManager) parse(tokenString string) (*Claims, error) {
+	var claims Claims
+	ring := m.ring.Load()
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(t *jwt.Token) (any, error) {
+		if t.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+			if m.secret == nil {
+				return nil, errors.New("shared secret not configured")
+			}
+			return m.secret, nil
+		}
+		// The key named by the token must be of the algorithm of the token
+		kid, _ := t.Header["kid"].(string)
+		key := ring.keys[kid]
+		if key == nil || key.method.Alg() != t.Method.Alg() {
+			return nil, errors.New("unknown signing key")
+		}
+		return key.public, nil
+	}, jwt.WithValidMethods(ring.methods), jwt.WithIssuer(TokenIssuer), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
@@ -0,0 +1,91 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters (RFC 6238) understood by every authenticator app: SHA-1, 6 digits, 30 second steps
+const (
+	TOTPDigits = 6
+	TOTPPeriod = 30 * time.Second
+	// totpSkew is the number of steps before and after the current one whose codes are accepted, for
+	// clocks drifting apart and codes typed as they roll over
+	totpSkew = 1
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a random 160-bit secret, base32-encoded as authenticator apps expect it
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPStep returns the time step t falls in
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / int64(TOTPPeriod/time.Second)
+}
+
+// TOTPCode returns the code of the secret for a time step
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, RFC 4226 section 5.3
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", TOTPDigits, value%uint32(math.Pow10(TOTPDigits))), nil
+}
+
+// VerifyTOTP checks a code of the secret at now and returns the time step it was issued for. Codes
+// of steps up to after are rejected, so that a code cannot be used twice.
+func VerifyTOTP(secret, code string, now time.Time, after int64) (int64, bool) {
+	current := TOTPStep(now)
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= after {
+			continue
+		}
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// TOTPURI returns the otpauth:// URI enrolling the secret in an authenticator app, usually shown as
+// a QR code, labelled with the issuer and account name
+func TOTPURI(issuer, account, secret string) string {
+	query := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(TOTPDigits)},
+		"period":    {fmt.Sprint(int(TOTPPeriod / time.Second))},
+	}
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
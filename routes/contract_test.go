@@ -0,0 +1,106 @@
+package routes_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/frtasoniero/user-management-api/docs"
+	handler "github.com/frtasoniero/user-management-api/internal/adapters/handler/http"
+	"github.com/frtasoniero/user-management-api/pkg/openapi"
+	"github.com/frtasoniero/user-management-api/routes/routestest"
+)
+
+func loadContract(t *testing.T) *openapi.Spec {
+	t.Helper()
+	spec, err := handler.LoadContract([]byte(docs.SwaggerInfo.ReadDoc()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return spec
+}
+
+// TestRoutesMatchContract checks the requests and responses of the golden cases against the
+// Swagger documentation; run make swagger after changing the annotations of a handler
+func TestRoutesMatchContract(t *testing.T) {
+	spec := loadContract(t)
+	for _, tc := range routeCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			method, route, _ := strings.Cut(tc.route, " ")
+			// Routes outside of the API, such as the key set, are not in the documentation
+			if !strings.HasPrefix(route, spec.BasePath+"/") {
+				t.Skip("route outside of the documented API")
+			}
+
+			req, w := serve(t, tc)
+
+			err := spec.ValidateRequest(route, routestest.NewRequest(req), []byte(req.Body))
+			if err != nil && !tc.invalid {
+				t.Errorf("request does not match the documentation: %v", err)
+			}
+			if err := spec.ValidateResponse(method, route, w.Code, w.Header(), w.Body.Bytes()); err != nil {
+				t.Errorf("response does not match the documentation: %v", err)
+			}
+		})
+	}
+}
+
+// TestEveryRouteIsDocumented checks that the documentation describes the registered routes and nothing else
+func TestEveryRouteIsDocumented(t *testing.T) {
+	spec := loadContract(t)
+	registered := make(map[string]bool)
+	for _, route := range routestest.New(t).Router.Routes() {
+		// Routes outside of the API, such as the readiness check, are not in the documentation
+		if !strings.HasPrefix(route.Path, spec.BasePath+"/") {
+			continue
+		}
+		registered[strings.ToLower(route.Method)+" "+spec.PathOf(route.Path)] = true
+		if _, err := spec.Operation(route.Method, route.Path); err != nil {
+			t.Errorf("route %s %s: %v", route.Method, route.Path, err)
+		}
+	}
+
+	for path, methods := range spec.Paths {
+		for method := range methods {
+			if !registered[method+" "+path] {
+				t.Errorf("documented operation %s %s has no route", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+// TestOpenAPIDocumentIsCurrent checks that the document served at /api/v1/openapi.json, built from
+// the routes and bodies of the binary, is the generated documentation; run make swagger when it is not
+func TestOpenAPIDocumentIsCurrent(t *testing.T) {
+	w := routestest.New(t).Do(routestest.Request{Method: http.MethodGet, Target: "/api/v1/openapi.json"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+
+	var served, generated map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &served); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &generated); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, section := range []string{"paths", "definitions"} {
+		servedItems, _ := served[section].(map[string]any)
+		generatedItems, _ := generated[section].(map[string]any)
+		for name, item := range servedItems {
+			if !reflect.DeepEqual(item, generatedItems[name]) {
+				got, _ := json.Marshal(item)
+				want, _ := json.Marshal(generatedItems[name])
+				t.Errorf("%s %s differs from the generated documentation\nserved:    %s\ngenerated: %s", section, name, got, want)
+			}
+		}
+		for name := range generatedItems {
+			if _, ok := servedItems[name]; !ok {
+				t.Errorf("%s %s of the generated documentation is not served", section, name)
+			}
+		}
+	}
+}
@@ -3,10 +3,18 @@ package routes
 
 import (
 	"net/http"
+	"net/http/pprof"
+	"slices"
+	"time"
 
 	handler "github.com/frtasoniero/user-management-api/internal/adapters/handler/http"
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
 	"github.com/frtasoniero/user-management-api/internal/core/usecase"
 	"github.com/frtasoniero/user-management-api/internal/repository"
+	"github.com/frtasoniero/user-management-api/pkg/i18n"
+	"github.com/frtasoniero/user-management-api/pkg/openapi"
+	"github.com/frtasoniero/user-management-api/pkg/security"
 	"github.com/gin-gonic/gin"
 
 	// Swagger imports
@@ -14,22 +22,541 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
-func RegisterRoutes(router *gin.Engine, userRepo *repository.UserRepository) {
-	userUseCase := usecase.NewUserUseCase(userRepo)
-	userHandler := handler.NewUserHandler(userUseCase)
+// Dependencies groups the repositories, services and settings the API routes are built from
+type Dependencies struct {
+	UserRepo      ports.UserRepository
+	OrgRepo       *repository.OrganizationRepository
+	RoleRepo      *repository.RoleRepository
+	AuditRepo     ports.AuditRepository
+	LoginEvents   *repository.LoginEventRepository
+	Sessions      *repository.SessionRepository
+	OAuthClients  *repository.OAuthClientRepository
+	EmailDomains  *repository.EmailDomainRepository
+	Terms         *repository.TermsRepository
+	Relationships *repository.RelationshipRepository
+	Documents     *repository.DocumentRepository
+	UserHistory   *repository.UserHistoryRepository
+	// DocumentStorage keeps the identity document files, downloaded through URLs valid for DocumentURLTTL
+	DocumentStorage ports.PresignedFileStorage
+	DocumentURLTTL  time.Duration
+	// IdentityVerifier runs automated checks of the documents submitted for verification; nil leaves them to staff
+	IdentityVerifier ports.IdentityVerifier
+	AvatarUseCase    ports.AvatarUseCase
+	// DirectorySync syncs the accounts of a tenant with an LDAP directory; nil disables the sync routes
+	DirectorySync ports.DirectorySyncUseCase
+	// SigningKeys rotates the token signing keys stored in the database; nil disables the signing key routes
+	SigningKeys ports.SigningKeyUseCase
+	// Jobs runs the background jobs, listed and retried by the job routes
+	Jobs ports.JobUseCase
+	// Stats lists the daily user statistics rolled up by the stats jobs
+	Stats ports.StatsUseCase
+	// Scheduler reports the periodic tasks enqueuing the scheduled jobs
+	Scheduler ports.SchedulerReporter
+	Tokens    *security.TokenManager
+	// Mailer sends the new-device login notifications, whose "wasn't me" link points to LoginReportURL
+	Mailer         ports.Mailer
+	LoginReportURL string
+	// SMS texts the codes verifying phone numbers and the login codes of SMS factors, valid for PhoneCodeTTL
+	SMS          ports.SMSSender
+	PhoneCodeTTL time.Duration
+	// MFAIssuer names the TOTP factors in authenticator apps; devices trusted after a login with a
+	// second factor skip it for TrustedDeviceTTL
+	MFAIssuer        string
+	TrustedDeviceTTL time.Duration
+	// ExternalTokens verifies the tokens of an external identity provider, accepted next to the
+	// tokens of the API; nil only accepts the tokens of the API
+	ExternalTokens ports.TokenVerifier
+	// IntrospectionClients are the clients allowed to introspect tokens; empty disables the introspection endpoint
+	IntrospectionClients handler.ClientCredentials
+	// GeoIP locates the IP addresses of logins; nil leaves them without location
+	GeoIP ports.GeoIPResolver
+	// LoginAnomalyPolicy flags logins far from the previous one, which needs GeoIP
+	LoginAnomalyPolicy domain.LoginAnomalyPolicy
+	// ImpersonationTTL is the lifetime of admin impersonation tokens
+	ImpersonationTTL time.Duration
+	// RecentAuthMaxAge is how long after proving their password or a second factor users reach the
+	// sensitive routes, such as removing a second factor or deleting their account
+	RecentAuthMaxAge time.Duration
+	// AuthMode is domain.AuthModeCookie for logins to set session cookies lasting SessionTTL instead
+	// of answering bearer tokens, which are still accepted
+	AuthMode       string
+	SessionTTL     time.Duration
+	MetadataPolicy domain.MetadataPolicy
+	// PasswordPolicy is enforced on registration and reported by the password strength endpoint
+	PasswordPolicy domain.PasswordPolicy
+	// PasswordBreaches looks the passwords accepted by PasswordPolicy up in known data breaches
+	PasswordBreaches ports.PasswordBreachCheck
+	// AgePolicy is enforced on registration and on changes of the birthdate
+	AgePolicy domain.AgePolicy
+	// CustomFields are the extra fields users register with, configured by REGISTRATION_FIELDS
+	CustomFields domain.CustomFieldSchema
+	// EmailDomainPolicy lists the email domains allowed and denied to register in every tenant
+	EmailDomainPolicy domain.EmailDomainPolicy
+	// UpsertPolicy resolves the conflicts of PUT /users/by-email/{email} without on_conflict
+	UpsertPolicy    string
+	Geocoding       ports.AddressGeocoding
+	Tenancy         handler.TenantResolver
+	BodyLimits      handler.BodyLimits
+	RequestTimeouts handler.RequestTimeouts
+	RateLimiter     ports.RateLimiter
+	IPBackoff       ports.IPBackoff
+	// Config is the runtime configuration of the instance: the log level, rate limits, feature flags and
+	// CORS origins reloaded without restarting
+	Config ports.ConfigUseCase
+	// EmailCheckMaxDelay randomly delays email checks to slow down account enumeration
+	EmailCheckMaxDelay time.Duration
+	// LastSeenInterval is how often the last-seen time of an active user is written
+	LastSeenInterval time.Duration
+	// DatabaseRetries reports the reads of UserRepo retried after transient database errors
+	DatabaseRetries ports.RetryReporter
+	// DatabaseBreaker reports the circuit breaker of UserRepo, failing requests fast while the database is down
+	DatabaseBreaker ports.BreakerReporter
+	// SlowQueries reports the database operations slower than the slow query threshold
+	SlowQueries ports.SlowQueryReporter
+	// Profiling mounts the net/http/pprof endpoints under /debug/pprof for callers with system:debug
+	Profiling bool
+	// Contract logs the requests and responses not matching the Swagger documentation; nil disables it
+	Contract *openapi.Spec
+	// OpenAPI is the Swagger document served at /api/v1/openapi.json, updated to the registered routes
+	// and the request and response bodies of the binary; nil disables the endpoint
+	OpenAPI []byte
+	// Swagger mounts the Swagger UI under /swagger/
+	Swagger bool
+	// DocsAuth protects the Swagger UI and the OpenAPI document; the zero value leaves them public
+	DocsAuth DocsAuth
+	// AdminDashboard mounts the admin dashboard under /admin/
+	AdminDashboard bool
+	// HealthChecks are the dependencies reported at /readyz
+	HealthChecks []ports.HealthCheck
+	// I18n translates error messages from Accept-Language; nil leaves them in English
+	I18n *i18n.Catalog
+}
+
+// DocsAuth protects the API documentation with basic auth when Username is set, or else with a
+// bearer token of the tenant holding system:read when Admin is set
+type DocsAuth struct {
+	Username string
+	Password string
+	Admin    bool
+}
+
+// UseCases are the use cases the route handlers call
+type UseCases struct {
+	Users         ports.UserUseCase
+	Organizations ports.OrganizationUseCase
+	Roles         ports.RoleUseCase
+	Audit         ports.AuditUseCase
+	Merge         ports.AccountMergeUseCase
+	Export        ports.ExportUseCase
+	LoginEvents   ports.LoginEventUseCase
+	Avatars       ports.AvatarUseCase
+	DirectorySync ports.DirectorySyncUseCase
+	ExternalAuth  ports.ExternalAuthUseCase
+	SigningKeys   ports.SigningKeyUseCase
+	OAuthClients  ports.OAuthClientUseCase
+	EmailDomains  ports.EmailDomainUseCase
+	Terms         ports.TermsUseCase
+	Jobs          ports.JobUseCase
+	Stats         ports.StatsUseCase
+	Relationships ports.RelationshipUseCase
+	Documents     ports.DocumentUseCase
+	Verification  ports.VerificationUseCase
+	Phone         ports.PhoneVerificationUseCase
+	MFA           ports.MFAUseCase
+	Security      ports.SecurityEventUseCase
+	Sessions      ports.SessionUseCase
+	UserHistory   ports.UserHistoryUseCase
+	Config        ports.ConfigUseCase
+}
+
+// NewUseCases builds the use cases from the repositories and services of deps
+func NewUseCases(deps Dependencies) UseCases {
+	auditUseCase := usecase.NewAuditUseCase(deps.AuditRepo)
+	var externalAuth ports.ExternalAuthUseCase
+	if deps.ExternalTokens != nil {
+		externalAuth = usecase.NewExternalAuthUseCase(deps.ExternalTokens, deps.UserRepo, auditUseCase)
+	}
+	var sessions ports.SessionUseCase
+	if deps.AuthMode == domain.AuthModeCookie {
+		sessions = usecase.NewSessionUseCase(deps.Sessions, deps.SessionTTL)
+	}
+	emailDomains := usecase.NewEmailDomainUseCase(deps.EmailDomains, deps.EmailDomainPolicy, auditUseCase)
+	return UseCases{
+		Users:         usecase.NewUserUseCase(deps.UserRepo, deps.MetadataPolicy, deps.PasswordPolicy, deps.AgePolicy, deps.Geocoding, deps.PasswordBreaches, auditUseCase, deps.UpsertPolicy, emailDomains, deps.CustomFields),
+		Organizations: usecase.NewOrganizationUseCase(deps.OrgRepo, deps.UserRepo),
+		Roles:         usecase.NewRoleUseCase(deps.RoleRepo, deps.UserRepo),
+		Audit:         auditUseCase,
+		Merge:         usecase.NewAccountMergeUseCase(deps.UserRepo, deps.OrgRepo, auditUseCase),
+		Export:        usecase.NewExportUseCase(deps.UserRepo, auditUseCase),
+		LoginEvents:   usecase.NewLoginEventUseCase(deps.LoginEvents, deps.UserRepo, auditUseCase, deps.Mailer, deps.GeoIP, deps.LoginAnomalyPolicy, deps.LoginReportURL),
+		Avatars:       deps.AvatarUseCase,
+		DirectorySync: deps.DirectorySync,
+		ExternalAuth:  externalAuth,
+		SigningKeys:   deps.SigningKeys,
+		OAuthClients:  usecase.NewOAuthClientUseCase(deps.OAuthClients, auditUseCase),
+		EmailDomains:  emailDomains,
+		Terms:         usecase.NewTermsUseCase(deps.Terms, auditUseCase),
+		Jobs:          deps.Jobs,
+		Stats:         deps.Stats,
+		Relationships: usecase.NewRelationshipUseCase(deps.Relationships, deps.UserRepo),
+		Documents:     usecase.NewDocumentUseCase(deps.Documents, deps.UserRepo, deps.DocumentStorage, auditUseCase, deps.DocumentURLTTL),
+		Verification:  usecase.NewVerificationUseCase(deps.UserRepo, deps.Documents, deps.DocumentStorage, deps.IdentityVerifier, auditUseCase, deps.Mailer),
+		Phone:         usecase.NewPhoneVerificationUseCase(deps.UserRepo, deps.SMS, deps.PhoneCodeTTL),
+		MFA:           usecase.NewMFAUseCase(deps.UserRepo, deps.SMS, auditUseCase, deps.MFAIssuer, deps.PhoneCodeTTL, deps.TrustedDeviceTTL),
+		Security:      usecase.NewSecurityEventUseCase(deps.AuditRepo, deps.LoginEvents),
+		Sessions:      sessions,
+		UserHistory:   usecase.NewUserHistoryUseCase(deps.UserHistory, deps.UserRepo, auditUseCase),
+		Config:        deps.Config,
+	}
+}
+
+func RegisterRoutes(router *gin.Engine, deps Dependencies) {
+	RegisterHandlers(router, deps, NewUseCases(deps))
+}
+
+// RegisterHandlers mounts the routes on router, calling useCases; the repositories of deps are not
+// used, so tests can serve the routes from fake use cases
+func RegisterHandlers(router *gin.Engine, deps Dependencies, useCases UseCases) {
+	userUseCase := useCases.Users
+	orgUseCase := useCases.Organizations
+	roleUseCase := useCases.Roles
+	auditUseCase := useCases.Audit
+	// Session cookies only authenticate requests in cookie mode
+	var sessions ports.SessionUseCase
+	if deps.AuthMode == domain.AuthModeCookie {
+		sessions = useCases.Sessions
+	}
+	userHandler := handler.NewUserHandler(userUseCase, useCases.Relationships)
+	avatarHandler := handler.NewAvatarHandler(useCases.Avatars)
+	authHandler := handler.NewAuthHandler(userUseCase, useCases.MFA, auditUseCase, roleUseCase, useCases.LoginEvents, deps.IPBackoff, deps.Tokens, sessions, deps.ImpersonationTTL)
+	orgHandler := handler.NewOrganizationHandler(orgUseCase)
+	relationshipHandler := handler.NewRelationshipHandler(useCases.Relationships)
+	userHistoryHandler := handler.NewUserHistoryHandler(useCases.UserHistory)
+	documentHandler := handler.NewDocumentHandler(useCases.Documents)
+	verificationHandler := handler.NewVerificationHandler(useCases.Verification)
+	phoneHandler := handler.NewPhoneHandler(useCases.Phone)
+	mfaHandler := handler.NewMFAHandler(useCases.MFA)
+	securityEventHandler := handler.NewSecurityEventHandler(useCases.Security)
+	roleHandler := handler.NewRoleHandler(roleUseCase)
+	auditHandler := handler.NewAuditHandler(auditUseCase)
+	mergeHandler := handler.NewMergeHandler(useCases.Merge)
+	exportHandler := handler.NewExportHandler(useCases.Export)
+	oauthClientHandler := handler.NewOAuthClientHandler(useCases.OAuthClients, deps.Tokens)
+	emailDomainHandler := handler.NewEmailDomainHandler(useCases.EmailDomains)
+	termsHandler := handler.NewTermsHandler(useCases.Terms)
+	jobHandler := handler.NewJobHandler(useCases.Jobs)
+	statsHandler := handler.NewStatsHandler(useCases.Stats)
+	securityHandler := handler.NewSecurityHandler(deps.IPBackoff)
+	configHandler := handler.NewConfigHandler(useCases.Config)
+	systemHandler := handler.NewSystemHandler(deps.DatabaseRetries, deps.DatabaseBreaker, deps.SlowQueries, deps.Scheduler)
+	availabilityHandler := handler.NewAvailabilityHandler(userUseCase, deps.EmailCheckMaxDelay)
+
+	// Rate limits are read from the runtime configuration on every request
+	config := useCases.Config
+	apiLimit := func() ports.RateLimit { return config.Config().RateLimits.API }
+	authLimit := func() ports.RateLimit { return config.Config().RateLimits.Auth }
+	emailCheckLimit := func() ports.RateLimit { return config.Config().RateLimits.EmailCheck }
+
+	// Browsers of the allowed origins call every route, their preflight requests answered before routing
+	router.Use(handler.CORS(config))
+
+	// Authenticated routes also audit every request made with an impersonation token,
+	// count against the API limit of the account and update its last-seen time
+	trackLastSeen := handler.TrackLastSeen(userUseCase, deps.LastSeenInterval)
+	// Tokens issued before a logout from all devices, or to deleted clients, are refused on every route
+	rejectRevoked := handler.RejectRevokedTokens(userUseCase, useCases.OAuthClients)
+	// State-changing requests of cookie sessions must echo their CSRF token
+	requireCSRFToken := handler.RequireCSRFToken()
+	requireAuth := []gin.HandlerFunc{
+		handler.RequireAuth(deps.Tokens, useCases.ExternalAuth, sessions),
+		rejectRevoked,
+		requireCSRFToken,
+		handler.AuditImpersonation(auditUseCase),
+		handler.RateLimitByAccount(deps.RateLimiter, handler.RateLimitClassAPI, apiLimit),
+		trackLastSeen,
+	}
+	// Routes guarded by a permission also accept the tokens of OAuth2 clients holding it as a scope
+	requireAuthOrClient := append([]gin.HandlerFunc{handler.RequireAuthOrClient(deps.Tokens, useCases.ExternalAuth, sessions)}, requireAuth[1:]...)
+	requirePermission := func(permission string) gin.HandlerFunc {
+		return handler.RequirePermission(roleUseCase, permission)
+	}
+	// Restricted tokens only reach the routes of their scopes: reading or changing the account and
+	// organizations of the caller, and the admin routes
+	readScope := handler.RequireScope(domain.ScopeUsersRead)
+	writeScope := handler.RequireScope(domain.ScopeUsersWrite)
+	adminScope := handler.RequireScope(domain.ScopeAdmin)
+	// Sensitive changes of the account need a password or second factor proven recently
+	recentAuth := handler.RequireRecentAuth(deps.RecentAuthMaxAge)
+	// Users are answered 451 until they accept the current terms, which the terms routes let them do
+	requireTerms := handler.RequireTermsAccepted(useCases.Terms)
+
+	// The documentation describes every route, so it is only mounted when configured and can be
+	// protected; browsers only send basic auth credentials to the Swagger UI
+	var docsAuth []gin.HandlerFunc
+	switch {
+	case deps.DocsAuth.Username != "":
+		docsAuth = []gin.HandlerFunc{gin.BasicAuthForRealm(gin.Accounts{deps.DocsAuth.Username: deps.DocsAuth.Password}, "API documentation")}
+	case deps.DocsAuth.Admin:
+		docsAuth = append([]gin.HandlerFunc{handler.ResolveTenant(deps.Tenancy)}, requireAuth...)
+		docsAuth = append(docsAuth, adminScope, requirePermission(domain.PermissionSystemRead))
+	}
+	withDocsAuth := func(h gin.HandlerFunc) []gin.HandlerFunc {
+		return append(slices.Clip(docsAuth), h)
+	}
 
 	// Swagger documentation endpoint
 	// Access at: http://localhost:8080/swagger/index.html
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	if deps.Swagger {
+		router.GET("/swagger/*any", withDocsAuth(ginSwagger.WrapHandler(swaggerfiles.Handler))...)
+	}
+
+	// Admin dashboard, whose calls to the admin routes are authorized like any other
+	if deps.AdminDashboard {
+		dashboardHandler := handler.NewDashboardHandler(handler.DashboardConfig{TenantHeader: deps.Tenancy.Header})
+		router.GET("/admin/*filepath", dashboardHandler.ServeDashboard)
+	}
+
+	// Readiness of the instance, with the health of each of its dependencies
+	router.GET("/readyz", handler.NewReadinessHandler(usecase.NewReadinessUseCase(deps.HealthChecks)).GetReadiness)
 
-	apiGroup := router.Group("/api/v1")
+	// Public keys of the tokens signed with a key pair, validated by other services without the secret
+	if len(deps.Tokens.JWKS().Keys) > 0 {
+		router.GET("/.well-known/jwks.json", handler.NewJWKSHandler(deps.Tokens).GetJWKS)
+	}
+
+	// Traffic is optionally checked against the Swagger documentation, error messages are localized,
+	// oversized and deeply nested request bodies are rejected before binding and handlers get a
+	// deadline, except for the streamed user export
+	handler.UseJSONFieldNames()
+	apiGroup := router.Group("/api/v1",
+		handler.ValidateContract(deps.Contract),
+		handler.LocalizeErrors(deps.I18n),
+		handler.LimitRequestBody(deps.BodyLimits),
+		handler.RequestTimeout(deps.RequestTimeouts, "/api/v1/admin/users/export"),
+	)
 	{
 		apiGroup.GET("/health", healthCheck)
+		apiGroup.GET("/meta/countries", handler.ListCountries)
+		apiGroup.GET("/features", configHandler.GetFeatures)
+		if deps.OpenAPI != nil {
+			apiGroup.GET("/openapi.json", withDocsAuth(handler.NewOpenAPIHandler(deps.OpenAPI, router.Routes).GetDocument)...)
+		}
+
+		// "Wasn't me" links of new-device emails carry their tenant in the token
+		failFast := handler.FailFastWhenDatabaseUnavailable(deps.DatabaseBreaker)
+		apiGroup.GET("/auth/login-report", authHandler.ConfirmLoginReport)
+		apiGroup.POST("/auth/login-report", failFast, authHandler.ReportLogin)
+
+		// Introspected tokens carry their tenant, resource servers authenticate as clients
+		if len(deps.IntrospectionClients) > 0 {
+			introspectionHandler := handler.NewIntrospectionHandler(deps.Tokens, userUseCase, useCases.OAuthClients)
+			apiGroup.POST("/auth/introspect",
+				handler.RateLimitByIP(deps.RateLimiter, handler.RateLimitClassAPI, apiLimit),
+				failFast,
+				handler.RequireClient(deps.IntrospectionClients, "token introspection"),
+				introspectionHandler.Introspect,
+			)
+		}
+
+		// Every other route is scoped to the tenant resolved from the subdomain or tenant header,
+		// rate limited per client IP and answers 503 at once while the database is down
+		tenantGroup := apiGroup.Group("",
+			handler.ResolveTenant(deps.Tenancy),
+			handler.RateLimitByIP(deps.RateLimiter, handler.RateLimitClassAPI, apiLimit),
+			failFast,
+		)
+
+		// Auth routes, limited per IP and per targeted account
+		tenantGroup.POST("/auth/login",
+			handler.RateLimitByIP(deps.RateLimiter, handler.RateLimitClassAuth, authLimit),
+			handler.RateLimitByLoginEmail(deps.RateLimiter, handler.RateLimitClassAuth, authLimit),
+			authHandler.Login,
+		)
+		tenantGroup.POST("/auth/mfa/challenge",
+			handler.RateLimitByIP(deps.RateLimiter, handler.RateLimitClassAuth, authLimit),
+			authHandler.SendMFACode,
+		)
+		tenantGroup.POST("/auth/mfa/verify",
+			handler.RateLimitByIP(deps.RateLimiter, handler.RateLimitClassAuth, authLimit),
+			authHandler.VerifyMFA,
+		)
+		tenantGroup.POST("/auth/password-strength", authHandler.EvaluatePassword)
+		reauthGroup := tenantGroup.Group("/auth/reauthenticate",
+			append(slices.Clip(requireAuth), handler.RateLimitByIP(deps.RateLimiter, handler.RateLimitClassAuth, authLimit))...)
+		reauthGroup.POST("", authHandler.Reauthenticate)
+		reauthGroup.POST("/challenge", authHandler.SendReauthenticationCode)
+		tenantGroup.POST("/auth/logout", append(slices.Clip(requireAuth), authHandler.Logout)...)
+		tenantGroup.GET("/auth/csrf-token", append(slices.Clip(requireAuth), authHandler.GetCSRFToken)...)
+		tenantGroup.POST("/auth/token",
+			handler.RateLimitByIP(deps.RateLimiter, handler.RateLimitClassAuth, authLimit),
+			oauthClientHandler.IssueToken,
+		)
+
+		// User routes; user activity is only shown to callers with the users:activity permission.
+		// Their user reads see the writes of the previous requests of the client.
+		readYourWrites := handler.ReadYourWrites()
+		viewerGroup := tenantGroup.Group("",
+			readYourWrites,
+			handler.OptionalAuth(deps.Tokens, useCases.ExternalAuth, sessions),
+			rejectRevoked,
+			requireCSRFToken,
+			handler.CheckPermission(roleUseCase, domain.PermissionUsersActivity),
+			trackLastSeen,
+		)
+		viewerGroup.GET("/users", handler.CheckPermission(roleUseCase, domain.PermissionSystemRead), userHandler.GetUsers)
+		viewerGroup.GET("/users/count", userHandler.CountUsers)
+		tenantGroup.GET("/users/check-email", handler.RateLimitByIP(deps.RateLimiter, handler.RateLimitClassEmailCheck, emailCheckLimit), availabilityHandler.CheckEmail)
+		viewerGroup.POST("/users/search", userHandler.SearchUsers)
+		viewerGroup.POST("/users/lookup", userHandler.LookupUsers)
+		viewerGroup.GET("/users/autocomplete", userHandler.AutocompleteUsers)
+		viewerGroup.GET("/users/by-username/:username", userHandler.GetUserByUsername)
+		viewerGroup.GET("/users/:id", userHandler.GetUserByID)
+		viewerGroup.GET("/users/:id/followers", relationshipHandler.ListFollowers)
+		viewerGroup.GET("/users/:id/following", relationshipHandler.ListFollowing)
+		tenantGroup.HEAD("/users/:id", userHandler.UserExists)
+		tenantGroup.POST("/users/register", readYourWrites, userHandler.Register)
+		tenantGroup.GET("/users/register/fields", userHandler.GetRegistrationFields)
+		// Avatars are uploaded by their user or by staff holding users:profile
+		tenantGroup.POST("/users/:id/avatar",
+			append(slices.Clip(requireAuth), requireTerms, writeScope, handler.CheckPermission(roleUseCase, domain.PermissionUsersProfile), avatarHandler.UploadAvatar)...)
+		tenantGroup.GET("/terms", termsHandler.ListCurrentTerms)
+
+		// Terms acceptance of the authenticated user, reachable before accepting them
+		termsGroup := tenantGroup.Group("/users/me/terms", requireAuth...)
+		termsGroup.GET("", readScope, termsHandler.GetMyTerms)
+		termsGroup.POST("/accept", writeScope, termsHandler.AcceptMyTerms)
+
+		// Authenticated user routes
+		meGroup := tenantGroup.Group("/users/me", append(slices.Clip(requireAuth), requireTerms, readYourWrites)...)
+		meGroup.DELETE("", writeScope, recentAuth, userHandler.DeleteMyAccount)
+		meGroup.GET("/settings", readScope, userHandler.GetMySettings)
+		meGroup.GET("/login-history", readScope, authHandler.ListMyLoginHistory)
+		meGroup.POST("/logout-all", writeScope, authHandler.LogoutAll)
+		meGroup.GET("/security-events", readScope, securityEventHandler.ListMySecurityEvents)
+		meGroup.PATCH("/settings", writeScope, userHandler.UpdateMySettings)
+		meGroup.PATCH("/profile", writeScope, userHandler.UpdateMyProfile)
+		meGroup.POST("/phone/verify", writeScope, phoneHandler.SendPhoneCode)
+		meGroup.POST("/phone/confirm", writeScope, phoneHandler.ConfirmPhone)
+		meGroup.GET("/mfa/factors", readScope, mfaHandler.ListMyMFAFactors)
+		meGroup.POST("/mfa/factors", writeScope, mfaHandler.AddMyMFAFactor)
+		meGroup.POST("/mfa/factors/:factorId/confirm", writeScope, mfaHandler.ConfirmMyMFAFactor)
+		meGroup.DELETE("/mfa/factors/:factorId", writeScope, recentAuth, mfaHandler.RemoveMyMFAFactor)
+		meGroup.GET("/trusted-devices", readScope, mfaHandler.ListMyTrustedDevices)
+		meGroup.DELETE("/trusted-devices", writeScope, mfaHandler.RevokeMyTrustedDevices)
+		meGroup.DELETE("/trusted-devices/:deviceId", writeScope, mfaHandler.RevokeMyTrustedDevice)
+		meGroup.PUT("/username", writeScope, userHandler.UpdateMyUsername)
+		meGroup.GET("/addresses", readScope, userHandler.ListMyAddresses)
+		meGroup.POST("/addresses", writeScope, userHandler.AddMyAddress)
+		meGroup.PUT("/addresses/:addressId", writeScope, userHandler.UpdateMyAddress)
+		meGroup.DELETE("/addresses/:addressId", writeScope, userHandler.RemoveMyAddress)
+		meGroup.GET("/organizations", readScope, orgHandler.ListMyOrganizations)
+		meGroup.PUT("/following/:userId", writeScope, relationshipHandler.Follow)
+		meGroup.DELETE("/following/:userId", writeScope, relationshipHandler.Unfollow)
+		meGroup.GET("/blocks", readScope, relationshipHandler.ListBlocks)
+		meGroup.POST("/blocks/:id", writeScope, relationshipHandler.Block)
+		meGroup.DELETE("/blocks/:id", writeScope, relationshipHandler.Unblock)
+
+		// Identity documents, uploaded and listed by their user or by staff holding users:documents,
+		// and only downloaded by staff
+		documentGroup := tenantGroup.Group("/users/:id/documents",
+			append(slices.Clip(requireAuthOrClient), requireTerms, handler.CheckPermission(roleUseCase, domain.PermissionUsersDocuments))...)
+		documentGroup.POST("", documentHandler.UploadDocument)
+		documentGroup.GET("", documentHandler.ListDocuments)
+		documentGroup.GET("/:documentId/download", adminScope, requirePermission(domain.PermissionUsersDocuments), documentHandler.GetDocumentDownload)
+
+		// Identity verification, submitted and read like the documents, and reviewed by staff holding users:verify
+		verificationGroup := tenantGroup.Group("/users/:id/verification",
+			append(slices.Clip(requireAuthOrClient), requireTerms, handler.CheckPermission(roleUseCase, domain.PermissionUsersDocuments))...)
+		verificationGroup.POST("", verificationHandler.SubmitVerification)
+		verificationGroup.GET("", verificationHandler.GetVerification)
+		verificationGroup.POST("/review", adminScope, requirePermission(domain.PermissionUsersVerify), verificationHandler.ReviewVerification)
+		// Results of the checks of the identity verification provider, authenticated by their signature
+		tenantGroup.POST("/webhooks/identity-verification", verificationHandler.HandleCheckWebhook)
+
+		// Organization routes
+		orgGroup := tenantGroup.Group("/organizations", append(slices.Clip(requireAuth), requireTerms)...)
+		orgGroup.POST("", writeScope, orgHandler.CreateOrganization)
+		orgGroup.GET("/:id", readScope, orgHandler.GetOrganization)
+		orgGroup.PATCH("/:id", writeScope, orgHandler.UpdateOrganization)
+		orgGroup.DELETE("/:id", writeScope, orgHandler.DeleteOrganization)
+		orgGroup.GET("/:id/members", readScope, orgHandler.ListMembers)
+		orgGroup.PUT("/:id/members/:userId", writeScope, orgHandler.SetMember)
+		orgGroup.DELETE("/:id/members/:userId", writeScope, orgHandler.RemoveMember)
+
+		// Admin routes, each guarded by the admin scope and the permission it requires
+		staffGroup := tenantGroup.Group("", append(slices.Clip(requireAuthOrClient), requireTerms, adminScope, readYourWrites)...)
+		staffGroup.PATCH("/users/:id/metadata", requirePermission(domain.PermissionUsersMeta), userHandler.UpdateUserMetadata)
+		staffGroup.PATCH("/users/:id/profile", requirePermission(domain.PermissionUsersProfile), userHandler.UpdateUserProfile)
+		staffGroup.PUT("/users/by-email/:email", requirePermission(domain.PermissionUsersSync), userHandler.UpsertUserByEmail)
+		staffGroup.POST("/users/find-or-create", requirePermission(domain.PermissionUsersSync), userHandler.FindOrCreateUser)
+		staffGroup.POST("/users/:id/tags", requirePermission(domain.PermissionUsersTags), userHandler.AddUserTags)
+		staffGroup.DELETE("/users/:id/tags/:tag", requirePermission(domain.PermissionUsersTags), userHandler.RemoveUserTag)
+		staffGroup.GET("/users/:id/history", requirePermission(domain.PermissionUsersHistory), userHistoryHandler.GetUserHistory)
+		staffGroup.POST("/users/:id/revert", requirePermission(domain.PermissionUsersProfile), userHistoryHandler.RevertUser)
+
+		adminGroup := tenantGroup.Group("/admin", append(slices.Clip(requireAuthOrClient), requireTerms, adminScope)...)
+		adminGroup.GET("/roles", requirePermission(domain.PermissionRolesManage), roleHandler.ListRoles)
+		adminGroup.POST("/roles", requirePermission(domain.PermissionRolesManage), roleHandler.CreateRole)
+		adminGroup.GET("/roles/:name", requirePermission(domain.PermissionRolesManage), roleHandler.GetRole)
+		adminGroup.PATCH("/roles/:name", requirePermission(domain.PermissionRolesManage), roleHandler.UpdateRole)
+		adminGroup.DELETE("/roles/:name", requirePermission(domain.PermissionRolesManage), roleHandler.DeleteRole)
+		adminGroup.PUT("/users/:id/roles/:name", requirePermission(domain.PermissionRolesAssign), roleHandler.AssignRole)
+		adminGroup.DELETE("/users/:id/roles/:name", requirePermission(domain.PermissionRolesAssign), roleHandler.UnassignRole)
+		adminGroup.POST("/users/:id/impersonate", requirePermission(domain.PermissionUsersImpersonate), authHandler.Impersonate)
+		adminGroup.GET("/users/duplicates", requirePermission(domain.PermissionUsersMerge), mergeHandler.ListDuplicateUsers)
+		adminGroup.POST("/users/:id/merge", requirePermission(domain.PermissionUsersMerge), mergeHandler.MergeUsers)
+		adminGroup.PATCH("/users/batch", requirePermission(domain.PermissionUsersBatch), userHandler.BatchUpdateUsers)
+		adminGroup.GET("/users/export",
+			requirePermission(domain.PermissionUsersExport),
+			handler.CheckPermission(roleUseCase, domain.PermissionUsersActivity),
+			exportHandler.ExportUsers,
+		)
+		adminGroup.GET("/audit-logs", requirePermission(domain.PermissionAuditRead), auditHandler.ListAuditEvents)
+		adminGroup.GET("/security/ip-blocks", requirePermission(domain.PermissionSecurityManage), securityHandler.ListIPBlocks)
+		adminGroup.DELETE("/security/ip-blocks/:ip", requirePermission(domain.PermissionSecurityManage), securityHandler.ClearIPBlock)
+		if useCases.SigningKeys != nil {
+			signingKeyHandler := handler.NewSigningKeyHandler(useCases.SigningKeys)
+			adminGroup.GET("/security/signing-keys", requirePermission(domain.PermissionSecurityManage), signingKeyHandler.ListSigningKeys)
+			adminGroup.POST("/security/signing-keys/rotate", requirePermission(domain.PermissionSecurityManage), signingKeyHandler.RotateSigningKey)
+		}
+		adminGroup.GET("/oauth-clients", requirePermission(domain.PermissionSecurityManage), oauthClientHandler.ListClients)
+		adminGroup.POST("/oauth-clients", requirePermission(domain.PermissionSecurityManage), oauthClientHandler.RegisterClient)
+		adminGroup.GET("/oauth-clients/:id", requirePermission(domain.PermissionSecurityManage), oauthClientHandler.GetClient)
+		adminGroup.DELETE("/oauth-clients/:id", requirePermission(domain.PermissionSecurityManage), oauthClientHandler.DeleteClient)
+		adminGroup.GET("/email-domains", requirePermission(domain.PermissionSecurityManage), emailDomainHandler.ListEmailDomains)
+		adminGroup.POST("/email-domains", requirePermission(domain.PermissionSecurityManage), emailDomainHandler.AddEmailDomain)
+		adminGroup.DELETE("/email-domains/:list/:domain", requirePermission(domain.PermissionSecurityManage), emailDomainHandler.RemoveEmailDomain)
+		adminGroup.GET("/terms", requirePermission(domain.PermissionTermsManage), termsHandler.ListTermsVersions)
+		adminGroup.POST("/terms", requirePermission(domain.PermissionTermsManage), termsHandler.PublishTermsVersion)
+		adminGroup.GET("/system/database", requirePermission(domain.PermissionSystemRead), systemHandler.GetDatabaseStats)
+		adminGroup.GET("/system/scheduler", requirePermission(domain.PermissionSystemRead), systemHandler.GetScheduler)
+		adminGroup.GET("/system/config", requirePermission(domain.PermissionSystemRead), configHandler.GetConfig)
+		adminGroup.PATCH("/system/config", requirePermission(domain.PermissionSystemConfig), configHandler.UpdateConfig)
+		adminGroup.GET("/stats/users", requirePermission(domain.PermissionSystemRead), statsHandler.GetUserStats)
+		adminGroup.GET("/analytics/registrations", requirePermission(domain.PermissionSystemRead), statsHandler.GetRegistrations)
+		adminGroup.GET("/jobs", requirePermission(domain.PermissionJobsManage), jobHandler.ListJobs)
+		adminGroup.GET("/jobs/:id", requirePermission(domain.PermissionJobsManage), jobHandler.GetJob)
+		adminGroup.POST("/jobs/:id/retry", requirePermission(domain.PermissionJobsManage), jobHandler.RetryJob)
+		if useCases.DirectorySync != nil {
+			directorySyncHandler := handler.NewDirectorySyncHandler(useCases.DirectorySync)
+			adminGroup.GET("/directory-sync", requirePermission(domain.PermissionUsersSync), directorySyncHandler.GetStatus)
+			adminGroup.POST("/directory-sync", requirePermission(domain.PermissionUsersSync), directorySyncHandler.TriggerSync)
+		}
+	}
 
-		// User routes
-		apiGroup.GET("/users", userHandler.GetUsers)
-		apiGroup.GET("/users/:id", userHandler.GetUserByID)
-		apiGroup.POST("/users/register", userHandler.Register)
+	// Profiles of the running instance, downloaded with an Authorization header and read with go tool pprof;
+	// they expose memory contents, so they are disabled unless configured and admin only
+	if deps.Profiling {
+		debugGroup := router.Group("/debug/pprof", handler.ResolveTenant(deps.Tenancy))
+		debugGroup.Use(requireAuth...)
+		debugGroup.Use(adminScope, requirePermission(domain.PermissionSystemDebug))
+		debugGroup.GET("/", gin.WrapF(pprof.Index))
+		debugGroup.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+		debugGroup.GET("/profile", gin.WrapF(pprof.Profile))
+		debugGroup.GET("/symbol", gin.WrapF(pprof.Symbol))
+		debugGroup.POST("/symbol", gin.WrapF(pprof.Symbol))
+		debugGroup.GET("/trace", gin.WrapF(pprof.Trace))
+		debugGroup.GET("/:profile", gin.WrapF(pprof.Index)) // heap, goroutine, allocs, block, mutex, threadcreate
 	}
 }
 
@@ -0,0 +1,3515 @@
+package routes_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/core/usecase"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+	"github.com/frtasoniero/user-management-api/internal/repository"
+	"github.com/frtasoniero/user-management-api/internal/repository/repositorytest"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+	"github.com/frtasoniero/user-management-api/routes"
+	"github.com/frtasoniero/user-management-api/routes/routestest"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Callers of the cases: anonymous, a regular user, an admin or a support agent, whose custom role
+// only grants the permissions of supportRoles
+const (
+	anonymous = ""
+	asUser    = domain.RoleUser
+	asAdmin   = domain.RoleAdmin
+	asSupport = "support"
+)
+
+// userIDs are the IDs of the tokens of each caller
+var userIDs = map[string]string{asUser: "u1", asAdmin: "admin1", asSupport: "support1"}
+
+var created = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// routeCase is a request to one route whose response is compared with testdata/golden/<name>.json
+type routeCase struct {
+	name   string
+	route  string // Method and full path of the route, see gin.RouteInfo
+	req    routestest.Request
+	as     string
+	deps   func(deps *routes.Dependencies) // Settings other than those of routestest.New
+	setup  func(h *routestest.Harness)
+	ignore []string // Body fields varying between runs
+	// invalid marks the requests that break the documentation on purpose, e.g. omitting a required field
+	invalid bool
+}
+
+// evaluatePasswords evaluates passwords with policy, as the user use case does
+func evaluatePasswords(policy domain.PasswordPolicy) func(*routestest.Harness) {
+	return func(h *routestest.Harness) {
+		h.Users.EvaluatePasswordFunc = func(_ context.Context, password string, userInputs ...string) domain.PasswordEvaluation {
+			return policy.Evaluate(password, userInputs...)
+		}
+	}
+}
+
+// sampleMFAFactors are a confirmed TOTP factor and an SMS factor of u1
+func sampleMFAFactors() []domain.MFAFactor {
+	confirmed := created.Add(time.Minute)
+	return []domain.MFAFactor{
+		{ID: "f1", Type: domain.MFAFactorTOTP, Label: "Phone app", CreatedAt: created, ConfirmedAt: &confirmed, Secret: "JBSWY3DPEHPK3PXP"},
+		{ID: "f2", Type: domain.MFAFactorSMS, Phone: "+15551234567", CreatedAt: created, ConfirmedAt: &created},
+	}
+}
+
+// sampleTrustedDevices are a device of u1 trusted for 30 days, used since, and another one
+func sampleTrustedDevices() []domain.TrustedDevice {
+	used := created.Add(24 * time.Hour)
+	return []domain.TrustedDevice{
+		{ID: "d1", UserAgent: "Mozilla/5.0", IP: "203.0.113.7", CreatedAt: created, LastUsedAt: &used, ExpiresAt: created.Add(30 * 24 * time.Hour)},
+		{ID: "d2", UserAgent: "curl/8.5.0", IP: "198.51.100.4", CreatedAt: created, ExpiresAt: created.Add(30 * 24 * time.Hour)},
+	}
+}
+
+func sampleUser() *domain.User {
+	seen := created.Add(time.Hour)
+	return &domain.User{
+		ID:         "u1",
+		TenantID:   domain.DefaultTenantID,
+		Email:      "john.doe@example.com",
+		Username:   "johndoe",
+		Roles:      []string{domain.RoleUser},
+		Profile:    domain.Profile{FirstName: "John", LastName: "Doe"},
+		LastSeenAt: &seen,
+		CreatedAt:  created,
+		UpdatedAt:  created,
+	}
+}
+
+func sampleOrganization() *domain.Organization {
+	return &domain.Organization{ID: "o1", Name: "Acme Corp", Slug: "acme-corp", CreatedAt: created, UpdatedAt: created}
+}
+
+func sampleRelationship() *domain.Relationship {
+	return &domain.Relationship{ID: "rel1", FollowerID: "u1", FolloweeID: "u2", CreatedAt: created}
+}
+
+func sampleBlock() *domain.Block {
+	return &domain.Block{ID: "b1", BlockerID: "u1", BlockedID: "u3", CreatedAt: created}
+}
+
+func sampleDocument() *domain.Document {
+	expires := time.Date(2030, 1, 31, 0, 0, 0, 0, time.UTC)
+	return &domain.Document{ID: "d1", UserID: "u1", Type: domain.DocumentTypePassport, Status: domain.DocumentStatusPending, FileName: "passport.pdf",
+		ContentType: "application/pdf", Size: 8, StorageKey: "default/u1/d1.pdf", ExpiresAt: &expires, UploadedBy: "u1", CreatedAt: created, UpdatedAt: created}
+}
+
+func sampleVerification() *domain.Verification {
+	submitted := created
+	return &domain.Verification{Status: domain.VerificationStatusPendingReview, DocumentIDs: []string{"d1"}, SubmittedBy: "u1", SubmittedAt: &submitted}
+}
+
+func sampleMembership(role string) *domain.Membership {
+	return &domain.Membership{ID: "m1", OrganizationID: "o1", UserID: "u2", Role: role, CreatedAt: created, UpdatedAt: created}
+}
+
+func sampleAddress() *domain.Address {
+	return &domain.Address{ID: "a1", Type: "home", Primary: true, Street: "123 Main St", City: "New York", State: "NY", Country: "US", ZipCode: "10001"}
+}
+
+func sampleRole() *domain.Role {
+	return &domain.Role{ID: "r1", Name: "support", Description: "Support agents", Permissions: []string{domain.PermissionUsersTags}, CreatedAt: created, UpdatedAt: created}
+}
+
+func sampleOAuthClient() *domain.OAuthClient {
+	return &domain.OAuthClient{ID: "c1", Name: "billing-service", Scopes: []string{domain.ScopeAdmin, domain.PermissionSystemRead, domain.PermissionSecurityManage}, CreatedBy: "admin1", CreatedAt: created}
+}
+
+func sampleTermsVersion() *domain.TermsVersion {
+	return &domain.TermsVersion{ID: "tv2", Document: domain.TermsDocumentTerms, Version: "2024-06-01", URL: "https://example.com/legal/terms/2024-06-01", PublishedBy: "admin1", PublishedAt: created}
+}
+
+func sampleUserVersion() *domain.UserVersion {
+	return &domain.UserVersion{UserID: "u1", Version: 2, ActorID: "admin1", CreatedAt: created, Changes: []domain.FieldChange{
+		{Field: "profile.last_name", Old: "Doe", New: "Smith"},
+		{Field: "tags", Old: []any{"beta"}, New: []any{"beta", "vip"}},
+	}}
+}
+
+func sampleEmailDomainRule() *domain.EmailDomainRule {
+	return &domain.EmailDomainRule{ID: "ed1", List: domain.EmailDomainAllow, Domain: "example.com", CreatedBy: "admin1", CreatedAt: &created}
+}
+
+func sampleFailedJob() *domain.Job {
+	finished := created.Add(time.Hour)
+	return &domain.Job{ID: "j1", Type: domain.JobTypeEmail, Status: domain.JobStatusFailed, Attempts: 5, MaxAttempts: 5,
+		LastError: "dial tcp 10.0.0.5:587: connect: connection refused", RunAt: finished, CreatedAt: created, UpdatedAt: finished, FinishedAt: &finished}
+}
+
+func sampleTermsAcceptance() *domain.TermsAcceptance {
+	return &domain.TermsAcceptance{Document: domain.TermsDocumentPrivacy, Version: "2024-01-15", AcceptedAt: created.Add(time.Hour)}
+}
+
+// pendingTerms leaves the sample version of the terms to accept by every user
+func pendingTerms(h *routestest.Harness) {
+	h.Terms.PendingVersionsFunc = func(context.Context, string) ([]*domain.TermsVersion, error) {
+		return []*domain.TermsVersion{sampleTermsVersion()}, nil
+	}
+}
+
+func avatarUpload(t *testing.T) (string, string) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile("avatar", "avatar.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("\x89PNG\r\n\x1a\n"))
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body.String(), form.FormDataContentType()
+}
+
+// documentUpload is the multipart body of a passport upload and its content type
+func documentUpload(t *testing.T) (string, string) {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("type", domain.DocumentTypePassport); err != nil {
+		t.Fatal(err)
+	}
+	if err := form.WriteField("expires_on", "2030-01-31"); err != nil {
+		t.Fatal(err)
+	}
+	part, err := form.CreateFormFile("file", "passport.pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	part.Write([]byte("%PDF-1.7"))
+	if err := form.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return body.String(), form.FormDataContentType()
+}
+
+func routeCases(t *testing.T) []routeCase {
+	avatarBody, avatarType := avatarUpload(t)
+	documentBody, documentType := documentUpload(t)
+	introspected := introspectionForm(t)
+	clientToken := clientToken(t, domain.ScopeAdmin, domain.PermissionSecurityManage)
+	readOnlyAdmin := scopedToken(t, userIDs[asAdmin], asAdmin, domain.ScopeUsersRead)
+	mfaToken := mfaToken(t, domain.ScopeUsersRead)
+	impersonation := impersonationToken(t)
+	// deeplyNested nests arrays deeper than the 32 levels of handler.DefaultBodyLimits
+	deeplyNested := `{"email":"john.doe@example.com","password":"secret123","profile":` + strings.Repeat("[", 40) + strings.Repeat("]", 40) + "}"
+	return []routeCase{
+		// Public routes
+		{
+			name:  "health",
+			route: "GET /api/v1/health",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/health"},
+		},
+		{
+			name:  "jwks",
+			route: "GET /.well-known/jwks.json",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/.well-known/jwks.json"},
+			deps:  signWithKeyPair(t),
+		},
+		{
+			name:  "jwks_shared_secret",
+			route: "GET /.well-known/jwks.json",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/.well-known/jwks.json"},
+		},
+		{
+			name:   "readyz",
+			route:  "GET /readyz",
+			req:    routestest.Request{Method: http.MethodGet, Target: "/readyz"},
+			ignore: []string{"checked_at"},
+		},
+		{
+			name:  "readyz_degraded",
+			route: "GET /readyz",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/readyz"},
+			deps: checkHealth(
+				ports.HealthCheck{Name: "mongodb", Critical: true, Checker: fixedHealth(ports.DependencyHealth{Status: ports.HealthUp, LatencyMS: 1.5, Version: "7.0.12"})},
+				ports.HealthCheck{Name: "job_workers", Checker: fixedHealth(ports.DependencyHealth{
+					Status:  ports.HealthDegraded,
+					Error:   "1 of 4 workers are not alive",
+					Details: map[string]string{"workers": "4", "alive": "3"},
+				})},
+				ports.HealthCheck{Name: "redis", Checker: fixedHealth(ports.DependencyHealth{Status: ports.HealthDown, Error: "connection refused"})},
+			),
+			ignore: []string{"checked_at"},
+		},
+		{
+			name:  "readyz_down",
+			route: "GET /readyz",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/readyz"},
+			deps: checkHealth(
+				ports.HealthCheck{Name: "mongodb", Critical: true, Checker: fixedHealth(ports.DependencyHealth{Status: ports.HealthDown, Error: "server selection timeout"})},
+				ports.HealthCheck{Name: "mail", Checker: fixedHealth(ports.DependencyHealth{Status: ports.HealthUp, LatencyMS: 12.25})},
+			),
+			ignore: []string{"checked_at"},
+		},
+		{
+			name:  "countries_by_code",
+			route: "GET /api/v1/meta/countries",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/meta/countries?country=BR"},
+		},
+		{
+			name:  "features",
+			route: "GET /api/v1/features",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/features"},
+			setup: runtimeConfig(ports.RuntimeConfigUpdate{Features: map[string]bool{"new-dashboard": true, "passkeys": false}}),
+		},
+		{
+			name:  "features_from_allowed_origin",
+			route: "GET /api/v1/features",
+			req: routestest.Request{Method: http.MethodGet, Target: "/api/v1/features",
+				Header: map[string]string{"Origin": "https://app.example.com"}},
+			setup: runtimeConfig(ports.RuntimeConfigUpdate{CORSOrigins: []string{"https://app.example.com"}}),
+		},
+		{
+			name:   "openapi_document",
+			route:  "GET /api/v1/openapi.json",
+			req:    routestest.Request{Method: http.MethodGet, Target: "/api/v1/openapi.json"},
+			ignore: []string{"paths", "definitions"},
+		},
+		{
+			name:  "openapi_document_without_credentials",
+			route: "GET /api/v1/openapi.json",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/openapi.json"},
+			deps:  protectDocs(routes.DocsAuth{Username: "docs", Password: "secret"}),
+		},
+		{
+			name:  "openapi_document_with_credentials",
+			route: "GET /api/v1/openapi.json",
+			req: routestest.Request{Method: http.MethodGet, Target: "/api/v1/openapi.json", Header: map[string]string{
+				"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte("docs:secret")),
+			}},
+			deps:   protectDocs(routes.DocsAuth{Username: "docs", Password: "secret"}),
+			ignore: []string{"paths", "definitions"},
+		},
+		{
+			name:  "openapi_document_admin_only_as_user",
+			route: "GET /api/v1/openapi.json",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/openapi.json"},
+			as:    asUser,
+			deps:  protectDocs(routes.DocsAuth{Admin: true}),
+		},
+		{
+			name:   "openapi_document_admin_only_as_admin",
+			route:  "GET /api/v1/openapi.json",
+			req:    routestest.Request{Method: http.MethodGet, Target: "/api/v1/openapi.json"},
+			as:     asAdmin,
+			deps:   protectDocs(routes.DocsAuth{Admin: true}),
+			ignore: []string{"paths", "definitions"},
+		},
+		{
+			name:  "countries_unknown",
+			route: "GET /api/v1/meta/countries",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/meta/countries?country=XX"},
+		},
+		{
+			name:  "login_report_link",
+			route: "GET /api/v1/auth/login-report",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/auth/login-report?token=report-token"},
+			setup: func(h *routestest.Harness) {
+				h.LoginEvents.ReportLoginFunc = func(context.Context, string) error {
+					t.Error("opening the link must not report the login")
+					return nil
+				}
+			},
+		},
+		{
+			name:  "login_report",
+			route: "POST /api/v1/auth/login-report",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login-report?token=report-token"},
+			setup: func(h *routestest.Harness) {
+				h.LoginEvents.ReportLoginFunc = func(_ context.Context, token string) error {
+					if token != "report-token" {
+						return domain.ErrInvalidLoginReport
+					}
+					return nil
+				}
+			},
+		},
+		{
+			name:  "login_report_invalid",
+			route: "POST /api/v1/auth/login-report",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login-report?token=expired"},
+			setup: func(h *routestest.Harness) {
+				h.LoginEvents.ReportLoginFunc = func(context.Context, string) error { return domain.ErrInvalidLoginReport }
+			},
+		},
+
+		// Login
+		{
+			name:  "login",
+			route: "POST /api/v1/auth/login",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login", Body: `{"email":"john.doe@example.com","password":"secret123"}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.AuthenticateFunc = func(context.Context, string, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+			ignore: []string{"access_token", "expires_at"},
+		},
+		{
+			name:  "login_scoped",
+			route: "POST /api/v1/auth/login",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login", Body: `{"email":"john.doe@example.com","password":"secret123","scopes":["users:read","users:read"]}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.AuthenticateFunc = func(context.Context, string, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+			ignore: []string{"access_token", "expires_at"},
+		},
+		{
+			name:  "login_invalid_scope",
+			route: "POST /api/v1/auth/login",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login", Body: `{"email":"john.doe@example.com","password":"secret123","scopes":["everything"]}`},
+		},
+		{
+			name:    "login_missing_password",
+			route:   "POST /api/v1/auth/login",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login", Body: `{"email":"john.doe@example.com"}`},
+			invalid: true,
+		},
+		{
+			name:  "login_invalid_credentials",
+			route: "POST /api/v1/auth/login",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login", Body: `{"email":"john.doe@example.com","password":"wrong"}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.AuthenticateFunc = func(context.Context, string, string) (*domain.User, error) { return nil, usecase.ErrInvalidCredentials }
+			},
+		},
+		{
+			name:  "login_ip_blocked",
+			route: "POST /api/v1/auth/login",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login", Body: `{"email":"john.doe@example.com","password":"secret123"}`},
+			setup: func(h *routestest.Harness) {
+				h.IPBackoff.CheckFunc = func(context.Context, string) (time.Duration, error) { return 8 * time.Second, nil }
+			},
+		},
+		{
+			name:  "login_deactivated",
+			route: "POST /api/v1/auth/login",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login", Body: `{"email":"john.doe@example.com","password":"secret123"}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.AuthenticateFunc = func(context.Context, string, string) (*domain.User, error) { return nil, usecase.ErrAccountDeactivated }
+			},
+		},
+		{
+			name:  "login_mfa_required",
+			route: "POST /api/v1/auth/login",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login", Body: `{"email":"john.doe@example.com","password":"secret123"}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.AuthenticateFunc = func(context.Context, string, string) (*domain.User, error) {
+					user := sampleUser()
+					user.MFAFactors = sampleMFAFactors()
+					return user, nil
+				}
+			},
+			ignore: []string{"mfa_token", "expires_at"},
+		},
+		{
+			name:  "login_trusted_device",
+			route: "POST /api/v1/auth/login",
+			req: routestest.Request{
+				Method: http.MethodPost,
+				Target: "/api/v1/auth/login",
+				Body:   `{"email":"john.doe@example.com","password":"secret123"}`,
+				Header: map[string]string{"X-Device-Token": "device-token"},
+			},
+			setup: func(h *routestest.Harness) {
+				h.Users.AuthenticateFunc = func(context.Context, string, string) (*domain.User, error) {
+					user := sampleUser()
+					user.MFAFactors = sampleMFAFactors()
+					return user, nil
+				}
+				h.MFA.UseTrustedDeviceFunc = func(_ context.Context, _ *domain.User, token string) (bool, error) {
+					return token == "device-token", nil
+				}
+			},
+			ignore: []string{"access_token", "expires_at"},
+		},
+		{
+			name:  "login_anomaly_challenge",
+			route: "POST /api/v1/auth/login",
+			req: routestest.Request{
+				Method: http.MethodPost,
+				Target: "/api/v1/auth/login",
+				Body:   `{"email":"john.doe@example.com","password":"secret123"}`,
+				Header: map[string]string{"X-Device-Token": "device-token"},
+			},
+			setup: func(h *routestest.Harness) {
+				h.Users.AuthenticateFunc = func(context.Context, string, string) (*domain.User, error) {
+					user := sampleUser()
+					user.MFAFactors = sampleMFAFactors()
+					return user, nil
+				}
+				h.LoginEvents.AssessLoginFunc = func(context.Context, *domain.User, string, string) ([]domain.LoginAnomaly, error) {
+					return []domain.LoginAnomaly{{Rule: domain.LoginRuleImpossibleTravel, Action: domain.LoginAnomalyChallenge}}, nil
+				}
+				h.MFA.UseTrustedDeviceFunc = func(context.Context, *domain.User, string) (bool, error) {
+					t.Error("challenged logins must not use trusted devices")
+					return true, nil
+				}
+			},
+			ignore: []string{"mfa_token", "expires_at"},
+		},
+		{
+			name:  "mfa_challenge",
+			route: "POST /api/v1/auth/mfa/challenge",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/mfa/challenge", Body: `{"mfa_token":"` + mfaToken + `","factor_id":"f2"}`},
+			setup: func(h *routestest.Harness) {
+				h.MFA.SendCodeFunc = func(_ context.Context, userID, factorID string) (time.Time, error) {
+					if userID != "u1" || factorID != "f2" {
+						return time.Time{}, domain.ErrMFAFactorNotFound
+					}
+					return time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC), nil
+				}
+			},
+		},
+		{
+			name:  "mfa_challenge_sms_rate_limited",
+			route: "POST /api/v1/auth/mfa/challenge",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/mfa/challenge", Body: `{"mfa_token":"` + mfaToken + `","factor_id":"f2"}`},
+			setup: func(h *routestest.Harness) {
+				h.MFA.SendCodeFunc = func(context.Context, string, string) (time.Time, error) {
+					return time.Time{}, domain.ErrSMSRateLimited
+				}
+			},
+		},
+		{
+			name:  "mfa_challenge_access_token",
+			route: "POST /api/v1/auth/mfa/challenge",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/mfa/challenge", Body: `{"mfa_token":"` + readOnlyAdmin + `","factor_id":"f2"}`},
+		},
+		{
+			name:  "mfa_verify",
+			route: "POST /api/v1/auth/mfa/verify",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/mfa/verify", Body: `{"mfa_token":"` + mfaToken + `","factor_id":"f1","code":"123456"}`},
+			setup: func(h *routestest.Harness) {
+				h.MFA.VerifyFunc = func(_ context.Context, userID, factorID, code string) error {
+					if userID != "u1" || factorID != "f1" || code != "123456" {
+						return domain.ErrInvalidMFACode
+					}
+					return nil
+				}
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+			ignore: []string{"access_token", "expires_at"},
+		},
+		{
+			name:  "mfa_verify_remember_device",
+			route: "POST /api/v1/auth/mfa/verify",
+			req: routestest.Request{
+				Method: http.MethodPost,
+				Target: "/api/v1/auth/mfa/verify",
+				Body:   `{"mfa_token":"` + mfaToken + `","factor_id":"f1","code":"123456","remember_device":true}`,
+				Header: map[string]string{"User-Agent": "Mozilla/5.0"},
+			},
+			setup: func(h *routestest.Harness) {
+				h.MFA.VerifyFunc = func(context.Context, string, string, string) error { return nil }
+				h.MFA.TrustDeviceFunc = func(_ context.Context, _, userAgent, ip string) (*domain.TrustedDevice, string, error) {
+					device := sampleTrustedDevices()[0]
+					device.UserAgent, device.IP = userAgent, ip
+					return &device, "device-token", nil
+				}
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+			ignore: []string{"access_token", "expires_at"},
+		},
+		{
+			name:  "mfa_verify_wrong_code",
+			route: "POST /api/v1/auth/mfa/verify",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/mfa/verify", Body: `{"mfa_token":"` + mfaToken + `","factor_id":"f1","code":"654321"}`},
+			setup: func(h *routestest.Harness) {
+				h.MFA.VerifyFunc = func(context.Context, string, string, string) error { return domain.ErrInvalidMFACode }
+			},
+		},
+		{
+			name:  "password_strength_weak",
+			route: "POST /api/v1/auth/password-strength",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/password-strength", Body: `{"password":"JohnDoe!","email":"john.doe@example.com"}`},
+			setup: evaluatePasswords(domain.PasswordPolicy{MinLength: 8, MinScore: 3}),
+		},
+		{
+			name:  "password_strength_strong",
+			route: "POST /api/v1/auth/password-strength",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/password-strength", Body: `{"password":"staple horse battery correct"}`},
+			setup: evaluatePasswords(domain.PasswordPolicy{MinLength: 8, MinScore: 3}),
+		},
+		{
+			name:  "password_strength_breached",
+			route: "POST /api/v1/auth/password-strength",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/password-strength", Body: `{"password":"staple horse battery correct"}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.EvaluatePasswordFunc = func(_ context.Context, password string, userInputs ...string) domain.PasswordEvaluation {
+					evaluation := domain.PasswordPolicy{MinLength: 8}.Evaluate(password, userInputs...)
+					evaluation.Breaches, evaluation.Err = 3861493, domain.ErrPasswordBreached
+					return evaluation
+				}
+			},
+		},
+		{
+			name:    "password_strength_missing_password",
+			route:   "POST /api/v1/auth/password-strength",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/password-strength", Body: `{"email":"john.doe@example.com"}`},
+			invalid: true,
+		},
+		{
+			name:  "reauthenticate_password",
+			route: "POST /api/v1/auth/reauthenticate",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/reauthenticate", Body: `{"password":"securePassword123"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.VerifyPasswordFunc = func(_ context.Context, userID, password string) (*domain.User, error) {
+					if userID != "u1" || password != "securePassword123" {
+						return nil, usecase.ErrIncorrectPassword
+					}
+					return sampleUser(), nil
+				}
+			},
+			ignore: []string{"access_token", "expires_at"},
+		},
+		{
+			name:  "reauthenticate_mfa_code",
+			route: "POST /api/v1/auth/reauthenticate",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/reauthenticate", Body: `{"factor_id":"f1","code":"123456"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.VerifyFunc = func(_ context.Context, userID, factorID, code string) error {
+					if userID != "u1" || factorID != "f1" || code != "123456" {
+						return domain.ErrInvalidMFACode
+					}
+					return nil
+				}
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+			ignore: []string{"access_token", "expires_at"},
+		},
+		{
+			name:  "reauthenticate_wrong_password",
+			route: "POST /api/v1/auth/reauthenticate",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/reauthenticate", Body: `{"password":"wrong"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.VerifyPasswordFunc = func(context.Context, string, string) (*domain.User, error) { return nil, usecase.ErrIncorrectPassword }
+			},
+		},
+		{
+			name:  "reauthenticate_no_method",
+			route: "POST /api/v1/auth/reauthenticate",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/reauthenticate", Body: `{"factor_id":"f1"}`},
+			as:    asUser,
+		},
+		{
+			name:  "reauthenticate_impersonation",
+			route: "POST /api/v1/auth/reauthenticate",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/reauthenticate", Body: `{"password":"securePassword123"}`, Token: impersonation},
+		},
+		{
+			name:  "reauthenticate_challenge",
+			route: "POST /api/v1/auth/reauthenticate/challenge",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/reauthenticate/challenge", Body: `{"factor_id":"f2"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.SendCodeFunc = func(context.Context, string, string) (time.Time, error) {
+					return time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC), nil
+				}
+			},
+		},
+		{
+			name:  "login_cookie_session",
+			route: "POST /api/v1/auth/login",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/login", Body: `{"email":"john.doe@example.com","password":"secret123"}`},
+			deps:  cookieMode,
+			setup: func(h *routestest.Harness) {
+				h.Users.AuthenticateFunc = func(context.Context, string, string) (*domain.User, error) { return sampleUser(), nil }
+				h.Sessions.CreateSessionFunc = func(context.Context, *domain.User, []string, string, string) (*domain.Session, string, string, error) {
+					return sampleSession(), "session-token", "csrf-token", nil
+				}
+			},
+		},
+		{
+			name:  "logout",
+			route: "POST /api/v1/auth/logout",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/auth/logout"},
+			as:    asUser,
+		},
+		{
+			name:  "logout_cookie_session",
+			route: "POST /api/v1/auth/logout",
+			req:   sessionRequest(http.MethodPost, "/api/v1/auth/logout", "", "csrf-token"),
+			deps:  cookieMode,
+			setup: cookieSession,
+		},
+		{
+			name:  "logout_csrf_token_missing",
+			route: "POST /api/v1/auth/logout",
+			req:   sessionRequest(http.MethodPost, "/api/v1/auth/logout", "", ""),
+			deps:  cookieMode,
+			setup: cookieSession,
+		},
+		{
+			name:  "logout_csrf_token_mismatch",
+			route: "POST /api/v1/auth/logout",
+			req:   sessionRequest(http.MethodPost, "/api/v1/auth/logout", "", "other-token"),
+			deps:  cookieMode,
+			setup: cookieSession,
+		},
+		{
+			name:  "logout_cross_site",
+			route: "POST /api/v1/auth/logout",
+			req: func() routestest.Request {
+				req := sessionRequest(http.MethodPost, "/api/v1/auth/logout", "", "csrf-token")
+				req.Header["Sec-Fetch-Site"] = "cross-site"
+				return req
+			}(),
+			deps:  cookieMode,
+			setup: cookieSession,
+		},
+		{
+			name:  "logout_session_expired",
+			route: "POST /api/v1/auth/logout",
+			req:   sessionRequest(http.MethodPost, "/api/v1/auth/logout", "", "csrf-token"),
+			deps:  cookieMode,
+			setup: func(h *routestest.Harness) {
+				h.Sessions.AuthenticateFunc = func(context.Context, string) (*domain.Session, error) { return nil, domain.ErrSessionNotFound }
+			},
+		},
+		{
+			name:  "csrf_token",
+			route: "GET /api/v1/auth/csrf-token",
+			req:   sessionRequest(http.MethodGet, "/api/v1/auth/csrf-token", "", ""),
+			deps:  cookieMode,
+			setup: func(h *routestest.Harness) {
+				cookieSession(h)
+				h.Sessions.RotateCSRFTokenFunc = func(context.Context, *domain.Session) (string, error) { return "new-csrf-token", nil }
+			},
+		},
+		{
+			name:  "csrf_token_bearer",
+			route: "GET /api/v1/auth/csrf-token",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/auth/csrf-token"},
+			as:    asUser,
+		},
+		{
+			name:  "reauthenticate_cookie_session",
+			route: "POST /api/v1/auth/reauthenticate",
+			req:   sessionRequest(http.MethodPost, "/api/v1/auth/reauthenticate", `{"password":"securePassword123"}`, "csrf-token"),
+			deps:  cookieMode,
+			setup: func(h *routestest.Harness) {
+				cookieSession(h)
+				h.Users.VerifyPasswordFunc = func(context.Context, string, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+		},
+		{
+			name:  "introspect",
+			route: "POST /api/v1/auth/introspect",
+			req:   introspectionRequest(introspected, routestest.IntrospectionSecret),
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+			ignore: []string{"iat", "exp", "jti"},
+		},
+		{
+			name:  "introspect_deactivated_user",
+			route: "POST /api/v1/auth/introspect",
+			req:   introspectionRequest(introspected, routestest.IntrospectionSecret),
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) {
+					user := sampleUser()
+					user.DeactivatedAt = &created
+					return user, nil
+				}
+			},
+		},
+		{
+			name:  "introspect_client",
+			route: "POST /api/v1/auth/introspect",
+			req:   introspectionRequest(url.Values{"token": {clientToken}}.Encode(), routestest.IntrospectionSecret),
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.GetClientFunc = func(context.Context, string) (*domain.OAuthClient, error) { return sampleOAuthClient(), nil }
+			},
+			ignore: []string{"iat", "exp", "jti"},
+		},
+		{
+			name:  "introspect_invalid_token",
+			route: "POST /api/v1/auth/introspect",
+			req:   introspectionRequest("token=not-a-token", routestest.IntrospectionSecret),
+		},
+		{
+			name:  "introspect_invalid_client",
+			route: "POST /api/v1/auth/introspect",
+			req:   introspectionRequest(introspected, "guessed"),
+		},
+		{
+			name:  "oauth_token",
+			route: "POST /api/v1/auth/token",
+			req:   tokenRequest("grant_type=client_credentials&scope=system%3Aread", "c1:s3cret"),
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.AuthenticateClientFunc = func(_ context.Context, id, secret string) (*domain.OAuthClient, error) {
+					if id != "c1" || secret != "s3cret" {
+						return nil, usecase.ErrInvalidClientCredentials
+					}
+					return sampleOAuthClient(), nil
+				}
+			},
+			ignore: []string{"access_token"},
+		},
+		{
+			name:  "oauth_token_invalid_client",
+			route: "POST /api/v1/auth/token",
+			req:   tokenRequest("grant_type=client_credentials", "c1:guessed"),
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.AuthenticateClientFunc = func(context.Context, string, string) (*domain.OAuthClient, error) {
+					return nil, usecase.ErrInvalidClientCredentials
+				}
+			},
+		},
+		{
+			name:  "oauth_token_scope_not_held",
+			route: "POST /api/v1/auth/token",
+			req:   tokenRequest("grant_type=client_credentials&scope=users%3Aexport", "c1:s3cret"),
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.AuthenticateClientFunc = func(context.Context, string, string) (*domain.OAuthClient, error) {
+					return sampleOAuthClient(), nil
+				}
+			},
+		},
+		{
+			name:    "oauth_token_unsupported_grant",
+			route:   "POST /api/v1/auth/token",
+			req:     tokenRequest("grant_type=password", "c1:s3cret"),
+			invalid: true,
+		},
+
+		// User routes
+		{
+			name:  "users_list",
+			route: "GET /api/v1/users",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users?page=1&page_size=10"},
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUsersFunc = func(context.Context, *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+					return &ports.GetUsersResult{Users: []*domain.User{sampleUser()}, TotalCount: 1, Page: 1, PageSize: 10, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:  "users_list_activity_as_admin",
+			route: "GET /api/v1/users",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users?inactive_days=90"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUsersFunc = func(context.Context, *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+					return &ports.GetUsersResult{Users: []*domain.User{sampleUser()}, TotalCount: 1, Page: 1, PageSize: 10, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:  "users_list_facets",
+			route: "GET /api/v1/users",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users?facets=country,status"},
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUsersFunc = func(context.Context, *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+					return &ports.GetUsersResult{
+						Users: []*domain.User{sampleUser()}, TotalCount: 1, Page: 1, PageSize: 10, TotalPages: 1,
+						Facets: map[string][]ports.FacetCount{
+							domain.UserFacetCountry: {{Value: "US", Count: 1}},
+							domain.UserFacetStatus:  {{Value: domain.UserStatusActive, Count: 1}},
+						},
+					}, nil
+				}
+			},
+		},
+		{
+			name:  "users_list_invalid_facet",
+			route: "GET /api/v1/users",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users?facets=password_hash"},
+		},
+		{
+			name:  "users_list_near",
+			route: "GET /api/v1/users",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users?near=40.7128,-74.006&radius_km=25"},
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUsersFunc = func(context.Context, *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+					return &ports.GetUsersResult{Users: []*domain.User{sampleUser()}, TotalCount: 1, Page: 1, PageSize: 10, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:  "users_list_invalid_near",
+			route: "GET /api/v1/users",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users?near=new-york"},
+		},
+		{
+			name:    "users_list_invalid_sort",
+			route:   "GET /api/v1/users",
+			req:     routestest.Request{Method: http.MethodGet, Target: "/api/v1/users?sort=password_hash"},
+			invalid: true,
+		},
+		{
+			name:  "users_list_explain_forbidden",
+			route: "GET /api/v1/users",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users?explain=true"},
+			as:    asUser,
+		},
+		{
+			name:  "users_count",
+			route: "GET /api/v1/users/count",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/count?tag=beta"},
+			setup: func(h *routestest.Harness) {
+				h.Users.CountUsersFunc = func(context.Context, *ports.GetUsersOptions) (int64, error) { return 42, nil }
+			},
+		},
+		{
+			name:  "users_check_email",
+			route: "GET /api/v1/users/check-email",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/check-email?email=John.Doe@example.com"},
+			setup: func(h *routestest.Harness) {
+				h.Users.IsEmailAvailableFunc = func(context.Context, string) (bool, error) { return true, nil }
+			},
+		},
+		{
+			name:    "users_check_email_missing",
+			route:   "GET /api/v1/users/check-email",
+			req:     routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/check-email"},
+			invalid: true,
+		},
+		{
+			name:  "users_search",
+			route: "POST /api/v1/users/search",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/search", Body: `{"page":1,"page_size":10,"sort":"email"}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUsersFunc = func(context.Context, *ports.GetUsersOptions) (*ports.GetUsersResult, error) {
+					return &ports.GetUsersResult{Users: []*domain.User{sampleUser()}, TotalCount: 1, Page: 1, PageSize: 10, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:    "users_search_invalid_sort",
+			route:   "POST /api/v1/users/search",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/search", Body: `{"sort":"password_hash"}`},
+			invalid: true,
+		},
+		{
+			name:  "users_lookup",
+			route: "POST /api/v1/users/lookup",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/lookup", Body: `{"ids":["u1","missing"]}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.LookupUsersFunc = func(context.Context, []string) ([]*domain.User, error) { return []*domain.User{sampleUser()}, nil }
+			},
+		},
+		{
+			name:    "users_lookup_empty",
+			route:   "POST /api/v1/users/lookup",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/lookup", Body: `{"ids":[]}`},
+			invalid: true,
+		},
+		{
+			name:  "users_autocomplete",
+			route: "GET /api/v1/users/autocomplete",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/autocomplete?q=jo&limit=5"},
+			setup: func(h *routestest.Harness) {
+				h.Users.AutocompleteUsersFunc = func(context.Context, string, int) ([]*domain.User, error) {
+					jane := &domain.User{ID: "u2", Email: "jane.jones@example.com", Profile: domain.Profile{FirstName: "Jane", LastName: "Jones"}}
+					return []*domain.User{sampleUser(), jane}, nil
+				}
+			},
+		},
+		{
+			name:    "users_autocomplete_missing_q",
+			route:   "GET /api/v1/users/autocomplete",
+			req:     routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/autocomplete"},
+			invalid: true,
+			setup: func(h *routestest.Harness) {
+				h.Users.AutocompleteUsersFunc = func(context.Context, string, int) ([]*domain.User, error) {
+					return nil, fmt.Errorf("%w: q must have 1 to %d characters", domain.ErrInvalidSearch, domain.MaxAutocompleteLength)
+				}
+			},
+		},
+		{
+			name:  "users_by_username",
+			route: "GET /api/v1/users/by-username/:username",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/by-username/johndoe"},
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByUsernameFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+		},
+		{
+			name:  "users_by_username_not_found",
+			route: "GET /api/v1/users/by-username/:username",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/by-username/nobody"},
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByUsernameFunc = func(context.Context, string) (*domain.User, error) { return nil, usecase.ErrUserNotFound }
+			},
+		},
+		{
+			name:  "users_get",
+			route: "GET /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1"},
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+				h.Relationships.CountRelationshipsFunc = func(context.Context, string) (*domain.RelationshipCounts, error) {
+					return &domain.RelationshipCounts{Followers: 3, Following: 2}, nil
+				}
+			},
+		},
+		{
+			name:  "users_get_blocked",
+			route: "GET /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+				h.Relationships.ListBlockersFunc = func(_ context.Context, _ string, ids []string) ([]string, error) { return ids, nil }
+			},
+		},
+		{
+			name:  "users_get_as_admin",
+			route: "GET /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+		},
+		{
+			name:  "users_get_csv",
+			route: "GET /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1", Header: map[string]string{"Accept": "text/csv"}},
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+		},
+		{
+			name:  "users_get_not_found",
+			route: "GET /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/missing"},
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return nil, usecase.ErrUserNotFound }
+			},
+		},
+		{
+			name:  "users_followers",
+			route: "GET /api/v1/users/:id/followers",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u2/followers?page=1&page_size=10"},
+			setup: func(h *routestest.Harness) {
+				h.Relationships.ListFollowersFunc = func(context.Context, *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error) {
+					return &ports.RelationshipQueryResult{Relationships: []*domain.Relationship{sampleRelationship()}, TotalCount: 1, Page: 1, PageSize: 10, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:  "users_followers_not_found",
+			route: "GET /api/v1/users/:id/followers",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/missing/followers"},
+			setup: func(h *routestest.Harness) {
+				h.Relationships.ListFollowersFunc = func(context.Context, *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error) {
+					return nil, usecase.ErrUserNotFound
+				}
+			},
+		},
+		{
+			name:  "users_following",
+			route: "GET /api/v1/users/:id/following",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1/following"},
+			setup: func(h *routestest.Harness) {
+				h.Relationships.ListFollowingFunc = func(context.Context, *ports.RelationshipQuery) (*ports.RelationshipQueryResult, error) {
+					return &ports.RelationshipQueryResult{Relationships: []*domain.Relationship{sampleRelationship()}, TotalCount: 1, Page: 1, PageSize: 20, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:  "users_exists",
+			route: "HEAD /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodHead, Target: "/api/v1/users/u1"},
+			setup: func(h *routestest.Harness) {
+				h.Users.UserExistsFunc = func(context.Context, string) (bool, error) { return true, nil }
+			},
+		},
+		{
+			name:  "users_exists_missing",
+			route: "HEAD /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodHead, Target: "/api/v1/users/missing"},
+		},
+		{
+			name:  "users_register",
+			route: "POST /api/v1/users/register",
+			req: routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/register",
+				Body: `{"email":"john.doe@example.com","password":"secret123","profile":{"first_name":"John","last_name":"Doe"}}`},
+		},
+		{
+			name:    "users_register_nested_too_deeply",
+			route:   "POST /api/v1/users/register",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/register", Body: deeplyNested},
+			invalid: true,
+		},
+		{
+			// Handlers bind JSON whatever the Content-Type, which must not skip the depth limit
+			name:  "users_register_nested_too_deeply_as_text",
+			route: "POST /api/v1/users/register",
+			req: routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/register", Body: deeplyNested,
+				Header: map[string]string{"Content-Type": "text/plain"}},
+			invalid: true,
+		},
+		{
+			name:  "users_register_email_taken",
+			route: "POST /api/v1/users/register",
+			req: routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/register",
+				Body: `{"email":"john.doe@example.com","password":"secret123","profile":{"first_name":"John","last_name":"Doe"}}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.RegisterFunc = func(context.Context, string, string, string, domain.Profile, map[string]string) error {
+					return domain.ErrEmailTaken
+				}
+			},
+		},
+		{
+			name:  "users_register_invalid_field",
+			route: "POST /api/v1/users/register",
+			req: routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/register",
+				Body: `{"email":"john.doe@example.com","password":"secret123","profile":{"first_name":"John","last_name":"Doe","phone":"12"}}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.RegisterFunc = func(context.Context, string, string, string, domain.Profile, map[string]string) error {
+					return &domain.FieldError{Field: "profile.phone", Err: errors.New("invalid phone number")}
+				}
+			},
+		},
+		{
+			name:  "users_register_custom_field_required",
+			route: "POST /api/v1/users/register",
+			req: routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/register",
+				Body: `{"email":"john.doe@example.com","password":"secret123","profile":{"first_name":"John","last_name":"Doe"},"custom_fields":{"employee_id":"E123456"}}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.RegisterFunc = func(context.Context, string, string, string, domain.Profile, map[string]string) error {
+					return &domain.FieldError{Field: "custom_fields.department", Err: domain.ErrCustomFieldRequired}
+				}
+			},
+		},
+		{
+			name:  "users_register_fields",
+			route: "GET /api/v1/users/register/fields",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/register/fields"},
+			setup: func(h *routestest.Harness) {
+				h.Users.RegistrationFieldsFunc = func() []domain.CustomField {
+					return []domain.CustomField{
+						{Name: "department", Type: domain.CustomFieldText, Required: true, Options: []string{"sales", "engineering"}, MaxLength: 256},
+						{Name: "employee_id", Type: domain.CustomFieldText, Pattern: "E[0-9]{6}", MaxLength: 7},
+					}
+				}
+			},
+		},
+		{
+			name:  "users_register_email_domain_not_allowed",
+			route: "POST /api/v1/users/register",
+			req: routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/register",
+				Body: `{"email":"john.doe@mailinator.com","password":"secret123","profile":{"first_name":"John","last_name":"Doe"}}`},
+			setup: func(h *routestest.Harness) {
+				h.Users.RegisterFunc = func(context.Context, string, string, string, domain.Profile, map[string]string) error {
+					return domain.ErrEmailDomainNotAllowed
+				}
+			},
+		},
+		{
+			name:  "users_avatar",
+			route: "POST /api/v1/users/:id/avatar",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/avatar", Body: avatarBody, Header: map[string]string{"Content-Type": avatarType}},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Avatars.UploadAvatarFunc = func(context.Context, string, []byte) (*domain.Avatar, error) {
+					return &domain.Avatar{Version: "1", Status: domain.AvatarStatusProcessing, OriginalURL: "/media/avatars/u1/1/original.png", UpdatedAt: created}, nil
+				}
+			},
+		},
+		{
+			name:  "users_avatar_by_staff",
+			route: "POST /api/v1/users/:id/avatar",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u2/avatar", Body: avatarBody, Header: map[string]string{"Content-Type": avatarType}},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Avatars.UploadAvatarFunc = func(context.Context, string, []byte) (*domain.Avatar, error) {
+					return &domain.Avatar{Version: "1", Status: domain.AvatarStatusProcessing, OriginalURL: "/media/avatars/u2/1/original.png", UpdatedAt: created}, nil
+				}
+			},
+		},
+		{
+			name:  "users_avatar_of_another_user",
+			route: "POST /api/v1/users/:id/avatar",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u2/avatar", Body: avatarBody, Header: map[string]string{"Content-Type": avatarType}},
+			as:    asUser,
+		},
+		{
+			name:  "users_avatar_unauthenticated",
+			route: "POST /api/v1/users/:id/avatar",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/avatar", Body: avatarBody, Header: map[string]string{"Content-Type": avatarType}},
+		},
+		{
+			name:    "users_avatar_missing_file",
+			route:   "POST /api/v1/users/:id/avatar",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/avatar", Body: `{}`},
+			as:      asUser,
+			invalid: true,
+		},
+		{
+			name:  "users_documents_upload",
+			route: "POST /api/v1/users/:id/documents",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/documents", Body: documentBody, Header: map[string]string{"Content-Type": documentType}},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Documents.UploadDocumentFunc = func(context.Context, *ports.DocumentUpload) (*domain.Document, error) { return sampleDocument(), nil }
+			},
+		},
+		{
+			name:  "users_documents_upload_invalid_file",
+			route: "POST /api/v1/users/:id/documents",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/documents", Body: documentBody, Header: map[string]string{"Content-Type": documentType}},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Documents.UploadDocumentFunc = func(context.Context, *ports.DocumentUpload) (*domain.Document, error) {
+					return nil, domain.ErrInvalidDocumentFile
+				}
+			},
+		},
+		{
+			name:  "users_documents_upload_other_user",
+			route: "POST /api/v1/users/:id/documents",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u2/documents", Body: documentBody, Header: map[string]string{"Content-Type": documentType}},
+			as:    asUser,
+		},
+		{
+			name:  "users_documents_list_as_admin",
+			route: "GET /api/v1/users/:id/documents",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1/documents"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Documents.ListDocumentsFunc = func(context.Context, string) ([]*domain.Document, error) {
+					return []*domain.Document{sampleDocument()}, nil
+				}
+			},
+		},
+		{
+			name:  "users_documents_list_read_only_admin",
+			route: "GET /api/v1/users/:id/documents",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1/documents", Token: readOnlyAdmin},
+		},
+		{
+			name:  "users_documents_download",
+			route: "GET /api/v1/users/:id/documents/:documentId/download",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1/documents/d1/download"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Documents.PresignDownloadFunc = func(context.Context, string, string, string) (*domain.DocumentDownload, error) {
+					return &domain.DocumentDownload{URL: "/documents/default/u1/d1.pdf?expires=1704067500&signature=9f86d081", ExpiresAt: created.Add(5 * time.Minute)}, nil
+				}
+			},
+		},
+		{
+			name:  "users_documents_download_as_user",
+			route: "GET /api/v1/users/:id/documents/:documentId/download",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1/documents/d1/download"},
+			as:    asUser,
+		},
+		{
+			name:  "users_documents_download_not_found",
+			route: "GET /api/v1/users/:id/documents/:documentId/download",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1/documents/missing/download"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Documents.PresignDownloadFunc = func(context.Context, string, string, string) (*domain.DocumentDownload, error) {
+					return nil, usecase.ErrDocumentNotFound
+				}
+			},
+		},
+		{
+			name:  "users_verification_get",
+			route: "GET /api/v1/users/:id/verification",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1/verification"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Verification.GetVerificationFunc = func(context.Context, string) (*domain.Verification, error) {
+					return sampleVerification(), nil
+				}
+			},
+		},
+		{
+			name:  "users_verification_submit",
+			route: "POST /api/v1/users/:id/verification",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/verification", Body: `{"document_ids":["d1"]}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Verification.SubmitVerificationFunc = func(context.Context, string, string, []string) (*domain.Verification, error) {
+					return sampleVerification(), nil
+				}
+			},
+		},
+		{
+			name:  "users_verification_submit_pending",
+			route: "POST /api/v1/users/:id/verification",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/verification", Body: `{"document_ids":["d1"]}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Verification.SubmitVerificationFunc = func(context.Context, string, string, []string) (*domain.Verification, error) {
+					return nil, domain.ErrVerificationConflict
+				}
+			},
+		},
+		{
+			name:  "users_verification_submit_other_user",
+			route: "POST /api/v1/users/:id/verification",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u2/verification", Body: `{"document_ids":["d1"]}`},
+			as:    asUser,
+		},
+		{
+			name:  "users_verification_review",
+			route: "POST /api/v1/users/:id/verification/review",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/verification/review", Body: `{"decision":"reject","reason":"The passport photo is not legible"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Verification.ReviewVerificationFunc = func(_ context.Context, reviewerID, _, _, reason string) (*domain.Verification, error) {
+					verification := sampleVerification()
+					reviewed := created.Add(24 * time.Hour)
+					verification.Status, verification.Reason, verification.ReviewedBy, verification.ReviewedAt = domain.VerificationStatusRejected, reason, reviewerID, &reviewed
+					return verification, nil
+				}
+			},
+		},
+		{
+			name:  "users_verification_review_without_reason",
+			route: "POST /api/v1/users/:id/verification/review",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/verification/review", Body: `{"decision":"reject"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Verification.ReviewVerificationFunc = func(context.Context, string, string, string, string) (*domain.Verification, error) {
+					return nil, domain.ErrInvalidVerificationReason
+				}
+			},
+		},
+		{
+			name:  "users_verification_review_as_user",
+			route: "POST /api/v1/users/:id/verification/review",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/verification/review", Body: `{"decision":"approve"}`},
+			as:    asUser,
+		},
+		{
+			name:  "webhooks_identity_verification",
+			route: "POST /api/v1/webhooks/identity-verification",
+			req: routestest.Request{Method: http.MethodPost, Target: "/api/v1/webhooks/identity-verification", Body: `{"check_id":"sandbox-1","result":"clear"}`,
+				Header: map[string]string{"X-Sandbox-Signature": "9f86d081884c7d65"}},
+			setup: func(h *routestest.Harness) {
+				h.Verification.HandleCheckWebhookFunc = func(context.Context, http.Header, []byte) error {
+					return nil
+				}
+			},
+		},
+		{
+			name:  "webhooks_identity_verification_invalid_signature",
+			route: "POST /api/v1/webhooks/identity-verification",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/webhooks/identity-verification", Body: `{"check_id":"sandbox-1","result":"clear"}`},
+			setup: func(h *routestest.Harness) {
+				h.Verification.HandleCheckWebhookFunc = func(context.Context, http.Header, []byte) error {
+					return ports.ErrInvalidWebhookSignature
+				}
+			},
+		},
+		{
+			name:  "webhooks_identity_verification_disabled",
+			route: "POST /api/v1/webhooks/identity-verification",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/webhooks/identity-verification", Body: `{"check_id":"sandbox-1","result":"clear"}`},
+			setup: func(h *routestest.Harness) {
+				h.Verification.HandleCheckWebhookFunc = func(context.Context, http.Header, []byte) error {
+					return usecase.ErrIdentityVerifierDisabled
+				}
+			},
+		},
+		{
+			name:  "terms_current",
+			route: "GET /api/v1/terms",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/terms"},
+			setup: func(h *routestest.Harness) {
+				h.Terms.CurrentVersionsFunc = func(context.Context) ([]*domain.TermsVersion, error) {
+					return []*domain.TermsVersion{sampleTermsVersion()}, nil
+				}
+			},
+		},
+
+		// Routes of the authenticated user
+		{
+			name:  "me_terms",
+			route: "GET /api/v1/users/me/terms",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/terms"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				pendingTerms(h)
+				h.Terms.GetStatusFunc = func(context.Context, string) (*domain.TermsStatus, error) {
+					return &domain.TermsStatus{Pending: []*domain.TermsVersion{sampleTermsVersion()}, Accepted: []*domain.TermsAcceptance{sampleTermsAcceptance()}}, nil
+				}
+			},
+		},
+		{
+			name:  "me_terms_accept",
+			route: "POST /api/v1/users/me/terms/accept",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/terms/accept", Body: `{"versions":{"terms":"2024-06-01"}}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				pendingTerms(h)
+				h.Terms.AcceptVersionsFunc = func(context.Context, string, map[string]string) (*domain.TermsStatus, error) {
+					accepted := &domain.TermsAcceptance{Document: domain.TermsDocumentTerms, Version: "2024-06-01", AcceptedAt: created.Add(2 * time.Hour)}
+					return &domain.TermsStatus{Pending: []*domain.TermsVersion{}, Accepted: []*domain.TermsAcceptance{accepted, sampleTermsAcceptance()}}, nil
+				}
+			},
+		},
+		{
+			name:  "me_terms_accept_not_current",
+			route: "POST /api/v1/users/me/terms/accept",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/terms/accept", Body: `{"versions":{"terms":"2023-01-01"}}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Terms.AcceptVersionsFunc = func(context.Context, string, map[string]string) (*domain.TermsStatus, error) {
+					return nil, domain.ErrTermsVersionNotCurrent
+				}
+			},
+		},
+		{
+			name:  "me_settings_terms_not_accepted",
+			route: "GET /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/settings"},
+			as:    asUser,
+			setup: pendingTerms,
+		},
+		{
+			name:  "me_settings",
+			route: "GET /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/settings"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.GetSettingsFunc = func(context.Context, string) (*domain.Settings, error) {
+					return &domain.Settings{Theme: "dark", Language: "en-US"}, nil
+				}
+			},
+		},
+		{
+			name:  "me_settings_unauthenticated",
+			route: "GET /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/settings"},
+		},
+		{
+			name:  "me_settings_key_pair_token",
+			route: "GET /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/settings"},
+			as:    asUser,
+			deps:  signWithKeyPair(t),
+			setup: func(h *routestest.Harness) {
+				h.Users.GetSettingsFunc = func(context.Context, string) (*domain.Settings, error) {
+					return &domain.Settings{Theme: "dark", Language: "en-US"}, nil
+				}
+			},
+		},
+		{
+			name:  "me_settings_external_token",
+			route: "GET /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/settings", Token: "keycloak-token"},
+			setup: func(h *routestest.Harness) {
+				h.ExternalAuth.AuthenticateTokenFunc = func(context.Context, string) (*domain.User, error) {
+					return &domain.User{ID: "u1", Roles: []string{domain.RoleUser}}, nil
+				}
+				h.Users.GetSettingsFunc = func(context.Context, string) (*domain.Settings, error) {
+					return &domain.Settings{Theme: "dark", Language: "en-US"}, nil
+				}
+			},
+		},
+		{
+			name:  "me_settings_identity_provider_unavailable",
+			route: "GET /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/settings", Token: "keycloak-token"},
+			setup: func(h *routestest.Harness) {
+				h.ExternalAuth.AuthenticateTokenFunc = func(context.Context, string) (*domain.User, error) {
+					return nil, ports.ErrIdentityProviderUnavailable
+				}
+			},
+		},
+		{
+			name:  "me_settings_update",
+			route: "PATCH /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/me/settings", Body: `{"theme":"light"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpdateSettingsFunc = func(context.Context, string, domain.SettingsUpdate) (*domain.Settings, error) {
+					return &domain.Settings{Theme: "light", Language: "en-US"}, nil
+				}
+			},
+		},
+		{
+			// Writes made with an impersonation token are attributed to the admin
+			name:  "me_settings_update_impersonated",
+			route: "PATCH /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/me/settings", Body: `{"theme":"light"}`, Token: impersonation},
+			setup: func(h *routestest.Harness) {
+				h.Users.UpdateSettingsFunc = func(ctx context.Context, _ string, _ domain.SettingsUpdate) (*domain.Settings, error) {
+					if actor := domain.ActorFromContext(ctx); actor.ID != "admin1" {
+						return nil, fmt.Errorf("write attributed to %q", actor.ID)
+					}
+					return &domain.Settings{Theme: "light", Language: "en-US"}, nil
+				}
+			},
+		},
+		{
+			name:  "me_settings_update_read_only_token",
+			route: "PATCH /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/me/settings", Body: `{"theme":"light"}`, Token: readOnlyAdmin},
+		},
+		{
+			name:  "me_settings_update_invalid",
+			route: "PATCH /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/me/settings", Body: `{"theme":"neon"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpdateSettingsFunc = func(context.Context, string, domain.SettingsUpdate) (*domain.Settings, error) {
+					return nil, domain.ErrInvalidTheme
+				}
+			},
+		},
+		{
+			name:  "me_profile_update",
+			route: "PATCH /api/v1/users/me/profile",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/me/profile", Body: `{"phone":"+1-555-987-6543","birthdate":"1990-05-15"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpdateProfileFunc = func(_ context.Context, actorID, userID string, update domain.ProfileUpdate, override bool) (*domain.User, error) {
+					if actorID != "u1" || userID != "u1" || override {
+						return nil, errors.New("unexpected profile update")
+					}
+					user := sampleUser()
+					user.Profile.Phone, user.Profile.Birthdate = *update.Phone, *update.Birthdate
+					return user, nil
+				}
+			},
+		},
+		{
+			name:  "me_profile_update_under_minimum_age",
+			route: "PATCH /api/v1/users/me/profile",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/me/profile", Body: `{"birthdate":"2015-05-15"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpdateProfileFunc = func(context.Context, string, string, domain.ProfileUpdate, bool) (*domain.User, error) {
+					return nil, &domain.FieldError{Field: "birthdate", Err: domain.ErrUnderMinimumAge}
+				}
+			},
+		},
+		{
+			name:  "me_profile_update_read_only_token",
+			route: "PATCH /api/v1/users/me/profile",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/me/profile", Body: `{"phone":"+1-555-987-6543"}`, Token: readOnlyAdmin},
+		},
+		{
+			name:  "me_phone_verify",
+			route: "POST /api/v1/users/me/phone/verify",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/phone/verify"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Phone.SendCodeFunc = func(context.Context, string) (time.Time, error) {
+					return time.Date(2024, 1, 1, 0, 10, 0, 0, time.UTC), nil
+				}
+			},
+		},
+		{
+			name:  "me_phone_verify_too_frequent",
+			route: "POST /api/v1/users/me/phone/verify",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/phone/verify"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Phone.SendCodeFunc = func(context.Context, string) (time.Time, error) {
+					return time.Time{}, domain.ErrPhoneCodeTooFrequent
+				}
+			},
+		},
+		{
+			name:  "me_phone_verify_already_verified",
+			route: "POST /api/v1/users/me/phone/verify",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/phone/verify"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Phone.SendCodeFunc = func(context.Context, string) (time.Time, error) {
+					return time.Time{}, domain.ErrPhoneAlreadyVerified
+				}
+			},
+		},
+		{
+			name:  "me_phone_confirm",
+			route: "POST /api/v1/users/me/phone/confirm",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/phone/confirm", Body: `{"code":"123456"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Phone.ConfirmCodeFunc = func(_ context.Context, userID, code string) (*domain.User, error) {
+					if userID != "u1" || code != "123456" {
+						return nil, domain.ErrInvalidPhoneCode
+					}
+					user := sampleUser()
+					verifiedAt := time.Date(2024, 1, 1, 0, 5, 0, 0, time.UTC)
+					user.Profile.Phone, user.PhoneVerifiedAt = "+1-555-123-4567", &verifiedAt
+					return user, nil
+				}
+			},
+		},
+		{
+			name:  "me_phone_confirm_wrong_code",
+			route: "POST /api/v1/users/me/phone/confirm",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/phone/confirm", Body: `{"code":"654321"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Phone.ConfirmCodeFunc = func(context.Context, string, string) (*domain.User, error) {
+					return nil, domain.ErrInvalidPhoneCode
+				}
+			},
+		},
+		{
+			name:  "me_phone_confirm_malformed_code",
+			route: "POST /api/v1/users/me/phone/confirm",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/phone/confirm", Body: `{"code":"12ab"}`},
+			as:    asUser,
+		},
+		{
+			name:  "me_mfa_factors",
+			route: "GET /api/v1/users/me/mfa/factors",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/mfa/factors"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.ListFactorsFunc = func(context.Context, string) ([]domain.MFAFactor, error) { return sampleMFAFactors(), nil }
+			},
+		},
+		{
+			name:  "me_mfa_factor_add_totp",
+			route: "POST /api/v1/users/me/mfa/factors",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/mfa/factors", Body: `{"type":"totp","label":"Phone app"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.AddFactorFunc = func(_ context.Context, _, factorType, label string) (*domain.MFAEnrollment, error) {
+					secret := "JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"
+					return &domain.MFAEnrollment{
+						Factor: domain.MFAFactor{ID: "f3", Type: factorType, Label: label, CreatedAt: created},
+						Secret: secret,
+						URI:    security.TOTPURI("User Management API", "john.doe@example.com", secret),
+					}, nil
+				}
+			},
+		},
+		{
+			name:  "me_mfa_factor_add_phone_unverified",
+			route: "POST /api/v1/users/me/mfa/factors",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/mfa/factors", Body: `{"type":"sms"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.AddFactorFunc = func(context.Context, string, string, string) (*domain.MFAEnrollment, error) {
+					return nil, domain.ErrMFAPhoneUnverified
+				}
+			},
+		},
+		{
+			name:    "me_mfa_factor_add_invalid_type",
+			route:   "POST /api/v1/users/me/mfa/factors",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/mfa/factors", Body: `{"type":"email"}`},
+			as:      asUser,
+			invalid: true,
+		},
+		{
+			name:  "me_mfa_factor_confirm",
+			route: "POST /api/v1/users/me/mfa/factors/:factorId/confirm",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/mfa/factors/f1/confirm", Body: `{"code":"123456"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.ConfirmFactorFunc = func(_ context.Context, userID, factorID, code string) (*domain.MFAFactor, error) {
+					if userID != "u1" || factorID != "f1" || code != "123456" {
+						return nil, domain.ErrInvalidMFACode
+					}
+					return &sampleMFAFactors()[0], nil
+				}
+			},
+		},
+		{
+			name:  "me_mfa_factor_confirm_already_confirmed",
+			route: "POST /api/v1/users/me/mfa/factors/:factorId/confirm",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/mfa/factors/f1/confirm", Body: `{"code":"123456"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.ConfirmFactorFunc = func(context.Context, string, string, string) (*domain.MFAFactor, error) {
+					return nil, domain.ErrMFAFactorConfirmed
+				}
+			},
+		},
+		{
+			name:  "me_mfa_factor_remove",
+			route: "DELETE /api/v1/users/me/mfa/factors/:factorId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/mfa/factors/f2"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.RemoveFactorFunc = func(context.Context, string, string) error { return nil }
+			},
+		},
+		{
+			name:  "me_mfa_factor_remove_not_found",
+			route: "DELETE /api/v1/users/me/mfa/factors/:factorId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/mfa/factors/missing"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.RemoveFactorFunc = func(context.Context, string, string) error { return domain.ErrMFAFactorNotFound }
+			},
+		},
+		{
+			name:  "me_mfa_factor_remove_reauthentication_required",
+			route: "DELETE /api/v1/users/me/mfa/factors/:factorId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/mfa/factors/f2", Token: impersonation},
+		},
+		{
+			name:  "me_trusted_devices",
+			route: "GET /api/v1/users/me/trusted-devices",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/trusted-devices"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.ListTrustedDevicesFunc = func(context.Context, string) ([]domain.TrustedDevice, error) { return sampleTrustedDevices(), nil }
+			},
+		},
+		{
+			name:  "me_trusted_device_revoke",
+			route: "DELETE /api/v1/users/me/trusted-devices/:deviceId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/trusted-devices/d1"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.RevokeTrustedDeviceFunc = func(context.Context, string, string) error { return nil }
+			},
+		},
+		{
+			name:  "me_trusted_device_revoke_not_found",
+			route: "DELETE /api/v1/users/me/trusted-devices/:deviceId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/trusted-devices/missing"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.RevokeTrustedDeviceFunc = func(context.Context, string, string) error { return domain.ErrTrustedDeviceNotFound }
+			},
+		},
+		{
+			name:  "me_trusted_devices_revoke_all",
+			route: "DELETE /api/v1/users/me/trusted-devices",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/trusted-devices"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.MFA.RevokeTrustedDevicesFunc = func(context.Context, string) (int, error) { return 2, nil }
+			},
+		},
+		{
+			name:  "me_login_history",
+			route: "GET /api/v1/users/me/login-history",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/login-history"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.LoginEvents.ListLoginHistoryFunc = func(context.Context, string, int, int) (*ports.LoginHistory, error) {
+					event := &domain.LoginEvent{ID: "e1", UserID: "u1", IP: "203.0.113.7", UserAgent: "Mozilla/5.0", CreatedAt: created}
+					return &ports.LoginHistory{Events: []*domain.LoginEvent{event}, TotalCount: 1, Page: 1, PageSize: 20, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			// Safe methods of cookie sessions need no CSRF token
+			name:  "me_login_history_cookie_session",
+			route: "GET /api/v1/users/me/login-history",
+			req:   sessionRequest(http.MethodGet, "/api/v1/users/me/login-history", "", ""),
+			deps:  cookieMode,
+			setup: func(h *routestest.Harness) {
+				cookieSession(h)
+				h.LoginEvents.ListLoginHistoryFunc = func(context.Context, string, int, int) (*ports.LoginHistory, error) {
+					return &ports.LoginHistory{Events: []*domain.LoginEvent{}, TotalCount: 0, Page: 1, PageSize: 20, TotalPages: 0}, nil
+				}
+			},
+		},
+		{
+			name:  "me_logout_all",
+			route: "POST /api/v1/users/me/logout-all",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/logout-all"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.LogoutAllFunc = func(context.Context, string, string) error { return nil }
+			},
+		},
+		{
+			name:  "me_logout_all_revoked_token",
+			route: "POST /api/v1/users/me/logout-all",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/logout-all"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				// The token of the caller has generation 0, issued before a previous logout from all devices
+				h.Users.TokenGenerationFunc = func(context.Context, string) (int, error) { return 1, nil }
+			},
+		},
+		{
+			name:  "me_delete",
+			route: "DELETE /api/v1/users/me",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.DeleteUserFunc = func(context.Context, string) error { return nil }
+			},
+		},
+		{
+			name:  "me_delete_reauthentication_required",
+			route: "DELETE /api/v1/users/me",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me", Token: impersonation},
+		},
+		{
+			name:  "me_security_events",
+			route: "GET /api/v1/users/me/security-events",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/security-events?before=2024-02-01T00:00:00Z&limit=2"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Security.ListSecurityEventsFunc = func(context.Context, string, time.Time, int) (*ports.SecurityEventFeed, error) {
+					next := created.Add(-time.Hour)
+					return &ports.SecurityEventFeed{
+						Events: []domain.SecurityEvent{
+							{Type: domain.AuditActionMFAFactorAdded, OccurredAt: created, Details: map[string]string{"factor_id": "f1", "type": domain.MFAFactorTOTP}},
+							{Type: domain.SecurityEventNewDeviceLogin, OccurredAt: next, Details: map[string]string{"login_event_id": "e1", "ip": "203.0.113.7", "user_agent": "Mozilla/5.0"}},
+						},
+						NextBefore: &next,
+					}, nil
+				}
+			},
+		},
+		{
+			name:  "me_security_events_invalid_before",
+			route: "GET /api/v1/users/me/security-events",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/security-events?before=yesterday"},
+			as:    asUser,
+		},
+		{
+			name:  "me_username",
+			route: "PUT /api/v1/users/me/username",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/me/username", Body: `{"username":"johndoe"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.SetUsernameFunc = func(context.Context, string, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+		},
+		{
+			name:  "me_username_taken",
+			route: "PUT /api/v1/users/me/username",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/me/username", Body: `{"username":"janedoe"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.SetUsernameFunc = func(context.Context, string, string) (*domain.User, error) { return nil, domain.ErrUsernameTaken }
+			},
+		},
+		{
+			name:  "me_addresses",
+			route: "GET /api/v1/users/me/addresses",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/addresses"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.ListAddressesFunc = func(context.Context, string) ([]domain.Address, error) {
+					return []domain.Address{*sampleAddress()}, nil
+				}
+			},
+		},
+		{
+			name:  "me_addresses_add",
+			route: "POST /api/v1/users/me/addresses",
+			req: routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/addresses",
+				Body: `{"type":"home","primary":true,"street":"123 Main St","city":"New York","state":"NY","country":"US","zip_code":"10001"}`},
+			as: asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.AddAddressFunc = func(context.Context, string, domain.Address) (*domain.Address, error) { return sampleAddress(), nil }
+			},
+		},
+		{
+			name:  "me_addresses_add_invalid_type",
+			route: "POST /api/v1/users/me/addresses",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/addresses", Body: `{"type":"castle","city":"New York"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.AddAddressFunc = func(context.Context, string, domain.Address) (*domain.Address, error) {
+					return nil, domain.ErrInvalidAddressType
+				}
+			},
+		},
+		{
+			name:  "me_addresses_update",
+			route: "PUT /api/v1/users/me/addresses/:addressId",
+			req: routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/me/addresses/a1",
+				Body: `{"type":"home","primary":true,"street":"123 Main St","city":"New York","state":"NY","country":"US","zip_code":"10001"}`},
+			as: asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpdateAddressFunc = func(context.Context, string, string, domain.Address) (*domain.Address, error) {
+					return sampleAddress(), nil
+				}
+			},
+		},
+		{
+			name:  "me_addresses_remove",
+			route: "DELETE /api/v1/users/me/addresses/:addressId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/addresses/a1"},
+			as:    asUser,
+		},
+		{
+			name:  "me_addresses_remove_not_found",
+			route: "DELETE /api/v1/users/me/addresses/:addressId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/addresses/missing"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Users.RemoveAddressFunc = func(context.Context, string, string) error { return domain.ErrAddressNotFound }
+			},
+		},
+		{
+			name:  "me_organizations",
+			route: "GET /api/v1/users/me/organizations",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/organizations"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.ListUserMembershipsFunc = func(context.Context, string) ([]*domain.Membership, error) {
+					return []*domain.Membership{sampleMembership(domain.MembershipRoleOwner)}, nil
+				}
+			},
+		},
+		{
+			name:  "me_follow",
+			route: "PUT /api/v1/users/me/following/:userId",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/me/following/u2"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Relationships.FollowFunc = func(context.Context, string, string) (*domain.Relationship, error) {
+					return sampleRelationship(), nil
+				}
+			},
+		},
+		{
+			name:  "me_follow_self",
+			route: "PUT /api/v1/users/me/following/:userId",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/me/following/u1"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Relationships.FollowFunc = func(context.Context, string, string) (*domain.Relationship, error) {
+					return nil, domain.ErrFollowSelf
+				}
+			},
+		},
+		{
+			name:  "me_follow_blocked_by_user",
+			route: "PUT /api/v1/users/me/following/:userId",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/me/following/u3"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Relationships.FollowFunc = func(context.Context, string, string) (*domain.Relationship, error) {
+					return nil, usecase.ErrBlockedByUser
+				}
+			},
+		},
+		{
+			name:  "me_unfollow",
+			route: "DELETE /api/v1/users/me/following/:userId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/following/u2"},
+			as:    asUser,
+		},
+		{
+			name:  "me_unfollow_not_followed",
+			route: "DELETE /api/v1/users/me/following/:userId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/following/u3"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Relationships.UnfollowFunc = func(context.Context, string, string) error { return usecase.ErrRelationshipNotFound }
+			},
+		},
+		{
+			name:  "me_blocks",
+			route: "GET /api/v1/users/me/blocks",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/blocks"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Relationships.ListBlocksFunc = func(context.Context, *ports.RelationshipQuery) (*ports.BlockQueryResult, error) {
+					return &ports.BlockQueryResult{Blocks: []*domain.Block{sampleBlock()}, TotalCount: 1, Page: 1, PageSize: 20, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:  "me_block",
+			route: "POST /api/v1/users/me/blocks/:id",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/blocks/u3"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Relationships.BlockFunc = func(context.Context, string, string) (*domain.Block, error) { return sampleBlock(), nil }
+			},
+		},
+		{
+			name:  "me_block_self",
+			route: "POST /api/v1/users/me/blocks/:id",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/me/blocks/u1"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Relationships.BlockFunc = func(context.Context, string, string) (*domain.Block, error) { return nil, domain.ErrBlockSelf }
+			},
+		},
+		{
+			name:  "me_unblock",
+			route: "DELETE /api/v1/users/me/blocks/:id",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/blocks/u3"},
+			as:    asUser,
+		},
+		{
+			name:  "me_unblock_not_blocked",
+			route: "DELETE /api/v1/users/me/blocks/:id",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/me/blocks/u2"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Relationships.UnblockFunc = func(context.Context, string, string) error { return usecase.ErrBlockNotFound }
+			},
+		},
+
+		// Organization routes
+		{
+			name:  "organizations_create",
+			route: "POST /api/v1/organizations",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/organizations", Body: `{"name":"Acme Corp","slug":"acme-corp"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.CreateOrganizationFunc = func(context.Context, string, string, string) (*domain.Organization, error) {
+					return sampleOrganization(), nil
+				}
+			},
+		},
+		{
+			name:  "organizations_create_slug_taken",
+			route: "POST /api/v1/organizations",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/organizations", Body: `{"name":"Acme Corp","slug":"acme-corp"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.CreateOrganizationFunc = func(context.Context, string, string, string) (*domain.Organization, error) {
+					return nil, usecase.ErrSlugTaken
+				}
+			},
+		},
+		{
+			name:  "organizations_get",
+			route: "GET /api/v1/organizations/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/organizations/o1"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.GetOrganizationFunc = func(context.Context, string, string) (*domain.Organization, error) {
+					return sampleOrganization(), nil
+				}
+			},
+		},
+		{
+			name:  "organizations_get_not_member",
+			route: "GET /api/v1/organizations/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/organizations/o2"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.GetOrganizationFunc = func(context.Context, string, string) (*domain.Organization, error) {
+					return nil, usecase.ErrNotMember
+				}
+			},
+		},
+		{
+			name:  "organizations_update",
+			route: "PATCH /api/v1/organizations/:id",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/organizations/o1", Body: `{"name":"Acme Corporation"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.UpdateOrganizationFunc = func(context.Context, string, string, string) (*domain.Organization, error) {
+					org := sampleOrganization()
+					org.Name = "Acme Corporation"
+					return org, nil
+				}
+			},
+		},
+		{
+			name:  "organizations_delete",
+			route: "DELETE /api/v1/organizations/:id",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/organizations/o1"},
+			as:    asUser,
+		},
+		{
+			name:  "organizations_delete_not_owner",
+			route: "DELETE /api/v1/organizations/:id",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/organizations/o1"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.DeleteOrganizationFunc = func(context.Context, string, string) error { return usecase.ErrOrgPermissionDenied }
+			},
+		},
+		{
+			name:  "organizations_members",
+			route: "GET /api/v1/organizations/:id/members",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/organizations/o1/members"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.ListMembersFunc = func(context.Context, string, string) ([]*domain.Membership, error) {
+					return []*domain.Membership{sampleMembership(domain.MembershipRoleMember)}, nil
+				}
+			},
+		},
+		{
+			name:  "organizations_members_set",
+			route: "PUT /api/v1/organizations/:id/members/:userId",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/organizations/o1/members/u2", Body: `{"role":"admin"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.SetMemberFunc = func(context.Context, string, string, string, string) (*domain.Membership, error) {
+					return sampleMembership(domain.MembershipRoleAdmin), nil
+				}
+			},
+		},
+		{
+			name:  "organizations_members_set_invalid_role",
+			route: "PUT /api/v1/organizations/:id/members/:userId",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/organizations/o1/members/u2", Body: `{"role":"boss"}`},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.SetMemberFunc = func(context.Context, string, string, string, string) (*domain.Membership, error) {
+					return nil, domain.ErrInvalidMembershipRole
+				}
+			},
+		},
+		{
+			name:  "organizations_members_remove",
+			route: "DELETE /api/v1/organizations/:id/members/:userId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/organizations/o1/members/u2"},
+			as:    asUser,
+		},
+		{
+			name:  "organizations_members_remove_last_owner",
+			route: "DELETE /api/v1/organizations/:id/members/:userId",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/organizations/o1/members/u1"},
+			as:    asUser,
+			setup: func(h *routestest.Harness) {
+				h.Organizations.RemoveMemberFunc = func(context.Context, string, string, string) error { return usecase.ErrLastOwner }
+			},
+		},
+
+		// Staff routes
+		{
+			name:  "users_metadata",
+			route: "PATCH /api/v1/users/:id/metadata",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/u1/metadata", Body: `{"plan":"pro","legacy_id":null}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpdateMetadataFunc = func(context.Context, string, map[string]*string) (map[string]string, error) {
+					return map[string]string{"plan": "pro"}, nil
+				}
+			},
+		},
+		{
+			name:  "users_metadata_forbidden",
+			route: "PATCH /api/v1/users/:id/metadata",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/u1/metadata", Body: `{"plan":"pro"}`},
+			as:    asUser,
+		},
+		{
+			name:  "users_profile_update_override",
+			route: "PATCH /api/v1/users/:id/profile",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/u1/profile", Body: `{"birthdate":"2015-05-15","override_minimum_age":true}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpdateProfileFunc = func(_ context.Context, actorID, userID string, update domain.ProfileUpdate, override bool) (*domain.User, error) {
+					if actorID != "admin1" || userID != "u1" || !override {
+						return nil, errors.New("unexpected profile update")
+					}
+					user := sampleUser()
+					user.Profile.Birthdate = *update.Birthdate
+					return user, nil
+				}
+			},
+		},
+		{
+			name:  "users_profile_update_forbidden",
+			route: "PATCH /api/v1/users/:id/profile",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/users/u2/profile", Body: `{"birthdate":"2015-05-15","override_minimum_age":true}`},
+			as:    asUser,
+		},
+		{
+			name:  "users_upsert_created",
+			route: "PUT /api/v1/users/by-email/:email",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/by-email/john.doe@example.com", Body: `{"username":"johndoe","first_name":"John","last_name":"Doe"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpsertUserByEmailFunc = func(_ context.Context, _, email string, _ domain.UserUpsert, _ string) (*domain.User, bool, error) {
+					if email != "john.doe@example.com" {
+						return nil, false, errors.New("unexpected email")
+					}
+					return sampleUser(), true, nil
+				}
+			},
+		},
+		{
+			name:  "users_upsert_keep_existing",
+			route: "PUT /api/v1/users/by-email/:email",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/by-email/john.doe@example.com?on_conflict=keep_existing", Body: `{"first_name":"Johnny","timezone":"America/Sao_Paulo"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpsertUserByEmailFunc = func(_ context.Context, _, _ string, upsert domain.UserUpsert, policy string) (*domain.User, bool, error) {
+					user, _, err := upsert.Apply(sampleUser(), "", policy)
+					return user, false, err
+				}
+			},
+		},
+		{
+			name:  "users_upsert_reject_conflict",
+			route: "PUT /api/v1/users/by-email/:email",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/by-email/john.doe@example.com?on_conflict=reject", Body: `{"first_name":"Johnny","last_name":"Doe"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpsertUserByEmailFunc = func(_ context.Context, _, _ string, upsert domain.UserUpsert, policy string) (*domain.User, bool, error) {
+					_, _, err := upsert.Apply(sampleUser(), "", policy)
+					return nil, false, err
+				}
+			},
+		},
+		{
+			name:  "users_upsert_invalid_policy",
+			route: "PUT /api/v1/users/by-email/:email",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/by-email/john.doe@example.com?on_conflict=merge", Body: `{"first_name":"John"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.UpsertUserByEmailFunc = func(_ context.Context, _, _ string, _ domain.UserUpsert, policy string) (*domain.User, bool, error) {
+					_, err := domain.ParseUpsertPolicy(policy)
+					return nil, false, err
+				}
+			},
+		},
+		{
+			name:  "users_upsert_forbidden",
+			route: "PUT /api/v1/users/by-email/:email",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/users/by-email/john.doe@example.com", Body: `{"first_name":"John"}`},
+			as:    asUser,
+		},
+		{
+			name:  "users_find_or_create_created",
+			route: "POST /api/v1/users/find-or-create",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/find-or-create", Body: `{"email":"john.doe@example.com","first_name":"John","last_name":"Doe"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.FindOrCreateFunc = func(context.Context, string, string, string, string) (*domain.User, bool, error) {
+					return sampleUser(), true, nil
+				}
+			},
+		},
+		{
+			name:  "users_find_or_create_existing",
+			route: "POST /api/v1/users/find-or-create",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/find-or-create", Body: `{"email":"john.doe@example.com"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.FindOrCreateFunc = func(context.Context, string, string, string, string) (*domain.User, bool, error) {
+					return sampleUser(), false, nil
+				}
+			},
+		},
+		{
+			name:    "users_find_or_create_invalid_email",
+			route:   "POST /api/v1/users/find-or-create",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/find-or-create", Body: `{"email":"john.doe"}`},
+			as:      asAdmin,
+			invalid: true,
+		},
+		{
+			name:  "users_find_or_create_forbidden",
+			route: "POST /api/v1/users/find-or-create",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/find-or-create", Body: `{"email":"john.doe@example.com"}`},
+			as:    asUser,
+		},
+		{
+			name:  "users_tags_add",
+			route: "POST /api/v1/users/:id/tags",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/tags", Body: `{"tags":["beta","vip"]}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.AddTagsFunc = func(context.Context, string, []string) ([]string, error) { return []string{"beta", "vip"}, nil }
+			},
+		},
+		{
+			name:  "users_tags_add_invalid",
+			route: "POST /api/v1/users/:id/tags",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/tags", Body: `{"tags":["Not A Tag"]}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.AddTagsFunc = func(context.Context, string, []string) ([]string, error) { return nil, domain.ErrInvalidTag }
+			},
+		},
+		{
+			name:  "users_tags_remove",
+			route: "DELETE /api/v1/users/:id/tags/:tag",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/users/u1/tags/beta"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.RemoveTagFunc = func(context.Context, string, string) ([]string, error) { return []string{"vip"}, nil }
+			},
+		},
+		{
+			name:  "users_history",
+			route: "GET /api/v1/users/:id/history",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1/history?page=1&page_size=10"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.UserHistory.ListHistoryFunc = func(context.Context, *ports.UserHistoryQuery) (*ports.UserHistoryResult, error) {
+					return &ports.UserHistoryResult{Versions: []*domain.UserVersion{sampleUserVersion()}, TotalCount: 1, Page: 1, PageSize: 10, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:  "users_history_not_found",
+			route: "GET /api/v1/users/:id/history",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/missing/history"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.UserHistory.ListHistoryFunc = func(context.Context, *ports.UserHistoryQuery) (*ports.UserHistoryResult, error) {
+					return nil, usecase.ErrUserNotFound
+				}
+			},
+		},
+		{
+			name:  "users_revert",
+			route: "POST /api/v1/users/:id/revert",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/revert?version=2"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.UserHistory.RevertUserFunc = func(context.Context, string, string, int) (*domain.User, error) { return sampleUser(), nil }
+			},
+		},
+		{
+			name:    "users_revert_invalid",
+			route:   "POST /api/v1/users/:id/revert",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/revert?version=latest"},
+			as:      asAdmin,
+			invalid: true,
+			setup: func(h *routestest.Harness) {
+				h.UserHistory.RevertUserFunc = func(context.Context, string, string, int) (*domain.User, error) {
+					return nil, usecase.ErrInvalidUserVersion
+				}
+			},
+		},
+		{
+			name:  "users_revert_not_found",
+			route: "POST /api/v1/users/:id/revert",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/users/u1/revert?version=9"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.UserHistory.RevertUserFunc = func(context.Context, string, string, int) (*domain.User, error) {
+					return nil, usecase.ErrUserVersionNotFound
+				}
+			},
+		},
+
+		// Admin routes
+		{
+			name:  "roles_list",
+			route: "GET /api/v1/admin/roles",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/roles"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Roles.ListRolesFunc = func(context.Context) ([]*domain.Role, error) {
+					return append(domain.SystemRoles(), sampleRole()), nil
+				}
+			},
+		},
+		{
+			name:  "roles_list_read_only_token",
+			route: "GET /api/v1/admin/roles",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/roles", Token: readOnlyAdmin},
+		},
+		{
+			name:  "roles_list_forbidden",
+			route: "GET /api/v1/admin/roles",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/roles"},
+			as:    asUser,
+		},
+		{
+			name:  "roles_create",
+			route: "POST /api/v1/admin/roles",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/roles", Body: `{"name":"support","description":"Support agents","permissions":["users:tags"]}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Roles.CreateRoleFunc = func(context.Context, string, string, []string) (*domain.Role, error) { return sampleRole(), nil }
+			},
+		},
+		{
+			name:  "roles_create_exists",
+			route: "POST /api/v1/admin/roles",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/roles", Body: `{"name":"support"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Roles.CreateRoleFunc = func(context.Context, string, string, []string) (*domain.Role, error) {
+					return nil, usecase.ErrRoleExists
+				}
+			},
+		},
+		{
+			name:  "roles_get",
+			route: "GET /api/v1/admin/roles/:name",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/roles/support"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Roles.GetRoleFunc = func(context.Context, string) (*domain.Role, error) { return sampleRole(), nil }
+			},
+		},
+		{
+			name:  "roles_get_not_found",
+			route: "GET /api/v1/admin/roles/:name",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/roles/missing"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Roles.GetRoleFunc = func(context.Context, string) (*domain.Role, error) { return nil, usecase.ErrRoleNotFound }
+			},
+		},
+		{
+			name:  "roles_update",
+			route: "PATCH /api/v1/admin/roles/:name",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/admin/roles/support", Body: `{"permissions":["users:tags","users:metadata"]}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Roles.UpdateRoleFunc = func(_ context.Context, _ string, _ *string, permissions []string) (*domain.Role, error) {
+					role := sampleRole()
+					role.Permissions = permissions
+					return role, nil
+				}
+			},
+		},
+		{
+			name:  "roles_delete",
+			route: "DELETE /api/v1/admin/roles/:name",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/admin/roles/support"},
+			as:    asAdmin,
+		},
+		{
+			name:  "roles_delete_system",
+			route: "DELETE /api/v1/admin/roles/:name",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/admin/roles/admin"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Roles.DeleteRoleFunc = func(context.Context, string) error { return usecase.ErrSystemRole }
+			},
+		},
+		{
+			name:  "roles_assign",
+			route: "PUT /api/v1/admin/users/:id/roles/:name",
+			req:   routestest.Request{Method: http.MethodPut, Target: "/api/v1/admin/users/u1/roles/support"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Roles.AssignRoleFunc = func(context.Context, string, string) ([]string, error) { return []string{"user", "support"}, nil }
+			},
+		},
+		{
+			name:  "roles_unassign",
+			route: "DELETE /api/v1/admin/users/:id/roles/:name",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/admin/users/u1/roles/support"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Roles.UnassignRoleFunc = func(context.Context, string, string) ([]string, error) { return []string{"user"}, nil }
+			},
+		},
+		{
+			name:  "roles_unassign_last_role",
+			route: "DELETE /api/v1/admin/users/:id/roles/:name",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/admin/users/u1/roles/user"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Roles.UnassignRoleFunc = func(context.Context, string, string) ([]string, error) { return nil, usecase.ErrLastRoleOfUser }
+			},
+		},
+		{
+			name:  "impersonate",
+			route: "POST /api/v1/admin/users/:id/impersonate",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/users/u1/impersonate", Body: `{"reason":"Investigating ticket #4521"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+			ignore: []string{"access_token", "expires_at"},
+		},
+		{
+			name:  "impersonate_more_privileged_user",
+			route: "POST /api/v1/admin/users/:id/impersonate",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/users/admin1/impersonate", Body: `{"reason":"Investigating ticket #4521"}`},
+			as:    asSupport,
+			setup: func(h *routestest.Harness) {
+				supportRoles(h, domain.PermissionUsersImpersonate)
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) {
+					admin := sampleUser()
+					admin.ID, admin.Roles = "admin1", []string{domain.RoleAdmin}
+					return admin, nil
+				}
+			},
+		},
+		{
+			name:  "impersonate_by_support",
+			route: "POST /api/v1/admin/users/:id/impersonate",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/users/u1/impersonate", Body: `{"reason":"Investigating ticket #4521"}`},
+			as:    asSupport,
+			setup: func(h *routestest.Harness) {
+				supportRoles(h, domain.PermissionUsersImpersonate)
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+			ignore: []string{"access_token", "expires_at"},
+		},
+		{
+			name:  "impersonate_self",
+			route: "POST /api/v1/admin/users/:id/impersonate",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/users/admin1/impersonate", Body: `{"reason":"Testing"}`},
+			as:    asAdmin,
+		},
+		{
+			name:  "duplicates",
+			route: "GET /api/v1/admin/users/duplicates",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/users/duplicates"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Merge.FindDuplicatesFunc = func(context.Context, int, int) (*ports.DuplicateReport, error) {
+					duplicate := sampleUser()
+					duplicate.ID, duplicate.Email, duplicate.Username = "u2", "john.doe+old@example.com", ""
+					candidate := &domain.DuplicateCandidate{Reasons: []string{"similar_email"}, Users: []*domain.User{sampleUser(), duplicate}}
+					return &ports.DuplicateReport{Candidates: []*domain.DuplicateCandidate{candidate}, TotalCount: 1, Page: 1, PageSize: 20, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:  "merge",
+			route: "POST /api/v1/admin/users/:id/merge",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/users/u1/merge", Body: `{"duplicate_id":"u2","policy":"keep_primary"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Merge.MergeFunc = func(context.Context, string, string, string, string) (*domain.User, error) { return sampleUser(), nil }
+			},
+		},
+		{
+			name:    "merge_invalid_policy",
+			route:   "POST /api/v1/admin/users/:id/merge",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/users/u1/merge", Body: `{"duplicate_id":"u2","policy":"newest"}`},
+			as:      asAdmin,
+			invalid: true,
+		},
+		{
+			name:  "users_batch_tag_country",
+			route: "PATCH /api/v1/admin/users/batch",
+			req: routestest.Request{Method: http.MethodPatch, Target: "/api/v1/admin/users/batch",
+				Body: `{"filter":{"field":"profile.addresses.country","op":"eq","value":"BR"},"update":{"add_tags":["vip"]}}`},
+			as: asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.BatchUpdateUsersFunc = func(context.Context, string, *ports.GetUsersOptions, domain.UserBatchUpdate) (*ports.UserBatchResult, error) {
+					return &ports.UserBatchResult{Matched: 42, Modified: 40}, nil
+				}
+			},
+		},
+		{
+			name:  "users_batch_suspend_ids",
+			route: "PATCH /api/v1/admin/users/batch",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/admin/users/batch", Body: `{"ids":["u1","u2"],"update":{"deactivated":true}}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.BatchUpdateUsersFunc = func(context.Context, string, *ports.GetUsersOptions, domain.UserBatchUpdate) (*ports.UserBatchResult, error) {
+					return &ports.UserBatchResult{Matched: 2, Modified: 2}, nil
+				}
+			},
+		},
+		{
+			name:  "users_batch_no_selector",
+			route: "PATCH /api/v1/admin/users/batch",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/admin/users/batch", Body: `{"update":{"add_tags":["vip"]}}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Users.BatchUpdateUsersFunc = func(context.Context, string, *ports.GetUsersOptions, domain.UserBatchUpdate) (*ports.UserBatchResult, error) {
+					return nil, domain.ErrBatchSelectorMissing
+				}
+			},
+		},
+		{
+			name:  "users_batch_forbidden",
+			route: "PATCH /api/v1/admin/users/batch",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/admin/users/batch", Body: `{"ids":["u1"],"update":{"deactivated":true}}`},
+			as:    asUser,
+		},
+		{
+			name:  "export",
+			route: "GET /api/v1/admin/users/export",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/users/export"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Export.ExportUsersFunc = func(_ context.Context, _ string, _ *ports.UserExport, w io.Writer) (int, error) {
+					_, err := io.WriteString(w, `{"id":"u1","email":"john.doe@example.com"}`+"\n")
+					return 1, err
+				}
+			},
+		},
+		{
+			name:    "export_invalid_format",
+			route:   "GET /api/v1/admin/users/export",
+			req:     routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/users/export?format=xlsx"},
+			as:      asAdmin,
+			invalid: true,
+		},
+		{
+			name:  "audit_logs",
+			route: "GET /api/v1/admin/audit-logs",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/audit-logs?action=impersonation.started"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Audit.ListFunc = func(context.Context, *ports.AuditQuery) (*ports.AuditQueryResult, error) {
+					event := &domain.AuditEvent{ID: "ev1", Action: domain.AuditActionImpersonationStarted, ActorID: "admin1", TargetID: "u1",
+						Details: map[string]string{"reason": "Investigating ticket #4521"}, CreatedAt: created}
+					return &ports.AuditQueryResult{Events: []*domain.AuditEvent{event}, TotalCount: 1, Page: 1, PageSize: 20, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:  "ip_blocks",
+			route: "GET /api/v1/admin/security/ip-blocks",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/security/ip-blocks"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.IPBackoff.ListBlocksFunc = func(context.Context) ([]ports.IPBlock, error) {
+					return []ports.IPBlock{{IP: "203.0.113.7", Failures: 8, LastFailureAt: created, BlockedUntil: created.Add(8 * time.Second)}}, nil
+				}
+				h.IPBackoff.StatsFunc = func(context.Context) (*ports.IPBackoffStats, error) {
+					return &ports.IPBackoffStats{Failures: 8, BlocksIssued: 1, ActiveBlocks: 1, TrackedIPs: 1}, nil
+				}
+			},
+		},
+		{
+			name:  "ip_blocks_clear",
+			route: "DELETE /api/v1/admin/security/ip-blocks/:ip",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/admin/security/ip-blocks/203.0.113.7"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.IPBackoff.ClearBlockFunc = func(context.Context, string) (bool, error) { return true, nil }
+			},
+		},
+		{
+			name:  "ip_blocks_clear_unknown",
+			route: "DELETE /api/v1/admin/security/ip-blocks/:ip",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/admin/security/ip-blocks/198.51.100.1"},
+			as:    asAdmin,
+		},
+		{
+			name:  "signing_keys",
+			route: "GET /api/v1/admin/security/signing-keys",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/security/signing-keys"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.SigningKeys.ListSigningKeysFunc = func(context.Context) ([]*domain.SigningKey, error) {
+					expires := created.Add(70 * time.Minute)
+					return []*domain.SigningKey{
+						{ID: "mQKaAl5vXfBWXOVGqTfgHqLLfPWV9uGTEOFNrfSyBso", Algorithm: "EdDSA", PrivateKey: []byte("sealed"), Status: domain.SigningKeyRetired, CreatedAt: created, ActivatesAt: created, ExpiresAt: &expires},
+						{ID: "CxCc3cbyfs4AjLRtKISuy9-8Qo1yLrNx910PHt93HSw", Algorithm: "EdDSA", PrivateKey: []byte("sealed"), Status: domain.SigningKeyActive, CreatedAt: created, ActivatesAt: created.Add(10 * time.Minute)},
+					}, nil
+				}
+			},
+		},
+		{
+			name:  "signing_keys_disabled",
+			route: "GET /api/v1/admin/security/signing-keys",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/security/signing-keys"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.SigningKeys.ListSigningKeysFunc = func(context.Context) ([]*domain.SigningKey, error) {
+					return nil, usecase.ErrSigningKeysDisabled
+				}
+			},
+		},
+		{
+			name:  "signing_keys_rotate",
+			route: "POST /api/v1/admin/security/signing-keys/rotate",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/security/signing-keys/rotate"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.SigningKeys.RotateSigningKeyFunc = func(_ context.Context, actorID string) (*domain.SigningKey, error) {
+					return &domain.SigningKey{ID: "Ww2C0u3wX7pV3n-pWQbL4JvEvuN0sYbKcQH1l3tJ1mE", Algorithm: "EdDSA", Status: domain.SigningKeyPending, CreatedAt: created, ActivatesAt: created.Add(10 * time.Minute)}, nil
+				}
+			},
+		},
+		{
+			name:  "oauth_clients_list",
+			route: "GET /api/v1/admin/oauth-clients",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/oauth-clients"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.ListClientsFunc = func(context.Context) ([]*domain.OAuthClient, error) {
+					return []*domain.OAuthClient{sampleOAuthClient()}, nil
+				}
+			},
+		},
+		{
+			name:  "oauth_clients_list_as_client",
+			route: "GET /api/v1/admin/oauth-clients",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/oauth-clients", Token: clientToken},
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.ListClientsFunc = func(context.Context) ([]*domain.OAuthClient, error) {
+					return []*domain.OAuthClient{sampleOAuthClient()}, nil
+				}
+			},
+		},
+		{
+			name:  "oauth_client_deleted",
+			route: "GET /api/v1/admin/oauth-clients",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/oauth-clients", Token: clientToken},
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.GetClientFunc = func(context.Context, string) (*domain.OAuthClient, error) { return nil, usecase.ErrClientNotFound }
+				h.OAuthClients.ListClientsFunc = func(context.Context) ([]*domain.OAuthClient, error) {
+					t.Error("the tokens of deleted clients must be refused")
+					return nil, nil
+				}
+			},
+		},
+		{
+			name:  "oauth_client_scope_missing",
+			route: "GET /api/v1/admin/audit-logs",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/audit-logs", Token: clientToken},
+		},
+		{
+			name:  "oauth_client_on_user_route",
+			route: "GET /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/settings", Token: clientToken},
+		},
+		{
+			name:  "oauth_clients_get",
+			route: "GET /api/v1/admin/oauth-clients/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/oauth-clients/c1"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.GetClientFunc = func(context.Context, string) (*domain.OAuthClient, error) { return sampleOAuthClient(), nil }
+			},
+		},
+		{
+			name:  "oauth_clients_get_unknown",
+			route: "GET /api/v1/admin/oauth-clients/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/oauth-clients/missing"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.GetClientFunc = func(context.Context, string) (*domain.OAuthClient, error) { return nil, usecase.ErrClientNotFound }
+			},
+		},
+		{
+			name:  "oauth_clients_register",
+			route: "POST /api/v1/admin/oauth-clients",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/oauth-clients", Body: `{"name":"billing-service","scopes":["admin","system:read","security:manage"]}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.RegisterClientFunc = func(context.Context, string, string, []string) (*domain.OAuthClient, string, error) {
+					return sampleOAuthClient(), "Zm9vYmFyYmF6cXV4LXNlY3JldC1vZi0zMi1ieXRlcw", nil
+				}
+			},
+		},
+		{
+			name:  "oauth_clients_register_invalid_scope",
+			route: "POST /api/v1/admin/oauth-clients",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/oauth-clients", Body: `{"name":"billing-service","scopes":["*"]}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.OAuthClients.RegisterClientFunc = func(context.Context, string, string, []string) (*domain.OAuthClient, string, error) {
+					return nil, "", domain.ErrInvalidScope
+				}
+			},
+		},
+		{
+			name:  "oauth_clients_delete",
+			route: "DELETE /api/v1/admin/oauth-clients/:id",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/admin/oauth-clients/c1"},
+			as:    asAdmin,
+		},
+		{
+			name:  "email_domains_list",
+			route: "GET /api/v1/admin/email-domains",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/email-domains"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.EmailDomains.ListRulesFunc = func(context.Context) ([]*domain.EmailDomainRule, error) {
+					configured := &domain.EmailDomainRule{List: domain.EmailDomainDeny, Domain: "mailinator.com", Configured: true}
+					return []*domain.EmailDomainRule{configured, sampleEmailDomainRule()}, nil
+				}
+			},
+		},
+		{
+			name:  "email_domains_add",
+			route: "POST /api/v1/admin/email-domains",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/email-domains", Body: `{"list":"allow","domain":"Example.com"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.EmailDomains.AddRuleFunc = func(context.Context, string, string, string) (*domain.EmailDomainRule, error) {
+					return sampleEmailDomainRule(), nil
+				}
+			},
+		},
+		{
+			name:    "email_domains_add_invalid_list",
+			route:   "POST /api/v1/admin/email-domains",
+			req:     routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/email-domains", Body: `{"list":"block","domain":"example.com"}`},
+			as:      asAdmin,
+			invalid: true,
+		},
+		{
+			name:  "email_domains_add_invalid_domain",
+			route: "POST /api/v1/admin/email-domains",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/email-domains", Body: `{"list":"allow","domain":"localhost"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.EmailDomains.AddRuleFunc = func(context.Context, string, string, string) (*domain.EmailDomainRule, error) {
+					return nil, domain.ErrInvalidEmailDomain
+				}
+			},
+		},
+		{
+			name:  "email_domains_add_exists",
+			route: "POST /api/v1/admin/email-domains",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/email-domains", Body: `{"list":"allow","domain":"example.com"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.EmailDomains.AddRuleFunc = func(context.Context, string, string, string) (*domain.EmailDomainRule, error) {
+					return nil, domain.ErrEmailDomainRuleExists
+				}
+			},
+		},
+		{
+			name:  "email_domains_add_as_user",
+			route: "POST /api/v1/admin/email-domains",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/email-domains", Body: `{"list":"allow","domain":"example.com"}`},
+			as:    asUser,
+		},
+		{
+			name:  "email_domains_remove",
+			route: "DELETE /api/v1/admin/email-domains/:list/:domain",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/admin/email-domains/allow/example.com"},
+			as:    asAdmin,
+		},
+		{
+			name:  "email_domains_remove_unknown",
+			route: "DELETE /api/v1/admin/email-domains/:list/:domain",
+			req:   routestest.Request{Method: http.MethodDelete, Target: "/api/v1/admin/email-domains/deny/example.org"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.EmailDomains.RemoveRuleFunc = func(context.Context, string, string, string) error { return usecase.ErrEmailDomainRuleNotFound }
+			},
+		},
+		{
+			name:  "terms_versions_list",
+			route: "GET /api/v1/admin/terms",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/terms?document=terms"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Terms.ListVersionsFunc = func(context.Context, string) ([]*domain.TermsVersion, error) {
+					previous := &domain.TermsVersion{ID: "tv1", Document: domain.TermsDocumentTerms, Version: "2023-01-01", PublishedBy: "admin1", PublishedAt: created.Add(-time.Hour)}
+					return []*domain.TermsVersion{sampleTermsVersion(), previous}, nil
+				}
+			},
+		},
+		{
+			name:  "terms_versions_publish",
+			route: "POST /api/v1/admin/terms",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/terms", Body: `{"document":"terms","version":"2024-06-01","url":"https://example.com/legal/terms/2024-06-01"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Terms.PublishVersionFunc = func(context.Context, string, string, string, string) (*domain.TermsVersion, error) {
+					return sampleTermsVersion(), nil
+				}
+			},
+		},
+		{
+			name:  "terms_versions_publish_exists",
+			route: "POST /api/v1/admin/terms",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/terms", Body: `{"document":"terms","version":"2024-06-01"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Terms.PublishVersionFunc = func(context.Context, string, string, string, string) (*domain.TermsVersion, error) {
+					return nil, domain.ErrTermsVersionExists
+				}
+			},
+		},
+		{
+			name:  "terms_versions_publish_as_user",
+			route: "POST /api/v1/admin/terms",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/terms", Body: `{"document":"terms","version":"2024-06-01"}`},
+			as:    asUser,
+		},
+		{
+			name:  "jobs_list",
+			route: "GET /api/v1/admin/jobs",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/jobs?status=failed"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Jobs.ListJobsFunc = func(context.Context, *ports.JobQuery) (*ports.JobQueryResult, error) {
+					return &ports.JobQueryResult{Jobs: []*domain.Job{sampleFailedJob()}, TotalCount: 1, Page: 1, PageSize: 20, TotalPages: 1}, nil
+				}
+			},
+		},
+		{
+			name:    "jobs_list_invalid_status",
+			route:   "GET /api/v1/admin/jobs",
+			req:     routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/jobs?status=dead"},
+			as:      asAdmin,
+			invalid: true,
+		},
+		{
+			name:  "jobs_get",
+			route: "GET /api/v1/admin/jobs/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/jobs/j1"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Jobs.GetJobFunc = func(context.Context, string) (*domain.Job, error) {
+					return sampleFailedJob(), nil
+				}
+			},
+		},
+		{
+			name:  "jobs_get_not_found",
+			route: "GET /api/v1/admin/jobs/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/jobs/missing"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Jobs.GetJobFunc = func(context.Context, string) (*domain.Job, error) {
+					return nil, usecase.ErrJobNotFound
+				}
+			},
+		},
+		{
+			name:  "jobs_retry",
+			route: "POST /api/v1/admin/jobs/:id/retry",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/jobs/j1/retry"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Jobs.RetryJobFunc = func(context.Context, string, string) (*domain.Job, error) {
+					job := sampleFailedJob()
+					job.Retry(created.Add(2 * time.Hour))
+					return job, nil
+				}
+			},
+		},
+		{
+			name:  "jobs_retry_not_failed",
+			route: "POST /api/v1/admin/jobs/:id/retry",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/jobs/j1/retry"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Jobs.RetryJobFunc = func(context.Context, string, string) (*domain.Job, error) {
+					return nil, usecase.ErrJobNotFailed
+				}
+			},
+		},
+		{
+			name:  "jobs_retry_as_user",
+			route: "POST /api/v1/admin/jobs/:id/retry",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/jobs/j1/retry"},
+			as:    asUser,
+		},
+		{
+			name:  "directory_sync_status",
+			route: "GET /api/v1/admin/directory-sync",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/directory-sync"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.DirectorySync.StatusFunc = func(context.Context) (*ports.DirectorySyncStatus, error) {
+					return &ports.DirectorySyncStatus{LastReport: &ports.DirectorySyncReport{
+						StartedAt: created, FinishedAt: created.Add(2 * time.Second), Entries: 3, Created: 1, Deactivated: 1, Unchanged: 1,
+						Changes: []ports.DirectorySyncChange{
+							{Action: ports.DirectorySyncCreate, DirectoryID: "4f2c9a1e", UserID: "u2", Email: "jane.roe@example.com"},
+							{Action: ports.DirectorySyncDeactivate, DirectoryID: "8b7d3e05", UserID: "u3", Email: "max.mustermann@example.com"},
+						},
+					}}, nil
+				}
+			},
+		},
+		{
+			name:  "directory_sync_disabled",
+			route: "GET /api/v1/admin/directory-sync",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/directory-sync"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.DirectorySync.StatusFunc = func(context.Context) (*ports.DirectorySyncStatus, error) {
+					return nil, usecase.ErrDirectorySyncDisabled
+				}
+			},
+		},
+		{
+			name:  "directory_sync_trigger",
+			route: "POST /api/v1/admin/directory-sync",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/directory-sync?dry_run=true"},
+			as:    asAdmin,
+		},
+		{
+			name:  "directory_sync_running",
+			route: "POST /api/v1/admin/directory-sync",
+			req:   routestest.Request{Method: http.MethodPost, Target: "/api/v1/admin/directory-sync"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.DirectorySync.TriggerSyncFunc = func(context.Context, string, bool) error { return usecase.ErrDirectorySyncRunning }
+			},
+		},
+		{
+			name:  "system_database",
+			route: "GET /api/v1/admin/system/database",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/system/database"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Retries.RetryStatsFunc = func() ports.RetryStats { return ports.RetryStats{Retries: 3, Recovered: 2, Exhausted: 1} }
+				h.SlowQueries.SlowQueryStatsFunc = func() ports.SlowQueryStats {
+					return ports.SlowQueryStats{ThresholdMS: 100, Count: 1, Shapes: []ports.SlowQueryShape{
+						{Command: "find", Collection: "users", Filter: "{tenant_id: ?, email: ?}", Count: 1, MaxMS: 850},
+					}}
+				}
+			},
+		},
+		{
+			name:  "system_config",
+			route: "GET /api/v1/admin/system/config",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/system/config"},
+			as:    asAdmin,
+			setup: runtimeConfig(ports.RuntimeConfigUpdate{
+				Features:    map[string]bool{"new-dashboard": true},
+				CORSOrigins: []string{"https://app.example.com"},
+			}),
+		},
+		{
+			name:  "system_config_as_user",
+			route: "GET /api/v1/admin/system/config",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/system/config"},
+			as:    asUser,
+		},
+		{
+			name:  "update_system_config",
+			route: "PATCH /api/v1/admin/system/config",
+			req: routestest.Request{Method: http.MethodPatch, Target: "/api/v1/admin/system/config",
+				Body: `{"log_level":"warn","rate_limits":{"api":"600/1m"},"features":{"passkeys":true}}`},
+			as: asAdmin,
+			setup: func(h *routestest.Harness) {
+				runtimeConfig(ports.RuntimeConfigUpdate{})(h)
+				h.Audit.RecordFunc = func(_ context.Context, action, actorID, _ string, details map[string]string) error {
+					if action != domain.AuditActionConfigUpdated || actorID != "admin1" || details["log_level"] != "info -> warn" {
+						return fmt.Errorf("unexpected audit event %s by %s: %v", action, actorID, details)
+					}
+					return nil
+				}
+			},
+		},
+		{
+			name:  "update_system_config_invalid_origin",
+			route: "PATCH /api/v1/admin/system/config",
+			req: routestest.Request{Method: http.MethodPatch, Target: "/api/v1/admin/system/config",
+				Body: `{"cors_origins":["https://app.example.com/login"]}`},
+			as:    asAdmin,
+			setup: runtimeConfig(ports.RuntimeConfigUpdate{}),
+		},
+		{
+			name:  "update_system_config_not_managed",
+			route: "PATCH /api/v1/admin/system/config",
+			req:   routestest.Request{Method: http.MethodPatch, Target: "/api/v1/admin/system/config", Body: `{"log_level":"debug"}`},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				config, err := usecase.NewConfigUseCase(*h.Config.Config(), h.Audit, "ops")
+				if err != nil {
+					panic(err)
+				}
+				h.Config.UpdateConfigFunc = config.UpdateConfig
+			},
+		},
+		{
+			name:  "system_scheduler",
+			route: "GET /api/v1/admin/system/scheduler",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/system/scheduler"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Scheduler.ScheduledTasksFunc = func() []ports.ScheduledTaskStats {
+					next := time.Date(2024, 1, 2, 3, 0, 0, 0, time.UTC)
+					last := time.Date(2024, 1, 1, 3, 0, 7, 0, time.UTC)
+					return []ports.ScheduledTaskStats{
+						{Name: domain.JobTypeRetentionPurge, Schedule: "0 3 * * *", NextRunAt: &next, LastRunAt: &last, LastOutcome: ports.TaskSucceeded, LastMS: 42, Runs: 1},
+						{Name: domain.JobTypeStatsRollup, Schedule: "15 0 * * *", NextRunAt: &next},
+					}
+				}
+			},
+		},
+		{
+			name:  "stats_users",
+			route: "GET /api/v1/admin/stats/users",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/stats/users?days=7"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Stats.ListUserStatsFunc = func(context.Context, int) ([]*domain.UserStats, error) {
+					return []*domain.UserStats{
+						{Day: "2024-01-01", Total: 1250, New: 14, Active: 320, Deleted: 2, ComputedAt: time.Date(2024, 1, 2, 0, 15, 0, 0, time.UTC)},
+					}, nil
+				}
+			},
+		},
+		{
+			name:  "stats_users_invalid_days",
+			route: "GET /api/v1/admin/stats/users",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/stats/users?days=1000"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Stats.ListUserStatsFunc = func(context.Context, int) ([]*domain.UserStats, error) {
+					return nil, domain.ErrInvalidStatsDays
+				}
+			},
+			invalid: true,
+		},
+		{
+			name:  "analytics_registrations",
+			route: "GET /api/v1/admin/analytics/registrations",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/analytics/registrations?interval=week&from=2024-01-01&to=2024-01-22"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Stats.CountRegistrationsFunc = func(_ context.Context, query *ports.RegistrationsQuery) (*ports.RegistrationsReport, error) {
+					first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+					return &ports.RegistrationsReport{Interval: query.Interval, From: query.From, To: query.To, Total: 17, Buckets: []ports.RegistrationBucket{
+						{Start: first, Count: 9},
+						{Start: first.AddDate(0, 0, 7), Count: 0},
+						{Start: first.AddDate(0, 0, 14), Count: 8},
+					}}, nil
+				}
+			},
+		},
+		{
+			name:  "analytics_registrations_invalid_time",
+			route: "GET /api/v1/admin/analytics/registrations",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/analytics/registrations?from=yesterday"},
+			as:    asAdmin,
+		},
+		{
+			name:  "analytics_registrations_invalid_range",
+			route: "GET /api/v1/admin/analytics/registrations",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/analytics/registrations?from=2024-02-01&to=2024-01-01"},
+			as:    asAdmin,
+			setup: func(h *routestest.Harness) {
+				h.Stats.CountRegistrationsFunc = func(context.Context, *ports.RegistrationsQuery) (*ports.RegistrationsReport, error) {
+					return nil, domain.ErrInvalidAnalyticsRange
+				}
+			},
+		},
+		{
+			name:  "stats_users_as_user",
+			route: "GET /api/v1/admin/stats/users",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/admin/stats/users"},
+			as:    asUser,
+		},
+
+		// Middleware shared by the tenant routes
+		{
+			name:  "invalid_tenant",
+			route: "GET /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1", Header: map[string]string{routestest.TenantHeader: "Not A Tenant!"}},
+		},
+		{
+			name:  "token_of_other_tenant",
+			route: "GET /api/v1/users/me/settings",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/me/settings", Header: map[string]string{routestest.TenantHeader: "acme"}},
+			as:    asUser,
+		},
+		{
+			name:  "rate_limited",
+			route: "GET /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1"},
+			setup: func(h *routestest.Harness) {
+				h.RateLimiter.AllowFunc = func(context.Context, string, ports.RateLimit) (*ports.RateLimitResult, error) {
+					return &ports.RateLimitResult{RetryAfter: 30 * time.Second}, nil
+				}
+			},
+		},
+		{
+			name:  "database_unavailable",
+			route: "GET /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1"},
+			setup: func(h *routestest.Harness) {
+				h.Breaker.BreakerStatsFunc = func() ports.BreakerStats { return ports.BreakerStats{State: ports.BreakerOpen} }
+			},
+		},
+		{
+			name:  "database_unavailable_mid_request",
+			route: "GET /api/v1/users/:id",
+			req:   routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1"},
+			setup: func(h *routestest.Harness) {
+				h.Users.GetUserByIDFunc = func(context.Context, string) (*domain.User, error) { return nil, ports.ErrDatabaseUnavailable }
+			},
+		},
+	}
+}
+
+// signWithKeyPair signs the tokens with a fixed Ed25519 key, still accepting HS256 tokens
+func signWithKeyPair(t *testing.T) func(*routes.Dependencies) {
+	signer := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	tokens, err := security.NewKeyPairTokenManager(signer, nil, "routestest-secret", time.Hour)
+	if err != nil {
+		t.Fatalf("creating key pair token manager: %v", err)
+	}
+	return func(deps *routes.Dependencies) {
+		deps.Tokens = tokens
+	}
+}
+
+// introspectionForm is the introspection form of a token of u1, signed with the secret of the harness
+func introspectionForm(t *testing.T) string {
+	token, _, err := security.NewTokenManager("routestest-secret", time.Hour).Generate("u1", []string{asUser}, domain.DefaultTenantID)
+	if err != nil {
+		t.Fatalf("generating token: %v", err)
+	}
+	return url.Values{"token": {token}, "token_type_hint": {"access_token"}}.Encode()
+}
+
+// introspectionRequest introspects the token of form as the introspection client of the harness
+func introspectionRequest(form, secret string) routestest.Request {
+	return routestest.Request{
+		Method: http.MethodPost,
+		Target: "/api/v1/auth/introspect",
+		Body:   form,
+		Header: map[string]string{
+			"Content-Type":  "application/x-www-form-urlencoded",
+			"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(routestest.IntrospectionClient+":"+secret)),
+		},
+	}
+}
+
+// scopedToken is a token of the user restricted to the scopes, signed with the secret of the harness
+func scopedToken(t *testing.T, userID, role string, scopes ...string) string {
+	token, _, err := security.NewTokenManager("routestest-secret", time.Hour).GenerateScoped(userID, []string{role}, domain.DefaultTenantID, 0, scopes)
+	if err != nil {
+		t.Fatalf("generating scoped token: %v", err)
+	}
+	return token
+}
+
+// mfaToken is an MFA token of u1 for a login restricted to the scopes, signed with the secret of the harness
+func mfaToken(t *testing.T, scopes ...string) string {
+	token, _, err := security.NewTokenManager("routestest-secret", time.Hour).GenerateMFA("u1", domain.DefaultTenantID, scopes, time.Hour)
+	if err != nil {
+		t.Fatalf("generating mfa token: %v", err)
+	}
+	return token
+}
+
+// supportRoles resolves the permissions of the roles with the role use case, the custom role of
+// asSupport granting permissions
+func supportRoles(h *routestest.Harness, permissions ...string) {
+	roles := usecase.NewRoleUseCase(&mocks.RoleRepository{
+		GetRolesByNamesFunc: func(_ context.Context, names []string) ([]*domain.Role, error) {
+			if !slices.Contains(names, asSupport) {
+				return nil, nil
+			}
+			return []*domain.Role{{Name: asSupport, Permissions: permissions}}, nil
+		},
+	}, &mocks.UserRepository{})
+	h.Roles.HasPermissionFunc = roles.HasPermission
+	h.Roles.CoversRolesFunc = roles.CoversRoles
+}
+
+// impersonationToken is a token of u1 impersonated by admin1, which carries no auth time, signed with the secret of the harness
+func impersonationToken(t *testing.T) string {
+	token, _, err := security.NewTokenManager("routestest-secret", time.Hour).GenerateImpersonation("u1", []string{asUser}, domain.DefaultTenantID, "admin1", 0, nil, time.Hour)
+	if err != nil {
+		t.Fatalf("generating impersonation token: %v", err)
+	}
+	return token
+}
+
+// clientToken is a token of the OAuth2 client c1 granting the scopes, signed with the secret of the harness
+func clientToken(t *testing.T, scopes ...string) string {
+	token, _, err := security.NewTokenManager("routestest-secret", time.Hour).GenerateClient("c1", scopes, domain.DefaultTenantID)
+	if err != nil {
+		t.Fatalf("generating client token: %v", err)
+	}
+	return token
+}
+
+// tokenRequest requests a token with the form, authenticating with the basic credentials "<id>:<secret>"
+func tokenRequest(form, credentials string) routestest.Request {
+	return routestest.Request{
+		Method: http.MethodPost,
+		Target: "/api/v1/auth/token",
+		Body:   form,
+		Header: map[string]string{
+			"Content-Type":  "application/x-www-form-urlencoded",
+			"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)),
+		},
+	}
+}
+
+func protectDocs(auth routes.DocsAuth) func(*routes.Dependencies) {
+	return func(deps *routes.Dependencies) {
+		deps.DocsAuth = auth
+	}
+}
+
+// checkHealth reports the checks at /readyz
+func checkHealth(checks ...ports.HealthCheck) func(*routes.Dependencies) {
+	return func(deps *routes.Dependencies) {
+		deps.HealthChecks = checks
+	}
+}
+
+// runtimeConfig serves the runtime configuration of the harness with update applied through the
+// config use case, managed by the default tenant
+func runtimeConfig(update ports.RuntimeConfigUpdate) func(*routestest.Harness) {
+	return func(h *routestest.Harness) {
+		config, err := usecase.NewConfigUseCase(h.Config.Config().Updated(&update), h.Audit, domain.DefaultTenantID)
+		if err != nil {
+			panic(err)
+		}
+		h.Config.ConfigFunc = config.Config
+		h.Config.UpdateConfigFunc = config.UpdateConfig
+	}
+}
+
+// fixedHealth is a dependency always reporting health
+func fixedHealth(health ports.DependencyHealth) ports.HealthChecker {
+	return &mocks.HealthChecker{CheckHealthFunc: func(context.Context) ports.DependencyHealth {
+		return health
+	}}
+}
+
+// cookieMode makes logins set session cookies
+func cookieMode(deps *routes.Dependencies) {
+	deps.AuthMode = domain.AuthModeCookie
+}
+
+// sampleSession is a session of u1 started at created, whose CSRF token is csrf-token
+func sampleSession() *domain.Session {
+	return &domain.Session{
+		ID:            domain.HashSessionToken("session-token"),
+		TenantID:      domain.DefaultTenantID,
+		UserID:        "u1",
+		Roles:         []string{asUser},
+		AuthTime:      created,
+		CSRFTokenHash: domain.HashSessionToken("csrf-token"),
+		CreatedAt:     created,
+		ExpiresAt:     created.Add(12 * time.Hour),
+	}
+}
+
+// cookieSession authenticates the session-token cookie as sampleSession
+func cookieSession(h *routestest.Harness) {
+	h.Sessions.AuthenticateFunc = func(_ context.Context, token string) (*domain.Session, error) {
+		if token != "session-token" {
+			return nil, domain.ErrSessionNotFound
+		}
+		return sampleSession(), nil
+	}
+}
+
+// sessionRequest is a request sending the session-token and csrf-token cookies and, unless empty,
+// the CSRF token in the header
+func sessionRequest(method, target, body, csrfToken string) routestest.Request {
+	header := map[string]string{"Cookie": "session=session-token; csrf_token=csrf-token"}
+	if csrfToken != "" {
+		header["X-CSRF-Token"] = csrfToken
+	}
+	return routestest.Request{Method: method, Target: target, Body: body, Header: header}
+}
+
+// serve sends the request of a case to a new harness
+func serve(t *testing.T, tc routeCase) (routestest.Request, *httptest.ResponseRecorder) {
+	t.Helper()
+	var configure []func(*routes.Dependencies)
+	if tc.deps != nil {
+		configure = append(configure, tc.deps)
+	}
+	h := routestest.New(t, configure...)
+	if tc.setup != nil {
+		tc.setup(h)
+	}
+	req := tc.req
+	if tc.as != anonymous {
+		req.Token = h.Token(t, userIDs[tc.as], tc.as)
+	}
+	return req, h.Do(req)
+}
+
+func TestRoutes(t *testing.T) {
+	for _, tc := range routeCases(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			_, w := serve(t, tc)
+
+			routestest.AssertGolden(t, tc.name, w, tc.ignore...)
+			// Clients branch on error codes, so every error body must carry one
+			var body struct{ Code, Error string }
+			if w.Code >= 400 && json.Unmarshal(w.Body.Bytes(), &body) == nil && body.Error != "" && body.Code == "" {
+				t.Errorf("error response %q has no code", body.Error)
+			}
+		})
+	}
+}
+
+// TestEveryRouteHasACase keeps the golden files in step with the routes registered
+func TestEveryRouteHasACase(t *testing.T) {
+	covered := make(map[string]bool)
+	for _, tc := range routeCases(t) {
+		covered[tc.route] = true
+	}
+
+	for _, route := range routestest.New(t).Router.Routes() {
+		if route.Path == "/swagger/*any" {
+			continue
+		}
+		if key := route.Method + " " + route.Path; !covered[key] {
+			t.Errorf("no golden case for %s", key)
+		}
+	}
+}
+
+// tenantDeployments serves the users of the shared database and of the database of acme from fake
+// deployments, each returning the user of the tenant its queries filter on
+func tenantDeployments(t *testing.T) (shared, acme *repositorytest.Server, users ports.UserUseCase) {
+	t.Helper()
+	serve := func(emails map[string]string) *repositorytest.Server {
+		server := repositorytest.NewServer(t)
+		server.Reply = func(cmd repositorytest.Command) bson.D {
+			tenantID, _ := cmd.Body.Lookup("filter", "tenant_id").StringValueOK()
+			if cmd.Name != "find" || emails[tenantID] == "" {
+				return nil
+			}
+			return repositorytest.FirstBatch(cmd.Namespace(), 0, bson.M{"_id": "u1", "tenant_id": tenantID, "email": emails[tenantID], "created_at": created})
+		}
+		return server
+	}
+	shared = serve(map[string]string{domain.DefaultTenantID: "john.doe@example.com", "globex": "hank@globex.example"})
+	acme = serve(map[string]string{"acme": "ada@acme.example"})
+
+	databases := repository.NewTenantDatabases(shared.Client(t).Database("users_db"), map[string]*mongo.Database{
+		"acme": acme.Client(t).Database("acme_users_db"),
+	})
+	users = routes.NewUseCases(routes.Dependencies{UserRepo: repository.NewUserRepository(databases, "users")}).Users
+	return shared, acme, users
+}
+
+// TestTenantsReachTheirDatabase serves the user reads of each tenant from the real user repository,
+// routed to the database of the tenant
+func TestTenantsReachTheirDatabase(t *testing.T) {
+	tests := []struct {
+		name      string
+		tenant    string
+		wantEmail string
+		wantNS    string // Namespace of the query, in the shared database unless it is acme's
+	}{
+		{name: "default tenant", wantEmail: "john.doe@example.com", wantNS: "users_db.users"},
+		{name: "tenant with its own database", tenant: "acme", wantEmail: "ada@acme.example", wantNS: "acme_users_db.users"},
+		{name: "tenant of the shared database", tenant: "globex", wantEmail: "hank@globex.example", wantNS: "users_db.users"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shared, acme, users := tenantDeployments(t)
+			h := routestest.New(t)
+			h.Users.GetUserByIDFunc = users.GetUserByID
+
+			req := routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1"}
+			if tt.tenant != "" {
+				req.Header = map[string]string{routestest.TenantHeader: tt.tenant}
+			}
+			w := h.Do(req)
+			var user struct{ Email string }
+			if err := json.Unmarshal(w.Body.Bytes(), &user); err != nil || w.Code != http.StatusOK {
+				t.Fatalf("GET /api/v1/users/u1 = %d %s", w.Code, w.Body)
+			}
+			if user.Email != tt.wantEmail {
+				t.Errorf("email = %q, want %q", user.Email, tt.wantEmail)
+			}
+
+			var namespaces []string
+			for _, cmd := range append(shared.Commands(), acme.Commands()...) {
+				namespaces = append(namespaces, cmd.Namespace())
+			}
+			if !slices.Equal(namespaces, []string{tt.wantNS}) {
+				t.Errorf("queries = %v, want [%s]", namespaces, tt.wantNS)
+			}
+		})
+	}
+}
+
+// TestReadsAfterTheConsistencyToken sends back the consistency token of a response, whose next
+// read waits for the position it carries
+func TestReadsAfterTheConsistencyToken(t *testing.T) {
+	_, acme, users := tenantDeployments(t)
+	h := routestest.New(t)
+	h.Users.GetUserByIDFunc = users.GetUserByID
+	req := routestest.Request{Method: http.MethodGet, Target: "/api/v1/users/u1", Header: map[string]string{routestest.TenantHeader: "acme"}}
+
+	w := h.Do(req)
+	token := w.Header().Get("X-Consistency-Token")
+	if w.Code != http.StatusOK || token == "" {
+		t.Fatalf("GET /api/v1/users/u1 = %d with token %q, want 200 with a token", w.Code, token)
+	}
+	readConcern := func() bson.Raw {
+		commands := acme.Commands()
+		value, _ := commands[len(commands)-1].Body.Lookup("readConcern").DocumentOK()
+		return value
+	}
+	if rc := readConcern(); rc != nil {
+		t.Errorf("readConcern of the first read = %s, want none", rc)
+	}
+
+	req.Header["X-Consistency-Token"] = token
+	if w := h.Do(req); w.Code != http.StatusOK || w.Header().Get("X-Consistency-Token") == "" {
+		t.Fatalf("GET /api/v1/users/u1 = %d with token %q, want 200 with a token", w.Code, w.Header().Get("X-Consistency-Token"))
+	}
+	if _, _, ok := readConcern().Lookup("afterClusterTime").TimestampOK(); !ok {
+		t.Errorf("readConcern of the next read = %s, want one after the token", readConcern())
+	}
+}
+
+func TestLoginClientIP(t *testing.T) {
+	const peer, forwarded = "192.0.2.1", "203.0.113.7" // httptest requests come from 192.0.2.1
+	tests := []struct {
+		name           string
+		trustedProxies []string // nil trusts no proxy, as without TRUSTED_PROXIES
+		wantIP         string
+	}{
+		{name: "no trusted proxy", wantIP: peer},
+		{name: "from a trusted proxy", trustedProxies: []string{"192.0.2.0/24"}, wantIP: forwarded},
+		{name: "from another proxy", trustedProxies: []string{"198.51.100.1"}, wantIP: peer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := routestest.New(t)
+			if err := h.Router.SetTrustedProxies(tt.trustedProxies); err != nil {
+				t.Fatal(err)
+			}
+			// Each use of the client IP records the IP it got
+			ips := map[string]string{}
+			h.IPBackoff.CheckFunc = func(_ context.Context, ip string) (time.Duration, error) {
+				ips["backoff"] = ip
+				return 0, nil
+			}
+			h.RateLimiter.AllowFunc = func(_ context.Context, key string, limit ports.RateLimit) (*ports.RateLimitResult, error) {
+				if _, ip, ok := strings.Cut(key, ":ip:"+domain.DefaultTenantID+":"); ok {
+					ips["rate limit "+strings.Split(key, ":")[1]] = ip
+				}
+				return &ports.RateLimitResult{Allowed: true, Remaining: limit.Requests - 1}, nil
+			}
+			h.LoginEvents.AssessLoginFunc = func(_ context.Context, _ *domain.User, ip, _ string) ([]domain.LoginAnomaly, error) {
+				ips["anomaly detection"] = ip
+				return nil, nil
+			}
+			h.LoginEvents.RecordLoginFunc = func(_ context.Context, _ *domain.User, ip, _ string) (*domain.LoginEvent, error) {
+				ips["login event"] = ip
+				return nil, nil
+			}
+			h.Users.AuthenticateFunc = func(context.Context, string, string) (*domain.User, error) { return sampleUser(), nil }
+
+			w := h.Do(routestest.Request{
+				Method: http.MethodPost,
+				Target: "/api/v1/auth/login",
+				Body:   `{"email":"john.doe@example.com","password":"secret123"}`,
+				Header: map[string]string{"X-Forwarded-For": forwarded},
+			})
+			if w.Code != http.StatusOK {
+				t.Fatalf("POST /api/v1/auth/login = %d %s", w.Code, w.Body)
+			}
+			for _, use := range []string{"backoff", "rate limit api", "rate limit auth", "anomaly detection", "login event"} {
+				if ip := ips[use]; ip != tt.wantIP {
+					t.Errorf("client IP of the %s = %q, want %s", use, ip, tt.wantIP)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,390 @@
+// Package routestest serves the API routes from fake use cases, so tests can exercise the whole
+// middleware chain of a route and compare its responses with golden files.
+package routestest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/frtasoniero/user-management-api/docs"
+	handler "github.com/frtasoniero/user-management-api/internal/adapters/handler/http"
+	"github.com/frtasoniero/user-management-api/internal/core/domain"
+	"github.com/frtasoniero/user-management-api/internal/core/ports"
+	"github.com/frtasoniero/user-management-api/internal/mocks"
+	"github.com/frtasoniero/user-management-api/pkg/security"
+	"github.com/frtasoniero/user-management-api/routes"
+	"github.com/gin-gonic/gin"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the responses received")
+
+// TenantHeader is the header the harness resolves tenants from; requests without it get domain.DefaultTenantID
+const TenantHeader = "X-Tenant-ID"
+
+// IntrospectionClient and IntrospectionSecret are the credentials of the client allowed to introspect tokens
+const (
+	IntrospectionClient = "gateway"
+	IntrospectionSecret = "s3cret"
+)
+
+// GoldenHeaders are the response headers recorded in golden files; the others, such as
+// Content-Disposition with its timestamped file name, vary between runs or add nothing
+var GoldenHeaders = []string{
+	"Content-Type",
+	"Content-Language",
+	"Retry-After",
+	"Set-Cookie",
+	"X-RateLimit-Limit",
+	"X-RateLimit-Remaining",
+	"X-Total-Count",
+	"X-Page",
+	"X-Page-Size",
+	"X-Total-Pages",
+	"Access-Control-Allow-Origin",
+	"Access-Control-Allow-Credentials",
+}
+
+// Harness is a router serving every API route from fakes. The fakes are shared with the
+// handlers, so a test sets the Func fields it needs before sending its request.
+type Harness struct {
+	Router *gin.Engine
+	Tokens *security.TokenManager
+
+	Users         *mocks.UserUseCase
+	Organizations *mocks.OrganizationUseCase
+	Roles         *mocks.RoleUseCase
+	Audit         *mocks.AuditUseCase
+	Merge         *mocks.AccountMergeUseCase
+	Export        *mocks.ExportUseCase
+	LoginEvents   *mocks.LoginEventUseCase
+	Avatars       *mocks.AvatarUseCase
+	DirectorySync *mocks.DirectorySyncUseCase
+	ExternalAuth  *mocks.ExternalAuthUseCase
+	SigningKeys   *mocks.SigningKeyUseCase
+	OAuthClients  *mocks.OAuthClientUseCase
+	EmailDomains  *mocks.EmailDomainUseCase
+	Terms         *mocks.TermsUseCase
+	Jobs          *mocks.JobUseCase
+	Stats         *mocks.StatsUseCase
+	Relationships *mocks.RelationshipUseCase
+	Documents     *mocks.DocumentUseCase
+	Verification  *mocks.VerificationUseCase
+	Phone         *mocks.PhoneVerificationUseCase
+	MFA           *mocks.MFAUseCase
+	Security      *mocks.SecurityEventUseCase
+	Sessions      *mocks.SessionUseCase
+	UserHistory   *mocks.UserHistoryUseCase
+	Config        *mocks.ConfigUseCase
+
+	IPBackoff   *mocks.IPBackoff
+	RateLimiter *mocks.RateLimiter
+	Retries     *mocks.RetryReporter
+	Breaker     *mocks.BreakerReporter
+	SlowQueries *mocks.SlowQueryReporter
+	Scheduler   *mocks.SchedulerReporter
+}
+
+// New returns a harness whose fakes answer with zero values, except that every request is within
+// its rate limit, the runtime configuration has no feature flags nor CORS origins, the database
+// breaker is closed, tokens not issued by the API are rejected and roles grant the permissions of
+// the system role of the same name, so admin tokens pass every permission check and user tokens
+// none, and IntrospectionClient may introspect tokens.
+// The configure functions can change the settings the routes are registered with.
+func New(t testing.TB, configure ...func(*routes.Dependencies)) *Harness {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	h := &Harness{
+		Router:        gin.New(),
+		Tokens:        security.NewTokenManager("routestest-secret", time.Hour),
+		Users:         &mocks.UserUseCase{},
+		Organizations: &mocks.OrganizationUseCase{},
+		Roles: &mocks.RoleUseCase{
+			HasPermissionFunc: func(_ context.Context, roles []string, permission string) (bool, error) {
+				for _, name := range roles {
+					if role := domain.SystemRole(name); role != nil && role.Grants(permission) {
+						return true, nil
+					}
+				}
+				return false, nil
+			},
+			CoversRolesFunc: func(_ context.Context, roles, others []string) (bool, error) {
+				for _, name := range others {
+					role := domain.SystemRole(name)
+					if role == nil {
+						continue
+					}
+					for _, permission := range role.Permissions {
+						if !slices.ContainsFunc(roles, func(name string) bool {
+							granted := domain.SystemRole(name)
+							return granted != nil && granted.Grants(permission)
+						}) {
+							return false, nil
+						}
+					}
+				}
+				return true, nil
+			},
+		},
+		Audit:         &mocks.AuditUseCase{},
+		Merge:         &mocks.AccountMergeUseCase{},
+		Export:        &mocks.ExportUseCase{},
+		LoginEvents:   &mocks.LoginEventUseCase{},
+		Avatars:       &mocks.AvatarUseCase{},
+		DirectorySync: &mocks.DirectorySyncUseCase{},
+		ExternalAuth: &mocks.ExternalAuthUseCase{
+			AuthenticateTokenFunc: func(context.Context, string) (*domain.User, error) { return nil, security.ErrInvalidToken },
+		},
+		SigningKeys:   &mocks.SigningKeyUseCase{},
+		OAuthClients:  &mocks.OAuthClientUseCase{},
+		EmailDomains:  &mocks.EmailDomainUseCase{},
+		Terms:         &mocks.TermsUseCase{},
+		Jobs:          &mocks.JobUseCase{},
+		Stats:         &mocks.StatsUseCase{},
+		Relationships: &mocks.RelationshipUseCase{},
+		Documents:     &mocks.DocumentUseCase{},
+		Verification:  &mocks.VerificationUseCase{},
+		Phone:         &mocks.PhoneVerificationUseCase{},
+		MFA:           &mocks.MFAUseCase{},
+		Security:      &mocks.SecurityEventUseCase{},
+		Sessions:      &mocks.SessionUseCase{},
+		UserHistory:   &mocks.UserHistoryUseCase{},
+		Config: &mocks.ConfigUseCase{
+			ConfigFunc: func() *ports.RuntimeConfig {
+				return &ports.RuntimeConfig{
+					LogLevel: ports.LogLevelInfo,
+					RateLimits: ports.RuntimeRateLimits{
+						API:        ports.RateLimit{Requests: 100, Window: time.Minute},
+						Auth:       ports.RateLimit{Requests: 10, Window: time.Minute},
+						EmailCheck: ports.RateLimit{Requests: 20, Window: time.Minute},
+					},
+					Features:    map[string]bool{},
+					CORSOrigins: []string{},
+				}
+			},
+		},
+		IPBackoff: &mocks.IPBackoff{},
+		RateLimiter: &mocks.RateLimiter{
+			AllowFunc: func(_ context.Context, _ string, limit ports.RateLimit) (*ports.RateLimitResult, error) {
+				return &ports.RateLimitResult{Allowed: true, Remaining: limit.Requests - 1}, nil
+			},
+		},
+		Retries: &mocks.RetryReporter{},
+		Breaker: &mocks.BreakerReporter{
+			BreakerStatsFunc: func() ports.BreakerStats {
+				return ports.BreakerStats{State: ports.BreakerClosed}
+			},
+		},
+		SlowQueries: &mocks.SlowQueryReporter{},
+		Scheduler:   &mocks.SchedulerReporter{},
+	}
+
+	deps := routes.Dependencies{
+		Tokens:               h.Tokens,
+		ImpersonationTTL:     15 * time.Minute,
+		RecentAuthMaxAge:     15 * time.Minute,
+		Tenancy:              handler.TenantResolver{Header: TenantHeader, DefaultTenant: domain.DefaultTenantID},
+		BodyLimits:           handler.DefaultBodyLimits(),
+		RequestTimeouts:      handler.DefaultRequestTimeouts(),
+		RateLimiter:          h.RateLimiter,
+		IPBackoff:            h.IPBackoff,
+		Config:               h.Config,
+		LastSeenInterval:     time.Hour,
+		DatabaseRetries:      h.Retries,
+		DatabaseBreaker:      h.Breaker,
+		SlowQueries:          h.SlowQueries,
+		Scheduler:            h.Scheduler,
+		OpenAPI:              []byte(docs.SwaggerInfo.ReadDoc()),
+		IntrospectionClients: handler.ClientCredentials{IntrospectionClient: IntrospectionSecret},
+	}
+	for _, f := range configure {
+		f(&deps)
+	}
+	// Tokens of the callers are issued by the token manager the routes are registered with
+	h.Tokens = deps.Tokens
+	// As the API without TRUSTED_PROXIES, the client IP is the peer of the request
+	if err := h.Router.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("trusting no proxy: %v", err)
+	}
+	routes.RegisterHandlers(h.Router, deps, routes.UseCases{
+		Users:         h.Users,
+		Organizations: h.Organizations,
+		Roles:         h.Roles,
+		Audit:         h.Audit,
+		Merge:         h.Merge,
+		Export:        h.Export,
+		LoginEvents:   h.LoginEvents,
+		Avatars:       h.Avatars,
+		DirectorySync: h.DirectorySync,
+		ExternalAuth:  h.ExternalAuth,
+		SigningKeys:   h.SigningKeys,
+		OAuthClients:  h.OAuthClients,
+		EmailDomains:  h.EmailDomains,
+		Terms:         h.Terms,
+		Jobs:          h.Jobs,
+		Stats:         h.Stats,
+		Relationships: h.Relationships,
+		Documents:     h.Documents,
+		Verification:  h.Verification,
+		Phone:         h.Phone,
+		MFA:           h.MFA,
+		Security:      h.Security,
+		Sessions:      h.Sessions,
+		UserHistory:   h.UserHistory,
+		Config:        h.Config,
+	})
+	return h
+}
+
+// Token returns a bearer token of the default tenant for the user with the roles
+func (h *Harness) Token(t testing.TB, userID string, roles ...string) string {
+	t.Helper()
+	token, _, err := h.Tokens.Generate(userID, roles, domain.DefaultTenantID)
+	if err != nil {
+		t.Fatalf("generating token: %v", err)
+	}
+	return token
+}
+
+// Request describes a request sent by Do
+type Request struct {
+	Method string
+	Target string // Path and query
+	Body   string // Sent as JSON unless Header sets another Content-Type
+	Token  string // Bearer token, see Token
+	Header map[string]string
+}
+
+// Do serves the request and records the response
+func (h *Harness) Do(req Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.Router.ServeHTTP(w, NewRequest(req))
+	return w
+}
+
+// NewRequest builds the HTTP request Do sends
+func NewRequest(req Request) *http.Request {
+	var body io.Reader
+	if req.Body != "" {
+		body = strings.NewReader(req.Body)
+	}
+	r := httptest.NewRequest(req.Method, req.Target, body)
+	if req.Body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	if req.Token != "" {
+		r.Header.Set("Authorization", "Bearer "+req.Token)
+	}
+	for name, value := range req.Header {
+		r.Header.Set(name, value)
+	}
+	return r
+}
+
+// golden is the content of a golden file
+type golden struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"` // application/json bodies as is, other bodies as a JSON string
+}
+
+// AssertGolden compares the status, GoldenHeaders and body of the response with
+// testdata/golden/<name>.json, or rewrites that file when the test runs with -update.
+// The values of the top-level body fields in ignore, such as tokens and expiry times, are
+// replaced with "<ignored>".
+func AssertGolden(t testing.TB, name string, w *httptest.ResponseRecorder, ignore ...string) {
+	t.Helper()
+
+	got, err := render(w, ignore)
+	if err != nil {
+		t.Fatalf("rendering response of %s: %v", name, err)
+	}
+
+	path := filepath.Join("testdata", "golden", name+".json")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file (run the test with -update to create it): %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("response differs from %s (run the test with -update to accept it)\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func render(w *httptest.ResponseRecorder, ignore []string) ([]byte, error) {
+	g := golden{Status: w.Code}
+	for _, name := range GoldenHeaders {
+		if value := w.Header().Get(name); value != "" {
+			if g.Headers == nil {
+				g.Headers = make(map[string]string)
+			}
+			g.Headers[name] = value
+		}
+	}
+
+	if body := w.Body.Bytes(); len(body) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+		if mediaType == "application/json" && json.Valid(body) {
+			g.Body = ignoreFields(body, ignore)
+		} else {
+			text, err := json.Marshal(string(body))
+			if err != nil {
+				return nil, err
+			}
+			g.Body = text
+		}
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(g); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// ignoreFields replaces the values of the fields of a JSON object; other bodies are returned as is
+func ignoreFields(body []byte, fields []string) []byte {
+	if len(fields) == 0 {
+		return body
+	}
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil {
+		return body
+	}
+	for _, field := range fields {
+		if _, ok := object[field]; ok {
+			object[field] = json.RawMessage(`"<ignored>"`)
+		}
+	}
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(object); err != nil {
+		return body
+	}
+	return bytes.TrimSpace(out.Bytes())
+}
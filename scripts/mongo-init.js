@@ -21,18 +21,73 @@ db.createCollection('users', {
     $jsonSchema: {
       bsonType: 'object',
       // Remove additionalProperties: false to allow _id field
-      required: ['email', 'password_hash', 'profile', 'created_at', 'updated_at'],
+      required: ['tenant_id', 'email', 'password_hash', 'profile', 'created_at', 'updated_at'],
       properties: {
         _id: {
           bsonType: 'string',
           pattern: '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$',
           description: 'Must be a valid UUID'
         },
+        tenant_id: {
+          bsonType: 'string',
+          pattern: '^[a-z0-9][a-z0-9-]{0,62}$',
+          description: 'Must be a valid tenant identifier'
+        },
         email: {
           bsonType: 'string',
           pattern: '^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\\.[a-zA-Z]{2,}$',
           description: 'Must be a valid email address'
         },
+        normalized_email: {
+          bsonType: 'string',
+          description: 'Email with provider aliasing removed, used for uniqueness'
+        },
+        email_verified_at: {
+          bsonType: 'date',
+          description: 'When the user proved to own the email'
+        },
+        password_reset_required: {
+          bsonType: 'bool',
+          description: 'Logins are refused until the password is changed'
+        },
+        deleted_at: {
+          bsonType: 'date',
+          description: 'When the user was soft-deleted'
+        },
+        merged_into: {
+          bsonType: 'string',
+          description: 'ID of the user a duplicate account was merged into'
+        },
+        anonymized_at: {
+          bsonType: 'date',
+          description: 'When the retention job anonymized the soft-deleted user'
+        },
+        directory_id: {
+          bsonType: 'string',
+          description: 'Identifier of the directory entry the account is synced from'
+        },
+        deactivated_at: {
+          bsonType: 'date',
+          description: 'When the account was deactivated by a directory sync'
+        },
+        external_identity: {
+          bsonType: 'object',
+          required: ['issuer', 'subject'],
+          properties: {
+            issuer: { bsonType: 'string', description: 'Issuer of the tokens of the external identity provider' },
+            subject: { bsonType: 'string', description: 'Subject of the account at the identity provider' }
+          }
+        },
+        custom_fields: {
+          bsonType: 'object',
+          additionalProperties: { bsonType: 'string' },
+          description: 'Values of the custom registration fields of REGISTRATION_FIELDS'
+        },
+        username: {
+          bsonType: 'string',
+          pattern: '^[a-z0-9][a-z0-9._-]{2,29}$',
+          description: 'Must be a valid lowercase username'
+        },
         password_hash: {
           bsonType: 'string',
           minLength: 1,
@@ -50,6 +105,42 @@ db.createCollection('users', {
               bsonType: 'string',
               minLength: 1
             },
+            addresses: {
+              bsonType: 'array',
+              maxItems: 10,
+              items: {
+                bsonType: 'object',
+                required: ['id', 'type', 'primary'],
+                properties: {
+                  id: { bsonType: 'string' },
+                  type: { enum: ['home', 'work', 'billing'] },
+                  primary: { bsonType: 'bool' },
+                  street: { bsonType: 'string' },
+                  city: { bsonType: 'string' },
+                  state: { bsonType: 'string' },
+                  country: { bsonType: 'string' },
+                  zip_code: { bsonType: 'string' },
+                  location: {
+                    bsonType: 'object',
+                    required: ['lat', 'lng'],
+                    properties: {
+                      lat: { bsonType: 'double', minimum: -90, maximum: 90 },
+                      lng: { bsonType: 'double', minimum: -180, maximum: 180 }
+                    }
+                  },
+                  // GeoJSON copy of location for the 2dsphere index, longitude first
+                  geo: {
+                    bsonType: 'object',
+                    required: ['type', 'coordinates'],
+                    properties: {
+                      type: { enum: ['Point'] },
+                      coordinates: { bsonType: 'array', minItems: 2, maxItems: 2, items: { bsonType: 'double' } }
+                    }
+                  }
+                }
+              }
+            },
+            // Single address of users created before addresses, migrated on their next address change
             address: {
               bsonType: 'object',
               properties: {
@@ -62,7 +153,9 @@ db.createCollection('users', {
             },
             phone: { bsonType: 'string' },
             birthdate: { bsonType: 'string' },
-            nin: { bsonType: 'string' }
+            nin: { bsonType: 'string' },
+            timezone: { bsonType: 'string' },
+            locale: { bsonType: 'string' }
           }
         },
         created_at: {
@@ -70,6 +163,14 @@ db.createCollection('users', {
         },
         updated_at: {
           bsonType: 'date'
+        },
+        last_login_at: {
+          bsonType: 'date',
+          description: 'When the user last logged in'
+        },
+        last_seen_at: {
+          bsonType: 'date',
+          description: 'When the user last made an authenticated request, updated at most every LAST_SEEN_INTERVAL'
         }
       }
     }
@@ -79,9 +180,46 @@ db.createCollection('users', {
 });
 
 // Create indexes for better performance
+// Emails and NINs are unique per tenant
 db.users.createIndex(
-  { email: 1 },
-  { unique: true, name: 'email_unique_idx' }
+  { tenant_id: 1, email: 1 },
+  { unique: true, name: 'tenant_email_unique_idx' }
+);
+
+db.users.createIndex(
+  { tenant_id: 1, normalized_email: 1 },
+  {
+    unique: true,
+    partialFilterExpression: { normalized_email: { $exists: true } },
+    name: 'tenant_normalized_email_unique_partial_idx'
+  }
+);
+
+db.users.createIndex(
+  { tenant_id: 1, username: 1 },
+  {
+    unique: true,
+    partialFilterExpression: { username: { $exists: true } },
+    name: 'tenant_username_unique_partial_idx'
+  }
+);
+
+db.users.createIndex(
+  { tenant_id: 1, directory_id: 1 },
+  {
+    unique: true,
+    partialFilterExpression: { directory_id: { $exists: true } },
+    name: 'tenant_directory_id_unique_partial_idx'
+  }
+);
+
+db.users.createIndex(
+  { tenant_id: 1, 'external_identity.issuer': 1, 'external_identity.subject': 1 },
+  {
+    unique: true,
+    partialFilterExpression: { external_identity: { $exists: true } },
+    name: 'tenant_external_identity_unique_partial_idx'
+  }
 );
 
 db.users.createIndex(
@@ -89,20 +227,279 @@ db.users.createIndex(
   { name: 'name_idx' }
 );
 
+// Name prefixes of the autocomplete, see GET /users/autocomplete
+db.users.createIndex(
+  { tenant_id: 1, 'profile.first_name': 1 },
+  { name: 'tenant_first_name_idx' }
+);
+db.users.createIndex(
+  { tenant_id: 1, 'profile.last_name': 1 },
+  { name: 'tenant_last_name_idx' }
+);
+
 db.users.createIndex(
   { 'profile.phone': 1 },
   { sparse: true, name: 'phone_sparse_idx' }
 );
 
 db.users.createIndex(
-  { 'profile.nin': 1 },
-  { unique: true, sparse: true, name: 'nin_unique_sparse_idx' }
+  { tenant_id: 1, 'profile.nin': 1 },
+  {
+    unique: true,
+    partialFilterExpression: { 'profile.nin': { $exists: true } },
+    name: 'tenant_nin_unique_partial_idx'
+  }
+);
+
+db.users.createIndex(
+  { tenant_id: 1, 'verification.check.provider': 1, 'verification.check.id': 1 },
+  {
+    partialFilterExpression: { 'verification.check': { $exists: true } },
+    name: 'tenant_verification_check_partial_idx'
+  }
+);
+
+db.users.createIndex(
+  { tenant_id: 1, created_at: 1 },
+  { name: 'tenant_created_at_idx' }
+);
+
+db.users.createIndex(
+  { tenant_id: 1, last_seen_at: 1 },
+  { name: 'tenant_last_seen_at_idx' }
+);
+
+// Soft-deleted users, purged by the retention job across tenants
+db.users.createIndex(
+  { deleted_at: 1 },
+  { sparse: true, name: 'deleted_at_sparse_idx' }
+);
+
+db.users.createIndex(
+  { tags: 1 },
+  { name: 'tags_multikey_idx' }
 );
 
 db.users.createIndex(
+  { 'metadata.$**': 1 },
+  { name: 'metadata_wildcard_idx' }
+);
+
+// Located addresses, for ?near= lookups
+db.users.createIndex(
+  { tenant_id: 1, 'profile.addresses.geo': '2dsphere' },
+  { name: 'tenant_address_geo_idx' }
+);
+
+// Organizations and user memberships
+db.createCollection('organizations');
+db.organizations.createIndex(
+  { tenant_id: 1, slug: 1 },
+  { unique: true, name: 'tenant_slug_unique_idx' }
+);
+
+db.createCollection('memberships');
+db.memberships.createIndex(
+  { organization_id: 1, user_id: 1 },
+  { unique: true, name: 'organization_user_unique_idx' }
+);
+db.memberships.createIndex(
+  { tenant_id: 1, user_id: 1 },
+  { name: 'tenant_user_id_idx' }
+);
+
+// Custom roles (built-in admin/user roles are defined by the API)
+db.createCollection('roles');
+db.roles.createIndex(
+  { tenant_id: 1, name: 1 },
+  { unique: true, name: 'tenant_name_unique_idx' }
+);
+
+// Audit trail of administrative actions (impersonation, ...)
+db.createCollection('audit_logs');
+db.audit_logs.createIndex(
+  { tenant_id: 1, created_at: -1 },
+  { name: 'tenant_created_at_idx' }
+);
+db.audit_logs.createIndex(
+  { tenant_id: 1, actor_id: 1, created_at: -1 },
+  { name: 'tenant_actor_idx' }
+);
+db.audit_logs.createIndex(
+  { tenant_id: 1, target_id: 1, created_at: -1 },
+  { name: 'tenant_target_idx' }
+);
+db.audit_logs.createIndex(
+  { action: 1, created_at: -1 },
+  { name: 'action_created_at_idx' }
+);
+
+// Successful logins and the devices they came from, used for new-device notifications
+db.createCollection('login_events');
+db.login_events.createIndex(
+  { tenant_id: 1, user_id: 1, fingerprint: 1, created_at: -1 },
+  { name: 'tenant_user_fingerprint_idx' }
+);
+// Login history
+db.login_events.createIndex(
+  { tenant_id: 1, user_id: 1, created_at: -1 },
+  { name: 'tenant_user_created_at_idx' }
+);
+// Purged by the retention job across tenants
+db.login_events.createIndex(
   { created_at: 1 },
   { name: 'created_at_idx' }
 );
+db.login_events.createIndex(
+  { report_token_hash: 1 },
+  {
+    unique: true,
+    partialFilterExpression: { report_token_hash: { $exists: true } },
+    name: 'report_token_hash_unique_partial_idx'
+  }
+);
+
+// Token signing keys shared by every tenant, removed once expired
+db.createCollection('signing_keys');
+db.signing_keys.createIndex(
+  { expires_at: 1 },
+  { expireAfterSeconds: 0, name: 'expires_at_ttl_idx' }
+);
+
+// Cookie sessions of first-party web apps, removed once expired
+db.createCollection('sessions');
+db.sessions.createIndex(
+  { tenant_id: 1, user_id: 1 },
+  { name: 'tenant_user_idx' }
+);
+db.sessions.createIndex(
+  { expires_at: 1 },
+  { expireAfterSeconds: 0, name: 'expires_at_ttl_idx' }
+);
+
+// Follow relationships between the users of a tenant
+db.createCollection('relationships');
+db.relationships.createIndex(
+  { tenant_id: 1, follower_id: 1, followee_id: 1 },
+  { unique: true, name: 'tenant_follower_followee_unique_idx' }
+);
+db.relationships.createIndex(
+  { tenant_id: 1, followee_id: 1, created_at: -1 },
+  { name: 'tenant_followee_created_at_idx' }
+);
+
+// Users blocked by the users of a tenant
+db.createCollection('blocks');
+db.blocks.createIndex(
+  { tenant_id: 1, blocker_id: 1, blocked_id: 1 },
+  { unique: true, name: 'tenant_blocker_blocked_unique_idx' }
+);
+db.blocks.createIndex(
+  { tenant_id: 1, blocked_id: 1, blocker_id: 1 },
+  { name: 'tenant_blocked_blocker_idx' }
+);
+
+// Identity documents uploaded for the users of a tenant, whose files are kept in private storage
+db.createCollection('documents');
+db.documents.createIndex(
+  { tenant_id: 1, user_id: 1, created_at: -1 },
+  { name: 'tenant_user_created_at_idx' }
+);
+
+// Daily user statistics of each tenant, rolled up by the stats.rollup job
+db.createCollection('user_stats');
+db.user_stats.createIndex(
+  { tenant_id: 1, day: 1 },
+  { name: 'tenant_day_idx' }
+);
+
+// OAuth2 clients of services calling the API without a user, their secrets are stored hashed
+db.createCollection('oauth_clients');
+db.oauth_clients.createIndex(
+  { tenant_id: 1, created_at: 1 },
+  { name: 'tenant_created_at_idx' }
+);
+
+// Read model of the user lists (USER_READ_MODEL), one flat document per active user synced from the
+// users change stream, indexed for each sort field
+db.createCollection('user_list');
+db.user_list.createIndex(
+  { tenant_id: 1, created_at: 1 },
+  { name: 'tenant_created_at_idx' }
+);
+db.user_list.createIndex(
+  { tenant_id: 1, updated_at: 1 },
+  { name: 'tenant_updated_at_idx' }
+);
+db.user_list.createIndex(
+  { tenant_id: 1, email: 1 },
+  { name: 'tenant_email_idx' }
+);
+db.user_list.createIndex(
+  { tenant_id: 1, first_name: 1 },
+  { name: 'tenant_first_name_idx' }
+);
+db.user_list.createIndex(
+  { tenant_id: 1, last_name: 1 },
+  { name: 'tenant_last_name_idx' }
+);
+db.user_list.createIndex(
+  { tenant_id: 1, tags: 1 },
+  { name: 'tenant_tags_idx' }
+);
+
+// Change history of the users, one document per version with the changed fields
+db.createCollection('user_history');
+db.user_history.createIndex(
+  { tenant_id: 1, user_id: 1, version: -1 },
+  { unique: true, name: 'tenant_user_version_unique_idx' }
+);
+
+// Email domains added at runtime to the allow and deny lists of the registrations of a tenant
+db.createCollection('email_domain_rules');
+db.email_domain_rules.createIndex(
+  { tenant_id: 1, list: 1, domain: 1 },
+  { unique: true, name: 'tenant_list_domain_unique_idx' }
+);
+
+// Published versions of the terms of service and privacy policy, and their acceptances by users
+db.createCollection('terms_versions');
+db.terms_versions.createIndex(
+  { tenant_id: 1, document: 1, version: 1 },
+  { unique: true, name: 'tenant_document_version_unique_idx' }
+);
+db.terms_versions.createIndex(
+  { tenant_id: 1, document: 1, published_at: -1 },
+  { name: 'tenant_document_published_at_idx' }
+);
+db.createCollection('terms_acceptances');
+db.terms_acceptances.createIndex(
+  { tenant_id: 1, user_id: 1, accepted_at: -1 },
+  { name: 'tenant_user_accepted_at_idx' }
+);
+
+// Background jobs run by the workers of every instance, succeeded jobs are removed once expired
+db.createCollection('jobs');
+db.jobs.createIndex(
+  { status: 1, type: 1, run_at: 1 },
+  { name: 'status_type_run_at_idx' }
+);
+db.jobs.createIndex(
+  { tenant_id: 1, status: 1, created_at: -1 },
+  { name: 'tenant_status_created_at_idx' }
+);
+db.jobs.createIndex(
+  { unique_key: 1 },
+  {
+    unique: true,
+    partialFilterExpression: { unique_key: { $exists: true } },
+    name: 'unique_key_unique_partial_idx'
+  }
+);
+db.jobs.createIndex(
+  { expires_at: 1 },
+  { expireAfterSeconds: 0, name: 'expires_at_ttl_idx' }
+);
 
 print('✅ Database initialized successfully!');
 print('✅ Users collection created with schema validation');